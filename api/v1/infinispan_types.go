@@ -16,19 +16,211 @@ type InfinispanSecurity struct {
 	EndpointAuthentication *bool `json:"endpointAuthentication,omitempty"`
 	// +optional
 	EndpointSecretName string `json:"endpointSecretName,omitempty"`
+	// The backend providing the user identities, defaults to a Kubernetes Secret
+	// +optional
+	EndpointSecret *EndpointSecret `json:"endpointSecret,omitempty"`
 	// +optional
 	EndpointEncryption *EndpointEncryption `json:"endpointEncryption,omitempty"`
+	// Changing the value rotates the password of the operator user and of the users in the generated identities Secret.
+	// The new passwords are applied to the running pods without restarting them
+	// +optional
+	CredentialRotation string `json:"credentialRotation,omitempty"`
+	// Encrypts the JGroups traffic between the pods of the cluster
+	// +optional
+	TransportEncryption *TransportEncryption `json:"transportEncryption,omitempty"`
+	// The security realm authenticating the users of the endpoints instead of the identities Secret
+	// +optional
+	Realm *SecurityRealm `json:"realm,omitempty"`
+	// Creates a NetworkPolicy denying the ingress traffic of the pods, except from the other pods of the cluster, the
+	// operator namespace, the sources of networkPolicySources and, for the exposed endpoints, any source
+	// +optional
+	NetworkPolicy *bool `json:"networkPolicy,omitempty"`
+	// Additional sources allowed to connect to the endpoints of the pods when networkPolicy is true
+	// +optional
+	NetworkPolicySources []NetworkPolicySource `json:"networkPolicySources,omitempty"`
+	// The Pod Security Standard profile that the pods of the cluster comply with. Defaults to Default, which leaves the
+	// security context to the platform
+	// +optional
+	PodSecurityProfile PodSecurityProfile `json:"podSecurityProfile,omitempty"`
+}
+
+// PodSecurityProfile is the Pod Security Standard profile of the pods
+// +kubebuilder:validation:Enum=Default;Restricted
+type PodSecurityProfile string
+
+const (
+	// PodSecurityProfileDefault leaves the security context of the pods to the platform
+	PodSecurityProfileDefault PodSecurityProfile = "Default"
+	// PodSecurityProfileRestricted runs the containers as non-root with the RuntimeDefault seccomp profile, without
+	// capabilities and with a read-only root filesystem, complying with the restricted Pod Security Standard
+	PodSecurityProfileRestricted PodSecurityProfile = "Restricted"
+)
+
+// NetworkPolicySource selects the pods allowed to connect to the endpoints of the cluster
+type NetworkPolicySource struct {
+	// The labels of the pods allowed to connect. All the pods of the selected namespaces are allowed when not set
+	// +optional
+	PodSelector map[string]string `json:"podSelector,omitempty"`
+	// The labels of the namespaces of the pods allowed to connect. Only the pods of the cluster namespace are allowed
+	// when not set
+	// +optional
+	NamespaceSelector map[string]string `json:"namespaceSelector,omitempty"`
+}
+
+// SecurityRealm configures the backend authenticating the users of the endpoints. The operator keeps authenticating
+// with its own identities on the admin endpoint
+type SecurityRealm struct {
+	// Authenticates the users against an LDAP server
+	// +optional
+	Ldap *LdapRealm `json:"ldap,omitempty"`
+	// Authenticates the clients with OAuth2 bearer tokens, validated with the token introspection endpoint of the
+	// identity provider. Cannot be combined with ldap
+	// +optional
+	OAuth2 *OAuth2Realm `json:"oauth2,omitempty"`
+}
+
+// OAuth2Realm authenticates the bearer tokens issued by an OAuth2 or OpenID Connect identity provider, e.g. Keycloak
+type OAuth2Realm struct {
+	// The URL of the issuer of the tokens, e.g. https://keycloak.example.org/realms/infinispan
+	// +kubebuilder:validation:Pattern=`^https?://`
+	IssuerURL string `json:"issuerUrl"`
+	// The Secret with the client ID, in the clientId key, and the client secret, in the clientSecret key, that the
+	// server authenticates with on the introspection endpoint
+	ClientSecretName string `json:"clientSecretName"`
+	// The audience that the tokens must be issued for, the audience is not verified when not specified
+	// +optional
+	Audience string `json:"audience,omitempty"`
+	// The token introspection endpoint. Defaults to the introspection_endpoint of the OpenID Connect discovery document
+	// of the issuer
+	// +optional
+	IntrospectionURL string `json:"introspectionUrl,omitempty"`
+	// The claim of the token used as the name of the user. Defaults to preferred_username
+	// +optional
+	PrincipalClaim string `json:"principalClaim,omitempty"`
+	// The Secret with the CA certificate, in the ca.crt key, verifying the certificate of the identity provider. The
+	// system CAs are used when not specified
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// LdapRealm authenticates the users against an LDAP server and maps their groups to roles
+type LdapRealm struct {
+	// The URL of the LDAP server, e.g. ldaps://ldap.example.org:636
+	// +kubebuilder:validation:Pattern=`^ldaps?://`
+	URL string `json:"url"`
+	// The Secret with the DN, in the username key, and the password, in the password key, that the server binds with
+	// to search the users
+	BindSecretName string `json:"bindSecretName"`
+	// The base DN the users are searched in
+	SearchDN string `json:"searchDn"`
+	// The filter matching the entry of a user, {0} being replaced by the username. Defaults to (uid={0})
+	// +optional
+	UserFilter string `json:"userFilter,omitempty"`
+	// The base DN the groups of the users are searched in, the groups are not mapped to roles when not specified
+	// +optional
+	GroupSearchDN string `json:"groupSearchDn,omitempty"`
+	// The filter matching the groups of a user, {1} being replaced by the DN of the user. Defaults to (member={1})
+	// +optional
+	GroupFilter string `json:"groupFilter,omitempty"`
+	// The attribute of the group entries mapped to a role. Defaults to cn
+	// +optional
+	GroupAttribute string `json:"groupAttribute,omitempty"`
+	// Searches the users and groups in the whole subtree of the base DNs instead of their direct children
+	// +optional
+	SearchRecursive bool `json:"searchRecursive,omitempty"`
+	// The Secret with the CA certificate, in the ca.crt key, verifying the certificate of an ldaps:// server. The
+	// system CAs are used when not specified
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
 }
 
+// TransportEncryptionType specifies the JGroups protocol encrypting the cluster traffic
+// +kubebuilder:validation:Enum=ASYM_ENCRYPT;SYM_ENCRYPT
+type TransportEncryptionType string
+
+const (
+	// TransportEncryptionAsym shares the secret key generated by the coordinator with the members authenticated by the keystore
+	TransportEncryptionAsym TransportEncryptionType = "ASYM_ENCRYPT"
+	// TransportEncryptionSym encrypts the traffic with the secret key held in the keystore
+	TransportEncryptionSym TransportEncryptionType = "SYM_ENCRYPT"
+)
+
+// TransportEncryption configures the encryption of the JGroups traffic between the pods of the cluster
+type TransportEncryption struct {
+	// Defaults to ASYM_ENCRYPT
+	// +optional
+	Type TransportEncryptionType `json:"type,omitempty"`
+	// The Secret holding the keystore, required for SYM_ENCRYPT. A keystore is generated by the operator for
+	// ASYM_ENCRYPT when not provided
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// EndpointSecretSourceType specifies all the possible sources of the user identities
+// +kubebuilder:validation:Enum=Secret;Vault;ExternalSecret
+type EndpointSecretSourceType string
+
+const (
+	// EndpointSecretSourceSecret identities held in the Secret endpointSecretName, generated when not provided
+	EndpointSecretSourceSecret EndpointSecretSourceType = "Secret"
+	// EndpointSecretSourceVault identities rendered into the server pods from HashiCorp Vault
+	EndpointSecretSourceVault EndpointSecretSourceType = "Vault"
+	// EndpointSecretSourceExternalSecret identities synchronised into a Secret by the external-secrets operator
+	EndpointSecretSourceExternalSecret EndpointSecretSourceType = "ExternalSecret"
+)
+
+// EndpointSecret configures the backend providing the user identities
+type EndpointSecret struct {
+	// +optional
+	Source EndpointSecretSourceType `json:"source,omitempty"`
+	// Required when source is Vault
+	// +optional
+	Vault *VaultSecretSource `json:"vault,omitempty"`
+	// Required when source is ExternalSecret
+	// +optional
+	ExternalSecret *ExternalSecretSource `json:"externalSecret,omitempty"`
+}
+
+// VaultSecretSource reads the identities.yaml content from a Vault secret, either through the Vault agent injector or
+// the Secrets Store CSI driver
+type VaultSecretSource struct {
+	// The Vault role used by the agent to authenticate the pods
+	// +optional
+	Role string `json:"role,omitempty"`
+	// The path of the Vault KV version 2 secret, e.g. secret/data/infinispan
+	// +optional
+	Path string `json:"path,omitempty"`
+	// The key of the Vault secret holding the identities.yaml content, defaults to identities.yaml
+	// +optional
+	Key string `json:"key,omitempty"`
+	// The SecretProviderClass mounting the identities with the Secrets Store CSI driver instead of the Vault agent.
+	// It must provide an object named identities.yaml
+	// +optional
+	SecretProviderClass string `json:"secretProviderClass,omitempty"`
+}
+
+// ExternalSecretSource references the ExternalSecret that synchronises the identities into a Kubernetes Secret
+type ExternalSecretSource struct {
+	// The name of the ExternalSecret. Its target Secret is expected to have the same name unless endpointSecretName is set
+	Name string `json:"name"`
+}
+
+// Authorization configures the role based access control of the cluster
 type Authorization struct {
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
+	// Custom roles defined in addition to the default roles. Caches grant access to them in their authorization configuration.
+	// Ignored while authorization is disabled
 	// +optional
 	Roles []AuthorizationRole `json:"roles,omitempty"`
 }
 
+// AuthorizationRole associates a role with the permissions it grants
 type AuthorizationRole struct {
-	Name        string   `json:"name"`
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_-]+$`
+	Name string `json:"name"`
+	// One or more of ALL, ALL_READ, ALL_WRITE, LIFECYCLE, READ, WRITE, EXEC, LISTEN, BULK_READ, BULK_WRITE, ADMIN, CREATE, MONITOR, NONE
+	// +kubebuilder:validation:MinItems=1
 	Permissions []string `json:"permissions"`
 }
 
@@ -76,16 +268,98 @@ type EndpointEncryption struct {
 	ClientCert ClientCertType `json:"clientCert,omitempty"`
 	// +optional
 	ClientCertSecretName string `json:"clientCertSecretName,omitempty"`
+	// Issues the certificate with cert-manager into certSecretName. Renewed certificates are reloaded by the servers
+	// without restarting the pods
+	// +optional
+	CertManager *CertManagerSpec `json:"certManager,omitempty"`
+}
+
+// CertManagerSpec configures the cert-manager Certificate of the endpoints
+type CertManagerSpec struct {
+	// The cert-manager issuer signing the certificate
+	IssuerRef CertManagerIssuerRef `json:"issuerRef"`
+	// DNS names added to the names of the cluster Services, e.g. the hosts the endpoints are exposed with
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+}
+
+// CertManagerIssuerRef references a cert-manager Issuer or ClusterIssuer
+type CertManagerIssuerRef struct {
+	Name string `json:"name"`
+	// Defaults to Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	// Defaults to cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
 }
 
+// InfinispanStorageType specifies whether the data of the pods outlives them
+// +kubebuilder:validation:Enum=persistent;ephemeral
+type InfinispanStorageType string
+
+const (
+	// StoragePersistent stores the data of each pod in a PersistentVolumeClaim
+	StoragePersistent InfinispanStorageType = "persistent"
+	// StorageEphemeral stores the data of each pod in an emptyDir volume, which is lost when the pod restarts
+	StorageEphemeral InfinispanStorageType = "ephemeral"
+)
+
 // InfinispanServiceContainerSpec resource requirements specific for service
 type InfinispanServiceContainerSpec struct {
+	// Size of the PersistentVolumeClaims, or the size limit of the emptyDir volumes with ephemeral storage
 	// +optional
 	Storage *string `json:"storage,omitempty"`
+	// Deprecated, use storageType=ephemeral instead
 	// +optional
 	EphemeralStorage bool `json:"ephemeralStorage,omitempty"`
+	// Type of storage of the pod data. Defaults to persistent
+	// +optional
+	StorageType InfinispanStorageType `json:"storageType,omitempty"`
 	// +optional
 	StorageClassName string `json:"storageClassName,omitempty"`
+	// +optional
+	Probes *InfinispanProbesSpec `json:"probes,omitempty"`
+	// Adds a readiness gate to the pods so that they only receive traffic from the Services once they have joined a
+	// cluster view with all the pods and the initial state transfer has completed
+	// +optional
+	ReadinessGate bool `json:"readinessGate,omitempty"`
+	// Percentage of the JVM heap of the server used as the memory max-size of the caches created by Cache CRs without
+	// memory bounds, so that the eviction of the entries prevents the pods from being killed for running out of memory
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	DefaultEvictionPercentage int32 `json:"defaultEvictionPercentage,omitempty"`
+	// Number of zero-capacity pods joining the cluster in addition to spec.replicas. Zero-capacity pods don't own any
+	// cache entry, they run the server tasks and the backups without storing data
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ZeroCapacityNodes int32 `json:"zeroCapacityNodes,omitempty"`
+}
+
+// InfinispanProbesSpec overrides the default thresholds of the Infinispan container probes
+type InfinispanProbesSpec struct {
+	// +optional
+	Liveness *InfinispanProbeSpec `json:"liveness,omitempty"`
+	// +optional
+	Readiness *InfinispanProbeSpec `json:"readiness,omitempty"`
+	// Startup probe thresholds. Increase the failureThreshold of clusters that take longer than 10 minutes to recover
+	// persisted data on startup
+	// +optional
+	Startup *InfinispanProbeSpec `json:"startup,omitempty"`
+}
+
+// InfinispanProbeSpec probe thresholds, unset fields keep the operator defaults
+type InfinispanProbeSpec struct {
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=DataGrid;Cache
@@ -115,27 +389,162 @@ type InfinispanServiceSpec struct {
 	Sites *InfinispanSitesSpec `json:"sites,omitempty"`
 	// +optional
 	ReplicationFactor int32 `json:"replicationFactor,omitempty"`
+	// How the caches created by the operator for a DataGrid service behave when the cluster splits and merges
+	// +optional
+	PartitionHandling *InfinispanPartitionHandlingSpec `json:"partitionHandling,omitempty"`
+}
+
+// WhenSplitType specifies how a cache behaves when the cluster splits into partitions
+// +kubebuilder:validation:Enum=ALLOW_READ_WRITES;ALLOW_READS;DENY_READ_WRITES
+type WhenSplitType string
+
+const (
+	// WhenSplitAllowReadWrites keeps the cache available in every partition, the entries may diverge
+	WhenSplitAllowReadWrites WhenSplitType = "ALLOW_READ_WRITES"
+	// WhenSplitAllowReads only allows reading the entries whose owners are all in the partition
+	WhenSplitAllowReads WhenSplitType = "ALLOW_READS"
+	// WhenSplitDenyReadWrites only allows reading and writing the entries whose owners are all in the partition
+	WhenSplitDenyReadWrites WhenSplitType = "DENY_READ_WRITES"
+)
+
+// MergePolicyType specifies how the conflicting entries are resolved when the partitions merge
+// +kubebuilder:validation:Enum=NONE;PREFERRED_ALWAYS;PREFERRED_NON_NULL;REMOVE_ALL
+type MergePolicyType string
+
+const (
+	// MergePolicyNone doesn't resolve the conflicts
+	MergePolicyNone MergePolicyType = "NONE"
+	// MergePolicyPreferredAlways keeps the entry of the preferred partition
+	MergePolicyPreferredAlways MergePolicyType = "PREFERRED_ALWAYS"
+	// MergePolicyPreferredNonNull keeps the entry of the preferred partition, or a non-null entry of another partition
+	MergePolicyPreferredNonNull MergePolicyType = "PREFERRED_NON_NULL"
+	// MergePolicyRemoveAll removes the conflicting entries
+	MergePolicyRemoveAll MergePolicyType = "REMOVE_ALL"
+)
+
+// InfinispanPartitionHandlingSpec configures the partition handling of the caches created by the operator
+type InfinispanPartitionHandlingSpec struct {
+	// How the caches behave when the cluster splits, defaults to ALLOW_READ_WRITES
+	// +optional
+	WhenSplit WhenSplitType `json:"whenSplit,omitempty"`
+	// How the conflicting entries are resolved when the partitions merge, defaults to REMOVE_ALL
+	// +optional
+	MergePolicy MergePolicyType `json:"mergePolicy,omitempty"`
 }
 
 // InfinispanContainerSpec specify resource requirements per container
 type InfinispanContainerSpec struct {
 	// +optional
 	ExtraJvmOpts string `json:"extraJvmOpts,omitempty"`
+	// JVM arguments appended to extraJvmOpts, one argument per entry
+	// +optional
+	JvmArgs []string `json:"jvmArgs,omitempty"`
+	// Environment variables added to the Infinispan container. Variables managed by the operator cannot be overridden
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
 	// +optional
 	Memory string `json:"memory,omitempty"`
 	// +optional
 	CPU string `json:"cpu,omitempty"`
+	// How the JVM memory of the server is sized, defaults to Manual
+	// +optional
+	MemoryPolicy MemoryPolicyType `json:"memoryPolicy,omitempty"`
+	// The percentage of the container memory left to the JVM native memory when memoryPolicy is Auto, defaults to 25
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=90
+	// +optional
+	MemoryHeadroomPercent int32 `json:"memoryHeadroomPercent,omitempty"`
 }
 
+// MemoryPolicyType specifies how the JVM memory of the server is sized
+// +kubebuilder:validation:Enum=Manual;Auto
+type MemoryPolicyType string
+
+const (
+	// MemoryPolicyManual the JVM memory is sized by the server image and the extra JVM options
+	MemoryPolicyManual MemoryPolicyType = "Manual"
+	// MemoryPolicyAuto the operator sizes the heap and the off-heap memory of the JVM from the container memory
+	MemoryPolicyAuto MemoryPolicyType = "Auto"
+)
+
 type InfinispanSitesLocalSpec struct {
 	Name   string              `json:"name"`
 	Expose CrossSiteExposeSpec `json:"expose"`
 	// +optional
 	MaxRelayNodes int32 `json:"maxRelayNodes,omitempty"`
+	// Tuning of the state transfer of the caches to the remote sites
+	// +optional
+	StateTransfer *CrossSiteStateTransferSpec `json:"stateTransfer,omitempty"`
+	// The Gossip Router deployed by the operator for the cross-site tunnel
+	// +optional
+	GossipRouter *GossipRouterSpec `json:"gossipRouter,omitempty"`
+}
+
+// GossipRouterSpec configures the Gossip Router Deployment the relay nodes of the remote sites connect to
+type GossipRouterSpec struct {
+	// Number of Gossip Router pods, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Memory of the Gossip Router container, requested and limited
+	// +optional
+	Memory string `json:"memory,omitempty"`
+	// CPU of the Gossip Router container, requested and limited
+	// +optional
+	CPU string `json:"cpu,omitempty"`
+	// Secures the connections of the relay nodes to the Gossip Router with TLS
+	// +optional
+	TLS *GossipRouterTLSSpec `json:"tls,omitempty"`
+}
+
+// GossipRouterTLSSpec configures the keystore shared by the Gossip Router and the relay nodes
+type GossipRouterTLSSpec struct {
+	// The Secret with the keystore, in a keystore.p12 or keystore.jks key, and its password, in a password key. The
+	// keystore must also trust the certificates of the Gossip Routers of the remote sites
+	SecretName string `json:"secretName"`
+	// The TLS protocol of the connections, defaults to TLSv1.3
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// CrossSiteStateTransferMode specifies whether the state of the caches is pushed to a remote site when it comes back online
+// +kubebuilder:validation:Enum=AUTO;MANUAL
+type CrossSiteStateTransferMode string
+
+const (
+	// CrossSiteStateTransferAuto pushes the state of the caches as soon as a remote site is back online
+	CrossSiteStateTransferAuto CrossSiteStateTransferMode = "AUTO"
+	// CrossSiteStateTransferManual only pushes the state of the caches when requested
+	CrossSiteStateTransferManual CrossSiteStateTransferMode = "MANUAL"
+)
+
+// CrossSiteStateTransferSpec tunes the transfer of the cache entries to the remote sites. The server defaults are used
+// for the fields that aren't set
+type CrossSiteStateTransferSpec struct {
+	// Number of cache entries sent in each batch
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ChunkSize *int32 `json:"chunkSize,omitempty"`
+	// Seconds to wait for the remote site to apply a batch
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// Number of times a failed batch is sent again before the state transfer fails
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+	// Whether the state is pushed automatically when a remote site comes back online, or only when requested with the
+	// infinispan.org/xsiteStateTransferAt annotation. Defaults to MANUAL
+	// +optional
+	Mode CrossSiteStateTransferMode `json:"mode,omitempty"`
 }
 
 type InfinispanSiteLocationSpec struct {
 	Name string `json:"name"`
+	// The cluster scoped InfinispanSite describing the connection to the remote site. The fields of the location take
+	// precedence over the ones of the InfinispanSite
+	// +optional
+	Site string `json:"site,omitempty"`
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
 	// +optional
@@ -171,11 +580,34 @@ const (
 )
 
 type InfinispanLoggingSpec struct {
+	// Levels of the server log categories. Changes are applied to the running pods without restarting them
 	Categories map[string]LoggingLevelType `json:"categories,omitempty"`
+	// Log4j pattern of the console log output. Changing the log output format restarts the pods
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+	// Writes the console log output in JSON format. Cannot be combined with pattern
+	// +optional
+	JSON bool `json:"json,omitempty"`
+	// Scans the server logs for errors, which are reported as Events and with the ServerAlert condition
+	// +optional
+	Alerts *InfinispanLogAlertsSpec `json:"alerts,omitempty"`
+}
+
+// InfinispanLogAlertsSpec configures the errors of the server logs reported as alerts. Out of memory errors, split
+// brains and persistence failures are always reported
+type InfinispanLogAlertsSpec struct {
+	// The period of the logs scanned for errors. The ServerAlert condition is cleared when no error has been logged
+	// during the period. Defaults to 300 seconds
+	// +kubebuilder:validation:Minimum=60
+	// +optional
+	WindowSeconds *int32 `json:"windowSeconds,omitempty"`
+	// Additional regular expressions matched against the ERROR and FATAL lines of the server logs
+	// +optional
+	Patterns []string `json:"patterns,omitempty"`
 }
 
 // ExposeType describe different exposition methods for Infinispan
-// +kubebuilder:validation:Enum=NodePort;LoadBalancer;Route
+// +kubebuilder:validation:Enum=NodePort;LoadBalancer;Route;GatewayRoute
 type ExposeType string
 
 const (
@@ -191,6 +623,10 @@ const (
 	// ExposeTypeRoute means the service will be exposed via
 	// `Route` on Openshift or via `Ingress` on Kubernetes
 	ExposeTypeRoute ExposeType = "Route"
+
+	// ExposeTypeGatewayRoute means the service will be exposed via a Gateway API
+	// `TLSRoute` with SNI passthrough, endpoint encryption is required
+	ExposeTypeGatewayRoute ExposeType = "GatewayRoute"
 )
 
 // CrossSiteExposeType describe different exposition methods for Infinispan Cross-Site service
@@ -223,7 +659,96 @@ const (
 
 // ExposeSpec describe how Infinispan will be exposed externally
 type ExposeSpec struct {
-	// Type specifies different exposition methods for data grid
+	// Type specifies different exposition methods for data grid. The endpoints are not exposed when the type is not
+	// specified, e.g. to only expose the console
+	// +optional
+	Type ExposeType `json:"type,omitempty"`
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// +optional
+	Host string `json:"host,omitempty"`
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// The client CIDRs allowed to access a LoadBalancer, when supported by the cloud provider
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+	// The Gateway the routes attach to, required for GatewayRoute. The Gateway must provide a TLS listener in passthrough mode
+	// +optional
+	Gateway *GatewayParentReference `json:"gateway,omitempty"`
+	// Exposes each endpoint protocol with a distinct Service, Route or Ingress instead of the single port endpoint
+	// +optional
+	PerEndpoint bool `json:"perEndpoint,omitempty"`
+	// How the HotRod endpoint is exposed when perEndpoint is true, defaults to the type and annotations of this spec
+	// +optional
+	HotRod *EndpointExposeSpec `json:"hotrod,omitempty"`
+	// How the REST endpoint is exposed when perEndpoint is true, defaults to the type and annotations of this spec
+	// +optional
+	Rest *EndpointExposeSpec `json:"rest,omitempty"`
+	// How the Memcached endpoint is exposed when perEndpoint is true. The Memcached endpoint is only enabled when exposed
+	// +optional
+	Memcached *EndpointExposeSpec `json:"memcached,omitempty"`
+	// Creates the <name>-read-only Service, which only targets the pods that have completed the state transfer of the
+	// caches, so that read traffic can be directed separately from writes
+	// +optional
+	ReadOnlyService bool `json:"readOnlyService,omitempty"`
+	// Exposes the console with a dedicated Route, or Ingress, on its own host
+	// +optional
+	Console *ConsoleExposeSpec `json:"console,omitempty"`
+}
+
+// ConsoleExposeSpec describes how the console is exposed externally
+type ConsoleExposeSpec struct {
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Where TLS is terminated for the Route. Defaults to passthrough when endpoint encryption is enabled, and to edge
+	// with OIDC authentication
+	// +optional
+	TLSTermination RouteTLSTermination `json:"tlsTermination,omitempty"`
+	// Authenticates the console users with an OpenID Connect provider, with a proxy sidecar in front of the console
+	// +optional
+	OIDC *ConsoleOIDCSpec `json:"oidc,omitempty"`
+}
+
+// ConsoleOIDCSpec configures the OpenID Connect authentication of the console users
+type ConsoleOIDCSpec struct {
+	// The URL of the OpenID Connect issuer
+	IssuerURL string `json:"issuerUrl"`
+	// Name of the Secret holding the clientId and clientSecret of the OIDC client, and the cookieSecret the proxy encrypts
+	// its cookies with
+	ClientSecretName string `json:"clientSecretName"`
+	// Restricts the authenticated users to the email domains, all the users are allowed by default
+	// +optional
+	EmailDomains []string `json:"emailDomains,omitempty"`
+}
+
+// ExposeEndpoint identifies an endpoint protocol exposed by its own Service, Route or Ingress
+type ExposeEndpoint string
+
+const (
+	ExposeEndpointHotRod    ExposeEndpoint = "hotrod"
+	ExposeEndpointRest      ExposeEndpoint = "rest"
+	ExposeEndpointMemcached ExposeEndpoint = "memcached"
+)
+
+// RouteTLSTermination describes where TLS is terminated for an endpoint exposed by a Route or Ingress
+// +kubebuilder:validation:Enum=passthrough;edge;reencrypt
+type RouteTLSTermination string
+
+const (
+	// RouteTLSTerminationPassthrough passes the encrypted traffic through to the server, requires endpoint encryption
+	RouteTLSTerminationPassthrough RouteTLSTermination = "passthrough"
+	// RouteTLSTerminationEdge terminates TLS at the router, requires endpoint encryption to be disabled
+	RouteTLSTerminationEdge RouteTLSTermination = "edge"
+	// RouteTLSTerminationReencrypt terminates TLS at the router and encrypts the traffic to the server again,
+	// requires endpoint encryption
+	RouteTLSTerminationReencrypt RouteTLSTermination = "reencrypt"
+)
+
+// EndpointExposeSpec describes how a single endpoint protocol will be exposed externally
+type EndpointExposeSpec struct {
+	// Type specifies different exposition methods for the endpoint, GatewayRoute is not supported
 	Type ExposeType `json:"type"`
 	// +optional
 	NodePort int32 `json:"nodePort,omitempty"`
@@ -233,6 +758,24 @@ type ExposeSpec struct {
 	Host string `json:"host,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// The client CIDRs allowed to access a LoadBalancer, when supported by the cloud provider
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+	// Where TLS is terminated for Route, defaults to passthrough when endpoint encryption is enabled.
+	// Only the REST endpoint supports edge and reencrypt
+	// +optional
+	TLSTermination RouteTLSTermination `json:"tlsTermination,omitempty"`
+}
+
+// GatewayParentReference identifies the Gateway, and optionally its listener, that a route attaches to
+type GatewayParentReference struct {
+	Name string `json:"name"`
+	// Defaults to the namespace of the Infinispan
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// The name of the Gateway listener
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
 }
 
 // CrossSiteExposeSpec describe how Infinispan Cross-Site service will be exposed externally
@@ -245,14 +788,31 @@ type CrossSiteExposeSpec struct {
 	Port int32 `json:"port,omitempty"`
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// Host name advertised to the remote sites as the address of the local site, e.g. a DNS record or a Route
+	// pointing to the x-site service. It replaces the address resolved from the x-site service and must resolve
+	// +optional
+	RouteHostName string `json:"routeHostName,omitempty"`
+	// Static IP address requested from the cloud provider for the load balancer of the x-site service. Only
+	// supported with the LoadBalancer type
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
 }
 
 // Autoscale describe autoscaling configuration for the cluster
 type Autoscale struct {
-	MaxReplicas        int32 `json:"maxReplicas"`
-	MinReplicas        int32 `json:"minReplicas"`
-	MaxMemUsagePercent int   `json:"maxMemUsagePercent"`
-	MinMemUsagePercent int   `json:"minMemUsagePercent"`
+	// Maximum number of pods the cluster can be scaled up to
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Minimum number of pods the cluster can be scaled down to
+	MinReplicas int32 `json:"minReplicas"`
+	// Data memory usage percentage above which the cluster is scaled up
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MaxMemUsagePercent int `json:"maxMemUsagePercent"`
+	// Data memory usage percentage below which the cluster is scaled down
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinMemUsagePercent int `json:"minMemUsagePercent"`
+	// Suspends autoscaling without removing the configuration
 	// +optional
 	Disabled bool `json:"disabled,omitempty"`
 }
@@ -265,6 +825,15 @@ type InfinispanExternalDependencies struct {
 	VolumeClaimName string `json:"volumeClaimName,omitempty"`
 	// +optional
 	Artifacts []InfinispanExternalArtifacts `json:"artifacts,omitempty"`
+	// Init containers run before the server. Files written to /opt/infinispan/server/lib/init-containers, mounted in
+	// each init container, are added to the server classpath
+	// +optional
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+	// URL of the Maven repository that the artifacts defined by Maven coordinates are downloaded from. Defaults to
+	// Maven Central
+	// +optional
+	// +kubebuilder:validation:Pattern=`^https?://[-a-zA-Z0-9+&@#/%?=~_|!:,.;]*[-a-zA-Z0-9+&@#/%=~_|]`
+	MavenRepository string `json:"mavenRepository,omitempty"`
 }
 
 // ExternalArtifactType defines external artifact file type
@@ -278,9 +847,14 @@ const (
 )
 
 type InfinispanExternalArtifacts struct {
+	// +optional
 	// +kubebuilder:validation:Pattern=`^(https?|ftp)://[-a-zA-Z0-9+&@#/%?=~_|!:,.;]*[-a-zA-Z0-9+&@#/%=~_|]`
-	// URL of the file you want to download.
-	Url string `json:"url"`
+	// URL of the file you want to download. Exactly one of url or maven must be specified.
+	Url string `json:"url,omitempty"`
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[^:\s]+:[^:\s]+(:[^:\s]+){1,3}$`
+	// Maven coordinates of the artifact you want to download, in the groupId:artifactId[:packaging[:classifier]]:version format.
+	Maven string `json:"maven,omitempty"`
 	// +optional
 	// Specifies the type of file you want to download. If not specified, the file type is automatically determined from the extension.
 	Type ExternalArtifactType `json:"type,omitempty"`
@@ -290,6 +864,24 @@ type InfinispanExternalArtifacts struct {
 	Hash string `json:"hash,omitempty"`
 }
 
+// InfinispanTracingSpec configures the export of the traces to an OpenTelemetry collector
+type InfinispanTracingSpec struct {
+	// The OTLP/HTTP endpoint of the collector, e.g. http://otel-collector.observability.svc:4318
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+	// The ratio of the traces that are sampled, between 0 and 1. Defaults to 1
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	// +optional
+	SamplingRatio string `json:"samplingRatio,omitempty"`
+	// The service name of the server spans. Defaults to the name of the Infinispan CR
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+	// The Secret with the CA certificate, in the ca.crt key, verifying the certificate of a https:// collector. The
+	// system CAs are used when not specified
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
 // InfinispanCloudEvents describes how Infinispan is connected with Cloud Event, see Kafka docs for more info
 type InfinispanCloudEvents struct {
 	// BootstrapServers is comma separated list of boostrap server:port addresses
@@ -302,11 +894,182 @@ type InfinispanCloudEvents struct {
 	CacheEntriesTopic string `json:"cacheEntriesTopic,omitempty"`
 }
 
+// UpgradeType defines how the cluster is upgraded when the operator default image changes
+// +kubebuilder:validation:Enum=Shutdown;DataMigration;Canary
+type UpgradeType string
+
+const (
+	// UpgradeTypeShutdown gracefully shuts down the cluster and restarts it with the new image
+	UpgradeTypeShutdown UpgradeType = "Shutdown"
+	// UpgradeTypeDataMigration starts a staging cluster with the new image and migrates the caches to it through remote
+	// stores, verifying the number of entries of the persisted caches, before the old StatefulSet and its volumes are
+	// retired. The upgraded cluster then migrates the caches back from the staging cluster, which is removed
+	UpgradeTypeDataMigration UpgradeType = "DataMigration"
+	// UpgradeTypeCanary upgrades the pods one at a time with the StatefulSet rolling update partition, waiting for the
+	// cluster to be healthy and rebalanced after each pod. The upgraded pods are rolled back when the cluster isn't
+	// healthy within the canary timeout
+	UpgradeTypeCanary UpgradeType = "Canary"
+)
+
+// InfinispanUpgradesSpec defines the upgrade strategy of the cluster
+type InfinispanUpgradesSpec struct {
+	Type UpgradeType `json:"type"`
+	// The time a Canary upgrade waits for the cluster to be healthy after upgrading a pod, before rolling back the
+	// upgrade. Defaults to 600 seconds
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	CanaryTimeoutSeconds *int32 `json:"canaryTimeoutSeconds,omitempty"`
+}
+
+// CanaryUpgradeStatus defines the progress of a Canary upgrade
+type CanaryUpgradeStatus struct {
+	// The image of the pods before the upgrade
+	SourceImage string `json:"sourceImage"`
+	// The operator default image of the pods before the upgrade
+	SourceDefaultImage string `json:"sourceDefaultImage"`
+	// The operator default image the pods are upgraded to
+	TargetDefaultImage string `json:"targetDefaultImage"`
+	// The pods with an ordinal greater or equal to the partition are upgraded
+	Partition int32 `json:"partition"`
+	// When the partition was last lowered
+	PartitionTime metav1.Time `json:"partitionTime"`
+	// True if the upgrade was rolled back because the cluster wasn't healthy within the canary timeout
+	// +optional
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+// RebalanceStatus defines the progress of the redistribution of the cache entries across the pods, after a change of
+// the cluster topology
+type RebalanceStatus struct {
+	// Hash of the cluster members the rebalance was last checked for
+	Topology string `json:"topology"`
+	// When the caches started rebalancing
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// The caches that have been rebalancing since the start time
+	// +optional
+	Caches []string `json:"caches,omitempty"`
+	// Percentage of the caches that have completed rebalancing
+	Progress int32 `json:"progress"`
+}
+
+// OperationType identifies a long-running server operation of the cluster
+// +kubebuilder:validation:Enum=GracefulShutdown;XSiteStateTransfer
+type OperationType string
+
+const (
+	OperationGracefulShutdown   OperationType = "GracefulShutdown"
+	OperationXSiteStateTransfer OperationType = "XSiteStateTransfer"
+)
+
+// OperationPhase is the state of a long-running server operation
+type OperationPhase string
+
+const (
+	// OperationRunning means that the operation is queued or executing
+	OperationRunning OperationPhase = "Running"
+	// OperationSucceeded means that the server completed the operation
+	OperationSucceeded OperationPhase = "Succeeded"
+	// OperationFailed means that the server failed the operation, which isn't retried
+	OperationFailed OperationPhase = "Failed"
+)
+
+// OperationStatus tracks a long-running server operation, which is executed in the background so that the
+// reconciliation of the other clusters isn't blocked while it runs
+type OperationStatus struct {
+	Type  OperationType  `json:"type"`
+	Phase OperationPhase `json:"phase"`
+	// Identifies the request of the operation, e.g. the requested timestamp of a cross-site state transfer
+	// +optional
+	ID string `json:"id,omitempty"`
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// The error of a failed operation
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// InfinispanMonitoringSpec defines the monitoring resources created for the cluster
+type InfinispanMonitoringSpec struct {
+	// Create a ServiceMonitor scraping the cluster metrics, when the Prometheus operator is installed. Takes precedence
+	// over the infinispan.org/monitoring annotation
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Create a GrafanaDashboard with the JVM, cache and cluster metrics of the cluster. Requires the Grafana operator
+	// +optional
+	Dashboards bool `json:"dashboards,omitempty"`
+	// Configures the authentication and encryption of the metrics endpoint scraped by the ServiceMonitor. By default
+	// the metrics endpoint shares the configuration of the admin endpoint
+	// +optional
+	Security *InfinispanMonitoringSecuritySpec `json:"security,omitempty"`
+	// Namespace of the Prometheus instance scraping the ServiceMonitor, allowed to reach the metrics endpoint by the
+	// NetworkPolicy. Defaults to openshift-user-workload-monitoring on OpenShift and to monitoring otherwise
+	// +optional
+	PrometheusNamespace string `json:"prometheusNamespace,omitempty"`
+}
+
+// InfinispanMonitoringSecuritySpec configures the access to the metrics endpoint
+type InfinispanMonitoringSecuritySpec struct {
+	// Require the operator admin credentials to read the metrics, enabled by default. When disabled the metrics can be
+	// read without credentials, the rest of the admin endpoint still requires them
+	// +optional
+	Authentication *bool `json:"authentication,omitempty"`
+	// Name of a Secret with the tls.crt and tls.key keys used to serve the metrics over TLS. The ServiceMonitor verifies
+	// the server certificate with the ca.crt key of the Secret, or with tls.crt when the Secret has no ca.crt key
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=MaxUnavailable;Quorum
+type PodDisruptionBudgetPolicy string
+
+const (
+	// PodDisruptionBudgetMaxUnavailable allows a single pod of the cluster to be disrupted at a time
+	PodDisruptionBudgetMaxUnavailable PodDisruptionBudgetPolicy = "MaxUnavailable"
+	// PodDisruptionBudgetQuorum keeps a majority of the cluster pods available, it requires at least 3 replicas
+	PodDisruptionBudgetQuorum PodDisruptionBudgetPolicy = "Quorum"
+)
+
+// InfinispanPodDisruptionBudgetSpec configures the PodDisruptionBudget created for the cluster
+type InfinispanPodDisruptionBudgetSpec struct {
+	// Create a PodDisruptionBudget for the cluster pods. Defaults to true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// Number of pods that must remain available during voluntary disruptions such as node drains. Defaults to MaxUnavailable
+	// +optional
+	Policy PodDisruptionBudgetPolicy `json:"policy,omitempty"`
+}
+
+// InfinispanSchedulingSpec defines how the cluster pods are scheduled and disrupted
+type InfinispanSchedulingSpec struct {
+	// +optional
+	PodDisruptionBudget *InfinispanPodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+	// Tolerations of the cluster pods, allowing them to be scheduled on tainted nodes such as dedicated node pools
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Labels of the nodes the cluster pods can be scheduled on
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Name of the PriorityClass of the cluster pods
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// Constraints that spread the cluster pods across topology domains such as zones or nodes. The label selector
+	// defaults to the pods of the cluster
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
 // InfinispanSpec defines the desired state of Infinispan
 type InfinispanSpec struct {
 	Replicas int32 `json:"replicas"`
 	// +optional
 	Image *string `json:"image,omitempty"`
+	// Version of the Infinispan server, one of the versions of the operand catalog of the operator. The default image of
+	// the operator is used when neither the version nor the image are specified. Changing the version upgrades the cluster
+	// +optional
+	Version string `json:"version,omitempty"`
 	// +optional
 	Security InfinispanSecurity `json:"security,omitempty"`
 	// +optional
@@ -326,8 +1089,105 @@ type InfinispanSpec struct {
 	// External dependencies needed by the Infinispan cluster
 	// +optional
 	Dependencies *InfinispanExternalDependencies `json:"dependencies,omitempty"`
+	// Containers run alongside the server in each pod, e.g. to export logs or metrics
+	// +optional
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+	// Strategy used to upgrade the cluster
+	// +optional
+	Upgrades *InfinispanUpgradesSpec `json:"upgrades,omitempty"`
+	// Monitoring resources created for the cluster
+	// +optional
+	Monitoring *InfinispanMonitoringSpec `json:"monitoring,omitempty"`
+	// Scheduling and disruption settings of the cluster pods
+	// +optional
+	Scheduling *InfinispanSchedulingSpec `json:"scheduling,omitempty"`
+	// IP family policy of the Services created for the cluster. Requires Kubernetes 1.20 or later
+	// +optional
+	IPFamilyPolicy IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+	// IP families of the Services created for the cluster, in order of preference. The cluster members communicate
+	// with the first family, which defaults to the IP family of the Kubernetes pod network
+	// +kubebuilder:validation:MaxItems=2
+	// +optional
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+	// Labels and annotations added to every resource created for the cluster
+	// +optional
+	Metadata *InfinispanMetadataSpec `json:"metadata,omitempty"`
+	// Server endpoints that are disabled by default
+	// +optional
+	Endpoints *InfinispanEndpointsSpec `json:"endpoints,omitempty"`
+	// Customizes the JGroups stack used by the pods to form the cluster
+	// +optional
+	JGroups *InfinispanJGroupsSpec `json:"jgroups,omitempty"`
+	// The name of a ConfigMap with a server configuration fragment, in the infinispan-config.yaml key, that is merged
+	// into the configuration generated by the operator. The values generated by the operator take precedence
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Exports the traces of the server, and of the operator reconciling the cluster, to an OpenTelemetry collector
+	// +optional
+	Tracing *InfinispanTracingSpec `json:"tracing,omitempty"`
+}
+
+// InfinispanEndpointsSpec enables the server endpoints that are disabled by default. Enabled endpoints are added to
+// the cluster Service
+type InfinispanEndpointsSpec struct {
+	// Enables the Memcached endpoint on port 11221. The Memcached endpoint doesn't authenticate clients
+	// +optional
+	Memcached bool `json:"memcached,omitempty"`
+	// Enables the RESP endpoint on port 6379, so that Redis clients can connect to the cluster
+	// +optional
+	Resp bool `json:"resp,omitempty"`
+}
+
+// JGroupsStackType specifies the JGroups transport stack of the cluster
+// +kubebuilder:validation:Enum=tcp;udp;tunnel
+type JGroupsStackType string
+
+const (
+	// JGroupsStackTCP the pods exchange messages over TCP connections
+	JGroupsStackTCP JGroupsStackType = "tcp"
+	// JGroupsStackUDP the pods exchange messages over UDP multicast, which must be supported by the pod network
+	JGroupsStackUDP JGroupsStackType = "udp"
+	// JGroupsStackTunnel the pods exchange messages through the configured Gossip routers
+	JGroupsStackTunnel JGroupsStackType = "tunnel"
+)
+
+// InfinispanJGroupsSpec customizes the JGroups stack of the cluster. Changes to the stack restart the pods
+type InfinispanJGroupsSpec struct {
+	// Defaults to tcp
+	// +optional
+	Stack JGroupsStackType `json:"stack,omitempty"`
+	// The Gossip routers used by the tunnel stack, in the host[port] format
+	// +optional
+	GossipRouters []string `json:"gossipRouters,omitempty"`
+	// Overrides the properties of the JGroups protocols, keyed by <PROTOCOL>.<property>. For example
+	// FD_ALL3.timeout, MERGE3.max_interval or TCP.thread_pool.max_threads
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// InfinispanMetadataSpec defines the labels and annotations propagated to the resources created for the cluster.
+// Keys removed from the spec are removed from the resources too. Labels and annotations set by the operator take
+// precedence
+type InfinispanMetadataSpec struct {
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// IPFamilyPolicyType specifies whether the Services created for the cluster are single-stack or dual-stack
+// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+type IPFamilyPolicyType string
+
+const (
+	// IPFamilyPolicySingleStack assigns the Services an IP of the first IP family
+	IPFamilyPolicySingleStack IPFamilyPolicyType = "SingleStack"
+	// IPFamilyPolicyPreferDualStack assigns the Services an IP of both IP families when the cluster is dual-stack
+	IPFamilyPolicyPreferDualStack IPFamilyPolicyType = "PreferDualStack"
+	// IPFamilyPolicyRequireDualStack assigns the Services an IP of both IP families, failing on single-stack clusters
+	IPFamilyPolicyRequireDualStack IPFamilyPolicyType = "RequireDualStack"
+)
+
 type ConditionType string
 
 const (
@@ -338,17 +1198,98 @@ const (
 	ConditionWellFormed          ConditionType = "WellFormed"
 	ConditionCrossSiteViewFormed ConditionType = "CrossSiteViewFormed"
 	ConditionGossipRouterReady   ConditionType = "GossipRouterReady"
+	ConditionDataMigrationFailed ConditionType = "DataMigrationFailed"
+	ConditionServerAlert         ConditionType = "ServerAlert"
+	ConditionEphemeralStorage    ConditionType = "EphemeralStorage"
+	ConditionConfigOverlayMerged ConditionType = "ConfigOverlayMerged"
+	ConditionSplitBrain          ConditionType = "SplitBrain"
+	ConditionLdapRealmBound      ConditionType = "LdapRealmBound"
+	ConditionOAuth2RealmReady    ConditionType = "OAuth2RealmReady"
+
+	ConditionCrossSiteConfigurationValid ConditionType = "CrossSiteConfigurationValid"
+	ConditionRebalancing                 ConditionType = "Rebalancing"
+
+	// ConditionReady, ConditionProgressing and ConditionDegraded summarise the other conditions of the cluster
+	ConditionReady       ConditionType = "Ready"
+	ConditionProgressing ConditionType = "Progressing"
+	ConditionDegraded    ConditionType = "Degraded"
+)
+
+// Reasons of the cluster conditions
+const (
+	ReasonPrelimChecksPassed     = "PreliminaryChecksPassed"
+	ReasonPrelimChecksFailed     = "PreliminaryChecksFailed"
+	ReasonGossipRouterReady      = "GossipRouterReady"
+	ReasonGossipRouterNotReady   = "GossipRouterNotReady"
+	ReasonUpgradeScheduled       = "UpgradeScheduled"
+	ReasonUpgradeCompleted       = "UpgradeCompleted"
+	ReasonCanaryUpgrade          = "CanaryUpgrade"
+	ReasonUpgradeRolledBack      = "UpgradeRolledBack"
+	ReasonPodsNotReady           = "PodsNotReady"
+	ReasonClusterFormed          = "ClusterFormed"
+	ReasonClusterNotFormed       = "ClusterNotFormed"
+	ReasonShutdownRequested      = "ShutdownRequested"
+	ReasonShutdownFailed         = "ShutdownFailed"
+	ReasonClusterStopped         = "ClusterStopped"
+	ReasonClusterResumed         = "ClusterResumed"
+	ReasonClusterRecovering      = "ClusterRecovering"
+	ReasonCrossSiteViewFormed    = "CrossSiteViewFormed"
+	ReasonCrossSiteViewNotFormed = "CrossSiteViewNotFormed"
+	ReasonCrossSiteViewError     = "CrossSiteViewError"
+	ReasonDataMigrationFailed    = "DataMigrationFailed"
+	ReasonNoServerAlert          = "NoServerAlert"
+	ReasonOutOfMemory            = "OutOfMemory"
+	ReasonSplitBrain             = "SplitBrain"
+	ReasonSingleView             = "SingleView"
+	ReasonPersistenceFailure     = "PersistenceFailure"
+	ReasonServerError            = "ServerError"
+	ReasonDataNotDurable         = "DataNotDurable"
+	ReasonConfigOverlayMerged    = "ConfigOverlayMerged"
+	ReasonConfigOverlayConflict  = "ConfigOverlayConflict"
+	ReasonConfigOverlayInvalid   = "ConfigOverlayInvalid"
+	ReasonClusterReady           = "ClusterReady"
+	ReasonAsExpected             = "AsExpected"
+	ReasonLdapBound              = "LdapBound"
+	ReasonLdapBindFailed         = "LdapBindFailed"
+	ReasonOAuth2RealmConfigured  = "OAuth2RealmConfigured"
+	ReasonOAuth2DiscoveryFailed  = "OAuth2DiscoveryFailed"
+
+	ReasonCrossSiteConfigurationValid   = "CrossSiteConfigurationValid"
+	ReasonCrossSiteConfigurationInvalid = "CrossSiteConfigurationInvalid"
+	ReasonCrossSiteUnreachable          = "CrossSiteUnreachable"
+
+	ReasonRebalanceInProgress = "RebalanceInProgress"
+	ReasonRebalanceCompleted  = "RebalanceCompleted"
+	ReasonCachesBalanced      = "CachesBalanced"
+	// ReasonUnknown is assigned to conditions recorded without a reason by previous releases
+	ReasonUnknown = "Unknown"
 )
 
-// InfinispanCondition define a condition of the cluster
-type InfinispanCondition struct {
-	// Type is the type of the condition.
-	Type ConditionType `json:"type"`
-	// Status is the status of the condition.
-	Status metav1.ConditionStatus `json:"status"`
-	// Human-readable message indicating details about last transition.
+type DataMigrationStage string
+
+const (
+	// DataMigrationStaging means that the staging cluster is being started with the new image
+	DataMigrationStaging DataMigrationStage = "Staging"
+	// DataMigrationToStaging means that the caches are being migrated from the cluster to the staging cluster
+	DataMigrationToStaging DataMigrationStage = "MigrateToStaging"
+	// DataMigrationUpgrade means that the migrated entries have been verified and the old StatefulSet is being retired
+	DataMigrationUpgrade DataMigrationStage = "Upgrade"
+	// DataMigrationFromStaging means that the caches are being migrated from the staging cluster to the upgraded cluster
+	DataMigrationFromStaging DataMigrationStage = "MigrateFromStaging"
+	// DataMigrationFailed means that the entries of the migrated caches do not match. The staging cluster is retained
+	// and no further DataMigration upgrade is performed until its StatefulSet is removed
+	DataMigrationFailed DataMigrationStage = "Failed"
+)
+
+// DataMigrationStatus describes the progress of a DataMigration upgrade
+type DataMigrationStatus struct {
+	Stage DataMigrationStage `json:"stage"`
+	// Number of entries of each persisted cache migrated to the staging cluster, used to verify the upgraded cluster
 	// +optional
-	Message string `json:"message,omitempty"`
+	CacheEntries map[string]int `json:"cacheEntries,omitempty"`
+	// Number of times the caches have been migrated in the current stage because the entries did not match
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
 }
 
 type DeploymentStatus struct {
@@ -360,10 +1301,28 @@ type DeploymentStatus struct {
 	Stopped []string `json:"stopped,omitempty"`
 }
 
+// CrossSiteStatus describes the state of a backup site as reported by the server
+type CrossSiteStatus struct {
+	// Name of the backup site
+	Name string `json:"name"`
+	// Status of the site, online, offline or mixed when only some of the caches backing up to it are online
+	Status string `json:"status"`
+	// State transfer status of each cache pushing its state to the site
+	// +optional
+	StateTransfer map[string]string `json:"stateTransfer,omitempty"`
+}
+
 // InfinispanStatus defines the observed state of Infinispan
 type InfinispanStatus struct {
 	// +optional
-	Conditions []InfinispanCondition `json:"conditions,omitempty"`
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+	// The metadata.generation of the Infinispan CR that the conditions were last evaluated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// +optional
 	StatefulSetName string `json:"statefulSetName,omitempty"`
 	// +optional
@@ -374,6 +1333,32 @@ type InfinispanStatus struct {
 	PodStatus DeploymentStatus `json:"podStatus,omitempty"`
 	// +optional
 	ConsoleUrl *string `json:"consoleUrl,omitempty"`
+	// +optional
+	DataMigration *DataMigrationStatus `json:"dataMigration,omitempty"`
+	// Progress of the last Canary upgrade
+	// +optional
+	CanaryUpgrade *CanaryUpgradeStatus `json:"canaryUpgrade,omitempty"`
+	// Progress of the last rebalance of the caches
+	// +optional
+	Rebalance *RebalanceStatus `json:"rebalance,omitempty"`
+	// The last long-running server operation of each type
+	// +optional
+	Operations []OperationStatus `json:"operations,omitempty"`
+	// Hash of the endpoint keystore issued by cert-manager that the servers have loaded
+	// +optional
+	KeystoreHash string `json:"keystoreHash,omitempty"`
+	// The infinispan.org/restartedAt annotation value of the last completed rolling restart
+	// +optional
+	RestartedAt string `json:"restartedAt,omitempty"`
+	// Backup sites of the cluster caches
+	// +optional
+	XSite []CrossSiteStatus `json:"xsite,omitempty"`
+	// The infinispan.org/xsiteStateTransferAt annotation value of the last requested cross-site state transfer
+	// +optional
+	XSiteStateTransferAt string `json:"xsiteStateTransferAt,omitempty"`
+	// Version of the operand catalog that the pods run
+	// +optional
+	Version string `json:"version,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -8,10 +8,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/version"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -50,68 +53,125 @@ const (
 	SiteServiceFQNTemplate  = "%s.%s.svc.cluster.local"
 
 	GossipRouterDeploymentNameTemplate = "%s-tunnel"
+	DefaultGossipRouterTLSProtocol     = "TLSv1.3"
+
+	DebugBundleJobNameTemplate = "%s-debug-bundle"
+	ConfigExportNameTemplate   = "%s-config-export"
 )
 
 type ExternalDependencyType string
 
-// equals compares two ConditionType's case insensitive
-func (a ConditionType) equals(b ConditionType) bool {
-	return strings.EqualFold(strings.ToLower(string(a)), strings.ToLower(string(b)))
-}
-
 // GetCondition return the Status of the given condition or nil
 // if condition is not present
-func (ispn *Infinispan) GetCondition(condition ConditionType) InfinispanCondition {
-	for _, c := range ispn.Status.Conditions {
-		if c.Type.equals(condition) {
-			return c
-		}
+func (ispn *Infinispan) GetCondition(condition ConditionType) metav1.Condition {
+	if c := meta.FindStatusCondition(ispn.Status.Conditions, string(condition)); c != nil {
+		return *c
 	}
 	// Absence of condition means `False` value
-	return InfinispanCondition{Type: condition, Status: metav1.ConditionFalse}
+	return metav1.Condition{Type: string(condition), Status: metav1.ConditionFalse}
 }
 
-// SetCondition set condition to status
-func (ispn *Infinispan) SetCondition(condition ConditionType, status metav1.ConditionStatus, message string) bool {
-	changed := false
-	for idx := range ispn.Status.Conditions {
-		c := &ispn.Status.Conditions[idx]
-		if c.Type.equals(condition) {
-			if c.Status != status {
-				c.Status = status
-				changed = true
-			}
-			if c.Message != message {
-				c.Message = message
-				changed = true
-			}
-
-			return changed
-		}
+// SetCondition set condition to status, updating the summary conditions when it changes
+func (ispn *Infinispan) SetCondition(condition ConditionType, status metav1.ConditionStatus, reason, message string) bool {
+	if !ispn.setCondition(condition, status, reason, message) {
+		return false
 	}
-	ispn.Status.Conditions = append(ispn.Status.Conditions, InfinispanCondition{Type: condition, Status: status, Message: message})
+	ispn.updateSummaryConditions()
 	return true
 }
 
 // SetConditions set provided conditions to status
-func (ispn *Infinispan) SetConditions(conds []InfinispanCondition) bool {
+func (ispn *Infinispan) SetConditions(conds []metav1.Condition) bool {
 	changed := false
 	for _, c := range conds {
-		changed = changed || ispn.SetCondition(c.Type, c.Status, c.Message)
+		changed = ispn.SetCondition(ConditionType(c.Type), c.Status, c.Reason, c.Message) || changed
 	}
 	return changed
 }
 
 // RemoveCondition remove condition from Status
 func (ispn *Infinispan) RemoveCondition(condition ConditionType) bool {
+	if meta.FindStatusCondition(ispn.Status.Conditions, string(condition)) == nil {
+		return false
+	}
+	meta.RemoveStatusCondition(&ispn.Status.Conditions, string(condition))
+	ispn.updateSummaryConditions()
+	return true
+}
+
+func (ispn *Infinispan) setCondition(condition ConditionType, status metav1.ConditionStatus, reason, message string) bool {
+	// Conditions recorded by previous releases lack the fields required by metav1.Condition
 	for idx := range ispn.Status.Conditions {
 		c := &ispn.Status.Conditions[idx]
-		if c.Type.equals(condition) {
-			ispn.Status.Conditions = append(ispn.Status.Conditions[:idx], ispn.Status.Conditions[idx+1:]...)
-			return true
+		if c.Reason == "" {
+			c.Reason = ReasonUnknown
+		}
+		if c.LastTransitionTime.IsZero() {
+			c.LastTransitionTime = metav1.Now()
 		}
 	}
-	return false
+	if current := meta.FindStatusCondition(ispn.Status.Conditions, string(condition)); current != nil &&
+		current.Status == status && current.Reason == reason && current.Message == message && current.ObservedGeneration == ispn.Generation {
+		return false
+	}
+	meta.SetStatusCondition(&ispn.Status.Conditions, metav1.Condition{
+		Type:    string(condition),
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	// meta.SetStatusCondition of apimachinery 0.19 doesn't update the observedGeneration
+	meta.FindStatusCondition(ispn.Status.Conditions, string(condition)).ObservedGeneration = ispn.Generation
+	return true
+}
+
+// updateSummaryConditions derives the Ready, Progressing and Degraded conditions from the other conditions, so that
+// the state of the cluster can be assessed by tools that are not aware of the Infinispan specific conditions
+func (ispn *Infinispan) updateSummaryConditions() {
+	degraded := metav1.Condition{Status: metav1.ConditionFalse, Reason: ReasonAsExpected}
+	if meta.IsStatusConditionFalse(ispn.Status.Conditions, string(ConditionPrelimChecksPassed)) {
+		degraded = metav1.Condition{Status: metav1.ConditionTrue, Reason: ReasonPrelimChecksFailed, Message: ispn.GetCondition(ConditionPrelimChecksPassed).Message}
+	} else if ispn.IsConditionTrue(ConditionDataMigrationFailed) {
+		degraded = metav1.Condition{Status: metav1.ConditionTrue, Reason: ReasonDataMigrationFailed, Message: ispn.GetCondition(ConditionDataMigrationFailed).Message}
+	} else if c := ispn.GetCondition(ConditionSplitBrain); c.Status == metav1.ConditionTrue {
+		degraded = metav1.Condition{Status: metav1.ConditionTrue, Reason: c.Reason, Message: c.Message}
+	} else if c := ispn.GetCondition(ConditionServerAlert); c.Status == metav1.ConditionTrue {
+		degraded = metav1.Condition{Status: metav1.ConditionTrue, Reason: c.Reason, Message: c.Message}
+	} else if c := ispn.GetCondition(ConditionGracefulShutdown); c.Reason == ReasonShutdownFailed {
+		degraded = metav1.Condition{Status: metav1.ConditionTrue, Reason: c.Reason, Message: c.Message}
+	}
+
+	progressing := metav1.Condition{Status: metav1.ConditionFalse, Reason: ReasonAsExpected}
+	ready := metav1.Condition{Status: metav1.ConditionTrue, Reason: ReasonClusterReady, Message: ispn.GetCondition(ConditionWellFormed).Message}
+	if degraded.Status == metav1.ConditionTrue {
+		ready = metav1.Condition{Status: metav1.ConditionFalse, Reason: degraded.Reason, Message: degraded.Message}
+	} else if c := ispn.GetCondition(ConditionGracefulShutdown); c.Status == metav1.ConditionTrue {
+		ready = metav1.Condition{Status: metav1.ConditionFalse, Reason: ReasonClusterStopped, Message: "The cluster has been gracefully shutdown"}
+	} else {
+		for _, condition := range []ConditionType{ConditionUpgrade, ConditionStopping} {
+			if c := ispn.GetCondition(condition); c.Status == metav1.ConditionTrue {
+				progressing = metav1.Condition{Status: metav1.ConditionTrue, Reason: c.Reason, Message: c.Message}
+				break
+			}
+		}
+		if c := ispn.GetCondition(ConditionGracefulShutdown); progressing.Status == metav1.ConditionFalse && c.Reason == ReasonClusterRecovering {
+			progressing = metav1.Condition{Status: metav1.ConditionTrue, Reason: c.Reason, Message: c.Message}
+		}
+		if c := ispn.GetCondition(ConditionWellFormed); progressing.Status == metav1.ConditionFalse && c.Status != metav1.ConditionTrue {
+			progressing = metav1.Condition{Status: metav1.ConditionTrue, Reason: c.Reason, Message: c.Message}
+			if progressing.Reason == "" {
+				progressing.Reason = ReasonPodsNotReady
+			}
+		}
+		if progressing.Status == metav1.ConditionTrue {
+			ready = metav1.Condition{Status: metav1.ConditionFalse, Reason: progressing.Reason, Message: progressing.Message}
+		}
+	}
+
+	ispn.setCondition(ConditionReady, ready.Status, ready.Reason, ready.Message)
+	ispn.setCondition(ConditionProgressing, progressing.Status, progressing.Reason, progressing.Message)
+	ispn.setCondition(ConditionDegraded, degraded.Status, degraded.Reason, degraded.Message)
+	ispn.Status.ObservedGeneration = ispn.Generation
 }
 
 func (ispn *Infinispan) ExpectConditionStatus(expected map[ConditionType]metav1.ConditionStatus) error {
@@ -131,7 +191,7 @@ func (ispn *Infinispan) ExpectConditionStatus(expected map[ConditionType]metav1.
 // ApplyDefaults applies default values to the Infinispan instance
 func (ispn *Infinispan) ApplyDefaults() {
 	if ispn.Status.Conditions == nil {
-		ispn.Status.Conditions = []InfinispanCondition{}
+		ispn.Status.Conditions = []metav1.Condition{}
 	}
 	if ispn.Spec.Service.Type == "" {
 		ispn.Spec.Service.Type = ServiceTypeCache
@@ -142,6 +202,12 @@ func (ispn *Infinispan) ApplyDefaults() {
 	if ispn.Spec.Container.Memory == "" {
 		ispn.Spec.Container.Memory = consts.DefaultMemorySize.String()
 	}
+	if ispn.Spec.Container.MemoryPolicy == "" {
+		ispn.Spec.Container.MemoryPolicy = MemoryPolicyManual
+	}
+	if ispn.Spec.Container.MemoryPolicy == MemoryPolicyAuto && ispn.Spec.Container.MemoryHeadroomPercent == 0 {
+		ispn.Spec.Container.MemoryHeadroomPercent = consts.DefaultMemoryHeadroomPercent
+	}
 	if ispn.Spec.Container.CPU == "" {
 		cpuLimitString := toMilliDecimalQuantity(consts.DefaultCPULimit)
 		ispn.Spec.Container.CPU = cpuLimitString.String()
@@ -157,7 +223,7 @@ func (ispn *Infinispan) ApplyDefaults() {
 	if ispn.Spec.Security.EndpointAuthentication == nil {
 		ispn.Spec.Security.EndpointAuthentication = pointer.BoolPtr(true)
 	}
-	if *ispn.Spec.Security.EndpointAuthentication {
+	if *ispn.Spec.Security.EndpointAuthentication && ispn.GetEndpointSecretSource() == EndpointSecretSourceSecret {
 		ispn.Spec.Security.EndpointSecretName = ispn.GetSecretName()
 	} else if ispn.IsGeneratedSecret() {
 		ispn.Spec.Security.EndpointSecretName = ""
@@ -176,8 +242,17 @@ func (ispn *Infinispan) ApplyMonitoringAnnotation() {
 
 // ApplyEndpointEncryptionSettings compute the EndpointEncryption object
 func (ispn *Infinispan) ApplyEndpointEncryptionSettings(servingCertsMode string, reqLogger logr.Logger) {
-	// Populate EndpointEncryption if serving cert service is available
 	encryption := ispn.Spec.Security.EndpointEncryption
+	// The certificate issued by cert-manager is stored in a Secret
+	if encryption != nil && encryption.CertManager != nil {
+		if encryption.Type == "" {
+			encryption.Type = CertificateSourceTypeSecret
+		}
+		if encryption.CertSecretName == "" {
+			encryption.CertSecretName = ispn.Name + "-cert-secret"
+		}
+	}
+	// Populate EndpointEncryption if serving cert service is available
 	if servingCertsMode == "openshift.io" && (!ispn.IsEncryptionCertSourceDefined() || ispn.IsEncryptionCertFromService()) {
 		if encryption == nil {
 			encryption = &EndpointEncryption{}
@@ -208,9 +283,30 @@ func (ispn *Infinispan) ImageName() string {
 	if ispn.Spec.Image != nil && *ispn.Spec.Image != "" {
 		return *ispn.Spec.Image
 	}
+	return ispn.DefaultImageName()
+}
+
+// DefaultImageName returns the image of spec.version, or the default image of the operator when no version is
+// specified. The image is set as DEFAULT_IMAGE env of the pods, which triggers an upgrade when it changes
+func (ispn *Infinispan) DefaultImageName() string {
+	if ispn.Spec.Version != "" {
+		if operand, err := version.Operands.Get(ispn.Spec.Version); err == nil {
+			return operand.ResolvedImage()
+		}
+	}
 	return consts.DefaultImageName
 }
 
+// GetOperand returns the catalog operand of spec.version or of the image of the cluster, nil if the image is not part
+// of the catalog
+func (ispn *Infinispan) GetOperand() *version.Operand {
+	if ispn.Spec.Version != "" {
+		operand, _ := version.Operands.Get(ispn.Spec.Version)
+		return operand
+	}
+	return version.Operands.ForImage(ispn.ImageName())
+}
+
 func (ispn *Infinispan) ImageType() ImageType {
 	if strings.Contains(ispn.ImageName(), consts.NativeImageMarker) {
 		return ImageTypeNative
@@ -238,6 +334,88 @@ func (ispn *Infinispan) GetServiceExternalName() string {
 	return externalServiceName
 }
 
+// GetEndpointExternalName returns the name of the Service, Route or Ingress exposing the endpoint when perEndpoint is true
+func (ispn *Infinispan) GetEndpointExternalName(endpoint ExposeEndpoint) string {
+	externalName := fmt.Sprintf("%s-external-%s", ispn.Name, endpoint)
+	if expose := ispn.GetEndpointExpose(endpoint); expose != nil && expose.Type == ExposeTypeRoute && len(externalName)+len(ispn.Namespace) >= MaxRouteObjectNameLength {
+		suffix := "-" + string(endpoint)
+		return externalName[0:MaxRouteObjectNameLength-len(ispn.Namespace)-len(suffix)-1] + suffix
+	}
+	return externalName
+}
+
+// IsConsoleExposed returns true if the console is exposed by a dedicated Route or Ingress
+func (ispn *Infinispan) IsConsoleExposed() bool {
+	return ispn.Spec.Expose != nil && ispn.Spec.Expose.Console != nil
+}
+
+// GetConsoleOIDC returns the OIDC authentication of the exposed console, or nil if the console users aren't
+// authenticated by the proxy
+func (ispn *Infinispan) GetConsoleOIDC() *ConsoleOIDCSpec {
+	if !ispn.IsConsoleExposed() {
+		return nil
+	}
+	return ispn.Spec.Expose.Console.OIDC
+}
+
+// GetConsoleExternalName returns the name of the Service and of the Route or Ingress exposing the console
+func (ispn *Infinispan) GetConsoleExternalName() string {
+	externalName := fmt.Sprintf("%s-console", ispn.Name)
+	if len(externalName)+len(ispn.Namespace) >= MaxRouteObjectNameLength {
+		suffix := "-console"
+		return externalName[0:MaxRouteObjectNameLength-len(ispn.Namespace)-len(suffix)-1] + suffix
+	}
+	return externalName
+}
+
+// IsExposedPerEndpoint returns true if each endpoint protocol is exposed by its own Service, Route or Ingress
+func (ispn *Infinispan) IsExposedPerEndpoint() bool {
+	return ispn.IsExposed() && ispn.Spec.Expose.PerEndpoint
+}
+
+// GetEndpointExpose returns how the endpoint is exposed when perEndpoint is true, or nil if it isn't exposed.
+// HotRod and REST endpoints default to the type and annotations of the expose spec
+func (ispn *Infinispan) GetEndpointExpose(endpoint ExposeEndpoint) *EndpointExposeSpec {
+	if !ispn.IsExposedPerEndpoint() {
+		return nil
+	}
+	expose := ispn.Spec.Expose
+	var endpointExpose *EndpointExposeSpec
+	switch endpoint {
+	case ExposeEndpointHotRod:
+		endpointExpose = expose.HotRod
+	case ExposeEndpointRest:
+		endpointExpose = expose.Rest
+	case ExposeEndpointMemcached:
+		return expose.Memcached
+	}
+	if endpointExpose == nil {
+		return &EndpointExposeSpec{Type: expose.Type, Annotations: expose.Annotations, LoadBalancerSourceRanges: expose.LoadBalancerSourceRanges}
+	}
+	return endpointExpose
+}
+
+// IsMemcachedEnabled returns true if the Memcached endpoint is enabled or exposed
+func (ispn *Infinispan) IsMemcachedEnabled() bool {
+	if endpoints := ispn.Spec.Endpoints; endpoints != nil && endpoints.Memcached {
+		return true
+	}
+	return ispn.GetEndpointExpose(ExposeEndpointMemcached) != nil
+}
+
+// IsRespEnabled returns true if the RESP endpoint is enabled
+func (ispn *Infinispan) IsRespEnabled() bool {
+	return ispn.Spec.Endpoints != nil && ispn.Spec.Endpoints.Resp
+}
+
+// GetJGroupsStack returns the JGroups transport stack of the cluster, tcp by default
+func (ispn *Infinispan) GetJGroupsStack() JGroupsStackType {
+	if jgroups := ispn.Spec.JGroups; jgroups != nil && jgroups.Stack != "" {
+		return jgroups.Stack
+	}
+	return JGroupsStackTCP
+}
+
 func (ispn *Infinispan) GetServiceName() string {
 	return ispn.Name
 }
@@ -246,6 +424,34 @@ func (ispn *Infinispan) GetAdminServiceName() string {
 	return fmt.Sprintf("%s-admin", ispn.Name)
 }
 
+// GetAdminHeadlessServiceName returns the name of the headless admin service, which lists all the pods
+func (ispn *Infinispan) GetAdminHeadlessServiceName() string {
+	return fmt.Sprintf("%s-admin-headless", ispn.Name)
+}
+
+// GetReadOnlyServiceName returns the name of the Service targeting the pods that completed the state transfer
+func (ispn *Infinispan) GetReadOnlyServiceName() string {
+	return fmt.Sprintf("%s-read-only", ispn.Name)
+}
+
+// HasReadOnlyService returns true if the read-only Service is created
+func (ispn *Infinispan) HasReadOnlyService() bool {
+	return ispn.Spec.Expose != nil && ispn.Spec.Expose.ReadOnlyService
+}
+
+// GetZeroCapacityStatefulSetName returns the name of the StatefulSet of the zero-capacity pods
+func (ispn *Infinispan) GetZeroCapacityStatefulSetName() string {
+	return fmt.Sprintf("%s-zero", ispn.Name)
+}
+
+// GetZeroCapacityNodes returns the number of zero-capacity pods joining the cluster, 0 when the service is not DataGrid
+func (ispn *Infinispan) GetZeroCapacityNodes() int32 {
+	if !ispn.IsDataGrid() || ispn.Spec.Service.Container == nil {
+		return 0
+	}
+	return ispn.Spec.Service.Container.ZeroCapacityNodes
+}
+
 func (ispn *Infinispan) GetPingServiceName() string {
 	return fmt.Sprintf("%s-ping", ispn.Name)
 }
@@ -258,6 +464,29 @@ func (ispn *Infinispan) HasSites() bool {
 	return ispn.IsDataGrid() && ispn.Spec.Service.Sites != nil && len(ispn.Spec.Service.Sites.Locations) > 0
 }
 
+// GetPartitionHandling returns how the caches created by the operator behave when the cluster splits and how the
+// conflicting entries are resolved when the partitions merge
+func (ispn *Infinispan) GetPartitionHandling() (WhenSplitType, MergePolicyType) {
+	whenSplit, mergePolicy := WhenSplitAllowReadWrites, MergePolicyRemoveAll
+	if spec := ispn.Spec.Service.PartitionHandling; spec != nil && ispn.IsDataGrid() {
+		if spec.WhenSplit != "" {
+			whenSplit = spec.WhenSplit
+		}
+		if spec.MergePolicy != "" {
+			mergePolicy = spec.MergePolicy
+		}
+	}
+	return whenSplit, mergePolicy
+}
+
+// GetLogAlerts returns the configuration of the server log alerts, nil when they are disabled
+func (ispn *Infinispan) GetLogAlerts() *InfinispanLogAlertsSpec {
+	if ispn.Spec.Logging == nil {
+		return nil
+	}
+	return ispn.Spec.Logging.Alerts
+}
+
 // GetRemoteSiteLocations returns remote site locations
 func (ispn *Infinispan) GetRemoteSiteLocations() (remoteLocations map[string]InfinispanSiteLocationSpec) {
 	remoteLocations = make(map[string]InfinispanSiteLocationSpec)
@@ -325,6 +554,9 @@ func (ispn *Infinispan) GetEndpointScheme() string {
 // GetSecretName returns the secret name associated with a server
 func (ispn *Infinispan) GetSecretName() string {
 	if ispn.Spec.Security.EndpointSecretName == "" {
+		if ispn.GetEndpointSecretSource() == EndpointSecretSourceExternalSecret && ispn.Spec.Security.EndpointSecret.ExternalSecret != nil {
+			return ispn.Spec.Security.EndpointSecret.ExternalSecret.Name
+		}
 		return ispn.GenerateSecretName()
 	}
 	return ispn.Spec.Security.EndpointSecretName
@@ -358,6 +590,14 @@ func (ispn *Infinispan) IsClientCertEnabled() bool {
 	return ispn.IsEncryptionEnabled() && ispn.Spec.Security.EndpointEncryption.ClientCert != "" && ispn.Spec.Security.EndpointEncryption.ClientCert != ClientCertNone
 }
 
+// GetEndpointSecretSource returns the backend providing the user identities
+func (ispn *Infinispan) GetEndpointSecretSource() EndpointSecretSourceType {
+	if ispn.Spec.Security.EndpointSecret == nil || ispn.Spec.Security.EndpointSecret.Source == "" {
+		return EndpointSecretSourceSecret
+	}
+	return ispn.Spec.Security.EndpointSecret.Source
+}
+
 // IsGeneratedSecret verifies that the Secret should be generated by the controller
 func (ispn *Infinispan) IsGeneratedSecret() bool {
 	return ispn.Spec.Security.EndpointSecretName == ispn.GenerateSecretName()
@@ -373,6 +613,21 @@ func (ispn *Infinispan) GetServiceMonitorName() string {
 	return fmt.Sprintf("%v-monitor", ispn.Name)
 }
 
+// GetGrafanaDashboardName returns the GrafanaDashboard name for the cluster
+func (ispn *Infinispan) GetGrafanaDashboardName() string {
+	return fmt.Sprintf("%v-dashboard", ispn.Name)
+}
+
+// GetPodDisruptionBudgetName returns the PodDisruptionBudget name for the cluster
+func (ispn *Infinispan) GetPodDisruptionBudgetName() string {
+	return fmt.Sprintf("%v-pdb", ispn.Name)
+}
+
+// GetNetworkPolicyName returns the NetworkPolicy name for the cluster
+func (ispn *Infinispan) GetNetworkPolicyName() string {
+	return fmt.Sprintf("%v-network-policy", ispn.Name)
+}
+
 // GetKeystoreSecretName ...
 func (ispn *Infinispan) GetKeystoreSecretName() string {
 	if ispn.Spec.Security.EndpointEncryption == nil {
@@ -381,6 +636,35 @@ func (ispn *Infinispan) GetKeystoreSecretName() string {
 	return ispn.Spec.Security.EndpointEncryption.CertSecretName
 }
 
+// IsTransportEncryptionEnabled returns true if the JGroups traffic between the pods is encrypted
+func (ispn *Infinispan) IsTransportEncryptionEnabled() bool {
+	return ispn.Spec.Security.TransportEncryption != nil
+}
+
+// GetTransportEncryptionType returns the JGroups protocol encrypting the cluster traffic
+func (ispn *Infinispan) GetTransportEncryptionType() TransportEncryptionType {
+	if encryption := ispn.Spec.Security.TransportEncryption; encryption != nil && encryption.Type != "" {
+		return encryption.Type
+	}
+	return TransportEncryptionAsym
+}
+
+// IsGeneratedTransportKeystore returns true if the transport encryption keystore is generated by the operator
+func (ispn *Infinispan) IsGeneratedTransportKeystore() bool {
+	return ispn.IsTransportEncryptionEnabled() && ispn.Spec.Security.TransportEncryption.SecretName == ""
+}
+
+// GetTransportKeystoreSecretName returns the name of the Secret holding the transport encryption keystore
+func (ispn *Infinispan) GetTransportKeystoreSecretName() string {
+	if !ispn.IsTransportEncryptionEnabled() {
+		return ""
+	}
+	if secretName := ispn.Spec.Security.TransportEncryption.SecretName; secretName != "" {
+		return secretName
+	}
+	return fmt.Sprintf("%s-transport-keystore", ispn.Name)
+}
+
 func (ispn *Infinispan) GetTruststoreSecretName() string {
 	if ispn.Spec.Security.EndpointEncryption == nil {
 		return ""
@@ -398,22 +682,119 @@ func (spec *InfinispanContainerSpec) GetCpuResources() (*resource.Quantity, *res
 	return &cpuRequests, &cpuLimits, nil
 }
 
+// GetExtraJvmOpts returns the extra JVM options followed by the JVM arguments
+func (spec *InfinispanContainerSpec) GetExtraJvmOpts() string {
+	if len(spec.JvmArgs) == 0 {
+		return spec.ExtraJvmOpts
+	}
+	return strings.TrimSpace(strings.TrimSpace(spec.ExtraJvmOpts) + " " + strings.Join(spec.JvmArgs, " "))
+}
+
+// GetMemoryJavaOptions returns the heap and off-heap JVM options computed from the container memory when the memory
+// policy is Auto, otherwise an empty string. The headroom is left to the JVM native memory, and the remaining memory is
+// split between the heap and the off-heap limit
+func (spec *InfinispanContainerSpec) GetMemoryJavaOptions() string {
+	if spec.MemoryPolicy != MemoryPolicyAuto {
+		return ""
+	}
+	memory, err := resource.ParseQuantity(spec.Memory)
+	if err != nil {
+		return ""
+	}
+	usableMb, heapMb := spec.autoMemoryMb(memory)
+	return fmt.Sprintf(consts.AutoMemoryJavaOptions, heapMb, heapMb, usableMb-heapMb)
+}
+
+// autoMemoryMb returns the memory usable by the JVM and the heap size of the Auto memory policy, in MB
+func (spec *InfinispanContainerSpec) autoMemoryMb(memory resource.Quantity) (int64, int64) {
+	headroom := int64(consts.DefaultMemoryHeadroomPercent)
+	if spec.MemoryHeadroomPercent > 0 {
+		headroom = int64(spec.MemoryHeadroomPercent)
+	}
+	usableMb := memory.Value() / (1024 * 1024) * (100 - headroom) / 100
+	return usableMb, usableMb * consts.AutoMemoryHeapPercent / 100
+}
+
+// GetHeapSizeBytes returns the maximum heap size of the server JVM: the -Xmx option of the extra JVM options, the heap
+// of the Auto memory policy, or the default heap of the server image
+func (spec *InfinispanContainerSpec) GetHeapSizeBytes() (int64, error) {
+	var xmx string
+	for _, opt := range strings.Fields(spec.GetExtraJvmOpts()) {
+		if strings.HasPrefix(opt, "-Xmx") {
+			xmx = strings.TrimPrefix(opt, "-Xmx")
+		}
+	}
+	if xmx != "" {
+		return parseJvmSize(xmx)
+	}
+	memory, err := resource.ParseQuantity(spec.Memory)
+	if err != nil {
+		return 0, err
+	}
+	if spec.MemoryPolicy == MemoryPolicyAuto {
+		_, heapMb := spec.autoMemoryMb(memory)
+		return heapMb * 1024 * 1024, nil
+	}
+	return memory.Value() * consts.ServerDefaultHeapPercent / 100, nil
+}
+
+// parseJvmSize parses a JVM memory size, e.g. 512m or 2G
+func parseJvmSize(size string) (int64, error) {
+	multiplier := int64(1)
+	value := size
+	switch strings.ToLower(size[len(size)-1:]) {
+	case "k":
+		multiplier = 1024
+	case "m":
+		multiplier = 1024 * 1024
+	case "g":
+		multiplier = 1024 * 1024 * 1024
+	case "t":
+		multiplier = 1024 * 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		value = size[:len(size)-1]
+	}
+	bytes, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || bytes <= 0 {
+		return 0, fmt.Errorf("invalid JVM memory size '%s'", size)
+	}
+	return bytes * multiplier, nil
+}
+
 func (ispn *Infinispan) GetJavaOptions() string {
+	extraJvmOpts := ispn.Spec.Container.GetExtraJvmOpts()
+	if ispn.IsIPv6() {
+		extraJvmOpts = strings.TrimSpace(extraJvmOpts + " " + consts.IPv6JavaOptions)
+	}
 	switch ispn.Spec.Service.Type {
 	case ServiceTypeDataGrid:
-		return ispn.Spec.Container.ExtraJvmOpts
+		// The extra JVM options follow the auto-tuned memory options, so that they take precedence
+		return strings.TrimSpace(ispn.Spec.Container.GetMemoryJavaOptions() + " " + extraJvmOpts)
 	case ServiceTypeCache:
 		switch ispn.ImageType() {
 		case ImageTypeJVM:
 			return fmt.Sprintf(consts.CacheServiceJavaOptions, consts.CacheServiceFixedMemoryXmxMb, consts.CacheServiceFixedMemoryXmxMb, consts.CacheServiceMaxRamMb,
-				consts.CacheServiceMinHeapFreeRatio, consts.CacheServiceMaxHeapFreeRatio, ispn.Spec.Container.ExtraJvmOpts)
+				consts.CacheServiceMinHeapFreeRatio, consts.CacheServiceMaxHeapFreeRatio, extraJvmOpts)
 		case ImageTypeNative:
-			return fmt.Sprintf(consts.CacheServiceNativeJavaOptions, consts.CacheServiceFixedMemoryXmxMb, consts.CacheServiceFixedMemoryXmxMb, ispn.Spec.Container.ExtraJvmOpts)
+			return fmt.Sprintf(consts.CacheServiceNativeJavaOptions, consts.CacheServiceFixedMemoryXmxMb, consts.CacheServiceFixedMemoryXmxMb, extraJvmOpts)
 		}
 	}
 	return ""
 }
 
+// ApplyIPFamily defaults the IP families of the cluster to the IP family of the pod network
+func (ispn *Infinispan) ApplyIPFamily(family corev1.IPFamily) {
+	if len(ispn.Spec.IPFamilies) == 0 && family != "" {
+		ispn.Spec.IPFamilies = []corev1.IPFamily{family}
+	}
+}
+
+// IsIPv6 returns true if the cluster members communicate over IPv6
+func (ispn *Infinispan) IsIPv6() bool {
+	return len(ispn.Spec.IPFamilies) > 0 && ispn.Spec.IPFamilies[0] == corev1.IPv6Protocol
+}
+
 // GetLogCategoriesForConfig return a map of log category for the Infinispan configuration
 func (ispn *Infinispan) GetLogCategoriesForConfig() map[string]string {
 	if ispn.Spec.Logging != nil {
@@ -453,7 +834,8 @@ func (ispn *Infinispan) EnsureClusterStability() error {
 }
 
 func (ispn *Infinispan) IsUpgradeNeeded(logger logr.Logger) bool {
-	if ispn.IsUpgradeCondition() {
+	// Canary upgrades update the StatefulSet in place
+	if ispn.IsUpgradeCondition() && ispn.Status.CanaryUpgrade == nil {
 		if ispn.GetCondition(ConditionStopping).Status == metav1.ConditionFalse {
 			if ispn.Status.ReplicasWantedAtRestart > 0 {
 				logger.Info("graceful shutdown after upgrade completed, continue upgrade process")
@@ -480,6 +862,16 @@ func (ispn *Infinispan) IsEncryptionCertFromService() bool {
 	return ee != nil && (ee.Type == CertificateSourceTypeService || ee.Type == CertificateSourceTypeServiceLowCase)
 }
 
+// IsEncryptionCertFromCertManager returns true if the encryption certificate is issued by cert-manager
+func (ispn *Infinispan) IsEncryptionCertFromCertManager() bool {
+	return ispn.IsEncryptionEnabled() && !ispn.IsEncryptionCertFromService() && ispn.Spec.Security.EndpointEncryption.CertManager != nil
+}
+
+// GetCertManagerKeystorePasswordSecretName returns the name of the Secret holding the password of the keystore issued by cert-manager
+func (ispn *Infinispan) GetCertManagerKeystorePasswordSecretName() string {
+	return fmt.Sprintf("%s-cert-keystore-password", ispn.Name)
+}
+
 // IsEncryptionCertSourceDefined returns true if encryption certificates source is defined
 func (ispn *Infinispan) IsEncryptionCertSourceDefined() bool {
 	ee := ispn.Spec.Security.EndpointEncryption
@@ -490,15 +882,30 @@ func toMilliDecimalQuantity(value int64) resource.Quantity {
 	return *resource.NewMilliQuantity(value, resource.DecimalSI)
 }
 
-// IsEphemeralStorage
+// IsEphemeralStorage returns true if the pod data is stored in emptyDir volumes
 func (ispn *Infinispan) IsEphemeralStorage() bool {
 	cont := ispn.Spec.Service.Container
 	if cont != nil {
-		return cont.EphemeralStorage
+		return cont.EphemeralStorage || cont.StorageType == StorageEphemeral
 	}
 	return false
 }
 
+// GetProbes returns the probe thresholds configured for the Infinispan container
+func (ispn *Infinispan) GetProbes() InfinispanProbesSpec {
+	cont := ispn.Spec.Service.Container
+	if cont != nil && cont.Probes != nil {
+		return *cont.Probes
+	}
+	return InfinispanProbesSpec{}
+}
+
+// HasReadinessGate returns true if the pods only become ready once they have joined a well-formed cluster view
+func (ispn *Infinispan) HasReadinessGate() bool {
+	cont := ispn.Spec.Service.Container
+	return cont != nil && cont.ReadinessGate
+}
+
 // StorageClassName returns a storage class name if it defined
 func (ispn *Infinispan) StorageClassName() string {
 	sc := ispn.Spec.Service.Container
@@ -616,8 +1023,12 @@ func (ispn *Infinispan) HasExternalArtifacts() bool {
 	return ispn.Spec.Dependencies != nil && len(ispn.Spec.Dependencies.Artifacts) > 0
 }
 
-// IsServiceMonitorEnabled validates that "infinispan.org/monitoring":true annotation defines or not
+// IsServiceMonitorEnabled returns true if spec.monitoring.enabled is true or, when it isn't set, if the
+// "infinispan.org/monitoring":true annotation is defined
 func (ispn *Infinispan) IsServiceMonitorEnabled() bool {
+	if ispn.Spec.Monitoring != nil && ispn.Spec.Monitoring.Enabled != nil {
+		return *ispn.Spec.Monitoring.Enabled
+	}
 	monitor, ok := ispn.GetAnnotations()[ServiceMonitoringAnnotation]
 	if ok {
 		isMonitor, err := strconv.ParseBool(monitor)
@@ -626,7 +1037,170 @@ func (ispn *Infinispan) IsServiceMonitorEnabled() bool {
 	return false
 }
 
+// IsGrafanaDashboardEnabled returns true if a GrafanaDashboard must be created for the cluster
+func (ispn *Infinispan) IsGrafanaDashboardEnabled() bool {
+	return ispn.Spec.Monitoring != nil && ispn.Spec.Monitoring.Dashboards
+}
+
+// HasMetricsSecurity returns true if the metrics endpoint doesn't share the configuration of the admin endpoint
+func (ispn *Infinispan) HasMetricsSecurity() bool {
+	return ispn.Spec.Monitoring != nil && ispn.Spec.Monitoring.Security != nil
+}
+
+// IsMetricsAuthenticationEnabled returns true unless spec.monitoring.security.authentication is false
+func (ispn *Infinispan) IsMetricsAuthenticationEnabled() bool {
+	if ispn.HasMetricsSecurity() && ispn.Spec.Monitoring.Security.Authentication != nil {
+		return *ispn.Spec.Monitoring.Security.Authentication
+	}
+	return true
+}
+
+// GetLdapRealm returns the LDAP realm authenticating the users of the endpoints, nil if the users are authenticated
+// with the identities Secret
+func (ispn *Infinispan) GetLdapRealm() *LdapRealm {
+	if realm := ispn.Spec.Security.Realm; realm != nil {
+		return realm.Ldap
+	}
+	return nil
+}
+
+// GetOAuth2Realm returns the OAuth2 realm authenticating the bearer tokens of the clients, nil if not configured
+func (ispn *Infinispan) GetOAuth2Realm() *OAuth2Realm {
+	if realm := ispn.Spec.Security.Realm; realm != nil {
+		return realm.OAuth2
+	}
+	return nil
+}
+
+// GetMetricsTLSSecretName returns the name of the Secret used to serve the metrics over TLS, if any
+func (ispn *Infinispan) GetMetricsTLSSecretName() string {
+	if !ispn.HasMetricsSecurity() {
+		return ""
+	}
+	return ispn.Spec.Monitoring.Security.TLSSecretName
+}
+
+// IsPodSecurityRestricted returns true if the pods must comply with the restricted Pod Security Standard
+func (ispn *Infinispan) IsPodSecurityRestricted() bool {
+	return ispn.Spec.Security.PodSecurityProfile == PodSecurityProfileRestricted
+}
+
+// IsNetworkPolicyEnabled returns true if a NetworkPolicy must restrict the ingress traffic of the cluster pods
+func (ispn *Infinispan) IsNetworkPolicyEnabled() bool {
+	return ispn.Spec.Security.NetworkPolicy != nil && *ispn.Spec.Security.NetworkPolicy
+}
+
+// IsPodDisruptionBudgetEnabled returns true if a PodDisruptionBudget must be created for the cluster
+func (ispn *Infinispan) IsPodDisruptionBudgetEnabled() bool {
+	pdb := ispn.GetPodDisruptionBudgetSpec()
+	return pdb == nil || pdb.Enabled == nil || *pdb.Enabled
+}
+
+// GetPodDisruptionBudgetSpec returns the configured PodDisruptionBudget, nil if not configured
+func (ispn *Infinispan) GetPodDisruptionBudgetSpec() *InfinispanPodDisruptionBudgetSpec {
+	if ispn.Spec.Scheduling == nil {
+		return nil
+	}
+	return ispn.Spec.Scheduling.PodDisruptionBudget
+}
+
+// GetPodDisruptionBudgetPolicy returns the PodDisruptionBudget policy, MaxUnavailable if not configured
+func (ispn *Infinispan) GetPodDisruptionBudgetPolicy() PodDisruptionBudgetPolicy {
+	if pdb := ispn.GetPodDisruptionBudgetSpec(); pdb != nil && pdb.Policy != "" {
+		return pdb.Policy
+	}
+	return PodDisruptionBudgetMaxUnavailable
+}
+
 // GetGossipRouterDeploymentName returns the Gossip Router deployment name
 func (ispn *Infinispan) GetGossipRouterDeploymentName() string {
 	return fmt.Sprintf(GossipRouterDeploymentNameTemplate, ispn.Name)
 }
+
+// GetGossipRouterSpec returns the configuration of the Gossip Router, nil if not configured
+func (ispn *Infinispan) GetGossipRouterSpec() *GossipRouterSpec {
+	if ispn.Spec.Service.Sites == nil {
+		return nil
+	}
+	return ispn.Spec.Service.Sites.Local.GossipRouter
+}
+
+// GetGossipRouterReplicas returns the number of Gossip Router pods, none when the cluster is shut down
+func (ispn *Infinispan) GetGossipRouterReplicas() int32 {
+	if ispn.Spec.Replicas <= 0 {
+		return 0
+	}
+	if router := ispn.GetGossipRouterSpec(); router != nil && router.Replicas != nil {
+		return *router.Replicas
+	}
+	return 1
+}
+
+// GetGossipRouterTLS returns the TLS configuration of the Gossip Router, nil if the tunnel isn't secured
+func (ispn *Infinispan) GetGossipRouterTLS() *GossipRouterTLSSpec {
+	if router := ispn.GetGossipRouterSpec(); router != nil && router.TLS != nil && router.TLS.SecretName != "" {
+		return router.TLS
+	}
+	return nil
+}
+
+// GetGossipRouterTLSProtocol returns the TLS protocol of the connections to the Gossip Router
+func (ispn *Infinispan) GetGossipRouterTLSProtocol() string {
+	if tls := ispn.GetGossipRouterTLS(); tls != nil && tls.Protocol != "" {
+		return tls.Protocol
+	}
+	return DefaultGossipRouterTLSProtocol
+}
+
+// GetConfigExportName returns the name of the ConfigMap holding the exported server configuration of the cluster
+func (ispn *Infinispan) GetConfigExportName() string {
+	return fmt.Sprintf(ConfigExportNameTemplate, ispn.Name)
+}
+
+// GetDebugBundleJobName returns the name of the Job collecting the debug bundle of the cluster
+func (ispn *Infinispan) GetDebugBundleJobName() string {
+	return fmt.Sprintf(DebugBundleJobNameTemplate, ispn.Name)
+}
+
+// IsDataMigrationUpgrade true if the caches are migrated through a staging cluster on upgrade
+func (ispn *Infinispan) IsDataMigrationUpgrade() bool {
+	return ispn.Spec.Upgrades != nil && ispn.Spec.Upgrades.Type == UpgradeTypeDataMigration
+}
+
+// IsCanaryUpgrade true if the pods are upgraded one at a time, verifying the cluster health after each pod
+func (ispn *Infinispan) IsCanaryUpgrade() bool {
+	return ispn.Spec.Upgrades != nil && ispn.Spec.Upgrades.Type == UpgradeTypeCanary
+}
+
+// GetCanaryTimeout returns the time a Canary upgrade waits for the cluster to be healthy after upgrading a pod
+func (ispn *Infinispan) GetCanaryTimeout() time.Duration {
+	if ispn.Spec.Upgrades == nil || ispn.Spec.Upgrades.CanaryTimeoutSeconds == nil {
+		return consts.DefaultCanaryTimeout
+	}
+	return time.Duration(*ispn.Spec.Upgrades.CanaryTimeoutSeconds) * time.Second
+}
+
+// GetDataMigrationName returns the name of the StatefulSet and of the Service of the staging cluster of a DataMigration
+// upgrade
+func (ispn *Infinispan) GetDataMigrationName() string {
+	return fmt.Sprintf("%v-migration", ispn.Name)
+}
+
+// GetOperation returns the status of the last operation of the type, nil if none was executed
+func (ispn *Infinispan) GetOperation(operationType OperationType) *OperationStatus {
+	for i := range ispn.Status.Operations {
+		if ispn.Status.Operations[i].Type == operationType {
+			return &ispn.Status.Operations[i]
+		}
+	}
+	return nil
+}
+
+// SetOperation replaces the status of the last operation of the same type
+func (ispn *Infinispan) SetOperation(operation OperationStatus) {
+	if existing := ispn.GetOperation(operation.Type); existing != nil {
+		*existing = operation
+		return
+	}
+	ispn.Status.Operations = append(ispn.Status.Operations, operation)
+}
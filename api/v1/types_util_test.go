@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -72,3 +73,197 @@ func TestApplyOperatorLabels(t *testing.T) {
 		assert.True(t, reflect.DeepEqual(ispn.Labels, labelPodMap) || len(labelPodMap) == 0 && ispn.Labels == nil)
 	}
 }
+
+func TestApplyDefaultsEndpointSecretSource(t *testing.T) {
+	ispn := &Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan"}}
+	ispn.ApplyDefaults()
+	assert.Equal(t, "example-infinispan-generated-secret", ispn.Spec.Security.EndpointSecretName)
+	assert.True(t, ispn.IsGeneratedSecret())
+
+	// The generated Secret is dropped in favour of the target of the ExternalSecret
+	ispn.Spec.Security.EndpointSecret = &EndpointSecret{
+		Source:         EndpointSecretSourceExternalSecret,
+		ExternalSecret: &ExternalSecretSource{Name: "external-identities"},
+	}
+	ispn.ApplyDefaults()
+	assert.Empty(t, ispn.Spec.Security.EndpointSecretName)
+	assert.Equal(t, "external-identities", ispn.GetSecretName())
+
+	ispn.Spec.Security.EndpointSecretName = "connect-secret"
+	assert.Equal(t, "connect-secret", ispn.GetSecretName())
+}
+
+func TestGetExtraJvmOpts(t *testing.T) {
+	spec := &InfinispanContainerSpec{ExtraJvmOpts: "-Xmx512m "}
+	assert.Equal(t, "-Xmx512m ", spec.GetExtraJvmOpts(), "Extra JVM options unchanged without JVM arguments")
+
+	spec.JvmArgs = []string{"-XX:+UseG1GC", "-XX:+HeapDumpOnOutOfMemoryError"}
+	assert.Equal(t, "-Xmx512m -XX:+UseG1GC -XX:+HeapDumpOnOutOfMemoryError", spec.GetExtraJvmOpts())
+
+	spec.ExtraJvmOpts = ""
+	assert.Equal(t, "-XX:+UseG1GC -XX:+HeapDumpOnOutOfMemoryError", spec.GetExtraJvmOpts())
+}
+
+func TestIPv6JavaOptions(t *testing.T) {
+	ispn := &Infinispan{Spec: InfinispanSpec{Service: InfinispanServiceSpec{Type: ServiceTypeDataGrid}, Container: InfinispanContainerSpec{ExtraJvmOpts: "-Xmx512m"}}}
+	ispn.ApplyIPFamily("")
+	assert.False(t, ispn.IsIPv6())
+	assert.Equal(t, "-Xmx512m", ispn.GetJavaOptions())
+
+	ispn.ApplyIPFamily(corev1.IPv6Protocol)
+	assert.True(t, ispn.IsIPv6())
+	assert.Equal(t, "-Xmx512m -Djava.net.preferIPv6Addresses=true", ispn.GetJavaOptions())
+
+	// The IP families of the cluster aren't changed once set
+	ispn.ApplyIPFamily(corev1.IPv4Protocol)
+	assert.Equal(t, []corev1.IPFamily{corev1.IPv6Protocol}, ispn.Spec.IPFamilies)
+}
+
+func TestMemoryJavaOptions(t *testing.T) {
+	ispn := &Infinispan{Spec: InfinispanSpec{Service: InfinispanServiceSpec{Type: ServiceTypeDataGrid}, Container: InfinispanContainerSpec{Memory: "2Gi", ExtraJvmOpts: "-XX:+UseG1GC"}}}
+	assert.Equal(t, "-XX:+UseG1GC", ispn.GetJavaOptions())
+
+	// 25% of the memory is left to the JVM, the remaining 1536M are split between the heap and the off-heap limit
+	ispn.Spec.Container.MemoryPolicy = MemoryPolicyAuto
+	assert.Equal(t, "-Xmx1152M -Xms1152M -XX:MaxDirectMemorySize=384M -XX:+UseG1GC", ispn.GetJavaOptions())
+
+	ispn.Spec.Container.MemoryHeadroomPercent = 50
+	assert.Equal(t, "-Xmx768M -Xms768M -XX:MaxDirectMemorySize=256M", ispn.Spec.Container.GetMemoryJavaOptions())
+}
+
+func TestGetHeapSizeBytes(t *testing.T) {
+	spec := &InfinispanContainerSpec{Memory: "2Gi"}
+	heap, err := spec.GetHeapSizeBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024*1024*1024), heap)
+
+	spec.MemoryPolicy = MemoryPolicyAuto
+	heap, err = spec.GetHeapSizeBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1152*1024*1024), heap)
+
+	// The -Xmx of the extra JVM options takes precedence
+	spec.ExtraJvmOpts = "-Xmx512m"
+	spec.JvmArgs = []string{"-Xmx1G"}
+	heap, err = spec.GetHeapSizeBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024*1024*1024), heap)
+
+	spec.JvmArgs = []string{"-Xmxlots"}
+	_, err = spec.GetHeapSizeBytes()
+	assert.EqualError(t, err, "invalid JVM memory size 'lots'")
+}
+
+func TestSetCondition(t *testing.T) {
+	ispn := &Infinispan{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	assert.True(t, ispn.SetCondition(ConditionPrelimChecksPassed, metav1.ConditionTrue, ReasonPrelimChecksPassed, ""))
+	assert.False(t, ispn.SetCondition(ConditionPrelimChecksPassed, metav1.ConditionTrue, ReasonPrelimChecksPassed, ""))
+
+	c := ispn.GetCondition(ConditionPrelimChecksPassed)
+	assert.Equal(t, int64(2), c.ObservedGeneration)
+	assert.False(t, c.LastTransitionTime.IsZero())
+	assert.Equal(t, int64(2), ispn.Status.ObservedGeneration)
+
+	// Conditions are re-evaluated for new generations
+	ispn.Generation = 3
+	assert.True(t, ispn.SetCondition(ConditionPrelimChecksPassed, metav1.ConditionTrue, ReasonPrelimChecksPassed, ""))
+	assert.Equal(t, int64(3), ispn.GetCondition(ConditionPrelimChecksPassed).ObservedGeneration)
+
+	assert.True(t, ispn.RemoveCondition(ConditionPrelimChecksPassed))
+	assert.False(t, ispn.RemoveCondition(ConditionPrelimChecksPassed))
+	assert.Equal(t, metav1.ConditionFalse, ispn.GetCondition(ConditionPrelimChecksPassed).Status)
+}
+
+func TestSetConditionMigratesLegacyConditions(t *testing.T) {
+	ispn := &Infinispan{Status: InfinispanStatus{Conditions: []metav1.Condition{{Type: string(ConditionWellFormed), Status: metav1.ConditionTrue}}}}
+	ispn.SetCondition(ConditionPrelimChecksPassed, metav1.ConditionTrue, ReasonPrelimChecksPassed, "")
+	c := ispn.GetCondition(ConditionWellFormed)
+	assert.Equal(t, ReasonUnknown, c.Reason)
+	assert.False(t, c.LastTransitionTime.IsZero())
+}
+
+func TestSummaryConditions(t *testing.T) {
+	summary := func(ispn *Infinispan) map[ConditionType]string {
+		conditions := map[ConditionType]string{}
+		for _, condition := range []ConditionType{ConditionReady, ConditionProgressing, ConditionDegraded} {
+			c := ispn.GetCondition(condition)
+			conditions[condition] = string(c.Status) + "/" + c.Reason
+		}
+		return conditions
+	}
+
+	ispn := &Infinispan{}
+	ispn.SetCondition(ConditionPrelimChecksPassed, metav1.ConditionTrue, ReasonPrelimChecksPassed, "")
+	assert.Equal(t, map[ConditionType]string{ConditionReady: "False/PodsNotReady", ConditionProgressing: "True/PodsNotReady", ConditionDegraded: "False/AsExpected"}, summary(ispn))
+
+	ispn.SetCondition(ConditionWellFormed, metav1.ConditionTrue, ReasonClusterFormed, "View: pod-0")
+	assert.Equal(t, map[ConditionType]string{ConditionReady: "True/ClusterReady", ConditionProgressing: "False/AsExpected", ConditionDegraded: "False/AsExpected"}, summary(ispn))
+	assert.Equal(t, "View: pod-0", ispn.GetCondition(ConditionReady).Message)
+
+	ispn.SetCondition(ConditionUpgrade, metav1.ConditionTrue, ReasonUpgradeScheduled, "")
+	assert.Equal(t, map[ConditionType]string{ConditionReady: "False/UpgradeScheduled", ConditionProgressing: "True/UpgradeScheduled", ConditionDegraded: "False/AsExpected"}, summary(ispn))
+
+	ispn.SetCondition(ConditionUpgrade, metav1.ConditionFalse, ReasonUpgradeCompleted, "")
+	ispn.SetCondition(ConditionGracefulShutdown, metav1.ConditionTrue, ReasonClusterStopped, "")
+	assert.Equal(t, map[ConditionType]string{ConditionReady: "False/ClusterStopped", ConditionProgressing: "False/AsExpected", ConditionDegraded: "False/AsExpected"}, summary(ispn))
+
+	ispn.SetCondition(ConditionGracefulShutdown, metav1.ConditionFalse, ReasonClusterRecovering, "")
+	assert.Equal(t, map[ConditionType]string{ConditionReady: "False/ClusterRecovering", ConditionProgressing: "True/ClusterRecovering", ConditionDegraded: "False/AsExpected"}, summary(ispn))
+
+	ispn.SetCondition(ConditionGracefulShutdown, metav1.ConditionFalse, ReasonShutdownFailed, "shutdown failed")
+	assert.Equal(t, "True/ShutdownFailed", summary(ispn)[ConditionDegraded])
+	assert.Equal(t, "shutdown failed", ispn.GetCondition(ConditionDegraded).Message)
+
+	ispn.SetCondition(ConditionGracefulShutdown, metav1.ConditionTrue, ReasonClusterStopped, "")
+
+	ispn.SetCondition(ConditionPrelimChecksPassed, metav1.ConditionFalse, ReasonPrelimChecksFailed, "invalid spec")
+	assert.Equal(t, map[ConditionType]string{ConditionReady: "False/PreliminaryChecksFailed", ConditionProgressing: "False/AsExpected", ConditionDegraded: "True/PreliminaryChecksFailed"}, summary(ispn))
+	assert.Equal(t, "invalid spec", ispn.GetCondition(ConditionDegraded).Message)
+}
+
+func TestGetEndpointExpose(t *testing.T) {
+	ispn := &Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: InfinispanSpec{
+			Expose: &ExposeSpec{
+				Type:        ExposeTypeRoute,
+				Annotations: map[string]string{"key": "value"},
+				NodePort:    30222,
+				Rest:        &EndpointExposeSpec{Type: ExposeTypeLoadBalancer},
+			},
+		},
+	}
+	assert.Nil(t, ispn.GetEndpointExpose(ExposeEndpointHotRod), "Endpoints are exposed individually only with perEndpoint")
+
+	ispn.Spec.Expose.PerEndpoint = true
+	assert.Equal(t, &EndpointExposeSpec{Type: ExposeTypeRoute, Annotations: map[string]string{"key": "value"}}, ispn.GetEndpointExpose(ExposeEndpointHotRod))
+	assert.Equal(t, &EndpointExposeSpec{Type: ExposeTypeLoadBalancer}, ispn.GetEndpointExpose(ExposeEndpointRest))
+	assert.Nil(t, ispn.GetEndpointExpose(ExposeEndpointMemcached))
+	assert.False(t, ispn.IsMemcachedEnabled())
+
+	ispn.Spec.Expose.Memcached = &EndpointExposeSpec{Type: ExposeTypeNodePort}
+	assert.True(t, ispn.IsMemcachedEnabled())
+
+	assert.Equal(t, "example-infinispan-external-hotrod", ispn.GetEndpointExternalName(ExposeEndpointHotRod))
+	ispn.Name = "extra-long-cluster-name-d----------------------------d"
+	assert.Equal(t, "extra-long-cluster-name-d--------------hotrod", ispn.GetEndpointExternalName(ExposeEndpointHotRod), "Route expose long name")
+	assert.Equal(t, MaxRouteObjectNameLength, len(ispn.GetEndpointExternalName(ExposeEndpointHotRod))+len(namespace)+1, "Route expose name length")
+	assert.Equal(t, "extra-long-cluster-name-d----------------------------d-external-rest", ispn.GetEndpointExternalName(ExposeEndpointRest))
+}
+
+func TestIsServiceMonitorEnabled(t *testing.T) {
+	ispn := &Infinispan{}
+	assert.False(t, ispn.IsServiceMonitorEnabled())
+
+	ispn.Annotations = map[string]string{ServiceMonitoringAnnotation: "true"}
+	assert.True(t, ispn.IsServiceMonitorEnabled())
+
+	// The spec takes precedence over the annotation
+	enabled := false
+	ispn.Spec.Monitoring = &InfinispanMonitoringSpec{Enabled: &enabled}
+	assert.False(t, ispn.IsServiceMonitorEnabled())
+
+	enabled = true
+	ispn.Annotations[ServiceMonitoringAnnotation] = "false"
+	assert.True(t, ispn.IsServiceMonitorEnabled())
+}
@@ -6,6 +6,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -66,6 +67,98 @@ func (in *Autoscale) DeepCopy() *Autoscale {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryUpgradeStatus) DeepCopyInto(out *CanaryUpgradeStatus) {
+	*out = *in
+	in.PartitionTime.DeepCopyInto(&out.PartitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryUpgradeStatus.
+func (in *CanaryUpgradeStatus) DeepCopy() *CanaryUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerRef) DeepCopyInto(out *CertManagerIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerRef.
+func (in *CertManagerIssuerRef) DeepCopy() *CertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerSpec) DeepCopyInto(out *CertManagerSpec) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerSpec.
+func (in *CertManagerSpec) DeepCopy() *CertManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsoleExposeSpec) DeepCopyInto(out *ConsoleExposeSpec) {
+	*out = *in
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(ConsoleOIDCSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsoleExposeSpec.
+func (in *ConsoleExposeSpec) DeepCopy() *ConsoleExposeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsoleExposeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsoleOIDCSpec) DeepCopyInto(out *ConsoleOIDCSpec) {
+	*out = *in
+	if in.EmailDomains != nil {
+		in, out := &in.EmailDomains, &out.EmailDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsoleOIDCSpec.
+func (in *ConsoleOIDCSpec) DeepCopy() *ConsoleOIDCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsoleOIDCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossSiteExposeSpec) DeepCopyInto(out *CrossSiteExposeSpec) {
 	*out = *in
@@ -88,6 +181,80 @@ func (in *CrossSiteExposeSpec) DeepCopy() *CrossSiteExposeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrossSiteStateTransferSpec) DeepCopyInto(out *CrossSiteStateTransferSpec) {
+	*out = *in
+	if in.ChunkSize != nil {
+		in, out := &in.ChunkSize, &out.ChunkSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossSiteStateTransferSpec.
+func (in *CrossSiteStateTransferSpec) DeepCopy() *CrossSiteStateTransferSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CrossSiteStateTransferSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrossSiteStatus) DeepCopyInto(out *CrossSiteStatus) {
+	*out = *in
+	if in.StateTransfer != nil {
+		in, out := &in.StateTransfer, &out.StateTransfer
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossSiteStatus.
+func (in *CrossSiteStatus) DeepCopy() *CrossSiteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrossSiteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataMigrationStatus) DeepCopyInto(out *DataMigrationStatus) {
+	*out = *in
+	if in.CacheEntries != nil {
+		in, out := &in.CacheEntries, &out.CacheEntries
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataMigrationStatus.
+func (in *DataMigrationStatus) DeepCopy() *DataMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
 	*out = *in
@@ -121,6 +288,11 @@ func (in *DeploymentStatus) DeepCopy() *DeploymentStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointEncryption) DeepCopyInto(out *EndpointEncryption) {
 	*out = *in
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointEncryption.
@@ -133,6 +305,58 @@ func (in *EndpointEncryption) DeepCopy() *EndpointEncryption {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointExposeSpec) DeepCopyInto(out *EndpointExposeSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointExposeSpec.
+func (in *EndpointExposeSpec) DeepCopy() *EndpointExposeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointExposeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointSecret) DeepCopyInto(out *EndpointSecret) {
+	*out = *in
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultSecretSource)
+		**out = **in
+	}
+	if in.ExternalSecret != nil {
+		in, out := &in.ExternalSecret, &out.ExternalSecret
+		*out = new(ExternalSecretSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointSecret.
+func (in *EndpointSecret) DeepCopy() *EndpointSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExposeSpec) DeepCopyInto(out *ExposeSpec) {
 	*out = *in
@@ -143,6 +367,36 @@ func (in *ExposeSpec) DeepCopyInto(out *ExposeSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayParentReference)
+		**out = **in
+	}
+	if in.HotRod != nil {
+		in, out := &in.HotRod, &out.HotRod
+		*out = new(EndpointExposeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rest != nil {
+		in, out := &in.Rest, &out.Rest
+		*out = new(EndpointExposeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Memcached != nil {
+		in, out := &in.Memcached, &out.Memcached
+		*out = new(EndpointExposeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Console != nil {
+		in, out := &in.Console, &out.Console
+		*out = new(ConsoleExposeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposeSpec.
@@ -155,6 +409,76 @@ func (in *ExposeSpec) DeepCopy() *ExposeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretSource) DeepCopyInto(out *ExternalSecretSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretSource.
+func (in *ExternalSecretSource) DeepCopy() *ExternalSecretSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayParentReference) DeepCopyInto(out *GatewayParentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayParentReference.
+func (in *GatewayParentReference) DeepCopy() *GatewayParentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayParentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GossipRouterSpec) DeepCopyInto(out *GossipRouterSpec) {
+	*out = *in
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(GossipRouterTLSSpec)
+		**out = **in
+	}}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GossipRouterSpec.
+func (in *GossipRouterSpec) DeepCopy() *GossipRouterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GossipRouterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GossipRouterTLSSpec) DeepCopyInto(out *GossipRouterTLSSpec) {
+	*out = *in
+	*out = *in}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GossipRouterTLSSpec.
+func (in *GossipRouterTLSSpec) DeepCopy() *GossipRouterTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GossipRouterTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Infinispan) DeepCopyInto(out *Infinispan) {
 	*out = *in
@@ -198,31 +522,43 @@ func (in *InfinispanCloudEvents) DeepCopy() *InfinispanCloudEvents {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InfinispanCondition) DeepCopyInto(out *InfinispanCondition) {
+func (in *InfinispanContainerSpec) DeepCopyInto(out *InfinispanContainerSpec) {
 	*out = *in
+	if in.JvmArgs != nil {
+		in, out := &in.JvmArgs, &out.JvmArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanCondition.
-func (in *InfinispanCondition) DeepCopy() *InfinispanCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanContainerSpec.
+func (in *InfinispanContainerSpec) DeepCopy() *InfinispanContainerSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(InfinispanCondition)
+	out := new(InfinispanContainerSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InfinispanContainerSpec) DeepCopyInto(out *InfinispanContainerSpec) {
+func (in *InfinispanEndpointsSpec) DeepCopyInto(out *InfinispanEndpointsSpec) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanContainerSpec.
-func (in *InfinispanContainerSpec) DeepCopy() *InfinispanContainerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanEndpointsSpec.
+func (in *InfinispanEndpointsSpec) DeepCopy() *InfinispanEndpointsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(InfinispanContainerSpec)
+	out := new(InfinispanEndpointsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -232,86 +568,361 @@ func (in *InfinispanExternalArtifacts) DeepCopyInto(out *InfinispanExternalArtif
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanExternalArtifacts.
-func (in *InfinispanExternalArtifacts) DeepCopy() *InfinispanExternalArtifacts {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanExternalArtifacts.
+func (in *InfinispanExternalArtifacts) DeepCopy() *InfinispanExternalArtifacts {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanExternalArtifacts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanExternalDependencies) DeepCopyInto(out *InfinispanExternalDependencies) {
+	*out = *in
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]InfinispanExternalArtifacts, len(*in))
+		copy(*out, *in)
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanExternalDependencies.
+func (in *InfinispanExternalDependencies) DeepCopy() *InfinispanExternalDependencies {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanExternalDependencies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanJGroupsSpec) DeepCopyInto(out *InfinispanJGroupsSpec) {
+	*out = *in
+	if in.GossipRouters != nil {
+		in, out := &in.GossipRouters, &out.GossipRouters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanJGroupsSpec.
+func (in *InfinispanJGroupsSpec) DeepCopy() *InfinispanJGroupsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanJGroupsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanList) DeepCopyInto(out *InfinispanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Infinispan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanList.
+func (in *InfinispanList) DeepCopy() *InfinispanList {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InfinispanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanLogAlertsSpec) DeepCopyInto(out *InfinispanLogAlertsSpec) {
+	*out = *in
+	if in.WindowSeconds != nil {
+		in, out := &in.WindowSeconds, &out.WindowSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanLogAlertsSpec.
+func (in *InfinispanLogAlertsSpec) DeepCopy() *InfinispanLogAlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanLogAlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanLoggingSpec) DeepCopyInto(out *InfinispanLoggingSpec) {
+	*out = *in
+	if in.Categories != nil {
+		in, out := &in.Categories, &out.Categories
+		*out = make(map[string]LoggingLevelType, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(InfinispanLogAlertsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanLoggingSpec.
+func (in *InfinispanLoggingSpec) DeepCopy() *InfinispanLoggingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanLoggingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanMetadataSpec) DeepCopyInto(out *InfinispanMetadataSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanMetadataSpec.
+func (in *InfinispanMetadataSpec) DeepCopy() *InfinispanMetadataSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanMetadataSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanMonitoringSecuritySpec) DeepCopyInto(out *InfinispanMonitoringSecuritySpec) {
+	*out = *in
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanMonitoringSecuritySpec.
+func (in *InfinispanMonitoringSecuritySpec) DeepCopy() *InfinispanMonitoringSecuritySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanMonitoringSecuritySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanMonitoringSpec) DeepCopyInto(out *InfinispanMonitoringSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(InfinispanMonitoringSecuritySpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanMonitoringSpec.
+func (in *InfinispanMonitoringSpec) DeepCopy() *InfinispanMonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanMonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanPartitionHandlingSpec) DeepCopyInto(out *InfinispanPartitionHandlingSpec) {
+	*out = *in
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanPartitionHandlingSpec.
+func (in *InfinispanPartitionHandlingSpec) DeepCopy() *InfinispanPartitionHandlingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanPartitionHandlingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanPodDisruptionBudgetSpec) DeepCopyInto(out *InfinispanPodDisruptionBudgetSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanPodDisruptionBudgetSpec.
+func (in *InfinispanPodDisruptionBudgetSpec) DeepCopy() *InfinispanPodDisruptionBudgetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(InfinispanExternalArtifacts)
+	out := new(InfinispanPodDisruptionBudgetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InfinispanExternalDependencies) DeepCopyInto(out *InfinispanExternalDependencies) {
+func (in *InfinispanProbeSpec) DeepCopyInto(out *InfinispanProbeSpec) {
 	*out = *in
-	if in.Artifacts != nil {
-		in, out := &in.Artifacts, &out.Artifacts
-		*out = make([]InfinispanExternalArtifacts, len(*in))
-		copy(*out, *in)
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanExternalDependencies.
-func (in *InfinispanExternalDependencies) DeepCopy() *InfinispanExternalDependencies {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanProbeSpec.
+func (in *InfinispanProbeSpec) DeepCopy() *InfinispanProbeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(InfinispanExternalDependencies)
+	out := new(InfinispanProbeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InfinispanList) DeepCopyInto(out *InfinispanList) {
+func (in *InfinispanProbesSpec) DeepCopyInto(out *InfinispanProbesSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Infinispan, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(InfinispanProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(InfinispanProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(InfinispanProbeSpec)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanList.
-func (in *InfinispanList) DeepCopy() *InfinispanList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanProbesSpec.
+func (in *InfinispanProbesSpec) DeepCopy() *InfinispanProbesSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(InfinispanList)
+	out := new(InfinispanProbesSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InfinispanList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InfinispanLoggingSpec) DeepCopyInto(out *InfinispanLoggingSpec) {
+func (in *InfinispanSchedulingSpec) DeepCopyInto(out *InfinispanSchedulingSpec) {
 	*out = *in
-	if in.Categories != nil {
-		in, out := &in.Categories, &out.Categories
-		*out = make(map[string]LoggingLevelType, len(*in))
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(InfinispanPodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanLoggingSpec.
-func (in *InfinispanLoggingSpec) DeepCopy() *InfinispanLoggingSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanSchedulingSpec.
+func (in *InfinispanSchedulingSpec) DeepCopy() *InfinispanSchedulingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(InfinispanLoggingSpec)
+	out := new(InfinispanSchedulingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -329,11 +940,38 @@ func (in *InfinispanSecurity) DeepCopyInto(out *InfinispanSecurity) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.EndpointSecret != nil {
+		in, out := &in.EndpointSecret, &out.EndpointSecret
+		*out = new(EndpointSecret)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.EndpointEncryption != nil {
 		in, out := &in.EndpointEncryption, &out.EndpointEncryption
 		*out = new(EndpointEncryption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TransportEncryption != nil {
+		in, out := &in.TransportEncryption, &out.TransportEncryption
+		*out = new(TransportEncryption)
+		**out = **in
+	}
+	if in.Realm != nil {
+		in, out := &in.Realm, &out.Realm
+		*out = new(SecurityRealm)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(bool)
 		**out = **in
 	}
+	if in.NetworkPolicySources != nil {
+		in, out := &in.NetworkPolicySources, &out.NetworkPolicySources
+		*out = make([]NetworkPolicySource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanSecurity.
@@ -354,6 +992,11 @@ func (in *InfinispanServiceContainerSpec) DeepCopyInto(out *InfinispanServiceCon
 		*out = new(string)
 		**out = **in
 	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(InfinispanProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanServiceContainerSpec.
@@ -379,6 +1022,11 @@ func (in *InfinispanServiceSpec) DeepCopyInto(out *InfinispanServiceSpec) {
 		*out = new(InfinispanSitesSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PartitionHandling != nil {
+		in, out := &in.PartitionHandling, &out.PartitionHandling
+		*out = new(InfinispanPartitionHandlingSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanServiceSpec.
@@ -420,6 +1068,16 @@ func (in *InfinispanSiteLocationSpec) DeepCopy() *InfinispanSiteLocationSpec {
 func (in *InfinispanSitesLocalSpec) DeepCopyInto(out *InfinispanSitesLocalSpec) {
 	*out = *in
 	in.Expose.DeepCopyInto(&out.Expose)
+	if in.StateTransfer != nil {
+		in, out := &in.StateTransfer, &out.StateTransfer
+		*out = new(CrossSiteStateTransferSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GossipRouter != nil {
+		in, out := &in.GossipRouter, &out.GossipRouter
+		*out = new(GossipRouterSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanSitesLocalSpec.
@@ -464,7 +1122,7 @@ func (in *InfinispanSpec) DeepCopyInto(out *InfinispanSpec) {
 		**out = **in
 	}
 	in.Security.DeepCopyInto(&out.Security)
-	out.Container = in.Container
+	in.Container.DeepCopyInto(&out.Container)
 	in.Service.DeepCopyInto(&out.Service)
 	if in.Logging != nil {
 		in, out := &in.Logging, &out.Logging
@@ -496,6 +1154,53 @@ func (in *InfinispanSpec) DeepCopyInto(out *InfinispanSpec) {
 		*out = new(InfinispanExternalDependencies)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Upgrades != nil {
+		in, out := &in.Upgrades, &out.Upgrades
+		*out = new(InfinispanUpgradesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(InfinispanMonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(InfinispanSchedulingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(InfinispanMetadataSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(InfinispanEndpointsSpec)
+		**out = **in
+	}
+	if in.JGroups != nil {
+		in, out := &in.JGroups, &out.JGroups
+		*out = new(InfinispanJGroupsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(InfinispanTracingSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanSpec.
@@ -513,8 +1218,10 @@ func (in *InfinispanStatus) DeepCopyInto(out *InfinispanStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]InfinispanCondition, len(*in))
-		copy(*out, *in)
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Security != nil {
 		in, out := &in.Security, &out.Security
@@ -527,6 +1234,35 @@ func (in *InfinispanStatus) DeepCopyInto(out *InfinispanStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.DataMigration != nil {
+		in, out := &in.DataMigration, &out.DataMigration
+		*out = new(DataMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CanaryUpgrade != nil {
+		in, out := &in.CanaryUpgrade, &out.CanaryUpgrade
+		*out = new(CanaryUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rebalance != nil {
+		in, out := &in.Rebalance, &out.Rebalance
+		*out = new(RebalanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]OperationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.XSite != nil {
+		in, out := &in.XSite, &out.XSite
+		*out = make([]CrossSiteStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanStatus.
@@ -538,3 +1274,199 @@ func (in *InfinispanStatus) DeepCopy() *InfinispanStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanTracingSpec) DeepCopyInto(out *InfinispanTracingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanTracingSpec.
+func (in *InfinispanTracingSpec) DeepCopy() *InfinispanTracingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanTracingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfinispanUpgradesSpec) DeepCopyInto(out *InfinispanUpgradesSpec) {
+	*out = *in
+	if in.CanaryTimeoutSeconds != nil {
+		in, out := &in.CanaryTimeoutSeconds, &out.CanaryTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfinispanUpgradesSpec.
+func (in *InfinispanUpgradesSpec) DeepCopy() *InfinispanUpgradesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfinispanUpgradesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LdapRealm) DeepCopyInto(out *LdapRealm) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LdapRealm.
+func (in *LdapRealm) DeepCopy() *LdapRealm {
+	if in == nil {
+		return nil
+	}
+	out := new(LdapRealm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicySource) DeepCopyInto(out *NetworkPolicySource) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicySource.
+func (in *NetworkPolicySource) DeepCopy() *NetworkPolicySource {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OAuth2Realm) DeepCopyInto(out *OAuth2Realm) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OAuth2Realm.
+func (in *OAuth2Realm) DeepCopy() *OAuth2Realm {
+	if in == nil {
+		return nil
+	}
+	out := new(OAuth2Realm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStatus) DeepCopyInto(out *OperationStatus) {
+	*out = *in
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStatus.
+func (in *OperationStatus) DeepCopy() *OperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalanceStatus) DeepCopyInto(out *RebalanceStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Caches != nil {
+		in, out := &in.Caches, &out.Caches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceStatus.
+func (in *RebalanceStatus) DeepCopy() *RebalanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityRealm) DeepCopyInto(out *SecurityRealm) {
+	*out = *in
+	if in.Ldap != nil {
+		in, out := &in.Ldap, &out.Ldap
+		*out = new(LdapRealm)
+		**out = **in
+	}
+	if in.OAuth2 != nil {
+		in, out := &in.OAuth2, &out.OAuth2
+		*out = new(OAuth2Realm)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityRealm.
+func (in *SecurityRealm) DeepCopy() *SecurityRealm {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityRealm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransportEncryption) DeepCopyInto(out *TransportEncryption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportEncryption.
+func (in *TransportEncryption) DeepCopy() *TransportEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(TransportEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretSource) DeepCopyInto(out *VaultSecretSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretSource.
+func (in *VaultSecretSource) DeepCopy() *VaultSecretSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretSource)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -14,6 +14,45 @@ type BackupSpec struct {
 	Resources *BackupResources `json:"resources,omitempty"`
 	// +optional
 	Container v1.InfinispanContainerSpec `json:"container,omitempty"`
+	// Schedule in Cron format. When defined, this Backup is used as a template and a new Backup
+	// is created on each scheduled time. The name of a scheduled Backup must not exceed 52 characters.
+	// Backups created by the schedule are not deleted along with the scheduled Backup
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// Number of succeeded scheduled Backups to retain. Older Backups and their PVCs are deleted,
+	// as are failed Backups other than the most recent one. All Backups are retained when not defined
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Retention int32 `json:"retention,omitempty"`
+	// How a scheduled time is handled while a Backup created by the schedule is neither Succeeded nor Failed.
+	// Defaults to Forbid
+	// +optional
+	ConcurrencyPolicy BackupConcurrencyPolicyType `json:"concurrencyPolicy,omitempty"`
+	// Object storage that the backup archive is uploaded to once the backup completes. The archive is
+	// still written to the Backup PVC first
+	// +optional
+	Storage *BackupStorageSpec `json:"storage,omitempty"`
+	// Encrypts the backup archive with the key of a Secret once the server has written it. The same Secret must be
+	// provided to restore the archive
+	// +optional
+	Encryption *BackupEncryptionSpec `json:"encryption,omitempty"`
+}
+
+// BackupConcurrencyPolicyType specifies how the Backups of a schedule are allowed to run concurrently
+// +kubebuilder:validation:Enum=Forbid;Allow
+type BackupConcurrencyPolicyType string
+
+const (
+	// BackupConcurrencyForbid skips the scheduled time while a Backup of the schedule is in progress
+	BackupConcurrencyForbid BackupConcurrencyPolicyType = "Forbid"
+	// BackupConcurrencyAllow creates the Backup of each scheduled time, even if the previous ones are in progress
+	BackupConcurrencyAllow BackupConcurrencyPolicyType = "Allow"
+)
+
+// BackupEncryptionSpec the key that backup archives are encrypted with, using AES-GCM
+type BackupEncryptionSpec struct {
+	// Name of the Secret with the 16, 24 or 32 bytes AES key in the key field
+	SecretRef string `json:"secretRef"`
 }
 
 type BackupVolumeSpec struct {
@@ -23,6 +62,51 @@ type BackupVolumeSpec struct {
 	StorageClassName *string `json:"storageClassName,omitempty"`
 }
 
+// BackupStorageSpec object storage location of backup archives. Exactly one of s3, gcs or azure must be configured
+type BackupStorageSpec struct {
+	// +optional
+	S3 *S3StorageSpec `json:"s3,omitempty"`
+	// +optional
+	GCS *GCSStorageSpec `json:"gcs,omitempty"`
+	// +optional
+	Azure *AzureStorageSpec `json:"azure,omitempty"`
+}
+
+// S3StorageSpec Amazon S3, or S3 compatible, bucket
+type S3StorageSpec struct {
+	Bucket string `json:"bucket"`
+	// Prefix of the archive key, the archive is stored as <prefix>/<backup>.zip
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// +optional
+	Region string `json:"region,omitempty"`
+	// Endpoint URL of S3 compatible object storage
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+	// Name of the Secret with the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// GCSStorageSpec Google Cloud Storage bucket
+type GCSStorageSpec struct {
+	Bucket string `json:"bucket"`
+	// Prefix of the archive object name, the archive is stored as <prefix>/<backup>.zip
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Name of the Secret with the service account key in the credentials.json key
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// AzureStorageSpec Azure Blob Storage container
+type AzureStorageSpec struct {
+	Container string `json:"container"`
+	// Prefix of the archive blob name, the archive is stored as <prefix>/<backup>.zip
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Name of the Secret with the AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY keys
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
 type BackupResources struct {
 	// +optional
 	Caches []string `json:"caches,omitempty"`
@@ -70,6 +154,26 @@ type BackupStatus struct {
 	Reason string `json:"reason,omitempty"`
 	// The name of the created PersistentVolumeClaim used to store the backup
 	PVC string `json:"pvc,omitempty"`
+	// The object storage location of the uploaded backup archive
+	// +optional
+	Location string `json:"location,omitempty"`
+	// Last time a Backup was created for the schedule
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// The progress of the backup operation on the server
+	// +optional
+	Progress *OperationProgress `json:"progress,omitempty"`
+}
+
+// OperationProgress reports the progress of a Backup or Restore operation
+type OperationProgress struct {
+	// The number of caches included in the operation, 0 if the caches are only known to the server
+	CachesTotal int32 `json:"cachesTotal"`
+	// The number of caches processed by the operation
+	CachesDone int32 `json:"cachesDone"`
+	// The size in bytes of the created backup archive
+	// +optional
+	BytesWritten int64 `json:"bytesWritten,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -103,6 +207,15 @@ const (
 	DefaultBackupMemory   = "512Mi"
 )
 
+// MaxScheduledBackupNameLength is the maximum length of a scheduled Backup name, so that the created Backup
+// names, including the timestamp suffix, are valid label values
+const MaxScheduledBackupNameLength = 52
+
+// IsScheduled true if the Backup is a template for scheduled Backups
+func (b *Backup) IsScheduled() bool {
+	return b.Spec.Schedule != ""
+}
+
 func (s *BackupSpec) ApplyDefaults() {
 	if s.Container.CPU == "" {
 		s.Container.CPU = DefaultBackupCpuLimit
@@ -10,6 +10,32 @@ type BatchSpec struct {
 	Cluster   string  `json:"cluster"`
 	Config    *string `json:"config,omitempty"`
 	ConfigMap *string `json:"configMap,omitempty"`
+	// Keys of the ConfigMap, including binaryData keys, mounted as files at custom paths of the batch pod. All the keys
+	// are also available in /etc/batch
+	// +optional
+	ConfigMapFiles []BatchFileSpec `json:"configMapFiles,omitempty"`
+	// Secrets with credential files used by the batch, e.g. keystores, mounted in the batch pod
+	// +optional
+	Secrets []BatchSecretSpec `json:"secrets,omitempty"`
+}
+
+// BatchFileSpec mounts a key of the batch ConfigMap as a file
+type BatchFileSpec struct {
+	// The key of the ConfigMap
+	Key string `json:"key"`
+	// The absolute path of the file in the batch pod
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+}
+
+// BatchSecretSpec mounts the keys of a Secret as files
+type BatchSecretSpec struct {
+	// The name of the Secret
+	Name string `json:"name"`
+	// The absolute path of the directory the keys of the Secret are mounted in. Defaults to /etc/batch-secrets/<name>
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
 }
 
 type BatchPhase string
@@ -36,6 +62,24 @@ type BatchStatus struct {
 	Reason string `json:"reason,omitempty"`
 	// The UUID of the Infinispan instance that the Batch is associated with
 	ClusterUID *types.UID `json:"clusterUID,omitempty"`
+	// Status of the batch commands executed so far, in execution order
+	// +optional
+	Commands []BatchCommandStatus `json:"commands,omitempty"`
+	// Name of the ConfigMap containing the output of the batch commands
+	// +optional
+	LogConfigMap string `json:"logConfigMap,omitempty"`
+}
+
+// BatchCommandStatus defines the outcome of a single batch command
+type BatchCommandStatus struct {
+	// The command as written in the batch
+	Command string `json:"command"`
+	// Exit code of the command, unset while the command is running
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// Last lines written to stderr by the command
+	// +optional
+	Stderr string `json:"stderr,omitempty"`
 }
 
 // +kubebuilder:object:root=true
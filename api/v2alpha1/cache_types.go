@@ -30,14 +30,129 @@ type CacheSpec struct {
 	// Name of the cache to be created. If empty ObjectMeta.Name will be used
 	// +optional
 	Name string `json:"name,omitempty"`
-	// Cache template in XML format
+	// Cache template in XML, YAML or JSON format, detected from its content
 	// +optional
 	Template string `json:"template,omitempty"`
+	// Cache template in XML format
+	// +optional
+	TemplateXML string `json:"templateXML,omitempty"`
+	// Cache template in YAML format
+	// +optional
+	TemplateYAML string `json:"templateYAML,omitempty"`
+	// Cache template in JSON format
+	// +optional
+	TemplateJSON string `json:"templateJSON,omitempty"`
 	// Name of the template to be used to create this cache
 	// +optional
 	TemplateName string `json:"templateName,omitempty"`
+	// Name of the CacheTemplate, in the same namespace, whose configuration is used to create this cache
+	// +optional
+	TemplateRef string `json:"templateRef,omitempty"`
+	// Indexing configuration of the cache, including the Protobuf schemas to register on the cluster
+	// +optional
+	Indexing *CacheIndexingSpec `json:"indexing,omitempty"`
+	// Remote store offloading the cache entries to a cache of another Infinispan cluster
+	// +optional
+	RemoteStore *CacheRemoteStoreSpec `json:"remoteStore,omitempty"`
+	// How changes to the template of an existing cache are applied
+	// +optional
+	Updates *CacheUpdateSpec `json:"updates,omitempty"`
+	// Whether the cache is deleted from the cluster when the Cache CR is deleted. Defaults to Retain
+	// +optional
+	DeletionPolicy CacheDeletionPolicyType `json:"deletionPolicy,omitempty"`
 }
 
+// CacheDeletionPolicyType specifies what happens to the cache of the cluster when the Cache CR is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type CacheDeletionPolicyType string
+
+const (
+	// CacheDeletionRetain keeps the cache and its entries on the cluster
+	CacheDeletionRetain CacheDeletionPolicyType = "Retain"
+	// CacheDeletionDelete deletes the cache and its entries from the cluster
+	CacheDeletionDelete CacheDeletionPolicyType = "Delete"
+)
+
+// CacheUpdateStrategyType specifies how changes to the template that cannot be applied at runtime are handled
+// +kubebuilder:validation:Enum=retain;recreate
+type CacheUpdateStrategyType string
+
+const (
+	// CacheUpdateRetain keeps the existing cache and reports the changes with the RequiresRecreate condition
+	CacheUpdateRetain CacheUpdateStrategyType = "retain"
+	// CacheUpdateRecreate deletes and recreates the cache with the new template, discarding its entries
+	CacheUpdateRecreate CacheUpdateStrategyType = "recreate"
+)
+
+// CacheUpdateSpec defines how the changes to the template of an existing cache are applied. Changes to attributes
+// that can be changed at runtime are always applied in place
+type CacheUpdateSpec struct {
+	// How changes that cannot be applied in place are handled. Defaults to retain
+	// +optional
+	Strategy CacheUpdateStrategyType `json:"strategy,omitempty"`
+	// How changes made to the live cache configuration outside of the Cache CR are handled. Defaults to Revert
+	// +optional
+	DriftPolicy CacheDriftPolicyType `json:"driftPolicy,omitempty"`
+}
+
+// CacheDriftPolicyType specifies how out-of-band changes to the live cache configuration are handled
+// +kubebuilder:validation:Enum=Report;Revert
+type CacheDriftPolicyType string
+
+const (
+	// CacheDriftReport only reports the changes with status.drifted
+	CacheDriftReport CacheDriftPolicyType = "Report"
+	// CacheDriftRevert reverts the changes, recreating the cache only when spec.updates.strategy is recreate
+	CacheDriftRevert CacheDriftPolicyType = "Revert"
+)
+
+// CacheRemoteStoreSpec defines the remote cache, of an Infinispan cluster in the same or a different namespace, that the
+// cache entries are offloaded to
+type CacheRemoteStoreSpec struct {
+	// Name of the Infinispan cluster hosting the remote cache
+	ClusterName string `json:"clusterName"`
+	// Namespace of the Infinispan cluster, defaults to the namespace of the Cache. A cluster in another namespace must
+	// allow the namespace of the Cache with the infinispan.org/remote-store-namespaces annotation
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the remote cache, defaults to the name of the cache
+	// +optional
+	CacheName string `json:"cacheName,omitempty"`
+	// User of the remote cluster identities used to connect, defaults to the first user of its identities Secret
+	// +optional
+	Username string `json:"username,omitempty"`
+}
+
+// CacheIndexingSpec defines the Protobuf schemas and the indexed entities of a cache
+type CacheIndexingSpec struct {
+	// Protobuf schemas to register on the cluster before the cache is created
+	// +optional
+	Schemas []ProtobufSchemaSpec `json:"schemas,omitempty"`
+	// Fully qualified names of the Protobuf message types to be indexed
+	// +optional
+	IndexedEntities []string `json:"indexedEntities,omitempty"`
+}
+
+// ProtobufSchemaSpec defines a Protobuf schema to be registered on the cluster
+type ProtobufSchemaSpec struct {
+	// Name of the schema file
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_./-]+\.proto$`
+	Name string `json:"name"`
+	// Protobuf schema definition
+	Schema string `json:"schema"`
+}
+
+const (
+	// CacheConditionReady means that the cache exists on the cluster
+	CacheConditionReady = "Ready"
+	// CacheConditionRequiresRecreate means that the template changed attributes that can only be changed by recreating
+	// the cache
+	CacheConditionRequiresRecreate = "RequiresRecreate"
+	// CacheConditionUnsupportedFeatures means that the cache configuration uses features that the service type of the
+	// cluster doesn't support
+	CacheConditionUnsupportedFeatures = "UnsupportedFeatures"
+)
+
 // CacheCondition define a condition of the cluster
 type CacheCondition struct {
 	// Type is the type of the condition.
@@ -49,6 +164,28 @@ type CacheCondition struct {
 	Message string `json:"message,omitempty"`
 }
 
+type CacheRebalanceState string
+
+const (
+	// CacheBalanced means that the cache data is evenly distributed across the cluster
+	CacheBalanced CacheRebalanceState = "Balanced"
+	// CacheRebalancing means that the cache data is being redistributed across the cluster
+	CacheRebalancing CacheRebalanceState = "Rebalancing"
+	// CacheRebalancingDisabled means that rebalancing is disabled for the cache
+	CacheRebalancingDisabled CacheRebalanceState = "Disabled"
+)
+
+// CacheStatistics defines the statistics of a cache, as reported by the cluster
+type CacheStatistics struct {
+	// Approximate number of entries in the cache
+	Entries int64 `json:"entries"`
+	// Approximate amount of memory used by the cache entries in bytes, only available for caches bounded by memory
+	// +optional
+	MemoryUsedBytes int64 `json:"memoryUsedBytes,omitempty"`
+	// Rebalance state of the cache
+	RebalanceState CacheRebalanceState `json:"rebalanceState"`
+}
+
 // CacheStatus defines the observed state of Cache
 type CacheStatus struct {
 	// Conditions list for this cache
@@ -57,8 +194,41 @@ type CacheStatus struct {
 	// Service name that exposes the cache inside the cluster
 	// +optional
 	ServiceName string `json:"serviceName,omitempty"`
+	// Statistics of the cache, periodically refreshed
+	// +optional
+	Statistics *CacheStatistics `json:"statistics,omitempty"`
+	// Hash of the CacheTemplate or spec.template configuration the cache was last created or updated with
+	// +optional
+	TemplateHash string `json:"templateHash,omitempty"`
+	// Canonical JSON form, as converted by the cluster, of the spec template configuration the cache was last created
+	// or updated with
+	// +optional
+	CanonicalTemplate string `json:"canonicalTemplate,omitempty"`
+	// Whether the live cache configuration differs from the configuration it was last created or updated with
+	// +optional
+	Drifted bool `json:"drifted,omitempty"`
+	// Whether the cache was created by the operator or adopted from the cluster where it already existed
+	// +optional
+	Origin CacheOrigin `json:"origin,omitempty"`
+	// Hash of the remote store configuration, credentials included, and of the spec.template the cache was last
+	// created with
+	// +optional
+	RemoteStoreHash string `json:"remoteStoreHash,omitempty"`
+	// Hash of each Protobuf schema of spec.indexing last registered on the cluster, by schema name
+	// +optional
+	SchemaHashes map[string]string `json:"schemaHashes,omitempty"`
 }
 
+// CacheOrigin specifies how the cache of the cluster came to be managed by the Cache CR
+type CacheOrigin string
+
+const (
+	// CacheOriginCreated means that the operator created the cache
+	CacheOriginCreated CacheOrigin = "Created"
+	// CacheOriginAdopted means that the cache already existed with the configuration of the Cache CR
+	CacheOriginAdopted CacheOrigin = "Adopted"
+)
+
 // +kubebuilder:object:root=true
 
 // Cache is the Schema for the caches API
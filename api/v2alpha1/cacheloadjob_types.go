@@ -0,0 +1,128 @@
+package v2alpha1
+
+// IMPORTANT: run "make codegen" or "operator-sdk generate k8s" to regenerate code after modifying this file
+// NOTE: json tags are required. Any new fields you add must have json tags for the fields to be serialized.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CacheLoadFormat is the format of the data file loaded into the cache
+// +kubebuilder:validation:Enum=CSV;JSON
+type CacheLoadFormat string
+
+const (
+	// CacheLoadFormatCSV is a CSV file whose first column is the key and second column the value of the entries
+	CacheLoadFormatCSV CacheLoadFormat = "CSV"
+	// CacheLoadFormatJSON is a JSON object whose members are the entries
+	CacheLoadFormatJSON CacheLoadFormat = "JSON"
+)
+
+// CacheLoadJobSpec defines the desired state of CacheLoadJob
+type CacheLoadJobSpec struct {
+	// Name of the Infinispan cluster
+	Cluster string `json:"cluster"`
+	// Name of the cache the entries are loaded into, the cache must exist
+	CacheName string `json:"cacheName"`
+	// Format of the data file
+	Format CacheLoadFormat `json:"format"`
+	// Location of the data file
+	Source CacheLoadSourceSpec `json:"source"`
+	// Media type of the values, defaults to text/plain for CSV and application/json for JSON
+	// +optional
+	ValueMediaType string `json:"valueMediaType,omitempty"`
+	// Number of entries written concurrently to the cache. Defaults to 10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency int32 `json:"concurrency,omitempty"`
+	// Number of entries that can fail to be written before the load fails. Defaults to 0
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxErrors int32 `json:"maxErrors,omitempty"`
+}
+
+// CacheLoadSourceSpec location of the data file. Exactly one of volume or objectStorage must be configured
+type CacheLoadSourceSpec struct {
+	// +optional
+	Volume *CacheLoadVolumeSource `json:"volume,omitempty"`
+	// +optional
+	ObjectStorage *CacheLoadObjectStorageSource `json:"objectStorage,omitempty"`
+}
+
+// CacheLoadVolumeSource data file stored in a PersistentVolumeClaim
+type CacheLoadVolumeSource struct {
+	// Name of the PersistentVolumeClaim, in the same namespace
+	ClaimName string `json:"claimName"`
+	// Path of the data file in the volume
+	Path string `json:"path"`
+}
+
+// CacheLoadObjectStorageSource data file stored in object storage. Exactly one of s3, gcs or azure must be configured
+type CacheLoadObjectStorageSource struct {
+	BackupStorageSpec `json:",inline"`
+	// Key of the data file, relative to the prefix
+	Key string `json:"key"`
+}
+
+type CacheLoadJobPhase string
+
+const (
+	// CacheLoadJobInitializing means that the CacheLoadJob is waiting for the cluster to be ready
+	CacheLoadJobInitializing CacheLoadJobPhase = "Initializing"
+	// CacheLoadJobRunning means that the Job loading the entries has been created
+	CacheLoadJobRunning CacheLoadJobPhase = "Running"
+	// CacheLoadJobSucceeded means that all the entries of the data file have been processed
+	CacheLoadJobSucceeded CacheLoadJobPhase = "Succeeded"
+	// CacheLoadJobFailed means that the data file couldn't be loaded
+	CacheLoadJobFailed CacheLoadJobPhase = "Failed"
+)
+
+// CacheLoadJobStatus defines the observed state of CacheLoadJob
+type CacheLoadJobStatus struct {
+	// Current phase of the load
+	// +optional
+	Phase CacheLoadJobPhase `json:"phase,omitempty"`
+	// Reason of the failure of the load
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// The UUID of the Infinispan instance that the entries are loaded into
+	// +optional
+	ClusterUID *types.UID `json:"clusterUID,omitempty"`
+	// Number of entries written to the cache
+	// +optional
+	Loaded int64 `json:"loaded,omitempty"`
+	// Number of entries that couldn't be written to the cache
+	// +optional
+	Failed int64 `json:"failed,omitempty"`
+	// Last errors reported for the entries that couldn't be written
+	// +optional
+	Errors []string `json:"errors,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CacheLoadJob is the Schema for the cacheloadjobs API. The entries of a CSV or JSON data file are written to a cache
+// of the cluster by a Job
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=cacheloadjobs,scope=Namespaced
+type CacheLoadJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CacheLoadJobSpec   `json:"spec,omitempty"`
+	Status CacheLoadJobStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CacheLoadJobList contains a list of CacheLoadJob
+type CacheLoadJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CacheLoadJob `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CacheLoadJob{}, &CacheLoadJobList{})
+}
@@ -0,0 +1,65 @@
+package v2alpha1
+
+// IMPORTANT: run "make codegen" or "operator-sdk generate k8s" to regenerate code after modifying this file
+// NOTE: json tags are required. Any new fields you add must have json tags for the fields to be serialized.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CachePolicySpec defines the desired state of CachePolicy
+type CachePolicySpec struct {
+	// Name of the cluster whose caches the policy applies to
+	ClusterName string `json:"clusterName"`
+	// Shell pattern matched against the names of the caches of the cluster, e.g. session-*
+	CacheNamePattern string `json:"cacheNamePattern"`
+	// Name of the CacheTemplate, in the same namespace, whose configuration is applied to the matching caches
+	TemplateRef string `json:"templateRef"`
+	// Whether the caches are deleted from the cluster when their Cache CR is deleted. Defaults to Retain
+	// +optional
+	DeletionPolicy CacheDeletionPolicyType `json:"deletionPolicy,omitempty"`
+}
+
+const (
+	// CachePolicyConditionReady means that the caches matching the policy are managed by Cache CRs
+	CachePolicyConditionReady = "Ready"
+
+	// CachePolicyLabel is the label of the Cache CRs created by a CachePolicy, with the name of the policy
+	CachePolicyLabel = "infinispan.org/cache-policy"
+)
+
+// CachePolicyStatus defines the observed state of CachePolicy
+type CachePolicyStatus struct {
+	// Conditions list for this policy
+	// +optional
+	Conditions []CacheCondition `json:"conditions,omitempty"`
+	// Names of the caches of the cluster matching the policy
+	// +optional
+	Caches []string `json:"caches,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CachePolicy is the Schema for the cachepolicies API. The caches of the cluster whose name matches the pattern of
+// the policy are managed by Cache CRs created with the CacheTemplate of the policy
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=cachepolicies,scope=Namespaced
+type CachePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CachePolicySpec   `json:"spec,omitempty"`
+	Status CachePolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// CachePolicyList contains a list of CachePolicy
+type CachePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CachePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CachePolicy{}, &CachePolicyList{})
+}
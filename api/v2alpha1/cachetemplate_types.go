@@ -0,0 +1,54 @@
+package v2alpha1
+
+// IMPORTANT: run "make codegen" or "operator-sdk generate k8s" to regenerate code after modifying this file
+// NOTE: json tags are required. Any new fields you add must have json tags for the fields to be serialized.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CacheTemplateUpdatePolicy specifies how the caches created from a template are updated when the template changes
+// +kubebuilder:validation:Enum=None;Update;Recreate
+type CacheTemplateUpdatePolicy string
+
+const (
+	// CacheTemplateUpdateNone leaves existing caches unchanged, only caches created afterwards use the new template
+	CacheTemplateUpdateNone CacheTemplateUpdatePolicy = "None"
+	// CacheTemplateUpdateUpdate applies the new template to existing caches, which fails if an immutable attribute changed
+	CacheTemplateUpdateUpdate CacheTemplateUpdatePolicy = "Update"
+	// CacheTemplateUpdateRecreate deletes and recreates existing caches with the new template, discarding their entries
+	CacheTemplateUpdateRecreate CacheTemplateUpdatePolicy = "Recreate"
+)
+
+// CacheTemplateSpec defines the desired state of CacheTemplate
+type CacheTemplateSpec struct {
+	// Cache configuration in XML, JSON or YAML format
+	Template string `json:"template"`
+	// How caches referencing the template are updated when it changes. Defaults to None
+	// +optional
+	UpdatePolicy CacheTemplateUpdatePolicy `json:"updatePolicy,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CacheTemplate is the Schema for the cachetemplates API. Caches in the same namespace reference it with
+// spec.templateRef to be created with its configuration
+// +kubebuilder:resource:path=cachetemplates,scope=Namespaced
+type CacheTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CacheTemplateSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// CacheTemplateList contains a list of CacheTemplate
+type CacheTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CacheTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CacheTemplate{}, &CacheTemplateList{})
+}
@@ -0,0 +1,94 @@
+package v2alpha1
+
+// IMPORTANT: run "make codegen" or "operator-sdk generate k8s" to regenerate code after modifying this file
+// NOTE: json tags are required. Any new fields you add must have json tags for the fields to be serialized.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CounterType specifies the consistency of a counter
+// +kubebuilder:validation:Enum=strong;weak
+type CounterType string
+
+const (
+	// CounterStrong is a counter whose value is updated atomically and can be bounded
+	CounterStrong CounterType = "strong"
+	// CounterWeak is a counter whose updates are applied asynchronously, for higher throughput
+	CounterWeak CounterType = "weak"
+)
+
+// CounterStorageType specifies whether the value of a counter is persisted
+// +kubebuilder:validation:Enum=VOLATILE;PERSISTENT
+type CounterStorageType string
+
+const (
+	// CounterVolatile counters lose their value when the cluster restarts
+	CounterVolatile CounterStorageType = "VOLATILE"
+	// CounterPersistent counters keep their value across cluster restarts
+	CounterPersistent CounterStorageType = "PERSISTENT"
+)
+
+// CounterSpec defines the desired state of Counter
+type CounterSpec struct {
+	// Name of the cluster where to create the counter
+	ClusterName string `json:"clusterName"`
+	// Name of the counter to be created. If empty ObjectMeta.Name will be used
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Type of the counter. Defaults to strong
+	// +optional
+	Type CounterType `json:"type,omitempty"`
+	// Initial value of the counter
+	// +optional
+	InitialValue int64 `json:"initialValue,omitempty"`
+	// Lower bound of a strong counter
+	// +optional
+	LowerBound *int64 `json:"lowerBound,omitempty"`
+	// Upper bound of a strong counter
+	// +optional
+	UpperBound *int64 `json:"upperBound,omitempty"`
+	// Storage of the counter value. Defaults to VOLATILE
+	// +optional
+	Storage CounterStorageType `json:"storage,omitempty"`
+}
+
+const (
+	// CounterConditionReady means that the counter exists on the cluster with the configuration of the spec
+	CounterConditionReady = "Ready"
+)
+
+// CounterStatus defines the observed state of Counter
+type CounterStatus struct {
+	// Conditions list for this counter
+	// +optional
+	Conditions []CacheCondition `json:"conditions,omitempty"`
+	// Current value of the counter, periodically refreshed
+	// +optional
+	Value *int64 `json:"value,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Counter is the Schema for the counters API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=counters,scope=Namespaced
+type Counter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CounterSpec   `json:"spec,omitempty"`
+	Status CounterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// CounterList contains a list of Counter
+type CounterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Counter `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Counter{}, &CounterList{})
+}
@@ -0,0 +1,54 @@
+package v2alpha1
+
+// IMPORTANT: run "make codegen" or "operator-sdk generate k8s" to regenerate code after modifying this file
+// NOTE: json tags are required. Any new fields you add must have json tags for the fields to be serialized.
+
+import (
+	v1 "github.com/infinispan/infinispan-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InfinispanSiteSpec defines the connection to a remote site
+type InfinispanSiteSpec struct {
+	// URL of the remote site, with the same schemes as the url of an Infinispan site location
+	// +kubebuilder:validation:Pattern=`(^(kubernetes|minikube|openshift):\/\/(([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])\.)*([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])*(:[0-9]+)+$)|(^(infinispan\+xsite):\/\/(([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])\.)*([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])*(:[0-9]+)*$)`
+	URL string `json:"url"`
+	// The Secret holding the credentials of the remote API server. It is looked up in the namespace of each Infinispan
+	// referencing the site
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// Namespace of the remote Infinispan cluster, defaults to the namespace of the Infinispan referencing the site
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the remote Infinispan cluster, defaults to the name of the Infinispan referencing the site
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+	// The expected type of the Service exposing the remote site. The cross-site configuration is not applied while the
+	// remote Service has another type
+	// +optional
+	ExposeType v1.CrossSiteExposeType `json:"exposeType,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// InfinispanSite is the Schema for the infinispansites API. Infinispan site locations reference it with site, so that
+// the connection to a remote site is described once for all the clusters backing up to it
+// +kubebuilder:resource:path=infinispansites,scope=Cluster
+type InfinispanSite struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec InfinispanSiteSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// InfinispanSiteList contains a list of InfinispanSite
+type InfinispanSiteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []InfinispanSite `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InfinispanSite{}, &InfinispanSiteList{})
+}
@@ -13,6 +13,13 @@ type RestoreSpec struct {
 	Resources *RestoreResources `json:"resources,omitempty"`
 	// +optional
 	Container v1.InfinispanContainerSpec `json:"container,omitempty"`
+	// Object storage that the archive of the backup is downloaded from. The Backup doesn't need to exist,
+	// which allows to restore archives created by Backups in other namespaces or clusters
+	// +optional
+	Storage *BackupStorageSpec `json:"storage,omitempty"`
+	// Decrypts the backup archive with the key of the Secret the Backup was encrypted with
+	// +optional
+	Encryption *BackupEncryptionSpec `json:"encryption,omitempty"`
 }
 
 type RestoreResources struct {
@@ -60,6 +67,9 @@ type RestoreStatus struct {
 	Phase RestorePhase `json:"phase"`
 	// Reason indicates the reason for any Restore related failures.
 	Reason string `json:"reason,omitempty"`
+	// The progress of the restore operation on the server
+	// +optional
+	Progress *OperationProgress `json:"progress,omitempty"`
 }
 
 // +kubebuilder:object:root=true
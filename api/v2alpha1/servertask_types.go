@@ -0,0 +1,89 @@
+package v2alpha1
+
+// IMPORTANT: run "make codegen" or "operator-sdk generate k8s" to regenerate code after modifying this file
+// NOTE: json tags are required. Any new fields you add must have json tags for the fields to be serialized.
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServerTaskType specifies how a server task is deployed
+// +kubebuilder:validation:Enum=Script;Java
+type ServerTaskType string
+
+const (
+	// ServerTaskScript tasks are JavaScript scripts uploaded to the task registry of the cluster by the operator
+	ServerTaskScript ServerTaskType = "Script"
+	// ServerTaskJava tasks are Java classes deployed to the server with the cluster dependencies, the operator only
+	// reports whether they are registered
+	ServerTaskJava ServerTaskType = "Java"
+)
+
+// ServerTaskSpec defines the desired state of ServerTask
+type ServerTaskSpec struct {
+	// Name of the cluster where to deploy the task
+	ClusterName string `json:"clusterName"`
+	// Name of the task on the cluster. If empty ObjectMeta.Name will be used
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Type of the task. Defaults to Script
+	// +optional
+	Type ServerTaskType `json:"type,omitempty"`
+	// The JavaScript source of a Script task
+	// +optional
+	Script *string `json:"script,omitempty"`
+	// The ConfigMap key holding the JavaScript source of a Script task
+	// +optional
+	ScriptConfigMap *corev1.ConfigMapKeySelector `json:"scriptConfigMap,omitempty"`
+	// The role required to execute a Script task. Any user with the EXEC permission can execute the task when empty
+	// +optional
+	Role string `json:"role,omitempty"`
+}
+
+const (
+	// ServerTaskConditionReady means that the task is registered on the cluster with the spec
+	ServerTaskConditionReady = "Ready"
+)
+
+// ServerTaskStatus defines the observed state of ServerTask
+type ServerTaskStatus struct {
+	// Conditions list for this task
+	// +optional
+	Conditions []CacheCondition `json:"conditions,omitempty"`
+	// Hash of the script uploaded to the cluster
+	// +optional
+	ScriptHash string `json:"scriptHash,omitempty"`
+	// Execution mode of the task, as reported by the cluster
+	// +optional
+	ExecutionMode string `json:"executionMode,omitempty"`
+	// The role required to execute the task, as reported by the cluster. Empty when any user with the EXEC permission
+	// can execute it
+	// +optional
+	AllowedRole string `json:"allowedRole,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ServerTask is the Schema for the servertasks API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=servertasks,scope=Namespaced
+type ServerTask struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ServerTaskSpec   `json:"spec,omitempty"`
+	Status ServerTaskStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// ServerTaskList contains a list of ServerTask
+type ServerTaskList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ServerTask `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ServerTask{}, &ServerTaskList{})
+}
@@ -26,6 +26,16 @@ func (cache *Cache) SetCondition(condition string, status metav1.ConditionStatus
 	return true
 }
 
+// GetCondition returns the condition of the given type, with a False status if the condition is not present
+func (cache *Cache) GetCondition(condition string) CacheCondition {
+	for _, c := range cache.Status.Conditions {
+		if c.Type == condition {
+			return c
+		}
+	}
+	return CacheCondition{Type: condition, Status: metav1.ConditionFalse}
+}
+
 func (cache *Cache) GetCacheName() string {
 	cacheName := cache.Name
 	if cache.Spec.Name != "" {
@@ -33,3 +43,136 @@ func (cache *Cache) GetCacheName() string {
 	}
 	return cacheName
 }
+
+// GetTemplate returns the configuration of the first of spec.template, spec.templateXML, spec.templateYAML and
+// spec.templateJSON that is set, with its media type. The media type of spec.template is empty as its format is
+// detected from its content
+func (cache *Cache) GetTemplate() (string, string) {
+	spec := cache.Spec
+	switch {
+	case spec.Template != "":
+		return spec.Template, ""
+	case spec.TemplateXML != "":
+		return spec.TemplateXML, "application/xml"
+	case spec.TemplateYAML != "":
+		return spec.TemplateYAML, "application/yaml"
+	case spec.TemplateJSON != "":
+		return spec.TemplateJSON, "application/json"
+	}
+	return "", ""
+}
+
+// GetUpdateStrategy returns how changes to the template that cannot be applied in place are handled
+func (cache *Cache) GetUpdateStrategy() CacheUpdateStrategyType {
+	if cache.Spec.Updates == nil || cache.Spec.Updates.Strategy == "" {
+		return CacheUpdateRetain
+	}
+	return cache.Spec.Updates.Strategy
+}
+
+// GetDeletionPolicy returns whether the cache is deleted from the cluster when the Cache CR is deleted
+func (cache *Cache) GetDeletionPolicy() CacheDeletionPolicyType {
+	if cache.Spec.DeletionPolicy == "" {
+		return CacheDeletionRetain
+	}
+	return cache.Spec.DeletionPolicy
+}
+
+// IsManaged returns true once the cache has been created or adopted by the Cache CR. The status of Caches that
+// predate adoption has no origin, but has a service name once the cache exists
+func (cache *Cache) IsManaged() bool {
+	return cache.Status.Origin != "" || cache.Status.ServiceName != ""
+}
+
+// GetDriftPolicy returns how out-of-band changes to the live cache configuration are handled
+func (cache *Cache) GetDriftPolicy() CacheDriftPolicyType {
+	if cache.Spec.Updates == nil || cache.Spec.Updates.DriftPolicy == "" {
+		return CacheDriftRevert
+	}
+	return cache.Spec.Updates.DriftPolicy
+}
+
+// SetCondition set condition to status
+func (counter *Counter) SetCondition(condition string, status metav1.ConditionStatus, message string) bool {
+	for idx := range counter.Status.Conditions {
+		c := &counter.Status.Conditions[idx]
+		if c.Type == condition {
+			changed := c.Status != status || c.Message != message
+			c.Status = status
+			c.Message = message
+			return changed
+		}
+	}
+	counter.Status.Conditions = append(counter.Status.Conditions, CacheCondition{Type: condition, Status: status, Message: message})
+	return true
+}
+
+// GetCounterName returns the name of the counter on the cluster
+func (counter *Counter) GetCounterName() string {
+	if counter.Spec.Name != "" {
+		return counter.Spec.Name
+	}
+	return counter.Name
+}
+
+// GetType returns the type of the counter, strong by default
+func (counter *Counter) GetType() CounterType {
+	if counter.Spec.Type == "" {
+		return CounterStrong
+	}
+	return counter.Spec.Type
+}
+
+// GetStorage returns the storage of the counter, volatile by default
+func (counter *Counter) GetStorage() CounterStorageType {
+	if counter.Spec.Storage == "" {
+		return CounterVolatile
+	}
+	return counter.Spec.Storage
+}
+
+// SetCondition set condition to status
+func (task *ServerTask) SetCondition(condition string, status metav1.ConditionStatus, message string) bool {
+	for idx := range task.Status.Conditions {
+		c := &task.Status.Conditions[idx]
+		if c.Type == condition {
+			changed := c.Status != status || c.Message != message
+			c.Status = status
+			c.Message = message
+			return changed
+		}
+	}
+	task.Status.Conditions = append(task.Status.Conditions, CacheCondition{Type: condition, Status: status, Message: message})
+	return true
+}
+
+// GetTaskName returns the name of the task on the cluster
+func (task *ServerTask) GetTaskName() string {
+	if task.Spec.Name != "" {
+		return task.Spec.Name
+	}
+	return task.Name
+}
+
+// GetType returns the type of the task, Script by default
+func (task *ServerTask) GetType() ServerTaskType {
+	if task.Spec.Type == "" {
+		return ServerTaskScript
+	}
+	return task.Spec.Type
+}
+
+// SetCondition set condition to status
+func (policy *CachePolicy) SetCondition(condition string, status metav1.ConditionStatus, message string) bool {
+	for idx := range policy.Status.Conditions {
+		c := &policy.Status.Conditions[idx]
+		if c.Type == condition {
+			changed := c.Status != status || c.Message != message
+			c.Status = status
+			c.Message = message
+			return changed
+		}
+	}
+	policy.Status.Conditions = append(policy.Status.Conditions, CacheCondition{Type: condition, Status: status, Message: message})
+	return true
+}
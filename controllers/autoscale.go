@@ -170,6 +170,21 @@ func autoscaleOnPercentUsage(ctx context.Context, usage *map[string]int, minPodN
 	loTh := ispn.Spec.Autoscale.MinMemUsagePercent
 	maxReplicas := ispn.Spec.Autoscale.MaxReplicas
 	if maxReplicas != 0 {
+		// Bring the cluster back within the configured bounds before acting on memory usage.
+		// A cluster with no replicas is being shutdown or upgraded, so it must be left alone
+		if !isShuttingDown(ispn) && (ispn.Spec.Replicas < ispn.Spec.Autoscale.MinReplicas || ispn.Spec.Replicas > maxReplicas) {
+			if ispn.Spec.Replicas < ispn.Spec.Autoscale.MinReplicas {
+				ispn.Spec.Replicas = ispn.Spec.Autoscale.MinReplicas
+			} else {
+				ispn.Spec.Replicas = maxReplicas
+			}
+			if err := kubernetes.Client.Update(ctx, ispn); err != nil {
+				log.Error(err, "Unable to scale within autoscale bounds")
+				return
+			}
+			log.Info("Scaling cluster within autoscale bounds", "Name", ispn.Name, "New Replicas", ispn.Spec.Replicas)
+			return
+		}
 		upscale := false
 		downscale := true
 		// Logic here is:
@@ -192,6 +207,7 @@ func autoscaleOnPercentUsage(ctx context.Context, usage *map[string]int, minPodN
 			err := kubernetes.Client.Update(ctx, ispn)
 			if err != nil {
 				log.Error(err, "Unable to upscale")
+				return
 			}
 			log.Info("Upscaling cluster", "Name", ispn.Name, "New Replicas", ispn.Spec.Replicas)
 			return
@@ -201,9 +217,33 @@ func autoscaleOnPercentUsage(ctx context.Context, usage *map[string]int, minPodN
 			err := kubernetes.Client.Update(ctx, ispn)
 			if err != nil {
 				log.Error(err, "Unable to downscale")
+				return
 			}
 			log.Info("Downscaling cluster", "Name", ispn.Name, "New Replicas", ispn.Spec.Replicas)
 			return
 		}
 	}
 }
+
+// validateAutoscale verifies the bounds of the autoscaling of a Cache service cluster
+func validateAutoscale(i *infinispanv1.Infinispan) error {
+	autoscale := i.Spec.Autoscale
+	if autoscale == nil || i.Spec.Service.Type != infinispanv1.ServiceTypeCache {
+		return nil
+	}
+	if autoscale.MaxReplicas != 0 && autoscale.MinReplicas > autoscale.MaxReplicas {
+		return fmt.Errorf("infinispan.spec.autoscale.minReplicas (%d) must not be greater than infinispan.spec.autoscale.maxReplicas (%d)", autoscale.MinReplicas, autoscale.MaxReplicas)
+	}
+	if autoscale.MinMemUsagePercent >= autoscale.MaxMemUsagePercent {
+		return fmt.Errorf("infinispan.spec.autoscale.minMemUsagePercent (%d) must be lower than infinispan.spec.autoscale.maxMemUsagePercent (%d)", autoscale.MinMemUsagePercent, autoscale.MaxMemUsagePercent)
+	}
+	return nil
+}
+
+// isShuttingDown true if the cluster is being stopped, either by a graceful shutdown or an upgrade
+func isShuttingDown(ispn *infinispanv1.Infinispan) bool {
+	return ispn.Spec.Replicas == 0 ||
+		ispn.IsConditionTrue(infinispanv1.ConditionStopping) ||
+		ispn.IsConditionTrue(infinispanv1.ConditionGracefulShutdown) ||
+		ispn.IsConditionTrue(infinispanv1.ConditionUpgrade)
+}
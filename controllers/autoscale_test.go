@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func autoscaleInfinispan(replicas, minReplicas, maxReplicas int32) *infinispanv1.Infinispan {
+	return &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: infinispanv1.InfinispanSpec{
+			Replicas: replicas,
+			Service:  infinispanv1.InfinispanServiceSpec{Type: infinispanv1.ServiceTypeCache},
+			Autoscale: &infinispanv1.Autoscale{
+				MinReplicas:        minReplicas,
+				MaxReplicas:        maxReplicas,
+				MinMemUsagePercent: 20,
+				MaxMemUsagePercent: 80,
+			},
+		},
+	}
+}
+
+func TestValidateAutoscale(t *testing.T) {
+	for _, test := range []struct {
+		infinispan *infinispanv1.Infinispan
+		err        string
+	}{
+		{infinispan: autoscaleInfinispan(2, 1, 3)},
+		{infinispan: autoscaleInfinispan(2, 3, 3)},
+		// No upper bound
+		{infinispan: autoscaleInfinispan(2, 4, 0)},
+		{
+			infinispan: autoscaleInfinispan(2, 4, 3),
+			err:        "infinispan.spec.autoscale.minReplicas (4) must not be greater than infinispan.spec.autoscale.maxReplicas (3)",
+		},
+		{
+			infinispan: func() *infinispanv1.Infinispan {
+				i := autoscaleInfinispan(2, 1, 3)
+				i.Spec.Autoscale.MinMemUsagePercent = 80
+				return i
+			}(),
+			err: "infinispan.spec.autoscale.minMemUsagePercent (80) must be lower than infinispan.spec.autoscale.maxMemUsagePercent (80)",
+		},
+		// Autoscaling is ignored by DataGrid service clusters
+		{
+			infinispan: func() *infinispanv1.Infinispan {
+				i := autoscaleInfinispan(2, 4, 3)
+				i.Spec.Service.Type = infinispanv1.ServiceTypeDataGrid
+				return i
+			}(),
+		},
+	} {
+		if test.err == "" {
+			assert.NoError(t, validateAutoscale(test.infinispan))
+		} else {
+			assert.EqualError(t, validateAutoscale(test.infinispan), test.err)
+		}
+	}
+}
+
+func TestAutoscaleWithinBounds(t *testing.T) {
+	stopping := func(i *infinispanv1.Infinispan) {
+		i.SetCondition(infinispanv1.ConditionStopping, metav1.ConditionTrue, infinispanv1.ReasonShutdownRequested, "")
+	}
+	for _, test := range []struct {
+		name     string
+		ispn     *infinispanv1.Infinispan
+		modify   func(*infinispanv1.Infinispan)
+		usage    int
+		expected int32
+	}{
+		{name: "below minReplicas", ispn: autoscaleInfinispan(1, 2, 4), usage: 50, expected: 2},
+		{name: "above maxReplicas", ispn: autoscaleInfinispan(6, 2, 4), usage: 50, expected: 4},
+		// The bounds take precedence over the memory usage
+		{name: "above maxReplicas with a high usage", ispn: autoscaleInfinispan(6, 2, 4), usage: 90, expected: 4},
+		{name: "below minReplicas with a low usage", ispn: autoscaleInfinispan(1, 2, 4), usage: 10, expected: 2},
+		{name: "within bounds with a high usage", ispn: autoscaleInfinispan(3, 2, 4), usage: 90, expected: 4},
+		{name: "within bounds with a low usage", ispn: autoscaleInfinispan(3, 2, 4), usage: 10, expected: 2},
+		{name: "at maxReplicas", ispn: autoscaleInfinispan(4, 2, 4), usage: 90, expected: 4},
+		{name: "at minReplicas", ispn: autoscaleInfinispan(2, 2, 4), usage: 10, expected: 2},
+		// A stopped cluster isn't scaled up to minReplicas
+		{name: "shutdown", ispn: autoscaleInfinispan(0, 2, 4), usage: 50, expected: 0},
+		{name: "stopping", ispn: autoscaleInfinispan(1, 2, 4), modify: stopping, usage: 50, expected: 1},
+	} {
+		if test.modify != nil {
+			test.modify(test.ispn)
+		}
+		scheme := runtime.NewScheme()
+		assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+		assert.NoError(t, infinispanv1.AddToScheme(scheme))
+		c := fake.NewFakeClientWithScheme(scheme, test.ispn.DeepCopy())
+
+		usage := map[string]int{"dataMemPercentUsage;node=example-infinispan-0": test.usage}
+		autoscaleOnPercentUsage(context.TODO(), &usage, 1, test.ispn, &kube.Kubernetes{Client: c})
+
+		updated := &infinispanv1.Infinispan{}
+		assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: test.ispn.Name}, updated))
+		assert.Equal(t, test.expected, updated.Spec.Replicas, test.name)
+	}
+}
+
+func TestIsShuttingDown(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		replicas  int32
+		condition infinispanv1.ConditionType
+		status    metav1.ConditionStatus
+		expected  bool
+	}{
+		{name: "running", replicas: 2, expected: false},
+		{name: "no replicas", replicas: 0, expected: true},
+		{name: "stopping", replicas: 2, condition: infinispanv1.ConditionStopping, status: metav1.ConditionTrue, expected: true},
+		{name: "graceful shutdown", replicas: 2, condition: infinispanv1.ConditionGracefulShutdown, status: metav1.ConditionTrue, expected: true},
+		{name: "upgrade", replicas: 2, condition: infinispanv1.ConditionUpgrade, status: metav1.ConditionTrue, expected: true},
+		{name: "recovered", replicas: 2, condition: infinispanv1.ConditionGracefulShutdown, status: metav1.ConditionFalse, expected: false},
+	} {
+		i := autoscaleInfinispan(test.replicas, 1, 3)
+		if test.condition != "" {
+			i.SetCondition(test.condition, test.status, "", "")
+		}
+		assert.Equal(t, test.expected, isShuttingDown(i), test.name)
+	}
+}
@@ -6,9 +6,11 @@ import (
 
 	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
 	"github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
 	"github.com/infinispan/infinispan-operator/pkg/infinispan/backup"
 	"github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
 	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -19,9 +21,10 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
-// +kubebuilder:rbac:groups=infinispan.org,resources=backups;backups/status;backups/finalizers,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=infinispan.org,resources=backups;backups/status;backups/finalizers,verbs=get;list;watch;create;update;patch;delete
 
 const (
 	BackupDataMountPath = "/opt/infinispan/backups"
@@ -41,7 +44,11 @@ type backupResource struct {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *BackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return newZeroCapacityController("Backup", &BackupReconciler{mgr.GetClient()}, mgr)
+	// Scheduled Backups are only templates, handled by the BackupScheduleReconciler
+	notScheduled := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return !obj.(*v2alpha1.Backup).IsScheduled()
+	})
+	return newZeroCapacityController("Backup", &BackupReconciler{mgr.GetClient()}, mgr, notScheduled)
 }
 
 func (r *BackupReconciler) ResourceInstance(ctx context.Context, key types.NamespacedName, ctrl *zeroCapacityController) (zeroCapacityResource, error) {
@@ -83,6 +90,9 @@ func (r *backupResource) UpdatePhase(phase zeroCapacityPhase, phaseErr error) er
 		}
 		backup.Status.Phase = v2alpha1.BackupPhase(phase)
 		backup.Status.Reason = reason
+		if phase == ZeroSucceeded && backup.Spec.Storage != nil {
+			backup.Status.Location = backupStorageURL(backup.Spec.Storage, backup.Name)
+		}
 	})
 	return err
 }
@@ -121,6 +131,23 @@ func (r *backupResource) update(mutate func()) (bool, error) {
 }
 
 func (r *backupResource) Init() (*zeroCapacitySpec, error) {
+	if storage := r.instance.Spec.Storage; storage != nil {
+		if err := validateBackupStorage(storage); err != nil {
+			if updateErr := r.UpdatePhase(ZeroFailed, err); updateErr != nil {
+				return nil, updateErr
+			}
+			return nil, err
+		}
+	}
+	if encryption := r.instance.Spec.Encryption; encryption != nil {
+		if err := validateBackupEncryption(r.ctx, r.client, r.instance.Namespace, encryption); err != nil {
+			if updateErr := r.UpdatePhase(ZeroFailed, err); updateErr != nil {
+				return nil, updateErr
+			}
+			return nil, err
+		}
+	}
+
 	err := r.getOrCreatePvc()
 	if err != nil {
 		return nil, err
@@ -226,5 +253,99 @@ func (r *backupResource) ExecStatus(client http.HttpClient) (zeroCapacityPhase,
 	backupManager := backup.NewManager(name, client)
 
 	status, err := backupManager.BackupStatus(name)
-	return zeroCapacityPhase(status), err
+	if err == nil && status != backup.StatusFailed {
+		if err := r.updateProgress(client, backupManager, status); err != nil {
+			return ZeroUnknown, err
+		}
+	}
+	if err != nil || status != backup.StatusSucceeded {
+		return zeroCapacityPhase(status), err
+	}
+	// The archive is encrypted before it's uploaded
+	if r.instance.Spec.Encryption != nil {
+		if phase, err := r.encryptStatus(); phase != ZeroSucceeded {
+			return phase, err
+		}
+	}
+	if r.instance.Spec.Storage == nil {
+		return zeroCapacityPhase(status), nil
+	}
+	// The Backup only succeeds once the archive has been uploaded to object storage
+	return r.uploadStatus()
+}
+
+func (r *backupResource) Cancel(client http.HttpClient) error {
+	name := r.instance.Name
+	return backup.NewManager(name, client).DeleteBackup(name)
+}
+
+// updateProgress publishes the progress of the backup in the status. The server only reports the completion of the
+// whole backup, so all the caches are done and the size of the archive is known once the backup has succeeded
+func (r *backupResource) updateProgress(client http.HttpClient, backupManager backup.ManagerInterface, status backup.Status) error {
+	progress := r.instance.Status.Progress.DeepCopy()
+	if progress == nil {
+		caches, err := backupCaches(&ispn.Cluster{Client: client}, r.instance.Name, r.instance.Spec.Resources)
+		if err != nil {
+			return err
+		}
+		progress = &v2alpha1.OperationProgress{CachesTotal: int32(len(caches))}
+	}
+	if status == backup.StatusSucceeded {
+		size, err := backupManager.BackupSize(r.instance.Name)
+		if err != nil {
+			return err
+		}
+		progress.CachesDone = progress.CachesTotal
+		progress.BytesWritten = size
+	}
+	_, err := r.update(func() {
+		r.instance.Status.Progress = progress
+	})
+	return err
+}
+
+// backupCaches returns the names of the caches included in the Backup. All the caches of the cluster are included
+// when no resources are specified or when the caches contain the "*" wildcard
+func backupCaches(cluster ispn.ClusterInterface, podName string, resources *v2alpha1.BackupResources) ([]string, error) {
+	if resources == nil || containsWildcard(resources.Caches) {
+		return cluster.CacheNames(podName)
+	}
+	return resources.Caches, nil
+}
+
+func containsWildcard(names []string) bool {
+	for _, name := range names {
+		if name == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadStatus creates the Job uploading the backup archive to object storage if it doesn't exist, returning the
+// phase of the upload
+func (r *backupResource) uploadStatus() (zeroCapacityPhase, error) {
+	job := &batchv1.Job{}
+	jobKey := types.NamespacedName{
+		Namespace: r.instance.Namespace,
+		Name:      fmt.Sprintf(BackupUploadJobNameTemplate, r.instance.Name),
+	}
+	if err := r.client.Get(r.ctx, jobKey, job); err == nil {
+		return jobPhase(job)
+	} else if !errors.IsNotFound(err) {
+		return ZeroUnknown, err
+	}
+
+	pod := &corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.instance.Namespace, Name: r.instance.Name}, pod); err != nil {
+		return ZeroUnknown, fmt.Errorf("unable to load zero-capacity pod: %w", err)
+	}
+	job = computeBackupUploadJob(r.instance, pod.Spec.NodeName)
+	if err := controllerutil.SetControllerReference(r.instance, job, r.scheme); err != nil {
+		return ZeroUnknown, err
+	}
+	if err := r.client.Create(r.ctx, job); err != nil {
+		return ZeroUnknown, fmt.Errorf("unable to create backup upload job: %w", err)
+	}
+	return ZeroRunning, nil
 }
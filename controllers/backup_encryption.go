@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/backup"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	BackupEncryptionCommand       = "backup-encryption"
+	BackupEncryptionKey           = "key"
+	BackupEncryptionVolumeName    = "backup-encryption-key"
+	BackupEncryptionMountPath     = "/etc/backup-encryption"
+	BackupEncryptJobNameTemplate  = "%s-encrypt"
+	BackupEncryptedDataMountPath  = "/opt/infinispan/encrypted-backups"
+	BackupEncryptedDataVolumeName = "encrypted-backup"
+)
+
+// validateBackupEncryption verifies that the encryption Secret holds a valid AES key
+func validateBackupEncryption(ctx context.Context, c client.Client, namespace string, encryption *v2alpha1.BackupEncryptionSpec) error {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: encryption.SecretRef}, secret); err != nil {
+		return fmt.Errorf("unable to load the encryption Secret '%s': %w", encryption.SecretRef, err)
+	}
+	key, ok := secret.Data[BackupEncryptionKey]
+	if !ok {
+		return fmt.Errorf("the encryption Secret '%s' must contain a '%s' key", secret.Name, BackupEncryptionKey)
+	}
+	return backup.ValidateEncryptionKey(key)
+}
+
+// operatorImage returns the image of the operator pod, which runs the backup-encryption command
+func operatorImage(ctx context.Context, c client.Client) (string, error) {
+	operatorNs, err := kube.GetOperatorNamespace()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine the operator namespace: %w", err)
+	}
+	operatorPod, err := kube.GetPod(ctx, c, operatorNs)
+	if err != nil {
+		return "", fmt.Errorf("unable to determine the operator image: %w", err)
+	}
+	return operatorPod.Spec.Containers[0].Image, nil
+}
+
+// backupEncryptionContainer returns the container encrypting the archive `input` to `output`, or decrypting it when
+// `decrypt` is true, with the volume of the encryption key
+func backupEncryptionContainer(encryption *v2alpha1.BackupEncryptionSpec, image, input, output string, decrypt bool) (corev1.Container, corev1.Volume) {
+	containerName := "encrypt"
+	if decrypt {
+		containerName = "decrypt"
+	}
+	container := corev1.Container{
+		Name:  containerName,
+		Image: image,
+		Command: []string{
+			"infinispan-operator",
+			BackupEncryptionCommand,
+			"--key-file", path.Join(BackupEncryptionMountPath, BackupEncryptionKey),
+			"--input", input,
+			"--output", output,
+		},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      BackupEncryptionVolumeName,
+			MountPath: BackupEncryptionMountPath,
+			ReadOnly:  true,
+		}},
+	}
+	if decrypt {
+		container.Command = append(container.Command, "--decrypt")
+	}
+	volume := corev1.Volume{
+		Name: BackupEncryptionVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: encryption.SecretRef},
+		},
+	}
+	return container, volume
+}
+
+// computeBackupEncryptJob returns the Job replacing the archive of the Backup on its PVC with the encrypted archive.
+// The Job runs on the node of the zero-capacity pod, as the PVC can only be mounted by a single node
+func computeBackupEncryptJob(backup *v2alpha1.Backup, nodeName, image string) *batchv1.Job {
+	name := backup.Name
+	archive := fmt.Sprintf("%[1]s/%[2]s/%[2]s.zip", BackupDataMountPath, name)
+	container, keyVolume := backupEncryptionContainer(backup.Spec.Encryption, image, archive, archive, false)
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      name,
+		MountPath: BackupDataMountPath,
+	})
+
+	labels := BackupEncryptLabels(name, backup.Spec.Cluster)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(BackupEncryptJobNameTemplate, name),
+			Namespace: backup.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// The archive is only replaced once encrypted, so the Job can be retried safely
+			BackoffLimit: pointer.Int32Ptr(2),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					NodeName:      nodeName,
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{container},
+					Volumes: []corev1.Volume{keyVolume, {
+						Name: name,
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+								ClaimName: name,
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// encryptStatus creates the Job encrypting the backup archive if it doesn't exist, returning the phase of the
+// encryption
+func (r *backupResource) encryptStatus() (zeroCapacityPhase, error) {
+	job := &batchv1.Job{}
+	jobKey := types.NamespacedName{
+		Namespace: r.instance.Namespace,
+		Name:      fmt.Sprintf(BackupEncryptJobNameTemplate, r.instance.Name),
+	}
+	if err := r.client.Get(r.ctx, jobKey, job); err == nil {
+		return jobPhase(job)
+	} else if !errors.IsNotFound(err) {
+		return ZeroUnknown, err
+	}
+
+	pod := &corev1.Pod{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Namespace: r.instance.Namespace, Name: r.instance.Name}, pod); err != nil {
+		return ZeroUnknown, fmt.Errorf("unable to load zero-capacity pod: %w", err)
+	}
+	image, err := operatorImage(r.ctx, r.client)
+	if err != nil {
+		return ZeroUnknown, err
+	}
+	job = computeBackupEncryptJob(r.instance, pod.Spec.NodeName, image)
+	if err := controllerutil.SetControllerReference(r.instance, job, r.scheme); err != nil {
+		return ZeroUnknown, err
+	}
+	if err := r.client.Create(r.ctx, job); err != nil {
+		return ZeroUnknown, fmt.Errorf("unable to create backup encryption job: %w", err)
+	}
+	return ZeroRunning, nil
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateBackupEncryption(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-key", Namespace: "testing"},
+		Data:       map[string][]byte{BackupEncryptionKey: []byte("0123456789abcdef0123456789abcdef")},
+	}
+	invalid := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid-key", Namespace: "testing"},
+		Data:       map[string][]byte{BackupEncryptionKey: []byte("short")},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, secret, invalid)
+
+	assert.NoError(t, validateBackupEncryption(context.TODO(), c, "testing", &v2alpha1.BackupEncryptionSpec{SecretRef: "backup-key"}))
+	assert.Error(t, validateBackupEncryption(context.TODO(), c, "testing", &v2alpha1.BackupEncryptionSpec{SecretRef: "invalid-key"}))
+	assert.Error(t, validateBackupEncryption(context.TODO(), c, "testing", &v2alpha1.BackupEncryptionSpec{SecretRef: "missing"}))
+}
+
+func TestComputeBackupEncryptJob(t *testing.T) {
+	backup := &v2alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "testing"},
+		Spec: v2alpha1.BackupSpec{
+			Cluster:    "example-infinispan",
+			Encryption: &v2alpha1.BackupEncryptionSpec{SecretRef: "backup-key"},
+		},
+	}
+	job := computeBackupEncryptJob(backup, "node-1", "operator-image")
+	assert.Equal(t, "nightly-encrypt", job.Name)
+	spec := job.Spec.Template.Spec
+	assert.Equal(t, "node-1", spec.NodeName)
+	assert.Equal(t, "operator-image", spec.Containers[0].Image)
+	// The archive is replaced by the encrypted archive
+	assert.Equal(t, []string{
+		"infinispan-operator", BackupEncryptionCommand,
+		"--key-file", "/etc/backup-encryption/key",
+		"--input", "/opt/infinispan/backups/nightly/nightly.zip",
+		"--output", "/opt/infinispan/backups/nightly/nightly.zip",
+	}, spec.Containers[0].Command)
+	assert.Equal(t, "backup-key", spec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, "nightly", spec.Volumes[1].PersistentVolumeClaim.ClaimName)
+}
+
+func TestDecryptedRestoreSpec(t *testing.T) {
+	backup := &v2alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "testing"},
+		Spec:       v2alpha1.BackupSpec{Cluster: "example-infinispan"},
+	}
+	restore := &v2alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "testing"},
+		Spec: v2alpha1.RestoreSpec{
+			Cluster:    "example-infinispan",
+			Backup:     "nightly",
+			Encryption: &v2alpha1.BackupEncryptionSpec{SecretRef: "backup-key"},
+		},
+	}
+	zeroSpec := decryptedRestoreSpec(restore, backup, "operator-image")
+	assert.NotNil(t, zeroSpec.Volume.VolumeSource.EmptyDir)
+	decrypt := zeroSpec.InitContainers[0]
+	assert.Equal(t, []string{
+		"--input", "/opt/infinispan/encrypted-backups/nightly/nightly.zip",
+		"--output", "/opt/infinispan/backups/nightly/nightly.zip",
+		"--decrypt",
+	}, decrypt.Command[4:])
+	assert.Equal(t, BackupEncryptedDataMountPath, decrypt.VolumeMounts[1].MountPath)
+	assert.True(t, zeroSpec.Volumes[1].PersistentVolumeClaim.ReadOnly)
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/backup"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// progressCluster only lists the caches, the remaining methods are not expected to be called
+type progressCluster struct {
+	ispn.ClusterInterface
+	caches []string
+}
+
+func (c *progressCluster) CacheNames(podName string) ([]string, error) {
+	return c.caches, nil
+}
+
+func TestBackupCaches(t *testing.T) {
+	cluster := &progressCluster{caches: []string{"a", "b", "c"}}
+
+	caches, err := backupCaches(cluster, "nightly", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, caches)
+
+	caches, err = backupCaches(cluster, "nightly", &v2alpha1.BackupResources{Caches: []string{"*"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, caches)
+
+	caches, err = backupCaches(cluster, "nightly", &v2alpha1.BackupResources{Caches: []string{"a"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, caches)
+}
+
+func TestRestoreProgress(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v2alpha1.AddToScheme(scheme))
+
+	instance := &v2alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "testing", CreationTimestamp: metav1.Now()},
+		Spec: v2alpha1.RestoreSpec{
+			Cluster:   "example-infinispan",
+			Resources: &v2alpha1.RestoreResources{Caches: []string{"a", "b", "c"}},
+		},
+	}
+	c := fake.NewFakeClientWithScheme(scheme, instance)
+	r := &restore{instance: instance, client: c, scheme: scheme, ctx: context.TODO()}
+	key := types.NamespacedName{Namespace: "testing", Name: "nightly"}
+
+	// A cache is done once it exists on the cluster
+	assert.NoError(t, r.updateProgress(&progressCluster{caches: []string{"a", "other"}}, backup.StatusRunning))
+	restored := &v2alpha1.Restore{}
+	assert.NoError(t, c.Get(context.TODO(), key, restored))
+	assert.Equal(t, &v2alpha1.OperationProgress{CachesTotal: 3, CachesDone: 1}, restored.Status.Progress)
+
+	assert.NoError(t, r.updateProgress(&progressCluster{}, backup.StatusSucceeded))
+	assert.NoError(t, c.Get(context.TODO(), key, restored))
+	assert.Equal(t, &v2alpha1.OperationProgress{CachesTotal: 3, CachesDone: 3}, restored.Status.Progress)
+
+	// The caches of the archive are only known to the server
+	instance = &v2alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{Name: "all", Namespace: "testing", CreationTimestamp: metav1.Now()},
+		Spec:       v2alpha1.RestoreSpec{Cluster: "example-infinispan"},
+	}
+	assert.NoError(t, c.Create(context.TODO(), instance))
+	r.instance = instance
+	assert.NoError(t, r.updateProgress(&progressCluster{caches: []string{"a"}}, backup.StatusRunning))
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "testing", Name: "all"}, restored))
+	assert.Equal(t, &v2alpha1.OperationProgress{}, restored.Status.Progress)
+}
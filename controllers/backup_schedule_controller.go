@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	EventReasonInvalidSchedule = "InvalidSchedule"
+	EventReasonScheduleSkipped = "ScheduleSkipped"
+	LabelBackupSchedule        = "infinispan_backup_schedule"
+)
+
+// BackupScheduleReconciler creates Backups on the schedule defined by a scheduled Backup and prunes the old ones.
+// The created Backups are not owned by the scheduled Backup, so that they and their PVCs outlive it
+type BackupScheduleReconciler struct {
+	client.Client
+	log      logr.Logger
+	eventRec record.EventRecorder
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Client = mgr.GetClient()
+	r.log = ctrl.Log.WithName("controllers").WithName("BackupSchedule")
+	r.eventRec = mgr.GetEventRecorderFor("backup-schedule-controller")
+	scheduled := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.(*v2alpha1.Backup).IsScheduled()
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("backup-schedule").
+		For(&v2alpha1.Backup{}, builder.WithPredicates(scheduled)).
+		Watches(
+			&source.Kind{Type: &v2alpha1.Backup{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					// Requeue the scheduled Backup when one of its Backups changes, so that expired Backups are pruned
+					if schedule, ok := a.GetLabels()[LabelBackupSchedule]; ok {
+						return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: a.GetNamespace(), Name: schedule}}}
+					}
+					return nil
+				}),
+		).
+		Complete(r)
+}
+
+func (r *BackupScheduleReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("+++++ Reconciling Backup schedule.")
+	defer reqLogger.Info("----- End Reconciling Backup schedule.")
+
+	instance := &v2alpha1.Backup{}
+	if err := r.Get(ctx, request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !instance.IsScheduled() {
+		return ctrl.Result{}, nil
+	}
+
+	if len(instance.Name) > v2alpha1.MaxScheduledBackupNameLength {
+		return ctrl.Result{}, r.invalidSchedule(ctx, instance, fmt.Sprintf("scheduled Backup name '%s' exceeds %d characters", instance.Name, v2alpha1.MaxScheduledBackupNameLength))
+	}
+
+	schedule, err := cron.ParseStandard(instance.Spec.Schedule)
+	if err != nil {
+		return ctrl.Result{}, r.invalidSchedule(ctx, instance, fmt.Sprintf("unable to parse schedule '%s': %v", instance.Spec.Schedule, err))
+	}
+
+	backups := &v2alpha1.BackupList{}
+	if err := r.List(ctx, backups, client.InNamespace(instance.Namespace), client.MatchingLabels(BackupScheduleLabels(instance.Name))); err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, backup := range expiredBackups(backups.Items, int(instance.Spec.Retention)) {
+		reqLogger.Info("Deleting expired scheduled Backup", "Backup.Name", backup.Name)
+		if err := r.Delete(ctx, &backup); err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+
+	now := time.Now()
+	if next := nextScheduleTime(schedule, instance); next.After(now) {
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+
+	if instance.Spec.ConcurrencyPolicy != v2alpha1.BackupConcurrencyAllow {
+		if active := activeBackups(backups.Items); len(active) > 0 {
+			msg := fmt.Sprintf("Skipping the scheduled Backup, Backup '%s' of the schedule is still in progress", active[0])
+			reqLogger.Info(msg)
+			r.eventRec.Event(instance, corev1.EventTypeNormal, EventReasonScheduleSkipped, msg)
+			if err := r.update(ctx, instance, func() {
+				instance.Status.LastScheduleTime = &metav1.Time{Time: now}
+			}); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: schedule.Next(now).Sub(now)}, nil
+		}
+	}
+
+	backup := &v2alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", instance.Name, now.Unix()),
+			Namespace: instance.Namespace,
+			Labels:    BackupScheduleLabels(instance.Name),
+		},
+		Spec: *instance.Spec.DeepCopy(),
+	}
+	backup.Spec.Schedule = ""
+	backup.Spec.Retention = 0
+	backup.Spec.ConcurrencyPolicy = ""
+	reqLogger.Info("Creating scheduled Backup", "Backup.Name", backup.Name)
+	if err := r.Create(ctx, backup); err != nil && !errors.IsAlreadyExists(err) {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.update(ctx, instance, func() {
+		instance.Status.LastScheduleTime = &metav1.Time{Time: now}
+		instance.Status.Reason = ""
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: schedule.Next(now).Sub(now)}, nil
+}
+
+func (r *BackupScheduleReconciler) invalidSchedule(ctx context.Context, instance *v2alpha1.Backup, msg string) error {
+	r.eventRec.Event(instance, corev1.EventTypeWarning, EventReasonInvalidSchedule, msg)
+	r.log.Info(msg, "Request.Namespace", instance.Namespace, "Request.Name", instance.Name)
+	return r.update(ctx, instance, func() {
+		instance.Status.Reason = msg
+	})
+}
+
+// nextScheduleTime returns the time at which the next Backup of the schedule must be created
+func nextScheduleTime(schedule cron.Schedule, instance *v2alpha1.Backup) time.Time {
+	lastScheduleTime := instance.CreationTimestamp.Time
+	if instance.Status.LastScheduleTime != nil {
+		lastScheduleTime = instance.Status.LastScheduleTime.Time
+	}
+	return schedule.Next(lastScheduleTime)
+}
+
+// activeBackups returns the names of the scheduled Backups that are neither Succeeded nor Failed, oldest first
+func activeBackups(backups []v2alpha1.Backup) []string {
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreationTimestamp.Before(&backups[j].CreationTimestamp)
+	})
+	var active []string
+	for _, backup := range backups {
+		if backup.DeletionTimestamp == nil && backup.Status.Phase != v2alpha1.BackupSucceeded && backup.Status.Phase != v2alpha1.BackupFailed {
+			active = append(active, backup.Name)
+		}
+	}
+	return active
+}
+
+// expiredBackups returns the scheduled Backups to delete. Only succeeded Backups count towards the retention, so that
+// repeated failures never push out the last good Backup. All failed Backups but the most recent one are expired
+func expiredBackups(backups []v2alpha1.Backup, retention int) []v2alpha1.Backup {
+	if retention <= 0 {
+		return nil
+	}
+
+	var succeeded, failed []v2alpha1.Backup
+	for _, backup := range backups {
+		switch backup.Status.Phase {
+		case v2alpha1.BackupSucceeded:
+			succeeded = append(succeeded, backup)
+		case v2alpha1.BackupFailed:
+			failed = append(failed, backup)
+		}
+	}
+
+	var expired []v2alpha1.Backup
+	for _, completed := range []struct {
+		backups []v2alpha1.Backup
+		keep    int
+	}{{succeeded, retention}, {failed, 1}} {
+		if len(completed.backups) <= completed.keep {
+			continue
+		}
+		// Newest first
+		sort.Slice(completed.backups, func(i, j int) bool {
+			return completed.backups[j].CreationTimestamp.Before(&completed.backups[i].CreationTimestamp)
+		})
+		expired = append(expired, completed.backups[completed.keep:]...)
+	}
+	return expired
+}
+
+func (r *BackupScheduleReconciler) update(ctx context.Context, backup *v2alpha1.Backup, mutate func()) error {
+	_, err := kube.CreateOrPatch(ctx, r.Client, backup, func() error {
+		if backup.CreationTimestamp.IsZero() {
+			return errors.NewNotFound(schema.ParseGroupResource("backup.infinispan.org"), backup.Name)
+		}
+		mutate()
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var scheduleEpoch = time.Date(2021, time.June, 1, 10, 30, 0, 0, time.UTC)
+
+func scheduledBackup(name string, age time.Duration, phase v2alpha1.BackupPhase) v2alpha1.Backup {
+	return v2alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(scheduleEpoch.Add(-age)),
+		},
+		Status: v2alpha1.BackupStatus{
+			Phase: phase,
+		},
+	}
+}
+
+func backupNames(backups []v2alpha1.Backup) []string {
+	names := make([]string, len(backups))
+	for i, backup := range backups {
+		names[i] = backup.Name
+	}
+	return names
+}
+
+func TestNextScheduleTime(t *testing.T) {
+	schedule, err := cron.ParseStandard("0 * * * *")
+	assert.NoError(t, err)
+
+	instance := &v2alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(scheduleEpoch),
+		},
+	}
+	// First run is computed from the creation of the scheduled Backup
+	assert.Equal(t, time.Date(2021, time.June, 1, 11, 0, 0, 0, time.UTC), nextScheduleTime(schedule, instance))
+
+	// Subsequent runs are computed from the last scheduled time
+	instance.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2021, time.June, 1, 14, 0, 0, 0, time.UTC)}
+	assert.Equal(t, time.Date(2021, time.June, 1, 15, 0, 0, 0, time.UTC), nextScheduleTime(schedule, instance))
+}
+
+func TestExpiredBackupsRetention(t *testing.T) {
+	backups := []v2alpha1.Backup{
+		scheduledBackup("b-3", 3*time.Hour, v2alpha1.BackupSucceeded),
+		scheduledBackup("b-1", 1*time.Hour, v2alpha1.BackupSucceeded),
+		scheduledBackup("b-4", 4*time.Hour, v2alpha1.BackupSucceeded),
+		scheduledBackup("b-2", 2*time.Hour, v2alpha1.BackupSucceeded),
+		scheduledBackup("b-0", 0, v2alpha1.BackupRunning),
+	}
+	assert.Equal(t, []string{"b-3", "b-4"}, backupNames(expiredBackups(backups, 2)))
+	assert.Empty(t, expiredBackups(backups, 4))
+	assert.Empty(t, expiredBackups(backups, 0))
+}
+
+func TestExpiredBackupsFailuresKeepLastSucceeded(t *testing.T) {
+	backups := []v2alpha1.Backup{
+		scheduledBackup("b-1", 1*time.Hour, v2alpha1.BackupFailed),
+		scheduledBackup("b-2", 2*time.Hour, v2alpha1.BackupFailed),
+		scheduledBackup("b-3", 3*time.Hour, v2alpha1.BackupFailed),
+		scheduledBackup("b-4", 4*time.Hour, v2alpha1.BackupSucceeded),
+	}
+	// Failed Backups never push out the last succeeded one and only the most recent failure is kept
+	assert.Equal(t, []string{"b-2", "b-3"}, backupNames(expiredBackups(backups, 1)))
+}
+
+func TestActiveBackups(t *testing.T) {
+	backups := []v2alpha1.Backup{
+		scheduledBackup("b-1", 1*time.Hour, v2alpha1.BackupRunning),
+		scheduledBackup("b-2", 2*time.Hour, v2alpha1.BackupSucceeded),
+		scheduledBackup("b-3", 3*time.Hour, v2alpha1.BackupFailed),
+		scheduledBackup("b-4", 4*time.Hour, ""),
+	}
+	assert.Equal(t, []string{"b-4", "b-1"}, activeBackups(backups))
+	assert.Empty(t, activeBackups(backups[1:3]))
+}
+
+func TestReconcileScheduleConcurrencyPolicy(t *testing.T) {
+	for _, test := range []struct {
+		policy  v2alpha1.BackupConcurrencyPolicyType
+		backups int
+		event   string
+	}{
+		{policy: "", backups: 1, event: "Normal ScheduleSkipped Skipping the scheduled Backup, Backup 'nightly-1' of the schedule is still in progress"},
+		{policy: v2alpha1.BackupConcurrencyForbid, backups: 1, event: "Normal ScheduleSkipped Skipping the scheduled Backup, Backup 'nightly-1' of the schedule is still in progress"},
+		{policy: v2alpha1.BackupConcurrencyAllow, backups: 2},
+	} {
+		scheme := runtime.NewScheme()
+		assert.NoError(t, v2alpha1.AddToScheme(scheme))
+		instance := &v2alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: namespace, CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Spec:       v2alpha1.BackupSpec{Cluster: "example-infinispan", Schedule: "0 * * * *", ConcurrencyPolicy: test.policy},
+		}
+		running := &v2alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly-1", Namespace: namespace, Labels: BackupScheduleLabels(instance.Name)},
+			Status:     v2alpha1.BackupStatus{Phase: v2alpha1.BackupRunning},
+		}
+		recorder := record.NewFakeRecorder(10)
+		r := &BackupScheduleReconciler{Client: fake.NewFakeClientWithScheme(scheme, instance, running), log: logf.Log, eventRec: recorder}
+
+		result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: instance.Name}})
+		assert.NoError(t, err)
+		assert.True(t, result.RequeueAfter > 0)
+		backups := &v2alpha1.BackupList{}
+		assert.NoError(t, r.List(context.TODO(), backups, client.MatchingLabels(BackupScheduleLabels(instance.Name))))
+		assert.Len(t, backups.Items, test.backups, test.policy)
+		assert.Equal(t, test.event, nextEvent(recorder.Events), test.policy)
+
+		// The skipped time isn't attempted again
+		updated := &v2alpha1.Backup{}
+		assert.NoError(t, r.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: instance.Name}, updated))
+		assert.NotNil(t, updated.Status.LastScheduleTime)
+	}
+}
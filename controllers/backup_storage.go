@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"fmt"
+	"path"
+
+	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	BackupStorageCredentialsVolumeName = "backup-storage-credentials"
+	BackupStorageCredentialsMountPath  = "/etc/backup-storage"
+	BackupStorageGCSCredentialsKey     = "credentials.json"
+	BackupUploadJobNameTemplate        = "%s-upload"
+)
+
+// validateBackupStorage verifies that exactly one object storage is configured
+func validateBackupStorage(storage *v2alpha1.BackupStorageSpec) error {
+	configured := 0
+	for _, set := range []bool{storage.S3 != nil, storage.GCS != nil, storage.Azure != nil} {
+		if set {
+			configured++
+		}
+	}
+	if configured != 1 {
+		return fmt.Errorf("exactly one of storage.s3, storage.gcs or storage.azure must be configured")
+	}
+	return nil
+}
+
+// backupStorageURL returns the location of the archive of Backup `name` in the object storage
+func backupStorageURL(storage *v2alpha1.BackupStorageSpec, name string) string {
+	return storageObjectURL(storage, backupArchiveName(name))
+}
+
+// storageObjectURL returns the location of `object`, relative to the prefix, in the object storage
+func storageObjectURL(storage *v2alpha1.BackupStorageSpec, object string) string {
+	switch {
+	case storage.S3 != nil:
+		return fmt.Sprintf("s3://%s/%s", storage.S3.Bucket, path.Join(storage.S3.Prefix, object))
+	case storage.GCS != nil:
+		return fmt.Sprintf("gs://%s/%s", storage.GCS.Bucket, path.Join(storage.GCS.Prefix, object))
+	default:
+		return fmt.Sprintf("azure://%s/%s", storage.Azure.Container, path.Join(storage.Azure.Prefix, object))
+	}
+}
+
+func backupArchiveName(name string) string {
+	return name + ".zip"
+}
+
+// backupStorageContainer returns the container copying the archive of Backup `name` between `file` and the object
+// storage, with the volumes it requires. The archive is uploaded when `upload` is true, otherwise it's downloaded
+func backupStorageContainer(storage *v2alpha1.BackupStorageSpec, name, file string, upload bool) (corev1.Container, []corev1.Volume) {
+	return storageContainer(storage, backupArchiveName(name), file, upload)
+}
+
+// storageContainer returns the container copying `object`, relative to the prefix, between `file` and the object
+// storage, with the volumes it requires
+func storageContainer(storage *v2alpha1.BackupStorageSpec, object, file string, upload bool) (corev1.Container, []corev1.Volume) {
+	containerName := "download"
+	if upload {
+		containerName = "upload"
+	}
+	container := corev1.Container{Name: containerName}
+	var volumes []corev1.Volume
+
+	copyArgs := func(remote string) []string {
+		if upload {
+			return []string{file, remote}
+		}
+		return []string{remote, file}
+	}
+
+	switch {
+	case storage.S3 != nil:
+		s3 := storage.S3
+		container.Image = consts.BackupS3ImageName
+		container.Command = append([]string{"aws", "s3", "cp"}, copyArgs(storageObjectURL(storage, object))...)
+		if s3.Region != "" {
+			container.Command = append(container.Command, "--region", s3.Region)
+		}
+		if s3.Endpoint != "" {
+			container.Command = append(container.Command, "--endpoint-url", s3.Endpoint)
+		}
+		container.EnvFrom = secretEnvSource(s3.CredentialsSecret)
+	case storage.GCS != nil:
+		keyFile := path.Join(BackupStorageCredentialsMountPath, BackupStorageGCSCredentialsKey)
+		container.Image = consts.BackupGCSImageName
+		// The paths are passed as arguments of the script so that they are never interpreted by the shell
+		container.Command = append([]string{"/bin/sh", "-c", `gcloud auth activate-service-account --key-file="$0" && gsutil cp "$1" "$2"`, keyFile},
+			copyArgs(storageObjectURL(storage, object))...)
+		// The home directory isn't writable when the pod runs with an arbitrary user
+		container.Env = []corev1.EnvVar{{Name: "CLOUDSDK_CONFIG", Value: "/tmp/gcloud"}}
+		container.VolumeMounts = []corev1.VolumeMount{{
+			Name:      BackupStorageCredentialsVolumeName,
+			MountPath: BackupStorageCredentialsMountPath,
+			ReadOnly:  true,
+		}}
+		volumes = append(volumes, corev1.Volume{
+			Name: BackupStorageCredentialsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: storage.GCS.CredentialsSecret},
+			},
+		})
+	default:
+		azure := storage.Azure
+		container.Image = consts.BackupAzureImageName
+		blob := path.Join(azure.Prefix, object)
+		if upload {
+			container.Command = []string{"az", "storage", "blob", "upload", "--overwrite", "--container-name", azure.Container, "--name", blob, "--file", file}
+		} else {
+			container.Command = []string{"az", "storage", "blob", "download", "--container-name", azure.Container, "--name", blob, "--file", file}
+		}
+		container.Env = []corev1.EnvVar{{Name: "AZURE_CONFIG_DIR", Value: "/tmp/azure"}}
+		container.EnvFrom = secretEnvSource(azure.CredentialsSecret)
+	}
+	return container, volumes
+}
+
+func secretEnvSource(secret string) []corev1.EnvFromSource {
+	return []corev1.EnvFromSource{{
+		SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: secret}},
+	}}
+}
+
+// computeBackupUploadJob returns the Job uploading the archive of the Backup from its PVC to object storage. The Job
+// runs on the node of the zero-capacity pod, as the PVC can only be mounted by a single node
+func computeBackupUploadJob(backup *v2alpha1.Backup, nodeName string) *batchv1.Job {
+	name := backup.Name
+	container, volumes := backupStorageContainer(backup.Spec.Storage, name, fmt.Sprintf("%[1]s/%[2]s/%[2]s.zip", BackupDataMountPath, name), true)
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      name,
+		MountPath: BackupDataMountPath,
+		ReadOnly:  true,
+	})
+	volumes = append(volumes, corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: name,
+				ReadOnly:  true,
+			},
+		},
+	})
+
+	labels := BackupUploadLabels(name, backup.Spec.Cluster)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(BackupUploadJobNameTemplate, name),
+			Namespace: backup.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(2),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					NodeName:      nodeName,
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{container},
+					Volumes:       volumes,
+				},
+			},
+		},
+	}
+}
+
+// jobPhase returns the zero-capacity phase matching the state of the Job
+func jobPhase(job *batchv1.Job) (zeroCapacityPhase, error) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return ZeroSucceeded, nil
+		case batchv1.JobFailed:
+			return ZeroFailed, fmt.Errorf("job '%s' failed: %s", job.Name, condition.Message)
+		}
+	}
+	return ZeroRunning, nil
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateBackupStorage(t *testing.T) {
+	s3 := &v2alpha1.S3StorageSpec{Bucket: "backups", CredentialsSecret: "aws"}
+	gcs := &v2alpha1.GCSStorageSpec{Bucket: "backups", CredentialsSecret: "gcp"}
+	assert.NoError(t, validateBackupStorage(&v2alpha1.BackupStorageSpec{S3: s3}))
+	assert.Error(t, validateBackupStorage(&v2alpha1.BackupStorageSpec{}))
+	assert.Error(t, validateBackupStorage(&v2alpha1.BackupStorageSpec{S3: s3, GCS: gcs}))
+}
+
+func TestBackupStorageContainer(t *testing.T) {
+	s3 := &v2alpha1.BackupStorageSpec{S3: &v2alpha1.S3StorageSpec{Bucket: "backups", Prefix: "prod", Region: "eu-west-1", CredentialsSecret: "aws"}}
+	container, volumes := backupStorageContainer(s3, "nightly", "/opt/infinispan/backups/nightly.zip", false)
+	assert.Equal(t, []string{"aws", "s3", "cp", "s3://backups/prod/nightly.zip", "/opt/infinispan/backups/nightly.zip", "--region", "eu-west-1"}, container.Command)
+	assert.Equal(t, "aws", container.EnvFrom[0].SecretRef.Name)
+	assert.Empty(t, volumes)
+
+	gcs := &v2alpha1.BackupStorageSpec{GCS: &v2alpha1.GCSStorageSpec{Bucket: "backups", CredentialsSecret: "gcp"}}
+	container, volumes = backupStorageContainer(gcs, "nightly", "/opt/infinispan/backups/nightly.zip", true)
+	assert.Equal(t, []string{"/etc/backup-storage/credentials.json", "/opt/infinispan/backups/nightly.zip", "gs://backups/nightly.zip"}, container.Command[3:])
+	assert.Equal(t, "gcp", volumes[0].Secret.SecretName)
+
+	azure := &v2alpha1.BackupStorageSpec{Azure: &v2alpha1.AzureStorageSpec{Container: "backups", Prefix: "prod/", CredentialsSecret: "azure"}}
+	container, _ = backupStorageContainer(azure, "nightly", "/opt/infinispan/backups/nightly.zip", true)
+	assert.Contains(t, container.Command, "upload")
+	assert.Contains(t, container.Command, "prod/nightly.zip")
+	assert.Equal(t, "azure://backups/prod/nightly.zip", backupStorageURL(azure, "nightly"))
+}
+
+func TestBackupUploadStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v2alpha1.AddToScheme(scheme))
+
+	instance := &v2alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "testing"},
+		Spec: v2alpha1.BackupSpec{
+			Cluster: "example-infinispan",
+			Storage: &v2alpha1.BackupStorageSpec{S3: &v2alpha1.S3StorageSpec{Bucket: "backups", CredentialsSecret: "aws"}},
+		},
+	}
+	zeroPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "testing"}, Spec: corev1.PodSpec{NodeName: "node-1"}}
+	c := fake.NewFakeClientWithScheme(scheme, instance, zeroPod)
+	r := &backupResource{instance: instance, client: c, scheme: scheme, ctx: context.TODO()}
+
+	// The upload Job is created on the node of the zero-capacity pod
+	phase, err := r.uploadStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, ZeroRunning, phase)
+	job := &batchv1.Job{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "testing", Name: "nightly-upload"}, job))
+	assert.Equal(t, "node-1", job.Spec.Template.Spec.NodeName)
+	assert.Equal(t, "/opt/infinispan/backups/nightly/nightly.zip", job.Spec.Template.Spec.Containers[0].Command[3])
+	assert.Equal(t, "nightly", job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}}
+	assert.NoError(t, c.Update(context.TODO(), job))
+	phase, err = r.uploadStatus()
+	assert.Equal(t, ZeroFailed, phase)
+	assert.EqualError(t, err, "job 'nightly-upload' failed: BackoffLimitExceeded")
+
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	assert.NoError(t, c.Update(context.TODO(), job))
+	phase, err = r.uploadStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, ZeroSucceeded, phase)
+}
+
+func TestRestoreArchive(t *testing.T) {
+	restore := &v2alpha1.Restore{Spec: v2alpha1.RestoreSpec{Backup: "nightly"}}
+	assert.Equal(t, "/opt/infinispan/backups/nightly/nightly.zip", restoreArchive(restore))
+
+	restore.Spec.Storage = &v2alpha1.BackupStorageSpec{S3: &v2alpha1.S3StorageSpec{Bucket: "backups", CredentialsSecret: "aws"}}
+	assert.Equal(t, "/opt/infinispan/backups/nightly.zip", restoreArchive(restore))
+}
@@ -3,6 +3,9 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	v1 "github.com/infinispan/infinispan-operator/api/v1"
@@ -29,6 +32,10 @@ const (
 	BatchFilename   = "batch"
 	BatchVolumeName = "batch-volume"
 	BatchVolumeRoot = "/etc/batch"
+	// BatchSecretsRoot is the directory the Secrets of the batch are mounted in by default
+	BatchSecretsRoot = "/etc/batch-secrets"
+	// BatchLogRefresh delay between refreshes of the batch log while the job is running
+	BatchLogRefresh = 5 * time.Second
 )
 
 // BatchReconciler reconciles a Batch object
@@ -119,9 +126,82 @@ func (r *batchRequest) validate() (reconcile.Result, error) {
 		return reconcile.Result{},
 			r.UpdatePhase(v2.BatchFailed, fmt.Errorf("at most one of ['Spec.config', 'spec.ConfigMap'] must be configured"))
 	}
+
+	if err := validateBatchFiles(&spec); err != nil {
+		return reconcile.Result{}, r.UpdatePhase(v2.BatchFailed, err)
+	}
+	if spec.Config != nil {
+		if err := validateBatchCommands(*spec.Config); err != nil {
+			return reconcile.Result{}, r.UpdatePhase(v2.BatchFailed, err)
+		}
+	}
 	return reconcile.Result{}, r.UpdatePhase(v2.BatchInitializing, nil)
 }
 
+// batchSessionCommands are the CLI commands that only change the state of the CLI session
+var batchSessionCommands = map[string]bool{
+	"alias":      true,
+	"cache":      true,
+	"cd":         true,
+	"connect":    true,
+	"container":  true,
+	"counter":    true,
+	"disconnect": true,
+	"encoding":   true,
+}
+
+// validateBatchCommands verifies that every command of the batch is self-contained, as BatchScript runs each command
+// in its own CLI session
+func validateBatchCommands(batch string) error {
+	for _, line := range strings.Split(batch, "\n") {
+		cmd := strings.TrimSpace(line)
+		if cmd == "" || strings.HasPrefix(cmd, "#") {
+			continue
+		}
+		if batchSessionCommands[strings.Fields(cmd)[0]] {
+			return fmt.Errorf("the batch command '%s' changes the CLI session, which isn't kept between the commands of a batch, use the --cache option or absolute resource paths instead", cmd)
+		}
+	}
+	return nil
+}
+
+// validateBatchFiles verifies that the ConfigMap files and the Secrets of the batch are mounted at distinct absolute
+// paths
+func validateBatchFiles(spec *v2.BatchSpec) error {
+	if len(spec.ConfigMapFiles) > 0 && spec.ConfigMap == nil {
+		return fmt.Errorf("'spec.configMapFiles' requires 'spec.configMap'")
+	}
+	paths := map[string]bool{}
+	for _, file := range spec.ConfigMapFiles {
+		if !path.IsAbs(file.Path) {
+			return fmt.Errorf("the path '%s' of the ConfigMap key '%s' must be absolute", file.Path, file.Key)
+		}
+		if paths[path.Clean(file.Path)] {
+			return fmt.Errorf("the path '%s' is mounted more than once", file.Path)
+		}
+		paths[path.Clean(file.Path)] = true
+	}
+	for _, secret := range spec.Secrets {
+		mountPath := batchSecretMountPath(secret)
+		if !path.IsAbs(mountPath) {
+			return fmt.Errorf("the mount path '%s' of the Secret '%s' must be absolute", mountPath, secret.Name)
+		}
+		if paths[path.Clean(mountPath)] {
+			return fmt.Errorf("the path '%s' is mounted more than once", mountPath)
+		}
+		paths[path.Clean(mountPath)] = true
+	}
+	return nil
+}
+
+// batchSecretMountPath returns the directory the keys of a Secret of the batch are mounted in
+func batchSecretMountPath(secret v2.BatchSecretSpec) string {
+	if secret.MountPath != "" {
+		return secret.MountPath
+	}
+	return fmt.Sprintf("%s/%s", BatchSecretsRoot, secret.Name)
+}
+
 func (r *batchRequest) initializeResources() (reconcile.Result, error) {
 	batch := r.batch
 	spec := batch.Spec
@@ -165,6 +245,32 @@ func (r *batchRequest) initializeResources() (reconcile.Result, error) {
 		}
 	}
 
+	configMap := &corev1.ConfigMap{}
+	if result, err := kube.LookupResource(*spec.ConfigMap, batch.Namespace, configMap, batch, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return *result, err
+	}
+	if commands, ok := configMap.Data[BatchFilename]; !ok {
+		return reconcile.Result{}, r.UpdatePhase(v2.BatchFailed, fmt.Errorf("the ConfigMap '%s' has no key '%s'", configMap.Name, BatchFilename))
+	} else if err := validateBatchCommands(commands); err != nil {
+		return reconcile.Result{}, r.UpdatePhase(v2.BatchFailed, err)
+	}
+	if len(spec.ConfigMapFiles) > 0 {
+		for _, file := range spec.ConfigMapFiles {
+			_, data := configMap.Data[file.Key]
+			_, binaryData := configMap.BinaryData[file.Key]
+			if !data && !binaryData {
+				return reconcile.Result{}, r.UpdatePhase(v2.BatchFailed, fmt.Errorf("the ConfigMap '%s' has no key '%s'", configMap.Name, file.Key))
+			}
+		}
+	}
+
+	// Wait for the Secrets to exist, the batch pod cannot start without them
+	for _, secret := range spec.Secrets {
+		if result, err := kube.LookupResource(secret.Name, batch.Namespace, &corev1.Secret{}, batch, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+			return *result, err
+		}
+	}
+
 	// We update the phase separately to the spec as the status update is ignored when in the update mutate function
 	_, err := r.update(func() error {
 		batch.Status.ClusterUID = &infinispan.UID
@@ -187,8 +293,20 @@ func (r *batchRequest) execute() (reconcile.Result, error) {
 		return reconcile.Result{}, r.UpdatePhase(v2.BatchFailed, err)
 	}
 
-	cliArgs := fmt.Sprintf("--properties '%s/%s' --file '%s/%s'", consts.ServerAdminIdentitiesRoot, consts.CliPropertiesFilename, BatchVolumeRoot, BatchFilename)
+	job := computeBatchJob(batch, infinispan)
+	_, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, job, func() error {
+		return controllerutil.SetControllerReference(batch, job, r.scheme)
+	})
 
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("unable to create batch job '%s': %w", batch.Name, err)
+	}
+	return reconcile.Result{}, r.UpdatePhase(v2.BatchRunning, nil)
+}
+
+// computeBatchJob computes the Job running the batch with the CLI of the Infinispan image. The batch ConfigMap is
+// mounted in /etc/batch, with its files and the Secrets of the batch mounted at the configured paths
+func computeBatchJob(batch *v2.Batch, infinispan *v1.Infinispan) *batchv1.Job {
 	labels := BatchLabels(batch.Name)
 	infinispan.AddLabelsForPods(labels)
 
@@ -204,10 +322,15 @@ func (r *batchRequest) execute() (reconcile.Result, error) {
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
+					Affinity: architectureAffinity(infinispan, nil),
 					Containers: []corev1.Container{{
 						Name:    batch.Name,
 						Image:   infinispan.ImageName(),
-						Command: []string{"/opt/infinispan/bin/cli.sh", cliArgs},
+						Command: []string{"/bin/bash", "-c", BatchScript},
+						Env: []corev1.EnvVar{
+							{Name: "BATCH_FILE", Value: fmt.Sprintf("%s/%s", BatchVolumeRoot, BatchFilename)},
+							{Name: "CLI_PROPERTIES", Value: fmt.Sprintf("%s/%s", consts.ServerAdminIdentitiesRoot, consts.CliPropertiesFilename)},
+						},
 						VolumeMounts: []corev1.VolumeMount{
 							{
 								Name:      BatchVolumeName,
@@ -245,14 +368,31 @@ func (r *batchRequest) execute() (reconcile.Result, error) {
 		},
 	}
 
-	_, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, job, func() error {
-		return controllerutil.SetControllerReference(batch, job, r.scheme)
-	})
-
-	if err != nil {
-		return reconcile.Result{}, fmt.Errorf("unable to create batch job '%s': %w", batch.Name, err)
+	spec := &job.Spec.Template.Spec
+	container := &spec.Containers[0]
+	for _, file := range batch.Spec.ConfigMapFiles {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      BatchVolumeName,
+			MountPath: file.Path,
+			SubPath:   file.Key,
+			ReadOnly:  true,
+		})
 	}
-	return reconcile.Result{}, r.UpdatePhase(v2.BatchRunning, nil)
+	for i, secret := range batch.Spec.Secrets {
+		volumeName := fmt.Sprintf("batch-secret-%d", i)
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: secret.Name},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: batchSecretMountPath(secret),
+			ReadOnly:  true,
+		})
+	}
+	return job
 }
 
 func (r *batchRequest) waitToComplete() (reconcile.Result, error) {
@@ -262,6 +402,12 @@ func (r *batchRequest) waitToComplete() (reconcile.Result, error) {
 		return *result, err
 	}
 
+	// Stream the log of the batch pod while the job is running, so that failed commands can be diagnosed early
+	log, logErr := r.streamLog()
+	if logErr != nil {
+		r.reqLogger.Info("Unable to stream batch log", "error", logErr.Error())
+	}
+
 	status := job.Status
 	if status.Succeeded > 0 {
 		return reconcile.Result{}, r.UpdatePhase(v2.BatchSucceeded, nil)
@@ -273,18 +419,14 @@ func (r *batchRequest) waitToComplete() (reconcile.Result, error) {
 			condition := status.Conditions[numConditions-1]
 
 			if condition.Type == batchv1.JobFailed {
-				var reason string
-				podName, err := GetJobPodName(batch.Name, batch.Namespace, r.Client, r.ctx)
-				if err != nil {
-					reason = err.Error()
-				} else {
-					reason, err = r.kubernetes.Logs(podName, batch.Namespace, r.ctx)
-					if err != nil {
-						reason = fmt.Errorf("unable to retrive logs for batch %s: %w", batch.Name, err).Error()
-					}
+				reason := log
+				if logErr != nil {
+					reason = logErr.Error()
+				} else if failed := failedBatchCommand(batch.Status.Commands); failed != nil {
+					reason = fmt.Sprintf("command '%s' failed with exit code %d", failed.Command, *failed.ExitCode)
 				}
 
-				_, err = r.update(func() error {
+				_, err := r.update(func() error {
 					r.batch.Status.Phase = v2.BatchFailed
 					r.batch.Status.Reason = reason
 					return nil
@@ -293,8 +435,8 @@ func (r *batchRequest) waitToComplete() (reconcile.Result, error) {
 			}
 		}
 	}
-	// The job has not completed, wait 1 second before retrying
-	return reconcile.Result{}, nil
+	// The job has not completed, requeue to refresh the streamed log
+	return reconcile.Result{RequeueAfter: BatchLogRefresh}, nil
 }
 
 func (r *batchRequest) UpdatePhase(phase v2.BatchPhase, phaseErr error) error {
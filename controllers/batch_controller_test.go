@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"testing"
+
+	v1 "github.com/infinispan/infinispan-operator/api/v1"
+	v2 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateBatchFiles(t *testing.T) {
+	spec := &v2.BatchSpec{
+		ConfigMap:      pointer.StringPtr("schemas"),
+		ConfigMapFiles: []v2.BatchFileSpec{{Key: "person.proto", Path: "/tmp/schemas/person.proto"}},
+		Secrets:        []v2.BatchSecretSpec{{Name: "keystore"}},
+	}
+	assert.NoError(t, validateBatchFiles(spec))
+
+	spec.Secrets = append(spec.Secrets, v2.BatchSecretSpec{Name: "truststore", MountPath: "/tmp/schemas/person.proto/"})
+	assert.EqualError(t, validateBatchFiles(spec), "the path '/tmp/schemas/person.proto/' is mounted more than once")
+
+	spec.Secrets = []v2.BatchSecretSpec{{Name: "truststore", MountPath: "certs"}}
+	assert.EqualError(t, validateBatchFiles(spec), "the mount path 'certs' of the Secret 'truststore' must be absolute")
+
+	spec = &v2.BatchSpec{Config: pointer.StringPtr("create cache"), ConfigMapFiles: []v2.BatchFileSpec{{Key: "person.proto", Path: "/tmp/person.proto"}}}
+	assert.EqualError(t, validateBatchFiles(spec), "'spec.configMapFiles' requires 'spec.configMap'")
+}
+
+func TestValidateBatchCommands(t *testing.T) {
+	assert.NoError(t, validateBatchCommands("# create the caches\n\ncreate cache books --file=/etc/batch/books.xml\nput --cache=books k1 v1\n  ls caches/books\n"))
+	assert.EqualError(t, validateBatchCommands("create cache books --template=org.infinispan.DIST_SYNC\n  cache books\nput k1 v1"),
+		"the batch command 'cache books' changes the CLI session, which isn't kept between the commands of a batch, use the --cache option or absolute resource paths instead")
+	assert.Error(t, validateBatchCommands("cd caches"))
+	assert.Error(t, validateBatchCommands("connect http://localhost:11222"))
+}
+
+func TestComputeBatchJob(t *testing.T) {
+	infinispan := &v1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace}}
+	batch := &v2.Batch{
+		ObjectMeta: metav1.ObjectMeta{Name: "schemas", Namespace: namespace},
+		Spec: v2.BatchSpec{
+			Cluster:        "example-infinispan",
+			ConfigMap:      pointer.StringPtr("schemas-cm"),
+			ConfigMapFiles: []v2.BatchFileSpec{{Key: "person.proto", Path: "/tmp/schemas/person.proto"}},
+			Secrets:        []v2.BatchSecretSpec{{Name: "keystore"}, {Name: "truststore", MountPath: "/tmp/truststore"}},
+		},
+	}
+	spec := computeBatchJob(batch, infinispan).Spec.Template.Spec
+	assert.Equal(t, "schemas-cm", spec.Volumes[0].ConfigMap.Name)
+	assert.Equal(t, "keystore", spec.Volumes[2].Secret.SecretName)
+	assert.Equal(t, "truststore", spec.Volumes[3].Secret.SecretName)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: BatchVolumeName, MountPath: "/etc/batch"},
+		{Name: AdminIdentitiesVolumeName, MountPath: "/etc/security/admin"},
+		{Name: BatchVolumeName, MountPath: "/tmp/schemas/person.proto", SubPath: "person.proto", ReadOnly: true},
+		{Name: "batch-secret-0", MountPath: "/etc/batch-secrets/keystore", ReadOnly: true},
+		{Name: "batch-secret-1", MountPath: "/tmp/truststore", ReadOnly: true},
+	}, spec.Containers[0].VolumeMounts)
+}
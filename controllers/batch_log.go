@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v2 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	BatchLogFilename = "log"
+	// BatchLogMaxBytes bounds the log stored in the ConfigMap, well below the ConfigMap size limit
+	BatchLogMaxBytes = 512 * 1024
+
+	BatchCommandMarker  = "### BATCH COMMAND: "
+	BatchStderrMarker   = "### BATCH STDERR:"
+	BatchExitCodeMarker = "### BATCH EXIT CODE: "
+
+	batchStderrSnippetLines = 10
+	batchStderrSnippetBytes = 1024
+)
+
+// BatchScript runs every command of the batch file in its own CLI session, so that the exit code and stderr of each
+// command can be reported. Execution stops at the first failed command, as with a single CLI batch. The session state
+// isn't kept between commands, validateBatchCommands rejects the commands that only change it
+const BatchScript = `cmd_file=$(mktemp)
+err_file=$(mktemp)
+while IFS= read -r cmd || [ -n "$cmd" ]; do
+  cmd="${cmd#"${cmd%%[![:space:]]*}"}"
+  case "$cmd" in
+    ""|"#"*) continue ;;
+  esac
+  echo "` + BatchCommandMarker + `$cmd"
+  printf '%s\n' "$cmd" > "$cmd_file"
+  /opt/infinispan/bin/cli.sh --properties "$CLI_PROPERTIES" --file "$cmd_file" 2> "$err_file"
+  rc=$?
+  if [ -s "$err_file" ]; then
+    echo "` + BatchStderrMarker + `"
+    cat "$err_file"
+  fi
+  echo "` + BatchExitCodeMarker + `$rc"
+  [ $rc -eq 0 ] || exit $rc
+done < "$BATCH_FILE"
+`
+
+// BatchLogConfigMapName returns the name of the ConfigMap the batch log is streamed to
+func BatchLogConfigMapName(batchName string) string {
+	return batchName + "-log"
+}
+
+// parseBatchLog extracts the status of the executed commands from the log written by BatchScript
+func parseBatchLog(log string) []v2.BatchCommandStatus {
+	var commands []v2.BatchCommandStatus
+	var stderr []string
+	inStderr := false
+	for _, line := range strings.Split(log, "\n") {
+		switch {
+		case strings.HasPrefix(line, BatchCommandMarker):
+			commands = append(commands, v2.BatchCommandStatus{Command: strings.TrimPrefix(line, BatchCommandMarker)})
+			stderr, inStderr = nil, false
+		case len(commands) == 0:
+			continue
+		case line == BatchStderrMarker:
+			inStderr = true
+		case strings.HasPrefix(line, BatchExitCodeMarker):
+			if exitCode, err := strconv.ParseInt(strings.TrimPrefix(line, BatchExitCodeMarker), 10, 32); err == nil {
+				command := &commands[len(commands)-1]
+				command.ExitCode = pointer.Int32Ptr(int32(exitCode))
+				command.Stderr = stderrSnippet(stderr)
+			}
+			stderr, inStderr = nil, false
+		case inStderr:
+			stderr = append(stderr, line)
+		}
+	}
+	return commands
+}
+
+// stderrSnippet returns the last lines of stderr, bounded to a size suitable for the Batch status
+func stderrSnippet(lines []string) string {
+	if len(lines) > batchStderrSnippetLines {
+		lines = lines[len(lines)-batchStderrSnippetLines:]
+	}
+	snippet := strings.TrimSpace(strings.Join(lines, "\n"))
+	if len(snippet) > batchStderrSnippetBytes {
+		snippet = snippet[len(snippet)-batchStderrSnippetBytes:]
+	}
+	return snippet
+}
+
+// failedBatchCommand returns the first command that completed with a non zero exit code
+func failedBatchCommand(commands []v2.BatchCommandStatus) *v2.BatchCommandStatus {
+	for i, command := range commands {
+		if command.ExitCode != nil && *command.ExitCode != 0 {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// streamLog copies the current log of the batch pod to the log ConfigMap and updates the status of the executed commands
+func (r *batchRequest) streamLog() (string, error) {
+	batch := r.batch
+	podName, err := GetJobPodName(batch.Name, batch.Namespace, r.Client, r.ctx)
+	if err != nil {
+		return "", err
+	}
+	log, err := r.kubernetes.Logs(podName, batch.Namespace, r.ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrive logs for batch %s: %w", batch.Name, err)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BatchLogConfigMapName(batch.Name),
+			Namespace: batch.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(r.ctx, r.Client, configMap, func() error {
+		data := log
+		if len(data) > BatchLogMaxBytes {
+			data = "[truncated]\n" + data[len(data)-BatchLogMaxBytes:]
+		}
+		configMap.Data = map[string]string{BatchLogFilename: data}
+		return controllerutil.SetControllerReference(batch, configMap, r.scheme)
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to create ConfigMap '%s': %w", configMap.Name, err)
+	}
+
+	commands := parseBatchLog(log)
+	_, err = r.update(func() error {
+		batch.Status.Commands = commands
+		batch.Status.LogConfigMap = configMap.Name
+		return nil
+	})
+	return log, err
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	v2 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+)
+
+func TestParseBatchLog(t *testing.T) {
+	log := `Picked up JAVA_TOOL_OPTIONS
+### BATCH COMMAND: create cache --template=org.infinispan.DIST_SYNC mycache
+### BATCH EXIT CODE: 0
+### BATCH COMMAND: put --cache=mycache hello world
+### BATCH STDERR:
+ISPN014000: Unknown cache
+### BATCH EXIT CODE: 1
+`
+	assert.Equal(t, []v2.BatchCommandStatus{
+		{Command: "create cache --template=org.infinispan.DIST_SYNC mycache", ExitCode: pointer.Int32Ptr(0)},
+		{Command: "put --cache=mycache hello world", ExitCode: pointer.Int32Ptr(1), Stderr: "ISPN014000: Unknown cache"},
+	}, parseBatchLog(log))
+
+	// The exit code of the running command is unset
+	commands := parseBatchLog("### BATCH COMMAND: create counter mycounter\n")
+	assert.Equal(t, []v2.BatchCommandStatus{{Command: "create counter mycounter"}}, commands)
+	assert.Nil(t, failedBatchCommand(commands))
+
+	assert.Empty(t, parseBatchLog(""))
+}
+
+func TestFailedBatchCommand(t *testing.T) {
+	commands := []v2.BatchCommandStatus{
+		{Command: "create cache mycache", ExitCode: pointer.Int32Ptr(0)},
+		{Command: "put --cache=mycache hello world", ExitCode: pointer.Int32Ptr(1)},
+	}
+	assert.Equal(t, &commands[1], failedBatchCommand(commands))
+	assert.Nil(t, failedBatchCommand(commands[:1]))
+}
+
+func TestStderrSnippet(t *testing.T) {
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, "line")
+	}
+	assert.Equal(t, batchStderrSnippetLines, len(strings.Split(stderrSnippet(lines), "\n")))
+	assert.Len(t, stderrSnippet([]string{strings.Repeat("x", 2*batchStderrSnippetBytes)}), batchStderrSnippetBytes)
+}
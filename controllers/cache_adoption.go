@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	EventReasonCacheAdopted          = "CacheAdopted"
+	EventReasonCacheAdoptionConflict = "CacheAdoptionConflict"
+)
+
+// cacheAdoptionConflicts compares the configuration of a cache that already exists on the cluster with `template`,
+// the configuration of the Cache CR, returning the differences that prevent the cache from being adopted. Caches
+// without a template are always adopted
+func cacheAdoptionConflicts(cluster ispn.ClusterInterface, cache *infinispanv2alpha1.Cache, template, podName string) ([]string, error) {
+	if template == "" {
+		return nil, nil
+	}
+	live, err := cluster.GetCacheConfiguration(cache.GetCacheName(), podName)
+	if err != nil {
+		return nil, err
+	}
+	if equal, err := cluster.CompareCacheConfigurations(template, live, false, podName); err != nil || equal {
+		return nil, err
+	}
+	desired, err := cluster.ConvertCacheConfiguration(template, "", podName)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := cacheConfigurationDiff(desired, live)
+	if err != nil {
+		return nil, err
+	}
+	if len(diff) == 0 {
+		// The server considers the configurations different even though the attributes of the template match
+		diff = []string{"the live configuration defines attributes that are not part of the template"}
+	}
+	return diff, nil
+}
+
+// cacheConfigurationDiff lists the attributes of the desired JSON configuration whose value differs in the live JSON
+// configuration. The attributes only defined by the live configuration are server defaults and aren't reported
+func cacheConfigurationDiff(desired, live string) ([]string, error) {
+	desiredAttrs, err := flattenCacheConfiguration(desired)
+	if err != nil {
+		return nil, err
+	}
+	liveAttrs, err := flattenCacheConfiguration(live)
+	if err != nil {
+		return nil, err
+	}
+	var diff []string
+	for path, value := range desiredAttrs {
+		if liveValue, ok := liveAttrs[path]; !ok {
+			diff = append(diff, fmt.Sprintf("%s: expected %s, not defined", path, value))
+		} else if liveValue != value {
+			diff = append(diff, fmt.Sprintf("%s: expected %s, found %s", path, value, liveValue))
+		}
+	}
+	sort.Strings(diff)
+	return diff, nil
+}
+
+// flattenCacheConfiguration returns the leaf attributes of a JSON cache configuration keyed by their path. The
+// configuration returned for a cache is wrapped in an object keyed by the cache name, which is removed
+func flattenCacheConfiguration(config string) (map[string]string, error) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(config), &root); err != nil {
+		return nil, fmt.Errorf("unable to parse the cache configuration: %w", err)
+	}
+	if wrapper, ok := root.(map[string]interface{}); ok && len(wrapper) == 1 {
+		for key, value := range wrapper {
+			if inner, ok := value.(map[string]interface{}); ok && !strings.HasSuffix(key, "-cache") && len(inner) == 1 {
+				root = inner
+			}
+		}
+	}
+	attrs := map[string]string{}
+	flattenJSON("", root, attrs)
+	return attrs, nil
+}
+
+func flattenJSON(path string, value interface{}, attrs map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenJSON(childPath, child, attrs)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenJSON(fmt.Sprintf("%s[%d]", path, i), child, attrs)
+		}
+	default:
+		// Values are compared in their string form, as the server returns some numbers and booleans as strings
+		attrs[path] = fmt.Sprint(v)
+	}
+}
+
+// reportAdoptionConflicts marks the Cache as not ready with the differences between its configuration and the
+// configuration of the existing cache, which is left untouched
+func (r *CacheReconciler) reportAdoptionConflicts(ctx context.Context, cache *infinispanv2alpha1.Cache, conflicts []string) (reconcile.Result, error) {
+	message := fmt.Sprintf("Cache %s already exists with a different configuration: %s", cache.GetCacheName(), strings.Join(conflicts, "; "))
+	if cache.SetCondition(infinispanv2alpha1.CacheConditionReady, metav1.ConditionFalse, message) {
+		r.eventRec.Event(cache, corev1.EventTypeWarning, EventReasonCacheAdoptionConflict, message)
+		if err := r.Client.Status().Update(ctx, cache); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+)
+
+// adoptionCluster holds an existing cache, the configurations are already in their canonical JSON form
+type adoptionCluster struct {
+	ispn.ClusterInterface
+	live string
+}
+
+func (c *adoptionCluster) GetCacheConfiguration(cacheName, podName string) (string, error) {
+	return c.live, nil
+}
+
+func (c *adoptionCluster) CompareCacheConfigurations(configuration, other string, ignoreMutable bool, podName string) (bool, error) {
+	return configuration == other, nil
+}
+
+func (c *adoptionCluster) ConvertCacheConfiguration(configuration, mediaType, podName string) (string, error) {
+	return configuration, nil
+}
+
+func TestCacheAdoptionConflicts(t *testing.T) {
+	template := `{"distributed-cache":{"mode":"SYNC","owners":2}}`
+	cluster := &adoptionCluster{live: template}
+	cache := &v2alpha1.Cache{Spec: v2alpha1.CacheSpec{Name: "mycache", Template: template}}
+
+	conflicts, err := cacheAdoptionConflicts(cluster, cache, template, "pod")
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// Caches without a template are adopted whatever their configuration
+	conflicts, err = cacheAdoptionConflicts(cluster, cache, "", "pod")
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// Only the attributes of the template are reported, the live configuration being wrapped by the cache name
+	cluster.live = `{"mycache":{"distributed-cache":{"mode":"ASYNC","owners":"2","statistics":true}}}`
+	conflicts, err = cacheAdoptionConflicts(cluster, cache, template, "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"distributed-cache.mode: expected SYNC, found ASYNC"}, conflicts)
+
+	cluster.live = `{"replicated-cache":{"mode":"SYNC"}}`
+	conflicts, err = cacheAdoptionConflicts(cluster, cache, template, "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"distributed-cache.mode: expected SYNC, not defined",
+		"distributed-cache.owners: expected 2, not defined",
+	}, conflicts)
+}
+
+func TestCacheIsManaged(t *testing.T) {
+	cache := &v2alpha1.Cache{}
+	assert.False(t, cache.IsManaged())
+	cache.Status.Origin = v2alpha1.CacheOriginAdopted
+	assert.True(t, cache.IsManaged())
+	// Caches created before the origin was recorded
+	assert.True(t, (&v2alpha1.Cache{Status: v2alpha1.CacheStatus{ServiceName: "example-infinispan"}}).IsManaged())
+}
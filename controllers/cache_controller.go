@@ -3,11 +3,14 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/go-logr/logr"
 	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
 	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
 	"github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
 	caches "github.com/infinispan/infinispan-operator/pkg/infinispan/caches"
 	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
 	corev1 "k8s.io/api/core/v1"
@@ -20,7 +23,9 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // CacheReconciler reconciles a Cache object
@@ -39,12 +44,59 @@ func (r *CacheReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.scheme = mgr.GetScheme()
 	r.kubernetes = kube.NewKubernetesFromController(mgr)
 	r.eventRec = mgr.GetEventRecorderFor("cache-controller")
+
+	ctx := context.TODO()
+	// Add the CacheTemplate name to the index, so that the Caches referencing an updated template are reconciled
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &infinispanv2alpha1.Cache{}, CacheTemplateRefField, func(obj client.Object) []string {
+		return []string{obj.(*infinispanv2alpha1.Cache).Spec.TemplateRef}
+	}); err != nil {
+		return err
+	}
+	// Add the remote store cluster to the index, so that the Caches are reconciled when its credentials change
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &infinispanv2alpha1.Cache{}, CacheRemoteStoreClusterField, func(obj client.Object) []string {
+		if cluster := remoteStoreCluster(obj.(*infinispanv2alpha1.Cache)); cluster != "" {
+			return []string{cluster}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&infinispanv2alpha1.Cache{}).
+		Watches(
+			&source.Kind{Type: &infinispanv1.Infinispan{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					return r.remoteStoreCacheRequests(ctx, a.(*infinispanv1.Infinispan))
+				}),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					return r.identitiesCacheRequests(ctx, a)
+				}),
+		).
+		Watches(
+			&source.Kind{Type: &infinispanv2alpha1.CacheTemplate{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					var requests []reconcile.Request
+					cacheList := &infinispanv2alpha1.CacheList{}
+					if err := r.kubernetes.ResourcesListByField(a.GetNamespace(), CacheTemplateRefField, a.GetName(), cacheList, ctx); err != nil {
+						r.log.Error(err, "failed to list Cache CR")
+					}
+					for _, item := range cacheList.Items {
+						requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}})
+					}
+					return requests
+				}),
+		).
 		Complete(r)
 }
 
 // +kubebuilder:rbac:groups=infinispan.org,resources=caches;caches/status;caches/finalizers,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=infinispan.org,resources=cachetemplates,verbs=get;list;watch
 
 func (r *CacheReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 
@@ -60,13 +112,20 @@ func (r *CacheReconciler) Reconcile(ctx context.Context, request ctrl.Request) (
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
-			reqLogger.Info("Cache resource not found. Ignoring it since cache deletion is not supported")
+			reqLogger.Info("Cache resource not found. Ignoring it since object must be deleted")
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
 
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.finalizeCache(ctx, instance)
+	}
+	if applyCacheFinalizer(instance) {
+		return reconcile.Result{}, r.Client.Update(ctx, instance)
+	}
+
 	if instance.Spec.AdminAuth != nil {
 		reqLogger.Info("Ignoring and removing 'spec.AdminAuth' field. The operator's admin credentials are now used to perform cache operations")
 		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, instance, func() error {
@@ -76,6 +135,30 @@ func (r *CacheReconciler) Reconcile(ctx context.Context, request ctrl.Request) (
 		return reconcile.Result{}, err
 	}
 
+	if err := validateCacheTemplateFormats(instance); err != nil {
+		reqLogger.Error(err, "Error creating cache")
+		return reconcile.Result{}, err
+	}
+	if err := validateCacheRemoteStore(instance); err != nil {
+		reqLogger.Error(err, "Error creating cache")
+		return reconcile.Result{}, err
+	}
+
+	var template *infinispanv2alpha1.CacheTemplate
+	if err := validateCacheTemplateRef(instance); err != nil {
+		reqLogger.Error(err, "Error creating cache")
+		return reconcile.Result{}, err
+	} else if instance.Spec.TemplateRef != "" {
+		template = &infinispanv2alpha1.CacheTemplate{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.TemplateRef}, template); err != nil {
+			if errors.IsNotFound(err) {
+				reqLogger.Info(fmt.Sprintf("CacheTemplate %s not found", instance.Spec.TemplateRef))
+				return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+			}
+			return reconcile.Result{}, err
+		}
+	}
+
 	// Reconcile cache
 	reqLogger.Info("Identify the target cluster")
 	// Fetch the Infinispan cluster info
@@ -91,6 +174,10 @@ func (r *CacheReconciler) Reconcile(ctx context.Context, request ctrl.Request) (
 		return reconcile.Result{}, err
 	}
 
+	if result, err := r.reconcileCacheFeatures(ctx, ispnInstance, instance, template); result != nil {
+		return *result, err
+	}
+
 	// Cluster must be well formed
 	if !ispnInstance.IsWellFormed() {
 		reqLogger.Info(fmt.Sprintf("Infinispan cluster %s not well formed", ispnInstance.Name))
@@ -109,45 +196,211 @@ func (r *CacheReconciler) Reconcile(ctx context.Context, request ctrl.Request) (
 		return reconcile.Result{}, nil
 	}
 
+	specTemplate, _ := instance.GetTemplate()
+	if indexing := instance.Spec.Indexing; indexing != nil {
+		if len(indexing.IndexedEntities) > 0 && (instance.Spec.TemplateName != "" || specTemplate != "") {
+			errIndexing := fmt.Errorf("indexing.indexedEntities cannot be combined with a template, configure indexing in the template instead")
+			reqLogger.Error(errIndexing, "Error creating cache")
+			return reconcile.Result{}, errIndexing
+		}
+	}
+
 	cluster, err := NewCluster(ispnInstance, r.kubernetes, ctx)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	templateHash := instance.Status.TemplateHash
+	canonicalTemplate := ""
+	remoteStoreHash := instance.Status.RemoteStoreHash
+	requiresRecreate := false
+	requiresRecreateMessage := "The template changes attributes that can only be changed by recreating the cache, set spec.updates.strategy=recreate to discard the cache entries and recreate it"
+	drifted := false
+	origin := instance.Status.Origin
+	schemaHashes := instance.Status.SchemaHashes
 	existsCache, err := cluster.ExistsCache(instance.GetCacheName(), podList.Items[0].Name)
 	if err == nil {
+		// The schemas are lost along with the cache when a cluster without persistence restarts
+		if schemaHashes, err = registerCacheSchemas(cluster, instance, !existsCache, podList.Items[0].Name, reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
 		if existsCache {
 			reqLogger.Info(fmt.Sprintf("Cache %s already exists", instance.GetCacheName()))
-			// Check if template matches?
+			if !instance.IsManaged() {
+				// The cache was created outside of the Cache CR, it's only adopted if its configuration matches
+				adoptedTemplate := ""
+				if template != nil {
+					adoptedTemplate = template.Spec.Template
+				} else if specTemplate != "" {
+					if adoptedTemplate, err = cacheCanonicalTemplate(cluster, instance, podList.Items[0].Name); err != nil {
+						reqLogger.Error(err, "Error converting the cache template")
+						return reconcile.Result{}, err
+					}
+				}
+				conflicts, err := cacheAdoptionConflicts(cluster, instance, adoptedTemplate, podList.Items[0].Name)
+				if err != nil {
+					reqLogger.Error(err, "Error comparing the existing cache configuration")
+					return reconcile.Result{}, err
+				}
+				if len(conflicts) > 0 {
+					return r.reportAdoptionConflicts(ctx, instance, conflicts)
+				}
+				origin = infinispanv2alpha1.CacheOriginAdopted
+				r.eventRec.Event(instance, corev1.EventTypeNormal, EventReasonCacheAdopted, "Adopted the existing cache of the cluster")
+			}
+			if template != nil {
+				action := cacheTemplateUpdateAction(template, templateHash)
+				if action != infinispanv2alpha1.CacheTemplateUpdateNone {
+					reqLogger.Info("CacheTemplate changed, updating cache", "template", template.Name, "updatePolicy", action)
+					if err := applyCacheTemplateUpdate(cluster, instance.GetCacheName(), template, action, podList.Items[0].Name); err != nil {
+						r.eventRec.Event(instance, corev1.EventTypeWarning, EventReasonCacheTemplateApplyFailed, err.Error())
+						reqLogger.Error(err, "Error updating cache from CacheTemplate")
+						return reconcile.Result{}, err
+					}
+					r.eventRec.Event(instance, corev1.EventTypeNormal, EventReasonCacheTemplateApplied, fmt.Sprintf("Cache updated from CacheTemplate %s with policy %s", template.Name, action))
+					templateHash = hash.HashString(template.Spec.Template)
+				} else if templateHash == "" {
+					templateHash = hash.HashString(template.Spec.Template)
+				} else if templateHash == hash.HashString(template.Spec.Template) {
+					// The CacheTemplate is unchanged, any difference with the live configuration is an out-of-band change
+					if drifted, err = r.reconcileCacheDrift(cluster, instance, template.Spec.Template, podList.Items[0].Name); err != nil {
+						reqLogger.Error(err, "Error reconciling the out-of-band changes to the cache configuration")
+						return reconcile.Result{}, err
+					}
+				}
+			} else if instance.Spec.RemoteStore != nil {
+				// The remote store holds the credentials of the remote cluster, which cannot be changed at runtime
+				canonicalTemplate = instance.Status.CanonicalTemplate
+				var recreated bool
+				remoteStoreHash, recreated, requiresRecreate, err = r.reconcileRemoteStore(ctx, cluster, ispnInstance, instance, podList.Items[0].Name, reqLogger)
+				if err != nil {
+					r.eventRec.Event(instance, corev1.EventTypeWarning, EventReasonCacheUpdateFailed, err.Error())
+					reqLogger.Error(err, "Error updating the remote store")
+					return reconcile.Result{}, err
+				}
+				requiresRecreateMessage = "The template or the remote store credentials changed, which can only be applied by recreating the cache, set spec.updates.strategy=recreate to discard the cache entries held in memory and recreate it"
+				if recreated {
+					r.eventRec.Event(instance, corev1.EventTypeNormal, EventReasonCacheRecreated, "Cache recreated with the changed remote store")
+					if specTemplate != "" {
+						templateHash = hash.HashString(specTemplate)
+						if canonicalTemplate, err = cacheCanonicalTemplate(cluster, instance, podList.Items[0].Name); err != nil {
+							reqLogger.Error(err, "Error converting the cache template")
+							return reconcile.Result{}, err
+						}
+					}
+				}
+			} else if specTemplate != "" {
+				specHash := hash.HashString(specTemplate)
+				canonical, err := cacheCanonicalTemplate(cluster, instance, podList.Items[0].Name)
+				if err == nil {
+					canonical, err = cacheDefaultEviction(canonical, ispnInstance, instance)
+				}
+				if err != nil {
+					reqLogger.Error(err, "Error converting the cache template")
+					return reconcile.Result{}, err
+				}
+				if templateHash == specHash || canonical == instance.Status.CanonicalTemplate {
+					// The template is unchanged, or only its format changed, any difference with the live configuration is an out-of-band change
+					templateHash, canonicalTemplate = specHash, canonical
+					if drifted, err = r.reconcileCacheDrift(cluster, instance, canonical, podList.Items[0].Name); err != nil {
+						reqLogger.Error(err, "Error reconciling the out-of-band changes to the cache configuration")
+						return reconcile.Result{}, err
+					}
+				} else {
+					canonicalTemplate = instance.Status.CanonicalTemplate
+					action, err := cacheTemplateUpdate(cluster, instance, canonical, podList.Items[0].Name)
+					if err != nil {
+						reqLogger.Error(err, "Error comparing the cache configuration with the template")
+						return reconcile.Result{}, err
+					}
+					requiresRecreate = action == cacheUpdateRequiresRecreate
+					if action == cacheUpdateInPlace || action == cacheUpdateRecreate {
+						reqLogger.Info("Cache template changed, updating cache", "action", action)
+						if err := applyCacheTemplate(cluster, instance, canonical, action, podList.Items[0].Name); err != nil {
+							r.eventRec.Event(instance, corev1.EventTypeWarning, EventReasonCacheUpdateFailed, err.Error())
+							reqLogger.Error(err, "Error updating cache from template")
+							return reconcile.Result{}, err
+						}
+						if action == cacheUpdateRecreate {
+							r.eventRec.Event(instance, corev1.EventTypeNormal, EventReasonCacheRecreated, "Cache recreated with the changed template")
+						} else {
+							r.eventRec.Event(instance, corev1.EventTypeNormal, EventReasonCacheUpdated, "Cache updated in place with the changed template")
+						}
+					}
+					if !requiresRecreate {
+						templateHash, canonicalTemplate = specHash, canonical
+					}
+				}
+			}
+		} else if instance.Labels[infinispanv2alpha1.CachePolicyLabel] != "" && instance.IsManaged() {
+			// The cache was removed from the cluster, the CachePolicy deletes the Cache CR instead of recreating it
+			reqLogger.Info(fmt.Sprintf("Cache %s was removed from the cluster, waiting for CachePolicy %s", instance.GetCacheName(), instance.Labels[infinispanv2alpha1.CachePolicyLabel]))
+			return reconcile.Result{}, nil
 		} else {
 			reqLogger.Info(fmt.Sprintf("Cache %s doesn't exist, create it", instance.GetCacheName()))
+			origin = infinispanv2alpha1.CacheOriginCreated
 			podName := podList.Items[0].Name
 			templateName := instance.Spec.TemplateName
-			if ispnInstance.Spec.Service.Type == infinispanv1.ServiceTypeCache && (templateName != "" || instance.Spec.Template != "") {
-				errTemplate := fmt.Errorf("cannot create a cache with a template in a CacheService cluster")
-				reqLogger.Error(errTemplate, "Error creating cache")
-				return reconcile.Result{}, err
-			}
-			if templateName != "" {
+			if template != nil {
+				err = cluster.CreateCacheWithConfiguration(instance.GetCacheName(), template.Spec.Template, podName)
+				if err != nil {
+					reqLogger.Error(err, "Error creating cache from CacheTemplate")
+					return reconcile.Result{}, err
+				}
+				templateHash = hash.HashString(template.Spec.Template)
+			} else if templateName != "" {
 				err = cluster.CreateCacheWithTemplateName(instance.Spec.Name, templateName, podName)
 				if err != nil {
 					reqLogger.Error(err, "Error creating cache with template name")
 					return reconcile.Result{}, err
 				}
-			} else {
-				xmlTemplate := instance.Spec.Template
-				if xmlTemplate == "" {
-					xmlTemplate, err = caches.DefaultCacheTemplateXML(podName, ispnInstance, cluster, reqLogger)
+			} else if specTemplate != "" && instance.Spec.RemoteStore == nil {
+				canonical, err := cacheCanonicalTemplate(cluster, instance, podName)
+				if err == nil {
+					canonical, err = cacheDefaultEviction(canonical, ispnInstance, instance)
+				}
+				if err != nil {
+					reqLogger.Error(err, "Error converting the cache template")
+					return reconcile.Result{}, err
+				}
+				err = cluster.CreateCacheWithConfiguration(instance.GetCacheName(), canonical, podName)
+				if err != nil {
+					reqLogger.Error(err, "Error in creating cache")
+					return reconcile.Result{}, err
 				}
+				templateHash, canonicalTemplate = hash.HashString(specTemplate), canonical
+			} else {
+				xmlTemplate, err := cacheTemplateXML(cluster, ispnInstance, instance, podName, reqLogger)
 				if err != nil {
 					reqLogger.Error(err, "Error getting default XML")
 					return reconcile.Result{}, err
 				}
 				reqLogger.Info(xmlTemplate)
+				if instance.Spec.RemoteStore != nil {
+					target, err := r.remoteStoreTargetFor(ctx, instance)
+					if err != nil {
+						reqLogger.Error(err, "Error resolving the remote store")
+						return reconcile.Result{}, err
+					}
+					reqLogger.Info("Adding remote store", "host", target.host, "cache", target.cacheName)
+					persistence := remoteStorePersistence(target)
+					if xmlTemplate, err = addCachePersistence(xmlTemplate, persistence); err != nil {
+						reqLogger.Error(err, "Error adding the remote store")
+						return reconcile.Result{}, err
+					}
+					remoteStoreHash = remoteStoreConfigHash(instance, persistence)
+				}
 				err = cluster.CreateCacheWithTemplate(instance.Spec.Name, xmlTemplate, podName)
 				if err != nil {
 					reqLogger.Error(err, "Error in creating cache")
 					return reconcile.Result{}, err
 				}
+				if specTemplate != "" {
+					templateHash = hash.HashString(specTemplate)
+					if canonicalTemplate, err = cacheCanonicalTemplate(cluster, instance, podName); err != nil {
+						reqLogger.Error(err, "Error converting the cache template")
+						return reconcile.Result{}, err
+					}
+				}
 			}
 		}
 	} else {
@@ -170,7 +423,47 @@ func (r *CacheReconciler) Reconcile(ctx context.Context, request ctrl.Request) (
 		instance.Status.ServiceName = serviceList.Items[0].Name
 		statusUpdate = true
 	}
-	statusUpdate = instance.SetCondition("Ready", metav1.ConditionTrue, "") || statusUpdate
+	if instance.Status.TemplateHash != templateHash {
+		instance.Status.TemplateHash = templateHash
+		statusUpdate = true
+	}
+	if instance.Status.CanonicalTemplate != canonicalTemplate {
+		instance.Status.CanonicalTemplate = canonicalTemplate
+		statusUpdate = true
+	}
+	if instance.Status.Drifted != drifted {
+		instance.Status.Drifted = drifted
+		statusUpdate = true
+	}
+	if instance.Status.Origin != origin {
+		instance.Status.Origin = origin
+		statusUpdate = true
+	}
+	if instance.Status.RemoteStoreHash != remoteStoreHash {
+		instance.Status.RemoteStoreHash = remoteStoreHash
+		statusUpdate = true
+	}
+	if !reflect.DeepEqual(instance.Status.SchemaHashes, schemaHashes) {
+		instance.Status.SchemaHashes = schemaHashes
+		statusUpdate = true
+	}
+	statusUpdate = instance.SetCondition(infinispanv2alpha1.CacheConditionReady, metav1.ConditionTrue, "") || statusUpdate
+	if specTemplate != "" || instance.Spec.RemoteStore != nil {
+		if requiresRecreate {
+			if instance.SetCondition(infinispanv2alpha1.CacheConditionRequiresRecreate, metav1.ConditionTrue, requiresRecreateMessage) {
+				r.eventRec.Event(instance, corev1.EventTypeWarning, EventReasonCacheRequiresRecreate, requiresRecreateMessage)
+				statusUpdate = true
+			}
+		} else {
+			statusUpdate = instance.SetCondition(infinispanv2alpha1.CacheConditionRequiresRecreate, metav1.ConditionFalse, "") || statusUpdate
+		}
+	}
+	if details, err := cluster.GetCacheDetails(instance.GetCacheName(), podList.Items[0].Name); err != nil {
+		reqLogger.Error(err, "Unable to retrieve cache statistics")
+	} else if statistics := cacheStatistics(details); !reflect.DeepEqual(instance.Status.Statistics, statistics) {
+		instance.Status.Statistics = statistics
+		statusUpdate = true
+	}
 	if statusUpdate {
 		reqLogger.Info("Update CR status with connection info")
 		err = r.Client.Status().Update(ctx, instance)
@@ -179,5 +472,58 @@ func (r *CacheReconciler) Reconcile(ctx context.Context, request ctrl.Request) (
 			return reconcile.Result{}, err
 		}
 	}
-	return ctrl.Result{}, nil
+	// Requeue to keep the statistics up to date
+	return ctrl.Result{RequeueAfter: constants.DefaultCacheStatisticsRefresh}, nil
+}
+
+func cacheStatistics(details *ispn.CacheDetails) *infinispanv2alpha1.CacheStatistics {
+	statistics := &infinispanv2alpha1.CacheStatistics{
+		Entries:        details.Size,
+		RebalanceState: infinispanv2alpha1.CacheBalanced,
+	}
+	// Memory usage is only tracked for caches bounded by memory
+	if details.Stats.DataMemoryUsed > 0 {
+		statistics.MemoryUsedBytes = details.Stats.DataMemoryUsed
+	} else if details.Stats.OffHeapMemoryUsed > 0 {
+		statistics.MemoryUsedBytes = details.Stats.OffHeapMemoryUsed
+	}
+	if details.RehashInProgress {
+		statistics.RebalanceState = infinispanv2alpha1.CacheRebalancing
+	} else if details.RebalancingEnabled != nil && !*details.RebalancingEnabled {
+		statistics.RebalanceState = infinispanv2alpha1.CacheRebalancingDisabled
+	}
+	return statistics
+}
+
+// cacheTemplateXML returns the spec.template of the cache, or the XML configuration generated by the operator when the
+// cache has no template
+func cacheTemplateXML(cluster ispn.ClusterInterface, ispnInstance *infinispanv1.Infinispan, cache *infinispanv2alpha1.Cache, podName string, logger logr.Logger) (string, error) {
+	if template, _ := cache.GetTemplate(); template != "" {
+		return template, nil
+	}
+	if cache.Spec.Indexing != nil && len(cache.Spec.Indexing.IndexedEntities) > 0 {
+		return caches.IndexedCacheTemplateXML(podName, ispnInstance, cache.Spec.Indexing.IndexedEntities, cluster, logger)
+	}
+	return caches.DefaultCacheTemplateXML(podName, ispnInstance, cluster, logger)
+}
+
+// registerCacheSchemas registers the Protobuf schemas of the indexing configuration whose content changed since they
+// were last registered, or all of them when force is true, returning the hashes of the schemas by name
+func registerCacheSchemas(cluster ispn.ClusterInterface, cache *infinispanv2alpha1.Cache, force bool, podName string, logger logr.Logger) (map[string]string, error) {
+	if cache.Spec.Indexing == nil || len(cache.Spec.Indexing.Schemas) == 0 {
+		return nil, nil
+	}
+	hashes := make(map[string]string, len(cache.Spec.Indexing.Schemas))
+	for _, schema := range cache.Spec.Indexing.Schemas {
+		schemaHash := hash.HashString(schema.Schema)
+		if force || cache.Status.SchemaHashes[schema.Name] != schemaHash {
+			logger.Info("Registering protobuf schema", "schema", schema.Name)
+			if err := cluster.RegisterProtobufSchema(schema.Name, schema.Schema, podName); err != nil {
+				logger.Error(err, "Error registering protobuf schema", "schema", schema.Name)
+				return nil, err
+			}
+		}
+		hashes[schema.Name] = schemaHash
+	}
+	return hashes, nil
 }
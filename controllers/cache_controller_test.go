@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestCacheStatistics(t *testing.T) {
+	disabled := false
+	for _, test := range []struct {
+		details  ispn.CacheDetails
+		expected v2alpha1.CacheStatistics
+	}{
+		{
+			details:  ispn.CacheDetails{Size: 10, Stats: ispn.CacheStats{DataMemoryUsed: -1, OffHeapMemoryUsed: 0}},
+			expected: v2alpha1.CacheStatistics{Entries: 10, RebalanceState: v2alpha1.CacheBalanced},
+		},
+		{
+			details:  ispn.CacheDetails{Size: 10, Stats: ispn.CacheStats{DataMemoryUsed: 2048}, RehashInProgress: true},
+			expected: v2alpha1.CacheStatistics{Entries: 10, MemoryUsedBytes: 2048, RebalanceState: v2alpha1.CacheRebalancing},
+		},
+		{
+			details:  ispn.CacheDetails{Stats: ispn.CacheStats{OffHeapMemoryUsed: 4096}, RebalancingEnabled: &disabled},
+			expected: v2alpha1.CacheStatistics{MemoryUsedBytes: 4096, RebalanceState: v2alpha1.CacheRebalancingDisabled},
+		},
+	} {
+		assert.Equal(t, test.expected, *cacheStatistics(&test.details))
+	}
+}
+
+func TestApplyCacheFinalizer(t *testing.T) {
+	cache := &v2alpha1.Cache{}
+	assert.False(t, applyCacheFinalizer(cache))
+
+	cache.Spec.DeletionPolicy = v2alpha1.CacheDeletionDelete
+	assert.True(t, applyCacheFinalizer(cache))
+	assert.Equal(t, []string{consts.CacheFinalizer}, cache.Finalizers)
+	assert.False(t, applyCacheFinalizer(cache))
+
+	cache.Spec.DeletionPolicy = v2alpha1.CacheDeletionRetain
+	assert.True(t, applyCacheFinalizer(cache))
+	assert.Empty(t, cache.Finalizers)
+}
+
+func TestFinalizeCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, infinispanv1.AddToScheme(scheme))
+	assert.NoError(t, v2alpha1.AddToScheme(scheme))
+	now := metav1.Now()
+	cache := &v2alpha1.Cache{
+		ObjectMeta: metav1.ObjectMeta{Name: "mycache", Namespace: namespace, Finalizers: []string{consts.CacheFinalizer}, DeletionTimestamp: &now},
+		Spec:       v2alpha1.CacheSpec{ClusterName: "example-infinispan", DeletionPolicy: v2alpha1.CacheDeletionDelete},
+		Status:     v2alpha1.CacheStatus{Origin: v2alpha1.CacheOriginCreated},
+	}
+	infinispan := &infinispanv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace}}
+	r := &CacheReconciler{Client: fake.NewFakeClientWithScheme(scheme, cache.DeepCopy(), infinispan), log: logf.Log, scheme: scheme, eventRec: record.NewFakeRecorder(10)}
+	ctx := context.TODO()
+
+	// The finalizer is kept until the cluster is available to delete the cache
+	result, err := r.finalizeCache(ctx, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, consts.DefaultWaitOnCluster, result.RequeueAfter)
+	assert.True(t, controllerutil.ContainsFinalizer(cache, consts.CacheFinalizer))
+
+	// There's nothing to delete without the cluster
+	assert.NoError(t, r.Client.Delete(ctx, infinispan))
+	result, err = r.finalizeCache(ctx, cache)
+	assert.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	stored := &v2alpha1.Cache{}
+	assert.NoError(t, r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "mycache"}, stored))
+	assert.Empty(t, stored.Finalizers)
+}
+
+// schemaCluster records the registered schemas, the remaining methods are not expected to be called
+type schemaCluster struct {
+	ispn.ClusterInterface
+	registered []string
+}
+
+func (c *schemaCluster) RegisterProtobufSchema(schemaName, schema, podName string) error {
+	c.registered = append(c.registered, schemaName)
+	return nil
+}
+
+func TestRegisterCacheSchemas(t *testing.T) {
+	cache := &v2alpha1.Cache{Spec: v2alpha1.CacheSpec{Indexing: &v2alpha1.CacheIndexingSpec{
+		Schemas: []v2alpha1.ProtobufSchemaSpec{
+			{Name: "book.proto", Schema: "message Book {}"},
+			{Name: "author.proto", Schema: "message Author {}"},
+		},
+	}}}
+	register := func(force bool) []string {
+		cluster := &schemaCluster{}
+		hashes, err := registerCacheSchemas(cluster, cache, force, "pod-0", logf.Log)
+		assert.NoError(t, err)
+		cache.Status.SchemaHashes = hashes
+		return cluster.registered
+	}
+
+	assert.Equal(t, []string{"book.proto", "author.proto"}, register(false))
+	// Unchanged schemas are not registered again
+	assert.Empty(t, register(false))
+	cache.Spec.Indexing.Schemas[1].Schema = "message Author { optional string name = 1; }"
+	assert.Equal(t, []string{"author.proto"}, register(false))
+	assert.Equal(t, []string{"book.proto", "author.proto"}, register(true))
+
+	cache.Spec.Indexing.Schemas = cache.Spec.Indexing.Schemas[:1]
+	assert.Empty(t, register(false))
+	assert.Len(t, cache.Status.SchemaHashes, 1)
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/controllers/constants"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const EventReasonCacheDeleted = "CacheDeleted"
+
+// applyCacheFinalizer adds the finalizer deleting the cache from the cluster when the deletion policy is Delete, and
+// removes it otherwise. Returns true if the finalizers of the Cache have been changed
+func applyCacheFinalizer(cache *infinispanv2alpha1.Cache) bool {
+	hasFinalizer := controllerutil.ContainsFinalizer(cache, constants.CacheFinalizer)
+	if cache.GetDeletionPolicy() == infinispanv2alpha1.CacheDeletionDelete {
+		if !hasFinalizer {
+			controllerutil.AddFinalizer(cache, constants.CacheFinalizer)
+			return true
+		}
+	} else if hasFinalizer {
+		controllerutil.RemoveFinalizer(cache, constants.CacheFinalizer)
+		return true
+	}
+	return false
+}
+
+// finalizeCache deletes the cache from the cluster before the finalizer of the deleted Cache is removed. The cache
+// is considered deleted if the cluster doesn't exist anymore
+func (r *CacheReconciler) finalizeCache(ctx context.Context, cache *infinispanv2alpha1.Cache) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(cache, constants.CacheFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	ispnInstance := &infinispanv1.Infinispan{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: cache.Namespace, Name: cache.Spec.ClusterName}, ispnInstance); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+	} else if ispnInstance.DeletionTimestamp.IsZero() && cache.IsManaged() {
+		// Caches that failed to be adopted are not deleted, as they were never managed by the Cache CR
+		if !ispnInstance.IsWellFormed() {
+			r.log.Info(fmt.Sprintf("Infinispan cluster %s not well formed, waiting to delete cache %s", ispnInstance.Name, cache.GetCacheName()))
+			return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+		}
+		if err := r.deleteServerCache(ctx, ispnInstance, cache); err != nil {
+			r.eventRec.Event(cache, corev1.EventTypeWarning, EventReasonCacheUpdateFailed, err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+	controllerutil.RemoveFinalizer(cache, constants.CacheFinalizer)
+	return reconcile.Result{}, r.Client.Update(ctx, cache)
+}
+
+func (r *CacheReconciler) deleteServerCache(ctx context.Context, ispnInstance *infinispanv1.Infinispan, cache *infinispanv2alpha1.Cache) error {
+	podList, err := PodList(ispnInstance, r.kubernetes, ctx)
+	if err != nil {
+		return err
+	}
+	podName := ""
+	for _, pod := range podList.Items {
+		if kube.IsPodReady(pod) {
+			podName = pod.Name
+			break
+		}
+	}
+	if podName == "" {
+		return fmt.Errorf("no ready pod of Infinispan cluster %s to delete cache %s", ispnInstance.Name, cache.GetCacheName())
+	}
+	cluster, err := NewCluster(ispnInstance, r.kubernetes, ctx)
+	if err != nil {
+		return err
+	}
+	if exists, err := cluster.ExistsCache(cache.GetCacheName(), podName); err != nil || !exists {
+		return err
+	}
+	if err := cluster.DeleteCache(cache.GetCacheName(), podName); err != nil {
+		return err
+	}
+	r.eventRec.Event(cache, corev1.EventTypeNormal, EventReasonCacheDeleted, fmt.Sprintf("Cache %s deleted from Infinispan cluster %s", cache.GetCacheName(), ispnInstance.Name))
+	return nil
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+)
+
+// cacheDefaultEviction bounds the memory of a cache without memory limits to the defaultEvictionPercentage of the
+// server heap, by adding a memory max-size to its canonical JSON configuration. The configuration is returned
+// unchanged when the safeguard is disabled, the cache was adopted, has a remote store or already bounds its memory, or
+// its entries are stored as objects, which can't be bounded by size
+func cacheDefaultEviction(canonical string, i *infinispanv1.Infinispan, cache *infinispanv2alpha1.Cache) (string, error) {
+	sc := i.Spec.Service.Container
+	if sc == nil || sc.DefaultEvictionPercentage == 0 || cache.Spec.RemoteStore != nil || cache.Status.Origin == infinispanv2alpha1.CacheOriginAdopted {
+		return canonical, nil
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(canonical), &config); err != nil || len(config) != 1 {
+		// Not the JSON configuration of a single cache
+		return canonical, nil
+	}
+	var cacheConfig map[string]interface{}
+	for _, c := range config {
+		cacheConfig, _ = c.(map[string]interface{})
+	}
+	if cacheConfig == nil {
+		return canonical, nil
+	}
+	memory, _ := cacheConfig["memory"].(map[string]interface{})
+	if memory == nil {
+		memory = map[string]interface{}{}
+	}
+	if isMemoryBounded(memory) || strings.EqualFold(fmt.Sprint(memory["storage"]), "OBJECT") {
+		return canonical, nil
+	}
+	if encoding, ok := cacheConfig["encoding"].(map[string]interface{}); ok && strings.Contains(fmt.Sprint(encoding), "application/x-java-object") {
+		return canonical, nil
+	}
+
+	heap, err := i.Spec.Container.GetHeapSizeBytes()
+	if err != nil {
+		return "", fmt.Errorf("unable to compute the heap size of the server for the default eviction: %w", err)
+	}
+	memory["max-size"] = strconv.FormatInt(heap*int64(sc.DefaultEvictionPercentage)/100, 10)
+	if _, ok := memory["when-full"]; !ok {
+		memory["when-full"] = "REMOVE"
+	}
+	cacheConfig["memory"] = memory
+	bounded, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(bounded), nil
+}
+
+// isMemoryBounded returns true if the memory configuration bounds the cache by size or by count
+func isMemoryBounded(memory map[string]interface{}) bool {
+	if size, ok := memory["max-size"]; ok && fmt.Sprint(size) != "" && fmt.Sprint(size) != "-1" {
+		return true
+	}
+	if count, ok := memory["max-count"]; ok && fmt.Sprint(count) != "-1" {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheDefaultEviction(t *testing.T) {
+	i := &infinispanv1.Infinispan{Spec: infinispanv1.InfinispanSpec{
+		Container: infinispanv1.InfinispanContainerSpec{Memory: "2Gi"},
+		Service: infinispanv1.InfinispanServiceSpec{
+			Type:      infinispanv1.ServiceTypeDataGrid,
+			Container: &infinispanv1.InfinispanServiceContainerSpec{},
+		},
+	}}
+	cache := &infinispanv2alpha1.Cache{}
+	unbounded := `{"distributed-cache":{"mode":"SYNC","owners":"2"}}`
+
+	// The safeguard is disabled by default
+	config, err := cacheDefaultEviction(unbounded, i, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, unbounded, config)
+
+	i.Spec.Service.Container.DefaultEvictionPercentage = 10
+	config, err = cacheDefaultEviction(unbounded, i, cache)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"distributed-cache":{"mode":"SYNC","owners":"2","memory":{"max-size":"107374182","when-full":"REMOVE"}}}`, config)
+
+	// The safeguard is idempotent, as it's applied to the stored canonical templates
+	again, err := cacheDefaultEviction(config, i, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, config, again)
+
+	// The caches already bounded or storing objects are not changed
+	for _, c := range []string{
+		`{"distributed-cache":{"memory":{"max-count":"1000"}}}`,
+		`{"distributed-cache":{"memory":{"max-size":"1GB","when-full":"EXCEPTION"}}}`,
+		`{"distributed-cache":{"memory":{"storage":"OBJECT"}}}`,
+		`{"local-cache":{"encoding":{"media-type":"application/x-java-object"}}}`,
+		`<distributed-cache/>`,
+	} {
+		config, err = cacheDefaultEviction(c, i, cache)
+		assert.NoError(t, err)
+		assert.Equal(t, c, config)
+	}
+
+	// A max-count of -1 doesn't bound the memory
+	config, err = cacheDefaultEviction(`{"replicated-cache":{"memory":{"max-count":"-1"}}}`, i, cache)
+	assert.NoError(t, err)
+	assert.Contains(t, config, `"max-size":"107374182"`)
+
+	cache.Status.Origin = infinispanv2alpha1.CacheOriginAdopted
+	config, err = cacheDefaultEviction(unbounded, i, cache)
+	assert.NoError(t, err)
+	assert.Equal(t, unbounded, config)
+}
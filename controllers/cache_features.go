@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
+)
+
+// +kubebuilder:webhook:path=/validate-infinispan-org-v2alpha1-cache,mutating=false,failurePolicy=fail,sideEffects=None,groups=infinispan.org,resources=caches,verbs=create;update,versions=v2alpha1,name=vcache.kb.io,admissionReviewVersions={v1,v1beta1}
+
+const (
+	CacheValidatingWebhookPath = "/validate-infinispan-org-v2alpha1-cache"
+
+	EventReasonCacheFeaturesUnsupported = "CacheFeaturesUnsupported"
+
+	CacheFeatureIndexing     = "indexing"
+	CacheFeaturePersistence  = "persistence"
+	CacheFeatureTemplates    = "templates"
+	CacheFeatureTransactions = "transactions"
+)
+
+// cacheServiceUnsupportedFeatures are the cache features a CacheService cluster doesn't support, as its caches are
+// copies of the default cache, which is neither transactional nor persistent
+var cacheServiceUnsupportedFeatures = map[string]bool{
+	CacheFeatureIndexing:     true,
+	CacheFeaturePersistence:  true,
+	CacheFeatureTemplates:    true,
+	CacheFeatureTransactions: true,
+}
+
+// cacheFeatures returns the sorted features that the cache configuration uses, from the spec of the Cache and from its
+// template, whether provided in the spec or by the CacheTemplate
+func cacheFeatures(cache *infinispanv2alpha1.Cache, cacheTemplate *infinispanv2alpha1.CacheTemplate) []string {
+	features := map[string]bool{}
+	spec := cache.Spec
+	if spec.Indexing != nil {
+		features[CacheFeatureIndexing] = true
+	}
+	if spec.RemoteStore != nil {
+		features[CacheFeaturePersistence] = true
+	}
+	template, _ := cache.GetTemplate()
+	if template != "" || spec.TemplateName != "" || spec.TemplateRef != "" {
+		features[CacheFeatureTemplates] = true
+	}
+	if cacheTemplate != nil {
+		template = cacheTemplate.Spec.Template
+	}
+	for _, feature := range templateFeatures(template) {
+		features[feature] = true
+	}
+	sorted := make([]string, 0, len(features))
+	for feature := range features {
+		sorted = append(sorted, feature)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// templateFeatures returns the features that the cache template configures, in XML, YAML or JSON format. Templates that
+// can't be parsed are rejected by the server when the cache is created
+func templateFeatures(template string) []string {
+	features := map[string]bool{}
+	template = strings.TrimSpace(template)
+	if strings.HasPrefix(template, "<") {
+		decoder := xml.NewDecoder(strings.NewReader(template))
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				break
+			}
+			if element, ok := token.(xml.StartElement); ok {
+				attributes := map[string]string{}
+				for _, attr := range element.Attr {
+					attributes[attr.Name.Local] = attr.Value
+				}
+				addTemplateFeature(features, element.Name.Local, attributes["mode"], attributes["enabled"])
+			}
+		}
+	} else {
+		var content interface{}
+		if err := yaml.Unmarshal([]byte(template), &content); err == nil {
+			walkTemplateFeatures(features, content)
+		}
+	}
+	var list []string
+	for feature := range features {
+		list = append(list, feature)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func walkTemplateFeatures(features map[string]bool, content interface{}) {
+	switch value := content.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			attributes, _ := child.(map[string]interface{})
+			mode, _ := attributes["mode"].(string)
+			enabled := ""
+			if e, ok := attributes["enabled"]; ok {
+				enabled = fmt.Sprint(e)
+			}
+			addTemplateFeature(features, key, mode, enabled)
+			walkTemplateFeatures(features, child)
+		}
+	case []interface{}:
+		for _, child := range value {
+			walkTemplateFeatures(features, child)
+		}
+	}
+}
+
+// addTemplateFeature records the feature configured by an element of the template. Transactions are disabled with the
+// NONE mode and indexing with enabled=false
+func addTemplateFeature(features map[string]bool, element, mode, enabled string) {
+	switch element {
+	case "transaction":
+		if !strings.EqualFold(mode, "NONE") {
+			features[CacheFeatureTransactions] = true
+		}
+	case "persistence":
+		features[CacheFeaturePersistence] = true
+	case "indexing":
+		if enabled != "false" {
+			features[CacheFeatureIndexing] = true
+		}
+	}
+}
+
+// unsupportedCacheFeatures returns the features of the cache that the service type of the cluster doesn't support
+func unsupportedCacheFeatures(i *infinispanv1.Infinispan, cache *infinispanv2alpha1.Cache, cacheTemplate *infinispanv2alpha1.CacheTemplate) []string {
+	if i.Spec.Service.Type != infinispanv1.ServiceTypeCache {
+		return nil
+	}
+	var unsupported []string
+	for _, feature := range cacheFeatures(cache, cacheTemplate) {
+		if cacheServiceUnsupportedFeatures[feature] {
+			unsupported = append(unsupported, feature)
+		}
+	}
+	return unsupported
+}
+
+// validateCacheFeatures verifies that the service type of the cluster supports the features of the cache, as the server
+// would otherwise reject the configuration with an error that doesn't name the feature
+func validateCacheFeatures(i *infinispanv1.Infinispan, cache *infinispanv2alpha1.Cache, cacheTemplate *infinispanv2alpha1.CacheTemplate) error {
+	if unsupported := unsupportedCacheFeatures(i, cache, cacheTemplate); len(unsupported) > 0 {
+		return fmt.Errorf("the CacheService cluster '%s' doesn't support the cache features: %s, create a DataGrid cluster instead", i.Name, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// CacheValidator rejects the Cache CRs that would fail the validation of the controller, including the features that
+// the service type of the target cluster doesn't support
+type CacheValidator struct {
+	client.Client
+	decoder *admission.Decoder
+}
+
+// SetupCacheWebhook registers the Cache validating webhook with the webhook server of the Manager
+func SetupCacheWebhook(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register(CacheValidatingWebhookPath, &webhook.Admission{Handler: &CacheValidator{Client: mgr.GetClient()}})
+}
+
+func (v *CacheValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *CacheValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cache := &infinispanv2alpha1.Cache{}
+	if err := v.decoder.Decode(req, cache); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := v.validateCacheAdmission(ctx, cache); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateCacheAdmission applies the validation of the controller. The cluster and the CacheTemplate are not required
+// to exist yet, the features are validated by the controller once they do
+func (v *CacheValidator) validateCacheAdmission(ctx context.Context, cache *infinispanv2alpha1.Cache) error {
+	for _, validate := range []func(*infinispanv2alpha1.Cache) error{validateCacheTemplateFormats, validateCacheRemoteStore, validateCacheTemplateRef} {
+		if err := validate(cache); err != nil {
+			return err
+		}
+	}
+	infinispan := &infinispanv1.Infinispan{}
+	if err := v.Get(ctx, types.NamespacedName{Namespace: cache.Namespace, Name: cache.Spec.ClusterName}, infinispan); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	var cacheTemplate *infinispanv2alpha1.CacheTemplate
+	if cache.Spec.TemplateRef != "" {
+		cacheTemplate = &infinispanv2alpha1.CacheTemplate{}
+		if err := v.Get(ctx, types.NamespacedName{Namespace: cache.Namespace, Name: cache.Spec.TemplateRef}, cacheTemplate); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			cacheTemplate = nil
+		}
+	}
+	return validateCacheFeatures(infinispan, cache, cacheTemplate)
+}
+
+// reconcileCacheFeatures stops the reconciliation of a cache whose features aren't supported by the service type of
+// the cluster, reporting them with the UnsupportedFeatures condition until the Cache is changed
+func (r *CacheReconciler) reconcileCacheFeatures(ctx context.Context, i *infinispanv1.Infinispan, cache *infinispanv2alpha1.Cache, cacheTemplate *infinispanv2alpha1.CacheTemplate) (*reconcile.Result, error) {
+	err := validateCacheFeatures(i, cache, cacheTemplate)
+	if err == nil {
+		if cache.GetCondition(infinispanv2alpha1.CacheConditionUnsupportedFeatures).Status == metav1.ConditionTrue {
+			cache.SetCondition(infinispanv2alpha1.CacheConditionUnsupportedFeatures, metav1.ConditionFalse, "")
+			return &reconcile.Result{Requeue: true}, r.Client.Status().Update(ctx, cache)
+		}
+		return nil, nil
+	}
+	if cache.SetCondition(infinispanv2alpha1.CacheConditionUnsupportedFeatures, metav1.ConditionTrue, err.Error()) {
+		r.eventRec.Event(cache, corev1.EventTypeWarning, EventReasonCacheFeaturesUnsupported, err.Error())
+		cache.SetCondition(infinispanv2alpha1.CacheConditionReady, metav1.ConditionFalse, "")
+		if err := r.Client.Status().Update(ctx, cache); err != nil {
+			return &reconcile.Result{}, err
+		}
+	}
+	// The Cache is reconciled again once its spec is changed
+	return &reconcile.Result{}, nil
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func featuresCache(spec infinispanv2alpha1.CacheSpec) *infinispanv2alpha1.Cache {
+	spec.ClusterName = "example-infinispan"
+	return &infinispanv2alpha1.Cache{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "infinispan.org/v2alpha1", Kind: "Cache"},
+		ObjectMeta: metav1.ObjectMeta{Name: "example-cache", Namespace: "testing"},
+		Spec:       spec,
+	}
+}
+
+func featuresInfinispan(serviceType infinispanv1.ServiceType) *infinispanv1.Infinispan {
+	return &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec:       infinispanv1.InfinispanSpec{Service: infinispanv1.InfinispanServiceSpec{Type: serviceType}},
+	}
+}
+
+func TestTemplateFeatures(t *testing.T) {
+	assert.Equal(t, []string{CacheFeaturePersistence, CacheFeatureTransactions}, templateFeatures(`
+<distributed-cache mode="SYNC">
+  <transaction mode="NON_XA" locking="PESSIMISTIC"/>
+  <persistence><file-store/></persistence>
+</distributed-cache>`))
+	assert.Empty(t, templateFeatures(`<distributed-cache><transaction mode="NONE"/><indexing enabled="false"/></distributed-cache>`))
+
+	assert.Equal(t, []string{CacheFeatureIndexing, CacheFeatureTransactions}, templateFeatures(`
+distributedCache:
+  transaction:
+    mode: "FULL_XA"
+  indexing:
+    indexedEntities:
+    - book_sample.Book`))
+	assert.Equal(t, []string{CacheFeaturePersistence}, templateFeatures(`{"distributed-cache": {"persistence": {"file-store": {}}, "indexing": {"enabled": false}}}`))
+	assert.Empty(t, templateFeatures(`distributedCache: {mode: "SYNC"}`))
+	assert.Empty(t, templateFeatures(""))
+}
+
+func TestValidateCacheFeatures(t *testing.T) {
+	cacheService := featuresInfinispan(infinispanv1.ServiceTypeCache)
+	assert.NoError(t, validateCacheFeatures(cacheService, featuresCache(infinispanv2alpha1.CacheSpec{}), nil))
+
+	cache := featuresCache(infinispanv2alpha1.CacheSpec{TemplateRef: "transactional", Indexing: &infinispanv2alpha1.CacheIndexingSpec{}})
+	template := &infinispanv2alpha1.CacheTemplate{Spec: infinispanv2alpha1.CacheTemplateSpec{Template: `<replicated-cache><transaction mode="BATCH"/></replicated-cache>`}}
+	assert.EqualError(t, validateCacheFeatures(cacheService, cache, template),
+		"the CacheService cluster 'example-infinispan' doesn't support the cache features: indexing, templates, transactions, create a DataGrid cluster instead")
+
+	cache = featuresCache(infinispanv2alpha1.CacheSpec{RemoteStore: &infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote"}})
+	assert.EqualError(t, validateCacheFeatures(cacheService, cache, nil),
+		"the CacheService cluster 'example-infinispan' doesn't support the cache features: persistence, create a DataGrid cluster instead")
+
+	// DataGrid clusters support all the features
+	assert.NoError(t, validateCacheFeatures(featuresInfinispan(infinispanv1.ServiceTypeDataGrid), cache, template))
+}
+
+func TestCacheValidatorHandle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, infinispanv1.AddToScheme(scheme))
+	assert.NoError(t, infinispanv2alpha1.AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	assert.NoError(t, err)
+	validator := &CacheValidator{Client: fake.NewFakeClientWithScheme(scheme, featuresInfinispan(infinispanv1.ServiceTypeCache))}
+	assert.NoError(t, validator.InjectDecoder(decoder))
+
+	request := func(cache *infinispanv2alpha1.Cache) admission.Request {
+		raw, err := json.Marshal(cache)
+		assert.NoError(t, err)
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	assert.True(t, validator.Handle(context.TODO(), request(featuresCache(infinispanv2alpha1.CacheSpec{}))).Allowed)
+
+	response := validator.Handle(context.TODO(), request(featuresCache(infinispanv2alpha1.CacheSpec{Template: `<local-cache><persistence/></local-cache>`})))
+	assert.False(t, response.Allowed)
+	assert.Equal(t, "the CacheService cluster 'example-infinispan' doesn't support the cache features: persistence, templates, create a DataGrid cluster instead", string(response.Result.Reason))
+
+	// The validation of the controller is applied
+	response = validator.Handle(context.TODO(), request(featuresCache(infinispanv2alpha1.CacheSpec{TemplateXML: "<local-cache/>", TemplateYAML: "localCache: {}"})))
+	assert.False(t, response.Allowed)
+	assert.Equal(t, "only one of template, templateXML, templateYAML and templateJSON can be provided", string(response.Result.Reason))
+
+	// The features are validated by the controller when the cluster doesn't exist yet
+	cache := featuresCache(infinispanv2alpha1.CacheSpec{Indexing: &infinispanv2alpha1.CacheIndexingSpec{}})
+	cache.Spec.ClusterName = "other-infinispan"
+	assert.True(t, validator.Handle(context.TODO(), request(cache)).Allowed)
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"fmt"
+
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+)
+
+// validateCacheTemplateFormats verifies that the cache configuration is provided in a single format
+func validateCacheTemplateFormats(cache *infinispanv2alpha1.Cache) error {
+	spec := cache.Spec
+	set := 0
+	for _, template := range []string{spec.Template, spec.TemplateXML, spec.TemplateYAML, spec.TemplateJSON} {
+		if template != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("only one of template, templateXML, templateYAML and templateJSON can be provided")
+	}
+	return nil
+}
+
+// cacheCanonicalTemplate returns the canonical JSON form of the spec template. The cluster only converts the template
+// when it changed since the canonical form was stored in the status, so that reformatting the template or switching it
+// to another format doesn't update the cache
+func cacheCanonicalTemplate(cluster ispn.ClusterInterface, cache *infinispanv2alpha1.Cache, podName string) (string, error) {
+	template, mediaType := cache.GetTemplate()
+	if cache.Status.CanonicalTemplate != "" && cache.Status.TemplateHash == hash.HashString(template) {
+		return cache.Status.CanonicalTemplate, nil
+	}
+	canonical, err := cluster.ConvertCacheConfiguration(template, mediaType, podName)
+	if err != nil {
+		return "", fmt.Errorf("unable to convert the cache template to its canonical form: %w", err)
+	}
+	return canonical, nil
+}
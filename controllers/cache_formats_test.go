@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+)
+
+// formatsCluster converts the configurations by removing their whitespaces, recording the media types it was asked
+// to convert
+type formatsCluster struct {
+	ispn.ClusterInterface
+	mediaTypes []string
+}
+
+func (c *formatsCluster) ConvertCacheConfiguration(configuration, mediaType, podName string) (string, error) {
+	c.mediaTypes = append(c.mediaTypes, mediaType)
+	return strings.Join(strings.Fields(configuration), ""), nil
+}
+
+func TestValidateCacheTemplateFormats(t *testing.T) {
+	cache := &v2alpha1.Cache{Spec: v2alpha1.CacheSpec{ClusterName: "example-infinispan"}}
+	assert.NoError(t, validateCacheTemplateFormats(cache))
+	cache.Spec.TemplateYAML = "distributedCache: {}"
+	assert.NoError(t, validateCacheTemplateFormats(cache))
+	cache.Spec.TemplateJSON = `{"distributed-cache":{}}`
+	assert.Error(t, validateCacheTemplateFormats(cache))
+}
+
+func TestCacheCanonicalTemplate(t *testing.T) {
+	cluster := &formatsCluster{}
+	cache := &v2alpha1.Cache{Spec: v2alpha1.CacheSpec{TemplateYAML: "distributedCache:\n  owners: 2"}}
+
+	canonical, err := cacheCanonicalTemplate(cluster, cache, "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, "distributedCache:owners:2", canonical)
+	assert.Equal(t, []string{"application/yaml"}, cluster.mediaTypes)
+
+	// The canonical form of an unchanged template is not converted again
+	cache.Status.TemplateHash = hash.HashString(cache.Spec.TemplateYAML)
+	cache.Status.CanonicalTemplate = canonical
+	canonical, err = cacheCanonicalTemplate(cluster, cache, "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, "distributedCache:owners:2", canonical)
+	assert.Len(t, cluster.mediaTypes, 1)
+
+	// A reformatted template has the same canonical form
+	cache.Spec.TemplateYAML = "distributedCache:\n    owners: 2\n"
+	canonical, err = cacheCanonicalTemplate(cluster, cache, "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, cache.Status.CanonicalTemplate, canonical)
+	assert.Len(t, cluster.mediaTypes, 2)
+
+	// The format of spec.template is detected by the cluster
+	cache.Spec.TemplateYAML = ""
+	cache.Spec.Template = `<distributed-cache owners="2"/>`
+	_, err = cacheCanonicalTemplate(cluster, cache, "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, "", cluster.mediaTypes[2])
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-logr/logr"
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/security"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// RemoteStoreNamespacesAnnotation lists, comma separated, the namespaces of the Caches allowed to use the Infinispan
+	// cluster as remote store. Caches of the namespace of the cluster are always allowed
+	RemoteStoreNamespacesAnnotation = "infinispan.org/remote-store-namespaces"
+	// CacheRemoteStoreClusterField is the Cache field indexed to lookup the Caches using an Infinispan cluster as remote store
+	CacheRemoteStoreClusterField = "spec.remoteStore.cluster"
+)
+
+const remoteStoreXML = `<persistence>
+	<remote-store xmlns="urn:infinispan:config:store:remote:13.0" cache="%s" raw-values="true" shared="true" segmented="false">
+		<remote-server host="%s" port="%d"/>%s
+	</remote-store>
+</persistence>`
+
+const remoteStoreAuthenticationXML = `
+		<security>
+			<authentication server-name="infinispan">
+				<digest username="%s" password="%s" realm="default"/>
+			</authentication>
+		</security>`
+
+// cacheElementEnd and emptyCacheElement match the end of the cache element of a cache configuration in XML format
+var (
+	cacheElementEnd   = regexp.MustCompile(`</(distributed|replicated|invalidation|local)-cache>`)
+	emptyCacheElement = regexp.MustCompile(`<((distributed|replicated|invalidation|local)-cache)\b([^>]*)/>`)
+)
+
+// remoteStoreTarget holds the connection details of the remote cache
+type remoteStoreTarget struct {
+	cacheName string
+	host      string
+	port      int
+	username  string
+	password  string
+}
+
+// validateCacheRemoteStore verifies that the remote store can be added to the cache configuration, which must be
+// provided in XML format or generated by the operator
+func validateCacheRemoteStore(cache *infinispanv2alpha1.Cache) error {
+	spec := cache.Spec
+	if spec.RemoteStore == nil {
+		return nil
+	}
+	if spec.RemoteStore.ClusterName == "" {
+		return fmt.Errorf("remoteStore.clusterName must be provided")
+	}
+	if spec.TemplateName != "" || spec.TemplateRef != "" {
+		return fmt.Errorf("remoteStore cannot be combined with templateName or templateRef")
+	}
+	if spec.TemplateYAML != "" || spec.TemplateJSON != "" {
+		return fmt.Errorf("remoteStore can only be combined with a template in XML format")
+	}
+	if template, _ := cache.GetTemplate(); strings.Contains(template, "<persistence") {
+		return fmt.Errorf("remoteStore cannot be combined with a template that configures persistence")
+	}
+	return nil
+}
+
+// remoteStoreTargetFor resolves the service and the credentials of the Infinispan cluster referenced by the remote store
+func (r *CacheReconciler) remoteStoreTargetFor(ctx context.Context, cache *infinispanv2alpha1.Cache) (*remoteStoreTarget, error) {
+	spec := cache.Spec.RemoteStore
+	namespace := spec.Namespace
+	if namespace == "" {
+		namespace = cache.Namespace
+	}
+	remote := &infinispanv1.Infinispan{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.ClusterName}, remote); err != nil {
+		return nil, fmt.Errorf("unable to fetch remoteStore Infinispan cluster %s/%s: %w", namespace, spec.ClusterName, err)
+	}
+	if !allowsRemoteStoreNamespace(remote, cache.Namespace) {
+		return nil, fmt.Errorf("remoteStore Infinispan cluster %s/%s doesn't allow the Caches of namespace %s, add it to its %s annotation",
+			namespace, spec.ClusterName, cache.Namespace, RemoteStoreNamespacesAnnotation)
+	}
+	if remote.IsEncryptionEnabled() {
+		return nil, fmt.Errorf("remoteStore Infinispan cluster %s/%s has endpoint encryption enabled, which is not supported", namespace, spec.ClusterName)
+	}
+
+	target := &remoteStoreTarget{
+		cacheName: spec.CacheName,
+		host:      fmt.Sprintf("%s.%s.svc", remote.GetServiceName(), namespace),
+		port:      consts.InfinispanUserPort,
+	}
+	if target.cacheName == "" {
+		target.cacheName = cache.GetCacheName()
+	}
+	if !remote.IsAuthenticationEnabled() {
+		return target, nil
+	}
+
+	if remote.GetEndpointSecretSource() == infinispanv1.EndpointSecretSourceVault {
+		return nil, fmt.Errorf("remoteStore Infinispan cluster %s/%s identities are not held in a Secret", namespace, spec.ClusterName)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: remote.GetSecretName()}, secret); err != nil {
+		return nil, fmt.Errorf("unable to fetch the identities of remoteStore Infinispan cluster %s/%s: %w", namespace, spec.ClusterName, err)
+	}
+	identities, err := security.ParseIdentities(secret.Data[consts.ServerIdentitiesFilename])
+	if err != nil {
+		return nil, err
+	}
+	for _, credentials := range identities.Credentials {
+		if spec.Username == "" || spec.Username == credentials.Username {
+			target.username = credentials.Username
+			target.password = credentials.Password
+			return target, nil
+		}
+	}
+	return nil, fmt.Errorf("user '%s' not found in the identities of remoteStore Infinispan cluster %s/%s", spec.Username, namespace, spec.ClusterName)
+}
+
+// allowsRemoteStoreNamespace returns whether the Caches of the namespace can use the Infinispan cluster as remote
+// store, which gives them the credentials of the cluster
+func allowsRemoteStoreNamespace(i *infinispanv1.Infinispan, namespace string) bool {
+	if i.Namespace == namespace {
+		return true
+	}
+	for _, allowed := range strings.Split(i.Annotations[RemoteStoreNamespacesAnnotation], ",") {
+		if strings.TrimSpace(allowed) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteStoreCluster returns the namespaced name of the Infinispan cluster used as remote store by the cache, the value
+// of the CacheRemoteStoreClusterField index
+func remoteStoreCluster(cache *infinispanv2alpha1.Cache) string {
+	spec := cache.Spec.RemoteStore
+	if spec == nil {
+		return ""
+	}
+	namespace := spec.Namespace
+	if namespace == "" {
+		namespace = cache.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: spec.ClusterName}.String()
+}
+
+// remoteStoreCacheRequests returns the requests of the Caches using the Infinispan cluster as remote store
+func (r *CacheReconciler) remoteStoreCacheRequests(ctx context.Context, i *infinispanv1.Infinispan) []reconcile.Request {
+	var requests []reconcile.Request
+	cacheList := &infinispanv2alpha1.CacheList{}
+	if err := r.kubernetes.ResourcesListByField("", CacheRemoteStoreClusterField, types.NamespacedName{Namespace: i.Namespace, Name: i.Name}.String(), cacheList, ctx); err != nil {
+		r.log.Error(err, "failed to list Cache CR")
+	}
+	for _, item := range cacheList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}})
+	}
+	return requests
+}
+
+// identitiesCacheRequests returns the requests of the Caches using as remote store an Infinispan cluster whose
+// identities are held in the Secret, so that the remote stores are updated when the credentials are rotated
+func (r *CacheReconciler) identitiesCacheRequests(ctx context.Context, secret client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	infinispanList := &infinispanv1.InfinispanList{}
+	if err := r.Client.List(ctx, infinispanList, client.InNamespace(secret.GetNamespace())); err != nil {
+		r.log.Error(err, "failed to list Infinispan CR")
+	}
+	for i := range infinispanList.Items {
+		if infinispanList.Items[i].GetSecretName() == secret.GetName() {
+			requests = append(requests, r.remoteStoreCacheRequests(ctx, &infinispanList.Items[i])...)
+		}
+	}
+	return requests
+}
+
+// reconcileRemoteStore recreates the existing cache when its remote store configuration changed since it was created,
+// as the credentials of the remote cluster are rotated, and spec.updates.strategy allows it. Returns the hash of the
+// remote store configuration of the live cache, whether it was recreated and whether it requires to be recreated
+func (r *CacheReconciler) reconcileRemoteStore(ctx context.Context, cluster ispn.ClusterInterface, ispnInstance *infinispanv1.Infinispan, cache *infinispanv2alpha1.Cache, podName string, logger logr.Logger) (string, bool, bool, error) {
+	target, err := r.remoteStoreTargetFor(ctx, cache)
+	if err != nil {
+		return "", false, false, err
+	}
+	persistence := remoteStorePersistence(target)
+	storeHash := remoteStoreConfigHash(cache, persistence)
+	if cache.Status.RemoteStoreHash == "" || cache.Status.RemoteStoreHash == storeHash {
+		return storeHash, false, false, nil
+	}
+	if cache.GetUpdateStrategy() != infinispanv2alpha1.CacheUpdateRecreate {
+		return cache.Status.RemoteStoreHash, false, true, nil
+	}
+
+	cacheXML, err := cacheTemplateXML(cluster, ispnInstance, cache, podName, logger)
+	if err == nil {
+		cacheXML, err = addCachePersistence(cacheXML, persistence)
+	}
+	if err != nil {
+		return "", false, false, err
+	}
+	if err := cluster.DeleteCache(cache.GetCacheName(), podName); err != nil {
+		return "", false, false, err
+	}
+	if err := cluster.CreateCacheWithTemplate(cache.GetCacheName(), cacheXML, podName); err != nil {
+		return "", false, false, err
+	}
+	return storeHash, true, false, nil
+}
+
+// remoteStoreConfigHash returns the hash of the remote store configuration and of the spec.template of the cache
+func remoteStoreConfigHash(cache *infinispanv2alpha1.Cache, persistence string) string {
+	template, _ := cache.GetTemplate()
+	return hash.HashString(template + persistence)
+}
+
+// remoteStorePersistence returns the persistence configuration, in XML format, of the remote store
+func remoteStorePersistence(target *remoteStoreTarget) string {
+	authentication := ""
+	if target.username != "" {
+		authentication = fmt.Sprintf(remoteStoreAuthenticationXML, escapeXMLAttr(target.username), escapeXMLAttr(target.password))
+	}
+	return fmt.Sprintf(remoteStoreXML, escapeXMLAttr(target.cacheName), target.host, target.port, authentication)
+}
+
+// addCachePersistence adds the persistence configuration to the cache element of an XML configuration
+func addCachePersistence(cacheXML, persistence string) (string, error) {
+	if loc := cacheElementEnd.FindStringIndex(cacheXML); loc != nil {
+		return cacheXML[:loc[0]] + persistence + "\n" + cacheXML[loc[0]:], nil
+	}
+	if loc := emptyCacheElement.FindStringSubmatchIndex(cacheXML); loc != nil {
+		element := cacheXML[loc[2]:loc[3]]
+		attributes := cacheXML[loc[6]:loc[7]]
+		return fmt.Sprintf("%s<%s%s>\n%s\n</%s>%s", cacheXML[:loc[0]], element, attributes, persistence, element, cacheXML[loc[1]:]), nil
+	}
+	return "", fmt.Errorf("unable to add the remote store, the cache template doesn't contain a cache element")
+}
+
+func escapeXMLAttr(value string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/security"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func remoteStoreCache(remoteStore *infinispanv2alpha1.CacheRemoteStoreSpec) *infinispanv2alpha1.Cache {
+	return &infinispanv2alpha1.Cache{
+		ObjectMeta: metav1.ObjectMeta{Name: "offloaded", Namespace: "apps"},
+		Spec:       infinispanv2alpha1.CacheSpec{ClusterName: "example-infinispan", RemoteStore: remoteStore},
+	}
+}
+
+func remoteStoreReconciler(t *testing.T, objs ...runtime.Object) *CacheReconciler {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, infinispanv1.AddToScheme(scheme))
+	return &CacheReconciler{Client: fake.NewFakeClientWithScheme(scheme, objs...), scheme: scheme}
+}
+
+// remoteStoreInfinispan returns the remote cluster, in the storage namespace, and its identities Secret
+func remoteStoreInfinispan(password string) (*infinispanv1.Infinispan, *corev1.Secret) {
+	remote := &infinispanv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "remote", Namespace: "storage"}}
+	remote.Spec.Security.EndpointSecretName = "remote-identities"
+	identities, _ := security.CreateIdentitiesFor(consts.DefaultDeveloperUser, password)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "remote-identities", Namespace: "storage"},
+		Data:       map[string][]byte{consts.ServerIdentitiesFilename: identities},
+	}
+	return remote, secret
+}
+
+// offloadingCluster records the configuration of the caches created with a template
+type offloadingCluster struct {
+	templateCluster
+	configurations map[string]string
+}
+
+func (c *offloadingCluster) CreateCacheWithTemplate(cacheName, template, podName string) error {
+	c.calls = append(c.calls, "create "+cacheName)
+	c.configurations[cacheName] = template
+	return nil
+}
+
+func TestValidateCacheRemoteStore(t *testing.T) {
+	assert.NoError(t, validateCacheRemoteStore(remoteStoreCache(nil)))
+	cache := remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote"})
+	assert.NoError(t, validateCacheRemoteStore(cache))
+	cache.Spec.Template = `<distributed-cache name="offloaded"/>`
+	assert.NoError(t, validateCacheRemoteStore(cache))
+
+	cache.Spec.Template = `<distributed-cache name="offloaded"><persistence/></distributed-cache>`
+	assert.Error(t, validateCacheRemoteStore(cache))
+	cache.Spec.Template = ""
+	cache.Spec.TemplateRef = "template"
+	assert.Error(t, validateCacheRemoteStore(cache))
+	cache.Spec.TemplateRef = ""
+	cache.Spec.TemplateName = "org.infinispan.DIST_SYNC"
+	assert.Error(t, validateCacheRemoteStore(cache))
+	assert.Error(t, validateCacheRemoteStore(remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{})))
+}
+
+func TestRemoteStoreTargetFor(t *testing.T) {
+	remote, secret := remoteStoreInfinispan("password")
+	r := remoteStoreReconciler(t, remote, secret)
+
+	// The Caches of another namespace must be allowed by the remote cluster
+	_, err := r.remoteStoreTargetFor(context.TODO(), remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote", Namespace: "storage"}))
+	assert.EqualError(t, err, "remoteStore Infinispan cluster storage/remote doesn't allow the Caches of namespace apps, add it to its infinispan.org/remote-store-namespaces annotation")
+	remote.Annotations = map[string]string{RemoteStoreNamespacesAnnotation: "other, apps"}
+	r = remoteStoreReconciler(t, remote, secret)
+
+	target, err := r.remoteStoreTargetFor(context.TODO(), remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote", Namespace: "storage"}))
+	assert.NoError(t, err)
+	assert.Equal(t, &remoteStoreTarget{cacheName: "offloaded", host: "remote.storage.svc", port: 11222, username: "developer", password: "password"}, target)
+
+	target, err = r.remoteStoreTargetFor(context.TODO(), remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote", Namespace: "storage", CacheName: "backing"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "backing", target.cacheName)
+
+	_, err = r.remoteStoreTargetFor(context.TODO(), remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote", Namespace: "storage", Username: "unknown"}))
+	assert.Error(t, err)
+	// The Infinispan cluster defaults to the namespace of the Cache
+	_, err = r.remoteStoreTargetFor(context.TODO(), remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote"}))
+	assert.Error(t, err)
+
+	remote.Spec.Security.EndpointAuthentication = pointer.BoolPtr(false)
+	r = remoteStoreReconciler(t, remote)
+	target, err = r.remoteStoreTargetFor(context.TODO(), remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote", Namespace: "storage"}))
+	assert.NoError(t, err)
+	assert.Empty(t, target.username)
+}
+
+func TestAddCachePersistence(t *testing.T) {
+	persistence := remoteStorePersistence(&remoteStoreTarget{cacheName: "offloaded", host: "remote.storage.svc", port: 11222, username: "developer", password: `pass"&<`})
+	assert.Contains(t, persistence, `<remote-server host="remote.storage.svc" port="11222"/>`)
+	assert.Contains(t, persistence, `<digest username="developer" password="pass&#34;&amp;&lt;" realm="default"/>`)
+	assert.NotContains(t, remoteStorePersistence(&remoteStoreTarget{cacheName: "offloaded", host: "remote.storage.svc", port: 11222}), "<security>")
+
+	cacheXML, err := addCachePersistence(`<infinispan><cache-container><distributed-cache name="offloaded"></distributed-cache></cache-container></infinispan>`, "<persistence/>")
+	assert.NoError(t, err)
+	assert.Equal(t, "<infinispan><cache-container><distributed-cache name=\"offloaded\"><persistence/>\n</distributed-cache></cache-container></infinispan>", cacheXML)
+
+	cacheXML, err = addCachePersistence(`<replicated-cache name="offloaded" statistics="true"/>`, "<persistence/>")
+	assert.NoError(t, err)
+	assert.Equal(t, "<replicated-cache name=\"offloaded\" statistics=\"true\">\n<persistence/>\n</replicated-cache>", cacheXML)
+
+	_, err = addCachePersistence(`<cache-container/>`, "<persistence/>")
+	assert.Error(t, err)
+}
+
+func TestReconcileRemoteStore(t *testing.T) {
+	remote, secret := remoteStoreInfinispan("password")
+	cache := remoteStoreCache(&infinispanv2alpha1.CacheRemoteStoreSpec{ClusterName: "remote"})
+	cache.Namespace = "storage"
+	cache.Spec.Template = `<distributed-cache name="offloaded"/>`
+	cluster := &offloadingCluster{configurations: map[string]string{}}
+
+	// The hash of the remote store is recorded for the caches created before it was tracked
+	r := remoteStoreReconciler(t, remote, secret)
+	storeHash, recreated, requiresRecreate, err := r.reconcileRemoteStore(context.TODO(), cluster, remote, cache, "pod", logf.Log)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, storeHash)
+	assert.False(t, recreated)
+	assert.False(t, requiresRecreate)
+	cache.Status.RemoteStoreHash = storeHash
+
+	// The rotated credentials can only be applied by recreating the cache
+	remote, secret = remoteStoreInfinispan("rotated")
+	r = remoteStoreReconciler(t, remote, secret)
+	retainedHash, recreated, requiresRecreate, err := r.reconcileRemoteStore(context.TODO(), cluster, remote, cache, "pod", logf.Log)
+	assert.NoError(t, err)
+	assert.Equal(t, storeHash, retainedHash)
+	assert.False(t, recreated)
+	assert.True(t, requiresRecreate)
+	assert.Nil(t, cluster.calls)
+
+	cache.Spec.Updates = &infinispanv2alpha1.CacheUpdateSpec{Strategy: infinispanv2alpha1.CacheUpdateRecreate}
+	rotatedHash, recreated, requiresRecreate, err := r.reconcileRemoteStore(context.TODO(), cluster, remote, cache, "pod", logf.Log)
+	assert.NoError(t, err)
+	assert.NotEqual(t, storeHash, rotatedHash)
+	assert.True(t, recreated)
+	assert.False(t, requiresRecreate)
+	assert.Equal(t, []string{"delete offloaded", "create offloaded"}, cluster.calls)
+	assert.Contains(t, cluster.configurations["offloaded"], `password="rotated"`)
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"fmt"
+
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+)
+
+const (
+	EventReasonCacheTemplateApplied     = "CacheTemplateApplied"
+	EventReasonCacheTemplateApplyFailed = "CacheTemplateApplyFailed"
+
+	// CacheTemplateRefField is the Cache field indexed to lookup the Caches referencing a CacheTemplate
+	CacheTemplateRefField = "spec.templateRef"
+)
+
+// validateCacheTemplateRef verifies that a templateRef is the only source of the cache configuration
+func validateCacheTemplateRef(cache *infinispanv2alpha1.Cache) error {
+	spec := cache.Spec
+	if spec.TemplateRef == "" {
+		return nil
+	}
+	if template, _ := cache.GetTemplate(); template != "" || spec.TemplateName != "" {
+		return fmt.Errorf("templateRef cannot be combined with template, templateXML, templateYAML, templateJSON or templateName")
+	}
+	if spec.Indexing != nil && len(spec.Indexing.IndexedEntities) > 0 {
+		return fmt.Errorf("indexing.indexedEntities cannot be combined with templateRef, configure indexing in the CacheTemplate instead")
+	}
+	return nil
+}
+
+// cacheTemplateUpdateAction returns how an existing cache, last configured with the template hash `appliedHash`,
+// must be updated to the current template configuration
+func cacheTemplateUpdateAction(template *infinispanv2alpha1.CacheTemplate, appliedHash string) infinispanv2alpha1.CacheTemplateUpdatePolicy {
+	if appliedHash == "" || appliedHash == hash.HashString(template.Spec.Template) || template.Spec.UpdatePolicy == "" {
+		return infinispanv2alpha1.CacheTemplateUpdateNone
+	}
+	return template.Spec.UpdatePolicy
+}
+
+// applyCacheTemplateUpdate applies the template configuration to the existing cache according to `action`
+func applyCacheTemplateUpdate(cluster ispn.ClusterInterface, cacheName string, template *infinispanv2alpha1.CacheTemplate, action infinispanv2alpha1.CacheTemplateUpdatePolicy, podName string) error {
+	switch action {
+	case infinispanv2alpha1.CacheTemplateUpdateUpdate:
+		return cluster.UpdateCacheConfiguration(cacheName, template.Spec.Template, podName)
+	case infinispanv2alpha1.CacheTemplateUpdateRecreate:
+		if err := cluster.DeleteCache(cacheName, podName); err != nil {
+			return err
+		}
+		return cluster.CreateCacheWithConfiguration(cacheName, template.Spec.Template, podName)
+	}
+	return nil
+}
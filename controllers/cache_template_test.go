@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+)
+
+// templateCluster records the cache operations, the remaining methods are not expected to be called
+type templateCluster struct {
+	ispn.ClusterInterface
+	calls []string
+}
+
+func (c *templateCluster) CreateCacheWithConfiguration(cacheName, configuration, podName string) error {
+	c.calls = append(c.calls, "create "+cacheName)
+	return nil
+}
+
+func (c *templateCluster) UpdateCacheConfiguration(cacheName, configuration, podName string) error {
+	c.calls = append(c.calls, "update "+cacheName)
+	return nil
+}
+
+func (c *templateCluster) DeleteCache(cacheName, podName string) error {
+	c.calls = append(c.calls, "delete "+cacheName)
+	return nil
+}
+
+func cacheTemplate(policy v2alpha1.CacheTemplateUpdatePolicy) *v2alpha1.CacheTemplate {
+	return &v2alpha1.CacheTemplate{
+		Spec: v2alpha1.CacheTemplateSpec{
+			Template:     `distributedCache: {mode: "SYNC"}`,
+			UpdatePolicy: policy,
+		},
+	}
+}
+
+func TestValidateCacheTemplateRef(t *testing.T) {
+	cache := &v2alpha1.Cache{Spec: v2alpha1.CacheSpec{TemplateName: "org.infinispan.DIST_SYNC"}}
+	assert.NoError(t, validateCacheTemplateRef(cache))
+
+	cache.Spec.TemplateRef = "template"
+	assert.Error(t, validateCacheTemplateRef(cache))
+
+	cache.Spec.TemplateName = ""
+	assert.NoError(t, validateCacheTemplateRef(cache))
+
+	cache.Spec.Indexing = &v2alpha1.CacheIndexingSpec{IndexedEntities: []string{"book_sample.Book"}}
+	assert.Error(t, validateCacheTemplateRef(cache))
+}
+
+func TestCacheTemplateUpdateAction(t *testing.T) {
+	template := cacheTemplate(v2alpha1.CacheTemplateUpdateRecreate)
+	current := hash.HashString(template.Spec.Template)
+
+	// Caches without a recorded hash, or already up to date, are left untouched
+	assert.Equal(t, v2alpha1.CacheTemplateUpdateNone, cacheTemplateUpdateAction(template, ""))
+	assert.Equal(t, v2alpha1.CacheTemplateUpdateNone, cacheTemplateUpdateAction(template, current))
+	assert.Equal(t, v2alpha1.CacheTemplateUpdateRecreate, cacheTemplateUpdateAction(template, "outdated"))
+
+	assert.Equal(t, v2alpha1.CacheTemplateUpdateNone, cacheTemplateUpdateAction(cacheTemplate(""), "outdated"))
+	assert.Equal(t, v2alpha1.CacheTemplateUpdateUpdate, cacheTemplateUpdateAction(cacheTemplate(v2alpha1.CacheTemplateUpdateUpdate), "outdated"))
+}
+
+func TestApplyCacheTemplateUpdate(t *testing.T) {
+	for policy, calls := range map[v2alpha1.CacheTemplateUpdatePolicy][]string{
+		v2alpha1.CacheTemplateUpdateNone:     nil,
+		v2alpha1.CacheTemplateUpdateUpdate:   {"update mycache"},
+		v2alpha1.CacheTemplateUpdateRecreate: {"delete mycache", "create mycache"},
+	} {
+		cluster := &templateCluster{}
+		assert.NoError(t, applyCacheTemplateUpdate(cluster, "mycache", cacheTemplate(policy), policy, "pod"))
+		assert.Equal(t, calls, cluster.calls, policy)
+	}
+}
+
+func TestCacheConfigurationContentType(t *testing.T) {
+	assert.Equal(t, "application/xml", ispn.CacheConfigurationContentType(` <distributed-cache mode="SYNC"/>`))
+	assert.Equal(t, "application/json", ispn.CacheConfigurationContentType(`{"distributed-cache": {"mode": "SYNC"}}`))
+	assert.Equal(t, "application/yaml", ispn.CacheConfigurationContentType(`distributedCache: {mode: "SYNC"}`))
+}
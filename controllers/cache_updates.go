@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"fmt"
+
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	EventReasonCacheUpdated          = "CacheUpdated"
+	EventReasonCacheRecreated        = "CacheRecreated"
+	EventReasonCacheRequiresRecreate = "CacheRequiresRecreate"
+	EventReasonCacheUpdateFailed     = "CacheUpdateFailed"
+	EventReasonCacheDrifted          = "CacheDrifted"
+	EventReasonCacheDriftReverted    = "CacheDriftReverted"
+)
+
+// cacheUpdateAction is how an existing cache is updated to match its spec.template
+type cacheUpdateAction string
+
+const (
+	// cacheUpdateNone means that the cache already matches the template
+	cacheUpdateNone cacheUpdateAction = "none"
+	// cacheUpdateInPlace means that only attributes that can be changed at runtime differ
+	cacheUpdateInPlace cacheUpdateAction = "update"
+	// cacheUpdateRecreate means that the cache must be recreated, as requested by spec.updates.strategy
+	cacheUpdateRecreate cacheUpdateAction = "recreate"
+	// cacheUpdateRequiresRecreate means that the cache can only be updated by recreating it, which isn't allowed
+	cacheUpdateRequiresRecreate cacheUpdateAction = "requiresRecreate"
+)
+
+// cacheTemplateUpdate compares the template of the cache with the configuration of the live cache, using the
+// server comparison so that the attributes defaulted by the server aren't reported as changes
+func cacheTemplateUpdate(cluster ispn.ClusterInterface, cache *infinispanv2alpha1.Cache, template, podName string) (cacheUpdateAction, error) {
+	live, err := cluster.GetCacheConfiguration(cache.GetCacheName(), podName)
+	if err != nil {
+		return "", err
+	}
+	if equal, err := cluster.CompareCacheConfigurations(template, live, false, podName); err != nil || equal {
+		return cacheUpdateNone, err
+	}
+	if compatible, err := cluster.CompareCacheConfigurations(template, live, true, podName); err != nil || compatible {
+		return cacheUpdateInPlace, err
+	}
+	if cache.GetUpdateStrategy() == infinispanv2alpha1.CacheUpdateRecreate {
+		return cacheUpdateRecreate, nil
+	}
+	return cacheUpdateRequiresRecreate, nil
+}
+
+// applyCacheTemplate updates the cache to match its template according to `action`
+func applyCacheTemplate(cluster ispn.ClusterInterface, cache *infinispanv2alpha1.Cache, template string, action cacheUpdateAction, podName string) error {
+	switch action {
+	case cacheUpdateInPlace:
+		return cluster.UpdateCacheConfiguration(cache.GetCacheName(), template, podName)
+	case cacheUpdateRecreate:
+		if err := cluster.DeleteCache(cache.GetCacheName(), podName); err != nil {
+			return err
+		}
+		return cluster.CreateCacheWithConfiguration(cache.GetCacheName(), template, podName)
+	}
+	return nil
+}
+
+// reconcileCacheDrift compares the live configuration of the cache with `template`, the configuration it was last
+// created or updated with, and reverts the out-of-band changes unless the drift policy only reports them. Returns
+// whether the live configuration still differs from the template
+func (r *CacheReconciler) reconcileCacheDrift(cluster ispn.ClusterInterface, cache *infinispanv2alpha1.Cache, template, podName string) (bool, error) {
+	action, err := cacheTemplateUpdate(cluster, cache, template, podName)
+	if err != nil || action == cacheUpdateNone {
+		return false, err
+	}
+	if cache.GetDriftPolicy() == infinispanv2alpha1.CacheDriftReport || action == cacheUpdateRequiresRecreate {
+		if !cache.Status.Drifted {
+			message := "The live cache configuration differs from the configuration the cache was created or updated with"
+			if action == cacheUpdateRequiresRecreate && cache.GetDriftPolicy() == infinispanv2alpha1.CacheDriftRevert {
+				message += ", set spec.updates.strategy=recreate to discard the cache entries and revert the changes"
+			}
+			r.eventRec.Event(cache, corev1.EventTypeWarning, EventReasonCacheDrifted, message)
+		}
+		return true, nil
+	}
+	if err := applyCacheTemplate(cluster, cache, template, action, podName); err != nil {
+		r.eventRec.Event(cache, corev1.EventTypeWarning, EventReasonCacheUpdateFailed, err.Error())
+		return true, err
+	}
+	r.eventRec.Event(cache, corev1.EventTypeNormal, EventReasonCacheDriftReverted, fmt.Sprintf("Reverted the out-of-band changes to the live cache configuration with action %s", action))
+	return false, nil
+}
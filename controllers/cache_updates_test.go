@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+// updatesCluster compares configurations by their mutable and immutable parts, the live configuration is the last one
+// applied to the cache
+type updatesCluster struct {
+	templateCluster
+	live      string
+	immutable map[string]string
+}
+
+func (c *updatesCluster) GetCacheConfiguration(cacheName, podName string) (string, error) {
+	return c.live, nil
+}
+
+func (c *updatesCluster) CompareCacheConfigurations(configuration, other string, ignoreMutable bool, podName string) (bool, error) {
+	if ignoreMutable {
+		return c.immutable[configuration] == c.immutable[other], nil
+	}
+	return configuration == other, nil
+}
+
+func updatesCache(template string, strategy v2alpha1.CacheUpdateStrategyType) *v2alpha1.Cache {
+	cache := &v2alpha1.Cache{Spec: v2alpha1.CacheSpec{Name: "mycache", Template: template}}
+	if strategy != "" {
+		cache.Spec.Updates = &v2alpha1.CacheUpdateSpec{Strategy: strategy}
+	}
+	return cache
+}
+
+func TestCacheTemplateUpdate(t *testing.T) {
+	cluster := &updatesCluster{
+		live: "owners=2,lifespan=10",
+		immutable: map[string]string{
+			"owners=2,lifespan=10": "owners=2",
+			"owners=2,lifespan=20": "owners=2",
+			"owners=3,lifespan=10": "owners=3",
+		},
+	}
+
+	action, err := cacheTemplateUpdate(cluster, updatesCache("owners=2,lifespan=10", ""), "owners=2,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, cacheUpdateNone, action)
+
+	action, err = cacheTemplateUpdate(cluster, updatesCache("owners=2,lifespan=20", ""), "owners=2,lifespan=20", "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, cacheUpdateInPlace, action)
+
+	// Immutable changes are only applied when the strategy allows recreating the cache
+	action, err = cacheTemplateUpdate(cluster, updatesCache("owners=3,lifespan=10", ""), "owners=3,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, cacheUpdateRequiresRecreate, action)
+
+	action, err = cacheTemplateUpdate(cluster, updatesCache("owners=3,lifespan=10", v2alpha1.CacheUpdateRetain), "owners=3,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, cacheUpdateRequiresRecreate, action)
+
+	action, err = cacheTemplateUpdate(cluster, updatesCache("owners=3,lifespan=10", v2alpha1.CacheUpdateRecreate), "owners=3,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, cacheUpdateRecreate, action)
+}
+
+func TestApplyCacheTemplate(t *testing.T) {
+	for action, calls := range map[cacheUpdateAction][]string{
+		cacheUpdateNone:             nil,
+		cacheUpdateRequiresRecreate: nil,
+		cacheUpdateInPlace:          {"update mycache"},
+		cacheUpdateRecreate:         {"delete mycache", "create mycache"},
+	} {
+		cluster := &templateCluster{}
+		assert.NoError(t, applyCacheTemplate(cluster, updatesCache("owners=2", v2alpha1.CacheUpdateRecreate), "owners=2", action, "pod"))
+		assert.Equal(t, calls, cluster.calls, action)
+	}
+}
+
+func TestReconcileCacheDrift(t *testing.T) {
+	immutable := map[string]string{
+		"owners=2,lifespan=10": "owners=2",
+		"owners=2,lifespan=20": "owners=2",
+		"owners=3,lifespan=10": "owners=3",
+	}
+	eventRec := record.NewFakeRecorder(10)
+	r := &CacheReconciler{eventRec: eventRec}
+
+	// No drift when the live configuration matches the template
+	cluster := &updatesCluster{live: "owners=2,lifespan=10", immutable: immutable}
+	drifted, err := r.reconcileCacheDrift(cluster, updatesCache("owners=2,lifespan=10", ""), "owners=2,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.False(t, drifted)
+	assert.Nil(t, cluster.calls)
+
+	// Out-of-band changes are reverted by default
+	cluster = &updatesCluster{live: "owners=2,lifespan=20", immutable: immutable}
+	drifted, err = r.reconcileCacheDrift(cluster, updatesCache("owners=2,lifespan=10", ""), "owners=2,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.False(t, drifted)
+	assert.Equal(t, []string{"update mycache"}, cluster.calls)
+	assert.Contains(t, <-eventRec.Events, EventReasonCacheDriftReverted)
+
+	// Changes that require recreating the cache are only reported with the retain strategy
+	cluster = &updatesCluster{live: "owners=3,lifespan=10", immutable: immutable}
+	drifted, err = r.reconcileCacheDrift(cluster, updatesCache("owners=2,lifespan=10", ""), "owners=2,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.True(t, drifted)
+	assert.Nil(t, cluster.calls)
+	assert.Contains(t, <-eventRec.Events, EventReasonCacheDrifted)
+
+	// The Report policy never changes the cache, and reports the drift once
+	cache := updatesCache("owners=2,lifespan=10", v2alpha1.CacheUpdateRecreate)
+	cache.Spec.Updates.DriftPolicy = v2alpha1.CacheDriftReport
+	drifted, err = r.reconcileCacheDrift(cluster, cache, "owners=2,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.True(t, drifted)
+	assert.Nil(t, cluster.calls)
+	assert.Contains(t, <-eventRec.Events, EventReasonCacheDrifted)
+
+	cache.Status.Drifted = true
+	drifted, err = r.reconcileCacheDrift(cluster, cache, "owners=2,lifespan=10", "pod")
+	assert.NoError(t, err)
+	assert.True(t, drifted)
+	assert.Empty(t, eventRec.Events)
+}
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	v1 "github.com/infinispan/infinispan-operator/api/v1"
+	v2 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	CacheLoadCommand            = "cache-load"
+	CacheLoadProgressMarker     = "### CACHE LOAD PROGRESS: "
+	CacheLoadErrorMarker        = "### CACHE LOAD ERROR: "
+	CacheLoadUsernameEnv        = "ADMIN_USERNAME"
+	CacheLoadPasswordEnv        = "ADMIN_PASSWORD"
+	CacheLoadDataVolumeName     = "cache-load-data"
+	CacheLoadDataMountPath      = "/opt/infinispan/cache-load"
+	CacheLoadJobNameTemplate    = "%s-load"
+	DefaultCacheLoadConcurrency = 10
+	// CacheLoadRefresh delay between refreshes of the progress of the load while the job is running
+	CacheLoadRefresh = 5 * time.Second
+	// cacheLoadStatusErrors bounds the number of errors reported in the CacheLoadJob status
+	cacheLoadStatusErrors = 10
+)
+
+// CacheLoadJobReconciler reconciles a CacheLoadJob object
+type CacheLoadJobReconciler struct {
+	client.Client
+	log        logr.Logger
+	scheme     *runtime.Scheme
+	kubernetes *kube.Kubernetes
+	eventRec   record.EventRecorder
+}
+
+// Struct for wrapping reconcile request data
+type cacheLoadJobRequest struct {
+	*CacheLoadJobReconciler
+	ctx       context.Context
+	instance  *v2.CacheLoadJob
+	reqLogger logr.Logger
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CacheLoadJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Client = mgr.GetClient()
+	r.log = ctrl.Log.WithName("controllers").WithName("CacheLoadJob")
+	r.scheme = mgr.GetScheme()
+	r.kubernetes = kube.NewKubernetesFromController(mgr)
+	r.eventRec = mgr.GetEventRecorderFor("cacheloadjob-controller")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v2.CacheLoadJob{}).Owns(&batchv1.Job{}).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infinispan.org,resources=cacheloadjobs;cacheloadjobs/status;cacheloadjobs/finalizers,verbs=get;list;watch;create;update;patch
+
+func (reconciler *CacheLoadJobReconciler) Reconcile(ctx context.Context, ctrlRequest ctrl.Request) (ctrl.Result, error) {
+	reqLogger := reconciler.log.WithValues("Request.Namespace", ctrlRequest.Namespace, "Request.Name", ctrlRequest.Name)
+	reqLogger.Info("Reconciling CacheLoadJob")
+
+	instance := &v2.CacheLoadJob{}
+	if err := reconciler.Get(ctx, ctrlRequest.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	r := &cacheLoadJobRequest{
+		CacheLoadJobReconciler: reconciler,
+		ctx:                    ctx,
+		instance:               instance,
+		reqLogger:              reqLogger,
+	}
+
+	switch instance.Status.Phase {
+	case "":
+		if err := validateCacheLoadJob(&instance.Spec); err != nil {
+			return reconcile.Result{}, r.updatePhase(v2.CacheLoadJobFailed, err.Error())
+		}
+		return reconcile.Result{}, r.updatePhase(v2.CacheLoadJobInitializing, "")
+	case v2.CacheLoadJobInitializing:
+		return r.execute()
+	case v2.CacheLoadJobRunning:
+		return r.waitToComplete()
+	default:
+		// The load either succeeded or failed
+		return reconcile.Result{}, nil
+	}
+}
+
+// validateCacheLoadJob verifies that the data file is read from a single source
+func validateCacheLoadJob(spec *v2.CacheLoadJobSpec) error {
+	source := spec.Source
+	if (source.Volume == nil) == (source.ObjectStorage == nil) {
+		return fmt.Errorf("exactly one of source.volume or source.objectStorage must be configured")
+	}
+	if storage := source.ObjectStorage; storage != nil {
+		configured := 0
+		for _, set := range []bool{storage.S3 != nil, storage.GCS != nil, storage.Azure != nil} {
+			if set {
+				configured++
+			}
+		}
+		if configured != 1 {
+			return fmt.Errorf("exactly one of source.objectStorage.s3, source.objectStorage.gcs or source.objectStorage.azure must be configured")
+		}
+	}
+	return nil
+}
+
+// execute creates the Job loading the data file once the cluster is ready
+func (r *cacheLoadJobRequest) execute() (reconcile.Result, error) {
+	instance := r.instance
+	infinispan := &v1.Infinispan{}
+	if result, err := kube.LookupResource(instance.Spec.Cluster, instance.Namespace, infinispan, instance, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return *result, err
+	}
+	if err := infinispan.EnsureClusterStability(); err != nil {
+		r.reqLogger.Info(fmt.Sprintf("Infinispan '%s' not ready: %s", instance.Spec.Cluster, err.Error()))
+		return reconcile.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+
+	image, err := operatorImage(r.ctx, r.Client)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	job := computeCacheLoadJob(instance, infinispan, image)
+	if err := controllerutil.SetControllerReference(instance, job, r.scheme); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.Client.Create(r.ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, fmt.Errorf("unable to create cache load job '%s': %w", job.Name, err)
+	}
+	return reconcile.Result{}, r.update(func() {
+		instance.Status.ClusterUID = &infinispan.UID
+		instance.Status.Phase = v2.CacheLoadJobRunning
+	})
+}
+
+// CacheLoadDefaultMediaType returns the media type of the values of the data file format
+func CacheLoadDefaultMediaType(format string) string {
+	if format == string(v2.CacheLoadFormatCSV) {
+		return "text/plain"
+	}
+	return "application/json"
+}
+
+// computeCacheLoadJob returns the Job writing the entries of the data file to the cache. Data files in object storage
+// are downloaded to an emptyDir volume by an init container
+func computeCacheLoadJob(instance *v2.CacheLoadJob, infinispan *v1.Infinispan, image string) *batchv1.Job {
+	spec := instance.Spec
+	var file string
+	var initContainers []corev1.Container
+	var volumes []corev1.Volume
+	if source := spec.Source.Volume; source != nil {
+		file = path.Join(CacheLoadDataMountPath, source.Path)
+		volumes = append(volumes, corev1.Volume{
+			Name: CacheLoadDataVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: source.ClaimName,
+					ReadOnly:  true,
+				},
+			},
+		})
+	} else {
+		source := spec.Source.ObjectStorage
+		file = path.Join(CacheLoadDataMountPath, path.Base(source.Key))
+		download, storageVolumes := storageContainer(&source.BackupStorageSpec, source.Key, file, false)
+		download.VolumeMounts = append(download.VolumeMounts, corev1.VolumeMount{
+			Name:      CacheLoadDataVolumeName,
+			MountPath: CacheLoadDataMountPath,
+		})
+		initContainers = append(initContainers, download)
+		volumes = append(storageVolumes, corev1.Volume{
+			Name:         CacheLoadDataVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+
+	concurrency := spec.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultCacheLoadConcurrency
+	}
+	command := []string{
+		"infinispan-operator",
+		CacheLoadCommand,
+		"--service", strings.Join([]string{infinispan.GetAdminHeadlessServiceName(), infinispan.Namespace, "svc"}, "."),
+		"--cache", spec.CacheName,
+		"--format", string(spec.Format),
+		"--file", file,
+		"--value-media-type", consts.GetWithDefault(spec.ValueMediaType, CacheLoadDefaultMediaType(string(spec.Format))),
+		"--concurrency", strconv.Itoa(int(concurrency)),
+		"--max-errors", strconv.Itoa(int(spec.MaxErrors)),
+	}
+	adminSecretKey := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: infinispan.GetAdminSecretName()},
+				Key:                  key,
+			},
+		}
+	}
+
+	labels := CacheLoadJobLabels(instance.Name, spec.Cluster)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(CacheLoadJobNameTemplate, instance.Name),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// Failures are usually caused by the data file, which a retry doesn't fix
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:  corev1.RestartPolicyNever,
+					InitContainers: initContainers,
+					Containers: []corev1.Container{{
+						Name:    "load",
+						Image:   image,
+						Command: command,
+						Env: []corev1.EnvVar{
+							{Name: CacheLoadUsernameEnv, ValueFrom: adminSecretKey(consts.AdminUsernameKey)},
+							{Name: CacheLoadPasswordEnv, ValueFrom: adminSecretKey(consts.AdminPasswordKey)},
+						},
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      CacheLoadDataVolumeName,
+							MountPath: CacheLoadDataMountPath,
+							ReadOnly:  true,
+						}},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+// waitToComplete refreshes the progress of the load from the log of the Job until it completes
+func (r *cacheLoadJobRequest) waitToComplete() (reconcile.Result, error) {
+	instance := r.instance
+	job := &batchv1.Job{}
+	if result, err := kube.LookupResource(fmt.Sprintf(CacheLoadJobNameTemplate, instance.Name), instance.Namespace, job, instance, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return *result, err
+	}
+
+	progress := cacheLoadProgress{}
+	if log, err := r.jobLog(job); err != nil {
+		r.reqLogger.Info("Unable to retrieve the cache load log", "error", err.Error())
+	} else {
+		progress = parseCacheLoadLog(log)
+	}
+
+	phase, reason := v2.CacheLoadJobRunning, ""
+	switch jobState, jobErr := jobPhase(job); jobState {
+	case ZeroSucceeded:
+		phase = v2.CacheLoadJobSucceeded
+	case ZeroFailed:
+		phase, reason = v2.CacheLoadJobFailed, consts.GetWithDefault(progress.reason, jobErr.Error())
+	}
+
+	if err := r.update(func() {
+		if progress.found {
+			instance.Status.Loaded = progress.loaded
+			instance.Status.Failed = progress.failed
+			instance.Status.Errors = progress.errors
+		}
+		instance.Status.Phase = phase
+		instance.Status.Reason = reason
+	}); err != nil {
+		return reconcile.Result{}, err
+	}
+	if phase == v2.CacheLoadJobRunning {
+		return reconcile.Result{RequeueAfter: CacheLoadRefresh}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// jobLog returns the log of the load container of the Job pod
+func (r *cacheLoadJobRequest) jobLog(job *batchv1.Job) (string, error) {
+	podList := &corev1.PodList{}
+	if err := r.kubernetes.ResourcesList(job.Namespace, job.Spec.Template.Labels, podList, r.ctx); err != nil {
+		return "", err
+	}
+	if len(podList.Items) == 0 {
+		return "", fmt.Errorf("no cache load job pods found")
+	}
+	return r.kubernetes.Logs(podList.Items[0].Name, job.Namespace, r.ctx)
+}
+
+type cacheLoadProgress struct {
+	found  bool
+	loaded int64
+	failed int64
+	errors []string
+	// reason is the last line written by the load that is not a marker, the cause of the failure of the load
+	reason string
+}
+
+// parseCacheLoadLog extracts the progress of the load from the log written by the cache-load command
+func parseCacheLoadLog(log string) cacheLoadProgress {
+	progress := cacheLoadProgress{}
+	for _, line := range strings.Split(log, "\n") {
+		switch {
+		case strings.HasPrefix(line, CacheLoadProgressMarker):
+			var loaded, failed int64
+			if _, err := fmt.Sscanf(strings.TrimPrefix(line, CacheLoadProgressMarker), "%d %d", &loaded, &failed); err == nil {
+				progress.found, progress.loaded, progress.failed = true, loaded, failed
+			}
+		case strings.HasPrefix(line, CacheLoadErrorMarker):
+			progress.errors = append(progress.errors, strings.TrimPrefix(line, CacheLoadErrorMarker))
+			if len(progress.errors) > cacheLoadStatusErrors {
+				progress.errors = progress.errors[1:]
+			}
+		case strings.TrimSpace(line) != "":
+			progress.reason = strings.TrimSpace(line)
+		}
+	}
+	return progress
+}
+
+func (r *cacheLoadJobRequest) updatePhase(phase v2.CacheLoadJobPhase, reason string) error {
+	return r.update(func() {
+		r.instance.Status.Phase = phase
+		r.instance.Status.Reason = reason
+	})
+}
+
+func (r *cacheLoadJobRequest) update(mutate func()) error {
+	instance := r.instance
+	_, err := kube.CreateOrPatch(r.ctx, r.Client, instance, func() error {
+		if instance.CreationTimestamp.IsZero() {
+			return errors.NewNotFound(schema.ParseGroupResource("cacheloadjob.infinispan.org"), instance.Name)
+		}
+		mutate()
+		return nil
+	})
+	return err
+}
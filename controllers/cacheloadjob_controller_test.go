@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"testing"
+
+	v1 "github.com/infinispan/infinispan-operator/api/v1"
+	v2 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func cacheLoadJob(source v2.CacheLoadSourceSpec) *v2.CacheLoadJob {
+	return &v2.CacheLoadJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "countries", Namespace: namespace},
+		Spec: v2.CacheLoadJobSpec{
+			Cluster:   "example-infinispan",
+			CacheName: "countries",
+			Format:    v2.CacheLoadFormatCSV,
+			Source:    source,
+		},
+	}
+}
+
+func TestValidateCacheLoadJob(t *testing.T) {
+	volume := &v2.CacheLoadVolumeSource{ClaimName: "reference-data", Path: "countries.csv"}
+	objectStorage := &v2.CacheLoadObjectStorageSource{Key: "countries.csv"}
+	assert.NoError(t, validateCacheLoadJob(&cacheLoadJob(v2.CacheLoadSourceSpec{Volume: volume}).Spec))
+	assert.EqualError(t, validateCacheLoadJob(&cacheLoadJob(v2.CacheLoadSourceSpec{}).Spec), "exactly one of source.volume or source.objectStorage must be configured")
+	assert.EqualError(t, validateCacheLoadJob(&cacheLoadJob(v2.CacheLoadSourceSpec{Volume: volume, ObjectStorage: objectStorage}).Spec), "exactly one of source.volume or source.objectStorage must be configured")
+	assert.EqualError(t, validateCacheLoadJob(&cacheLoadJob(v2.CacheLoadSourceSpec{ObjectStorage: objectStorage}).Spec), "exactly one of source.objectStorage.s3, source.objectStorage.gcs or source.objectStorage.azure must be configured")
+}
+
+func TestComputeCacheLoadJob(t *testing.T) {
+	infinispan := &v1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace}}
+	instance := cacheLoadJob(v2.CacheLoadSourceSpec{Volume: &v2.CacheLoadVolumeSource{ClaimName: "reference-data", Path: "data/countries.csv"}})
+	job := computeCacheLoadJob(instance, infinispan, "quay.io/infinispan/operator")
+
+	assert.Equal(t, "countries-load", job.Name)
+	spec := job.Spec.Template.Spec
+	assert.Empty(t, spec.InitContainers)
+	assert.Equal(t, "reference-data", spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	container := spec.Containers[0]
+	assert.Equal(t, "quay.io/infinispan/operator", container.Image)
+	assert.Equal(t, []string{
+		"infinispan-operator", "cache-load",
+		"--service", "example-infinispan-admin-headless.testing-namespace.svc",
+		"--cache", "countries",
+		"--format", "CSV",
+		"--file", "/opt/infinispan/cache-load/data/countries.csv",
+		"--value-media-type", "text/plain",
+		"--concurrency", "10",
+		"--max-errors", "0",
+	}, container.Command)
+	assert.Equal(t, "example-infinispan-generated-operator-secret", container.Env[1].ValueFrom.SecretKeyRef.Name)
+
+	s3 := &v2.S3StorageSpec{Bucket: "reference", Prefix: "prod", CredentialsSecret: "aws"}
+	instance = cacheLoadJob(v2.CacheLoadSourceSpec{ObjectStorage: &v2.CacheLoadObjectStorageSource{BackupStorageSpec: v2.BackupStorageSpec{S3: s3}, Key: "2021/countries.csv"}})
+	job = computeCacheLoadJob(instance, infinispan, "quay.io/infinispan/operator")
+	spec = job.Spec.Template.Spec
+	assert.Equal(t, []string{"aws", "s3", "cp", "s3://reference/prod/2021/countries.csv", "/opt/infinispan/cache-load/countries.csv"}, spec.InitContainers[0].Command)
+	assert.NotNil(t, spec.Volumes[0].EmptyDir)
+	assert.Contains(t, spec.Containers[0].Command, "/opt/infinispan/cache-load/countries.csv")
+}
+
+func TestParseCacheLoadLog(t *testing.T) {
+	log := `### CACHE LOAD PROGRESS: 1000 0
+### CACHE LOAD ERROR: unable to write key 'xx': status 400
+### CACHE LOAD PROGRESS: 1500 1
+### CACHE LOAD PROGRESS: 1700 1
+unable to load cache 'countries': unable to read the data file: record 1702 must have a key and a value column
+`
+	progress := parseCacheLoadLog(log)
+	assert.True(t, progress.found)
+	assert.Equal(t, int64(1700), progress.loaded)
+	assert.Equal(t, int64(1), progress.failed)
+	assert.Equal(t, []string{"unable to write key 'xx': status 400"}, progress.errors)
+	assert.Equal(t, "unable to load cache 'countries': unable to read the data file: record 1702 must have a key and a value column", progress.reason)
+
+	assert.False(t, parseCacheLoadLog("").found)
+}
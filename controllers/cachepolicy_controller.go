@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	EventReasonCachePolicyCacheCreated = "CachePolicyCacheCreated"
+	EventReasonCachePolicyCacheRemoved = "CachePolicyCacheRemoved"
+)
+
+// CachePolicyReconciler reconciles a CachePolicy object
+type CachePolicyReconciler struct {
+	client.Client
+	log        logr.Logger
+	scheme     *runtime.Scheme
+	kubernetes *kube.Kubernetes
+	eventRec   record.EventRecorder
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CachePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Client = mgr.GetClient()
+	r.log = ctrl.Log.WithName("controllers").WithName("CachePolicy")
+	r.scheme = mgr.GetScheme()
+	r.kubernetes = kube.NewKubernetesFromController(mgr)
+	r.eventRec = mgr.GetEventRecorderFor("cachepolicy-controller")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infinispanv2alpha1.CachePolicy{}).
+		Owns(&infinispanv2alpha1.Cache{}).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infinispan.org,resources=cachepolicies;cachepolicies/status;cachepolicies/finalizers,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups=infinispan.org,resources=caches,verbs=delete
+
+func (r *CachePolicyReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("+++++ Reconciling CachePolicy.")
+	defer reqLogger.Info("----- End Reconciling CachePolicy.")
+
+	// Fetch the CachePolicy instance
+	instance := &infinispanv2alpha1.CachePolicy{}
+	if err := r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.Info("CachePolicy resource not found. Ignoring it since its Caches are garbage collected")
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	updateStatus := func(status metav1.ConditionStatus, message string) error {
+		if !instance.SetCondition(infinispanv2alpha1.CachePolicyConditionReady, status, message) {
+			return nil
+		}
+		return r.Client.Status().Update(ctx, instance)
+	}
+
+	if err := validateCachePolicy(instance); err != nil {
+		reqLogger.Error(err, "Invalid CachePolicy")
+		return reconcile.Result{}, updateStatus(metav1.ConditionFalse, err.Error())
+	}
+
+	template := &infinispanv2alpha1.CacheTemplate{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.TemplateRef}, template); err != nil {
+		if errors.IsNotFound(err) {
+			message := fmt.Sprintf("CacheTemplate %s not found", instance.Spec.TemplateRef)
+			reqLogger.Info(message)
+			return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, updateStatus(metav1.ConditionFalse, message)
+		}
+		return reconcile.Result{}, err
+	}
+
+	// Fetch the Infinispan cluster info
+	ispnInstance := &infinispanv1.Infinispan{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterName}, ispnInstance); err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.Info(fmt.Sprintf("Infinispan cluster %s not found", instance.Spec.ClusterName))
+			return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// Cluster must be well formed
+	if !ispnInstance.IsWellFormed() {
+		reqLogger.Info(fmt.Sprintf("Infinispan cluster %s not well formed", ispnInstance.Name))
+		return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+	}
+	podList, err := PodList(ispnInstance, r.kubernetes, ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to list pods")
+		return reconcile.Result{}, err
+	} else if len(podList.Items) == 0 {
+		reqLogger.Info("No Infinispan pods found")
+		return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+	}
+
+	cluster, err := NewCluster(ispnInstance, r.kubernetes, ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	cacheNames, err := cluster.CacheNames(podList.Items[0].Name)
+	if err != nil {
+		reqLogger.Error(err, "Unable to list the caches of the cluster")
+		return reconcile.Result{}, err
+	}
+
+	status := instance.Status.DeepCopy()
+	if instance.Status.Caches, err = r.applyCachePolicy(ctx, instance, cacheNames); err != nil {
+		reqLogger.Error(err, "Error applying CachePolicy")
+		return reconcile.Result{}, err
+	}
+	instance.SetCondition(infinispanv2alpha1.CachePolicyConditionReady, metav1.ConditionTrue, "")
+	if !reflect.DeepEqual(status, &instance.Status) {
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Unable to update CachePolicy %s status", instance.Name))
+			return reconcile.Result{}, err
+		}
+	}
+	// The caches created on the cluster are discovered by polling, as the server doesn't notify the operator
+	return reconcile.Result{RequeueAfter: constants.DefaultCachePolicyPollPeriod}, nil
+}
+
+// applyCachePolicy creates a Cache CR for each cache of the cluster that matches the policy and isn't managed by a
+// Cache CR yet, and deletes the Cache CRs of the policy whose cache was removed from the cluster. Returns the names
+// of the caches matching the policy
+func (r *CachePolicyReconciler) applyCachePolicy(ctx context.Context, policy *infinispanv2alpha1.CachePolicy, cacheNames []string) ([]string, error) {
+	cacheList := &infinispanv2alpha1.CacheList{}
+	if err := r.Client.List(ctx, cacheList, &client.ListOptions{Namespace: policy.Namespace}); err != nil {
+		return nil, err
+	}
+	managed := map[string]bool{}
+	for _, cache := range cacheList.Items {
+		if cache.Spec.ClusterName == policy.Spec.ClusterName {
+			managed[cache.GetCacheName()] = true
+		}
+	}
+
+	exists := map[string]bool{}
+	matched := []string{}
+	for _, cacheName := range cacheNames {
+		exists[cacheName] = true
+		if !cachePolicyMatches(policy, cacheName) {
+			continue
+		}
+		matched = append(matched, cacheName)
+		if managed[cacheName] {
+			continue
+		}
+		cache := &infinispanv2alpha1.Cache{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cachePolicyCacheName(policy.Name, cacheName),
+				Namespace: policy.Namespace,
+				Labels:    map[string]string{infinispanv2alpha1.CachePolicyLabel: policy.Name},
+			},
+			Spec: infinispanv2alpha1.CacheSpec{
+				ClusterName:    policy.Spec.ClusterName,
+				Name:           cacheName,
+				TemplateRef:    policy.Spec.TemplateRef,
+				DeletionPolicy: policy.Spec.DeletionPolicy,
+			},
+		}
+		if err := controllerutil.SetControllerReference(policy, cache, r.scheme); err != nil {
+			return nil, err
+		}
+		if err := r.Client.Create(ctx, cache); err != nil {
+			return nil, fmt.Errorf("unable to create Cache %s for cache %s: %w", cache.Name, cacheName, err)
+		}
+		r.eventRec.Event(policy, corev1.EventTypeNormal, EventReasonCachePolicyCacheCreated, fmt.Sprintf("Cache %s created for cache %s", cache.Name, cacheName))
+	}
+
+	for i := range cacheList.Items {
+		cache := &cacheList.Items[i]
+		if cache.Labels[infinispanv2alpha1.CachePolicyLabel] != policy.Name || !metav1.IsControlledBy(cache, policy) {
+			continue
+		}
+		if exists[cache.GetCacheName()] || !cache.IsManaged() {
+			continue
+		}
+		// The cache was removed from the cluster, a new Cache CR is created if a cache with the same name is recreated
+		if err := r.Client.Delete(ctx, cache); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		r.eventRec.Event(policy, corev1.EventTypeNormal, EventReasonCachePolicyCacheRemoved, fmt.Sprintf("Cache %s deleted as cache %s was removed from the cluster", cache.Name, cache.GetCacheName()))
+	}
+	sort.Strings(matched)
+	return matched, nil
+}
+
+// cachePolicyMatches returns true if the cache name matches the pattern of the policy. Internal caches never match
+func cachePolicyMatches(policy *infinispanv2alpha1.CachePolicy, cacheName string) bool {
+	if strings.HasPrefix(cacheName, "___") {
+		return false
+	}
+	matches, err := path.Match(policy.Spec.CacheNamePattern, cacheName)
+	return err == nil && matches
+}
+
+// cachePolicyCacheName returns the name of the Cache CR created by the policy for the cache. Cache names that would
+// not result in a valid resource name are replaced by their hash
+func cachePolicyCacheName(policyName, cacheName string) string {
+	name := policyName + "-" + cacheName
+	if len(validation.IsDNS1123Subdomain(name)) > 0 {
+		name = policyName + "-" + hash.HashString(cacheName)[:10]
+	}
+	return name
+}
+
+// validateCachePolicy verifies that the name pattern of the policy is a valid shell pattern
+func validateCachePolicy(policy *infinispanv2alpha1.CachePolicy) error {
+	if _, err := path.Match(policy.Spec.CacheNamePattern, ""); err != nil {
+		return fmt.Errorf("invalid cacheNamePattern '%s': %w", policy.Spec.CacheNamePattern, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestCachePolicyMatches(t *testing.T) {
+	policy := &v2alpha1.CachePolicy{Spec: v2alpha1.CachePolicySpec{CacheNamePattern: "session-*"}}
+	assert.NoError(t, validateCachePolicy(policy))
+	assert.True(t, cachePolicyMatches(policy, "session-eu"))
+	assert.False(t, cachePolicyMatches(policy, "sessions"))
+
+	// Internal caches are never matched
+	policy.Spec.CacheNamePattern = "*"
+	assert.False(t, cachePolicyMatches(policy, "___protobuf_metadata"))
+
+	policy.Spec.CacheNamePattern = "session-["
+	assert.Error(t, validateCachePolicy(policy))
+	assert.False(t, cachePolicyMatches(policy, "session-eu"))
+}
+
+func TestCachePolicyCacheName(t *testing.T) {
+	assert.Equal(t, "sessions-session-eu", cachePolicyCacheName("sessions", "session-eu"))
+	assert.Regexp(t, "^sessions-[0-9a-f]{10}$", cachePolicyCacheName("sessions", "Session_EU"))
+}
+
+func TestApplyCachePolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, infinispanv1.AddToScheme(scheme))
+	assert.NoError(t, v2alpha1.AddToScheme(scheme))
+	policy := &v2alpha1.CachePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "sessions", Namespace: namespace, UID: "policy-uid"},
+		Spec:       v2alpha1.CachePolicySpec{ClusterName: "example-infinispan", CacheNamePattern: "session-*", TemplateRef: "session-template"},
+	}
+	// The cache is already managed by a Cache CR
+	existing := &v2alpha1.Cache{
+		ObjectMeta: metav1.ObjectMeta{Name: "session-us", Namespace: namespace},
+		Spec:       v2alpha1.CacheSpec{ClusterName: "example-infinispan"},
+	}
+	r := &CachePolicyReconciler{Client: fake.NewFakeClientWithScheme(scheme, policy, existing), log: logf.Log, scheme: scheme, eventRec: record.NewFakeRecorder(10)}
+	ctx := context.TODO()
+
+	matched, err := r.applyCachePolicy(ctx, policy, []string{"session-us", "session-eu", "default", "___script_cache"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"session-eu", "session-us"}, matched)
+
+	cacheList := &v2alpha1.CacheList{}
+	assert.NoError(t, r.Client.List(ctx, cacheList, client.MatchingLabels{v2alpha1.CachePolicyLabel: "sessions"}))
+	assert.Len(t, cacheList.Items, 1)
+	cache := cacheList.Items[0]
+	assert.Equal(t, "sessions-session-eu", cache.Name)
+	assert.Equal(t, v2alpha1.CacheSpec{ClusterName: "example-infinispan", Name: "session-eu", TemplateRef: "session-template"}, cache.Spec)
+	assert.True(t, metav1.IsControlledBy(&cache, policy))
+
+	// The Cache CR is deleted once its cache is removed from the cluster
+	cache.Status.Origin = v2alpha1.CacheOriginAdopted
+	assert.NoError(t, r.Client.Status().Update(ctx, &cache))
+	matched, err = r.applyCachePolicy(ctx, policy, []string{"session-us", "default"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"session-us"}, matched)
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "sessions-session-eu"}, &v2alpha1.Cache{})
+	assert.Error(t, err)
+	assert.NoError(t, r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "session-us"}, &v2alpha1.Cache{}))
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	EventReasonCanaryUpgrade           = "CanaryUpgrade"
+	EventReasonCanaryUpgradeCompleted  = "CanaryUpgradeCompleted"
+	EventReasonCanaryUpgradeRolledBack = "CanaryUpgradeRolledBack"
+)
+
+// reconcileCanaryUpgrade upgrades the pods to the operator default image one at a time, from the highest ordinal, by
+// lowering the rolling update partition of the StatefulSet once the upgraded pods are ready and the cluster is healthy.
+// If the cluster isn't healthy within the canary timeout, the StatefulSet is reverted to the previous image and the
+// upgraded pods are rolled back
+func (r *infinispanRequest) reconcileCanaryUpgrade(statefulSet *appsv1.StatefulSet, podList *corev1.PodList, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	canary := infinispan.Status.CanaryUpgrade
+	if canary == nil || !infinispan.IsUpgradeCondition() {
+		if upgrade, err := upgradeRequired(infinispan, podList); !upgrade || err != nil {
+			return nil, err
+		}
+		return r.startCanaryUpgrade(statefulSet)
+	}
+
+	if healthy, err := canaryHealthy(statefulSet, podList, canary.Partition, cluster); err != nil {
+		return &ctrl.Result{}, err
+	} else if !healthy {
+		if time.Since(canary.PartitionTime.Time) > infinispan.GetCanaryTimeout() {
+			return r.rollbackCanaryUpgrade(statefulSet, podList)
+		}
+		r.reqLogger.Info("Waiting for the cluster to be healthy to continue the canary upgrade", "partition", canary.Partition)
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+
+	if canary.Partition == 0 {
+		statefulSet.Spec.UpdateStrategy.RollingUpdate = nil
+		if err := r.Client.Update(r.ctx, statefulSet); err != nil {
+			return &ctrl.Result{}, err
+		}
+		r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonCanaryUpgradeCompleted, fmt.Sprintf("All pods upgraded to %s", canary.TargetDefaultImage))
+		return &ctrl.Result{Requeue: true}, r.update(func() {
+			infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionFalse, infinispanv1.ReasonUpgradeCompleted, "")
+			infinispan.Status.CanaryUpgrade = nil
+		})
+	}
+	return r.setCanaryPartition(statefulSet, canary.Partition-1)
+}
+
+// startCanaryUpgrade updates the image of the StatefulSet, with a partition restricting the upgrade to the pod with the
+// highest ordinal
+func (r *infinispanRequest) startCanaryUpgrade(statefulSet *appsv1.StatefulSet) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	container := &statefulSet.Spec.Template.Spec.Containers[0]
+	canary := &infinispanv1.CanaryUpgradeStatus{
+		SourceImage:        container.Image,
+		SourceDefaultImage: kube.GetPodDefaultImage(*container),
+		TargetDefaultImage: infinispan.DefaultImageName(),
+	}
+	r.reqLogger.Info("schedule an Infinispan cluster canary upgrade", "pod default image", canary.SourceDefaultImage, "desired image", canary.TargetDefaultImage)
+	if err := r.update(func() {
+		infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionTrue, infinispanv1.ReasonCanaryUpgrade, fmt.Sprintf("Upgrading the pods to %s", canary.TargetDefaultImage))
+		infinispan.Status.CanaryUpgrade = canary
+	}); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	container.Image = infinispan.ImageName()
+	updateStatefulSetEnv(statefulSet, "DEFAULT_IMAGE", canary.TargetDefaultImage)
+	return r.setCanaryPartition(statefulSet, *statefulSet.Spec.Replicas-1)
+}
+
+// setCanaryPartition upgrades the pods with an ordinal greater or equal to `partition`
+func (r *infinispanRequest) setCanaryPartition(statefulSet *appsv1.StatefulSet, partition int32) (*ctrl.Result, error) {
+	statefulSet.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(partition)}
+	if err := r.Client.Update(r.ctx, statefulSet); err != nil {
+		return &ctrl.Result{}, err
+	}
+	r.eventRec.Event(r.infinispan, corev1.EventTypeNormal, EventReasonCanaryUpgrade, fmt.Sprintf("Upgrading pod %s-%d", statefulSet.Name, partition))
+	return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, r.update(func() {
+		r.infinispan.Status.CanaryUpgrade.Partition = partition
+		r.infinispan.Status.CanaryUpgrade.PartitionTime = metav1.Now()
+	})
+}
+
+// rollbackCanaryUpgrade reverts the StatefulSet to the image of the pods before the upgrade, without partition so that
+// the upgraded pods are rolled back. The OrderedReady StatefulSet doesn't replace a pod that never becomes ready, so the
+// upgraded pods which are not ready are deleted to be recreated from the reverted template
+func (r *infinispanRequest) rollbackCanaryUpgrade(statefulSet *appsv1.StatefulSet, podList *corev1.PodList) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	canary := infinispan.Status.CanaryUpgrade
+	upgradedRevision := statefulSet.Status.UpdateRevision
+	statefulSet.Spec.Template.Spec.Containers[0].Image = canary.SourceImage
+	updateStatefulSetEnv(statefulSet, "DEFAULT_IMAGE", canary.SourceDefaultImage)
+	statefulSet.Spec.UpdateStrategy.RollingUpdate = nil
+	if err := r.Client.Update(r.ctx, statefulSet); err != nil {
+		return &ctrl.Result{}, err
+	}
+	for i, pod := range podList.Items {
+		if podOrdinal(pod.Name) < int(canary.Partition) || kube.IsPodReady(pod) || pod.Labels[appsv1.ControllerRevisionHashLabelKey] != upgradedRevision {
+			continue
+		}
+		r.reqLogger.Info("Deleting the upgraded pod which is not ready to roll it back", "pod", pod.Name)
+		if err := r.Client.Delete(r.ctx, &podList.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	message := fmt.Sprintf("The cluster was not healthy %s after upgrading pod %s-%d to %s", infinispan.GetCanaryTimeout(), statefulSet.Name, canary.Partition, canary.TargetDefaultImage)
+	r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonCanaryUpgradeRolledBack, message)
+	return &ctrl.Result{Requeue: true}, r.update(func() {
+		infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionFalse, infinispanv1.ReasonUpgradeRolledBack, message)
+		infinispan.Status.CanaryUpgrade.RolledBack = true
+	})
+}
+
+// canaryHealthy returns true when the pods with an ordinal greater or equal to the partition run the updated revision
+// of the StatefulSet, all the pods are ready and the cluster is healthy, with no rebalance in progress
+func canaryHealthy(statefulSet *appsv1.StatefulSet, podList *corev1.PodList, partition int32, cluster ispn.ClusterInterface) (bool, error) {
+	if statefulSet.Status.ObservedGeneration != statefulSet.Generation || len(podList.Items) == 0 || len(podList.Items) != int(*statefulSet.Spec.Replicas) {
+		return false, nil
+	}
+	for _, pod := range podList.Items {
+		if !kube.IsPodReady(pod) {
+			return false, nil
+		}
+		if podOrdinal(pod.Name) >= int(partition) && pod.Labels[appsv1.ControllerRevisionHashLabelKey] != statefulSet.Status.UpdateRevision {
+			return false, nil
+		}
+	}
+	health, err := cluster.GetClusterHealth(podList.Items[0].Name)
+	if err != nil {
+		return false, err
+	}
+	return health.Status == ispn.ClusterHealthHealthy, nil
+}
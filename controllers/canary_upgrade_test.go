@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const canarySourceImage = "quay.io/infinispan/server:previous"
+
+func canaryStatefulSet() *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:       pointer.Int32Ptr(2),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"updateDate": "previous"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "infinispan",
+						Image: canarySourceImage,
+						Env:   []corev1.EnvVar{{Name: "DEFAULT_IMAGE", Value: canarySourceImage}},
+					}},
+				},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "upgraded"},
+	}
+}
+
+// canaryPods returns ready pods, running the upgraded revision from ordinal `partition`
+func canaryPods(partition int) *corev1.PodList {
+	podList := &corev1.PodList{}
+	for i := 0; i < 2; i++ {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("example-infinispan-%d", i), Labels: map[string]string{}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "DEFAULT_IMAGE", Value: canarySourceImage}}}},
+			},
+			Status: corev1.PodStatus{
+				PodIP:             "10.0.0.1",
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+			},
+		}
+		if i >= partition {
+			pod.Labels[appsv1.ControllerRevisionHashLabelKey] = "upgraded"
+		}
+		podList.Items = append(podList.Items, pod)
+	}
+	return podList
+}
+
+func TestReconcileCanaryUpgrade(t *testing.T) {
+	statefulSet := canaryStatefulSet()
+	r := dataMigrationRequest(t, nil, statefulSet)
+	infinispan := r.infinispan
+	assert.NoError(t, r.update(func() {
+		infinispan.Spec.Upgrades.Type = infinispanv1.UpgradeTypeCanary
+	}))
+	healthy := &healthCluster{status: ispn.ClusterHealthHealthy}
+	getStatefulSet := func() *appsv1.StatefulSet {
+		ss := &appsv1.StatefulSet{}
+		assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "example-infinispan"}, ss))
+		return ss
+	}
+
+	// The pod with the highest ordinal is upgraded first
+	result, err := r.reconcileCanaryUpgrade(getStatefulSet(), canaryPods(2), healthy)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.True(t, infinispan.IsUpgradeCondition())
+	assert.Equal(t, canarySourceImage, infinispan.Status.CanaryUpgrade.SourceImage)
+	assert.Equal(t, consts.DefaultImageName, infinispan.Status.CanaryUpgrade.TargetDefaultImage)
+	assert.Equal(t, int32(1), infinispan.Status.CanaryUpgrade.Partition)
+	ss := getStatefulSet()
+	assert.Equal(t, int32(1), *ss.Spec.UpdateStrategy.RollingUpdate.Partition)
+	assert.Equal(t, infinispan.ImageName(), ss.Spec.Template.Spec.Containers[0].Image)
+
+	// The partition isn't lowered until the upgraded pod runs and the cluster is healthy
+	_, err = r.reconcileCanaryUpgrade(getStatefulSet(), canaryPods(2), healthy)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), *getStatefulSet().Spec.UpdateStrategy.RollingUpdate.Partition)
+	_, err = r.reconcileCanaryUpgrade(getStatefulSet(), canaryPods(1), &healthCluster{status: ispn.ClusterHealthDegraded})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), *getStatefulSet().Spec.UpdateStrategy.RollingUpdate.Partition)
+
+	_, err = r.reconcileCanaryUpgrade(getStatefulSet(), canaryPods(1), healthy)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), *getStatefulSet().Spec.UpdateStrategy.RollingUpdate.Partition)
+	assert.Equal(t, int32(0), infinispan.Status.CanaryUpgrade.Partition)
+
+	result, err = r.reconcileCanaryUpgrade(getStatefulSet(), canaryPods(0), healthy)
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Nil(t, getStatefulSet().Spec.UpdateStrategy.RollingUpdate)
+	assert.Nil(t, infinispan.Status.CanaryUpgrade)
+	assert.False(t, infinispan.IsUpgradeCondition())
+	assert.Equal(t, infinispanv1.ReasonUpgradeCompleted, infinispan.GetCondition(infinispanv1.ConditionUpgrade).Reason)
+}
+
+func TestRollbackCanaryUpgrade(t *testing.T) {
+	statefulSet := canaryStatefulSet()
+	statefulSet.Spec.Template.Spec.Containers[0].Image = consts.DefaultImageName
+	statefulSet.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(1)}
+	r := dataMigrationRequest(t, nil, statefulSet)
+	infinispan := r.infinispan
+	assert.NoError(t, r.update(func() {
+		infinispan.Spec.Upgrades = &infinispanv1.InfinispanUpgradesSpec{Type: infinispanv1.UpgradeTypeCanary, CanaryTimeoutSeconds: pointer.Int32Ptr(60)}
+		infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionTrue, infinispanv1.ReasonCanaryUpgrade, "")
+		infinispan.Status.CanaryUpgrade = &infinispanv1.CanaryUpgradeStatus{
+			SourceImage:        canarySourceImage,
+			SourceDefaultImage: canarySourceImage,
+			TargetDefaultImage: consts.DefaultImageName,
+			Partition:          1,
+			PartitionTime:      metav1.NewTime(time.Now().Add(-time.Minute * 2)),
+		}
+	}))
+	degraded := &healthCluster{status: ispn.ClusterHealthDegraded}
+
+	result, err := r.reconcileCanaryUpgrade(statefulSet, canaryPods(1), degraded)
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.True(t, infinispan.Status.CanaryUpgrade.RolledBack)
+	assert.False(t, infinispan.IsUpgradeCondition())
+	assert.Equal(t, infinispanv1.ReasonUpgradeRolledBack, infinispan.GetCondition(infinispanv1.ConditionUpgrade).Reason)
+
+	ss := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "example-infinispan"}, ss))
+	assert.Nil(t, ss.Spec.UpdateStrategy.RollingUpdate)
+	container := ss.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, canarySourceImage, container.Image)
+	assert.Equal(t, []corev1.EnvVar{{Name: "DEFAULT_IMAGE", Value: canarySourceImage}}, container.Env)
+
+	// The upgrade to the same image isn't retried
+	required, err := upgradeRequired(infinispan, canaryPods(2))
+	assert.NoError(t, err)
+	assert.False(t, required)
+	infinispan.Spec.Upgrades.Type = infinispanv1.UpgradeTypeShutdown
+	required, err = upgradeRequired(infinispan, canaryPods(2))
+	assert.NoError(t, err)
+	assert.True(t, required)
+}
+
+func TestRollbackCanaryUpgradePodNotReady(t *testing.T) {
+	statefulSet := canaryStatefulSet()
+	statefulSet.Spec.Template.Spec.Containers[0].Image = consts.DefaultImageName
+	statefulSet.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(1)}
+	// The canary pod never becomes ready, the pod before the partition isn't ready either but runs the source image
+	podList := canaryPods(1)
+	objs := []client.Object{statefulSet}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		pod.Namespace = namespace
+		pod.Status.Conditions = nil
+		objs = append(objs, pod.DeepCopy())
+	}
+	r := dataMigrationRequest(t, nil, objs...)
+	infinispan := r.infinispan
+	assert.NoError(t, r.update(func() {
+		infinispan.Spec.Upgrades = &infinispanv1.InfinispanUpgradesSpec{Type: infinispanv1.UpgradeTypeCanary, CanaryTimeoutSeconds: pointer.Int32Ptr(60)}
+		infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionTrue, infinispanv1.ReasonCanaryUpgrade, "")
+		infinispan.Status.CanaryUpgrade = &infinispanv1.CanaryUpgradeStatus{
+			SourceImage:        canarySourceImage,
+			SourceDefaultImage: canarySourceImage,
+			TargetDefaultImage: consts.DefaultImageName,
+			Partition:          1,
+			PartitionTime:      metav1.NewTime(time.Now().Add(-time.Minute * 2)),
+		}
+	}))
+
+	result, err := r.reconcileCanaryUpgrade(statefulSet, podList, &healthCluster{status: ispn.ClusterHealthDegraded})
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.True(t, infinispan.Status.CanaryUpgrade.RolledBack)
+
+	// The StatefulSet recreates the deleted canary pod from the reverted template
+	pod := &corev1.Pod{}
+	assert.True(t, errors.IsNotFound(r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "example-infinispan-1"}, pod)))
+	assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "example-infinispan-0"}, pod))
+}
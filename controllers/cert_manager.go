@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/security"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	CertManagerKeystorePasswordKey = "password"
+
+	EventReasonKeystoreReloaded = "KeystoreReloaded"
+)
+
+// CertManagerCertificateGVK is the cert-manager Certificate issuing the endpoint keystore
+var CertManagerCertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch
+
+// validateCertManager verifies that the issuer is provided and that the certificate is not also expected from a
+// serving certificate service
+func validateCertManager(i *ispnv1.Infinispan) error {
+	encryption := i.Spec.Security.EndpointEncryption
+	if encryption == nil || encryption.CertManager == nil {
+		return nil
+	}
+	if i.IsEncryptionCertFromService() {
+		return fmt.Errorf("infinispan.spec.security.endpointEncryption.certManager cannot be combined with type=%s", encryption.Type)
+	}
+	if encryption.CertManager.IssuerRef.Name == "" {
+		return fmt.Errorf("infinispan.spec.security.endpointEncryption.certManager.issuerRef.name must be provided")
+	}
+	return nil
+}
+
+// certManagerDNSNames returns the names of the cluster Service followed by the DNS names of the spec
+func certManagerDNSNames(i *ispnv1.Infinispan) []interface{} {
+	service := i.GetServiceName()
+	names := []interface{}{
+		service,
+		fmt.Sprintf("%s.%s", service, i.Namespace),
+		fmt.Sprintf("%s.%s.svc", service, i.Namespace),
+	}
+	for _, name := range i.Spec.Security.EndpointEncryption.CertManager.DNSNames {
+		names = append(names, name)
+	}
+	return names
+}
+
+// reconcileCertManagerCertificate creates the cert-manager Certificate issuing a PKCS12 keystore into the keystore
+// Secret, along with the Secret holding the password of the keystore
+func (s *secretRequest) reconcileCertManagerCertificate() error {
+	i := s.infinispan
+	if !i.IsEncryptionCertFromCertManager() {
+		return nil
+	}
+
+	passwordSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.GetCertManagerKeystorePasswordSecretName(),
+			Namespace: i.Namespace,
+		},
+	}
+	_, err := k8sctrlutil.CreateOrUpdate(s.ctx, s.Client, passwordSecret, func() error {
+		if _, ok := passwordSecret.Data[CertManagerKeystorePasswordKey]; !ok {
+			password, err := security.NewPassword()
+			if err != nil {
+				return err
+			}
+			passwordSecret.Labels = LabelsResource(i.Name, "infinispan-secret-cert-keystore-password")
+			passwordSecret.Type = corev1.SecretTypeOpaque
+			passwordSecret.Data = map[string][]byte{CertManagerKeystorePasswordKey: []byte(password)}
+		}
+		ApplyPropagatedMetadata(i, passwordSecret)
+		return k8sctrlutil.SetControllerReference(i, passwordSecret, s.scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create the keystore password secret: %w", err)
+	}
+
+	issuerRef := i.Spec.Security.EndpointEncryption.CertManager.IssuerRef
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertManagerCertificateGVK)
+	certificate.SetName(i.GetKeystoreSecretName())
+	certificate.SetNamespace(i.Namespace)
+	result, err := k8sctrlutil.CreateOrUpdate(s.ctx, s.Client, certificate, func() error {
+		issuer := map[string]interface{}{"name": issuerRef.Name}
+		if issuerRef.Kind != "" {
+			issuer["kind"] = issuerRef.Kind
+		}
+		if issuerRef.Group != "" {
+			issuer["group"] = issuerRef.Group
+		}
+		fields := map[string]interface{}{
+			"secretName": i.GetKeystoreSecretName(),
+			"issuerRef":  issuer,
+			"dnsNames":   certManagerDNSNames(i),
+			"keystores": map[string]interface{}{
+				"pkcs12": map[string]interface{}{
+					"create": true,
+					"passwordSecretRef": map[string]interface{}{
+						"name": passwordSecret.Name,
+						"key":  CertManagerKeystorePasswordKey,
+					},
+				},
+			},
+		}
+		for field, value := range fields {
+			if err := unstructured.SetNestedField(certificate.Object, value, "spec", field); err != nil {
+				return err
+			}
+		}
+		labels := certificate.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for key, value := range LabelsResource(i.Name, "infinispan-certificate") {
+			labels[key] = value
+		}
+		certificate.SetLabels(labels)
+		ApplyPropagatedMetadata(i, certificate)
+		return k8sctrlutil.SetControllerReference(i, certificate, s.scheme)
+	})
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("unable to create the Certificate %s, cert-manager is not installed: %w", certificate.GetName(), err)
+	} else if err != nil {
+		return fmt.Errorf("unable to create the Certificate %s: %w", certificate.GetName(), err)
+	}
+	if result == k8sctrlutil.OperationResultCreated {
+		s.reqLogger.Info(fmt.Sprintf("Created cert-manager Certificate %s", certificate.GetName()))
+	}
+	return nil
+}
+
+// keystoreHash returns the hash of the keystore Secret, which restarts the pods when it changes. The certificates
+// renewed by cert-manager are reloaded by the servers instead
+func keystoreHash(i *ispnv1.Infinispan, keystoreSecret *corev1.Secret) string {
+	if i.IsEncryptionCertFromCertManager() {
+		return ""
+	}
+	return hash.HashMap(keystoreSecret.Data)
+}
+
+// reloadCertManagerKeystore reloads the keystore on every server once the certificate renewed by cert-manager has been
+// updated in all the pods by the kubelet. A nil result means that the servers have loaded the current keystore
+func (r *infinispanRequest) reloadCertManagerKeystore(podList *corev1.PodList, cluster ispn.ClusterInterface, keystoreSecret *corev1.Secret) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	if !infinispan.IsEncryptionCertFromCertManager() {
+		if infinispan.Status.KeystoreHash == "" {
+			return nil, nil
+		}
+		return nil, r.update(func() {
+			infinispan.Status.KeystoreHash = ""
+		})
+	}
+
+	currentHash := hash.HashMap(keystoreSecret.Data)
+	if infinispan.Status.KeystoreHash == currentHash {
+		return nil, nil
+	}
+	// Servers load the current keystore when they start, it's only reloaded after a renewal
+	if infinispan.Status.KeystoreHash != "" {
+		certPath := fmt.Sprintf("%s/%s", consts.ServerEncryptKeystoreRoot, corev1.TLSCertKey)
+		for _, pod := range podList.Items {
+			stdout, stderr, err := r.kubernetes.ExecWithOptions(kube.ExecOptions{Command: []string{"cat", certPath}, PodName: pod.Name, Namespace: pod.Namespace})
+			if err != nil {
+				return &ctrl.Result{}, fmt.Errorf("unable to read the certificate of pod %s: %s, %w", pod.Name, stderr, err)
+			}
+			if !bytes.Equal(stdout.Bytes(), keystoreSecret.Data[corev1.TLSCertKey]) {
+				r.reqLogger.Info("waiting for the renewed certificate to be updated in the pod", "pod", pod.Name)
+				return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+			}
+		}
+		for _, pod := range podList.Items {
+			if err := cluster.ReloadKeystores(pod.Name); err != nil {
+				return &ctrl.Result{}, err
+			}
+		}
+		r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonKeystoreReloaded, "The certificate renewed by cert-manager has been reloaded by the servers")
+	}
+	return nil, r.update(func() {
+		infinispan.Status.KeystoreHash = currentHash
+	})
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func certManagerInfinispan() *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing", UID: "uid"}}
+	ispn.Spec.Security.EndpointEncryption = &ispnv1.EndpointEncryption{
+		CertManager: &ispnv1.CertManagerSpec{
+			IssuerRef: ispnv1.CertManagerIssuerRef{Name: "ca-issuer", Kind: "ClusterIssuer"},
+			DNSNames:  []string{"infinispan.example.com"},
+		},
+	}
+	ispn.ApplyEndpointEncryptionSettings("openshift.io", logr.Discard())
+	return ispn
+}
+
+func TestCertManagerEncryptionSettings(t *testing.T) {
+	ispn := certManagerInfinispan()
+	encryption := ispn.Spec.Security.EndpointEncryption
+	// The serving certificate service is not used when the certificate is issued by cert-manager
+	assert.Equal(t, ispnv1.CertificateSourceTypeSecret, encryption.Type)
+	assert.Equal(t, "example-infinispan-cert-secret", encryption.CertSecretName)
+	assert.True(t, ispn.IsEncryptionCertFromCertManager())
+	assert.NoError(t, validateCertManager(ispn))
+
+	secret := &corev1.Secret{Data: map[string][]byte{corev1.TLSCertKey: []byte("cert")}}
+	assert.Empty(t, keystoreHash(ispn, secret))
+	encryption.CertManager = nil
+	assert.NotEmpty(t, keystoreHash(ispn, secret))
+}
+
+func TestValidateCertManager(t *testing.T) {
+	ispn := certManagerInfinispan()
+	ispn.Spec.Security.EndpointEncryption.CertManager.IssuerRef.Name = ""
+	assert.Error(t, validateCertManager(ispn))
+
+	ispn = certManagerInfinispan()
+	ispn.Spec.Security.EndpointEncryption.Type = ispnv1.CertificateSourceTypeService
+	assert.Error(t, validateCertManager(ispn))
+}
+
+func TestReconcileCertManagerCertificate(t *testing.T) {
+	ispn := certManagerInfinispan()
+	c, scheme := transportEncryptionClient(t)
+	s := &secretRequest{
+		SecretReconciler: &SecretReconciler{Client: c, scheme: scheme},
+		infinispan:       ispn,
+		reqLogger:        ctrl.Log,
+		ctx:              context.TODO(),
+	}
+	assert.NoError(t, s.reconcileCertManagerCertificate())
+
+	passwordSecret := &corev1.Secret{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "testing", Name: "example-infinispan-cert-keystore-password"}, passwordSecret))
+	password := passwordSecret.Data[CertManagerKeystorePasswordKey]
+	assert.NotEmpty(t, password)
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertManagerCertificateGVK)
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "testing", Name: "example-infinispan-cert-secret"}, certificate))
+	spec := certificate.Object["spec"].(map[string]interface{})
+	assert.Equal(t, "example-infinispan-cert-secret", spec["secretName"])
+	assert.Equal(t, map[string]interface{}{"name": "ca-issuer", "kind": "ClusterIssuer"}, spec["issuerRef"])
+	assert.Equal(t, []interface{}{"example-infinispan", "example-infinispan.testing", "example-infinispan.testing.svc", "infinispan.example.com"}, spec["dnsNames"])
+	pkcs12, _, _ := unstructured.NestedMap(certificate.Object, "spec", "keystores", "pkcs12")
+	assert.Equal(t, true, pkcs12["create"])
+	assert.Len(t, certificate.GetOwnerReferences(), 1)
+
+	// The password is kept on later reconciliations
+	assert.NoError(t, s.reconcileCertManagerCertificate())
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "testing", Name: "example-infinispan-cert-keystore-password"}, passwordSecret))
+	assert.Equal(t, password, passwordSecret.Data[CertManagerKeystorePasswordKey])
+}
@@ -18,3 +18,17 @@ func NewCluster(i *v1.Infinispan, kubernetes *kube.Kubernetes, ctx context.Conte
 	}
 	return ispn.NewCluster(consts.DefaultOperatorUser, pass, i.Namespace, "http", kubernetes), nil
 }
+
+// NewPodCluster creates a Cluster sending the REST calls to specific pods through the headless admin service, e.g. to
+// collect per pod diagnostics. The REST calls are executed in the pods when the operator runs outside of Kubernetes, as
+// the pods can't be reached
+func NewPodCluster(i *v1.Infinispan, kubernetes *kube.Kubernetes, ctx context.Context) (*ispn.Cluster, error) {
+	if kube.IsRunModeLocal() {
+		return NewCluster(i, kubernetes, ctx)
+	}
+	pass, err := users.AdminPassword(i.GetAdminSecretName(), i.Namespace, kubernetes, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve opeator admin identities when creating Cluster instance: %w", err)
+	}
+	return ispn.NewDirectCluster(consts.DefaultOperatorUser, pass, i.Namespace, "http", i.GetAdminHeadlessServiceName(), kubernetes), nil
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"reflect"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -23,5 +24,8 @@ func (rt reconcileType) GroupVersionKind() schema.GroupVersionKind {
 }
 
 func (rt reconcileType) Kind() string {
+	if u, ok := rt.ObjectType.(*unstructured.Unstructured); ok {
+		return u.GetKind()
+	}
 	return reflect.TypeOf(rt.ObjectType).Elem().Name()
 }
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// ConfigExportAnnotation requests the export of the live server configuration into the config export ConfigMap
+	// when set to "true". The operator removes the annotation once the configuration is exported
+	ConfigExportAnnotation = "infinispan.org/export-config"
+	// ConfigExportPodAnnotation records the pod the exported configuration was retrieved from
+	ConfigExportPodAnnotation = "infinispan.org/config-export-pod"
+	// ConfigExportHashAnnotation is the hash of the exported configuration, to detect drifts without comparing it
+	ConfigExportHashAnnotation = "infinispan.org/config-export-hash"
+
+	ConfigExportServerKey = "server.xml"
+	// ConfigExportRedacted replaces the sensitive values of the exported configuration
+	ConfigExportRedacted = "***"
+
+	EventReasonConfigExported     = "ConfigExported"
+	EventReasonConfigExportFailed = "ConfigExportFailed"
+)
+
+var (
+	// sensitiveAttributeRegex matches the XML attributes holding credentials, e.g. keystore-password="..."
+	sensitiveAttributeRegex = regexp.MustCompile(`(\s[\w:.-]*(?i:password|secret|credential|token)[\w:.-]*\s*=\s*)("[^"]*"|'[^']*')`)
+	// sensitiveElementRegex matches the XML elements holding credentials, e.g. <password>...</password>
+	sensitiveElementRegex = regexp.MustCompile(`<([\w:.-]*(?i:password|secret|credential|token)[\w:.-]*)(\s[^<>]*)?>([^<]*)</([\w:.-]+)>`)
+)
+
+// redactServerConfig replaces the values of the XML attributes and elements holding credentials, so that the exported
+// configuration can be stored in Git. Elements referencing credentials, e.g. credential-reference, are kept
+func redactServerConfig(config string) string {
+	config = sensitiveAttributeRegex.ReplaceAllStringFunc(config, func(attribute string) string {
+		match := sensitiveAttributeRegex.FindStringSubmatch(attribute)
+		quote := match[2][:1]
+		return match[1] + quote + ConfigExportRedacted + quote
+	})
+	return sensitiveElementRegex.ReplaceAllStringFunc(config, func(element string) string {
+		match := sensitiveElementRegex.FindStringSubmatch(element)
+		if match[1] != match[4] || match[3] == "" {
+			return element
+		}
+		return fmt.Sprintf("<%s%s>%s</%s>", match[1], match[2], ConfigExportRedacted, match[4])
+	})
+}
+
+// reconcileConfigExport exports the live configuration of the server into the config export ConfigMap when requested
+// by the ConfigExportAnnotation. The export waits for a ready pod, and failures are reported as events so that they
+// never block the reconciliation of the cluster
+func (r *infinispanRequest) reconcileConfigExport(podList *corev1.PodList, cluster ispn.ClusterInterface) error {
+	i := r.infinispan
+	if i.Annotations[ConfigExportAnnotation] != "true" {
+		return nil
+	}
+	var podName string
+	for _, pod := range podList.Items {
+		if kube.IsPodReady(pod) {
+			podName = pod.Name
+			break
+		}
+	}
+	if podName == "" {
+		r.reqLogger.Info("Waiting for a ready pod to export the server configuration")
+		return nil
+	}
+
+	if err := r.exportServerConfig(cluster, podName); err != nil {
+		r.eventRec.Event(i, corev1.EventTypeWarning, EventReasonConfigExportFailed, err.Error())
+	} else {
+		r.eventRec.Event(i, corev1.EventTypeNormal, EventReasonConfigExported, fmt.Sprintf("Server configuration of pod %s exported to ConfigMap %s", podName, i.GetConfigExportName()))
+	}
+	return r.update(func() {
+		delete(i.Annotations, ConfigExportAnnotation)
+	})
+}
+
+func (r *infinispanRequest) exportServerConfig(cluster ispn.ClusterInterface, podName string) error {
+	i := r.infinispan
+	config, err := cluster.GetServerConfig(podName)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve the server configuration: %w", err)
+	}
+	config = redactServerConfig(config)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.GetConfigExportName(),
+			Namespace: i.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(r.ctx, r.Client, configMap, func() error {
+		if configMap.CreationTimestamp.IsZero() {
+			if err := controllerutil.SetControllerReference(i, configMap, r.scheme); err != nil {
+				return err
+			}
+		}
+		configMap.Labels = LabelsResource(i.Name, "infinispan-config-export")
+		if configMap.Annotations == nil {
+			configMap.Annotations = map[string]string{}
+		}
+		configMap.Annotations[ConfigExportPodAnnotation] = podName
+		configMap.Annotations[ConfigExportHashAnnotation] = hash.HashString(config)
+		configMap.Data = map[string]string{ConfigExportServerKey: config}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to store the server configuration in ConfigMap %s: %w", i.GetConfigExportName(), err)
+	}
+	r.reqLogger.Info("Exported the server configuration", "pod", podName, "configMap", i.GetConfigExportName())
+	return nil
+}
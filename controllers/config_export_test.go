@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const exportedServerConfig = `<infinispan>
+  <cache-container name="default" statistics="true"/>
+  <server>
+    <security>
+      <security-realms>
+        <security-realm name="default">
+          <server-identities>
+            <ssl>
+              <keystore path="/etc/security/keystore.p12" keystore-password="changeme" alias="server"/>
+            </ssl>
+          </server-identities>
+          <ldap-realm url="ldap://ldap:389" principal="uid=admin" credential='secret'>
+            <credential-reference store="credentials" alias="ldap"/>
+          </ldap-realm>
+          <token-realm auth-server-url="https://sso"><oauth2-introspection client-id="infinispan"><client-secret>s3cr3t</client-secret></oauth2-introspection></token-realm>
+        </security-realm>
+      </security-realms>
+    </security>
+  </server>
+</infinispan>`
+
+// configCluster returns the live configuration of the server
+type configCluster struct {
+	ispn.ClusterInterface
+	config string
+}
+
+func (c *configCluster) GetServerConfig(podName string) (string, error) {
+	return c.config, nil
+}
+
+func TestRedactServerConfig(t *testing.T) {
+	redacted := redactServerConfig(exportedServerConfig)
+	assert.Contains(t, redacted, `keystore-password="***"`)
+	assert.Contains(t, redacted, `credential='***'`)
+	assert.Contains(t, redacted, `<client-secret>***</client-secret>`)
+	assert.NotContains(t, redacted, "changeme")
+	assert.NotContains(t, redacted, "s3cr3t")
+	// References to the credentials and other attributes are kept
+	assert.Contains(t, redacted, `<credential-reference store="credentials" alias="ldap"/>`)
+	assert.Contains(t, redacted, `principal="uid=admin"`)
+	assert.Contains(t, redacted, `client-id="infinispan"`)
+}
+
+func TestReconcileConfigExport(t *testing.T) {
+	infinispan := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "example-infinispan",
+			Namespace:   namespace,
+			Annotations: map[string]string{ConfigExportAnnotation: "true"},
+			// Set by the API server, required to update the CR
+			CreationTimestamp: metav1.Now(),
+		},
+	}
+	r := volumeExpansionRequest(t, "1Gi", infinispan)
+	r.infinispan = infinispan.DeepCopy()
+	cluster := &configCluster{config: exportedServerConfig}
+
+	// The export waits for a ready pod
+	notReady := dataVolumePod("example-infinispan-0")
+	notReady.Status.Conditions = nil
+	assert.NoError(t, r.reconcileConfigExport(&corev1.PodList{Items: []corev1.Pod{notReady}}, cluster))
+	assert.Equal(t, "true", r.infinispan.Annotations[ConfigExportAnnotation])
+
+	pods := &corev1.PodList{Items: []corev1.Pod{notReady, dataVolumePod("example-infinispan-1")}}
+	assert.NoError(t, r.reconcileConfigExport(pods, cluster))
+
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "example-infinispan-config-export"}, configMap))
+	assert.Equal(t, redactServerConfig(exportedServerConfig), configMap.Data[ConfigExportServerKey])
+	assert.Equal(t, "example-infinispan-1", configMap.Annotations[ConfigExportPodAnnotation])
+	assert.NotEmpty(t, configMap.Annotations[ConfigExportHashAnnotation])
+	assert.Len(t, configMap.OwnerReferences, 1)
+
+	stored := &infinispanv1.Infinispan{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: infinispan.Name}, stored))
+	assert.NotContains(t, stored.Annotations, ConfigExportAnnotation)
+}
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConsoleProxyContainer is the sidecar authenticating the console users with OIDC
+	ConsoleProxyContainer = "console-proxy"
+	// ConsoleProxyHashAnnotation tracks the changes of the console proxy sidecar
+	ConsoleProxyHashAnnotation = "infinispan.org/console-proxy-hash"
+	// ConsoleCookieSecretKey is the key of the OIDC client Secret holding the secret the proxy encrypts its cookies with
+	ConsoleCookieSecretKey = "cookieSecret"
+)
+
+// validateConsoleExpose verifies the OIDC authentication of the console and that TLS can be terminated by its Route as
+// configured
+func validateConsoleExpose(i *ispnv1.Infinispan) error {
+	if !i.IsConsoleExposed() {
+		return nil
+	}
+	console := i.Spec.Expose.Console
+	field := "infinispan.spec.expose.console"
+	if oidc := console.OIDC; oidc != nil {
+		if u, err := url.Parse(oidc.IssuerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s.oidc.issuerUrl '%s' must be a http:// or https:// URL", field, oidc.IssuerURL)
+		}
+		if oidc.ClientSecretName == "" {
+			return fmt.Errorf("%s.oidc.clientSecretName must be provided", field)
+		}
+		// The proxy serves plain HTTP, the Route has to terminate TLS for the cookies of the proxy
+		if console.TLSTermination != "" && console.TLSTermination != ispnv1.RouteTLSTerminationEdge {
+			return fmt.Errorf("%s.tlsTermination=%s is not supported with OIDC authentication", field, console.TLSTermination)
+		}
+		return nil
+	}
+	switch console.TLSTermination {
+	case ispnv1.RouteTLSTerminationPassthrough, ispnv1.RouteTLSTerminationReencrypt:
+		if !i.IsEncryptionEnabled() {
+			return fmt.Errorf("%s.tlsTermination=%s requires endpoint encryption", field, console.TLSTermination)
+		}
+	case ispnv1.RouteTLSTerminationEdge:
+		if i.IsEncryptionEnabled() {
+			return fmt.Errorf("%s.tlsTermination=%s requires endpoint encryption to be disabled", field, console.TLSTermination)
+		}
+	}
+	return nil
+}
+
+// consoleExposeSpec returns how the console is exposed. TLS is terminated at the edge by default when the proxy
+// authenticates the console users, as the proxy serves plain HTTP
+func consoleExposeSpec(i *ispnv1.Infinispan) *ispnv1.EndpointExposeSpec {
+	console := i.Spec.Expose.Console
+	expose := &ispnv1.EndpointExposeSpec{
+		Type:           ispnv1.ExposeTypeRoute,
+		Host:           console.Host,
+		TLSTermination: console.TLSTermination,
+	}
+	if expose.TLSTermination == "" && console.OIDC != nil {
+		expose.TLSTermination = ispnv1.RouteTLSTerminationEdge
+	}
+	return expose
+}
+
+// computeConsoleService computes the Service targeting the console proxy sidecar of the pods
+func computeConsoleService(i *ispnv1.Infinispan) *corev1.Service {
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.GetConsoleExternalName(),
+			Namespace: i.Namespace,
+			Labels:    ExternalServiceLabels(i.Name),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: ServiceLabels(i.Name),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       consts.ConsoleProxyPortName,
+					Port:       consts.ConsoleProxyPort,
+					TargetPort: intstr.FromInt(consts.ConsoleProxyPort),
+				},
+			},
+		},
+	}
+	// This way CR labels will override operator labels with same name
+	i.AddOperatorLabelsForServices(service.Labels)
+	i.AddLabelsForServices(service.Labels)
+	return service
+}
+
+// reconcileConsoleExpose creates the Route or Ingress exposing the console, and the Service of the console proxy when
+// the console users are authenticated with OIDC. The resources are returned so that they aren't removed by
+// cleanupExternalExpose
+func (s serviceRequest) reconcileConsoleExpose() ([]client.Object, error) {
+	i := s.infinispan
+	if !i.IsConsoleExposed() {
+		return nil, nil
+	}
+	var exposed []client.Object
+	serviceName, port := i.GetServiceName(), consts.InfinispanUserPort
+	if i.GetConsoleOIDC() != nil {
+		service := computeConsoleService(i)
+		if err := s.reconcileResource(service); err != nil {
+			return nil, err
+		}
+		exposed = append(exposed, service)
+		serviceName, port = service.Name, consts.ConsoleProxyPort
+	}
+
+	var resource client.Object
+	expose := consoleExposeSpec(i)
+	if s.isTypeSupported(consts.ExternalTypeRoute) {
+		route := computeRoute(i, i.GetConsoleExternalName(), port, expose)
+		route.Spec.To.Name = serviceName
+		resource = route
+	} else if s.isTypeSupported(consts.ExternalTypeIngress) {
+		ingress := computeIngress(i, i.GetConsoleExternalName(), port, expose)
+		ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name = serviceName
+		resource = ingress
+	} else {
+		return exposed, nil
+	}
+	if err := s.reconcileResource(resource); err != nil {
+		return nil, err
+	}
+	return append(exposed, resource), nil
+}
+
+// validateConsoleClientSecret verifies that the OIDC client Secret of the console proxy holds all its keys
+func validateConsoleClientSecret(secret *corev1.Secret) error {
+	for _, key := range []string{OAuth2ClientIdKey, OAuth2ClientSecretKey, ConsoleCookieSecretKey} {
+		if _, ok := secret.Data[key]; !ok {
+			return fmt.Errorf("the console OIDC client Secret '%s' must contain a '%s' key", secret.Name, key)
+		}
+	}
+	return nil
+}
+
+// consoleProxyContainer returns the oauth2-proxy sidecar authenticating the console users with the OIDC issuer before
+// forwarding their requests to the server
+func consoleProxyContainer(i *ispnv1.Infinispan) corev1.Container {
+	oidc := i.GetConsoleOIDC()
+	args := []string{
+		"--provider=oidc",
+		"--oidc-issuer-url=" + oidc.IssuerURL,
+		fmt.Sprintf("--http-address=0.0.0.0:%d", consts.ConsoleProxyPort),
+		fmt.Sprintf("--upstream=%s://127.0.0.1:%d/", i.GetEndpointScheme(), consts.InfinispanUserPort),
+		"--reverse-proxy=true",
+		"--skip-provider-button=true",
+		"--pass-basic-auth=false",
+	}
+	if host := i.Spec.Expose.Console.Host; host != "" {
+		args = append(args, fmt.Sprintf("--redirect-url=https://%s/oauth2/callback", host))
+	}
+	if len(oidc.EmailDomains) == 0 {
+		args = append(args, "--email-domain=*")
+	}
+	for _, domain := range oidc.EmailDomains {
+		args = append(args, "--email-domain="+domain)
+	}
+	if i.IsEncryptionEnabled() {
+		// The server certificate is issued for the Service names, not for the loopback address
+		args = append(args, "--ssl-upstream-insecure-skip-verify=true")
+	}
+
+	secretEnv := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: oidc.ClientSecretName},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	return corev1.Container{
+		Name:  ConsoleProxyContainer,
+		Image: consts.ConsoleProxyImageName,
+		Args:  args,
+		Env: []corev1.EnvVar{
+			secretEnv("OAUTH2_PROXY_CLIENT_ID", OAuth2ClientIdKey),
+			secretEnv("OAUTH2_PROXY_CLIENT_SECRET", OAuth2ClientSecretKey),
+			secretEnv("OAUTH2_PROXY_COOKIE_SECRET", ConsoleCookieSecretKey),
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          consts.ConsoleProxyPortName,
+				ContainerPort: consts.ConsoleProxyPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+	}
+}
+
+// ApplyConsoleProxy adds the console proxy sidecar to the pod when the console users are authenticated with OIDC, or
+// removes it, returning true if the pod has been changed
+func ApplyConsoleProxy(i *ispnv1.Infinispan, meta *metav1.ObjectMeta, spec *corev1.PodSpec) bool {
+	var container *corev1.Container
+	proxyHash := ""
+	if i.GetConsoleOIDC() != nil {
+		c := consoleProxyContainer(i)
+		container = &c
+		containerJSON, _ := json.Marshal(container)
+		proxyHash = hash.HashByte(containerJSON)
+	}
+	if meta.Annotations[ConsoleProxyHashAnnotation] == proxyHash {
+		return false
+	}
+
+	spec.Containers = removeContainers(spec.Containers, ConsoleProxyContainer)
+	if container != nil {
+		spec.Containers = append(spec.Containers, *container)
+	}
+	if proxyHash == "" {
+		delete(meta.Annotations, ConsoleProxyHashAnnotation)
+	} else {
+		if meta.Annotations == nil {
+			meta.Annotations = map[string]string{}
+		}
+		meta.Annotations[ConsoleProxyHashAnnotation] = proxyHash
+	}
+	return true
+}
+
+// consoleURLScheme returns the scheme the console exposed by its own Route is served with
+func consoleURLScheme(i *ispnv1.Infinispan) string {
+	if routeTLSTermination(i, consoleExposeSpec(i)) != "" {
+		return strings.ToLower(string(corev1.URISchemeHTTPS))
+	}
+	return strings.ToLower(string(corev1.URISchemeHTTP))
+}
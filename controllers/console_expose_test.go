@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	ingressv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func consoleInfinispan(oidc bool) *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec: ispnv1.InfinispanSpec{
+			Expose: &ispnv1.ExposeSpec{
+				Console: &ispnv1.ConsoleExposeSpec{Host: "console.example.com"},
+			},
+		},
+	}
+	if oidc {
+		ispn.Spec.Expose.Console.OIDC = &ispnv1.ConsoleOIDCSpec{
+			IssuerURL:        "https://sso.example.com/realms/infinispan",
+			ClientSecretName: "console-client",
+			EmailDomains:     []string{"example.com"},
+		}
+	}
+	return ispn
+}
+
+func TestValidateConsoleExpose(t *testing.T) {
+	assert.NoError(t, validateExposeEndpoints(consoleInfinispan(false)))
+	assert.NoError(t, validateExposeEndpoints(consoleInfinispan(true)))
+
+	ispn := consoleInfinispan(true)
+	ispn.Spec.Expose.Console.OIDC.IssuerURL = "sso.example.com"
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	ispn = consoleInfinispan(true)
+	ispn.Spec.Expose.Console.OIDC.ClientSecretName = ""
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	// The proxy serves plain HTTP
+	ispn = consoleInfinispan(true)
+	ispn.Spec.Expose.Console.TLSTermination = ispnv1.RouteTLSTerminationPassthrough
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	ispn = consoleInfinispan(false)
+	ispn.Spec.Expose.Console.TLSTermination = ispnv1.RouteTLSTerminationPassthrough
+	assert.Error(t, validateExposeEndpoints(ispn))
+	ispn.Spec.Expose.Console.TLSTermination = ispnv1.RouteTLSTerminationEdge
+	assert.NoError(t, validateExposeEndpoints(ispn))
+}
+
+func TestApplyConsoleProxy(t *testing.T) {
+	ispn := consoleInfinispan(true)
+	meta := &metav1.ObjectMeta{}
+	spec := &corev1.PodSpec{Containers: []corev1.Container{{Name: InfinispanContainer}}}
+
+	assert.True(t, ApplyConsoleProxy(ispn, meta, spec))
+	assert.Len(t, spec.Containers, 2)
+	proxy := spec.Containers[1]
+	assert.Equal(t, ConsoleProxyContainer, proxy.Name)
+	assert.Contains(t, proxy.Args, "--oidc-issuer-url=https://sso.example.com/realms/infinispan")
+	assert.Contains(t, proxy.Args, "--email-domain=example.com")
+	assert.Contains(t, proxy.Args, "--upstream=http://127.0.0.1:11222/")
+	assert.Equal(t, "console-client", proxy.Env[0].ValueFrom.SecretKeyRef.Name)
+	assert.NotEmpty(t, meta.Annotations[ConsoleProxyHashAnnotation])
+
+	// Unchanged spec
+	assert.False(t, ApplyConsoleProxy(ispn, meta, spec))
+
+	ispn.Spec.Expose.Console.OIDC = nil
+	assert.True(t, ApplyConsoleProxy(ispn, meta, spec))
+	assert.Len(t, spec.Containers, 1)
+	assert.NotContains(t, meta.Annotations, ConsoleProxyHashAnnotation)
+}
+
+func TestReconcileConsoleExpose(t *testing.T) {
+	ispn := consoleInfinispan(true)
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, routev1.AddToScheme(scheme))
+	assert.NoError(t, ispnv1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, ispn)
+	s := serviceRequest{
+		ServiceReconciler: &ServiceReconciler{
+			Client: c,
+			log:    ctrl.Log,
+			scheme: scheme,
+			supportedTypes: map[string]*reconcileType{
+				consts.ExternalTypeRoute:   {ObjectType: &routev1.Route{}, GroupVersion: routev1.SchemeGroupVersion, GroupVersionSupported: true},
+				consts.ExternalTypeIngress: {ObjectType: &ingressv1.Ingress{}, GroupVersion: ingressv1.SchemeGroupVersion, GroupVersionSupported: true},
+			},
+		},
+		ctx:        context.TODO(),
+		infinispan: ispn,
+	}
+
+	exposed, err := s.reconcileConsoleExpose()
+	assert.NoError(t, err)
+	assert.Len(t, exposed, 2)
+
+	name := types.NamespacedName{Namespace: ispn.Namespace, Name: ispn.GetConsoleExternalName()}
+	service := &corev1.Service{}
+	assert.NoError(t, c.Get(context.TODO(), name, service))
+	assert.Equal(t, int32(consts.ConsoleProxyPort), service.Spec.Ports[0].Port)
+
+	route := &routev1.Route{}
+	assert.NoError(t, c.Get(context.TODO(), name, route))
+	assert.Equal(t, "console.example.com", route.Spec.Host)
+	assert.Equal(t, ispn.GetConsoleExternalName(), route.Spec.To.Name)
+	assert.Equal(t, routev1.TLSTerminationEdge, route.Spec.TLS.Termination)
+	assert.Equal(t, "https", consoleURLScheme(ispn))
+
+	// Without OIDC, the Route targets the cluster Service
+	ispn = consoleInfinispan(false)
+	ispn.Name = "other-infinispan"
+	s.infinispan = ispn
+	exposed, err = s.reconcileConsoleExpose()
+	assert.NoError(t, err)
+	assert.Len(t, exposed, 1)
+	route = &routev1.Route{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: ispn.Namespace, Name: ispn.GetConsoleExternalName()}, route))
+	assert.Equal(t, ispn.Name, route.Spec.To.Name)
+	assert.Nil(t, route.Spec.TLS)
+	assert.Equal(t, "http", consoleURLScheme(ispn))
+}
@@ -16,6 +16,15 @@ var (
 	// InitContainerImageName allows a custom initContainer image to be used
 	InitContainerImageName = GetEnvWithDefault("INITCONTAINER_IMAGE", "registry.access.redhat.com/ubi8-micro")
 
+	// BackupS3ImageName, BackupGCSImageName and BackupAzureImageName allow custom images to be used to upload and
+	// download backup archives to and from object storage
+	BackupS3ImageName    = GetEnvWithDefault("BACKUP_S3_IMAGE", "docker.io/amazon/aws-cli")
+	BackupGCSImageName   = GetEnvWithDefault("BACKUP_GCS_IMAGE", "gcr.io/google.com/cloudsdktool/cloud-sdk:slim")
+	BackupAzureImageName = GetEnvWithDefault("BACKUP_AZURE_IMAGE", "mcr.microsoft.com/azure-cli")
+
+	// ConsoleProxyImageName allows a custom image of the proxy authenticating the console users with OIDC
+	ConsoleProxyImageName = GetEnvWithDefault("CONSOLE_PROXY_IMAGE", "quay.io/oauth2-proxy/oauth2-proxy:v7.6.0")
+
 	// JGroupsDiagnosticsFlag is used to enable traces for JGroups
 	JGroupsDiagnosticsFlag = strings.ToUpper(GetEnvWithDefault("JGROUPS_DIAGNOSTICS", "FALSE"))
 
@@ -46,19 +55,25 @@ const (
 	// DefaultDeveloperUser users to access the cluster rest API
 	DefaultDeveloperUser = "developer"
 	// DefaultCacheName default cache name for the CacheService
-	DefaultCacheName         = "default"
-	AdminUsernameKey         = "username"
-	AdminPasswordKey         = "password"
-	InfinispanAdminPort      = 11223
-	InfinispanAdminPortName  = "infinispan-adm"
-	InfinispanUserPortName   = "infinispan"
-	InfinispanPingPort       = 8888
-	InfinispanPingPortName   = "ping"
-	InfinispanUserPort       = 11222
-	CrossSitePort            = 7900
-	CrossSitePortName        = "xsite"
-	StatefulSetPodLabel      = "app.kubernetes.io/created-by"
-	StaticCrossSiteUriSchema = "infinispan+xsite"
+	DefaultCacheName            = "default"
+	AdminUsernameKey            = "username"
+	AdminPasswordKey            = "password"
+	InfinispanAdminPort         = 11223
+	InfinispanAdminPortName     = "infinispan-adm"
+	InfinispanUserPortName      = "infinispan"
+	InfinispanPingPort          = 8888
+	InfinispanPingPortName      = "ping"
+	InfinispanUserPort          = 11222
+	InfinispanMemcachedPort     = 11221
+	InfinispanMemcachedPortName = "infinispan-mc"
+	InfinispanRespPort          = 6379
+	InfinispanRespPortName      = "infinispan-resp"
+	CrossSitePort               = 7900
+	CrossSitePortName           = "xsite"
+	ConsoleProxyPort            = 4180
+	ConsoleProxyPortName        = "console-proxy"
+	StatefulSetPodLabel         = "app.kubernetes.io/created-by"
+	StaticCrossSiteUriSchema    = "infinispan+xsite"
 	// DefaultCacheManagerName default cache manager name used for cross site
 	DefaultCacheManagerName                 = "default"
 	CacheServiceFixedMemoryXmxMb            = 200
@@ -69,24 +84,57 @@ const (
 	CacheServiceMaxRamMb                    = CacheServiceFixedMemoryXmxMb + CacheServiceJvmNativeMb
 	CacheServiceJavaOptions                 = "-Xmx%dM -Xms%dM -XX:MaxRAM=%dM -Dsun.zip.disableMemoryMapping=true -XX:+UseSerialGC -XX:MinHeapFreeRatio=%d -XX:MaxHeapFreeRatio=%d %s"
 	CacheServiceNativeJavaOptions           = "-Xmx%dM -Xms%dM -Dsun.zip.disableMemoryMapping=true %s"
+	// DefaultMemoryHeadroomPercent the percentage of the container memory left to the JVM native memory by the Auto memory policy
+	DefaultMemoryHeadroomPercent = 25
+	// AutoMemoryHeapPercent the percentage of the remaining container memory assigned to the heap by the Auto memory
+	// policy, the rest being the off-heap limit
+	AutoMemoryHeapPercent = 75
+	AutoMemoryJavaOptions = "-Xmx%dM -Xms%dM -XX:MaxDirectMemorySize=%dM"
+	// ServerDefaultHeapPercent the percentage of the container memory the server image assigns to the heap when the JVM
+	// options don't size it
+	ServerDefaultHeapPercent = 50
+	// IPv6JavaOptions makes the server and JGroups bind to the IPv6 address of the pod
+	IPv6JavaOptions = "-Djava.net.preferIPv6Addresses=true"
 
 	NativeImageMarker           = "native"
 	GeneratedSecretSuffix       = "generated-secret"
 	InfinispanFinalizer         = "finalizer.infinispan.org"
+	CacheFinalizer              = "finalizer.infinispan.org/cache"
+	ZeroCapacityFinalizer       = "finalizer.infinispan.org/zero-capacity"
 	SiteServiceTemplate         = "%v-site"
 	ServerConfigRoot            = "/etc/config"
 	ServerEncryptRoot           = "/etc/encrypt"
 	ServerEncryptTruststoreRoot = ServerEncryptRoot + "/truststore"
 	ServerEncryptKeystoreRoot   = ServerEncryptRoot + "/keystore"
+	ServerEncryptTransportRoot  = ServerEncryptRoot + "/transport"
+	ServerEncryptMetricsRoot    = ServerEncryptRoot + "/metrics"
+	ServerEncryptXSiteRoot      = ServerEncryptRoot + "/xsite"
 	ServerSecurityRoot          = "/etc/security"
 	ServerConfigFilename        = "infinispan.yaml"
 	ServerConfigPath            = ServerConfigRoot + "/" + ServerConfigFilename
+	ServerConfigOverlayFilename = "infinispan-config.yaml"
 	ServerIdentitiesFilename    = "identities.yaml"
 	CliPropertiesFilename       = "cli.properties"
 	ServerAdminIdentitiesRoot   = ServerSecurityRoot + "/admin"
 	ServerAdminIdentitiesPath   = ServerAdminIdentitiesRoot + "/" + ServerIdentitiesFilename
 	ServerUserIdentitiesRoot    = ServerSecurityRoot + "/user"
 	ServerUserIdentitiesPath    = ServerUserIdentitiesRoot + "/" + ServerIdentitiesFilename
+	ServerLdapRoot              = ServerSecurityRoot + "/ldap"
+	ServerLdapCaRoot            = ServerSecurityRoot + "/ldap-ca"
+	ServerOAuth2Root            = ServerSecurityRoot + "/oauth2"
+	ServerOAuth2CaRoot          = ServerSecurityRoot + "/oauth2-ca"
+	ServerTracingCaRoot         = ServerSecurityRoot + "/tracing-ca"
+	ServerCliPath               = "/opt/infinispan/bin/cli.sh"
+	ServerUsersFilename         = "users.properties"
+	ServerAdminUsersFilename    = "cli-admin-users.properties"
+
+	// ServerZeroCapacityConfigFilename is the key of the configuration of the zero-capacity pods in the cluster ConfigMap
+	ServerZeroCapacityConfigFilename = "infinispan-zero-capacity.yaml"
+	ServerZeroCapacityConfigPath     = ServerConfigRoot + "/" + ServerZeroCapacityConfigFilename
+	// ServerDataMigrationConfigFilename is the key of the configuration of the staging cluster of a DataMigration upgrade
+	// in the cluster ConfigMap
+	ServerDataMigrationConfigFilename = "infinispan-data-migration.yaml"
+	ServerDataMigrationConfigPath     = ServerConfigRoot + "/" + ServerDataMigrationConfigFilename
 
 	ServerHTTPBasePath         = "rest/v2"
 	ServerHTTPCacheManagerPath = ServerHTTPBasePath + "/cache-managers/" + DefaultCacheManagerName
@@ -95,8 +143,12 @@ const (
 	ServerHTTPClusterStop      = ServerHTTPBasePath + "/cluster?action=stop"
 	ServerHTTPHealthStatusPath = ServerHTTPHealthPath + "/status"
 	ServerHTTPLoggersPath      = ServerHTTPBasePath + "/logging/loggers"
+	ServerHTTPThreadsPath      = ServerHTTPBasePath + "/server/threads"
+	ServerHTTPConfigPath       = ServerHTTPBasePath + "/server/config"
 	ServerHTTPModifyLoggerPath = ServerHTTPLoggersPath + "/%s?level=%s"
 	ServerHTTPXSitePath        = ServerHTTPCacheManagerPath + "/x-site/backups"
+	ServerHTTPXSitePushPath    = ServerHTTPBasePath + "/caches/%s/x-site/push-state-status"
+	ServerHTTPProtobufPath     = ServerHTTPBasePath + "/caches/___protobuf_metadata"
 
 	EncryptTruststoreKey         = "truststore.p12"
 	EncryptTruststorePasswordKey = "truststore-password"
@@ -107,7 +159,23 @@ const (
 				<memory>
 					<off-heap size="%d" eviction="MEMORY" strategy="REMOVE"/>
 				</memory>
-				<partition-handling when-split="ALLOW_READ_WRITES" merge-policy="REMOVE_ALL" />
+				<partition-handling when-split="%s" merge-policy="%s" />
+			</distributed-cache>
+		</cache-container>
+	</infinispan>`
+
+	IndexedCacheTemplate = `<infinispan>
+		<cache-container>
+			<distributed-cache name="%v" mode="SYNC" owners="%d" statistics="true">
+				<encoding media-type="application/x-protostream"/>
+				<memory>
+					<off-heap size="%d" eviction="MEMORY" strategy="REMOVE"/>
+				</memory>
+				<indexing enabled="true" storage="local-heap">
+					<indexed-entities>%s
+					</indexed-entities>
+				</indexing>
+				<partition-handling when-split="%s" merge-policy="%s" />
 			</distributed-cache>
 		</cache-container>
 	</infinispan>`
@@ -120,19 +188,35 @@ const (
 	DefaultRequeueOnWrongSpec = 5 * time.Second
 	//DefaultWaitOnCluster delay for the Infinispan cluster wait if it not created while Cache creation
 	DefaultWaitOnCluster = 10 * time.Second
+	// DefaultCanaryTimeout is the time a Canary upgrade waits for the cluster to be healthy after upgrading a pod
+	DefaultCanaryTimeout = 10 * time.Minute
 	// DefaultWaitOnCreateResource delay for wait until resource (Secret, ConfigMap, Service) is created
 	DefaultWaitOnCreateResource = 2 * time.Second
 	// DefaultLongWaitOnCreateResource delay for wait until non core resource is create (only Grafana CRD atm)
 	DefaultLongWaitOnCreateResource = 60 * time.Second
 	//DefaultWaitClusterNotWellFormed wait delay until cluster is not well formed
 	DefaultWaitClusterNotWellFormed = 15 * time.Second
+	// DefaultCacheStatisticsRefresh delay between refreshes of the Cache CR statistics
+	DefaultCacheStatisticsRefresh = 60 * time.Second
+	// DefaultCounterValueRefresh delay between refreshes of the Counter CR value
+	DefaultCounterValueRefresh = 60 * time.Second
+	// DefaultCachePolicyPollPeriod delay between scans of the caches of a cluster for caches matching a CachePolicy
+	DefaultCachePolicyPollPeriod = 30 * time.Second
+	// DefaultLogAlertsPeriod delay between scans of the server logs for alerts
+	DefaultLogAlertsPeriod = 60 * time.Second
+	// DefaultLogAlertsWindow period of the server logs scanned for alerts
+	DefaultLogAlertsWindow = 300 * time.Second
 )
 
 const (
-	ExternalTypeService = "Service"
-	ExternalTypeRoute   = "Route"
-	ExternalTypeIngress = "Ingress"
-	ServiceMonitorType  = "ServiceMonitor"
+	ExternalTypeService     = "Service"
+	ExternalTypeRoute       = "Route"
+	ExternalTypeIngress     = "Ingress"
+	ExternalTypeTLSRoute    = "TLSRoute"
+	ServiceMonitorType      = "ServiceMonitor"
+	GrafanaDashboardType    = "GrafanaDashboard"
+	PodDisruptionBudgetType = "PodDisruptionBudget"
+	NetworkPolicyType       = "NetworkPolicy"
 )
 
 const DefaultKubeConfig = "~/.kube/config"
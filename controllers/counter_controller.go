@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	EventReasonCounterCreated           = "CounterCreated"
+	EventReasonCounterConfigurationDiff = "CounterConfigurationDiffers"
+)
+
+// CounterReconciler reconciles a Counter object
+type CounterReconciler struct {
+	client.Client
+	log        logr.Logger
+	scheme     *runtime.Scheme
+	kubernetes *kube.Kubernetes
+	eventRec   record.EventRecorder
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CounterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Client = mgr.GetClient()
+	r.log = ctrl.Log.WithName("controllers").WithName("Counter")
+	r.scheme = mgr.GetScheme()
+	r.kubernetes = kube.NewKubernetesFromController(mgr)
+	r.eventRec = mgr.GetEventRecorderFor("counter-controller")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infinispanv2alpha1.Counter{}).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infinispan.org,resources=counters;counters/status;counters/finalizers,verbs=get;list;watch;create;update;patch
+
+func (r *CounterReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("+++++ Reconciling Counter.")
+	defer reqLogger.Info("----- End Reconciling Counter.")
+
+	// Fetch the Counter instance
+	instance := &infinispanv2alpha1.Counter{}
+	if err := r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.Info("Counter resource not found. Ignoring it since counter deletion is not supported")
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if err := validateCounter(instance); err != nil {
+		reqLogger.Error(err, "Error creating counter")
+		return reconcile.Result{}, err
+	}
+
+	// Fetch the Infinispan cluster info
+	ispnInstance := &infinispanv1.Infinispan{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterName}, ispnInstance); err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.Info(fmt.Sprintf("Infinispan cluster %s not found", instance.Spec.ClusterName))
+			return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// Cluster must be well formed
+	if !ispnInstance.IsWellFormed() {
+		reqLogger.Info(fmt.Sprintf("Infinispan cluster %s not well formed", ispnInstance.Name))
+		return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+	}
+	podList, err := PodList(ispnInstance, r.kubernetes, ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to list pods")
+		return reconcile.Result{}, err
+	} else if len(podList.Items) == 0 {
+		reqLogger.Info("No Infinispan pods found")
+		return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+	}
+	podName := podList.Items[0].Name
+
+	cluster, err := NewCluster(ispnInstance, r.kubernetes, ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	statusUpdate := false
+	expected := counterConfiguration(instance)
+	actual, err := cluster.GetCounterConfiguration(instance.GetCounterName(), podName)
+	if err != nil {
+		reqLogger.Error(err, "Error validating counter exist")
+		return reconcile.Result{}, err
+	}
+	if actual == nil {
+		reqLogger.Info(fmt.Sprintf("Counter %s doesn't exist, create it", instance.GetCounterName()))
+		if err := cluster.CreateCounter(instance.GetCounterName(), expected, podName); err != nil {
+			reqLogger.Error(err, "Error creating counter")
+			return reconcile.Result{}, err
+		}
+		r.eventRec.Event(instance, corev1.EventTypeNormal, EventReasonCounterCreated, fmt.Sprintf("Counter %s created", instance.GetCounterName()))
+		statusUpdate = instance.SetCondition(infinispanv2alpha1.CounterConditionReady, metav1.ConditionTrue, "")
+	} else if !counterConfigurationMatches(expected, *actual) {
+		message := "The configuration of the counter cannot be changed, delete the counter from the cluster to recreate it with the spec"
+		if instance.SetCondition(infinispanv2alpha1.CounterConditionReady, metav1.ConditionFalse, message) {
+			r.eventRec.Event(instance, corev1.EventTypeWarning, EventReasonCounterConfigurationDiff, message)
+			statusUpdate = true
+		}
+	} else {
+		statusUpdate = instance.SetCondition(infinispanv2alpha1.CounterConditionReady, metav1.ConditionTrue, "")
+	}
+
+	if value, err := cluster.GetCounterValue(instance.GetCounterName(), podName); err != nil {
+		reqLogger.Error(err, "Unable to retrieve the counter value")
+	} else if !reflect.DeepEqual(instance.Status.Value, &value) {
+		instance.Status.Value = &value
+		statusUpdate = true
+	}
+	if statusUpdate {
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Unable to update Counter %s status", instance.Name))
+			return reconcile.Result{}, err
+		}
+	}
+	// Requeue to keep the value up to date
+	return ctrl.Result{RequeueAfter: constants.DefaultCounterValueRefresh}, nil
+}
+
+// validateCounter verifies that the bounds are only set for strong counters and contain the initial value
+func validateCounter(counter *infinispanv2alpha1.Counter) error {
+	spec := counter.Spec
+	if counter.GetType() == infinispanv2alpha1.CounterWeak {
+		if spec.LowerBound != nil || spec.UpperBound != nil {
+			return fmt.Errorf("lowerBound and upperBound are only supported by strong counters")
+		}
+		return nil
+	}
+	if spec.LowerBound != nil && spec.InitialValue < *spec.LowerBound {
+		return fmt.Errorf("initialValue (%d) must not be lower than lowerBound (%d)", spec.InitialValue, *spec.LowerBound)
+	}
+	if spec.UpperBound != nil && spec.InitialValue > *spec.UpperBound {
+		return fmt.Errorf("initialValue (%d) must not be greater than upperBound (%d)", spec.InitialValue, *spec.UpperBound)
+	}
+	return nil
+}
+
+// counterConfiguration returns the server configuration of the counter spec
+func counterConfiguration(counter *infinispanv2alpha1.Counter) ispn.CounterConfiguration {
+	attributes := &ispn.CounterAttributes{
+		InitialValue: counter.Spec.InitialValue,
+		Storage:      string(counter.GetStorage()),
+	}
+	if counter.GetType() == infinispanv2alpha1.CounterWeak {
+		return ispn.CounterConfiguration{Weak: attributes}
+	}
+	attributes.LowerBound = counter.Spec.LowerBound
+	attributes.UpperBound = counter.Spec.UpperBound
+	return ispn.CounterConfiguration{Strong: attributes}
+}
+
+// counterConfigurationMatches compares the configurations, the server reports the bounds of unbounded counters as the
+// extreme values of a long
+func counterConfigurationMatches(expected, actual ispn.CounterConfiguration) bool {
+	normalize := func(attributes *ispn.CounterAttributes) *ispn.CounterAttributes {
+		if attributes == nil {
+			return nil
+		}
+		normalized := *attributes
+		if normalized.LowerBound != nil && *normalized.LowerBound == math.MinInt64 {
+			normalized.LowerBound = nil
+		}
+		if normalized.UpperBound != nil && *normalized.UpperBound == math.MaxInt64 {
+			normalized.UpperBound = nil
+		}
+		return &normalized
+	}
+	return reflect.DeepEqual(normalize(expected.Strong), normalize(actual.Strong)) && reflect.DeepEqual(normalize(expected.Weak), normalize(actual.Weak))
+}
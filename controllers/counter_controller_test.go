@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateCounter(t *testing.T) {
+	counter := &v2alpha1.Counter{Spec: v2alpha1.CounterSpec{InitialValue: 5, LowerBound: pointer.Int64Ptr(0), UpperBound: pointer.Int64Ptr(10)}}
+	assert.NoError(t, validateCounter(counter))
+
+	counter.Spec.InitialValue = 11
+	assert.EqualError(t, validateCounter(counter), "initialValue (11) must not be greater than upperBound (10)")
+	counter.Spec.InitialValue = -1
+	assert.EqualError(t, validateCounter(counter), "initialValue (-1) must not be lower than lowerBound (0)")
+
+	counter.Spec.Type = v2alpha1.CounterWeak
+	assert.Error(t, validateCounter(counter))
+	counter.Spec.LowerBound, counter.Spec.UpperBound = nil, nil
+	assert.NoError(t, validateCounter(counter))
+}
+
+func TestCounterConfiguration(t *testing.T) {
+	counter := &v2alpha1.Counter{Spec: v2alpha1.CounterSpec{InitialValue: 1, UpperBound: pointer.Int64Ptr(10)}}
+	strong := counterConfiguration(counter)
+	assert.Nil(t, strong.Weak)
+	assert.Equal(t, ispn.CounterAttributes{InitialValue: 1, Storage: "VOLATILE", UpperBound: pointer.Int64Ptr(10)}, *strong.Strong)
+
+	// The server reports the missing bounds as the extreme values of a long
+	actual := ispn.CounterConfiguration{Strong: &ispn.CounterAttributes{InitialValue: 1, Storage: "VOLATILE",
+		LowerBound: pointer.Int64Ptr(math.MinInt64), UpperBound: pointer.Int64Ptr(10)}}
+	assert.True(t, counterConfigurationMatches(strong, actual))
+	actual.Strong.UpperBound = pointer.Int64Ptr(20)
+	assert.False(t, counterConfigurationMatches(strong, actual))
+
+	counter.Spec.Type = v2alpha1.CounterWeak
+	counter.Spec.Storage = v2alpha1.CounterPersistent
+	weak := counterConfiguration(counter)
+	assert.Nil(t, weak.Strong)
+	assert.Equal(t, ispn.CounterAttributes{InitialValue: 1, Storage: "PERSISTENT"}, *weak.Weak)
+	assert.False(t, counterConfigurationMatches(weak, actual))
+}
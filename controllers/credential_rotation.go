@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"fmt"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/security"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// CredentialRotationAnnotation records the spec.security.credentialRotation value of the credentials held in the Secrets
+	CredentialRotationAnnotation = "infinispan.org/credential-rotation"
+	// CredentialRotationAppliedAnnotation records the spec.security.credentialRotation value applied to the running pods
+	CredentialRotationAppliedAnnotation = "infinispan.org/credential-rotation-applied"
+	// PodIdentitiesHashAnnotation records the hash of the identities the pods were started with
+	PodIdentitiesHashAnnotation = "infinispan.org/pod-identities-hash"
+	// RotatedIdentitiesHashAnnotation records the hash of the identities applied to the running pods by a rotation
+	RotatedIdentitiesHashAnnotation = "infinispan.org/rotated-identities-hash"
+
+	EventReasonCredentialsRotated = "CredentialsRotated"
+)
+
+// identitiesHash returns the hash of the identities held in the Secret, used to restart the pods when they change.
+// Identities rotated at runtime are already applied to the running pods, so the hash they were started with is kept
+func identitiesHash(secret *corev1.Secret) string {
+	identitiesHash := hash.HashByte(secret.Data[consts.ServerIdentitiesFilename])
+	if podHash, ok := secret.Annotations[PodIdentitiesHashAnnotation]; ok && secret.Annotations[RotatedIdentitiesHashAnnotation] == identitiesHash {
+		return podHash
+	}
+	return identitiesHash
+}
+
+// setRotatedIdentities replaces the identities held in the Secret without changing its identitiesHash
+func setRotatedIdentities(secret *corev1.Secret, identities []byte) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[PodIdentitiesHashAnnotation] = identitiesHash(secret)
+	secret.Annotations[RotatedIdentitiesHashAnnotation] = hash.HashByte(identities)
+	secret.Data[consts.ServerIdentitiesFilename] = identities
+}
+
+// isUserCredentialRotationSupported verifies that the user identities are generated by the operator, user provided
+// identities are rotated by updating their Secret
+func isUserCredentialRotationSupported(i *ispnv1.Infinispan) bool {
+	return i.IsAuthenticationEnabled() && i.GetEndpointSecretSource() == ispnv1.EndpointSecretSourceSecret && i.IsGeneratedSecret()
+}
+
+// reconcileCredentialRotation generates new credentials when spec.security.credentialRotation changes and applies
+// them to the running pods. The credentials are stored before being applied, so that pods starting in the meantime
+// load them from the Secrets and a failed update can be retried
+func (s *secretRequest) reconcileCredentialRotation() (reconcile.Result, error) {
+	i := s.infinispan
+	rotation := i.Spec.Security.CredentialRotation
+	if rotation == "" {
+		return reconcile.Result{}, nil
+	}
+
+	adminSecret, err := s.getSecret(i.GetAdminSecretName())
+	if adminSecret == nil || err != nil {
+		return reconcile.Result{}, err
+	}
+	if adminSecret.Annotations[CredentialRotationAppliedAnnotation] == rotation {
+		return reconcile.Result{}, nil
+	}
+
+	var userSecret *corev1.Secret
+	if isUserCredentialRotationSupported(i) {
+		if userSecret, err = s.getSecret(i.GetSecretName()); userSecret == nil || err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if adminSecret.Annotations[CredentialRotationAnnotation] != rotation {
+		// The user identities are rotated first, the admin Secret annotation marks the rotated credentials as stored
+		if userSecret != nil {
+			identities, err := security.RotatePasswords(userSecret.Data[consts.ServerIdentitiesFilename])
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			setRotatedIdentities(userSecret, identities)
+			if err := s.Client.Update(s.ctx, userSecret); err != nil {
+				return reconcile.Result{}, fmt.Errorf("unable to rotate the user identities: %w", err)
+			}
+		}
+
+		password, err := security.NewPassword()
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		identities, err := security.CreateIdentitiesFor(consts.DefaultOperatorUser, password)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		s.addCliProperties(adminSecret, password)
+		s.addServiceMonitorProperties(adminSecret, password)
+		setRotatedIdentities(adminSecret, identities)
+		adminSecret.Annotations[CredentialRotationAnnotation] = rotation
+		if err := s.Client.Update(s.ctx, adminSecret); err != nil {
+			return reconcile.Result{}, fmt.Errorf("unable to rotate the operator identities: %w", err)
+		}
+		s.reqLogger.Info("Credentials rotated", "credentialRotation", rotation)
+	}
+
+	podList, err := PodList(i, s.kubernetes, s.ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	cluster, err := NewCluster(i, s.kubernetes, s.ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	var userIdentities []byte
+	if userSecret != nil {
+		userIdentities = userSecret.Data[consts.ServerIdentitiesFilename]
+	}
+	if err := applyRotatedCredentials(cluster, podList.Items, adminSecret.Data[consts.ServerIdentitiesFilename], userIdentities); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	adminSecret.Annotations[CredentialRotationAppliedAnnotation] = rotation
+	if err := s.Client.Update(s.ctx, adminSecret); err != nil {
+		return reconcile.Result{}, err
+	}
+	s.eventRec.Event(i, corev1.EventTypeNormal, EventReasonCredentialsRotated, fmt.Sprintf("Credentials rotated for credentialRotation '%s'", rotation))
+	return reconcile.Result{}, nil
+}
+
+// applyRotatedCredentials updates the passwords of the running pods. Pods that are not running load the identities
+// from the Secrets when they start
+func applyRotatedCredentials(cluster ispn.ClusterInterface, pods []corev1.Pod, adminIdentities, userIdentities []byte) error {
+	usersFiles := map[string][]byte{consts.ServerAdminUsersFilename: adminIdentities}
+	if userIdentities != nil {
+		usersFiles[consts.ServerUsersFilename] = userIdentities
+	}
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, usersFile := range []string{consts.ServerAdminUsersFilename, consts.ServerUsersFilename} {
+			descriptor, ok := usersFiles[usersFile]
+			if !ok {
+				continue
+			}
+			identities, err := security.ParseIdentities(descriptor)
+			if err != nil {
+				return err
+			}
+			for _, credentials := range identities.Credentials {
+				if err := cluster.UpdateUserPassword(usersFile, credentials.Username, credentials.Password, pod.Name); err != nil {
+					return fmt.Errorf("unable to apply the rotated credentials to pod '%s': %w", pod.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
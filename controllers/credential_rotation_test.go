@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/security"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+// passwordCluster records the passwords updated on each pod, the remaining methods are not expected to be called
+type passwordCluster struct {
+	ispn.ClusterInterface
+	passwords map[string]string
+}
+
+func (c *passwordCluster) UpdateUserPassword(usersFile, username, password, podName string) error {
+	c.passwords[podName+"/"+usersFile+"/"+username] = password
+	return nil
+}
+
+func identitiesSecret(identities []byte) *corev1.Secret {
+	return &corev1.Secret{Data: map[string][]byte{consts.ServerIdentitiesFilename: identities}}
+}
+
+func TestIdentitiesHash(t *testing.T) {
+	identities, _ := security.CreateIdentitiesFor(consts.DefaultDeveloperUser, "password")
+	secret := identitiesSecret(identities)
+	podHash := hash.HashByte(identities)
+	assert.Equal(t, podHash, identitiesHash(secret))
+
+	// Rotated identities keep the hash the pods were started with
+	rotated, err := security.RotatePasswords(identities)
+	assert.NoError(t, err)
+	setRotatedIdentities(secret, rotated)
+	assert.Equal(t, rotated, secret.Data[consts.ServerIdentitiesFilename])
+	assert.Equal(t, podHash, identitiesHash(secret))
+
+	rotatedTwice, err := security.RotatePasswords(rotated)
+	assert.NoError(t, err)
+	setRotatedIdentities(secret, rotatedTwice)
+	assert.Equal(t, podHash, identitiesHash(secret))
+
+	// Identities changed by the user restart the pods
+	changed, _ := security.CreateIdentitiesFor(consts.DefaultDeveloperUser, "changed")
+	secret.Data[consts.ServerIdentitiesFilename] = changed
+	assert.Equal(t, hash.HashByte(changed), identitiesHash(secret))
+}
+
+func TestRotatePasswords(t *testing.T) {
+	identities, _ := security.CreateIdentitiesFor(consts.DefaultDeveloperUser, "password")
+	rotated, err := security.RotatePasswords(identities)
+	assert.NoError(t, err)
+	parsed, err := security.ParseIdentities(rotated)
+	assert.NoError(t, err)
+	assert.Len(t, parsed.Credentials, 1)
+	assert.Equal(t, consts.DefaultDeveloperUser, parsed.Credentials[0].Username)
+	assert.Equal(t, []string{"admin"}, parsed.Credentials[0].Roles)
+	assert.NotEqual(t, "password", parsed.Credentials[0].Password)
+	assert.Len(t, parsed.Credentials[0].Password, 16)
+}
+
+func TestApplyRotatedCredentials(t *testing.T) {
+	adminIdentities, _ := security.CreateIdentitiesFor(consts.DefaultOperatorUser, "operator-password")
+	userIdentities, _ := security.CreateIdentitiesFor(consts.DefaultDeveloperUser, "developer-password")
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan-0"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan-1"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+	}
+
+	cluster := &passwordCluster{passwords: map[string]string{}}
+	assert.NoError(t, applyRotatedCredentials(cluster, pods, adminIdentities, userIdentities))
+	assert.Equal(t, map[string]string{
+		"example-infinispan-0/cli-admin-users.properties/operator": "operator-password",
+		"example-infinispan-0/users.properties/developer":          "developer-password",
+	}, cluster.passwords)
+
+	cluster = &passwordCluster{passwords: map[string]string{}}
+	assert.NoError(t, applyRotatedCredentials(cluster, pods, adminIdentities, nil))
+	assert.Equal(t, map[string]string{"example-infinispan-0/cli-admin-users.properties/operator": "operator-password"}, cluster.passwords)
+}
+
+func TestIsUserCredentialRotationSupported(t *testing.T) {
+	i := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan"}}
+	i.Spec.Security.EndpointSecretName = i.GenerateSecretName()
+	assert.True(t, isUserCredentialRotationSupported(i))
+
+	i.Spec.Security.EndpointSecretName = "connect-secret"
+	assert.False(t, isUserCredentialRotationSupported(i))
+
+	i.Spec.Security.EndpointSecretName = i.GenerateSecretName()
+	i.Spec.Security.EndpointAuthentication = pointer.BoolPtr(false)
+	assert.False(t, isUserCredentialRotationSupported(i))
+}
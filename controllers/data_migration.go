@@ -0,0 +1,520 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/security"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	EventReasonDataMigrationFailed   = "DataMigrationFailed"
+	EventReasonDataMigrationMismatch = "DataMigrationEntriesMismatch"
+	EventReasonDataMigrationComplete = "DataMigrationCompleted"
+	EventReasonDataMigrationRetry    = "DataMigrationRetry"
+	// MaxDataMigrationAttempts bounds the number of times the caches are migrated in a stage when their entries don't match
+	MaxDataMigrationAttempts = 3
+)
+
+// validateDataMigration verifies that the staging cluster of a DataMigration upgrade can connect to the HotRod endpoint
+// of the cluster with the identities of the cluster
+func validateDataMigration(i *infinispanv1.Infinispan) error {
+	if !i.IsDataMigrationUpgrade() {
+		return nil
+	}
+	if i.IsEncryptionEnabled() {
+		return fmt.Errorf("infinispan.spec.upgrades.type=%s cannot be combined with endpoint encryption", infinispanv1.UpgradeTypeDataMigration)
+	}
+	if i.IsAuthenticationEnabled() && i.GetEndpointSecretSource() == infinispanv1.EndpointSecretSourceVault {
+		return fmt.Errorf("infinispan.spec.upgrades.type=%s requires the identities to be held in a Secret", infinispanv1.UpgradeTypeDataMigration)
+	}
+	return nil
+}
+
+// migrateToStaging starts the staging cluster of a DataMigration upgrade with the new image and migrates the caches of
+// the cluster to it. A nil result means that the entries of the persisted caches have been verified and that the old
+// StatefulSet can be retired
+func (r *infinispanRequest) migrateToStaging(statefulSet *appsv1.StatefulSet, configMap *corev1.ConfigMap, podList *corev1.PodList, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	migration := infinispan.Status.DataMigration
+
+	if migration != nil && migration.Stage == infinispanv1.DataMigrationFailed {
+		// The staging cluster of a failed migration is retained for a manual recovery and blocks further upgrades until removed
+		err := r.Client.Get(r.ctx, types.NamespacedName{Namespace: infinispan.Namespace, Name: infinispan.GetDataMigrationName()}, &appsv1.StatefulSet{})
+		if err == nil {
+			r.reqLogger.Info("upgrade blocked by the failed data migration, remove the staging StatefulSet to continue", "statefulSet", infinispan.GetDataMigrationName())
+			return &ctrl.Result{RequeueAfter: consts.DefaultLongWaitOnCreateResource}, nil
+		}
+		if !errors.IsNotFound(err) {
+			return &ctrl.Result{}, err
+		}
+		if err := r.deleteDataMigrationResources(); err != nil {
+			return &ctrl.Result{}, err
+		}
+		if err := r.update(func() {
+			infinispan.Status.DataMigration = nil
+			infinispan.RemoveCondition(infinispanv1.ConditionDataMigrationFailed)
+		}); err != nil {
+			return &ctrl.Result{}, err
+		}
+		migration = nil
+	}
+
+	if migration == nil {
+		r.reqLogger.Info("starting the staging cluster of the data migration", "statefulSet", infinispan.GetDataMigrationName())
+		if err := r.update(func() {
+			infinispan.Status.DataMigration = &infinispanv1.DataMigrationStatus{Stage: infinispanv1.DataMigrationStaging}
+		}); err != nil {
+			return &ctrl.Result{}, err
+		}
+		migration = infinispan.Status.DataMigration
+	}
+
+	switch migration.Stage {
+	case infinispanv1.DataMigrationStaging:
+		if ready, err := r.reconcileStagingCluster(statefulSet, configMap); err != nil {
+			r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonDataMigrationFailed, err.Error())
+			return &ctrl.Result{}, err
+		} else if !ready {
+			r.reqLogger.Info("waiting for the staging cluster of the data migration to be ready")
+			return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+		}
+		if err := r.update(func() {
+			infinispan.Status.DataMigration.Stage = infinispanv1.DataMigrationToStaging
+		}); err != nil {
+			return &ctrl.Result{}, err
+		}
+	case infinispanv1.DataMigrationToStaging:
+	default:
+		// The entries have been verified, the old StatefulSet is being retired
+		return nil, nil
+	}
+
+	stagingPod, err := r.stagingPod()
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if stagingPod == "" {
+		r.reqLogger.Info("waiting for a pod of the staging cluster of the data migration to be ready")
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+	source, err := r.dataMigrationSource(infinispan.GetServiceName())
+	if err != nil {
+		return &ctrl.Result{}, r.failDataMigration(err.Error())
+	}
+	migrated, err := migrateCaches(cluster, podList.Items[0].Name, stagingPod, source)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	// The staging cluster must hold all the entries of the cluster, which fails when they are written while migrating
+	var mismatches []string
+	entries := make(map[string]int, len(migrated))
+	for cache, m := range migrated {
+		if m.sourceEntries != m.targetEntries {
+			mismatches = append(mismatches, fmt.Sprintf("%s (expected %d, found %d)", cache, m.sourceEntries, m.targetEntries))
+			// The cache is migrated again from scratch, as the entries removed from the cluster are kept by the staging cluster
+			if err := cluster.DeleteCache(cache, stagingPod); err != nil {
+				return &ctrl.Result{}, err
+			}
+		}
+		entries[cache] = m.targetEntries
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return r.retryDataMigration(mismatches)
+	}
+	r.reqLogger.Info("caches migrated to the staging cluster, retiring the old StatefulSet", "caches", len(entries))
+	return nil, r.update(func() {
+		infinispan.Status.DataMigration.Stage = infinispanv1.DataMigrationUpgrade
+		infinispan.Status.DataMigration.CacheEntries = entries
+		infinispan.Status.DataMigration.Attempts = 0
+	})
+}
+
+// migrateFromStaging migrates the caches of the staging cluster to the upgraded cluster, verifying that the entries of
+// each persisted cache migrated to the staging cluster have been migrated, and removes the staging cluster
+func (r *infinispanRequest) migrateFromStaging(podList *corev1.PodList, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	migration := infinispan.Status.DataMigration
+
+	if migration.Stage == infinispanv1.DataMigrationUpgrade {
+		if err := r.update(func() {
+			infinispan.Status.DataMigration.Stage = infinispanv1.DataMigrationFromStaging
+		}); err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	stagingPod, err := r.stagingPod()
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if stagingPod == "" {
+		msg := fmt.Sprintf("the staging cluster '%s' has no ready pod, the caches cannot be migrated to the upgraded cluster", infinispan.GetDataMigrationName())
+		r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonDataMigrationFailed, msg)
+		r.reqLogger.Info(msg)
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+	source, err := r.dataMigrationSource(infinispan.GetDataMigrationName())
+	if err != nil {
+		return &ctrl.Result{}, r.failDataMigration(err.Error())
+	}
+	migrated, err := migrateCaches(cluster, stagingPod, podList.Items[0].Name, source)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	// The upgraded cluster is already serving clients, so the number of entries read from the staging cluster is verified
+	// rather than the number of entries of the upgraded caches
+	var mismatches []string
+	for cache, expected := range migration.CacheEntries {
+		if m, ok := migrated[cache]; !ok || m.migrated != expected {
+			mismatches = append(mismatches, fmt.Sprintf("%s (expected %d, found %d)", cache, expected, m.migrated))
+		}
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return r.retryDataMigration(mismatches)
+	}
+
+	r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonDataMigrationComplete, "caches migrated to the upgraded cluster")
+	if err := r.deleteDataMigrationResources(); err != nil {
+		return &ctrl.Result{}, err
+	}
+	return nil, r.update(func() {
+		infinispan.Status.DataMigration = nil
+	})
+}
+
+// retryDataMigration migrates the caches again, failing the migration once MaxDataMigrationAttempts is reached
+func (r *infinispanRequest) retryDataMigration(mismatches []string) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	migration := infinispan.Status.DataMigration
+	if migration.Attempts+1 >= MaxDataMigrationAttempts {
+		msg := fmt.Sprintf("entry count mismatch after migrating caches: %s. The staging cluster '%s' is retained", strings.Join(mismatches, ", "), infinispan.GetDataMigrationName())
+		r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonDataMigrationMismatch, msg)
+		return nil, r.failDataMigration(msg)
+	}
+	msg := fmt.Sprintf("entry count mismatch after migrating caches: %s, migrating them again", strings.Join(mismatches, ", "))
+	r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonDataMigrationRetry, msg)
+	r.reqLogger.Info(msg)
+	return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, r.update(func() {
+		infinispan.Status.DataMigration.Attempts++
+	})
+}
+
+// failDataMigration records the failure in the Infinispan status, retaining the staging cluster
+func (r *infinispanRequest) failDataMigration(msg string) error {
+	r.reqLogger.Info(msg)
+	return r.update(func() {
+		r.infinispan.Status.DataMigration.Stage = infinispanv1.DataMigrationFailed
+		r.infinispan.SetCondition(infinispanv1.ConditionDataMigrationFailed, metav1.ConditionTrue, infinispanv1.ReasonDataMigrationFailed, msg)
+	})
+}
+
+// cacheMigration is the result of the migration of a persisted cache
+type cacheMigration struct {
+	// The number of entries read from the source cluster
+	migrated      int
+	sourceEntries int
+	targetEntries int
+}
+
+// migrateCaches creates the protobuf schemas and the caches of the pod `from` that are missing on the pod `to` and
+// migrates the entries of the persisted caches, connecting each cache to the source cluster through a remote store for
+// the duration of its migration
+func migrateCaches(cluster ispn.ClusterInterface, from, to string, source ispn.SourceConnection) (map[string]cacheMigration, error) {
+	schemas, err := cluster.GetProtobufSchemas(from)
+	if err != nil {
+		return nil, err
+	}
+	for name, schema := range schemas {
+		if err := cluster.RegisterProtobufSchema(name, schema, to); err != nil {
+			return nil, err
+		}
+	}
+
+	cacheNames, err := cluster.CacheNames(from)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(cacheNames)
+	migrated := map[string]cacheMigration{}
+	for _, cache := range cacheNames {
+		if strings.HasPrefix(cache, "___") {
+			continue
+		}
+		config, err := cluster.GetCacheConfiguration(cache, from)
+		if err != nil {
+			return nil, err
+		}
+		if exists, err := cluster.ExistsCache(cache, to); err != nil {
+			return nil, err
+		} else if !exists {
+			if err := cluster.CreateCacheWithConfiguration(cache, config, to); err != nil {
+				return nil, err
+			}
+		}
+		if persisted, err := isPersistedCache(config); err != nil {
+			return nil, fmt.Errorf("unable to read the configuration of cache '%s': %w", cache, err)
+		} else if !persisted {
+			continue
+		}
+
+		m, err := migrateCache(cluster, cache, from, to, source)
+		if err != nil {
+			return nil, fmt.Errorf("unable to migrate cache '%s': %w", cache, err)
+		}
+		migrated[cache] = *m
+	}
+	return migrated, nil
+}
+
+func migrateCache(cluster ispn.ClusterInterface, cache, from, to string, source ispn.SourceConnection) (m *cacheMigration, err error) {
+	// The cache is left connected when a previous migration has been interrupted
+	if err = cluster.DisconnectSource(cache, to); err != nil {
+		return
+	}
+	if err = cluster.ConnectSource(cache, source, to); err != nil {
+		return
+	}
+	defer func() {
+		if derr := cluster.DisconnectSource(cache, to); err == nil {
+			err = derr
+		}
+	}()
+
+	m = &cacheMigration{}
+	if m.migrated, err = cluster.SyncData(cache, to); err != nil {
+		return
+	}
+	if m.sourceEntries, err = cluster.GetCacheSize(cache, from); err != nil {
+		return
+	}
+	m.targetEntries, err = cluster.GetCacheSize(cache, to)
+	return
+}
+
+// isPersistedCache returns true if the cache configuration, in JSON format, configures persistence
+func isPersistedCache(config string) (bool, error) {
+	cache := map[string]map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(config), &cache); err != nil {
+		return false, err
+	}
+	for _, attributes := range cache {
+		if _, ok := attributes["persistence"]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// dataMigrationSource returns the connection to the HotRod endpoint of the Service, authenticated with the first
+// identity of the cluster
+func (r *infinispanRequest) dataMigrationSource(serviceName string) (ispn.SourceConnection, error) {
+	infinispan := r.infinispan
+	source := ispn.SourceConnection{
+		Host: fmt.Sprintf("%s.%s.svc", serviceName, infinispan.Namespace),
+		Port: consts.InfinispanUserPort,
+	}
+	if !infinispan.IsAuthenticationEnabled() {
+		return source, nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(r.ctx, types.NamespacedName{Namespace: infinispan.Namespace, Name: infinispan.GetSecretName()}, secret); err != nil {
+		return source, fmt.Errorf("unable to fetch the identities of the data migration: %w", err)
+	}
+	identities, err := security.ParseIdentities(secret.Data[consts.ServerIdentitiesFilename])
+	if err != nil {
+		return source, err
+	}
+	if len(identities.Credentials) == 0 {
+		return source, fmt.Errorf("secret '%s' holds no identity to migrate the caches with", secret.Name)
+	}
+	source.Username = identities.Credentials[0].Username
+	source.Password = identities.Credentials[0].Password
+	return source, nil
+}
+
+// reconcileStagingCluster creates the staging cluster of the DataMigration upgrade, returning true once all its pods
+// are ready
+func (r *infinispanRequest) reconcileStagingCluster(statefulSet *appsv1.StatefulSet, configMap *corev1.ConfigMap) (bool, error) {
+	infinispan := r.infinispan
+	migrationConfig, ok := configMap.Data[consts.ServerDataMigrationConfigFilename]
+	if !ok {
+		r.reqLogger.Info("Waiting for the configuration of the staging cluster")
+		return false, nil
+	}
+	if err := r.createDataMigrationResource(stagingService(infinispan)); err != nil {
+		return false, err
+	}
+	staging := stagingStatefulSet(infinispan, statefulSet, migrationConfig)
+	if err := r.createDataMigrationResource(staging); err != nil {
+		return false, err
+	}
+	return staging.Status.ReadyReplicas == *staging.Spec.Replicas, nil
+}
+
+// stagingPod returns the name of a ready pod of the staging cluster, or the empty string if none is ready
+func (r *infinispanRequest) stagingPod() (string, error) {
+	podList := &corev1.PodList{}
+	if err := r.Client.List(r.ctx, podList, client.InNamespace(r.infinispan.Namespace), client.MatchingLabels(DataMigrationPodLabels(r.infinispan.Name))); err != nil {
+		return "", err
+	}
+	for _, pod := range podList.Items {
+		if kube.IsPodReady(pod) {
+			return pod.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// stagingStatefulSet returns the StatefulSet of the staging cluster of a DataMigration upgrade. The pods run the
+// cluster pod template with the new image, loading the staging configuration so that they form their own cluster
+func stagingStatefulSet(i *infinispanv1.Infinispan, statefulSet *appsv1.StatefulSet, migrationConfig string) *appsv1.StatefulSet {
+	labels := DataMigrationPodLabels(i.Name)
+	podLabels := DataMigrationPodLabels(i.Name)
+	i.AddOperatorLabelsForPods(podLabels)
+	i.AddLabelsForPods(podLabels)
+
+	template := statefulSet.Spec.Template.DeepCopy()
+	template.Labels = podLabels
+	spec := &template.Spec
+	// The readiness gate is only managed for the cluster pods
+	spec.ReadinessGates = nil
+	spec.Affinity = podAffinity(i, labels)
+	spec.TopologySpreadConstraints = topologySpreadConstraints(i, labels)
+
+	container := &spec.Containers[0]
+	container.Image = i.ImageName()
+	setContainerEnv(container, "DEFAULT_IMAGE", i.DefaultImageName())
+	setContainerEnv(container, "CONFIG_PATH", consts.ServerDataMigrationConfigPath)
+	setContainerEnv(container, "CONFIG_HASH", hash.HashString(migrationConfig))
+
+	replicas := *statefulSet.Spec.Replicas
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        i.GetDataMigrationName(),
+			Namespace:   i.Namespace,
+			Annotations: consts.DeploymentAnnotations,
+			Labels:      LabelsResource(i.Name, "infinispan-data-migration"),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			// The staging cluster holds no data when it starts
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Replicas:             &replicas,
+			Template:             *template,
+			VolumeClaimTemplates: statefulSet.Spec.VolumeClaimTemplates,
+		},
+	}
+}
+
+// stagingService returns the headless Service of the staging cluster, which the staging pods discover each other with
+// and which the upgraded cluster reads the migrated entries from
+func stagingService(i *infinispanv1.Infinispan) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.GetDataMigrationName(),
+			Namespace: i.Namespace,
+			Labels:    LabelsResource(i.Name, "infinispan-service-data-migration"),
+		},
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  DataMigrationPodLabels(i.Name),
+			Ports: []corev1.ServicePort{
+				{
+					Name: consts.InfinispanPingPortName,
+					Port: consts.InfinispanPingPort,
+				},
+				{
+					Name: consts.InfinispanUserPortName,
+					Port: consts.InfinispanUserPort,
+				},
+			},
+		},
+	}
+}
+
+// createDataMigrationResource creates a resource of the staging cluster. An existing resource is only reused when
+// controlled by the Infinispan, so that a user resource with the same name is never adopted
+func (r *infinispanRequest) createDataMigrationResource(obj client.Object) error {
+	if err := controllerutil.SetControllerReference(r.infinispan, obj, r.scheme); err != nil {
+		return err
+	}
+	err := r.Client.Create(r.ctx, obj)
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+	// The existing resource is decoded into obj, so drop the reference set above before reading it
+	obj.SetOwnerReferences(nil)
+	if err := r.Client.Get(r.ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(obj, r.infinispan) {
+		return fmt.Errorf("%s '%s' already exists and is not controlled by Infinispan '%s'", reflect.TypeOf(obj).Elem().Name(), obj.GetName(), r.infinispan.Name)
+	}
+	return nil
+}
+
+// deleteDataMigrationResources deletes the StatefulSet, the volumes and the Service of the staging cluster
+func (r *infinispanRequest) deleteDataMigrationResources() error {
+	key := types.NamespacedName{Namespace: r.infinispan.Namespace, Name: r.infinispan.GetDataMigrationName()}
+	for _, obj := range []client.Object{&appsv1.StatefulSet{}, &corev1.Service{}} {
+		if err := r.Client.Get(r.ctx, key, obj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(obj, r.infinispan) {
+			continue
+		}
+		if err := r.Client.Delete(r.ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return r.deleteVolumeClaims(key.Name, DataMigrationPodLabels(r.infinispan.Name))
+}
+
+// deleteVolumeClaims deletes the PersistentVolumeClaims created from the claim templates of the StatefulSet, whose
+// pods match the labels
+func (r *infinispanRequest) deleteVolumeClaims(statefulSetName string, labels map[string]string) error {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.Client.List(r.ctx, pvcs, client.InNamespace(r.infinispan.Namespace), client.MatchingLabels(labels)); err != nil {
+		return err
+	}
+	claimName := regexp.MustCompile(fmt.Sprintf(`^.+-%s-\d+$`, regexp.QuoteMeta(statefulSetName)))
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if !claimName.MatchString(pvc.Name) {
+			continue
+		}
+		if err := r.Client.Delete(r.ctx, pvc); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
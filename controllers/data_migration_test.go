@@ -0,0 +1,399 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	migrationPod    = "example-infinispan-0"
+	migrationHost   = "example-infinispan.testing-namespace.svc"
+	stagingPodName  = "example-infinispan-migration-0"
+	stagingHost     = "example-infinispan-migration.testing-namespace.svc"
+	persistedConfig = `{"distributed-cache":{"mode":"SYNC","persistence":{"file-store":{}}}}`
+	volatileConfig  = `{"distributed-cache":{"mode":"SYNC"}}`
+)
+
+// migrationCluster holds the caches of each pod, the remaining methods are not expected to be called
+type migrationCluster struct {
+	ispn.ClusterInterface
+	hosts   map[string]string
+	configs map[string]map[string]string
+	entries map[string]map[string]int
+	schemas map[string]map[string]string
+	// The source cluster each cache is connected to, by pod
+	connected map[string]map[string]string
+	// Entries written to the source caches while the data is synchronized
+	writes map[string]int
+}
+
+func newMigrationCluster() *migrationCluster {
+	return &migrationCluster{
+		hosts:     map[string]string{migrationHost: migrationPod, stagingHost: stagingPodName},
+		configs:   map[string]map[string]string{migrationPod: {}, stagingPodName: {}},
+		entries:   map[string]map[string]int{migrationPod: {}, stagingPodName: {}},
+		schemas:   map[string]map[string]string{migrationPod: {}, stagingPodName: {}},
+		connected: map[string]map[string]string{migrationPod: {}, stagingPodName: {}},
+		writes:    map[string]int{},
+	}
+}
+
+func (c *migrationCluster) addCache(podName, cache, config string, entries int) {
+	c.configs[podName][cache] = config
+	c.entries[podName][cache] = entries
+}
+
+func (c *migrationCluster) GetProtobufSchemas(podName string) (map[string]string, error) {
+	return c.schemas[podName], nil
+}
+
+func (c *migrationCluster) RegisterProtobufSchema(schemaName, schema, podName string) error {
+	c.schemas[podName][schemaName] = schema
+	return nil
+}
+
+func (c *migrationCluster) CacheNames(podName string) ([]string, error) {
+	names := []string{"___protobuf_metadata"}
+	for name := range c.configs[podName] {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (c *migrationCluster) GetCacheConfiguration(cacheName, podName string) (string, error) {
+	return c.configs[podName][cacheName], nil
+}
+
+func (c *migrationCluster) ExistsCache(cacheName, podName string) (bool, error) {
+	_, ok := c.configs[podName][cacheName]
+	return ok, nil
+}
+
+func (c *migrationCluster) CreateCacheWithConfiguration(cacheName, configuration, podName string) error {
+	c.addCache(podName, cacheName, configuration, 0)
+	return nil
+}
+
+func (c *migrationCluster) DeleteCache(cacheName, podName string) error {
+	delete(c.configs[podName], cacheName)
+	delete(c.entries[podName], cacheName)
+	return nil
+}
+
+func (c *migrationCluster) GetCacheSize(cacheName, podName string) (int, error) {
+	return c.entries[podName][cacheName], nil
+}
+
+func (c *migrationCluster) ConnectSource(cacheName string, source ispn.SourceConnection, podName string) error {
+	c.connected[podName][cacheName] = c.hosts[source.Host]
+	return nil
+}
+
+func (c *migrationCluster) DisconnectSource(cacheName, podName string) error {
+	delete(c.connected[podName], cacheName)
+	return nil
+}
+
+func (c *migrationCluster) SyncData(cacheName, podName string) (int, error) {
+	source := c.connected[podName][cacheName]
+	migrated := c.entries[source][cacheName]
+	c.entries[podName][cacheName] = migrated
+	c.entries[source][cacheName] += c.writes[cacheName]
+	return migrated, nil
+}
+
+func dataMigrationRequest(t *testing.T, migration *infinispanv1.DataMigrationStatus, objs ...client.Object) *infinispanRequest {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, infinispanv1.AddToScheme(scheme))
+
+	infinispan := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "example-infinispan",
+			Namespace:         namespace,
+			UID:               "example-infinispan-uid",
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: infinispanv1.InfinispanSpec{
+			Replicas: 2,
+			Security: infinispanv1.InfinispanSecurity{EndpointAuthentication: pointer.BoolPtr(false)},
+			Upgrades: &infinispanv1.InfinispanUpgradesSpec{Type: infinispanv1.UpgradeTypeDataMigration},
+		},
+		Status: infinispanv1.InfinispanStatus{
+			DataMigration: migration,
+		},
+	}
+	initObjs := []runtime.Object{infinispan.DeepCopy()}
+	for _, obj := range objs {
+		initObjs = append(initObjs, obj)
+	}
+	return &infinispanRequest{
+		InfinispanReconciler: &InfinispanReconciler{
+			Client:   fake.NewFakeClientWithScheme(scheme, initObjs...),
+			scheme:   scheme,
+			eventRec: record.NewFakeRecorder(10),
+		},
+		ctx:        context.TODO(),
+		infinispan: infinispan,
+		reqLogger:  logf.Log,
+	}
+}
+
+var migrationPods = &corev1.PodList{Items: []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: migrationPod}}}}
+
+func migrationStatefulSet() *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: pointer.Int32Ptr(2),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: PodLabels("example-infinispan")},
+				Spec: corev1.PodSpec{
+					ReadinessGates: []corev1.PodReadinessGate{{ConditionType: "example"}},
+					Containers: []corev1.Container{{
+						Name:  InfinispanContainer,
+						Image: "infinispan:old",
+						Env:   []corev1.EnvVar{{Name: "CONFIG_HASH", Value: "cluster"}, {Name: "DEFAULT_IMAGE", Value: "infinispan:old"}},
+					}},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: DataMountVolume}}},
+		},
+	}
+}
+
+func migrationConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		Data: map[string]string{
+			consts.ServerConfigFilename:              "cluster",
+			consts.ServerDataMigrationConfigFilename: "staging",
+		},
+	}
+}
+
+// readyStagingPod returns a ready pod of the staging cluster
+func readyStagingPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: stagingPodName, Namespace: namespace, Labels: DataMigrationPodLabels("example-infinispan")},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func ownedStagingStatefulSet(t *testing.T, r *infinispanRequest) *appsv1.StatefulSet {
+	staging := stagingStatefulSet(r.infinispan, migrationStatefulSet(), "staging")
+	assert.NoError(t, controllerutil.SetControllerReference(r.infinispan, staging, r.scheme))
+	return staging
+}
+
+func volumeClaim(name string, labels map[string]string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+}
+
+func resourceExists(t *testing.T, r *infinispanRequest, name string, obj client.Object) bool {
+	err := r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
+	if errors.IsNotFound(err) {
+		return false
+	}
+	assert.NoError(t, err)
+	return true
+}
+
+func TestMigrateToStagingStartsStagingCluster(t *testing.T) {
+	r := dataMigrationRequest(t, nil)
+	result, err := r.migrateToStaging(migrationStatefulSet(), migrationConfigMap(), migrationPods, newMigrationCluster())
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, infinispanv1.DataMigrationStaging, r.infinispan.Status.DataMigration.Stage)
+
+	staging := &appsv1.StatefulSet{}
+	assert.True(t, resourceExists(t, r, "example-infinispan-migration", staging))
+	assert.True(t, metav1.IsControlledBy(staging, r.infinispan))
+	assert.Equal(t, DataMigrationPodLabels("example-infinispan"), staging.Spec.Selector.MatchLabels)
+	assert.Equal(t, int32(2), *staging.Spec.Replicas)
+	assert.Len(t, staging.Spec.VolumeClaimTemplates, 1)
+	assert.Nil(t, staging.Spec.Template.Spec.ReadinessGates)
+	container := staging.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, r.infinispan.ImageName(), container.Image)
+	assert.Equal(t, consts.ServerDataMigrationConfigPath, container.Env[kube.GetEnvVarIndex("CONFIG_PATH", &container.Env)].Value)
+	assert.NotEqual(t, "cluster", container.Env[kube.GetEnvVarIndex("CONFIG_HASH", &container.Env)].Value)
+
+	service := &corev1.Service{}
+	assert.True(t, resourceExists(t, r, "example-infinispan-migration", service))
+	assert.Equal(t, DataMigrationPodLabels("example-infinispan"), service.Spec.Selector)
+	assert.NotEqual(t, ServiceLabels("example-infinispan"), service.Spec.Selector)
+}
+
+func TestMigrateToStagingDoesNotAdoptUserStatefulSet(t *testing.T) {
+	userStatefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan-migration", Namespace: namespace}}
+	r := dataMigrationRequest(t, nil, userStatefulSet)
+	_, err := r.migrateToStaging(migrationStatefulSet(), migrationConfigMap(), migrationPods, newMigrationCluster())
+	assert.Error(t, err)
+	assert.Equal(t, infinispanv1.DataMigrationStaging, r.infinispan.Status.DataMigration.Stage)
+}
+
+func TestMigrateToStagingMigratesCaches(t *testing.T) {
+	migration := &infinispanv1.DataMigrationStatus{Stage: infinispanv1.DataMigrationToStaging}
+	r := dataMigrationRequest(t, migration, readyStagingPod())
+	cluster := newMigrationCluster()
+	cluster.addCache(migrationPod, "books", persistedConfig, 3)
+	cluster.addCache(migrationPod, "sessions", volatileConfig, 5)
+	cluster.schemas[migrationPod]["books.proto"] = "message Book {}"
+
+	result, err := r.migrateToStaging(migrationStatefulSet(), migrationConfigMap(), migrationPods, cluster)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, infinispanv1.DataMigrationUpgrade, r.infinispan.Status.DataMigration.Stage)
+	// Only the entries of the persisted caches are migrated
+	assert.Equal(t, map[string]int{"books": 3}, r.infinispan.Status.DataMigration.CacheEntries)
+	assert.Equal(t, map[string]string{"books": persistedConfig, "sessions": volatileConfig}, cluster.configs[stagingPodName])
+	assert.Equal(t, map[string]int{"books": 3, "sessions": 0}, cluster.entries[stagingPodName])
+	assert.Equal(t, cluster.schemas[migrationPod], cluster.schemas[stagingPodName])
+	assert.Empty(t, cluster.connected[stagingPodName])
+}
+
+func TestMigrateToStagingRetriedOnConcurrentWrites(t *testing.T) {
+	migration := &infinispanv1.DataMigrationStatus{Stage: infinispanv1.DataMigrationToStaging}
+	r := dataMigrationRequest(t, migration, readyStagingPod())
+	cluster := newMigrationCluster()
+	cluster.addCache(migrationPod, "books", persistedConfig, 3)
+	cluster.writes["books"] = 1
+
+	result, err := r.migrateToStaging(migrationStatefulSet(), migrationConfigMap(), migrationPods, cluster)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, infinispanv1.DataMigrationToStaging, r.infinispan.Status.DataMigration.Stage)
+	assert.Equal(t, int32(1), r.infinispan.Status.DataMigration.Attempts)
+	// The staging cache is removed so that it's migrated again from scratch
+	assert.NotContains(t, cluster.configs[stagingPodName], "books")
+
+	_, err = r.migrateToStaging(migrationStatefulSet(), migrationConfigMap(), migrationPods, cluster)
+	assert.NoError(t, err)
+	_, err = r.migrateToStaging(migrationStatefulSet(), migrationConfigMap(), migrationPods, cluster)
+	assert.NoError(t, err)
+	assert.Equal(t, infinispanv1.DataMigrationFailed, r.infinispan.Status.DataMigration.Stage)
+	assert.Equal(t, metav1.ConditionTrue, r.infinispan.GetCondition(infinispanv1.ConditionDataMigrationFailed).Status)
+
+	cluster.writes["books"] = 0
+	cluster.addCache(migrationPod, "books", persistedConfig, 3)
+	r.infinispan.Status.DataMigration.Stage = infinispanv1.DataMigrationFailed
+	assert.NoError(t, r.Client.Create(r.ctx, ownedStagingStatefulSet(t, r)))
+	result, err = r.migrateToStaging(migrationStatefulSet(), migrationConfigMap(), migrationPods, cluster)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, infinispanv1.DataMigrationFailed, r.infinispan.Status.DataMigration.Stage)
+}
+
+func TestMigrateToStagingRestartedOnceStagingRemoved(t *testing.T) {
+	migration := &infinispanv1.DataMigrationStatus{Stage: infinispanv1.DataMigrationFailed}
+	r := dataMigrationRequest(t, migration)
+	r.infinispan.SetCondition(infinispanv1.ConditionDataMigrationFailed, metav1.ConditionTrue, infinispanv1.ReasonDataMigrationFailed, "")
+
+	_, err := r.migrateToStaging(migrationStatefulSet(), migrationConfigMap(), migrationPods, newMigrationCluster())
+	assert.NoError(t, err)
+	assert.Equal(t, infinispanv1.DataMigrationStaging, r.infinispan.Status.DataMigration.Stage)
+	assert.False(t, r.infinispan.IsConditionTrue(infinispanv1.ConditionDataMigrationFailed))
+	assert.True(t, resourceExists(t, r, "example-infinispan-migration", &appsv1.StatefulSet{}))
+}
+
+func TestMigrateFromStaging(t *testing.T) {
+	migration := &infinispanv1.DataMigrationStatus{Stage: infinispanv1.DataMigrationUpgrade, CacheEntries: map[string]int{"books": 3}}
+	stagingClaim := volumeClaim("data-volume-example-infinispan-migration-0", DataMigrationPodLabels("example-infinispan"))
+	r := dataMigrationRequest(t, migration, readyStagingPod(), stagingClaim)
+	assert.NoError(t, r.Client.Create(r.ctx, ownedStagingStatefulSet(t, r)))
+	cluster := newMigrationCluster()
+	cluster.addCache(stagingPodName, "books", persistedConfig, 3)
+	// Written by the clients of the upgraded cluster
+	cluster.addCache(migrationPod, "books", persistedConfig, 1)
+
+	result, err := r.migrateFromStaging(migrationPods, cluster)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Nil(t, r.infinispan.Status.DataMigration)
+	assert.Equal(t, 3, cluster.entries[migrationPod]["books"])
+	assert.Empty(t, cluster.connected[migrationPod])
+	assert.False(t, resourceExists(t, r, "example-infinispan-migration", &appsv1.StatefulSet{}))
+	assert.False(t, resourceExists(t, r, stagingClaim.Name, &corev1.PersistentVolumeClaim{}))
+}
+
+func TestMigrateFromStagingFailsOnMismatch(t *testing.T) {
+	migration := &infinispanv1.DataMigrationStatus{Stage: infinispanv1.DataMigrationFromStaging, CacheEntries: map[string]int{"books": 3}, Attempts: MaxDataMigrationAttempts - 1}
+	r := dataMigrationRequest(t, migration, readyStagingPod())
+	assert.NoError(t, r.Client.Create(r.ctx, ownedStagingStatefulSet(t, r)))
+	cluster := newMigrationCluster()
+	cluster.addCache(stagingPodName, "books", persistedConfig, 2)
+
+	_, err := r.migrateFromStaging(migrationPods, cluster)
+	assert.NoError(t, err)
+	assert.Equal(t, infinispanv1.DataMigrationFailed, r.infinispan.Status.DataMigration.Stage)
+	condition := r.infinispan.GetCondition(infinispanv1.ConditionDataMigrationFailed)
+	assert.Contains(t, condition.Message, "books (expected 3, found 2)")
+	assert.True(t, resourceExists(t, r, "example-infinispan-migration", &appsv1.StatefulSet{}))
+}
+
+func TestDeleteVolumeClaimsOfStatefulSet(t *testing.T) {
+	clusterClaim := volumeClaim("data-volume-example-infinispan-0", PodLabels("example-infinispan"))
+	stagingClaim := volumeClaim("data-volume-example-infinispan-migration-0", DataMigrationPodLabels("example-infinispan"))
+	otherClaim := volumeClaim("example-infinispan-config", PodLabels("example-infinispan"))
+	r := dataMigrationRequest(t, nil, clusterClaim, stagingClaim, otherClaim)
+
+	assert.NoError(t, r.deleteVolumeClaims("example-infinispan", PodLabels("example-infinispan")))
+	assert.False(t, resourceExists(t, r, clusterClaim.Name, &corev1.PersistentVolumeClaim{}))
+	assert.True(t, resourceExists(t, r, stagingClaim.Name, &corev1.PersistentVolumeClaim{}))
+	assert.True(t, resourceExists(t, r, otherClaim.Name, &corev1.PersistentVolumeClaim{}))
+}
+
+func TestIsPersistedCache(t *testing.T) {
+	persisted, err := isPersistedCache(persistedConfig)
+	assert.NoError(t, err)
+	assert.True(t, persisted)
+	persisted, err = isPersistedCache(volatileConfig)
+	assert.NoError(t, err)
+	assert.False(t, persisted)
+	_, err = isPersistedCache("<distributed-cache/>")
+	assert.Error(t, err)
+}
+
+func TestValidateDataMigration(t *testing.T) {
+	i := &infinispanv1.Infinispan{Spec: infinispanv1.InfinispanSpec{
+		Upgrades: &infinispanv1.InfinispanUpgradesSpec{Type: infinispanv1.UpgradeTypeDataMigration},
+	}}
+	assert.NoError(t, validateDataMigration(i))
+	i.Spec.Security.EndpointEncryption = &infinispanv1.EndpointEncryption{Type: infinispanv1.CertificateSourceTypeSecret, CertSecretName: "tls"}
+	assert.EqualError(t, validateDataMigration(i), "infinispan.spec.upgrades.type=DataMigration cannot be combined with endpoint encryption")
+}
+
+func TestDataMigrationConfig(t *testing.T) {
+	i := &infinispanv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace}}
+	serverConf := computeServerConfig(i, &config.XSite{})
+	migrationYaml, err := dataMigrationConfig(i, serverConf, "")
+	assert.NoError(t, err)
+	migrationConf, err := config.FromYaml(migrationYaml)
+	assert.NoError(t, err)
+	assert.Equal(t, "example-infinispan-migration", migrationConf.Infinispan.ClusterName)
+	assert.Equal(t, "example-infinispan-migration.testing-namespace.svc.cluster.local", migrationConf.JGroups.DNSPing.Query)
+	assert.Nil(t, migrationConf.XSite)
+	assert.Equal(t, "example-infinispan", serverConf.Infinispan.ClusterName)
+	assert.NotNil(t, serverConf.XSite)
+}
@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DebugBundleAnnotation requests a Job collecting a debug bundle of the cluster when set to "true". The operator
+	// removes the annotation once the Job is created
+	DebugBundleAnnotation = "infinispan.org/debug-bundle"
+
+	DebugBundleCommand = "debug-bundle"
+	// DebugBundleJobPath is where the debug bundle Job writes the bundle, to be copied with kubectl cp
+	DebugBundleJobPath = "/tmp/debug-bundle.tar.gz"
+	// DebugBundleJobWait is how long the debug bundle Job keeps its pod running for the bundle to be copied
+	DebugBundleJobWait = time.Hour
+
+	EventReasonDebugBundleJobCreated = "DebugBundleJobCreated"
+	EventReasonDebugBundleJobFailed  = "DebugBundleJobFailed"
+)
+
+// DebugBundle collects the state of a cluster into a tar.gz archive. Failures to collect individual files are recorded
+// in the errors.txt file of the archive, so that a partially available cluster can still be debugged
+type DebugBundle struct {
+	Client  client.Client
+	Cluster ispn.ClusterInterface
+	Logs    func(pod, namespace string, ctx context.Context) (string, error)
+	Ctx     context.Context
+
+	tar    *tar.Writer
+	errors []string
+}
+
+// Write collects the Infinispan CR, its StatefulSet and pods, the logs, thread dump and cache manager stats of each
+// pod and the stats of each cache. Secrets and the server configuration are never collected, as they hold credentials
+func (b *DebugBundle) Write(i *ispnv1.Infinispan, out io.Writer) error {
+	gz := gzip.NewWriter(out)
+	b.tar = tar.NewWriter(gz)
+	b.errors = nil
+
+	b.addObject("infinispan.yaml", i)
+
+	statefulSet := &appsv1.StatefulSet{}
+	if err := b.Client.Get(b.Ctx, types.NamespacedName{Namespace: i.Namespace, Name: i.Name}, statefulSet); err != nil {
+		b.addError("statefulset.yaml", err)
+	} else {
+		statefulSet.ManagedFields = nil
+		b.addObject("statefulset.yaml", statefulSet)
+	}
+
+	podList := &corev1.PodList{}
+	if err := b.Client.List(b.Ctx, podList, client.InNamespace(i.Namespace), client.MatchingLabels(PodLabels(i.Name))); err != nil {
+		b.addError("pods.yaml", err)
+	} else {
+		for idx := range podList.Items {
+			podList.Items[idx].ManagedFields = nil
+		}
+		b.addObject("pods.yaml", podList)
+	}
+
+	var readyPod string
+	for _, pod := range podList.Items {
+		dir := fmt.Sprintf("pods/%s", pod.Name)
+		if logs, err := b.Logs(pod.Name, pod.Namespace, b.Ctx); err != nil {
+			b.addError(dir+"/server.log", err)
+		} else {
+			b.addFile(dir+"/server.log", []byte(logs))
+		}
+		if !kube.IsPodReady(pod) {
+			continue
+		}
+		if readyPod == "" {
+			readyPod = pod.Name
+		}
+		if threads, err := b.Cluster.GetThreadDump(pod.Name); err != nil {
+			b.addError(dir+"/threads.txt", err)
+		} else {
+			b.addFile(dir+"/threads.txt", threads.Bytes())
+		}
+		if stats, err := b.Cluster.GetCacheManagerStats(consts.DefaultCacheManagerName, pod.Name); err != nil {
+			b.addError(dir+"/cache-manager-stats.json", err)
+		} else {
+			b.addFile(dir+"/cache-manager-stats.json", stats.Bytes())
+		}
+	}
+
+	if readyPod != "" {
+		caches, err := b.Cluster.CacheNames(readyPod)
+		if err != nil {
+			b.addError("caches", err)
+		}
+		for _, cache := range caches {
+			name := fmt.Sprintf("caches/%s-stats.json", cache)
+			if stats, err := b.Cluster.GetCacheStats(cache, readyPod); err != nil {
+				b.addError(name, err)
+			} else {
+				b.addFile(name, stats.Bytes())
+			}
+		}
+	}
+
+	if len(b.errors) > 0 {
+		b.addFile("errors.txt", []byte(strings.Join(b.errors, "\n")+"\n"))
+	}
+	if err := b.tar.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (b *DebugBundle) addObject(name string, obj interface{}) {
+	if data, err := yaml.Marshal(obj); err != nil {
+		b.addError(name, err)
+	} else {
+		b.addFile(name, data)
+	}
+}
+
+func (b *DebugBundle) addFile(name string, data []byte) {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := b.tar.WriteHeader(header); err != nil {
+		b.addError(name, err)
+		return
+	}
+	if _, err := b.tar.Write(data); err != nil {
+		b.addError(name, err)
+	}
+}
+
+func (b *DebugBundle) addError(name string, err error) {
+	b.errors = append(b.errors, fmt.Sprintf("%s: %v", name, err))
+}
+
+// reconcileDebugBundle creates a Job collecting a debug bundle when requested by the DebugBundleAnnotation. A Job
+// created by a previous request is replaced, so that each request collects the current state of the cluster. Failures
+// are reported as events and never block the reconciliation of a cluster that is likely to be misbehaving
+func (r *infinispanRequest) reconcileDebugBundle() error {
+	i := r.infinispan
+	if i.Annotations[DebugBundleAnnotation] != "true" {
+		return nil
+	}
+
+	if err := r.createDebugBundleJob(); err != nil {
+		r.eventRec.Event(i, corev1.EventTypeWarning, EventReasonDebugBundleJobFailed, err.Error())
+	} else {
+		r.eventRec.Event(i, corev1.EventTypeNormal, EventReasonDebugBundleJobCreated, fmt.Sprintf("Job %s collects the debug bundle in %s", i.GetDebugBundleJobName(), DebugBundleJobPath))
+	}
+	return r.update(func() {
+		delete(i.Annotations, DebugBundleAnnotation)
+	})
+}
+
+func (r *infinispanRequest) createDebugBundleJob() error {
+	i := r.infinispan
+	operatorNs, err := kube.GetOperatorNamespace()
+	if err != nil {
+		return fmt.Errorf("unable to determine the operator namespace: %w", err)
+	}
+	operatorPod, err := kube.GetPod(r.ctx, r.Client, operatorNs)
+	if err != nil {
+		return fmt.Errorf("unable to determine the operator image: %w", err)
+	}
+
+	if err := r.reconcileDebugBundleServiceAccount(); err != nil {
+		return fmt.Errorf("unable to create the debug bundle ServiceAccount: %w", err)
+	}
+
+	job := computeDebugBundleJob(i, operatorPod.Spec.Containers[0].Image, i.GetDebugBundleJobName())
+	if err := r.Client.Delete(r.ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete the previous debug bundle Job: %w", err)
+	}
+	if err := controllerutil.SetControllerReference(i, job, r.scheme); err != nil {
+		return err
+	}
+	if err := r.Client.Create(r.ctx, job); err != nil {
+		return fmt.Errorf("unable to create the debug bundle Job: %w", err)
+	}
+	r.reqLogger.Info("Created debug bundle Job", "Job.Name", job.Name)
+	return nil
+}
+
+// reconcileDebugBundleServiceAccount creates the ServiceAccount the debug bundle Job runs with. It's bound to a Role
+// that only allows to read the resources collected in the bundle of the cluster, along with the admin Secret used to
+// query the server
+func (r *infinispanRequest) reconcileDebugBundleServiceAccount() error {
+	i := r.infinispan
+	name := i.GetDebugBundleJobName()
+	objectMeta := func() metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: i.Namespace}
+	}
+
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: objectMeta()}
+	if _, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, serviceAccount, func() error {
+		serviceAccount.Labels = LabelsResource(i.Name, "infinispan-debug-bundle")
+		return controllerutil.SetControllerReference(i, serviceAccount, r.scheme)
+	}); err != nil {
+		return err
+	}
+
+	role := &rbacv1.Role{ObjectMeta: objectMeta()}
+	if _, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, role, func() error {
+		role.Labels = LabelsResource(i.Name, "infinispan-debug-bundle")
+		role.Rules = debugBundleRules(i)
+		return controllerutil.SetControllerReference(i, role, r.scheme)
+	}); err != nil {
+		return err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: objectMeta()}
+	_, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, roleBinding, func() error {
+		roleBinding.Labels = LabelsResource(i.Name, "infinispan-debug-bundle")
+		roleBinding.Subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: name, Namespace: i.Namespace}}
+		// The RoleRef is immutable, it's only set when the RoleBinding is created
+		roleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name}
+		return controllerutil.SetControllerReference(i, roleBinding, r.scheme)
+	})
+	return err
+}
+
+// debugBundleRules returns the permissions required by the debug-bundle command to collect the bundle of the cluster
+func debugBundleRules(i *ispnv1.Infinispan) []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{APIGroups: []string{ispnv1.GroupVersion.Group}, Resources: []string{"infinispans"}, ResourceNames: []string{i.Name}, Verbs: []string{"get"}},
+		{APIGroups: []string{appsv1.GroupName}, Resources: []string{"statefulsets"}, ResourceNames: []string{i.Name}, Verbs: []string{"get"}},
+		{APIGroups: []string{corev1.GroupName}, Resources: []string{"secrets"}, ResourceNames: []string{i.GetAdminSecretName()}, Verbs: []string{"get"}},
+		{APIGroups: []string{corev1.GroupName}, Resources: []string{"pods"}, Verbs: []string{"list"}},
+		{APIGroups: []string{corev1.GroupName}, Resources: []string{"pods/log"}, Verbs: []string{"get"}},
+	}
+}
+
+// computeDebugBundleJob returns a Job running the debug-bundle command of the operator image. The pod keeps running
+// once the bundle is written, so that it can be copied with kubectl cp
+func computeDebugBundleJob(i *ispnv1.Infinispan, image, serviceAccount string) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.GetDebugBundleJobName(),
+			Namespace: i.Namespace,
+			Labels:    LabelsResource(i.Name, "infinispan-debug-bundle"),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: LabelsResource(i.Name, "infinispan-debug-bundle"),
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: serviceAccount,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:  "debug-bundle",
+						Image: image,
+						Command: []string{
+							"infinispan-operator",
+							DebugBundleCommand,
+							"--namespace", i.Namespace,
+							"--cluster", i.Name,
+							"--output", DebugBundleJobPath,
+							"--wait", DebugBundleJobWait.String(),
+						},
+					}},
+				},
+			},
+		},
+	}
+}
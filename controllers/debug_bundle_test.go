@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// bundleCluster returns the diagnostics of the pods, the remaining methods are not expected to be called
+type bundleCluster struct {
+	ispn.ClusterInterface
+}
+
+func (c *bundleCluster) GetThreadDump(podName string) (*bytes.Buffer, error) {
+	return bytes.NewBufferString("threads of " + podName), nil
+}
+
+func (c *bundleCluster) GetCacheManagerStats(cacheManagerName, podName string) (*bytes.Buffer, error) {
+	return nil, fmt.Errorf("stats unavailable")
+}
+
+func (c *bundleCluster) CacheNames(podName string) ([]string, error) {
+	return []string{"cache"}, nil
+}
+
+func (c *bundleCluster) GetCacheStats(cacheName, podName string) (*bytes.Buffer, error) {
+	return bytes.NewBufferString(`{"hits":1}`), nil
+}
+
+func bundlePod(name string, ready corev1.ConditionStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "testing", Labels: PodLabels("example-infinispan")},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+	}
+}
+
+func TestDebugBundleWrite(t *testing.T) {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"}}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ispn.GetAdminSecretName(), Namespace: "testing"}}
+	c := fake.NewFakeClientWithScheme(scheme, secret, bundlePod("example-infinispan-0", corev1.ConditionTrue), bundlePod("example-infinispan-1", corev1.ConditionFalse))
+
+	bundle := &DebugBundle{
+		Client:  c,
+		Cluster: &bundleCluster{},
+		Logs: func(pod, namespace string, ctx context.Context) (string, error) {
+			return "logs of " + pod, nil
+		},
+		Ctx: context.TODO(),
+	}
+	out := &bytes.Buffer{}
+	assert.NoError(t, bundle.Write(ispn, out))
+
+	files := map[string]string{}
+	gz, err := gzip.NewReader(out)
+	assert.NoError(t, err)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		data, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		files[header.Name] = string(data)
+	}
+
+	assert.Contains(t, files["infinispan.yaml"], "name: example-infinispan")
+	assert.Contains(t, files["pods.yaml"], "example-infinispan-1")
+	assert.Equal(t, "logs of example-infinispan-0", files["pods/example-infinispan-0/server.log"])
+	assert.Equal(t, "logs of example-infinispan-1", files["pods/example-infinispan-1/server.log"])
+	assert.Equal(t, "threads of example-infinispan-0", files["pods/example-infinispan-0/threads.txt"])
+	assert.NotContains(t, files, "pods/example-infinispan-1/threads.txt")
+	assert.Equal(t, `{"hits":1}`, files["caches/cache-stats.json"])
+	// Missing resources and failed requests are reported without failing the bundle
+	assert.Contains(t, files["errors.txt"], "statefulset.yaml")
+	assert.Contains(t, files["errors.txt"], "pods/example-infinispan-0/cache-manager-stats.json: stats unavailable")
+	for name, data := range files {
+		assert.NotContains(t, data, ispn.GetAdminSecretName(), name)
+	}
+}
+
+func TestComputeDebugBundleJob(t *testing.T) {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"}}
+	job := computeDebugBundleJob(ispn, "operator:latest", ispn.GetDebugBundleJobName())
+	assert.Equal(t, "example-infinispan-debug-bundle", job.Name)
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, "example-infinispan-debug-bundle", pod.ServiceAccountName)
+	assert.Equal(t, "operator:latest", pod.Containers[0].Image)
+	assert.Equal(t, []string{"infinispan-operator", "debug-bundle", "--namespace", "testing", "--cluster", "example-infinispan",
+		"--output", "/tmp/debug-bundle.tar.gz", "--wait", "1h0m0s"}, pod.Containers[0].Command)
+}
+
+func TestReconcileDebugBundleServiceAccount(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, ispnv1.AddToScheme(scheme))
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing", UID: "uid"}}
+	r := &infinispanRequest{
+		InfinispanReconciler: &InfinispanReconciler{Client: fake.NewFakeClientWithScheme(scheme), scheme: scheme},
+		ctx:                  context.TODO(),
+		infinispan:           ispn,
+		reqLogger:            logf.Log,
+	}
+	// Reconciling again updates the existing objects
+	assert.NoError(t, r.reconcileDebugBundleServiceAccount())
+	assert.NoError(t, r.reconcileDebugBundleServiceAccount())
+
+	key := types.NamespacedName{Namespace: "testing", Name: "example-infinispan-debug-bundle"}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, &corev1.ServiceAccount{}))
+	role := &rbacv1.Role{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, role))
+	// Only the admin Secret of the cluster can be read
+	assert.Contains(t, role.Rules, rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{ispn.GetAdminSecretName()}, Verbs: []string{"get"}})
+	for _, rule := range role.Rules {
+		assert.Subset(t, []string{"get", "list"}, rule.Verbs, rule)
+	}
+	roleBinding := &rbacv1.RoleBinding{}
+	assert.NoError(t, r.Client.Get(context.TODO(), key, roleBinding))
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: key.Name}, roleBinding.RoleRef)
+	assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: key.Name, Namespace: "testing"}}, roleBinding.Subjects)
+	assert.Equal(t, "uid", string(roleBinding.OwnerReferences[0].UID))
+}
@@ -19,8 +19,72 @@ const (
 	ExternalArtifactsVolumeName            = "external-artifacts"
 	ExternalArtifactsDownloadInitContainer = "external-artifacts-download"
 	ExternalArtifactsHashValidationCommand = "echo %s %s | %ssum -c"
+	DefaultMavenRepository                 = "https://repo1.maven.org/maven2"
 )
 
+// validateExternalArtifacts verifies that each artifact is defined by either a URL or Maven coordinates
+func validateExternalArtifacts(i *infinispanv1.Infinispan) error {
+	if !i.HasExternalArtifacts() {
+		return nil
+	}
+	for idx, artifact := range i.Spec.Dependencies.Artifacts {
+		if (artifact.Url == "") == (artifact.Maven == "") {
+			return fmt.Errorf("exactly one of infinispan.spec.dependencies.artifacts[%d].url or maven must be provided", idx)
+		}
+		if artifact.Maven != "" {
+			if _, err := mavenArtifactURL(DefaultMavenRepository, artifact.Maven); err != nil {
+				return fmt.Errorf("infinispan.spec.dependencies.artifacts[%d].maven: %w", idx, err)
+			}
+		}
+	}
+	return nil
+}
+
+// mavenArtifactURL returns the URL of the artifact with the groupId:artifactId[:packaging[:classifier]]:version
+// coordinates in the Maven repository
+func mavenArtifactURL(repository, coordinates string) (string, error) {
+	parts := strings.Split(coordinates, ":")
+	if len(parts) < 3 || len(parts) > 5 {
+		return "", fmt.Errorf("invalid Maven coordinates '%s', expected groupId:artifactId[:packaging[:classifier]]:version", coordinates)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("invalid Maven coordinates '%s', the coordinates cannot be empty", coordinates)
+		}
+	}
+	groupId, artifactId, version := parts[0], parts[1], parts[len(parts)-1]
+	packaging := "jar"
+	if len(parts) > 3 {
+		packaging = parts[2]
+	}
+	fileName := artifactId + "-" + version
+	if len(parts) == 5 {
+		fileName += "-" + parts[3]
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s.%s", strings.TrimSuffix(repository, "/"), strings.ReplaceAll(groupId, ".", "/"), artifactId, version, fileName, packaging), nil
+}
+
+// externalArtifacts returns the artifacts to download, the Maven coordinates being resolved to the URL of the artifact
+// in the Maven repository
+func externalArtifacts(ispn *infinispanv1.Infinispan) ([]infinispanv1.InfinispanExternalArtifacts, error) {
+	repository := ispn.Spec.Dependencies.MavenRepository
+	if repository == "" {
+		repository = DefaultMavenRepository
+	}
+	artifacts := make([]infinispanv1.InfinispanExternalArtifacts, len(ispn.Spec.Dependencies.Artifacts))
+	for i, artifact := range ispn.Spec.Dependencies.Artifacts {
+		if artifact.Maven != "" {
+			url, err := mavenArtifactURL(repository, artifact.Maven)
+			if err != nil {
+				return nil, err
+			}
+			artifact.Url = url
+		}
+		artifacts[i] = artifact
+	}
+	return artifacts, nil
+}
+
 func applyExternalDependenciesVolume(ispn *infinispanv1.Infinispan, spec *corev1.PodSpec) (updated bool) {
 	volumes := &spec.Volumes
 	volumeMounts := &spec.Containers[0].VolumeMounts
@@ -108,13 +172,18 @@ func externalArtifactsExtractCommand(ispn *infinispanv1.Infinispan) (string, err
 		return "", err
 	}
 
+	artifacts, err := externalArtifacts(ispn)
+	if err != nil {
+		return "", err
+	}
+
 	var tpl bytes.Buffer
 	err = tmpl.Execute(&tpl, struct {
 		MountPath string
 		Artifacts []infinispanv1.InfinispanExternalArtifacts
 	}{
 		MountPath: ExternalArtifactsMountPath,
-		Artifacts: ispn.Spec.Dependencies.Artifacts,
+		Artifacts: artifacts,
 	})
 
 	if err != nil {
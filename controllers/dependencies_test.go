@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func dependenciesInfinispan(artifacts ...infinispanv1.InfinispanExternalArtifacts) *infinispanv1.Infinispan {
+	ispn := &infinispanv1.Infinispan{}
+	ispn.Spec.Dependencies = &infinispanv1.InfinispanExternalDependencies{Artifacts: artifacts}
+	return ispn
+}
+
+func TestMavenArtifactURL(t *testing.T) {
+	url, err := mavenArtifactURL(DefaultMavenRepository, "org.postgresql:postgresql:42.3.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://repo1.maven.org/maven2/org/postgresql/postgresql/42.3.1/postgresql-42.3.1.jar", url)
+
+	url, err = mavenArtifactURL("https://maven.example.com/releases/", "com.example:filters:zip:bin:1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://maven.example.com/releases/com/example/filters/1.0/filters-1.0-bin.zip", url)
+
+	_, err = mavenArtifactURL(DefaultMavenRepository, "com.example:filters")
+	assert.Error(t, err)
+	_, err = mavenArtifactURL(DefaultMavenRepository, "com.example::1.0")
+	assert.Error(t, err)
+}
+
+func TestValidateExternalArtifacts(t *testing.T) {
+	assert.NoError(t, validateExternalArtifacts(&infinispanv1.Infinispan{}))
+	assert.NoError(t, validateExternalArtifacts(dependenciesInfinispan(
+		infinispanv1.InfinispanExternalArtifacts{Url: "https://example.com/store.jar"},
+		infinispanv1.InfinispanExternalArtifacts{Maven: "org.postgresql:postgresql:42.3.1"},
+	)))
+	assert.Error(t, validateExternalArtifacts(dependenciesInfinispan(infinispanv1.InfinispanExternalArtifacts{})))
+	assert.Error(t, validateExternalArtifacts(dependenciesInfinispan(
+		infinispanv1.InfinispanExternalArtifacts{Url: "https://example.com/store.jar", Maven: "org.postgresql:postgresql:42.3.1"},
+	)))
+}
+
+func TestExternalArtifactsExtractCommand(t *testing.T) {
+	ispn := dependenciesInfinispan(infinispanv1.InfinispanExternalArtifacts{
+		Maven: "org.postgresql:postgresql:42.3.1",
+		Hash:  "sha256:5e5a5ef5b3a4ea5f4b6e3c3d0a4c",
+	})
+	ispn.Spec.Dependencies.MavenRepository = "https://maven.example.com"
+	command, err := externalArtifactsExtractCommand(ispn)
+	assert.NoError(t, err)
+	assert.Contains(t, command, `retry "curl --insecure -LO https://maven.example.com/org/postgresql/postgresql/42.3.1/postgresql-42.3.1.jar"`)
+	assert.Contains(t, command, "echo 5e5a5ef5b3a4ea5f4b6e3c3d0a4c $FILENAME | sha256sum -c")
+}
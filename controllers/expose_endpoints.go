@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"fmt"
+	"net"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+)
+
+// exposeEndpoints lists the endpoints that can be exposed individually, in the order their resources are created
+var exposeEndpoints = []ispnv1.ExposeEndpoint{ispnv1.ExposeEndpointHotRod, ispnv1.ExposeEndpointRest, ispnv1.ExposeEndpointMemcached}
+
+// exposeEndpointPorts maps each endpoint to the server port it listens on. HotRod and REST share the single port endpoint
+var exposeEndpointPorts = map[ispnv1.ExposeEndpoint]int{
+	ispnv1.ExposeEndpointHotRod:    consts.InfinispanUserPort,
+	ispnv1.ExposeEndpointRest:      consts.InfinispanUserPort,
+	ispnv1.ExposeEndpointMemcached: consts.InfinispanMemcachedPort,
+}
+
+// exposeSpec returns how the single port endpoint is exposed when perEndpoint is false
+func exposeSpec(ispn *ispnv1.Infinispan) *ispnv1.EndpointExposeSpec {
+	expose := ispn.Spec.Expose
+	return &ispnv1.EndpointExposeSpec{
+		Type:                     expose.Type,
+		NodePort:                 expose.NodePort,
+		Port:                     expose.Port,
+		Host:                     expose.Host,
+		Annotations:              expose.Annotations,
+		LoadBalancerSourceRanges: expose.LoadBalancerSourceRanges,
+	}
+}
+
+// routeTLSTermination returns where TLS is terminated for an endpoint exposed by a Route or Ingress, or an empty
+// string if the endpoint isn't encrypted
+func routeTLSTermination(ispn *ispnv1.Infinispan, expose *ispnv1.EndpointExposeSpec) ispnv1.RouteTLSTermination {
+	if expose.TLSTermination != "" {
+		return expose.TLSTermination
+	}
+	if ispn.IsEncryptionEnabled() {
+		return ispnv1.RouteTLSTerminationPassthrough
+	}
+	return ""
+}
+
+// validateLoadBalancerSourceRanges verifies that the source ranges are CIDRs only set for the LoadBalancer type
+func validateLoadBalancerSourceRanges(field string, expose *ispnv1.EndpointExposeSpec) error {
+	if len(expose.LoadBalancerSourceRanges) > 0 && expose.Type != ispnv1.ExposeTypeLoadBalancer {
+		return fmt.Errorf("%s.loadBalancerSourceRanges is only supported for type=%s", field, ispnv1.ExposeTypeLoadBalancer)
+	}
+	for _, sourceRange := range expose.LoadBalancerSourceRanges {
+		if _, _, err := net.ParseCIDR(sourceRange); err != nil {
+			return fmt.Errorf("%s.loadBalancerSourceRanges '%s' is not a valid CIDR", field, sourceRange)
+		}
+	}
+	return nil
+}
+
+// validateExposeEndpoints verifies that each endpoint exposed by perEndpoint can be exposed as configured
+func validateExposeEndpoints(ispn *ispnv1.Infinispan) error {
+	if ispn.Spec.Expose == nil {
+		return nil
+	}
+	if err := validateConsoleExpose(ispn); err != nil {
+		return err
+	}
+	if err := validateLoadBalancerSourceRanges("infinispan.spec.expose", exposeSpec(ispn)); err != nil {
+		return err
+	}
+	if !ispn.IsExposedPerEndpoint() {
+		return nil
+	}
+	if ispn.GetExposeType() == ispnv1.ExposeTypeGatewayRoute {
+		return fmt.Errorf("infinispan.spec.expose.perEndpoint is not supported for type=%s", ispnv1.ExposeTypeGatewayRoute)
+	}
+	for _, endpoint := range exposeEndpoints {
+		expose := ispn.GetEndpointExpose(endpoint)
+		if expose == nil {
+			continue
+		}
+		field := fmt.Sprintf("infinispan.spec.expose.%s", endpoint)
+		if err := validateLoadBalancerSourceRanges(field, expose); err != nil {
+			return err
+		}
+		switch expose.Type {
+		case ispnv1.ExposeTypeNodePort, ispnv1.ExposeTypeLoadBalancer:
+			if expose.TLSTermination != "" {
+				return fmt.Errorf("%s.tlsTermination is only supported for type=%s", field, ispnv1.ExposeTypeRoute)
+			}
+		case ispnv1.ExposeTypeRoute:
+			if endpoint == ispnv1.ExposeEndpointMemcached {
+				return fmt.Errorf("%s.type must be %s or %s", field, ispnv1.ExposeTypeNodePort, ispnv1.ExposeTypeLoadBalancer)
+			}
+		default:
+			return fmt.Errorf("%s.type=%s is not supported", field, expose.Type)
+		}
+
+		switch expose.TLSTermination {
+		case ispnv1.RouteTLSTerminationPassthrough:
+			if !ispn.IsEncryptionEnabled() {
+				return fmt.Errorf("%s.tlsTermination=%s requires endpoint encryption", field, expose.TLSTermination)
+			}
+		case ispnv1.RouteTLSTerminationEdge, ispnv1.RouteTLSTerminationReencrypt:
+			if endpoint != ispnv1.ExposeEndpointRest {
+				return fmt.Errorf("%s.tlsTermination=%s is only supported by the %s endpoint", field, expose.TLSTermination, ispnv1.ExposeEndpointRest)
+			}
+			if expose.TLSTermination == ispnv1.RouteTLSTerminationEdge && ispn.IsEncryptionEnabled() {
+				return fmt.Errorf("%s.tlsTermination=%s requires endpoint encryption to be disabled", field, expose.TLSTermination)
+			}
+			if expose.TLSTermination == ispnv1.RouteTLSTerminationReencrypt && !ispn.IsEncryptionEnabled() {
+				return fmt.Errorf("%s.tlsTermination=%s requires endpoint encryption", field, expose.TLSTermination)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func perEndpointInfinispan(encrypted bool) *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec: ispnv1.InfinispanSpec{
+			Expose: &ispnv1.ExposeSpec{
+				Type:        ispnv1.ExposeTypeLoadBalancer,
+				PerEndpoint: true,
+				Rest:        &ispnv1.EndpointExposeSpec{Type: ispnv1.ExposeTypeRoute, Host: "rest.example.com"},
+				Memcached:   &ispnv1.EndpointExposeSpec{Type: ispnv1.ExposeTypeNodePort, NodePort: 30221},
+			},
+		},
+	}
+	if encrypted {
+		ispn.Spec.Security.EndpointEncryption = &ispnv1.EndpointEncryption{
+			Type:           ispnv1.CertificateSourceTypeSecret,
+			CertSecretName: "tls-secret",
+		}
+	}
+	return ispn
+}
+
+func TestValidateExposeEndpoints(t *testing.T) {
+	assert.NoError(t, validateExposeEndpoints(&ispnv1.Infinispan{}))
+	assert.NoError(t, validateExposeEndpoints(perEndpointInfinispan(false)))
+
+	ispn := perEndpointInfinispan(false)
+	ispn.Spec.Expose.Memcached.Type = ispnv1.ExposeTypeRoute
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	ispn = perEndpointInfinispan(false)
+	ispn.Spec.Expose.Type = ispnv1.ExposeTypeGatewayRoute
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	ispn = perEndpointInfinispan(false)
+	ispn.Spec.Expose.HotRod = &ispnv1.EndpointExposeSpec{Type: ispnv1.ExposeTypeGatewayRoute}
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	// Only the REST endpoint supports terminating TLS at the router
+	ispn = perEndpointInfinispan(false)
+	ispn.Spec.Expose.Rest.TLSTermination = ispnv1.RouteTLSTerminationEdge
+	assert.NoError(t, validateExposeEndpoints(ispn))
+	ispn.Spec.Expose.HotRod = &ispnv1.EndpointExposeSpec{Type: ispnv1.ExposeTypeRoute, TLSTermination: ispnv1.RouteTLSTerminationEdge}
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	ispn = perEndpointInfinispan(false)
+	ispn.Spec.Expose.Rest.TLSTermination = ispnv1.RouteTLSTerminationPassthrough
+	assert.Error(t, validateExposeEndpoints(ispn))
+	ispn.Spec.Expose.Rest.TLSTermination = ispnv1.RouteTLSTerminationReencrypt
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	ispn = perEndpointInfinispan(true)
+	ispn.Spec.Expose.Rest.TLSTermination = ispnv1.RouteTLSTerminationReencrypt
+	assert.NoError(t, validateExposeEndpoints(ispn))
+	ispn.Spec.Expose.Rest.TLSTermination = ispnv1.RouteTLSTerminationEdge
+	assert.Error(t, validateExposeEndpoints(ispn))
+
+	ispn = perEndpointInfinispan(false)
+	ispn.Spec.Expose.Memcached.TLSTermination = ispnv1.RouteTLSTerminationPassthrough
+	assert.Error(t, validateExposeEndpoints(ispn))
+}
+
+func TestComputeEndpointExpose(t *testing.T) {
+	ispn := perEndpointInfinispan(true)
+
+	hotrod := computeServiceExternal(ispn, ispn.GetEndpointExternalName(ispnv1.ExposeEndpointHotRod), exposeEndpointPorts[ispnv1.ExposeEndpointHotRod], ispn.GetEndpointExpose(ispnv1.ExposeEndpointHotRod))
+	assert.Equal(t, "example-infinispan-external-hotrod", hotrod.Name)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, hotrod.Spec.Type)
+	assert.Equal(t, int32(consts.InfinispanUserPort), hotrod.Spec.Ports[0].Port)
+
+	memcached := computeServiceExternal(ispn, ispn.GetEndpointExternalName(ispnv1.ExposeEndpointMemcached), exposeEndpointPorts[ispnv1.ExposeEndpointMemcached], ispn.GetEndpointExpose(ispnv1.ExposeEndpointMemcached))
+	assert.Equal(t, corev1.ServiceTypeNodePort, memcached.Spec.Type)
+	assert.Equal(t, int32(consts.InfinispanMemcachedPort), memcached.Spec.Ports[0].TargetPort.IntVal)
+	assert.Equal(t, int32(30221), memcached.Spec.Ports[0].NodePort)
+
+	rest := computeRoute(ispn, ispn.GetEndpointExternalName(ispnv1.ExposeEndpointRest), exposeEndpointPorts[ispnv1.ExposeEndpointRest], ispn.GetEndpointExpose(ispnv1.ExposeEndpointRest))
+	assert.Equal(t, "rest.example.com", rest.Spec.Host)
+	assert.Equal(t, routev1.TLSTerminationPassthrough, rest.Spec.TLS.Termination)
+
+	ispn.Spec.Expose.Rest.TLSTermination = ispnv1.RouteTLSTerminationReencrypt
+	rest = computeRoute(ispn, ispn.GetEndpointExternalName(ispnv1.ExposeEndpointRest), exposeEndpointPorts[ispnv1.ExposeEndpointRest], ispn.GetEndpointExpose(ispnv1.ExposeEndpointRest))
+	assert.Equal(t, routev1.TLSTerminationReencrypt, rest.Spec.TLS.Termination)
+
+	ingress := computeIngress(ispn, ispn.GetEndpointExternalName(ispnv1.ExposeEndpointRest), exposeEndpointPorts[ispnv1.ExposeEndpointRest], ispn.GetEndpointExpose(ispnv1.ExposeEndpointRest))
+	assert.Equal(t, []string{"rest.example.com"}, ingress.Spec.TLS[0].Hosts)
+
+	ispn = perEndpointInfinispan(false)
+	rest = computeRoute(ispn, ispn.GetEndpointExternalName(ispnv1.ExposeEndpointRest), exposeEndpointPorts[ispnv1.ExposeEndpointRest], ispn.GetEndpointExpose(ispnv1.ExposeEndpointRest))
+	assert.Nil(t, rest.Spec.TLS)
+}
+
+func TestCleanupExternalExpose(t *testing.T) {
+	ispn := perEndpointInfinispan(false)
+	externalService := func(name string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ispn.Namespace, Labels: ExternalServiceLabels(ispn.Name)}}
+	}
+	internal := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: ispn.Name, Namespace: ispn.Namespace, Labels: ServiceLabels(ispn.Name)}}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, internal, externalService(ispn.GetServiceExternalName()), externalService(ispn.GetEndpointExternalName(ispnv1.ExposeEndpointHotRod)))
+	s := serviceRequest{
+		ServiceReconciler: &ServiceReconciler{
+			Client: c,
+			log:    ctrl.Log,
+			supportedTypes: map[string]*reconcileType{
+				consts.ExternalTypeService: {ObjectType: &corev1.Service{}, GroupVersion: corev1.SchemeGroupVersion, GroupVersionSupported: true},
+			},
+		},
+		ctx:        context.TODO(),
+		infinispan: ispn,
+	}
+
+	hotrod := computeServiceExternal(ispn, ispn.GetEndpointExternalName(ispnv1.ExposeEndpointHotRod), consts.InfinispanUserPort, ispn.GetEndpointExpose(ispnv1.ExposeEndpointHotRod))
+	assert.NoError(t, s.cleanupExternalExpose(hotrod))
+
+	services := &corev1.ServiceList{}
+	assert.NoError(t, c.List(context.TODO(), services, client.InNamespace(ispn.Namespace)))
+	var names []string
+	for _, service := range services.Items {
+		names = append(names, service.Name)
+	}
+	assert.ElementsMatch(t, []string{ispn.Name, "example-infinispan-external-hotrod"}, names)
+}
+
+func TestEnabledEndpoints(t *testing.T) {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "namespace"}}
+	ports := func(service *corev1.Service) (ports []int32) {
+		for _, port := range service.Spec.Ports {
+			ports = append(ports, port.Port)
+		}
+		return
+	}
+	assert.Equal(t, []int32{consts.InfinispanUserPort}, ports(computeService(ispn)))
+	assert.False(t, computeServerConfig(ispn, nil).Endpoints.Resp)
+
+	ispn.Spec.Endpoints = &ispnv1.InfinispanEndpointsSpec{Memcached: true, Resp: true}
+	service := computeService(ispn)
+	assert.Equal(t, []int32{consts.InfinispanUserPort, consts.InfinispanMemcachedPort, consts.InfinispanRespPort}, ports(service))
+	endpoints := computeServerConfig(ispn, nil).Endpoints
+	assert.True(t, endpoints.Memcached)
+	assert.True(t, endpoints.Resp)
+
+	// The ports of an existing Service are updated when an endpoint is enabled
+	unstructuredPorts := func(service *corev1.Service) interface{} {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(service)
+		assert.NoError(t, err)
+		return content["spec"].(map[string]interface{})["ports"]
+	}
+	enabled := unstructuredPorts(service)
+	assert.False(t, servicePortsChanged(enabled, enabled))
+	ispn.Spec.Endpoints.Resp = false
+	assert.True(t, servicePortsChanged(enabled, unstructuredPorts(computeService(ispn))))
+}
+
+func TestLoadBalancerExpose(t *testing.T) {
+	ispn := &ispnv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec: ispnv1.InfinispanSpec{
+			Expose: &ispnv1.ExposeSpec{
+				Type:                     ispnv1.ExposeTypeLoadBalancer,
+				Annotations:              map[string]string{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"},
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+			},
+		},
+	}
+	assert.NoError(t, validateExposeEndpoints(ispn))
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, ispnv1.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme)
+	s := serviceRequest{
+		ServiceReconciler: &ServiceReconciler{Client: c, log: ctrl.Log, scheme: scheme},
+		ctx:               context.TODO(),
+		infinispan:        ispn,
+	}
+	reconcileExternal := func() *corev1.Service {
+		assert.NoError(t, s.reconcileResource(computeServiceExternal(ispn, ispn.GetServiceExternalName(), consts.InfinispanUserPort, exposeSpec(ispn))))
+		service := &corev1.Service{}
+		assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: ispn.Namespace, Name: ispn.GetServiceExternalName()}, service))
+		return service
+	}
+	service := reconcileExternal()
+	assert.Equal(t, []string{"10.0.0.0/8"}, service.Spec.LoadBalancerSourceRanges)
+	assert.Equal(t, "nlb", service.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"])
+
+	// Changes are applied to the existing Service
+	ispn.Spec.Expose.Annotations = map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"}
+	ispn.Spec.Expose.LoadBalancerSourceRanges = []string{"10.0.0.0/8", "192.168.0.0/16"}
+	service = reconcileExternal()
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.0.0/16"}, service.Spec.LoadBalancerSourceRanges)
+	assert.Equal(t, map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"}, service.Annotations)
+
+	ispn.Spec.Expose.LoadBalancerSourceRanges = nil
+	assert.Empty(t, reconcileExternal().Spec.LoadBalancerSourceRanges)
+
+	ispn.Spec.Expose.LoadBalancerSourceRanges = []string{"10.0.0.0"}
+	assert.EqualError(t, validateExposeEndpoints(ispn), "infinispan.spec.expose.loadBalancerSourceRanges '10.0.0.0' is not a valid CIDR")
+	ispn.Spec.Expose.Type = ispnv1.ExposeTypeNodePort
+	ispn.Spec.Expose.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	assert.EqualError(t, validateExposeEndpoints(ispn), "infinispan.spec.expose.loadBalancerSourceRanges is only supported for type=LoadBalancer")
+}
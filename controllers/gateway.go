@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const GatewayRouteSpecHashAnnotation = "infinispan.org/gateway-route-spec-hash"
+
+// The Gateway API is accessed unstructured, so that the operator doesn't depend on a specific release of it
+var (
+	GatewayGroupVersion  = schema.GroupVersion{Group: "gateway.networking.k8s.io", Version: "v1"}
+	TLSRouteGroupVersion = schema.GroupVersion{Group: "gateway.networking.k8s.io", Version: "v1alpha2"}
+)
+
+func newGatewayObject(gv schema.GroupVersion, kind string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gv.WithKind(kind))
+	return obj
+}
+
+// newGatewayRoute returns the TLSRoute exposing the Infinispan. TLS is passed through to the server so that HotRod
+// and REST clients connect to the single endpoint port based on SNI, an HTTPRoute cannot carry HotRod
+func newGatewayRoute() *unstructured.Unstructured {
+	return newGatewayObject(TLSRouteGroupVersion, consts.ExternalTypeTLSRoute)
+}
+
+// computeGatewayRoute computes the TLSRoute object
+func computeGatewayRoute(ispn *ispnv1.Infinispan) *unstructured.Unstructured {
+	route := newGatewayRoute()
+	route.SetName(ispn.GetServiceExternalName())
+	route.SetNamespace(ispn.Namespace)
+
+	labels := ExternalServiceLabels(ispn.Name)
+	// This way CR labels will override operator labels with same name
+	ispn.AddOperatorLabelsForServices(labels)
+	ispn.AddLabelsForServices(labels)
+	route.SetLabels(labels)
+
+	gateway := ispn.Spec.Expose.Gateway
+	parentRef := map[string]interface{}{"name": gateway.Name}
+	if gateway.Namespace != "" {
+		parentRef["namespace"] = gateway.Namespace
+	}
+	if gateway.SectionName != "" {
+		parentRef["sectionName"] = gateway.SectionName
+	}
+	backendRef := map[string]interface{}{
+		"name": ispn.Name,
+		"port": int64(consts.InfinispanUserPort),
+	}
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules": []interface{}{
+			map[string]interface{}{"backendRefs": []interface{}{backendRef}},
+		},
+	}
+	if ispn.Spec.Expose.Host != "" {
+		spec["hostnames"] = []interface{}{ispn.Spec.Expose.Host}
+	}
+	route.Object["spec"] = spec
+	return route
+}
+
+// reconcileGatewayRoute creates or updates the route. The spec is only overwritten when the computed spec changes,
+// as the fields defaulted by the Gateway API would otherwise cause an update on every reconciliation
+func (s serviceRequest) reconcileGatewayRoute(route *unstructured.Unstructured) error {
+	spec, err := json.Marshal(route.Object["spec"])
+	if err != nil {
+		return err
+	}
+	specHash := hash.HashByte(spec)
+
+	existing := newGatewayObject(route.GroupVersionKind().GroupVersion(), route.GetKind())
+	existing.SetName(route.GetName())
+	existing.SetNamespace(route.GetNamespace())
+	result, err := controllerutil.CreateOrUpdate(s.ctx, s.Client, existing, func() error {
+		existing.SetLabels(route.GetLabels())
+		annotations := existing.GetAnnotations()
+		if annotations[GatewayRouteSpecHashAnnotation] != specHash {
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[GatewayRouteSpecHashAnnotation] = specHash
+			existing.SetAnnotations(annotations)
+			existing.Object["spec"] = route.Object["spec"]
+		}
+		ApplyPropagatedMetadata(s.infinispan, existing)
+		return controllerutil.SetControllerReference(s.infinispan, existing, s.scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update %s: %w", route.GetKind(), err)
+	}
+	if result != controllerutil.OperationResultNone {
+		s.log.Info(fmt.Sprintf("%s %s %s", strings.Title(string(result)), existing.GetKind(), existing.GetName()))
+	}
+	return nil
+}
+
+// lookupGatewayRouteAddress waits for the route to be accepted by the Gateway and returns the address it is reachable on
+func (r *infinispanRequest) lookupGatewayRouteAddress() (string, *ctrl.Result, error) {
+	ispn := r.infinispan
+	route := newGatewayRoute()
+	if !r.isTypeSupported(route.GetKind()) {
+		return "", nil, nil
+	}
+	if result, err := kube.LookupResource(ispn.GetServiceExternalName(), ispn.Namespace, route, ispn, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return "", result, err
+	}
+	if !isGatewayRouteAccepted(route) {
+		r.reqLogger.Info("Waiting for the Gateway to accept the route", route.GetKind(), route.GetName())
+		return "", &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+
+	gatewayRef := ispn.Spec.Expose.Gateway
+	namespace := gatewayRef.Namespace
+	if namespace == "" {
+		namespace = ispn.Namespace
+	}
+	gateway := newGatewayObject(GatewayGroupVersion, "Gateway")
+	if err := r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: gatewayRef.Name}, gateway); err != nil {
+		return "", &ctrl.Result{}, err
+	}
+	address := gatewayRouteAddress(ispn, route, gateway)
+	if address == "" {
+		r.reqLogger.Info("Gateway address not ready yet. Waiting on value in reconcile loop", "gateway", gatewayRef.Name)
+		return "", &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+	return address, nil, nil
+}
+
+// isGatewayRouteAccepted returns true once every parent Gateway has accepted the route
+func isGatewayRouteAccepted(route *unstructured.Unstructured) bool {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	if len(parents) == 0 {
+		return false
+	}
+	for _, parent := range parents {
+		conditions, _, _ := unstructured.NestedSlice(parent.(map[string]interface{}), "conditions")
+		accepted := false
+		for _, condition := range conditions {
+			c := condition.(map[string]interface{})
+			if c["type"] == "Accepted" && c["status"] == "True" {
+				accepted = true
+			}
+		}
+		if !accepted {
+			return false
+		}
+	}
+	return true
+}
+
+// gatewayRouteAddress returns the host[:port] clients reach the route on. The host is taken from the route hostnames,
+// the hostname of the Gateway listener or the address assigned to the Gateway, in this order
+func gatewayRouteAddress(ispn *ispnv1.Infinispan, route, gateway *unstructured.Unstructured) string {
+	protocol, defaultPort := "TLS", int64(443)
+
+	var listener map[string]interface{}
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	for _, l := range listeners {
+		l := l.(map[string]interface{})
+		if sectionName := ispn.Spec.Expose.Gateway.SectionName; sectionName != "" {
+			if l["name"] == sectionName {
+				listener = l
+				break
+			}
+		} else if l["protocol"] == protocol {
+			listener = l
+			break
+		}
+	}
+
+	host := ""
+	if hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames"); len(hostnames) > 0 {
+		host = hostnames[0]
+	} else if hostname, ok := listener["hostname"].(string); ok && !strings.HasPrefix(hostname, "*") {
+		host = hostname
+	} else if addresses, _, _ := unstructured.NestedSlice(gateway.Object, "status", "addresses"); len(addresses) > 0 {
+		host, _ = addresses[0].(map[string]interface{})["value"].(string)
+	}
+	if host == "" {
+		return ""
+	}
+
+	if port, ok := listener["port"].(int64); ok && port != defaultPort {
+		return fmt.Sprintf("%s:%d", host, port)
+	}
+	return host
+}
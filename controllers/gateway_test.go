@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func gatewayInfinispan(host string, encrypted bool) *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec: ispnv1.InfinispanSpec{
+			Expose: &ispnv1.ExposeSpec{
+				Type:    ispnv1.ExposeTypeGatewayRoute,
+				Host:    host,
+				Gateway: &ispnv1.GatewayParentReference{Name: "gateway", Namespace: "gateways"},
+			},
+		},
+	}
+	if encrypted {
+		ispn.Spec.Security.EndpointEncryption = &ispnv1.EndpointEncryption{
+			Type:           ispnv1.CertificateSourceTypeSecret,
+			CertSecretName: "tls-secret",
+		}
+	}
+	return ispn
+}
+
+func gatewayWithListeners(addresses []interface{}, listeners ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   map[string]interface{}{"listeners": listeners},
+		"status": map[string]interface{}{"addresses": addresses},
+	}}
+}
+
+func TestComputeGatewayRoute(t *testing.T) {
+	route := computeGatewayRoute(gatewayInfinispan("", true))
+	assert.Equal(t, consts.ExternalTypeTLSRoute, route.GetKind())
+	assert.Equal(t, TLSRouteGroupVersion.String(), route.GetAPIVersion())
+	assert.Equal(t, "example-infinispan-external", route.GetName())
+	_, found, _ := unstructured.NestedSlice(route.Object, "spec", "hostnames")
+	assert.False(t, found)
+
+	route = computeGatewayRoute(gatewayInfinispan("infinispan.example.com", true))
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	assert.Equal(t, []string{"infinispan.example.com"}, hostnames)
+
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "gateway", "namespace": "gateways"}}, parentRefs)
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	backendRefs, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "backendRefs")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "example-infinispan", "port": int64(consts.InfinispanUserPort)}}, backendRefs)
+
+	// The computed route must be deep copyable, as required by the client cache
+	assert.NotPanics(t, func() { route.DeepCopy() })
+}
+
+func TestIsGatewayRouteAccepted(t *testing.T) {
+	route := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	assert.False(t, isGatewayRouteAccepted(route))
+
+	parent := func(status string) interface{} {
+		return map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "ResolvedRefs", "status": "True"},
+				map[string]interface{}{"type": "Accepted", "status": status},
+			},
+		}
+	}
+	route.Object["status"] = map[string]interface{}{"parents": []interface{}{parent("True"), parent("False")}}
+	assert.False(t, isGatewayRouteAccepted(route))
+
+	route.Object["status"] = map[string]interface{}{"parents": []interface{}{parent("True")}}
+	assert.True(t, isGatewayRouteAccepted(route))
+}
+
+func TestGatewayRouteAddress(t *testing.T) {
+	addresses := []interface{}{map[string]interface{}{"type": "IPAddress", "value": "10.0.0.1"}}
+	httpListener := map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80)}
+	tlsListener := map[string]interface{}{"name": "tls", "protocol": "TLS", "port": int64(8443), "hostname": "*.example.com"}
+
+	// The Gateway address is used when neither the route nor the listener define a hostname
+	ispn := gatewayInfinispan("", true)
+	defaultListener := map[string]interface{}{"name": "tls", "protocol": "TLS", "port": int64(443)}
+	assert.Equal(t, "10.0.0.1", gatewayRouteAddress(ispn, computeGatewayRoute(ispn), gatewayWithListeners(addresses, httpListener, defaultListener)))
+
+	// Non default listener ports are part of the address, wildcard listener hostnames are ignored
+	assert.Equal(t, "10.0.0.1:8443", gatewayRouteAddress(ispn, computeGatewayRoute(ispn), gatewayWithListeners(addresses, httpListener, tlsListener)))
+
+	ispn = gatewayInfinispan("infinispan.example.com", true)
+	assert.Equal(t, "infinispan.example.com:8443", gatewayRouteAddress(ispn, computeGatewayRoute(ispn), gatewayWithListeners(addresses, httpListener, tlsListener)))
+
+	// The listener selected by sectionName takes precedence over the protocol
+	ispn = gatewayInfinispan("", true)
+	ispn.Spec.Expose.Gateway.SectionName = "infinispan"
+	named := map[string]interface{}{"name": "infinispan", "protocol": "HTTP", "port": int64(8080), "hostname": "infinispan.example.com"}
+	assert.Equal(t, "infinispan.example.com:8080", gatewayRouteAddress(ispn, computeGatewayRoute(ispn), gatewayWithListeners(addresses, httpListener, named)))
+
+	assert.Empty(t, gatewayRouteAddress(ispn, computeGatewayRoute(ispn), gatewayWithListeners(nil, httpListener)))
+}
+
+func TestValidateGatewayRouteEncryption(t *testing.T) {
+	assert.NoError(t, validateInfinispan(gatewayInfinispan("", true)))
+	assert.EqualError(t, validateInfinispan(gatewayInfinispan("", false)),
+		"infinispan.spec.expose.type=GatewayRoute requires infinispan.spec.security.endpointEncryption, the TLSRoute passes TLS through to the server")
+}
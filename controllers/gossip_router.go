@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"fmt"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	GossipRouterKeystoreVolumeName = "gossip-router-keystore-volume"
+	// GossipRouterKeystorePasswordEnv holds the keystore password of the Gossip Router, so that it isn't visible in
+	// the arguments of the Deployment
+	GossipRouterKeystorePasswordEnv = "KEYSTORE_PASSWORD"
+)
+
+// validateGossipRouter verifies the resources of the Gossip Router container
+func validateGossipRouter(i *ispnv1.Infinispan) error {
+	router := i.GetGossipRouterSpec()
+	if router == nil {
+		return nil
+	}
+	if router.Memory != "" {
+		if _, err := resource.ParseQuantity(router.Memory); err != nil {
+			return fmt.Errorf("infinispan.spec.service.sites.local.gossipRouter.memory '%s' is not a valid quantity: %w", router.Memory, err)
+		}
+	}
+	if router.CPU != "" {
+		if _, err := resource.ParseQuantity(router.CPU); err != nil {
+			return fmt.Errorf("infinispan.spec.service.sites.local.gossipRouter.cpu '%s' is not a valid quantity: %w", router.CPU, err)
+		}
+	}
+	if router.TLS != nil && router.TLS.SecretName == "" {
+		return fmt.Errorf("infinispan.spec.service.sites.local.gossipRouter.tls.secretName must be provided")
+	}
+	return nil
+}
+
+// gossipRouterResources returns the resources of the Gossip Router container, the container defaults being kept for
+// the resources that aren't configured
+func gossipRouterResources(i *ispnv1.Infinispan) corev1.ResourceRequirements {
+	resources := corev1.ResourceRequirements{}
+	router := i.GetGossipRouterSpec()
+	if router == nil {
+		return resources
+	}
+	quantities := corev1.ResourceList{}
+	if memory, err := resource.ParseQuantity(router.Memory); err == nil {
+		quantities[corev1.ResourceMemory] = memory
+	}
+	if cpu, err := resource.ParseQuantity(router.CPU); err == nil {
+		quantities[corev1.ResourceCPU] = cpu
+	}
+	if len(quantities) > 0 {
+		resources.Requests = quantities
+		resources.Limits = quantities.DeepCopy()
+	}
+	return resources
+}
+
+// gossipRouterKeystore returns the key and the type of the keystore of the Gossip Router Secret. The password is
+// required, as the Gossip Router and the relay nodes can't load a keystore without one
+func gossipRouterKeystore(secret *corev1.Secret) (string, string, error) {
+	key, ksType, err := userKeystore(secret)
+	if err != nil {
+		return "", "", err
+	}
+	if key == "" {
+		return "", "", fmt.Errorf("the Gossip Router keystore Secret '%s' must contain a '%s' or a '%s' key", secret.Name, EncryptKeystoreName, EncryptKeystoreJKSName)
+	}
+	if len(secret.Data[EncryptKeystorePasswordKey]) == 0 {
+		return "", "", fmt.Errorf("the Gossip Router keystore Secret '%s' must contain a '%s' key", secret.Name, EncryptKeystorePasswordKey)
+	}
+	return key, ksType, nil
+}
+
+// applyGossipRouterTLS mounts the keystore Secret in the Gossip Router container and configures the TLS server socket.
+// The hash of the keystore rolls out the Gossip Router pods when the Secret changes
+func applyGossipRouterTLS(i *ispnv1.Infinispan, secret *corev1.Secret, spec *corev1.PodSpec) error {
+	key, ksType, err := gossipRouterKeystore(secret)
+	if err != nil {
+		return err
+	}
+	addSecretVolume(secret.Name, GossipRouterKeystoreVolumeName, consts.ServerEncryptXSiteRoot, spec)
+	container := &spec.Containers[0]
+	container.Env = append(container.Env,
+		corev1.EnvVar{
+			Name: GossipRouterKeystorePasswordEnv,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+					Key:                  EncryptKeystorePasswordKey,
+				},
+			},
+		},
+		corev1.EnvVar{Name: "KEYSTORE_HASH", Value: hash.HashMap(secret.Data)},
+	)
+	container.Args = append(container.Args,
+		"-tls_protocol", i.GetGossipRouterTLSProtocol(),
+		"-tls_keystore_path", fmt.Sprintf("%s/%s", consts.ServerEncryptXSiteRoot, key),
+		"-tls_keystore_password", fmt.Sprintf("$(%s)", GossipRouterKeystorePasswordEnv),
+		"-tls_keystore_type", ksType,
+	)
+	return nil
+}
+
+// configureCrossSiteEncryption configures the relay nodes to connect to the Gossip Routers with the TLS keystore
+// mounted from the Gossip Router Secret
+func (r configRequest) configureCrossSiteEncryption(xsite *config.XSite) (*reconcile.Result, error) {
+	i := r.infinispan
+	tls := i.GetGossipRouterTLS()
+	if tls == nil {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if result, err := kube.LookupResource(tls.SecretName, i.Namespace, secret, i, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return result, err
+	}
+	key, ksType, err := gossipRouterKeystore(secret)
+	if err != nil {
+		return &reconcile.Result{}, err
+	}
+	xsite.Encryption = &config.XSiteEncryption{
+		Protocol: i.GetGossipRouterTLSProtocol(),
+		Keystore: config.Keystore{
+			Path:     fmt.Sprintf("%s/%s", consts.ServerEncryptXSiteRoot, key),
+			Password: string(secret.Data[EncryptKeystorePasswordKey]),
+			Type:     ksType,
+		},
+	}
+	return nil, nil
+}
+
+// AddVolumeForCrossSiteEncryption mounts the Gossip Router keystore Secret in the server container
+func AddVolumeForCrossSiteEncryption(i *ispnv1.Infinispan, spec *corev1.PodSpec) {
+	addSecretVolume(i.GetGossipRouterTLS().SecretName, GossipRouterKeystoreVolumeName, consts.ServerEncryptXSiteRoot, spec)
+}
+
+// gossipRouterReadyPods returns the number of Gossip Router pods ready to accept connections
+func gossipRouterReadyPods(podList *corev1.PodList) int {
+	ready := 0
+	for _, pod := range podList.Items {
+		if statuses := pod.Status.ContainerStatuses; len(statuses) > 0 && statuses[0].Ready {
+			ready++
+		}
+	}
+	return ready
+}
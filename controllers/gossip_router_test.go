@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func gossipRouterInfinispan(router *ispnv1.GossipRouterSpec) *ispnv1.Infinispan {
+	ispn := crossSiteInfinispan(ispnv1.InfinispanSiteLocationSpec{Name: "site-b", URL: "infinispan+xsite://site-b.example.com"})
+	ispn.Spec.Replicas = 2
+	ispn.Spec.Service.Sites.Local.GossipRouter = router
+	return ispn
+}
+
+func TestValidateGossipRouter(t *testing.T) {
+	assert.NoError(t, validateGossipRouter(gossipRouterInfinispan(nil)))
+	assert.NoError(t, validateGossipRouter(gossipRouterInfinispan(&ispnv1.GossipRouterSpec{Memory: "512Mi", CPU: "500m"})))
+	assert.Error(t, validateGossipRouter(gossipRouterInfinispan(&ispnv1.GossipRouterSpec{Memory: "lots"})))
+	assert.Error(t, validateGossipRouter(gossipRouterInfinispan(&ispnv1.GossipRouterSpec{CPU: "fast"})))
+	assert.EqualError(t, validateGossipRouter(gossipRouterInfinispan(&ispnv1.GossipRouterSpec{TLS: &ispnv1.GossipRouterTLSSpec{}})),
+		"infinispan.spec.service.sites.local.gossipRouter.tls.secretName must be provided")
+}
+
+func TestGetGossipRouterDeployment(t *testing.T) {
+	r := &infinispanRequest{}
+	deployment, err := r.GetGossipRouterDeployment(gossipRouterInfinispan(nil), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+	assert.Empty(t, deployment.Spec.Template.Spec.Containers[0].Resources.Limits)
+	assert.Empty(t, deployment.Spec.Template.Spec.Volumes)
+
+	ispn := gossipRouterInfinispan(&ispnv1.GossipRouterSpec{
+		Replicas: pointer.Int32Ptr(3),
+		Memory:   "256Mi",
+		CPU:      "500m",
+		TLS:      &ispnv1.GossipRouterTLSSpec{SecretName: "router-tls"},
+	})
+	keystore := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "router-tls", Namespace: "testing"},
+		Data:       map[string][]byte{EncryptKeystoreJKSName: jksKeystore, EncryptKeystorePasswordKey: []byte("secret")},
+	}
+	deployment, err = r.GetGossipRouterDeployment(ispn, keystore)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, resource.MustParse("256Mi"), container.Resources.Limits[corev1.ResourceMemory])
+	assert.Equal(t, resource.MustParse("500m"), container.Resources.Requests[corev1.ResourceCPU])
+	assert.Equal(t, []string{"-port", "7900", "-dump_msgs", "registration",
+		"-tls_protocol", "TLSv1.3",
+		"-tls_keystore_path", consts.ServerEncryptXSiteRoot + "/keystore.jks",
+		"-tls_keystore_password", "$(KEYSTORE_PASSWORD)",
+		"-tls_keystore_type", KeystoreTypeJKS,
+	}, container.Args)
+	assert.Equal(t, "router-tls", container.Env[0].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, "router-tls", deployment.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, consts.ServerEncryptXSiteRoot, container.VolumeMounts[0].MountPath)
+
+	// The Gossip Router pods are shut down with the cluster
+	ispn.Spec.Replicas = 0
+	deployment, err = r.GetGossipRouterDeployment(ispn, keystore)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), *deployment.Spec.Replicas)
+
+	keystore.Data = map[string][]byte{EncryptKeystoreName: pkcs12Keystore}
+	_, err = r.GetGossipRouterDeployment(ispn, keystore)
+	assert.EqualError(t, err, "the Gossip Router keystore Secret 'router-tls' must contain a 'password' key")
+}
+
+func TestGossipRouterReadyPods(t *testing.T) {
+	pods := &corev1.PodList{Items: []corev1.Pod{rebalancePod("example-infinispan-tunnel-a"), dataVolumePod("example-infinispan-tunnel-b")}}
+	assert.Equal(t, 1, gossipRouterReadyPods(pods))
+}
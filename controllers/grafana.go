@@ -2,15 +2,21 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	rice "github.com/GeertJohan/go.rice"
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
 	consts "github.com/infinispan/infinispan-operator/controllers/constants"
 	grafanav1alpha1 "github.com/infinispan/infinispan-operator/pkg/apis/integreatly/v1alpha1"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
 	"github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -19,6 +25,8 @@ const (
 	grafanaDashboardNamespaceKey  = "grafana.dashboard.namespace"
 	grafanaDashboardNameKey       = "grafana.dashboard.name"
 	grafanaDashboardMonitoringKey = "grafana.dashboard.monitoring.key"
+
+	defaultGrafanaDashboardMonitoringKey = "middleware"
 )
 
 // +kubebuilder:rbac:groups=integreatly.org,resources=grafanadashboards,verbs=get;list;watch;create;delete;update
@@ -83,11 +91,7 @@ func emptyDashboard(config map[string]string) *grafanav1alpha1.GrafanaDashboard
 }
 
 func populateDashboard(dashboard *grafanav1alpha1.GrafanaDashboard, config map[string]string) error {
-	box, err := rice.FindBox("resources")
-	if err != nil {
-		return err
-	}
-	dashboardJSONData, err := box.String("grafana_dashboard.json")
+	dashboardJSONData, err := loadDashboardJSON()
 	if err != nil {
 		return err
 	}
@@ -95,8 +99,21 @@ func populateDashboard(dashboard *grafanav1alpha1.GrafanaDashboard, config map[s
 		"monitoring-key": config[grafanaDashboardMonitoringKey],
 		"app":            "grafana",
 	}
-	dashboard.Spec = grafanav1alpha1.GrafanaDashboardSpec{
-		Json: dashboardJSONData,
+	dashboard.Spec = dashboardSpec(dashboardJSONData)
+	return nil
+}
+
+func loadDashboardJSON() (string, error) {
+	box, err := rice.FindBox("resources")
+	if err != nil {
+		return "", err
+	}
+	return box.String("grafana_dashboard.json")
+}
+
+func dashboardSpec(dashboardJSON string) grafanav1alpha1.GrafanaDashboardSpec {
+	return grafanav1alpha1.GrafanaDashboardSpec{
+		Json: dashboardJSON,
 		// TODO migration to 1.22. No more needed Name field?
 		// Name: "infinispan.json",
 		Datasources: []grafanav1alpha1.GrafanaDashboardDatasource{
@@ -106,7 +123,117 @@ func populateDashboard(dashboard *grafanav1alpha1.GrafanaDashboard, config map[s
 			},
 		},
 	}
-	return nil
+}
+
+// reconcileGrafanaDashboard creates the GrafanaDashboard of the cluster when dashboards are enabled and removes it otherwise
+func (s serviceRequest) reconcileGrafanaDashboard() error {
+	ispn := s.infinispan
+	if !s.isTypeSupported(consts.GrafanaDashboardType) {
+		if ispn.IsGrafanaDashboardEnabled() {
+			s.log.Info("Grafana CRD not present - not installing the cluster dashboard CR")
+		}
+		return nil
+	}
+
+	dashboard := &grafanav1alpha1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ispn.GetGrafanaDashboardName(),
+			Namespace: ispn.Namespace,
+		},
+	}
+	if !ispn.IsGrafanaDashboardEnabled() {
+		if err := s.Client.Delete(s.ctx, dashboard); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	dashboardJSONData, err := loadDashboardJSON()
+	if err != nil {
+		return err
+	}
+	if dashboardJSONData, err = clusterDashboardJSON(dashboardJSONData, ispn); err != nil {
+		return err
+	}
+	operatorNs, err := kubernetes.GetOperatorNamespace()
+	if err != nil {
+		return err
+	}
+	monitoringKey, err := dashboardMonitoringKey(s.ctx, s.Client, operatorNs)
+	if err != nil {
+		return err
+	}
+	_, err = controllerutil.CreateOrUpdate(s.ctx, s.Client, dashboard, func() error {
+		labels := LabelsResource(ispn.Name, "infinispan-dashboard")
+		labels["monitoring-key"] = monitoringKey
+		labels["app"] = "grafana"
+		dashboard.Labels = labels
+		dashboard.Spec = dashboardSpec(dashboardJSONData)
+		ApplyPropagatedMetadata(ispn, dashboard)
+		return controllerutil.SetControllerReference(ispn, dashboard, s.scheme)
+	})
+	return err
+}
+
+// dashboardMonitoringKey returns the monitoring key that the operator ConfigMap configures for the dashboards, so that
+// the cluster dashboards are selected by the same Grafana instance as the operator dashboard
+func dashboardMonitoringKey(ctx context.Context, c client.Client, operatorNs string) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: operatorNs, Name: configMapName}, configMap); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return defaultGrafanaDashboardMonitoringKey, nil
+		}
+		return "", fmt.Errorf("unable to load the operator configuration: %w", err)
+	}
+	if key := configMap.Data[grafanaDashboardMonitoringKey]; key != "" {
+		return key, nil
+	}
+	return defaultGrafanaDashboardMonitoringKey, nil
+}
+
+// clusterDashboardJSON scopes the operator dashboard to a single cluster, replacing the namespace and cluster
+// selectors with hidden constants and giving the dashboard its own title and uid
+func clusterDashboardJSON(dashboardJSON string, ispn *ispnv1.Infinispan) (string, error) {
+	dashboard := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(dashboardJSON), &dashboard); err != nil {
+		return "", fmt.Errorf("unable to parse Grafana dashboard: %w", err)
+	}
+	delete(dashboard, "id")
+	dashboard["title"] = fmt.Sprintf("Infinispan %s/%s", ispn.Namespace, ispn.Name)
+	dashboard["uid"] = hash.HashString(ispn.Namespace + "/" + ispn.Name)
+
+	constants := map[string]string{
+		"namespace": ispn.Namespace,
+		// The metrics are scraped from the admin service, which is the job of the ServiceMonitor
+		"cluster": ispn.GetAdminServiceName(),
+	}
+	if templating, ok := dashboard["templating"].(map[string]interface{}); ok {
+		variables, _ := templating["list"].([]interface{})
+		for i, v := range variables {
+			variable, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := variable["name"].(string)
+			if value, ok := constants[name]; ok {
+				variables[i] = map[string]interface{}{
+					"name":    name,
+					"label":   variable["label"],
+					"type":    "constant",
+					"hide":    2,
+					"query":   value,
+					"current": map[string]interface{}{"text": value, "value": value},
+					"options": []interface{}{map[string]interface{}{"selected": true, "text": value, "value": value}},
+				}
+			}
+		}
+	}
+
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
 }
 
 func (r *ReconcileOperatorConfig) deleteDashboardOnKeyChanged(ctx context.Context, newCfg, curCfg map[string]string) error {
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterDashboardJSON(t *testing.T) {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"}}
+	dashboardJSON, err := loadDashboardJSON()
+	assert.NoError(t, err)
+
+	clusterJSON, err := clusterDashboardJSON(dashboardJSON, ispn)
+	assert.NoError(t, err)
+
+	dashboard := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(clusterJSON), &dashboard))
+	assert.Equal(t, "Infinispan testing/example-infinispan", dashboard["title"])
+	assert.NotContains(t, dashboard, "id")
+	assert.Len(t, dashboard["uid"], 40)
+	assert.NotEmpty(t, dashboard["panels"])
+
+	variables := map[string]map[string]interface{}{}
+	for _, v := range dashboard["templating"].(map[string]interface{})["list"].([]interface{}) {
+		variable := v.(map[string]interface{})
+		variables[variable["name"].(string)] = variable
+	}
+	assert.Equal(t, "constant", variables["namespace"]["type"])
+	assert.Equal(t, "testing", variables["namespace"]["query"])
+	assert.Equal(t, "constant", variables["cluster"]["type"])
+	assert.Equal(t, "example-infinispan-admin", variables["cluster"]["query"])
+	// The cache selector is still available to the user
+	assert.Equal(t, "query", variables["caches"]["type"])
+
+	// Each cluster gets its own dashboard
+	other, err := clusterDashboardJSON(dashboardJSON, &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "testing"}})
+	assert.NoError(t, err)
+	assert.NotEqual(t, clusterJSON, other)
+
+	_, err = clusterDashboardJSON("not json", ispn)
+	assert.Error(t, err)
+}
+
+func TestIsGrafanaDashboardEnabled(t *testing.T) {
+	ispn := &ispnv1.Infinispan{}
+	assert.False(t, ispn.IsGrafanaDashboardEnabled())
+	ispn.Spec.Monitoring = &ispnv1.InfinispanMonitoringSpec{Dashboards: true}
+	assert.True(t, ispn.IsGrafanaDashboardEnabled())
+}
+
+func TestDashboardMonitoringKey(t *testing.T) {
+	ctx := context.TODO()
+	key, err := dashboardMonitoringKey(ctx, fake.NewFakeClientWithScheme(scheme.Scheme), "operator")
+	assert.NoError(t, err)
+	assert.Equal(t, "middleware", key)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: "operator"},
+		Data:       map[string]string{grafanaDashboardNamespaceKey: "grafana"},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, configMap)
+	key, err = dashboardMonitoringKey(ctx, c, "operator")
+	assert.NoError(t, err)
+	assert.Equal(t, "middleware", key)
+
+	configMap.Data[grafanaDashboardMonitoringKey] = "infinispan"
+	assert.NoError(t, c.Update(ctx, configMap))
+	key, err = dashboardMonitoringKey(ctx, c, "operator")
+	assert.NoError(t, err)
+	assert.Equal(t, "infinispan", key)
+}
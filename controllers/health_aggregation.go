@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterHealthPath is the path of the metrics server of the operator serving the health of the managed clusters
+const ClusterHealthPath = "/clusters/health"
+
+// ClusterHealthReport is the health of the clusters managed by the operator
+type ClusterHealthReport struct {
+	Clusters []ClusterHealthStatus `json:"clusters"`
+}
+
+// ClusterHealthStatus is the health of a cluster, from the status of its Infinispan CR and from the health of the
+// server recorded by the last reconcile of the cluster
+type ClusterHealthStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Replicas  int32  `json:"replicas"`
+	Version   string `json:"version,omitempty"`
+	// Status of the WellFormed condition
+	WellFormed metav1.ConditionStatus `json:"wellFormed"`
+	// Pods of the cluster, by state
+	Pods infinispanv1.DeploymentStatus `json:"pods"`
+	// Health of the server, absent until the cluster is reconciled by the operator replica holding the leadership
+	Health *ServerHealthStatus            `json:"health,omitempty"`
+	XSite  []infinispanv1.CrossSiteStatus `json:"xsite,omitempty"`
+}
+
+// ServerHealthStatus is the health of the cluster as seen by one of its pods
+type ServerHealthStatus struct {
+	// Health status of the cluster, e.g. HEALTHY or DEGRADED
+	Status  string   `json:"status,omitempty"`
+	Members []string `json:"members,omitempty"`
+	// Health status of each cache
+	Caches map[string]string `json:"caches,omitempty"`
+	// Error retrieving the health from the server
+	Error      string    `json:"error,omitempty"`
+	ObservedAt time.Time `json:"observedAt"`
+}
+
+// clusterHealthRecords holds the server health recorded by the reconcile loop of each cluster
+var clusterHealthRecords = struct {
+	sync.RWMutex
+	m map[types.NamespacedName]*ServerHealthStatus
+}{m: make(map[types.NamespacedName]*ServerHealthStatus)}
+
+// recordClusterHealth records the health of the cluster as seen by its first ready pod
+func (r *infinispanRequest) recordClusterHealth(podList *corev1.PodList, cluster ispn.ClusterInterface) {
+	record := &ServerHealthStatus{ObservedAt: time.Now()}
+	var podName string
+	for _, pod := range podList.Items {
+		if kube.IsPodReady(pod) {
+			podName = pod.Name
+			break
+		}
+	}
+	if podName == "" {
+		record.Error = "no pod is ready"
+	} else if health, err := cluster.GetHealth(podName); err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Status = health.ClusterHealth.Status
+		record.Members = health.ClusterHealth.Nodes
+		sort.Strings(record.Members)
+		if len(health.CacheHealth) > 0 {
+			record.Caches = make(map[string]string, len(health.CacheHealth))
+			for _, c := range health.CacheHealth {
+				record.Caches[c.Name] = c.Status
+			}
+		}
+	}
+	clusterHealthRecords.Lock()
+	clusterHealthRecords.m[r.req.NamespacedName] = record
+	clusterHealthRecords.Unlock()
+	r.reportHealthChanges(record)
+}
+
+// forgetClusterHealth removes the health recorded for a deleted cluster
+func forgetClusterHealth(name types.NamespacedName) {
+	clusterHealthRecords.Lock()
+	delete(clusterHealthRecords.m, name)
+	clusterHealthRecords.Unlock()
+	forgetHealthEvents(name)
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// clusterHealthHandler serves the ClusterHealthReport of the clusters in JSON, optionally filtered with the namespace
+// query parameter. Requests must carry the bearer token of a user allowed to list the Infinispan CRs of the namespace,
+// or of all the namespaces when no namespace is requested
+type clusterHealthHandler struct {
+	client client.Client
+	log    logr.Logger
+}
+
+// NewClusterHealthHandler returns the handler of the ClusterHealthPath, listing the Infinispan CRs with the client
+func NewClusterHealthHandler(c client.Client, log logr.Logger) http.Handler {
+	return &clusterHealthHandler{client: c, log: log}
+}
+
+func (h *clusterHealthHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	namespace := req.URL.Query().Get("namespace")
+	if status, err := h.authorize(req, namespace); err != nil {
+		if status == http.StatusInternalServerError {
+			h.log.Error(err, "unable to authorize the cluster health request")
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	var opts []client.ListOption
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	list := &infinispanv1.InfinispanList{}
+	if err := h.client.List(req.Context(), list, opts...); err != nil {
+		h.log.Error(err, "unable to list the Infinispan clusters")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(clusterHealthReport(list.Items)); err != nil {
+		h.log.Error(err, "unable to write the cluster health report")
+	}
+}
+
+// authorize reviews the bearer token of the request and verifies that its user can list the Infinispan CRs of the
+// namespace, returning the HTTP status of the failure
+func (h *clusterHealthHandler) authorize(req *http.Request, namespace string) (int, error) {
+	authorization := req.Header.Get("Authorization")
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if !strings.HasPrefix(authorization, "Bearer ") || token == "" {
+		return http.StatusUnauthorized, errors.New("a bearer token is required")
+	}
+	tokenReview := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := h.client.Create(req.Context(), tokenReview); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, errors.New("the bearer token is not valid")
+	}
+
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "list",
+				Group:     infinispanv1.GroupVersion.Group,
+				Resource:  "infinispans",
+			},
+		},
+	}
+	if err := h.client.Create(req.Context(), review); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !review.Status.Allowed {
+		return http.StatusForbidden, fmt.Errorf("user '%s' cannot list the Infinispan clusters", user.Username)
+	}
+	return http.StatusOK, nil
+}
+
+// clusterHealthReport merges the status of the clusters with their recorded server health
+func clusterHealthReport(clusters []infinispanv1.Infinispan) *ClusterHealthReport {
+	report := &ClusterHealthReport{Clusters: make([]ClusterHealthStatus, 0, len(clusters))}
+	clusterHealthRecords.RLock()
+	defer clusterHealthRecords.RUnlock()
+	for _, i := range clusters {
+		status := ClusterHealthStatus{
+			Namespace:  i.Namespace,
+			Name:       i.Name,
+			Replicas:   i.Spec.Replicas,
+			Version:    i.Status.Version,
+			WellFormed: i.GetCondition(infinispanv1.ConditionWellFormed).Status,
+			Pods:       i.Status.PodStatus,
+			XSite:      i.Status.XSite,
+		}
+		if record, ok := clusterHealthRecords.m[types.NamespacedName{Namespace: i.Namespace, Name: i.Name}]; ok {
+			copied := *record
+			status.Health = &copied
+		}
+		report.Clusters = append(report.Clusters, status)
+	}
+	sort.Slice(report.Clusters, func(a, b int) bool {
+		if report.Clusters[a].Namespace != report.Clusters[b].Namespace {
+			return report.Clusters[a].Namespace < report.Clusters[b].Namespace
+		}
+		return report.Clusters[a].Name < report.Clusters[b].Name
+	})
+	return report
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reportingCluster reports the health of its caches
+type reportingCluster struct {
+	ispn.ClusterInterface
+	health *ispn.Health
+}
+
+func (c *reportingCluster) GetHealth(podName string) (*ispn.Health, error) {
+	return c.health, nil
+}
+
+// reviewingClient authenticates the tokens as the user of the same name, and allows the users to list the Infinispan CRs
+// of the namespaces they are mapped to, all the namespaces for the empty namespace
+type reviewingClient struct {
+	client.Client
+	namespaces map[string]string
+}
+
+func (c *reviewingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		if _, ok := c.namespaces[review.Spec.Token]; ok {
+			review.Status.Authenticated = true
+			review.Status.User.Username = review.Spec.Token
+		}
+		return nil
+	case *authorizationv1.SubjectAccessReview:
+		attributes := review.Spec.ResourceAttributes
+		if namespace := c.namespaces[review.Spec.User]; attributes.Verb == "list" && attributes.Resource == "infinispans" &&
+			(namespace == "" || namespace == attributes.Namespace) {
+			review.Status.Allowed = true
+		}
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestClusterHealthHandler(t *testing.T) {
+	infinispan := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec:       infinispanv1.InfinispanSpec{Replicas: 2},
+		Status: infinispanv1.InfinispanStatus{
+			Conditions: []metav1.Condition{{Type: string(infinispanv1.ConditionWellFormed), Status: metav1.ConditionTrue}},
+			PodStatus:  infinispanv1.DeploymentStatus{Ready: []string{"example-infinispan-0", "example-infinispan-1"}},
+			XSite:      []infinispanv1.CrossSiteStatus{{Name: "NYC", Status: "online"}},
+		},
+	}
+	other := &infinispanv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "z-namespace"}}
+	pod := dataVolumePod("example-infinispan-0")
+	r := volumeExpansionRequest(t, "1Gi", infinispan, other)
+	r.req.NamespacedName = types.NamespacedName{Namespace: namespace, Name: "example-infinispan"}
+	defer forgetClusterHealth(r.req.NamespacedName)
+
+	r.recordClusterHealth(&corev1.PodList{Items: []corev1.Pod{pod}}, &reportingCluster{health: &ispn.Health{
+		ClusterHealth: ispn.ClusterHealth{Status: ispn.ClusterHealthHealthy, Nodes: []string{"example-infinispan-1", "example-infinispan-0"}},
+		CacheHealth:   []ispn.CacheHealth{{Name: "a", Status: ispn.ClusterHealthHealthy}},
+	}})
+
+	handler := NewClusterHealthHandler(&reviewingClient{Client: r.Client, namespaces: map[string]string{"admin": "", "viewer": namespace}}, logf.Log)
+	serve := func(url, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rsp := httptest.NewRecorder()
+		handler.ServeHTTP(rsp, req)
+		return rsp
+	}
+	get := func(url string) *ClusterHealthReport {
+		rsp := serve(url, "admin")
+		assert.Equal(t, http.StatusOK, rsp.Code)
+		assert.Equal(t, "application/json", rsp.Header().Get("Content-Type"))
+		report := &ClusterHealthReport{}
+		assert.NoError(t, json.Unmarshal(rsp.Body.Bytes(), report))
+		return report
+	}
+
+	report := get(ClusterHealthPath)
+	assert.Len(t, report.Clusters, 2)
+	assert.Equal(t, "other", report.Clusters[1].Name)
+	assert.Nil(t, report.Clusters[1].Health)
+	assert.Equal(t, metav1.ConditionFalse, report.Clusters[1].WellFormed)
+
+	report = get(ClusterHealthPath + "?namespace=" + namespace)
+	assert.Len(t, report.Clusters, 1)
+	status := report.Clusters[0]
+	assert.Equal(t, int32(2), status.Replicas)
+	assert.Equal(t, metav1.ConditionTrue, status.WellFormed)
+	assert.Equal(t, infinispan.Status.PodStatus, status.Pods)
+	assert.Equal(t, infinispan.Status.XSite, status.XSite)
+	assert.Equal(t, ispn.ClusterHealthHealthy, status.Health.Status)
+	assert.Equal(t, []string{"example-infinispan-0", "example-infinispan-1"}, status.Health.Members)
+	assert.Equal(t, map[string]string{"a": ispn.ClusterHealthHealthy}, status.Health.Caches)
+
+	// The health of the deleted clusters is not reported
+	forgetClusterHealth(r.req.NamespacedName)
+	assert.Nil(t, get(ClusterHealthPath).Clusters[0].Health)
+
+	// Only the users allowed to list the Infinispan CRs of the requested namespaces are served
+	assert.Equal(t, http.StatusUnauthorized, serve(ClusterHealthPath, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(ClusterHealthPath, "unknown").Code)
+	assert.Equal(t, http.StatusForbidden, serve(ClusterHealthPath, "viewer").Code)
+	assert.Equal(t, http.StatusForbidden, serve(ClusterHealthPath+"?namespace=z-namespace", "viewer").Code)
+	assert.Equal(t, http.StatusOK, serve(ClusterHealthPath+"?namespace="+namespace, "viewer").Code)
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	EventReasonClusterHealthChanged = "ClusterHealthChanged"
+	EventReasonCacheHealthChanged   = "CacheHealthChanged"
+
+	// HealthEventInterval is the minimum interval between two health events of the same cluster or cache, so that a
+	// flapping health doesn't flood the Events. The changes within the interval are reported once it elapses
+	HealthEventInterval = time.Minute
+	// HealthEventsMaxCaches bounds the cache health events of a cluster per reconcile, the other changes being
+	// reported by a single event
+	HealthEventsMaxCaches = 10
+)
+
+// healthEventState is the health last reported with an event
+type healthEventState struct {
+	status     string
+	reportedAt time.Time
+}
+
+// healthEventStates holds the health reported by the events of each cluster, the caches being keyed by name and the
+// cluster by the empty name
+var healthEventStates = struct {
+	sync.Mutex
+	m map[types.NamespacedName]map[string]*healthEventState
+}{m: make(map[types.NamespacedName]map[string]*healthEventState)}
+
+// forgetHealthEvents removes the health reported for a deleted cluster
+func forgetHealthEvents(name types.NamespacedName) {
+	healthEventStates.Lock()
+	delete(healthEventStates.m, name)
+	healthEventStates.Unlock()
+}
+
+// healthChange is a health status to report with an event
+type healthChange struct {
+	cache    string
+	from, to string
+}
+
+// healthChanges returns the health changes of the cluster and of its caches to report, updating the reported states.
+// The first health observed is only reported when it isn't healthy, as the operator doesn't know the previous one
+func healthChanges(states map[string]*healthEventState, record *ServerHealthStatus) []healthChange {
+	current := map[string]string{"": record.Status}
+	for cache, status := range record.Caches {
+		current[cache] = status
+	}
+	for name := range states {
+		if _, ok := current[name]; !ok {
+			// The cache has been removed
+			delete(states, name)
+		}
+	}
+
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var changes []healthChange
+	for _, name := range names {
+		status := current[name]
+		state, ok := states[name]
+		if !ok {
+			states[name] = &healthEventState{status: status, reportedAt: record.ObservedAt}
+			if !isHealthy(status) {
+				changes = append(changes, healthChange{cache: name, to: status})
+			}
+			continue
+		}
+		if state.status == status || record.ObservedAt.Sub(state.reportedAt) < HealthEventInterval {
+			continue
+		}
+		changes = append(changes, healthChange{cache: name, from: state.status, to: status})
+		state.status = status
+		state.reportedAt = record.ObservedAt
+	}
+	return changes
+}
+
+func isHealthy(status string) bool {
+	return strings.HasPrefix(status, "HEALTHY")
+}
+
+func (c healthChange) eventType() string {
+	if isHealthy(c.to) {
+		return corev1.EventTypeNormal
+	}
+	return corev1.EventTypeWarning
+}
+
+func (c healthChange) message() string {
+	subject := "Cluster"
+	if c.cache != "" {
+		subject = fmt.Sprintf("Cache '%s'", c.cache)
+	}
+	if c.from == "" {
+		return fmt.Sprintf("%s health is %s", subject, c.to)
+	}
+	return fmt.Sprintf("%s health changed from %s to %s", subject, c.from, c.to)
+}
+
+// reportHealthChanges emits an event for each change of the health of the cluster and of its caches, so that the
+// history of the server health is listed with the Events of the Infinispan CR. The changes are rate limited
+func (r *infinispanRequest) reportHealthChanges(record *ServerHealthStatus) {
+	if record.Error != "" {
+		return
+	}
+	healthEventStates.Lock()
+	states, ok := healthEventStates.m[r.req.NamespacedName]
+	if !ok {
+		states = map[string]*healthEventState{}
+		healthEventStates.m[r.req.NamespacedName] = states
+	}
+	changes := healthChanges(states, record)
+	healthEventStates.Unlock()
+
+	var cacheEvents int
+	var unreported []healthChange
+	for _, change := range changes {
+		if change.cache == "" {
+			r.eventRec.Event(r.infinispan, change.eventType(), EventReasonClusterHealthChanged, change.message())
+		} else if cacheEvents < HealthEventsMaxCaches {
+			r.eventRec.Event(r.infinispan, change.eventType(), EventReasonCacheHealthChanged, change.message())
+			cacheEvents++
+		} else {
+			unreported = append(unreported, change)
+		}
+	}
+	if len(unreported) == 0 {
+		return
+	}
+	// The summary lists a bounded number of caches, as the size of the Event messages is limited
+	eventType := corev1.EventTypeNormal
+	var listed []string
+	for _, change := range unreported {
+		if change.eventType() == corev1.EventTypeWarning {
+			eventType = corev1.EventTypeWarning
+		}
+		if len(listed) < HealthEventsMaxCaches {
+			listed = append(listed, fmt.Sprintf("%s=%s", change.cache, change.to))
+		}
+	}
+	if len(unreported) > len(listed) {
+		listed = append(listed, "...")
+	}
+	r.eventRec.Event(r.infinispan, eventType, EventReasonCacheHealthChanged,
+		fmt.Sprintf("The health of %d more caches changed: %s", len(unreported), strings.Join(listed, ", ")))
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestHealthChanges(t *testing.T) {
+	states := map[string]*healthEventState{}
+	start := time.Now()
+	observe := func(after time.Duration, status string, caches map[string]string) []healthChange {
+		return healthChanges(states, &ServerHealthStatus{Status: status, Caches: caches, ObservedAt: start.Add(after)})
+	}
+
+	// Only the unhealthy statuses are reported when the cluster is first observed
+	assert.Equal(t, []healthChange{{cache: "b", to: ispn.ClusterHealthDegraded}},
+		observe(0, ispn.ClusterHealthHealthy, map[string]string{"a": ispn.ClusterHealthHealthy, "b": ispn.ClusterHealthDegraded}))
+	assert.Empty(t, observe(time.Second, ispn.ClusterHealthHealthy, map[string]string{"a": ispn.ClusterHealthHealthy, "b": ispn.ClusterHealthDegraded}))
+
+	// The changes within the interval are reported once it elapses
+	assert.Empty(t, observe(30*time.Second, ispn.ClusterHealthHealthy, map[string]string{"a": ispn.ClusterHealthHealthy, "b": ispn.ClusterHealthHealthy}))
+	assert.Equal(t, []healthChange{{cache: "b", from: ispn.ClusterHealthDegraded, to: ispn.ClusterHealthHealthy}},
+		observe(HealthEventInterval, ispn.ClusterHealthHealthy, map[string]string{"a": ispn.ClusterHealthHealthy, "b": ispn.ClusterHealthHealthy}))
+
+	// A flapping health isn't reported when it's back to the reported status
+	assert.Empty(t, observe(HealthEventInterval+time.Second, ispn.ClusterHealthHealthy, map[string]string{"a": ispn.ClusterHealthHealthy, "b": ispn.ClusterHealthDegraded}))
+	assert.Empty(t, observe(3*HealthEventInterval, ispn.ClusterHealthHealthy, map[string]string{"a": ispn.ClusterHealthHealthy, "b": ispn.ClusterHealthHealthy}))
+
+	assert.Equal(t, []healthChange{{from: ispn.ClusterHealthHealthy, to: ispn.ClusterHealthDegraded}, {cache: "a", from: ispn.ClusterHealthHealthy, to: "FAILED"}},
+		observe(4*HealthEventInterval, ispn.ClusterHealthDegraded, map[string]string{"a": "FAILED"}))
+	// The removed caches are forgotten
+	assert.NotContains(t, states, "b")
+}
+
+func TestReportHealthChanges(t *testing.T) {
+	infinispan := &infinispanv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace}}
+	r := volumeExpansionRequest(t, "1Gi", infinispan)
+	r.infinispan = infinispan
+	recorder := record.NewFakeRecorder(20)
+	r.eventRec = recorder
+	r.req.NamespacedName = types.NamespacedName{Namespace: namespace, Name: "example-infinispan"}
+	defer forgetHealthEvents(r.req.NamespacedName)
+
+	caches := map[string]string{}
+	for c := 0; c < HealthEventsMaxCaches+12; c++ {
+		caches[fmt.Sprintf("cache-%02d", c)] = ispn.ClusterHealthHealthy
+	}
+	start := time.Now()
+	r.reportHealthChanges(&ServerHealthStatus{Status: ispn.ClusterHealthHealthy, Caches: caches, ObservedAt: start})
+	assert.Empty(t, recorder.Events)
+
+	// The health isn't reported when it can't be retrieved
+	r.reportHealthChanges(&ServerHealthStatus{Error: "no pod is ready", ObservedAt: start.Add(HealthEventInterval)})
+	assert.Empty(t, recorder.Events)
+
+	for cache := range caches {
+		caches[cache] = ispn.ClusterHealthDegraded
+	}
+	r.reportHealthChanges(&ServerHealthStatus{Status: ispn.ClusterHealthDegraded, Caches: caches, ObservedAt: start.Add(HealthEventInterval)})
+	assert.Equal(t, "Warning ClusterHealthChanged Cluster health changed from HEALTHY to DEGRADED", nextEvent(recorder.Events))
+	assert.Equal(t, "Warning CacheHealthChanged Cache 'cache-00' health changed from HEALTHY to DEGRADED", nextEvent(recorder.Events))
+	for c := 1; c < HealthEventsMaxCaches; c++ {
+		nextEvent(recorder.Events)
+	}
+	assert.Equal(t, "Warning CacheHealthChanged The health of 12 more caches changed: cache-10=DEGRADED, cache-11=DEGRADED, cache-12=DEGRADED, "+
+		"cache-13=DEGRADED, cache-14=DEGRADED, cache-15=DEGRADED, cache-16=DEGRADED, cache-17=DEGRADED, cache-18=DEGRADED, cache-19=DEGRADED, ...",
+		nextEvent(recorder.Events))
+	assert.Empty(t, recorder.Events)
+}
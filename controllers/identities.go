@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	VaultAnnotationPrefix = "vault.hashicorp.com/"
+	VaultAgentInject      = VaultAnnotationPrefix + "agent-inject"
+	VaultRole             = VaultAnnotationPrefix + "role"
+	VaultInjectSecret     = VaultAnnotationPrefix + "agent-inject-secret-" + consts.ServerIdentitiesFilename
+	VaultInjectTemplate   = VaultAnnotationPrefix + "agent-inject-template-" + consts.ServerIdentitiesFilename
+	VaultSecretVolumePath = VaultAnnotationPrefix + "secret-volume-path-" + consts.ServerIdentitiesFilename
+
+	SecretsStoreCSIDriver = "secrets-store.csi.k8s.io"
+)
+
+// identitiesSource provisions the user identities of a cluster and configures the server pods to load them from
+// consts.ServerUserIdentitiesPath
+type identitiesSource interface {
+	// provision creates the identities when they are managed by the operator
+	provision(s *secretRequest) error
+	// secretName returns the Secret holding the identities, empty if they are not held in a Kubernetes Secret
+	secretName() string
+	// addToPod configures the pod to load the identities, returning false if it was already configured
+	addToPod(meta *metav1.ObjectMeta, spec *corev1.PodSpec) bool
+}
+
+func identitiesSourceFor(i *infinispanv1.Infinispan) identitiesSource {
+	switch i.GetEndpointSecretSource() {
+	case infinispanv1.EndpointSecretSourceVault:
+		vault := i.Spec.Security.EndpointSecret.Vault
+		if vault.SecretProviderClass != "" {
+			return vaultCSIIdentities{vault: vault}
+		}
+		return vaultAgentIdentities{vault: vault}
+	case infinispanv1.EndpointSecretSourceExternalSecret:
+		return externalSecretIdentities{secretIdentities{infinispan: i}}
+	default:
+		return secretIdentities{infinispan: i}
+	}
+}
+
+// validateIdentitiesSource verifies that the configuration required by the identities source is provided
+func validateIdentitiesSource(i *infinispanv1.Infinispan) error {
+	endpointSecret := i.Spec.Security.EndpointSecret
+	switch i.GetEndpointSecretSource() {
+	case infinispanv1.EndpointSecretSourceVault:
+		if endpointSecret.Vault == nil {
+			return fmt.Errorf("infinispan.spec.security.endpointSecret.vault must be provided for source=%s", infinispanv1.EndpointSecretSourceVault)
+		}
+		if endpointSecret.Vault.SecretProviderClass == "" && (endpointSecret.Vault.Role == "" || endpointSecret.Vault.Path == "") {
+			return fmt.Errorf("infinispan.spec.security.endpointSecret.vault requires either secretProviderClass or both role and path")
+		}
+	case infinispanv1.EndpointSecretSourceExternalSecret:
+		if endpointSecret.ExternalSecret == nil {
+			return fmt.Errorf("infinispan.spec.security.endpointSecret.externalSecret must be provided for source=%s", infinispanv1.EndpointSecretSourceExternalSecret)
+		}
+	}
+	return nil
+}
+
+// secretIdentities loads the identities from a Kubernetes Secret, generating it unless provided by the user
+type secretIdentities struct {
+	infinispan *infinispanv1.Infinispan
+}
+
+func (s secretIdentities) provision(r *secretRequest) error {
+	if !s.infinispan.IsGeneratedSecret() {
+		return nil
+	}
+	// Create the user identities secret if it doesn't already exist
+	secret, err := r.getSecret(s.infinispan.GetSecretName())
+	if secret != nil || err != nil {
+		return err
+	}
+	return r.createUserIdentitiesSecret()
+}
+
+func (s secretIdentities) secretName() string {
+	return s.infinispan.GetSecretName()
+}
+
+func (s secretIdentities) addToPod(meta *metav1.ObjectMeta, spec *corev1.PodSpec) bool {
+	if volume := findIdentitiesVolume(spec); volume != nil && volume.Secret != nil {
+		return false
+	}
+	removeIdentities(meta, spec)
+	addIdentitiesVolume(spec, corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{
+			SecretName: s.infinispan.GetSecretName(),
+		},
+	})
+	return true
+}
+
+// externalSecretIdentities loads the identities from the Secret synchronised by the external-secrets operator
+type externalSecretIdentities struct {
+	secretIdentities
+}
+
+func (externalSecretIdentities) provision(*secretRequest) error {
+	// The Secret is created by the external-secrets operator
+	return nil
+}
+
+// vaultAgentIdentities has the identities rendered into the pods by the Vault agent injector
+type vaultAgentIdentities struct {
+	vault *infinispanv1.VaultSecretSource
+}
+
+func (vaultAgentIdentities) provision(*secretRequest) error {
+	return nil
+}
+
+func (vaultAgentIdentities) secretName() string {
+	return ""
+}
+
+func (v vaultAgentIdentities) addToPod(meta *metav1.ObjectMeta, spec *corev1.PodSpec) bool {
+	annotations := v.annotations()
+	configured := true
+	for key, value := range annotations {
+		configured = configured && meta.Annotations[key] == value
+	}
+	if configured {
+		return false
+	}
+	removeIdentities(meta, spec)
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		meta.Annotations[key] = value
+	}
+	return true
+}
+
+func (v vaultAgentIdentities) annotations() map[string]string {
+	key := v.vault.Key
+	if key == "" {
+		key = consts.ServerIdentitiesFilename
+	}
+	return map[string]string{
+		VaultAgentInject:      "true",
+		VaultRole:             v.vault.Role,
+		VaultInjectSecret:     v.vault.Path,
+		VaultInjectTemplate:   fmt.Sprintf(`{{- with secret %q -}}{{ index .Data.data %q }}{{- end }}`, v.vault.Path, key),
+		VaultSecretVolumePath: consts.ServerUserIdentitiesRoot,
+	}
+}
+
+// vaultCSIIdentities mounts the identities from Vault with the Secrets Store CSI driver
+type vaultCSIIdentities struct {
+	vault *infinispanv1.VaultSecretSource
+}
+
+func (vaultCSIIdentities) provision(*secretRequest) error {
+	return nil
+}
+
+func (vaultCSIIdentities) secretName() string {
+	return ""
+}
+
+func (v vaultCSIIdentities) addToPod(meta *metav1.ObjectMeta, spec *corev1.PodSpec) bool {
+	if volume := findIdentitiesVolume(spec); volume != nil && volume.CSI != nil && volume.CSI.VolumeAttributes["secretProviderClass"] == v.vault.SecretProviderClass {
+		return false
+	}
+	removeIdentities(meta, spec)
+	addIdentitiesVolume(spec, corev1.VolumeSource{
+		CSI: &corev1.CSIVolumeSource{
+			Driver:           SecretsStoreCSIDriver,
+			ReadOnly:         pointer.BoolPtr(true),
+			VolumeAttributes: map[string]string{"secretProviderClass": v.vault.SecretProviderClass},
+		},
+	})
+	return true
+}
+
+// applyIdentities updates the identities source of the StatefulSet, returning true if the pods must be restarted.
+// Switching to a source that doesn't hold the identities in a Secret removes the Secret volume and IDENTITIES_HASH
+func applyIdentities(i *infinispanv1.Infinispan, statefulSet *appsv1.StatefulSet, userSecret *corev1.Secret) bool {
+	if !i.IsAuthenticationEnabled() {
+		return false
+	}
+	updateNeeded := false
+	template := &statefulSet.Spec.Template
+	// Validate identities Secret name changes
+	if secretName := identitiesSourceFor(i).secretName(); secretName != "" {
+		if currentName, secretIndex := findSecretInVolume(&template.Spec, IdentitiesVolumeName); secretIndex >= 0 && currentName != secretName {
+			template.Spec.Volumes[secretIndex].Secret.SecretName = secretName
+			template.Annotations["updateDate"] = time.Now().String()
+			updateNeeded = true
+		}
+	}
+	if AddVolumeForUserAuthentication(i, &template.ObjectMeta, &template.Spec) {
+		updateStatefulSetEnv(statefulSet, "IDENTITIES_PATH", consts.ServerUserIdentitiesPath)
+		updateNeeded = true
+	}
+	// Validate Secret changes (by the hash of the identities.yaml key value). Identities which are not held in a
+	// Secret are read by the server on startup only
+	if userSecret != nil {
+		updateNeeded = updateStatefulSetEnv(statefulSet, "IDENTITIES_HASH", identitiesHash(userSecret)) || updateNeeded
+	} else {
+		updateNeeded = removeStatefulSetEnv(statefulSet, "IDENTITIES_HASH") || updateNeeded
+	}
+	return updateNeeded
+}
+
+func findIdentitiesVolume(spec *corev1.PodSpec) *corev1.Volume {
+	for i, volume := range spec.Volumes {
+		if volume.Name == IdentitiesVolumeName {
+			return &spec.Volumes[i]
+		}
+	}
+	return nil
+}
+
+func addIdentitiesVolume(spec *corev1.PodSpec, source corev1.VolumeSource) {
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name:         IdentitiesVolumeName,
+		VolumeSource: source,
+	})
+	vm := &spec.Containers[0].VolumeMounts
+	*vm = append(*vm, corev1.VolumeMount{
+		Name:      IdentitiesVolumeName,
+		MountPath: consts.ServerUserIdentitiesRoot,
+	})
+}
+
+// removeIdentities removes the configuration of any identities source from the pod, so that switching
+// between sources doesn't leave two of them providing consts.ServerUserIdentitiesPath
+func removeIdentities(meta *metav1.ObjectMeta, spec *corev1.PodSpec) {
+	volumes := spec.Volumes[:0]
+	for _, volume := range spec.Volumes {
+		if volume.Name != IdentitiesVolumeName {
+			volumes = append(volumes, volume)
+		}
+	}
+	spec.Volumes = volumes
+
+	mounts := spec.Containers[0].VolumeMounts[:0]
+	for _, mount := range spec.Containers[0].VolumeMounts {
+		if mount.Name != IdentitiesVolumeName {
+			mounts = append(mounts, mount)
+		}
+	}
+	spec.Containers[0].VolumeMounts = mounts
+
+	for _, key := range []string{VaultAgentInject, VaultRole, VaultInjectSecret, VaultInjectTemplate, VaultSecretVolumePath} {
+		delete(meta.Annotations, key)
+	}
+}
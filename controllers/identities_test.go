@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func identitiesInfinispan(endpointSecret *infinispanv1.EndpointSecret) *infinispanv1.Infinispan {
+	return &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan"},
+		Spec: infinispanv1.InfinispanSpec{
+			Security: infinispanv1.InfinispanSecurity{
+				EndpointSecret: endpointSecret,
+			},
+		},
+	}
+}
+
+func identitiesPod() (*metav1.ObjectMeta, *corev1.PodSpec) {
+	return &metav1.ObjectMeta{Annotations: map[string]string{"updateDate": "now"}},
+		&corev1.PodSpec{Containers: []corev1.Container{{}}}
+}
+
+func TestSecretIdentitiesAddToPod(t *testing.T) {
+	ispn := identitiesInfinispan(nil)
+	meta, spec := identitiesPod()
+
+	assert.True(t, identitiesSourceFor(ispn).addToPod(meta, spec))
+	assert.False(t, identitiesSourceFor(ispn).addToPod(meta, spec))
+
+	assert.Len(t, spec.Volumes, 1)
+	assert.Equal(t, ispn.GenerateSecretName(), spec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, consts.ServerUserIdentitiesRoot, spec.Containers[0].VolumeMounts[0].MountPath)
+	assert.Equal(t, ispn.GenerateSecretName(), identitiesSourceFor(ispn).secretName())
+}
+
+func TestExternalSecretIdentitiesUseTargetSecret(t *testing.T) {
+	ispn := identitiesInfinispan(&infinispanv1.EndpointSecret{
+		Source:         infinispanv1.EndpointSecretSourceExternalSecret,
+		ExternalSecret: &infinispanv1.ExternalSecretSource{Name: "vault-identities"},
+	})
+	meta, spec := identitiesPod()
+
+	assert.True(t, identitiesSourceFor(ispn).addToPod(meta, spec))
+	assert.Equal(t, "vault-identities", spec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, "vault-identities", identitiesSourceFor(ispn).secretName())
+	assert.False(t, ispn.IsGeneratedSecret())
+}
+
+func TestVaultAgentIdentitiesReplaceSecretVolume(t *testing.T) {
+	ispn := identitiesInfinispan(nil)
+	meta, spec := identitiesPod()
+	identitiesSourceFor(ispn).addToPod(meta, spec)
+
+	ispn.Spec.Security.EndpointSecret = &infinispanv1.EndpointSecret{
+		Source: infinispanv1.EndpointSecretSourceVault,
+		Vault: &infinispanv1.VaultSecretSource{
+			Role: "infinispan",
+			Path: "secret/data/infinispan",
+		},
+	}
+	source := identitiesSourceFor(ispn)
+	assert.Empty(t, source.secretName())
+	assert.True(t, source.addToPod(meta, spec))
+	assert.False(t, source.addToPod(meta, spec))
+
+	assert.Empty(t, spec.Volumes)
+	assert.Empty(t, spec.Containers[0].VolumeMounts)
+	assert.Equal(t, "now", meta.Annotations["updateDate"])
+	assert.Equal(t, "true", meta.Annotations[VaultAgentInject])
+	assert.Equal(t, "infinispan", meta.Annotations[VaultRole])
+	assert.Equal(t, "secret/data/infinispan", meta.Annotations[VaultInjectSecret])
+	assert.Equal(t, `{{- with secret "secret/data/infinispan" -}}{{ index .Data.data "identities.yaml" }}{{- end }}`, meta.Annotations[VaultInjectTemplate])
+	assert.Equal(t, consts.ServerUserIdentitiesRoot, meta.Annotations[VaultSecretVolumePath])
+}
+
+func TestVaultCSIIdentitiesRemoveAgentAnnotations(t *testing.T) {
+	vault := &infinispanv1.VaultSecretSource{Role: "infinispan", Path: "secret/data/infinispan"}
+	ispn := identitiesInfinispan(&infinispanv1.EndpointSecret{Source: infinispanv1.EndpointSecretSourceVault, Vault: vault})
+	meta, spec := identitiesPod()
+	identitiesSourceFor(ispn).addToPod(meta, spec)
+
+	vault.SecretProviderClass = "infinispan-identities"
+	assert.True(t, identitiesSourceFor(ispn).addToPod(meta, spec))
+	assert.False(t, identitiesSourceFor(ispn).addToPod(meta, spec))
+
+	assert.Equal(t, map[string]string{"updateDate": "now"}, meta.Annotations)
+	assert.Len(t, spec.Volumes, 1)
+	assert.Equal(t, SecretsStoreCSIDriver, spec.Volumes[0].CSI.Driver)
+	assert.Equal(t, "infinispan-identities", spec.Volumes[0].CSI.VolumeAttributes["secretProviderClass"])
+	assert.Equal(t, consts.ServerUserIdentitiesRoot, spec.Containers[0].VolumeMounts[0].MountPath)
+}
+
+func TestValidateIdentitiesSource(t *testing.T) {
+	assert.NoError(t, validateIdentitiesSource(identitiesInfinispan(nil)))
+	assert.Error(t, validateIdentitiesSource(identitiesInfinispan(&infinispanv1.EndpointSecret{Source: infinispanv1.EndpointSecretSourceVault})))
+	assert.Error(t, validateIdentitiesSource(identitiesInfinispan(&infinispanv1.EndpointSecret{
+		Source: infinispanv1.EndpointSecretSourceVault,
+		Vault:  &infinispanv1.VaultSecretSource{Role: "infinispan"},
+	})))
+	assert.NoError(t, validateIdentitiesSource(identitiesInfinispan(&infinispanv1.EndpointSecret{
+		Source: infinispanv1.EndpointSecretSourceVault,
+		Vault:  &infinispanv1.VaultSecretSource{SecretProviderClass: "infinispan-identities"},
+	})))
+	assert.Error(t, validateIdentitiesSource(identitiesInfinispan(&infinispanv1.EndpointSecret{Source: infinispanv1.EndpointSecretSourceExternalSecret})))
+}
+
+func TestApplyIdentitiesSourceSwitch(t *testing.T) {
+	ispn := identitiesInfinispan(nil)
+	meta, spec := identitiesPod()
+	identitiesSourceFor(ispn).addToPod(meta, spec)
+	spec.Containers[0].Env = []corev1.EnvVar{{Name: "IDENTITIES_PATH", Value: consts.ServerUserIdentitiesPath}, {Name: "IDENTITIES_HASH", Value: "hash"}}
+	statefulSet := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{ObjectMeta: *meta, Spec: *spec}}}
+	userSecret := &corev1.Secret{Data: map[string][]byte{consts.ServerIdentitiesFilename: []byte("credentials")}}
+
+	// The external-secrets operator synchronises the identities into another Secret
+	ispn.Spec.Security.EndpointSecret = &infinispanv1.EndpointSecret{
+		Source:         infinispanv1.EndpointSecretSourceExternalSecret,
+		ExternalSecret: &infinispanv1.ExternalSecretSource{Name: "vault-identities"},
+	}
+	assert.True(t, applyIdentities(ispn, statefulSet, userSecret))
+	assert.False(t, applyIdentities(ispn, statefulSet, userSecret))
+	podSpec := &statefulSet.Spec.Template.Spec
+	assert.Equal(t, "vault-identities", podSpec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, identitiesHash(userSecret), podSpec.Containers[0].Env[kube.GetEnvVarIndex("IDENTITIES_HASH", &podSpec.Containers[0].Env)].Value)
+
+	// The identities rendered by Vault are not held in a Secret
+	ispn.Spec.Security.EndpointSecret = &infinispanv1.EndpointSecret{
+		Source: infinispanv1.EndpointSecretSourceVault,
+		Vault:  &infinispanv1.VaultSecretSource{Role: "infinispan", Path: "secret/data/infinispan"},
+	}
+	assert.True(t, applyIdentities(ispn, statefulSet, nil))
+	assert.False(t, applyIdentities(ispn, statefulSet, nil))
+	assert.Empty(t, podSpec.Volumes)
+	assert.Empty(t, podSpec.Containers[0].VolumeMounts)
+	assert.Equal(t, []corev1.EnvVar{{Name: "IDENTITIES_PATH", Value: consts.ServerUserIdentitiesPath}}, podSpec.Containers[0].Env)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-logr/logr"
 	v1 "github.com/infinispan/infinispan-operator/api/v1"
+	v2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
 	consts "github.com/infinispan/infinispan-operator/controllers/constants"
 	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
 	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
@@ -14,18 +15,25 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	EncryptKeystoreName = "keystore.p12"
 	EncryptKeystorePath = ServerRoot + "/conf/keystore"
+
+	// InfinispanConfigMapField is the Infinispan field indexed to lookup the Infinispans referencing a configuration overlay
+	InfinispanConfigMapField = "spec.configMapName"
 )
 
 // ConfigReconciler reconciles a ConfigMap object
@@ -53,10 +61,55 @@ func (r *ConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.kubernetes = kube.NewKubernetesFromController(mgr)
 	r.eventRec = mgr.GetEventRecorderFor(name + "-controller")
 
+	ctx := context.TODO()
+	// Add the InfinispanSite names to the index, so that the Infinispans referencing an updated site are reconciled
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1.Infinispan{}, InfinispanSiteRefField, siteRefs); err != nil {
+		return err
+	}
+	// Add the overlay ConfigMap name to the index, so that the Infinispans referencing an updated overlay are reconciled
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1.Infinispan{}, InfinispanConfigMapField, func(obj client.Object) []string {
+		if name := obj.(*v1.Infinispan).Spec.ConfigMapName; name != "" {
+			return []string{name}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1.Infinispan{}).
 		Owns(&corev1.ConfigMap{}).
+		Watches(
+			&source.Kind{Type: &v2alpha1.InfinispanSite{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					var requests []reconcile.Request
+					ispnList := &v1.InfinispanList{}
+					if err := r.kubernetes.ResourcesListByField("", InfinispanSiteRefField, a.GetName(), ispnList, ctx); err != nil {
+						r.log.Error(err, "failed to list Infinispan CR")
+					}
+					for _, item := range ispnList.Items {
+						requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}})
+					}
+					return requests
+				}),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					var requests []reconcile.Request
+					ispnList := &v1.InfinispanList{}
+					if err := r.kubernetes.ResourcesListByField(a.GetNamespace(), InfinispanConfigMapField, a.GetName(), ispnList, ctx); err != nil {
+						r.log.Error(err, "failed to list Infinispan CR")
+					}
+					for _, item := range ispnList.Items {
+						requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}})
+					}
+					return requests
+				}),
+		).
 		WithEventFilter(predicate.Funcs{
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				switch e.Object.(type) {
@@ -119,12 +172,24 @@ func (reconciler *ConfigReconciler) Reconcile(ctx context.Context, request recon
 			return *result, err
 		}
 
-		var err error
-		xsite, err = ComputeXSite(infinispan, r.kubernetes, siteService, reqLogger, r.eventRec, r.ctx)
+		// Locations referencing an InfinispanSite are completed with its connection details
+		resolved, exposeTypes, result, err := r.resolveSiteLocations()
+		if result != nil {
+			return *result, err
+		}
+		if result, err := r.verifyCrossSite(resolved); result != nil {
+			return *result, err
+		}
+		xsite, err = ComputeXSite(resolved, exposeTypes, r.kubernetes, siteService, reqLogger, r.eventRec, r.ctx)
 		if err != nil {
 			reqLogger.Error(err, "Error in computeXSite configuration")
 			return reconcile.Result{RequeueAfter: consts.DefaultWaitOnCreateResource}, nil
 		}
+		if result, err := r.configureCrossSiteEncryption(xsite); result != nil {
+			return *result, err
+		}
+	} else if err := r.removeInfinispanCondition(v1.ConditionCrossSiteConfigurationValid); err != nil {
+		return reconcile.Result{}, err
 	}
 
 	if result, err := r.computeAndReconcileConfigMap(xsite); result != nil {
@@ -144,56 +209,36 @@ func (r configRequest) computeAndReconcileConfigMap(xsite *config.XSite) (*recon
 
 	lsConfigMap := LabelsResource(name, "infinispan-configmap-configuration")
 
-	var roleMapper string
-	if r.infinispan.IsClientCertEnabled() && r.infinispan.Spec.Security.EndpointEncryption.ClientCert == v1.ClientCertAuthenticate {
-		roleMapper = "commonName"
-	} else {
-		roleMapper = "cluster"
+	serverConf := computeServerConfig(r.infinispan, xsite)
+
+	if result, err := ConfigureServerEncryption(r.infinispan, serverConf, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return result, err
 	}
 
-	jgroupsDiagnostics := consts.JGroupsDiagnosticsFlag == "TRUE"
-	serverConf := config.InfinispanConfiguration{
-		Infinispan: config.Infinispan{
-			Authorization: config.Authorization{
-				Enabled:    r.infinispan.IsAuthorizationEnabled(),
-				RoleMapper: roleMapper,
-			},
-			ClusterName: name,
-		},
-		JGroups: config.JGroups{
-			Transport: "tcp",
-			DNSPing: config.DNSPing{
-				Query: fmt.Sprintf("%s-ping.%s.svc.cluster.local", name, namespace),
-			},
-			Diagnostics: jgroupsDiagnostics,
-		},
-		Endpoints: config.Endpoints{
-			Authenticate:   r.infinispan.IsAuthenticationEnabled(),
-			DedicatedAdmin: true,
-		},
-		Logging: config.Logging{
-			Categories: r.infinispan.GetLogCategoriesForConfig(),
-		},
+	if result, err := r.configureTransportEncryption(serverConf); result != nil {
+		return result, err
 	}
 
-	specRoles := r.infinispan.GetAuthorizationRoles()
-	if len(specRoles) > 0 {
-		confRoles := make([]config.AuthorizationRole, len(specRoles))
-		for i, role := range specRoles {
-			confRoles[i] = config.AuthorizationRole(role)
-		}
-		serverConf.Infinispan.Authorization.Roles = confRoles
+	if result, err := r.configureMetricsSecurity(serverConf); result != nil {
+		return result, err
 	}
 
-	if xsite != nil {
-		serverConf.XSite = xsite
+	if result, err := r.configureLdapRealm(serverConf); result != nil {
+		return result, err
 	}
 
-	if result, err := ConfigureServerEncryption(r.infinispan, &serverConf, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+	if result, err := r.configureOAuth2Realm(serverConf); result != nil {
 		return result, err
 	}
 
-	r.configureCloudEvent(&serverConf)
+	if result, err := r.configureTracing(serverConf); result != nil {
+		return result, err
+	}
+
+	overlay, result, err := r.configOverlay()
+	if result != nil {
+		return result, err
+	}
 
 	configMapObject := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -202,11 +247,21 @@ func (r configRequest) computeAndReconcileConfigMap(xsite *config.XSite) (*recon
 		},
 	}
 
-	result, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, configMapObject, func() error {
+	var overlayErr error
+	var conflicts []string
+	opResult, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, configMapObject, func() error {
 		configYaml, err := serverConf.Yaml()
 		if err != nil {
 			return err
 		}
+		if overlay != "" {
+			// An invalid overlay is reported and the generated configuration used as is
+			if merged, mergeConflicts, err := config.Merge(configYaml, overlay); err != nil {
+				overlayErr = err
+			} else {
+				configYaml, conflicts = merged, mergeConflicts
+			}
+		}
 
 		if configMapObject.CreationTimestamp.IsZero() {
 			configMapObject.Data = map[string]string{consts.ServerConfigFilename: configYaml}
@@ -216,26 +271,211 @@ func (r configRequest) computeAndReconcileConfigMap(xsite *config.XSite) (*recon
 				return err
 			}
 		} else {
-			previousConfig, err := config.FromYaml(configMapObject.Data[consts.ServerConfigFilename])
-			if err == nil {
-				// Protecting Logging configuration from changes
-				serverConf.Logging = previousConfig.Logging
-			}
 			configMapObject.Data[consts.ServerConfigFilename] = configYaml
 		}
+		if r.infinispan.GetZeroCapacityNodes() > 0 {
+			zeroCapacityYaml, err := zeroCapacityConfig(serverConf, overlay)
+			if err != nil {
+				return err
+			}
+			configMapObject.Data[consts.ServerZeroCapacityConfigFilename] = zeroCapacityYaml
+		} else {
+			delete(configMapObject.Data, consts.ServerZeroCapacityConfigFilename)
+		}
+		if r.infinispan.IsDataMigrationUpgrade() {
+			migrationYaml, err := dataMigrationConfig(r.infinispan, serverConf, overlay)
+			if err != nil {
+				return err
+			}
+			configMapObject.Data[consts.ServerDataMigrationConfigFilename] = migrationYaml
+		} else {
+			delete(configMapObject.Data, consts.ServerDataMigrationConfigFilename)
+		}
+		ApplyPropagatedMetadata(r.infinispan, configMapObject)
 		return nil
 	})
 	if err != nil {
 		return &reconcile.Result{}, err
 	}
-	if result != controllerutil.OperationResultNone {
-		r.reqLogger.Info(fmt.Sprintf("ConfigMap '%s' %s", name, result))
+	if opResult != controllerutil.OperationResultNone {
+		r.reqLogger.Info(fmt.Sprintf("ConfigMap '%s' %s", name, opResult))
+	}
+	if err := r.updateOverlayCondition(overlay, conflicts, overlayErr); err != nil {
+		return &reconcile.Result{}, err
+	}
+	return nil, nil
+}
+
+// zeroCapacityConfig returns the configuration of the zero-capacity pods, the cluster configuration with the
+// zeroCapacityNode flag set
+func zeroCapacityConfig(serverConf *config.InfinispanConfiguration, overlay string) (string, error) {
+	zeroConf := *serverConf
+	zeroConf.Infinispan.ZeroCapacityNode = true
+	zeroYaml, err := zeroConf.Yaml()
+	if err != nil {
+		return "", err
+	}
+	if overlay != "" {
+		if merged, _, err := config.Merge(zeroYaml, overlay); err == nil {
+			zeroYaml = merged
+		}
 	}
-	return nil, err
+	return zeroYaml, nil
 }
 
-func (r configRequest) configureCloudEvent(c *config.InfinispanConfiguration) {
-	spec := r.infinispan.Spec
+// dataMigrationConfig returns the configuration of the staging cluster of a DataMigration upgrade, the cluster
+// configuration with the cluster name and the discovery query of the staging cluster, so that the staging pods form
+// their own cluster, and without cross-site replication
+func dataMigrationConfig(i *v1.Infinispan, serverConf *config.InfinispanConfiguration, overlay string) (string, error) {
+	migrationConf := *serverConf
+	migrationConf.Infinispan.ClusterName = i.GetDataMigrationName()
+	migrationConf.Infinispan.ZeroCapacityNode = false
+	migrationConf.JGroups.DNSPing.Query = fmt.Sprintf("%s.%s.svc.cluster.local", i.GetDataMigrationName(), i.Namespace)
+	migrationConf.XSite = nil
+	migrationYaml, err := migrationConf.Yaml()
+	if err != nil {
+		return "", err
+	}
+	if overlay != "" {
+		if merged, _, err := config.Merge(migrationYaml, overlay); err == nil {
+			migrationYaml = merged
+		}
+	}
+	return migrationYaml, nil
+}
+
+// configOverlay returns the server configuration fragment of the ConfigMap referenced by spec.configMapName, or an
+// empty string if the spec doesn't reference one
+func (r configRequest) configOverlay() (string, *reconcile.Result, error) {
+	name := r.infinispan.Spec.ConfigMapName
+	if name == "" {
+		return "", nil, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if result, err := kube.LookupResource(name, r.infinispan.Namespace, configMap, r.infinispan, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return "", result, err
+	}
+	overlay, ok := configMap.Data[consts.ServerConfigOverlayFilename]
+	if !ok {
+		msg := fmt.Sprintf("key '%s' not found in ConfigMap %s", consts.ServerConfigOverlayFilename, name)
+		r.eventRec.Event(r.infinispan, corev1.EventTypeWarning, v1.ReasonConfigOverlayInvalid, msg)
+		return "", &reconcile.Result{RequeueAfter: consts.DefaultWaitOnCreateResource}, r.setInfinispanCondition(v1.ConditionConfigOverlayMerged, metav1.ConditionFalse, v1.ReasonConfigOverlayInvalid, msg)
+	}
+	return overlay, nil, nil
+}
+
+// updateOverlayCondition reports the result of merging the configuration overlay with the ConfigOverlayMerged condition
+func (r configRequest) updateOverlayCondition(overlay string, conflicts []string, overlayErr error) error {
+	if r.infinispan.Spec.ConfigMapName == "" {
+		return r.removeInfinispanCondition(v1.ConditionConfigOverlayMerged)
+	}
+	if overlayErr != nil {
+		r.eventRec.Event(r.infinispan, corev1.EventTypeWarning, v1.ReasonConfigOverlayInvalid, overlayErr.Error())
+		return r.setInfinispanCondition(v1.ConditionConfigOverlayMerged, metav1.ConditionFalse, v1.ReasonConfigOverlayInvalid, overlayErr.Error())
+	}
+	if len(conflicts) > 0 {
+		msg := fmt.Sprintf("Values generated by the operator take precedence over: %s", strings.Join(conflicts, ", "))
+		return r.setInfinispanCondition(v1.ConditionConfigOverlayMerged, metav1.ConditionFalse, v1.ReasonConfigOverlayConflict, msg)
+	}
+	return r.setInfinispanCondition(v1.ConditionConfigOverlayMerged, metav1.ConditionTrue, v1.ReasonConfigOverlayMerged, "")
+}
+
+func (r configRequest) setInfinispanCondition(condition v1.ConditionType, status metav1.ConditionStatus, reason, message string) error {
+	return r.patchInfinispanStatus(func() bool {
+		return r.infinispan.SetCondition(condition, status, reason, message)
+	})
+}
+
+func (r configRequest) removeInfinispanCondition(condition v1.ConditionType) error {
+	return r.patchInfinispanStatus(func() bool {
+		return r.infinispan.RemoveCondition(condition)
+	})
+}
+
+// patchInfinispanStatus patches the Infinispan status when `update` changes the status of the current Infinispan
+func (r configRequest) patchInfinispanStatus(update func() bool) error {
+	if !update() {
+		return nil
+	}
+	ispn := r.infinispan
+	_, err := kube.CreateOrPatch(r.ctx, r.Client, ispn, func() error {
+		if ispn.CreationTimestamp.IsZero() {
+			return errors.NewNotFound(schema.ParseGroupResource("infinispan.infinispan.org"), ispn.Name)
+		}
+		update()
+		return nil
+	})
+	return err
+}
+
+// computeServerConfig renders the server configuration of the Infinispan which doesn't depend on other resources
+func computeServerConfig(i *v1.Infinispan, xsite *config.XSite) *config.InfinispanConfiguration {
+	jgroupsDiagnostics := consts.JGroupsDiagnosticsFlag == "TRUE"
+	serverConf := &config.InfinispanConfiguration{
+		Infinispan: config.Infinispan{
+			Authorization: authorizationConfig(i),
+			ClusterName:   i.Name,
+		},
+		JGroups: config.JGroups{
+			Transport: string(i.GetJGroupsStack()),
+			DNSPing: config.DNSPing{
+				Query: fmt.Sprintf("%s-ping.%s.svc.cluster.local", i.Name, i.Namespace),
+			},
+			Diagnostics: jgroupsDiagnostics,
+		},
+		Endpoints: config.Endpoints{
+			Authenticate:   i.IsAuthenticationEnabled(),
+			DedicatedAdmin: true,
+			Memcached:      i.IsMemcachedEnabled(),
+			Resp:           i.IsRespEnabled(),
+		},
+		Logging: config.Logging{
+			Console:    loggingConsole(i),
+			Categories: i.GetLogCategoriesForConfig(),
+		},
+	}
+
+	if i.IsIPv6() {
+		// The pods are only resolved by their IPv6 address
+		serverConf.JGroups.DNSPing.RecordType = "AAAA"
+	}
+
+	if jgroups := i.Spec.JGroups; jgroups != nil {
+		serverConf.JGroups.GossipRouters = jgroups.GossipRouters
+		serverConf.JGroups.Properties = jgroups.Properties
+	}
+
+	if xsite != nil {
+		serverConf.XSite = xsite
+	}
+
+	configureCloudEvent(i, serverConf)
+	return serverConf
+}
+
+// authorizationConfig renders the authorization of the Infinispan into the server configuration. Changes to the roles
+// modify the ConfigMap, which triggers a rolling update of the StatefulSet through the CONFIG_HASH env
+func authorizationConfig(i *v1.Infinispan) config.Authorization {
+	authorization := config.Authorization{
+		Enabled:    i.IsAuthorizationEnabled(),
+		RoleMapper: "cluster",
+	}
+	if i.IsClientCertEnabled() && i.Spec.Security.EndpointEncryption.ClientCert == v1.ClientCertAuthenticate {
+		authorization.RoleMapper = "commonName"
+	}
+
+	specRoles := i.GetAuthorizationRoles()
+	if len(specRoles) > 0 {
+		authorization.Roles = make([]config.AuthorizationRole, len(specRoles))
+		for index, role := range specRoles {
+			authorization.Roles[index] = config.AuthorizationRole(role)
+		}
+	}
+	return authorization
+}
+
+func configureCloudEvent(i *v1.Infinispan, c *config.InfinispanConfiguration) {
+	spec := i.Spec
 	if spec.CloudEvents != nil {
 		c.CloudEvents = &config.CloudEvents{}
 		c.CloudEvents.Acks = spec.CloudEvents.Acks
@@ -276,12 +516,25 @@ func ConfigureServerEncryption(i *v1.Infinispan, c *config.InfinispanConfigurati
 		if strings.Contains(i.Spec.Security.EndpointEncryption.CertServiceName, "openshift.io") {
 			configureNewKeystore(c)
 		}
+	} else if i.IsEncryptionCertFromCertManager() {
+		// The keystore is loaded from the mounted Secret, so that the certificates renewed by cert-manager can be reloaded
+		passwordSecret := &corev1.Secret{}
+		if result, err := kube.LookupResource(i.GetCertManagerKeystorePasswordSecretName(), i.Namespace, passwordSecret, i, client, log, eventRec, ctx); result != nil {
+			return result, err
+		}
+		c.Keystore.Path = fmt.Sprintf("%s/%s", consts.ServerEncryptKeystoreRoot, EncryptKeystoreName)
+		c.Keystore.Password = string(passwordSecret.Data[CertManagerKeystorePasswordKey])
 	} else {
-		if secretContains(keystoreSecret, EncryptKeystoreName) {
-			// If user provide a keystore in secret then use it ...
-			c.Keystore.Path = fmt.Sprintf("%s/%s", consts.ServerEncryptKeystoreRoot, EncryptKeystoreName)
-			c.Keystore.Password = string(keystoreSecret.Data["password"])
-			c.Keystore.Alias = string(keystoreSecret.Data["alias"])
+		keystoreKey, keystoreType, err := userKeystore(keystoreSecret)
+		if err != nil {
+			return &reconcile.Result{}, err
+		}
+		if keystoreKey != "" {
+			// If user provide a PKCS12 or JKS keystore in secret then use it ...
+			c.Keystore.Path = fmt.Sprintf("%s/%s", consts.ServerEncryptKeystoreRoot, keystoreKey)
+			c.Keystore.Type = keystoreType
+			c.Keystore.Password = string(keystoreSecret.Data[EncryptKeystorePasswordKey])
+			c.Keystore.Alias = string(keystoreSecret.Data[EncryptKeystoreAliasKey])
 		} else if secretContains(keystoreSecret, corev1.TLSPrivateKeyKey, corev1.TLSCertKey) {
 			configureNewKeystore(c)
 		}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/stretchr/testify/assert"
+)
+
+func authorizationInfinispan(authorization *infinispanv1.Authorization) *infinispanv1.Infinispan {
+	return &infinispanv1.Infinispan{
+		Spec: infinispanv1.InfinispanSpec{
+			Security: infinispanv1.InfinispanSecurity{
+				Authorization: authorization,
+			},
+		},
+	}
+}
+
+func TestAuthorizationConfigRoles(t *testing.T) {
+	ispn := authorizationInfinispan(&infinispanv1.Authorization{
+		Enabled: true,
+		Roles: []infinispanv1.AuthorizationRole{
+			{Name: "reader", Permissions: []string{"ALL_READ", "MONITOR"}},
+			{Name: "writer", Permissions: []string{"WRITE"}},
+		},
+	})
+	assert.Equal(t, config.Authorization{
+		Enabled:    true,
+		RoleMapper: "cluster",
+		Roles: []config.AuthorizationRole{
+			{Name: "reader", Permissions: []string{"ALL_READ", "MONITOR"}},
+			{Name: "writer", Permissions: []string{"WRITE"}},
+		},
+	}, authorizationConfig(ispn))
+
+	// Client certificate authentication maps the certificate common name to the roles
+	ispn.Spec.Security.EndpointEncryption = &infinispanv1.EndpointEncryption{
+		Type:       infinispanv1.CertificateSourceTypeSecret,
+		ClientCert: infinispanv1.ClientCertAuthenticate,
+	}
+	assert.Equal(t, "commonName", authorizationConfig(ispn).RoleMapper)
+}
+
+func TestAuthorizationConfigRolesChangeConfiguration(t *testing.T) {
+	render := func(ispn *infinispanv1.Infinispan) string {
+		conf := config.InfinispanConfiguration{Infinispan: config.Infinispan{Authorization: authorizationConfig(ispn)}}
+		yaml, err := conf.Yaml()
+		assert.NoError(t, err)
+		return yaml
+	}
+	authorization := &infinispanv1.Authorization{
+		Enabled: true,
+		Roles:   []infinispanv1.AuthorizationRole{{Name: "reader", Permissions: []string{"READ"}}},
+	}
+	ispn := authorizationInfinispan(authorization)
+	before := render(ispn)
+	assert.Contains(t, before, "reader")
+
+	// A role change must change the ConfigMap content, as its hash drives the rolling update
+	authorization.Roles[0].Permissions = append(authorization.Roles[0].Permissions, "BULK_READ")
+	assert.NotEqual(t, before, render(ispn))
+}
+
+func TestValidateAuthorization(t *testing.T) {
+	assert.NoError(t, validateAuthorization(authorizationInfinispan(nil)))
+	assert.NoError(t, validateAuthorization(authorizationInfinispan(&infinispanv1.Authorization{
+		Enabled: true,
+		Roles:   []infinispanv1.AuthorizationRole{{Name: "reader", Permissions: []string{"ALL_READ"}}},
+	})))
+
+	// Roles are ignored while authorization is disabled
+	disabled := authorizationInfinispan(&infinispanv1.Authorization{
+		Roles: []infinispanv1.AuthorizationRole{{Name: "reader", Permissions: []string{"read"}}},
+	})
+	assert.NoError(t, validateAuthorization(disabled))
+	assert.Empty(t, authorizationConfig(disabled).Roles)
+	assert.Error(t, validateAuthorization(authorizationInfinispan(&infinispanv1.Authorization{
+		Enabled: true,
+		Roles: []infinispanv1.AuthorizationRole{
+			{Name: "reader", Permissions: []string{"ALL_READ"}},
+			{Name: "reader", Permissions: []string{"READ"}},
+		},
+	})))
+	assert.Error(t, validateAuthorization(authorizationInfinispan(&infinispanv1.Authorization{
+		Enabled: true,
+		Roles:   []infinispanv1.AuthorizationRole{{Name: "reader", Permissions: []string{"read"}}},
+	})))
+}
@@ -3,8 +3,10 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"net"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
 	"github.com/infinispan/infinispan-operator/pkg/infinispan/caches"
 	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"github.com/infinispan/infinispan-operator/pkg/tracing"
 	routev1 "github.com/openshift/api/route/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/prometheus/common/log"
@@ -41,6 +44,7 @@ import (
 )
 
 const (
+	InfinispanContainer         = "infinispan"
 	ServerRoot                  = "/opt/infinispan/server"
 	DataMountPath               = ServerRoot + "/data"
 	DataMountVolume             = "data-volume"
@@ -53,8 +57,11 @@ const (
 	EventReasonPrelimChecksFailed    = "PrelimChecksFailed"
 	EventReasonLowPersistenceStorage = "LowPersistenceStorage"
 	EventReasonEphemeralStorage      = "EphemeralStorageEnables"
+	EventReasonClusterRecovered      = "ClusterRecovered"
 	EventReasonParseValueProblem     = "ParseValueProblem"
 	EventLoadBalancerUnsupported     = "LoadBalancerUnsupported"
+	// EventReasonAuthorizationRolesIgnored is reported when roles are defined while authorization is disabled
+	EventReasonAuthorizationRolesIgnored = "AuthorizationRolesIgnored"
 )
 
 // InfinispanReconciler reconciles a Infinispan object
@@ -65,6 +72,7 @@ type InfinispanReconciler struct {
 	kubernetes     *kube.Kubernetes
 	eventRec       record.EventRecorder
 	supportedTypes map[string]*reconcileType
+	operations     *operationQueue
 }
 
 // Struct for wrapping reconcile request data
@@ -74,6 +82,8 @@ type infinispanRequest struct {
 	req        ctrl.Request
 	infinispan *infinispanv1.Infinispan
 	reqLogger  logr.Logger
+	// trace of the reconcile loop, nil when the tracing is not configured
+	trace *tracing.Trace
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -83,10 +93,14 @@ func (r *InfinispanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.scheme = mgr.GetScheme()
 	r.kubernetes = kube.NewKubernetesFromController(mgr)
 	r.eventRec = mgr.GetEventRecorderFor("controller-infinispan")
+	r.operations = newOperationQueue(OperationWorkers, r.log.WithName("operations"), func(i *infinispanv1.Infinispan) (ispn.ClusterInterface, error) {
+		return NewCluster(i, r.kubernetes, context.Background())
+	})
 	r.supportedTypes = map[string]*reconcileType{
-		consts.ExternalTypeRoute:   {ObjectType: &routev1.Route{}, GroupVersion: routev1.SchemeGroupVersion, GroupVersionSupported: false},
-		consts.ExternalTypeIngress: {ObjectType: &ingressv1.Ingress{}, GroupVersion: ingressv1.SchemeGroupVersion, GroupVersionSupported: false},
-		consts.ServiceMonitorType:  {ObjectType: &monitoringv1.ServiceMonitor{}, GroupVersion: monitoringv1.SchemeGroupVersion, GroupVersionSupported: false},
+		consts.ExternalTypeRoute:    {ObjectType: &routev1.Route{}, GroupVersion: routev1.SchemeGroupVersion, GroupVersionSupported: false},
+		consts.ExternalTypeIngress:  {ObjectType: &ingressv1.Ingress{}, GroupVersion: ingressv1.SchemeGroupVersion, GroupVersionSupported: false},
+		consts.ServiceMonitorType:   {ObjectType: &monitoringv1.ServiceMonitor{}, GroupVersion: monitoringv1.SchemeGroupVersion, GroupVersionSupported: false},
+		consts.ExternalTypeTLSRoute: {ObjectType: newGatewayObject(TLSRouteGroupVersion, consts.ExternalTypeTLSRoute), GroupVersion: TLSRouteGroupVersion, GroupVersionSupported: false},
 	}
 
 	ctx := context.TODO()
@@ -108,6 +122,10 @@ func (r *InfinispanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	if err = mgr.AddMetricsExtraHandler(ClusterHealthPath, NewClusterHealthHandler(r.Client, r.log.WithName("health"))); err != nil {
+		return err
+	}
+
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&infinispanv1.Infinispan{})
 
@@ -169,14 +187,19 @@ func (r *InfinispanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return nil
 			}),
 	)
+	// Reconcile the cluster as soon as one of its long-running server operations completes
+	builder.Watches(&source.Channel{Source: r.operations.completed}, &handler.EnqueueRequestForObject{})
 	return builder.Complete(r)
 }
 
 // +kubebuilder:rbac:groups=infinispan.org,resources=infinispans;infinispans/status;infinispans/finalizers,verbs=get;list;watch;create;update;patch
 
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims;services;services/finalizers;endpoints;configmaps;pods;secrets,verbs=get;list;watch;create;update;delete;patch;deletecollection
-// +kubebuilder:rbac:groups=core,resources=nodes;serviceaccounts,verbs=get;list;watch
-// +kubebuilder:rbac:groups=core,resources=pods/logs,verbs=get
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
 // +kubebuilder:rbac:groups=core;events.k8s.io,resources=events,verbs=create;patch
 
@@ -186,13 +209,25 @@ func (r *InfinispanReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;delete;deletecollection;update
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=customresourcedefinitions;customresourcedefinitions/status,verbs=get;list
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=tlsroutes,verbs=get;list;watch;create;delete;deletecollection;update
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get
 // +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 
 // +kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=get;list;watch;create;delete;deletecollection;update
 
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;delete;update
 
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;delete
+
 func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlRequest ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	result, err := reconciler.reconcile(ctx, ctrlRequest)
+	observeReconcile(infinispanControllerName, ReconcileHandler, ctrlRequest.NamespacedName, start, &result, err)
+	return result, err
+}
+
+func (reconciler *InfinispanReconciler) reconcile(ctx context.Context, ctrlRequest ctrl.Request) (_ ctrl.Result, err error) {
+	start := time.Now()
 	reqLogger := reconciler.log.WithValues("Request.Namespace", ctrlRequest.Namespace, "Request.Name", ctrlRequest.Name)
 	reqLogger.Info("+++++ Reconciling Infinispan.")
 	defer reqLogger.Info("----- End Reconciling Infinispan.")
@@ -209,29 +244,31 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		infinispan:           infinispan,
 		reqLogger:            reqLogger,
 	}
+	defer func() {
+		r.trace.End(err)
+	}()
 
 	var preliminaryChecksResult *ctrl.Result
 	var preliminaryChecksError error
-	err := r.update(func() {
-		// Apply defaults and endpoint encryption settings if not already set
-		infinispan.ApplyDefaults()
-		if r.isTypeSupported(consts.ServiceMonitorType) {
-			infinispan.ApplyMonitoringAnnotation()
-		}
-		infinispan.Spec.Affinity = podAffinity(infinispan, PodLabels(infinispan.Name))
-		errLabel := infinispan.ApplyOperatorLabels()
+	err = r.update(func() {
+		// Apply defaults and endpoint encryption settings if not already set, when the mutating webhook is disabled
+		errLabel := defaultInfinispan(ctx, infinispan, r.isTypeSupported(consts.ServiceMonitorType), r.kubernetes.GetServingCertsMode(ctx), r.kubernetes.GetPodIPFamily, reqLogger)
 		if errLabel != nil {
 			reqLogger.Error(errLabel, "Error applying operator label")
 		}
-		infinispan.ApplyEndpointEncryptionSettings(r.kubernetes.GetServingCertsMode(ctx), reqLogger)
 
 		// Perform all the possible preliminary checks before go on
 		preliminaryChecksResult, preliminaryChecksError = r.preliminaryChecks()
 		if preliminaryChecksError != nil {
 			r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonPrelimChecksFailed, preliminaryChecksError.Error())
-			infinispan.SetCondition(infinispanv1.ConditionPrelimChecksPassed, metav1.ConditionFalse, preliminaryChecksError.Error())
+			infinispan.SetCondition(infinispanv1.ConditionPrelimChecksPassed, metav1.ConditionFalse, infinispanv1.ReasonPrelimChecksFailed, preliminaryChecksError.Error())
+		} else {
+			infinispan.SetCondition(infinispanv1.ConditionPrelimChecksPassed, metav1.ConditionTrue, infinispanv1.ReasonPrelimChecksPassed, "")
+		}
+		if infinispan.IsEphemeralStorage() {
+			infinispan.SetCondition(infinispanv1.ConditionEphemeralStorage, metav1.ConditionTrue, infinispanv1.ReasonDataNotDurable, "Data is stored in emptyDir volumes and is lost when the pods restart")
 		} else {
-			infinispan.SetCondition(infinispanv1.ConditionPrelimChecksPassed, metav1.ConditionTrue, "")
+			infinispan.RemoveCondition(infinispanv1.ConditionEphemeralStorage)
 		}
 	}, false)
 	if err != nil {
@@ -240,6 +277,8 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
 			reqLogger.Info("Infinispan resource not found. Ignoring since object must be deleted")
+			forgetClusterHealth(ctrlRequest.NamespacedName)
+			forgetTracer(ctrlRequest.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -250,6 +289,7 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 	if preliminaryChecksResult != nil {
 		return *preliminaryChecksResult, preliminaryChecksError
 	}
+	r.trace = r.startTrace(start)
 
 	// Wait for the ConfigMap to be created by config-controller
 	configMap := &corev1.ConfigMap{}
@@ -257,9 +297,9 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		return *result, err
 	}
 
-	// Wait for the Secret to be created by secret-controller or provided by user
+	// Wait for the Secret to be created by secret-controller or provided by user, unless identities are not held in a Secret
 	var userSecret *corev1.Secret
-	if infinispan.IsAuthenticationEnabled() {
+	if infinispan.IsAuthenticationEnabled() && identitiesSourceFor(infinispan).secretName() != "" {
 		userSecret = &corev1.Secret{}
 		if result, err := kube.LookupResource(infinispan.GetSecretName(), infinispan.Namespace, userSecret, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
 			return *result, err
@@ -271,6 +311,10 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		return *result, err
 	}
 
+	if err := r.reconcileDebugBundle(); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	var keystoreSecret *corev1.Secret
 	if infinispan.IsEncryptionEnabled() {
 		if infinispan.Spec.Security.EndpointEncryption.CertSecretName == "" {
@@ -290,8 +334,26 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		}
 	}
 
+	if oidc := infinispan.GetConsoleOIDC(); oidc != nil {
+		// Wait for the client Secret of the console proxy, which would otherwise fail to start
+		consoleSecret := &corev1.Secret{}
+		if result, err := kube.LookupResource(oidc.ClientSecretName, infinispan.Namespace, consoleSecret, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
+			return *result, err
+		}
+		if err := validateConsoleClientSecret(consoleSecret); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	if infinispan.HasSites() {
 		reqLogger.Info("Checking the Cross-Site Deployment (Gossip Router)")
+		var routerKeystore *corev1.Secret
+		if tls := infinispan.GetGossipRouterTLS(); tls != nil {
+			routerKeystore = &corev1.Secret{}
+			if result, err := kube.LookupResource(tls.SecretName, infinispan.Namespace, routerKeystore, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
+				return *result, err
+			}
+		}
 		tunnelDeployment := &appsv1.Deployment{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      infinispan.GetGossipRouterDeploymentName(),
@@ -299,9 +361,13 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 			},
 		}
 		result, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, tunnelDeployment, func() error {
-			tunnel := r.GetGossipRouterDeployment(infinispan)
+			tunnel, err := r.GetGossipRouterDeployment(infinispan, routerKeystore)
+			if err != nil {
+				return err
+			}
 			tunnelDeployment.Spec = tunnel.Spec
 			tunnelDeployment.Labels = tunnel.Labels
+			ApplyPropagatedMetadata(r.infinispan, tunnelDeployment)
 			if tunnelDeployment.CreationTimestamp.IsZero() {
 				reqLogger.Info("Creating the Cross-Site Deployment (Gossip Router)")
 				return controllerutil.SetControllerReference(r.infinispan, tunnelDeployment, r.scheme)
@@ -325,14 +391,16 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 			reqLogger.Error(err, "Failed to fetch Gossip Router pod")
 			return reconcile.Result{}, err
 		}
-		if !kube.AreAllPodsReady(gossipRouterPods) {
-			reqLogger.Info("Gossip Router pod is not ready")
+		readyRouters := gossipRouterReadyPods(gossipRouterPods)
+		routersMessage := fmt.Sprintf("%d of %d Gossip Router pods ready", readyRouters, infinispan.GetGossipRouterReplicas())
+		if !kube.AreAllPodsReady(gossipRouterPods) || readyRouters < int(infinispan.GetGossipRouterReplicas()) {
+			reqLogger.Info("Gossip Router pods are not ready", "ready", readyRouters)
 			return reconcile.Result{}, r.update(func() {
-				r.infinispan.SetCondition(infinispanv1.ConditionGossipRouterReady, metav1.ConditionFalse, "Gossip Router pod not ready")
+				r.infinispan.SetCondition(infinispanv1.ConditionGossipRouterReady, metav1.ConditionFalse, infinispanv1.ReasonGossipRouterNotReady, routersMessage)
 			})
 		}
 		if err = r.update(func() {
-			r.infinispan.SetCondition(infinispanv1.ConditionGossipRouterReady, metav1.ConditionTrue, "")
+			r.infinispan.SetCondition(infinispanv1.ConditionGossipRouterReady, metav1.ConditionTrue, infinispanv1.ReasonGossipRouterReady, routersMessage)
 		}); err != nil {
 			reqLogger.Error(err, "Failed to set Gossip Router pod condition")
 			return reconcile.Result{}, err
@@ -403,7 +471,7 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		}
 
 		if err := r.update(func() {
-			infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionFalse, "")
+			infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionFalse, infinispanv1.ReasonUpgradeCompleted, "")
 			if infinispan.Spec.Replicas != infinispan.Status.ReplicasWantedAtRestart {
 				reqLogger.Info("removed Infinispan resources, force an upgrade now", "replicasWantedAtRestart", infinispan.Status.ReplicasWantedAtRestart)
 				infinispan.Spec.Replicas = infinispan.Status.ReplicasWantedAtRestart
@@ -437,7 +505,16 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		return ctrl.Result{}, err
 	}
 
-	result, err := r.scheduleUpgradeIfNeeded(podList)
+	result, err := r.observeHandler("upgrade", func() (*ctrl.Result, error) {
+		if infinispan.IsCanaryUpgrade() {
+			cluster, err := NewCluster(infinispan, r.kubernetes, r.ctx)
+			if err != nil {
+				return &ctrl.Result{}, err
+			}
+			return r.reconcileCanaryUpgrade(statefulSet, podList, cluster)
+		}
+		return r.scheduleUpgradeIfNeeded(statefulSet, configMap, podList)
+	})
 	if result != nil {
 		return *result, err
 	}
@@ -450,7 +527,9 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 	// If user set Spec.replicas=0 we need to perform a graceful shutdown
 	// to preserve the data
 	var res *ctrl.Result
-	res, err = r.reconcileGracefulShutdown(statefulSet, podList, reqLogger, cluster)
+	res, err = r.observeHandler("graceful-shutdown", func() (*ctrl.Result, error) {
+		return r.reconcileGracefulShutdown(statefulSet, podList, reqLogger)
+	})
 	if res != nil {
 		return *res, err
 	}
@@ -460,13 +539,38 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// Record the catalog version of the pods, which changes of spec.version are validated against
+	if err := r.reconcileOperandVersion(podList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Remove the pods one at a time when spec.replicas is reduced, waiting for their entries to be redistributed
+	if result, err := r.observeHandler("scale-down", func() (*ctrl.Result, error) {
+		// The scale down coordinator queries the pods through the headless admin service
+		podCluster, err := NewPodCluster(infinispan, r.kubernetes, r.ctx)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		return r.reconcileScaleDown(statefulSet, podList, podCluster)
+	}); result != nil {
+		return *result, err
+	}
+
 	// Here where to reconcile with spec updates that reflect into
 	// changes to statefulset.spec.container.
-	res, err = r.reconcileContainerConf(statefulSet, configMap, adminSecret, userSecret, keystoreSecret, trustSecret)
+	res, err = r.observeHandler("container-configuration", func() (*ctrl.Result, error) {
+		return r.reconcileContainerConf(statefulSet, configMap, adminSecret, userSecret, keystoreSecret, trustSecret)
+	})
 	if res != nil {
 		return *res, err
 	}
 
+	if result, err := r.observeHandler("zero-capacity-nodes", func() (*ctrl.Result, error) {
+		return r.reconcileZeroCapacityNodes(statefulSet, configMap)
+	}); result != nil {
+		return *result, err
+	}
+
 	// Update the Infinispan status with the pod status
 	// Wait until all pods have IPs assigned
 	// Without those IPs, it's not possible to execute next calls
@@ -474,7 +578,7 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 	if !kube.ArePodIPsReady(podList) {
 		reqLogger.Info("Pods IPs are not ready yet")
 		return ctrl.Result{}, r.update(func() {
-			infinispan.SetCondition(infinispanv1.ConditionWellFormed, metav1.ConditionUnknown, "Pods are not ready")
+			infinispan.SetCondition(infinispanv1.ConditionWellFormed, metav1.ConditionUnknown, infinispanv1.ReasonPodsNotReady, "Pods are not ready")
 			infinispan.RemoveCondition(infinispanv1.ConditionCrossSiteViewFormed)
 			infinispan.Status.StatefulSetName = statefulSet.Name
 		})
@@ -494,6 +598,43 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		return ctrl.Result{}, err
 	}
 
+	if err := r.reconcileLogAlerts(podList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileConfigExport(podList, cluster); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if result, err := r.observeHandler("readiness-gate", func() (*ctrl.Result, error) {
+		if err := r.reconcileReadinessGate(podList, cluster); err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.observeHandler("state-transfer-labels", func() (*ctrl.Result, error) {
+		if err := r.reconcileStateTransferLabels(podList, cluster); err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.observeHandler("split-brain", func() (*ctrl.Result, error) {
+		if err := r.reconcileSplitBrain(podList, cluster); err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}); result != nil {
+		return *result, err
+	}
+
+	r.recordClusterHealth(podList, cluster)
+
 	// View didn't form, requeue until view has formed
 	if infinispan.NotClusterFormed(len(podList.Items), int(infinispan.Spec.Replicas)) {
 		reqLogger.Info("notClusterFormed")
@@ -501,10 +642,48 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 	}
 
 	// Below the code for a wellFormed cluster
-	err = configureLoggers(podList, cluster, infinispan)
+	if result, err := r.observeHandler("shutdown-recovery", func() (*ctrl.Result, error) {
+		return r.reconcileShutdownRecovery(podList, cluster)
+	}); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.observeHandler("rebalance", func() (*ctrl.Result, error) {
+		if err := r.reconcileRebalance(podList, cluster); err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}); result != nil {
+		return *result, err
+	}
+
+	startupLoggers, err := configureLoggers(podList, cluster, infinispan)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	if len(startupLoggers) > 0 {
+		if result, err := r.restartForStartupLoggers(statefulSet, configMap, startupLoggers); result != nil {
+			return *result, err
+		}
+	}
+
+	if result, err := r.observeHandler("keystore-reload", func() (*ctrl.Result, error) {
+		return r.reloadCertManagerKeystore(podList, cluster, keystoreSecret)
+	}); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.observeHandler("rolling-restart", func() (*ctrl.Result, error) {
+		return r.reconcileRollingRestart(podList, cluster)
+	}); result != nil {
+		return *result, err
+	}
+
+	if result, err := r.observeHandler("volume-expansion", func() (*ctrl.Result, error) {
+		return r.reconcileVolumeExpansion(podList, cluster)
+	}); result != nil {
+		return *result, err
+	}
 
 	// Create default cache if it doesn't exists.
 	if infinispan.IsCache() {
@@ -520,56 +699,44 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 		}
 	}
 
-	if infinispan.IsExposed() {
+	// Migrate the caches of the staging cluster of a DataMigration upgrade to the upgraded cluster
+	if migration := infinispan.Status.DataMigration; migration != nil && (migration.Stage == infinispanv1.DataMigrationUpgrade || migration.Stage == infinispanv1.DataMigrationFromStaging) {
+		if result, err := r.observeHandler("data-migration", func() (*ctrl.Result, error) {
+			return r.migrateFromStaging(podList, cluster)
+		}); result != nil {
+			return *result, err
+		}
+	}
+
+	if infinispan.IsConsoleExposed() || infinispan.IsExposed() {
 		var exposeAddress string
-		switch infinispan.GetExposeType() {
-		case infinispanv1.ExposeTypeLoadBalancer, infinispanv1.ExposeTypeNodePort:
-			// Wait for the cluster external Service to be created by service-controller
-			externalService := &corev1.Service{}
-			if result, err := kube.LookupResource(infinispan.GetServiceExternalName(), infinispan.Namespace, externalService, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
-				return *result, err
-			}
-			if len(externalService.Spec.Ports) > 0 && infinispan.GetExposeType() == infinispanv1.ExposeTypeNodePort {
-				if exposeHost, err := r.kubernetes.GetNodeHost(reqLogger, ctx); err != nil {
-					return ctrl.Result{}, err
-				} else {
-					exposeAddress = fmt.Sprintf("%s:%d", exposeHost, externalService.Spec.Ports[0].NodePort)
-				}
-			} else if infinispan.GetExposeType() == infinispanv1.ExposeTypeLoadBalancer {
-				// Waiting for LoadBalancer cloud provider to update the configured hostname inside Status field
-				if exposeAddress = r.kubernetes.GetExternalAddress(externalService); exposeAddress == "" {
-					if !helpers.HasLBFinalizer(externalService) {
-						errMsg := "LoadBalancer expose type is not supported on the target platform"
-						r.eventRec.Event(externalService, corev1.EventTypeWarning, EventLoadBalancerUnsupported, errMsg)
-						reqLogger.Info(errMsg)
-						return ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
-					}
-					reqLogger.Info("LoadBalancer address not ready yet. Waiting on value in reconcile loop")
-					return ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
-				}
-			}
-		case infinispanv1.ExposeTypeRoute:
-			if r.isTypeSupported(consts.ExternalTypeRoute) {
-				externalRoute := &routev1.Route{}
-				if result, err := kube.LookupResource(infinispan.GetServiceExternalName(), infinispan.Namespace, externalRoute, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
-					return *result, err
-				}
-				exposeAddress = externalRoute.Spec.Host
-			} else if r.isTypeSupported(consts.ExternalTypeIngress) {
-				externalIngress := &ingressv1.Ingress{}
-				if result, err := kube.LookupResource(infinispan.GetServiceExternalName(), infinispan.Namespace, externalIngress, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
-					return *result, err
-				}
-				if len(externalIngress.Spec.Rules) > 0 {
-					exposeAddress = externalIngress.Spec.Rules[0].Host
-				}
+		var result *ctrl.Result
+		var err error
+		consoleScheme := infinispan.GetEndpointScheme()
+		if infinispan.IsConsoleExposed() {
+			// The console is served on the host of its own Route
+			consoleScheme = consoleURLScheme(infinispan)
+			exposeAddress, result, err = r.lookupExposeAddress(infinispan.GetConsoleExternalName(), consoleExposeSpec(infinispan))
+		} else if infinispan.IsExposedPerEndpoint() {
+			// The console is served by the REST endpoint
+			restExpose := infinispan.GetEndpointExpose(infinispanv1.ExposeEndpointRest)
+			if restExpose.Type == infinispanv1.ExposeTypeRoute && restExpose.TLSTermination == infinispanv1.RouteTLSTerminationEdge {
+				consoleScheme = strings.ToLower(string(corev1.URISchemeHTTPS))
 			}
+			exposeAddress, result, err = r.lookupExposeAddress(infinispan.GetEndpointExternalName(infinispanv1.ExposeEndpointRest), restExpose)
+		} else if infinispan.GetExposeType() == infinispanv1.ExposeTypeGatewayRoute {
+			exposeAddress, result, err = r.lookupGatewayRouteAddress()
+		} else {
+			exposeAddress, result, err = r.lookupExposeAddress(infinispan.GetServiceExternalName(), exposeSpec(infinispan))
+		}
+		if result != nil {
+			return *result, err
 		}
 		if err := r.update(func() {
 			if exposeAddress == "" {
 				infinispan.Status.ConsoleUrl = nil
 			} else {
-				infinispan.Status.ConsoleUrl = pointer.StringPtr(fmt.Sprintf("%s://%s/console", infinispan.GetEndpointScheme(), exposeAddress))
+				infinispan.Status.ConsoleUrl = pointer.StringPtr(fmt.Sprintf("%s://%s/console", consoleScheme, exposeAddress))
 			}
 		}); err != nil {
 			return ctrl.Result{}, err
@@ -600,28 +767,115 @@ func (reconciler *InfinispanReconciler) Reconcile(ctx context.Context, ctrlReque
 				}
 			}
 		}
+		xsiteStatus, xsiteErr := GetCrossSiteStatus(podList.Items[0].Name, cluster)
+		if xsiteErr != nil {
+			log.Error(xsiteErr, "Unable to retrieve the cross-site status")
+		}
 		err = r.update(func() {
-			infinispan.SetConditions([]infinispanv1.InfinispanCondition{*crossSiteViewCondition})
+			infinispan.SetConditions([]metav1.Condition{*crossSiteViewCondition})
+			if xsiteErr == nil {
+				infinispan.Status.XSite = xsiteStatus
+			}
 		})
 		if err != nil || crossSiteViewCondition.Status != metav1.ConditionTrue {
 			return ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, err
 		}
+
+		if result, err := r.observeHandler("xsite-state-transfer", func() (*ctrl.Result, error) {
+			return r.reconcileXSiteStateTransfer(podList.Items[0].Name)
+		}); result != nil {
+			return *result, err
+		}
 	}
 
+	// Keep following the progress of the rebalance
+	if infinispan.IsConditionTrue(infinispanv1.ConditionRebalancing) {
+		return ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+	// Keep scanning the server logs for alerts
+	if infinispan.GetLogAlerts() != nil {
+		return ctrl.Result{RequeueAfter: consts.DefaultLogAlertsPeriod}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
+// lookupExposeAddress returns the host[:port] of the Service, Route or Ingress with the given name
+func (r *infinispanRequest) lookupExposeAddress(name string, expose *infinispanv1.EndpointExposeSpec) (string, *ctrl.Result, error) {
+	infinispan := r.infinispan
+	reqLogger := r.reqLogger
+	switch expose.Type {
+	case infinispanv1.ExposeTypeLoadBalancer, infinispanv1.ExposeTypeNodePort:
+		// Wait for the cluster external Service to be created by service-controller
+		externalService := &corev1.Service{}
+		if result, err := kube.LookupResource(name, infinispan.Namespace, externalService, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
+			return "", result, err
+		}
+		if len(externalService.Spec.Ports) > 0 && expose.Type == infinispanv1.ExposeTypeNodePort {
+			exposeHost, err := r.kubernetes.GetNodeHost(reqLogger, r.ctx)
+			if err != nil {
+				return "", &ctrl.Result{}, err
+			}
+			return net.JoinHostPort(exposeHost, strconv.Itoa(int(externalService.Spec.Ports[0].NodePort))), nil, nil
+		} else if expose.Type == infinispanv1.ExposeTypeLoadBalancer {
+			// Waiting for LoadBalancer cloud provider to update the configured hostname inside Status field
+			exposeAddress := r.kubernetes.GetExternalAddress(externalService)
+			if exposeAddress == "" {
+				if !helpers.HasLBFinalizer(externalService) {
+					errMsg := "LoadBalancer expose type is not supported on the target platform"
+					r.eventRec.Event(externalService, corev1.EventTypeWarning, EventLoadBalancerUnsupported, errMsg)
+					reqLogger.Info(errMsg)
+					return "", &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+				}
+				reqLogger.Info("LoadBalancer address not ready yet. Waiting on value in reconcile loop")
+				return "", &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+			}
+			return exposeAddress, nil, nil
+		}
+	case infinispanv1.ExposeTypeRoute:
+		if r.isTypeSupported(consts.ExternalTypeRoute) {
+			externalRoute := &routev1.Route{}
+			if result, err := kube.LookupResource(name, infinispan.Namespace, externalRoute, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
+				return "", result, err
+			}
+			return externalRoute.Spec.Host, nil, nil
+		} else if r.isTypeSupported(consts.ExternalTypeIngress) {
+			externalIngress := &ingressv1.Ingress{}
+			if result, err := kube.LookupResource(name, infinispan.Namespace, externalIngress, infinispan, r.Client, reqLogger, r.eventRec, r.ctx); result != nil {
+				return "", result, err
+			}
+			if len(externalIngress.Spec.Rules) > 0 {
+				return externalIngress.Spec.Rules[0].Host, nil, nil
+			}
+		}
+	}
+	return "", nil, nil
+}
+
 // PreliminaryChecks performs all the possible initial checks
 func (r *infinispanRequest) preliminaryChecks() (*ctrl.Result, error) {
+	if err := validateInfinispan(r.infinispan); err != nil {
+		return &ctrl.Result{
+			Requeue:      false,
+			RequeueAfter: consts.DefaultRequeueOnWrongSpec,
+		}, err
+	}
+	if authorization := r.infinispan.Spec.Security.Authorization; authorization != nil && !authorization.Enabled && len(authorization.Roles) > 0 {
+		msg := "infinispan.spec.security.authorization.roles are ignored while infinispan.spec.security.authorization.enabled=false"
+		r.eventRec.Event(r.infinispan, corev1.EventTypeWarning, EventReasonAuthorizationRolesIgnored, msg)
+		r.reqLogger.Info(msg)
+	}
+	return nil, nil
+}
+
+// validateInfinispan verifies the spec of a defaulted Infinispan CR. It's used by the preliminary checks of the
+// controller and by the validating webhook
+func validateInfinispan(i *infinispanv1.Infinispan) error {
 	// If a CacheService is requested, checks that the pods have enough memory
-	spec := r.infinispan.Spec
+	spec := i.Spec
 	if spec.Service.Type == infinispanv1.ServiceTypeCache {
 		memoryQ, err := resource.ParseQuantity(spec.Container.Memory)
 		if err != nil {
-			return &ctrl.Result{
-				Requeue:      false,
-				RequeueAfter: consts.DefaultRequeueOnWrongSpec,
-			}, err
+			return err
 		}
 		memory := memoryQ.Value()
 		nativeMemoryOverhead := (memory * consts.CacheServiceJvmNativePercentageOverhead) / 100
@@ -629,30 +883,128 @@ func (r *infinispanRequest) preliminaryChecks() (*ctrl.Result, error) {
 			(consts.CacheServiceFixedMemoryXmxMb * 1024 * 1024) +
 			nativeMemoryOverhead
 		if memory < occupiedMemory {
-			return &ctrl.Result{
-				Requeue:      false,
-				RequeueAfter: consts.DefaultRequeueOnWrongSpec,
-			}, fmt.Errorf("not enough memory. Increase infinispan.spec.container.memory. Now is %s, needed at least %d", memoryQ.String(), occupiedMemory)
+			return fmt.Errorf("not enough memory. Increase infinispan.spec.container.memory. Now is %s, needed at least %d", memoryQ.String(), occupiedMemory)
 		}
 	}
-	return nil, nil
+	if expose := spec.Expose; expose != nil && expose.Type == infinispanv1.ExposeTypeGatewayRoute && (expose.Gateway == nil || expose.Gateway.Name == "") {
+		return fmt.Errorf("infinispan.spec.expose.gateway.name must be provided for type=%s", infinispanv1.ExposeTypeGatewayRoute)
+	}
+	// HotRod can only be routed by the Gateway as TLS passed through to the server
+	if expose := spec.Expose; expose != nil && expose.Type == infinispanv1.ExposeTypeGatewayRoute && !i.IsEncryptionEnabled() {
+		return fmt.Errorf("infinispan.spec.expose.type=%s requires infinispan.spec.security.endpointEncryption, the TLSRoute passes TLS through to the server", infinispanv1.ExposeTypeGatewayRoute)
+	}
+	if err := validateExposeEndpoints(i); err != nil {
+		return err
+	}
+	if err := validateTransportEncryption(i); err != nil {
+		return err
+	}
+	if err := validateJGroups(i); err != nil {
+		return err
+	}
+	if err := validateIPFamilies(i); err != nil {
+		return err
+	}
+	if err := validateAuthorization(i); err != nil {
+		return err
+	}
+	if err := validateIdentitiesSource(i); err != nil {
+		return err
+	}
+	if err := validateContainerEnv(i); err != nil {
+		return err
+	}
+	if err := validateUserContainers(i); err != nil {
+		return err
+	}
+	if err := validateLogAlerts(i); err != nil {
+		return err
+	}
+	if err := validateLogging(i); err != nil {
+		return err
+	}
+	if err := validateCertManager(i); err != nil {
+		return err
+	}
+	if err := validateRestartedAt(i); err != nil {
+		return err
+	}
+	if err := validateExternalArtifacts(i); err != nil {
+		return err
+	}
+	if err := validateOperandVersion(i); err != nil {
+		return err
+	}
+	if err := validateLdapRealm(i); err != nil {
+		return err
+	}
+	if err := validateOAuth2Realm(i); err != nil {
+		return err
+	}
+	if err := validateXSiteStateTransfer(i); err != nil {
+		return err
+	}
+	if err := validateDataMigration(i); err != nil {
+		return err
+	}
+	if err := validateCrossSite(i); err != nil {
+		return err
+	}
+	if err := validateCrossSiteExpose(i); err != nil {
+		return err
+	}
+	if err := validateNetworkPolicy(i); err != nil {
+		return err
+	}
+	if err := validatePodDisruptionBudget(i); err != nil {
+		return err
+	}
+	if err := validateGossipRouter(i); err != nil {
+		return err
+	}
+	if err := validateTracing(i); err != nil {
+		return err
+	}
+	if container := spec.Service.Container; container != nil && container.EphemeralStorage && container.StorageType == infinispanv1.StoragePersistent {
+		return fmt.Errorf("infinispan.spec.service.container.ephemeralStorage cannot be combined with storageType=%s", infinispanv1.StoragePersistent)
+	}
+	if spec.Container.MemoryPolicy == infinispanv1.MemoryPolicyAuto && spec.Service.Type != infinispanv1.ServiceTypeDataGrid {
+		return fmt.Errorf("infinispan.spec.container.memoryPolicy=%s is only supported for service type %s", infinispanv1.MemoryPolicyAuto, infinispanv1.ServiceTypeDataGrid)
+	}
+	if container := spec.Service.Container; container != nil && container.ZeroCapacityNodes > 0 && spec.Service.Type != infinispanv1.ServiceTypeDataGrid {
+		return fmt.Errorf("infinispan.spec.service.container.zeroCapacityNodes is only supported for service type %s", infinispanv1.ServiceTypeDataGrid)
+	}
+	if spec.Service.PartitionHandling != nil && spec.Service.Type != infinispanv1.ServiceTypeDataGrid {
+		return fmt.Errorf("infinispan.spec.service.partitionHandling is only supported for service type %s", infinispanv1.ServiceTypeDataGrid)
+	}
+	return validateAutoscale(i)
 }
 
-func configureLoggers(pods *corev1.PodList, cluster ispn.ClusterInterface, infinispan *infinispanv1.Infinispan) error {
-	if infinispan.Spec.Logging == nil || len(infinispan.Spec.Logging.Categories) == 0 {
+// authorizationPermissions the permissions that can be granted to the roles of the server
+var authorizationPermissions = map[string]bool{
+	"ALL": true, "ALL_READ": true, "ALL_WRITE": true, "LIFECYCLE": true, "READ": true, "WRITE": true, "EXEC": true,
+	"LISTEN": true, "BULK_READ": true, "BULK_WRITE": true, "ADMIN": true, "CREATE": true, "MONITOR": true, "NONE": true,
+}
+
+// validateAuthorization verifies that the custom roles are unique and only grant permissions known to the server
+func validateAuthorization(i *infinispanv1.Infinispan) error {
+	authorization := i.Spec.Security.Authorization
+	if authorization == nil || len(authorization.Roles) == 0 {
 		return nil
 	}
-	for _, pod := range pods.Items {
-		serverLoggers, err := cluster.GetLoggers(pod.Name)
-		if err != nil {
-			return err
+	if !authorization.Enabled {
+		// The roles are ignored, the preliminary checks warn about them
+		return nil
+	}
+	roles := make(map[string]bool, len(authorization.Roles))
+	for _, role := range authorization.Roles {
+		if roles[role.Name] {
+			return fmt.Errorf("infinispan.spec.security.authorization.roles defines role '%s' more than once", role.Name)
 		}
-		for category, level := range infinispan.Spec.Logging.Categories {
-			serverLevel, ok := serverLoggers[category]
-			if !(ok && string(level) == serverLevel) {
-				if err := cluster.SetLogger(pod.Name, category, string(level)); err != nil {
-					return err
-				}
+		roles[role.Name] = true
+		for _, permission := range role.Permissions {
+			if !authorizationPermissions[permission] {
+				return fmt.Errorf("unknown permission '%s' granted to role '%s'", permission, role.Name)
 			}
 		}
 	}
@@ -685,6 +1037,17 @@ func (r *infinispanRequest) destroyResources() error {
 		return err
 	}
 
+	if err = r.deleteZeroCapacityNodes(); err != nil {
+		return err
+	}
+
+	if migration := infinispan.Status.DataMigration; migration != nil && migration.Stage == infinispanv1.DataMigrationUpgrade {
+		// The caches have been migrated to the staging cluster, the upgraded cluster starts without the old volumes
+		if err = r.deleteVolumeClaims(infinispan.Name, PodLabels(infinispan.Name)); err != nil {
+			return err
+		}
+	}
+
 	err = r.Client.Delete(r.ctx,
 		&appsv1.Deployment{
 			ObjectMeta: metav1.ObjectMeta{
@@ -739,6 +1102,16 @@ func (r *infinispanRequest) destroyResources() error {
 	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
+	err = r.Client.Delete(r.ctx,
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      infinispan.GetAdminHeadlessServiceName(),
+				Namespace: infinispan.Namespace,
+			},
+		})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
 
 	err = r.Client.Delete(context.TODO(),
 		&corev1.Service{
@@ -775,6 +1148,14 @@ func (r *infinispanRequest) destroyResources() error {
 		}
 	}
 
+	if route := newGatewayRoute(); r.isTypeSupported(route.GetKind()) {
+		route.SetName(infinispan.GetServiceExternalName())
+		route.SetNamespace(infinispan.Namespace)
+		if err = r.Client.Delete(r.ctx, route); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
 	err = r.Client.Delete(r.ctx,
 		&corev1.Service{
 			ObjectMeta: metav1.ObjectMeta{
@@ -840,7 +1221,7 @@ func (r *infinispanRequest) upgradeInfinispan() error {
 					}
 					infinispan.Spec.Service.Sites.Locations[i].Host = nil
 					infinispan.Spec.Service.Sites.Locations[i].Port = nil
-					infinispan.Spec.Service.Sites.Locations[i].URL = fmt.Sprintf("%s://%s:%d", consts.StaticCrossSiteUriSchema, *location.Host, port)
+					infinispan.Spec.Service.Sites.Locations[i].URL = fmt.Sprintf("%s://%s", consts.StaticCrossSiteUriSchema, net.JoinHostPort(*location.Host, strconv.Itoa(port)))
 				}
 			}
 		}
@@ -867,13 +1248,22 @@ func (r *infinispanRequest) dropSecretOwnerReference(secretName string) error {
 	return nil
 }
 
-func (r *infinispanRequest) scheduleUpgradeIfNeeded(podList *corev1.PodList) (*ctrl.Result, error) {
+func (r *infinispanRequest) scheduleUpgradeIfNeeded(statefulSet *appsv1.StatefulSet, configMap *corev1.ConfigMap, podList *corev1.PodList) (*ctrl.Result, error) {
 	infinispan := r.infinispan
 	if upgrade, err := upgradeRequired(infinispan, podList); upgrade || err != nil {
+		if infinispan.IsDataMigrationUpgrade() {
+			cluster, err := NewCluster(infinispan, r.kubernetes, r.ctx)
+			if err != nil {
+				return &ctrl.Result{}, err
+			}
+			if result, err := r.migrateToStaging(statefulSet, configMap, podList, cluster); result != nil {
+				return result, err
+			}
+		}
 		if err := r.update(func() {
 			podDefaultImage := kube.GetPodDefaultImage(podList.Items[0].Spec.Containers[0])
-			r.reqLogger.Info("schedule an Infinispan cluster upgrade", "pod default image", podDefaultImage, "desired image", consts.DefaultImageName)
-			infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionTrue, "")
+			r.reqLogger.Info("schedule an Infinispan cluster upgrade", "pod default image", podDefaultImage, "desired image", infinispan.DefaultImageName())
+			infinispan.SetCondition(infinispanv1.ConditionUpgrade, metav1.ConditionTrue, infinispanv1.ReasonUpgradeScheduled, "")
 			infinispan.Spec.Replicas = 0
 		}); err != nil {
 			return &ctrl.Result{}, err
@@ -890,6 +1280,11 @@ func upgradeRequired(infinispan *infinispanv1.Infinispan, podList *corev1.PodLis
 		return false, nil
 	}
 
+	// Don't retry a canary upgrade to the image that it was rolled back from
+	if canary := infinispan.Status.CanaryUpgrade; infinispan.IsCanaryUpgrade() && canary != nil && canary.RolledBack && canary.TargetDefaultImage == infinispan.DefaultImageName() {
+		return false, nil
+	}
+
 	// All pods need to be ready for the upgrade to be scheduled
 	// Handles brief window during whichstatefulSetForInfinispan resources have been removed,
 	//and old ones terminating while new ones are being created.
@@ -902,7 +1297,7 @@ func upgradeRequired(infinispan *infinispanv1.Infinispan, podList *corev1.PodLis
 	podDefaultImage := kube.GetPodDefaultImage(podList.Items[0].Spec.Containers[0])
 
 	// Get Infinispan image that the operator creates
-	desiredImage := consts.DefaultImageName
+	desiredImage := infinispan.DefaultImageName()
 
 	// If the operator's default image differs from the pod's default image,
 	// schedule an upgrade by gracefully shutting down the current cluster.
@@ -922,24 +1317,138 @@ func PodList(infinispan *infinispanv1.Infinispan, kube *kube.Kubernetes, ctx con
 	return podList, kube.ResourcesList(infinispan.Namespace, PodLabels(infinispan.Name), podList, ctx)
 }
 
+// legacyAffinityTopologyKey is the misspelt hostname key of the default anti-affinity that previous releases stored in
+// the Infinispan spec
+const legacyAffinityTopologyKey = "r.kubernetes.io/hostname"
+
 func podAffinity(i *infinispanv1.Infinispan, matchLabels map[string]string) *corev1.Affinity {
-	// The user hasn't configured Affinity, so we utilise the default strategy of preferring pods are deployed on distinct nodes
-	if i.Spec.Affinity == nil {
-		return &corev1.Affinity{
+	affinity := i.Spec.Affinity
+	// The user hasn't configured Affinity, so we utilise the default strategy of preferring pods are deployed on distinct nodes and zones
+	if affinity == nil {
+		affinity = &corev1.Affinity{
 			PodAntiAffinity: &corev1.PodAntiAffinity{
-				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
-					Weight: 100,
-					PodAffinityTerm: corev1.PodAffinityTerm{
-						LabelSelector: &metav1.LabelSelector{
-							MatchLabels: matchLabels,
-						},
-						TopologyKey: "r.kubernetes.io/hostname",
-					},
-				}},
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					preferredAntiAffinityTerm(corev1.LabelHostname, matchLabels),
+					preferredAntiAffinityTerm(corev1.LabelZoneFailureDomainStable, matchLabels),
+				},
 			},
 		}
 	}
-	return i.Spec.Affinity
+	return architectureAffinity(i, affinity)
+}
+
+// architectureAffinity requires the nodes of the pods to have one of the architectures that the operand image is
+// available for, so that the pods of a mixed-architecture cluster aren't scheduled on nodes that can't run the image.
+// The requirement is added to every node selector term of the affinity, as the terms are ORed. The affinity is returned
+// unchanged, possibly nil, when the operand doesn't list its architectures
+func architectureAffinity(i *infinispanv1.Infinispan, affinity *corev1.Affinity) *corev1.Affinity {
+	operand := i.GetOperand()
+	if operand == nil || len(operand.Architectures) == 0 {
+		return affinity
+	}
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelArchStable,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   operand.ArchitectureNames(),
+	}
+	if affinity == nil {
+		affinity = &corev1.Affinity{}
+	} else {
+		affinity = affinity.DeepCopy()
+	}
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for t := range selector.NodeSelectorTerms {
+		selector.NodeSelectorTerms[t].MatchExpressions = append(selector.NodeSelectorTerms[t].MatchExpressions, requirement)
+	}
+	return affinity
+}
+
+func preferredAntiAffinityTerm(topologyKey string, matchLabels map[string]string) corev1.WeightedPodAffinityTerm {
+	return corev1.WeightedPodAffinityTerm{
+		Weight: 100,
+		PodAffinityTerm: corev1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: matchLabels,
+			},
+			TopologyKey: topologyKey,
+		},
+	}
+}
+
+// isLegacyDefaultAffinity returns true if the affinity is the default anti-affinity stored in the spec by previous
+// releases, which must be removed so that the current default applies
+func isLegacyDefaultAffinity(affinity *corev1.Affinity, matchLabels map[string]string) bool {
+	legacy := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				preferredAntiAffinityTerm(legacyAffinityTopologyKey, matchLabels),
+			},
+		},
+	}
+	return reflect.DeepEqual(affinity, legacy)
+}
+
+// topologySpreadConstraints returns the configured spread constraints, selecting the cluster pods when the constraint
+// has no label selector
+func topologySpreadConstraints(i *infinispanv1.Infinispan, matchLabels map[string]string) []corev1.TopologySpreadConstraint {
+	if i.Spec.Scheduling == nil || len(i.Spec.Scheduling.TopologySpreadConstraints) == 0 {
+		return nil
+	}
+	constraints := make([]corev1.TopologySpreadConstraint, len(i.Spec.Scheduling.TopologySpreadConstraints))
+	for idx, constraint := range i.Spec.Scheduling.TopologySpreadConstraints {
+		constraint.DeepCopyInto(&constraints[idx])
+		if constraints[idx].LabelSelector == nil {
+			constraints[idx].LabelSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+		}
+	}
+	return constraints
+}
+
+// applyPodScheduling sets the tolerations, node selector and priority class of the cluster pods, returning true if the
+// pod spec changed
+func applyPodScheduling(i *infinispanv1.Infinispan, spec *corev1.PodSpec) bool {
+	var tolerations []corev1.Toleration
+	var nodeSelector map[string]string
+	var priorityClassName string
+	if scheduling := i.Spec.Scheduling; scheduling != nil {
+		if len(scheduling.Tolerations) > 0 {
+			tolerations = make([]corev1.Toleration, len(scheduling.Tolerations))
+			for idx := range scheduling.Tolerations {
+				scheduling.Tolerations[idx].DeepCopyInto(&tolerations[idx])
+			}
+		}
+		if len(scheduling.NodeSelector) > 0 {
+			nodeSelector = make(map[string]string, len(scheduling.NodeSelector))
+			for key, value := range scheduling.NodeSelector {
+				nodeSelector[key] = value
+			}
+		}
+		priorityClassName = scheduling.PriorityClassName
+	}
+	changed := false
+	if !reflect.DeepEqual(spec.Tolerations, tolerations) {
+		spec.Tolerations = tolerations
+		changed = true
+	}
+	if !reflect.DeepEqual(spec.NodeSelector, nodeSelector) {
+		spec.NodeSelector = nodeSelector
+		changed = true
+	}
+	if spec.PriorityClassName != priorityClassName {
+		spec.PriorityClassName = priorityClassName
+		changed = true
+	}
+	return changed
 }
 
 func GetSingleStatefulSetStatus(ss appsv1.StatefulSet) infinispanv1.DeploymentStatus {
@@ -1096,18 +1605,20 @@ func (r *infinispanRequest) statefulSetForInfinispan(adminSecret, userSecret, ke
 					Annotations: map[string]string{"updateDate": time.Now().String()},
 				},
 				Spec: corev1.PodSpec{
-					Affinity: ispn.Spec.Affinity,
+					Affinity:                  podAffinity(ispn, lsPod),
+					TopologySpreadConstraints: topologySpreadConstraints(ispn, lsPod),
+					ReadinessGates:            podReadinessGates(ispn),
 					Containers: []corev1.Container{{
 						Image: ispn.ImageName(),
-						Name:  "infinispan",
+						Name:  InfinispanContainer,
 						Env: PodEnv(ispn, &[]corev1.EnvVar{
-							{Name: "CONFIG_HASH", Value: hash.HashString(configMap.Data[consts.ServerConfigFilename])},
-							{Name: "ADMIN_IDENTITIES_HASH", Value: hash.HashByte(adminSecret.Data[consts.ServerIdentitiesFilename])},
+							{Name: "CONFIG_HASH", Value: serverConfigHash(configMap)},
+							{Name: "ADMIN_IDENTITIES_HASH", Value: identitiesHash(adminSecret)},
 						}),
-						LivenessProbe:  PodLivenessProbe(),
+						LivenessProbe:  PodLivenessProbe(ispn),
 						Ports:          PodPortsWithXsite(ispn),
-						ReadinessProbe: PodReadinessProbe(),
-						StartupProbe:   PodStartupProbe(),
+						ReadinessProbe: PodReadinessProbe(ispn),
+						StartupProbe:   PodStartupProbe(ispn),
 						Resources:      *podResources,
 						VolumeMounts:   volumeMounts,
 					}},
@@ -1119,11 +1630,11 @@ func (r *infinispanRequest) statefulSetForInfinispan(adminSecret, userSecret, ke
 
 	// Only append IDENTITIES_HASH and secret volume if authentication is enabled
 	spec := &dep.Spec.Template.Spec
-	if AddVolumeForUserAuthentication(ispn, spec) {
+	if AddVolumeForUserAuthentication(ispn, &dep.Spec.Template.ObjectMeta, spec) && userSecret != nil {
 		spec.Containers[0].Env = append(spec.Containers[0].Env,
 			corev1.EnvVar{
 				Name:  "IDENTITIES_HASH",
-				Value: hash.HashByte(userSecret.Data[consts.ServerIdentitiesFilename]),
+				Value: identitiesHash(userSecret),
 			})
 	}
 
@@ -1186,6 +1697,14 @@ func (r *infinispanRequest) statefulSetForInfinispan(adminSecret, userSecret, ke
 				EmptyDir: &corev1.EmptyDirVolumeSource{},
 			},
 		}
+		// The storage size limits the emptyDir volume, so that the pods are evicted instead of filling the node disk
+		if storageSize := ispn.StorageSize(); storageSize != "" {
+			sizeLimit, err := resource.ParseQuantity(storageSize)
+			if err != nil {
+				return nil, err
+			}
+			ephemeralVolume.EmptyDir.SizeLimit = &sizeLimit
+		}
 		*volumes = append(*volumes, ephemeralVolume)
 		errMsg := "Ephemeral storage configured. All data will be lost on cluster shutdown and restart."
 		r.eventRec.Event(ispn, corev1.EventTypeWarning, EventReasonEphemeralStorage, errMsg)
@@ -1197,12 +1716,16 @@ func (r *infinispanRequest) statefulSetForInfinispan(adminSecret, userSecret, ke
 	}
 
 	applyExternalDependenciesVolume(ispn, &dep.Spec.Template.Spec)
+	applyPodScheduling(ispn, &dep.Spec.Template.Spec)
+	ApplyUserContainers(ispn, &dep.Spec.Template.ObjectMeta, &dep.Spec.Template.Spec)
+	ApplyConsoleProxy(ispn, &dep.Spec.Template.ObjectMeta, &dep.Spec.Template.Spec)
+	applyPodSecurityProfile(ispn, &dep.Spec.Template.Spec)
 	if ispn.IsEncryptionEnabled() {
 		AddVolumesForEncryption(ispn, &dep.Spec.Template.Spec)
 		spec.Containers[0].Env = append(spec.Containers[0].Env,
 			corev1.EnvVar{
 				Name:  "KEYSTORE_HASH",
-				Value: hash.HashMap(keystoreSecret.Data),
+				Value: keystoreHash(ispn, keystoreSecret),
 			})
 
 		if ispn.IsClientCertEnabled() {
@@ -1213,6 +1736,27 @@ func (r *infinispanRequest) statefulSetForInfinispan(adminSecret, userSecret, ke
 				})
 		}
 	}
+	if ispn.IsTransportEncryptionEnabled() {
+		AddVolumeForTransportEncryption(ispn, &dep.Spec.Template.Spec)
+	}
+	if ispn.HasSites() && ispn.GetGossipRouterTLS() != nil {
+		AddVolumeForCrossSiteEncryption(ispn, &dep.Spec.Template.Spec)
+	}
+	if ispn.GetMetricsTLSSecretName() != "" {
+		AddVolumeForMetricsTLS(ispn, &dep.Spec.Template.Spec)
+	}
+	if ispn.GetLdapRealm() != nil {
+		AddVolumesForLdapRealm(ispn, &dep.Spec.Template.Spec)
+	}
+	if ispn.GetOAuth2Realm() != nil {
+		AddVolumesForOAuth2Realm(ispn, &dep.Spec.Template.Spec)
+	}
+	if ispn.Spec.Tracing != nil {
+		AddVolumesForTracing(ispn, &dep.Spec.Template.Spec)
+	}
+	// Record the user defined variables added by PodEnv
+	ApplyUserEnv(ispn, &dep.Spec.Template.ObjectMeta, spec)
+	ApplyPropagatedMetadata(ispn, dep)
 
 	// Set Infinispan instance as the owner and controller
 	if err = controllerutil.SetControllerReference(ispn, dep, r.scheme); err != nil {
@@ -1222,8 +1766,8 @@ func (r *infinispanRequest) statefulSetForInfinispan(adminSecret, userSecret, ke
 }
 
 // getInfinispanConditions returns the pods status and a summary status for the cluster
-func getInfinispanConditions(pods []corev1.Pod, m *infinispanv1.Infinispan, cluster ispn.ClusterInterface) []infinispanv1.InfinispanCondition {
-	var status []infinispanv1.InfinispanCondition
+func getInfinispanConditions(pods []corev1.Pod, m *infinispanv1.Infinispan, cluster ispn.ClusterInterface) []metav1.Condition {
+	var status []metav1.Condition
 	clusterViews := make(map[string]bool)
 	var errors []string
 	// Avoid to inspect the system if we're still waiting for the pods
@@ -1247,7 +1791,7 @@ func getInfinispanConditions(pods []corev1.Pod, m *infinispanv1.Infinispan, clus
 		}
 	}
 	// Evaluating WellFormed condition
-	wellformed := infinispanv1.InfinispanCondition{Type: infinispanv1.ConditionWellFormed}
+	wellformed := metav1.Condition{Type: string(infinispanv1.ConditionWellFormed)}
 	views := make([]string, len(clusterViews))
 	i := 0
 	for k := range clusterViews {
@@ -1258,13 +1802,16 @@ func getInfinispanConditions(pods []corev1.Pod, m *infinispanv1.Infinispan, clus
 	if len(errors) == 0 {
 		if len(views) == 1 {
 			wellformed.Status = metav1.ConditionTrue
+			wellformed.Reason = infinispanv1.ReasonClusterFormed
 			wellformed.Message = "View: " + views[0]
 		} else {
 			wellformed.Status = metav1.ConditionFalse
+			wellformed.Reason = infinispanv1.ReasonClusterNotFormed
 			wellformed.Message = "Views: " + strings.Join(views, ",")
 		}
 	} else {
 		wellformed.Status = metav1.ConditionUnknown
+		wellformed.Reason = infinispanv1.ReasonPodsNotReady
 		wellformed.Message = "Errors: " + strings.Join(errors, ",") + " Views: " + strings.Join(views, ",")
 	}
 	status = append(status, wellformed)
@@ -1272,15 +1819,19 @@ func getInfinispanConditions(pods []corev1.Pod, m *infinispanv1.Infinispan, clus
 }
 
 func (r *infinispanRequest) reconcileGracefulShutdown(statefulSet *appsv1.StatefulSet, podList *corev1.PodList,
-	logger logr.Logger, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+	logger logr.Logger) (*ctrl.Result, error) {
 	ispn := r.infinispan
 	if ispn.Spec.Replicas == 0 {
 		logger.Info(".Spec.Replicas==0")
+		// The zero-capacity pods don't hold data, they are removed before the cluster shutdown
+		if err := r.deleteZeroCapacityNodes(); err != nil {
+			return &ctrl.Result{}, err
+		}
 		if *statefulSet.Spec.Replicas != 0 {
 			logger.Info("StatefulSet.Spec.Replicas!=0")
 			// If cluster hasn't a `stopping` condition or it's false then send a graceful shutdown
 			if !ispn.IsConditionTrue(infinispanv1.ConditionStopping) {
-				res, err := r.gracefulShutdownReq(podList, logger, cluster)
+				res, err := r.gracefulShutdownReq(podList, logger)
 				if res != nil {
 					return res, err
 				}
@@ -1315,11 +1866,19 @@ func (r *infinispanRequest) reconcileGracefulShutdown(statefulSet *appsv1.Statef
 
 		return &ctrl.Result{Requeue: true}, r.update(func() {
 			if statefulSet.Status.CurrentReplicas == 0 {
-				ispn.SetCondition(infinispanv1.ConditionGracefulShutdown, metav1.ConditionTrue, "")
-				ispn.SetCondition(infinispanv1.ConditionStopping, metav1.ConditionFalse, "")
+				ispn.SetCondition(infinispanv1.ConditionGracefulShutdown, metav1.ConditionTrue, infinispanv1.ReasonClusterStopped, "")
+				ispn.SetCondition(infinispanv1.ConditionStopping, metav1.ConditionFalse, infinispanv1.ReasonClusterStopped, "")
 			}
 		})
 	}
+	if ispn.Spec.Replicas != 0 && ispn.GetCondition(infinispanv1.ConditionGracefulShutdown).Reason == infinispanv1.ReasonShutdownFailed {
+		// The shutdown that failed is no longer requested
+		if err := r.update(func() {
+			ispn.SetCondition(infinispanv1.ConditionGracefulShutdown, metav1.ConditionFalse, infinispanv1.ReasonAsExpected, "")
+		}); err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
 	if ispn.Spec.Replicas != 0 && ispn.IsConditionTrue(infinispanv1.ConditionGracefulShutdown) {
 		logger.Info("Resuming from graceful shutdown")
 		// If here we're resuming from graceful shutdown
@@ -1327,8 +1886,9 @@ func (r *infinispanRequest) reconcileGracefulShutdown(statefulSet *appsv1.Statef
 			return &ctrl.Result{Requeue: true}, fmt.Errorf("Spec.Replicas(%d) must be 0 or equal to Status.ReplicasWantedAtRestart(%d)", ispn.Spec.Replicas, ispn.Status.ReplicasWantedAtRestart)
 		}
 
+		// The cluster is resumed once the pods have rejoined and recovered the persisted data
 		if err := r.update(func() {
-			ispn.SetCondition(infinispanv1.ConditionGracefulShutdown, metav1.ConditionFalse, "")
+			ispn.SetCondition(infinispanv1.ConditionGracefulShutdown, metav1.ConditionFalse, infinispanv1.ReasonClusterRecovering, fmt.Sprintf("Waiting for the %d pods to recover the persisted data", ispn.Spec.Replicas))
 			ispn.Status.ReplicasWantedAtRestart = 0
 		}); err != nil {
 			return &ctrl.Result{}, err
@@ -1339,28 +1899,58 @@ func (r *infinispanRequest) reconcileGracefulShutdown(statefulSet *appsv1.Statef
 	return nil, nil
 }
 
-// gracefulShutdownReq send a graceful shutdown request to the cluster
-func (r *infinispanRequest) gracefulShutdownReq(podList *corev1.PodList, logger logr.Logger, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+// reconcileShutdownRecovery waits for the caches of a cluster resumed from a graceful shutdown to be available, as
+// they recover the persisted data once all the members that were shutdown have rejoined the cluster
+func (r *infinispanRequest) reconcileShutdownRecovery(podList *corev1.PodList, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	if infinispan.GetCondition(infinispanv1.ConditionGracefulShutdown).Reason != infinispanv1.ReasonClusterRecovering {
+		return nil, nil
+	}
+	health, err := cluster.GetClusterHealth(podList.Items[0].Name)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if health.Status == ispn.ClusterHealthDegraded {
+		r.reqLogger.Info("Waiting for the cluster to recover the persisted data")
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+	r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonClusterRecovered, "The cluster recovered the persisted data")
+	return nil, r.update(func() {
+		infinispan.SetCondition(infinispanv1.ConditionGracefulShutdown, metav1.ConditionFalse, infinispanv1.ReasonClusterResumed, "")
+	})
+}
+
+// gracefulShutdownReq send a graceful shutdown request to the cluster. The request is executed in the background, as
+// the servers persist the data of the caches before acknowledging it. The pods are kept when the shutdown fails, as
+// deleting them would lose the data that is not persisted
+func (r *infinispanRequest) gracefulShutdownReq(podList *corev1.PodList, logger logr.Logger) (*ctrl.Result, error) {
 	ispn := r.infinispan
-	logger.Info("Sending graceful shutdown request")
-	// Send a graceful shutdown to the first ready pod. If no pods are ready, then there's nothing to shutdown
+	// The generation identifies the shutdown request, as each shutdown follows a change of the replicas
+	id := strconv.FormatInt(ispn.Generation, 10)
+	var readyPods []string
 	for _, pod := range podList.Items {
 		if kube.IsPodReady(pod) {
-			if err := cluster.GracefulShutdown(pod.GetName()); err != nil {
-				logger.Error(err, "Error encountered on cluster shutdown")
-
-				// ISPN-13141 causes GracefulShutdown to fail if there are issues with the server
-				logger.Info("Cluster Shutdown failed. Attempting to execute GracefulShutdownTask")
-				if err := cluster.GracefulShutdownTask(pod.GetName()); err != nil {
-					logger.Error(err, fmt.Sprintf("Error encountered using GracefulShutdownTask on pod %s", pod.Name))
-					continue
-				}
-			} else {
-				logger.Info("Executed graceful shutdown on pod: ", "Pod.Name", pod.Name)
-				break
-			}
+			readyPods = append(readyPods, pod.Name)
 		}
 	}
+	phase, err := r.runOperation(infinispanv1.OperationGracefulShutdown, id, func(op *operation) error {
+		return gracefulShutdown(readyPods, op.log, op.cluster)
+	})
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if phase == infinispanv1.OperationRunning {
+		logger.Info("Waiting for the graceful shutdown of the cluster")
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+	if phase == infinispanv1.OperationFailed {
+		// The shutdown is attempted again by the next update of the Infinispan CR, which changes the generation
+		message := fmt.Sprintf("The graceful shutdown failed, the pods are kept running: %s", ispn.GetOperation(infinispanv1.OperationGracefulShutdown).Message)
+		logger.Info(message)
+		return &ctrl.Result{}, r.update(func() {
+			ispn.SetCondition(infinispanv1.ConditionGracefulShutdown, metav1.ConditionFalse, infinispanv1.ReasonShutdownFailed, message)
+		})
+	}
 
 	logger.Info("GracefulShutdown executed. Deleting all pods")
 	deleteOptions := []client.DeleteAllOfOption{client.MatchingLabels(PodLabels(ispn.Name)), client.InNamespace(ispn.Namespace)}
@@ -1369,8 +1959,8 @@ func (r *infinispanRequest) gracefulShutdownReq(podList *corev1.PodList, logger
 	}
 
 	if err := r.update(func() {
-		ispn.SetCondition(infinispanv1.ConditionStopping, metav1.ConditionTrue, "")
-		ispn.SetCondition(infinispanv1.ConditionWellFormed, metav1.ConditionFalse, "")
+		ispn.SetCondition(infinispanv1.ConditionStopping, metav1.ConditionTrue, infinispanv1.ReasonShutdownRequested, "")
+		ispn.SetCondition(infinispanv1.ConditionWellFormed, metav1.ConditionFalse, infinispanv1.ReasonShutdownRequested, "")
 	}); err != nil {
 		return &ctrl.Result{}, err
 	}
@@ -1378,6 +1968,29 @@ func (r *infinispanRequest) gracefulShutdownReq(podList *corev1.PodList, logger
 	return &ctrl.Result{Requeue: true, RequeueAfter: time.Second}, nil
 }
 
+// gracefulShutdown sends the graceful shutdown request to the first ready pod accepting it. If no pods are ready, then
+// there's nothing to shutdown
+func gracefulShutdown(readyPods []string, logger logr.Logger, cluster ispn.ClusterInterface) error {
+	logger.Info("Sending graceful shutdown request")
+	var shutdownErr error
+	for _, podName := range readyPods {
+		if err := cluster.GracefulShutdown(podName); err != nil {
+			logger.Error(err, "Error encountered on cluster shutdown")
+
+			// ISPN-13141 causes GracefulShutdown to fail if there are issues with the server
+			logger.Info("Cluster Shutdown failed. Attempting to execute GracefulShutdownTask")
+			if err := cluster.GracefulShutdownTask(podName); err != nil {
+				logger.Error(err, fmt.Sprintf("Error encountered using GracefulShutdownTask on pod %s", podName))
+				shutdownErr = fmt.Errorf("unable to shutdown the cluster from pod %s: %w", podName, err)
+				continue
+			}
+		}
+		logger.Info("Executed graceful shutdown on pod: ", "Pod.Name", podName)
+		return nil
+	}
+	return shutdownErr
+}
+
 // reconcileContainerConf reconcile the .Container struct is changed in .Spec. This needs a cluster restart
 func (r *infinispanRequest) reconcileContainerConf(statefulSet *appsv1.StatefulSet, configMap *corev1.ConfigMap, adminSecret,
 	userSecret, keystoreSecret, trustSecret *corev1.Secret) (*ctrl.Result, error) {
@@ -1387,7 +2000,7 @@ func (r *infinispanRequest) reconcileContainerConf(statefulSet *appsv1.StatefulS
 	// Ensure the deployment size is the same as the spec
 	replicas := ispn.Spec.Replicas
 	previousReplicas := *statefulSet.Spec.Replicas
-	if previousReplicas != replicas {
+	if previousReplicas != replicas && !isCoordinatedScaleDown(replicas, previousReplicas) {
 		statefulSet.Spec.Replicas = &replicas
 		r.reqLogger.Info("replicas changed, update infinispan", "replicas", replicas, "previous replicas", previousReplicas)
 		updateNeeded = true
@@ -1428,14 +2041,43 @@ func (r *infinispanRequest) reconcileContainerConf(statefulSet *appsv1.StatefulS
 		}
 	}
 
-	if !reflect.DeepEqual(spec.Affinity, ispn.Spec.Affinity) {
-		spec.Affinity = ispn.Spec.Affinity
+	if affinity := podAffinity(ispn, PodLabels(ispn.Name)); !reflect.DeepEqual(spec.Affinity, affinity) {
+		spec.Affinity = affinity
+		updateNeeded = true
+	}
+
+	if constraints := topologySpreadConstraints(ispn, PodLabels(ispn.Name)); !reflect.DeepEqual(spec.TopologySpreadConstraints, constraints) {
+		spec.TopologySpreadConstraints = constraints
+		updateNeeded = true
+	}
+	updateNeeded = applyPodScheduling(ispn, spec) || updateNeeded
+
+	if gates := podReadinessGates(ispn); !reflect.DeepEqual(spec.ReadinessGates, gates) {
+		spec.ReadinessGates = gates
+		updateNeeded = true
+	}
+
+	// Validate probe thresholds changes
+	container := &spec.Containers[0]
+	if liveness := PodLivenessProbe(ispn); !reflect.DeepEqual(container.LivenessProbe, liveness) {
+		container.LivenessProbe = liveness
+		updateNeeded = true
+	}
+	if readiness := PodReadinessProbe(ispn); !reflect.DeepEqual(container.ReadinessProbe, readiness) {
+		container.ReadinessProbe = readiness
+		updateNeeded = true
+	}
+	if startup := PodStartupProbe(ispn); !reflect.DeepEqual(container.StartupProbe, startup) {
+		container.StartupProbe = startup
 		updateNeeded = true
 	}
 
+	// Labels and annotations propagated from spec.metadata don't require the pods to be restarted
+	updateNeeded = ApplyPropagatedMetadata(ispn, statefulSet) || updateNeeded
+
 	// Validate ConfigMap changes (by the hash of the infinispan.yaml key value)
-	updateNeeded = updateStatefulSetEnv(statefulSet, "CONFIG_HASH", hash.HashString(configMap.Data[consts.ServerConfigFilename])) || updateNeeded
-	updateNeeded = updateStatefulSetEnv(statefulSet, "ADMIN_IDENTITIES_HASH", hash.HashByte(adminSecret.Data[consts.ServerIdentitiesFilename])) || updateNeeded
+	updateNeeded = applyServerConfigHash(statefulSet, configMap) || updateNeeded
+	updateNeeded = updateStatefulSetEnv(statefulSet, "ADMIN_IDENTITIES_HASH", identitiesHash(adminSecret)) || updateNeeded
 
 	externalArtifactsUpd, err := applyExternalArtifactsDownload(ispn, &statefulSet.Spec.Template.Spec)
 	if err != nil {
@@ -1443,43 +2085,58 @@ func (r *infinispanRequest) reconcileContainerConf(statefulSet *appsv1.StatefulS
 	}
 	updateNeeded = externalArtifactsUpd || updateNeeded
 	updateNeeded = applyExternalDependenciesVolume(ispn, &statefulSet.Spec.Template.Spec) || updateNeeded
+	updateNeeded = ApplyUserContainers(ispn, &statefulSet.Spec.Template.ObjectMeta, spec) || updateNeeded
+	updateNeeded = ApplyConsoleProxy(ispn, &statefulSet.Spec.Template.ObjectMeta, spec) || updateNeeded
+	updateNeeded = applyPodSecurityProfile(ispn, spec) || updateNeeded
 
-	// Validate identities Secret name changes
-	if secretName, secretIndex := findSecretInVolume(&statefulSet.Spec.Template.Spec, IdentitiesVolumeName); secretIndex >= 0 && secretName != ispn.GetSecretName() {
-		// Update new Secret name inside StatefulSet.Spec.Template
-		statefulSet.Spec.Template.Spec.Volumes[secretIndex].Secret.SecretName = ispn.GetSecretName()
-		statefulSet.Spec.Template.Annotations["updateDate"] = time.Now().String()
-		updateNeeded = true
-	}
-
-	if ispn.IsAuthenticationEnabled() {
-		if AddVolumeForUserAuthentication(ispn, spec) {
-			if userSecret == nil {
-				return &ctrl.Result{}, fmt.Errorf("User secret is nil. Requeueing")
-			}
-			spec.Containers[0].Env = append(spec.Containers[0].Env,
-				corev1.EnvVar{Name: "IDENTITIES_HASH", Value: hash.HashByte(userSecret.Data[consts.ServerIdentitiesFilename])},
-				corev1.EnvVar{Name: "IDENTITIES_PATH", Value: consts.ServerUserIdentitiesPath},
-			)
-			updateNeeded = true
-		} else {
-			// Validate Secret changes (by the hash of the identities.yaml key value)
-			updateNeeded = updateStatefulSetEnv(statefulSet, "IDENTITIES_HASH", hash.HashByte(userSecret.Data[consts.ServerIdentitiesFilename])) || updateNeeded
-		}
-	}
+	updateNeeded = applyIdentities(ispn, statefulSet, userSecret) || updateNeeded
 
 	if ispn.IsEncryptionEnabled() {
 		AddVolumesForEncryption(ispn, spec)
-		updateNeeded = updateStatefulSetEnv(statefulSet, "KEYSTORE_HASH", hash.HashMap(keystoreSecret.Data)) || updateNeeded
+		updateNeeded = updateStatefulSetEnv(statefulSet, "KEYSTORE_HASH", keystoreHash(ispn, keystoreSecret)) || updateNeeded
 
 		if ispn.IsClientCertEnabled() {
 			updateNeeded = updateStatefulSetEnv(statefulSet, "TRUSTSTORE_HASH", hash.HashMap(trustSecret.Data)) || updateNeeded
 		}
 	}
 
-	// Validate extra Java options changes
-	if updateStatefulSetEnv(statefulSet, "EXTRA_JAVA_OPTIONS", ispnContr.ExtraJvmOpts) {
-		updateStatefulSetEnv(statefulSet, "JAVA_OPTIONS", ispn.GetJavaOptions())
+	if ispn.IsTransportEncryptionEnabled() {
+		// The pods are restarted by the configuration change enabling the encryption
+		AddVolumeForTransportEncryption(ispn, spec)
+	}
+
+	if ispn.HasSites() && ispn.GetGossipRouterTLS() != nil {
+		// The pods are restarted by the configuration change securing the cross-site tunnel
+		AddVolumeForCrossSiteEncryption(ispn, spec)
+	}
+
+	if ispn.GetMetricsTLSSecretName() != "" {
+		// The pods are restarted by the configuration change serving the metrics over TLS
+		AddVolumeForMetricsTLS(ispn, spec)
+	}
+
+	if ispn.GetLdapRealm() != nil {
+		// The pods are restarted by the configuration change enabling the LDAP realm
+		AddVolumesForLdapRealm(ispn, spec)
+	}
+
+	if ispn.GetOAuth2Realm() != nil {
+		// The pods are restarted by the configuration change enabling the token realm
+		AddVolumesForOAuth2Realm(ispn, spec)
+	}
+
+	if ispn.Spec.Tracing != nil {
+		// The pods are restarted by the configuration change enabling the tracing
+		AddVolumesForTracing(ispn, spec)
+	}
+
+	// Validate Java options changes, the options auto-tuned by the memory policy depend on the container memory too
+	updateNeeded = updateStatefulSetEnv(statefulSet, "EXTRA_JAVA_OPTIONS", ispnContr.GetExtraJvmOpts()) || updateNeeded
+	updateNeeded = updateStatefulSetEnv(statefulSet, "JAVA_OPTIONS", ispn.GetJavaOptions()) || updateNeeded
+
+	// Validate user defined variables changes
+	if ApplyUserEnv(ispn, &statefulSet.Spec.Template.ObjectMeta, spec) {
+		statefulSet.Spec.Template.Annotations["updateDate"] = time.Now().String()
 		updateNeeded = true
 	}
 
@@ -1525,6 +2182,18 @@ func updateStatefulSetEnv(statefulSet *appsv1.StatefulSet, envName, newValue str
 	return false
 }
 
+// removeStatefulSetEnv removes the env variable from the server container, returning true if it was present
+func removeStatefulSetEnv(statefulSet *appsv1.StatefulSet, envName string) bool {
+	env := &statefulSet.Spec.Template.Spec.Containers[0].Env
+	envIndex := kube.GetEnvVarIndex(envName, env)
+	if envIndex < 0 {
+		return false
+	}
+	*env = append((*env)[:envIndex], (*env)[envIndex+1:]...)
+	statefulSet.Spec.Template.Annotations["updateDate"] = time.Now().String()
+	return true
+}
+
 func findSecretInVolume(pod *corev1.PodSpec, volumeName string) (string, int) {
 	for i, volumes := range pod.Volumes {
 		if volumes.Secret != nil && volumes.Name == volumeName {
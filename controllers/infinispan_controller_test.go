@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// healthCluster reports the configured cluster health status
+type healthCluster struct {
+	ispn.ClusterInterface
+	status string
+}
+
+func (c *healthCluster) GetClusterHealth(podName string) (*ispn.ClusterHealth, error) {
+	return &ispn.ClusterHealth{Status: c.status}, nil
+}
+
+func TestReconcileShutdownRecovery(t *testing.T) {
+	r := dataMigrationRequest(t, nil)
+	infinispan := r.infinispan
+
+	// Nothing to wait for without a resumed graceful shutdown
+	result, err := r.reconcileShutdownRecovery(migrationPods, &healthCluster{status: ispn.ClusterHealthDegraded})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	infinispan.SetCondition(infinispanv1.ConditionGracefulShutdown, metav1.ConditionFalse, infinispanv1.ReasonClusterRecovering, "")
+	result, err = r.reconcileShutdownRecovery(migrationPods, &healthCluster{status: ispn.ClusterHealthDegraded})
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.True(t, infinispan.IsConditionTrue(infinispanv1.ConditionProgressing))
+
+	result, err = r.reconcileShutdownRecovery(migrationPods, &healthCluster{status: "HEALTHY"})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, infinispanv1.ReasonClusterResumed, infinispan.GetCondition(infinispanv1.ConditionGracefulShutdown).Reason)
+}
@@ -1,14 +1,17 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/go-logr/logr"
 	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
 	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
 	"github.com/infinispan/infinispan-operator/pkg/infinispan/security"
 	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
 	corev1 "k8s.io/api/core/v1"
@@ -21,16 +24,39 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	k8sctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	EncryptClientCertPrefix   = "trust.cert."
 	EncryptClientCAName       = "trust.ca"
 	EncryptTruststorePassword = "password"
+
+	TruststoreCertsHashAnnotation = "infinispan.org/truststore-certs-hash"
 )
 
+// truststoreCerts returns the CA and client certificates of the Secret the Truststore is generated from, in a stable order
+func truststoreCerts(secret *corev1.Secret) [][]byte {
+	var certs [][]byte
+	if ca, ok := secret.Data[EncryptClientCAName]; ok {
+		certs = append(certs, ca)
+	}
+	var certKeys []string
+	for certKey := range secret.Data {
+		if strings.HasPrefix(certKey, EncryptClientCertPrefix) {
+			certKeys = append(certKeys, certKey)
+		}
+	}
+	sort.Strings(certKeys)
+	for _, certKey := range certKeys {
+		certs = append(certs, secret.Data[certKey])
+	}
+	return certs
+}
+
 // ReconcileSecret reconciles a Secret object
 type SecretReconciler struct {
 	client.Client
@@ -60,6 +86,22 @@ func (r *SecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Named(name).
 		For(&ispnv1.Infinispan{}).
 		Owns(&corev1.Secret{}).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					// Regenerate the Truststore when the client certificates of a user defined Secret change
+					var requests []reconcile.Request
+					ispnList := &ispnv1.InfinispanList{}
+					if err := r.kubernetes.ResourcesListByField(a.GetNamespace(), "spec.security.endpointEncryption.clientCertSecretName", a.GetName(), ispnList, context.TODO()); err != nil {
+						r.log.Error(err, "failed to list Infinispan CR")
+					}
+					for _, item := range ispnList.Items {
+						requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}})
+					}
+					return requests
+				}),
+		).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
 				switch e.Object.(type) {
@@ -109,22 +151,25 @@ func (reconciler *SecretReconciler) Reconcile(ctx context.Context, request recon
 		return *result, err
 	}
 
-	// Reconcile Credential Secrets
-	if err := r.reconcileAdminSecret(); err != nil {
+	if err := r.reconcileCertManagerCertificate(); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// If the user has provided their own secret or authentication is disabled, do nothing
-	if !r.infinispan.IsAuthenticationEnabled() || !r.infinispan.IsGeneratedSecret() {
-		return reconcile.Result{}, nil
+	if err := r.reconcileTransportKeystoreSecret(); err != nil {
+		return reconcile.Result{}, err
 	}
 
-	// Create the user identities secret if it doesn't already exist
-	secret, err := r.getSecret(r.infinispan.GetSecretName())
-	if secret != nil || err != nil {
+	// Reconcile Credential Secrets
+	if err := r.reconcileAdminSecret(); err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, r.createUserIdentitiesSecret()
+
+	if r.infinispan.IsAuthenticationEnabled() {
+		if err := identitiesSourceFor(r.infinispan).provision(r); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return r.reconcileCredentialRotation()
 }
 
 func (s *secretRequest) createUserIdentitiesSecret() error {
@@ -152,6 +197,7 @@ func (s *secretRequest) createSecret(name, label string, identities []byte) erro
 
 	s.reqLogger.Info(fmt.Sprintf("Creating Identities Secret %s", secret.Name))
 	_, err := k8sctrlutil.CreateOrUpdate(s.ctx, s.Client, secret, func() error {
+		ApplyPropagatedMetadata(s.infinispan, secret)
 		return k8sctrlutil.SetControllerReference(s.infinispan, secret, s.scheme)
 	})
 
@@ -192,36 +238,45 @@ func (s *secretRequest) reconcileTruststoreSecret() (*reconcile.Result, error) {
 		}
 
 		_, truststoreExists := trustSecret.Data[consts.EncryptTruststoreKey]
-		if truststoreExists {
+		certs := truststoreCerts(trustSecret)
+		if len(certs) == 0 {
+			if !truststoreExists {
+				return fmt.Errorf("the '%s' Secret must contain a '%s' key, a '%s' key or client certificates with the '%s' prefix",
+					trustSecret.Name, consts.EncryptTruststoreKey, EncryptClientCAName, EncryptClientCertPrefix)
+			}
+			// The Truststore is provided by the user
 			if _, ok := trustSecret.Data[consts.EncryptTruststorePasswordKey]; !ok {
 				return fmt.Errorf("the '%s' key must be provided when configuring an existing Truststore", consts.EncryptTruststorePasswordKey)
 			}
-		} else {
-			caPem := trustSecret.Data[EncryptClientCAName]
-			if trustSecret.Data == nil {
-				trustSecret.Data = map[string][]byte{
-					consts.EncryptTruststorePasswordKey: []byte(EncryptTruststorePassword),
-				}
-			} else {
-				if _, passwordProvided := trustSecret.Data[consts.EncryptTruststorePasswordKey]; !passwordProvided {
-					trustSecret.Data[consts.EncryptTruststorePasswordKey] = []byte(EncryptTruststorePassword)
-				}
-			}
+			return nil
+		}
+		if i.Spec.Security.EndpointEncryption.ClientCert == ispnv1.ClientCertAuthenticate && len(certs) == 1 && trustSecret.Data[EncryptClientCAName] != nil {
+			// Authenticate requires every client certificate to be in the Truststore, the CA alone doesn't authenticate any client
+			return fmt.Errorf("the '%s' Secret must contain the client certificates with the '%s' prefix when clientCert is '%s'",
+				trustSecret.Name, EncryptClientCertPrefix, ispnv1.ClientCertAuthenticate)
+		}
 
-			certs := [][]byte{caPem}
-			for certKey := range trustSecret.Data {
-				if strings.HasPrefix(certKey, EncryptClientCertPrefix) {
-					certs = append(certs, trustSecret.Data[certKey])
-				}
-			}
-			password := string(trustSecret.Data[consts.EncryptTruststorePasswordKey])
-			truststore, err := security.GenerateTruststore(certs, password)
-			if err != nil {
-				return err
-			}
-			trustSecret.Data[consts.EncryptTruststoreKey] = truststore
+		// The Truststore is generated from the certificates, and regenerated when they change
+		certsHash := hash.HashByte(bytes.Join(certs, nil))
+		if trustSecret.Annotations == nil {
+			trustSecret.Annotations = map[string]string{}
+		}
+		generatedHash, annotated := trustSecret.Annotations[TruststoreCertsHashAnnotation]
+		if truststoreExists && (generatedHash == certsHash || !annotated) {
+			// Truststores generated before the certificates hash was recorded are adopted as is
+			trustSecret.Annotations[TruststoreCertsHashAnnotation] = certsHash
 			return nil
 		}
+		if _, passwordProvided := trustSecret.Data[consts.EncryptTruststorePasswordKey]; !passwordProvided {
+			trustSecret.Data[consts.EncryptTruststorePasswordKey] = []byte(EncryptTruststorePassword)
+		}
+		password := string(trustSecret.Data[consts.EncryptTruststorePasswordKey])
+		truststore, err := security.GenerateTruststore(certs, password)
+		if err != nil {
+			return err
+		}
+		trustSecret.Data[consts.EncryptTruststoreKey] = truststore
+		trustSecret.Annotations[TruststoreCertsHashAnnotation] = certsHash
 		return nil
 	})
 	if err != nil {
@@ -268,6 +323,7 @@ func (s *secretRequest) reconcileAdminSecret() error {
 		s.addCliProperties(adminSecret, password)
 		s.addServiceMonitorProperties(adminSecret, password)
 		adminSecret.Data[consts.ServerIdentitiesFilename] = identities
+		ApplyPropagatedMetadata(s.infinispan, adminSecret)
 		return nil
 	})
 	return err
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func selfSignedCert(t *testing.T, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func truststoreRequest(t *testing.T, clientCert infinispanv1.ClientCertType, data map[string][]byte) *secretRequest {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, infinispanv1.AddToScheme(scheme))
+
+	infinispan := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: infinispanv1.InfinispanSpec{
+			Security: infinispanv1.InfinispanSecurity{
+				EndpointEncryption: &infinispanv1.EndpointEncryption{
+					Type:                 infinispanv1.CertificateSourceTypeSecret,
+					CertSecretName:       "tls-secret",
+					ClientCert:           clientCert,
+					ClientCertSecretName: "client-cert-secret",
+				},
+			},
+		},
+	}
+	trustSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "client-cert-secret", Namespace: namespace, CreationTimestamp: metav1.Now()},
+		Data:       data,
+	}
+	return &secretRequest{
+		SecretReconciler: &SecretReconciler{
+			Client:   fake.NewFakeClientWithScheme(scheme, trustSecret),
+			scheme:   scheme,
+			eventRec: record.NewFakeRecorder(10),
+		},
+		infinispan: infinispan,
+		reqLogger:  logf.Log,
+		ctx:        context.TODO(),
+	}
+}
+
+func truststoreSecret(t *testing.T, r *secretRequest) *corev1.Secret {
+	secret := &corev1.Secret{}
+	assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "client-cert-secret"}, secret))
+	return secret
+}
+
+func TestTruststoreCertsOrder(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{
+		"trust.cert.b":                      []byte("b"),
+		"trust.cert.a":                      []byte("a"),
+		EncryptClientCAName:                 []byte("ca"),
+		consts.EncryptTruststorePasswordKey: []byte("password"),
+	}}
+	assert.Equal(t, [][]byte{[]byte("ca"), []byte("a"), []byte("b")}, truststoreCerts(secret))
+}
+
+func TestReconcileTruststoreSecretRegeneratesOnCertChange(t *testing.T) {
+	r := truststoreRequest(t, infinispanv1.ClientCertAuthenticate, map[string][]byte{"trust.cert.client1": selfSignedCert(t, "client1")})
+	result, err := r.reconcileTruststoreSecret()
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+
+	secret := truststoreSecret(t, r)
+	truststore := secret.Data[consts.EncryptTruststoreKey]
+	assert.NotEmpty(t, truststore)
+	assert.Equal(t, []byte(EncryptTruststorePassword), secret.Data[consts.EncryptTruststorePasswordKey])
+	assert.NotEmpty(t, secret.Annotations[TruststoreCertsHashAnnotation])
+
+	// Unchanged certificates don't regenerate the Truststore
+	_, err = r.reconcileTruststoreSecret()
+	assert.NoError(t, err)
+	assert.Equal(t, truststore, truststoreSecret(t, r).Data[consts.EncryptTruststoreKey])
+
+	// A new client certificate is added to the Truststore
+	secret.Data["trust.cert.client2"] = selfSignedCert(t, "client2")
+	assert.NoError(t, r.Client.Update(r.ctx, secret))
+	_, err = r.reconcileTruststoreSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, truststore, truststoreSecret(t, r).Data[consts.EncryptTruststoreKey])
+}
+
+func TestReconcileTruststoreSecretKeepsUserTruststore(t *testing.T) {
+	r := truststoreRequest(t, infinispanv1.ClientCertValidate, map[string][]byte{
+		consts.EncryptTruststoreKey:         []byte("user-truststore"),
+		consts.EncryptTruststorePasswordKey: []byte("secret"),
+	})
+	_, err := r.reconcileTruststoreSecret()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("user-truststore"), truststoreSecret(t, r).Data[consts.EncryptTruststoreKey])
+
+	r = truststoreRequest(t, infinispanv1.ClientCertValidate, map[string][]byte{consts.EncryptTruststoreKey: []byte("user-truststore")})
+	_, err = r.reconcileTruststoreSecret()
+	assert.Error(t, err)
+}
+
+func TestReconcileTruststoreSecretValidatesCerts(t *testing.T) {
+	_, err := truststoreRequest(t, infinispanv1.ClientCertValidate, map[string][]byte{}).reconcileTruststoreSecret()
+	assert.Error(t, err)
+
+	ca := selfSignedCert(t, "ca")
+	// The CA doesn't authenticate any client
+	_, err = truststoreRequest(t, infinispanv1.ClientCertAuthenticate, map[string][]byte{EncryptClientCAName: ca}).reconcileTruststoreSecret()
+	assert.Error(t, err)
+
+	_, err = truststoreRequest(t, infinispanv1.ClientCertValidate, map[string][]byte{EncryptClientCAName: ca}).reconcileTruststoreSecret()
+	assert.NoError(t, err)
+}
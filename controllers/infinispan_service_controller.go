@@ -9,6 +9,7 @@ import (
 	"github.com/go-logr/logr"
 	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
 	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	grafanav1alpha1 "github.com/infinispan/infinispan-operator/pkg/apis/integreatly/v1alpha1"
 	hash "github.com/infinispan/infinispan-operator/pkg/hash"
 	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
 	routev1 "github.com/openshift/api/route/v1"
@@ -20,18 +21,24 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	SecretHashAnnotation = "infinispan.org/secret-hash"
+
+	EventReasonMonitoringUnavailable          = "MonitoringUnavailable"
+	EventReasonUserWorkloadMonitoringDisabled = "UserWorkloadMonitoringDisabled"
 )
 
 // reconcileConfig reconciles a Service,Route and Ingress objects
@@ -59,20 +66,52 @@ func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.kube = kube.NewKubernetesFromController(mgr)
 	r.eventRec = mgr.GetEventRecorderFor(name + "-controller")
 	r.supportedTypes = map[string]*reconcileType{
-		consts.ExternalTypeService: {ObjectType: &corev1.Service{}, GroupVersion: corev1.SchemeGroupVersion, GroupVersionSupported: true},
-		consts.ExternalTypeRoute:   {ObjectType: &routev1.Route{}, GroupVersion: routev1.SchemeGroupVersion, GroupVersionSupported: false},
-		consts.ExternalTypeIngress: {ObjectType: &ingressv1.Ingress{}, GroupVersion: schema.GroupVersion{Group: "networking.k8s.io", Version: "v1"}, GroupVersionSupported: false},
-		consts.ServiceMonitorType:  {ObjectType: &monitoringv1.ServiceMonitor{}, GroupVersion: monitoringv1.SchemeGroupVersion, GroupVersionSupported: false, TypeWatchDisable: true},
+		consts.ExternalTypeService:     {ObjectType: &corev1.Service{}, GroupVersion: corev1.SchemeGroupVersion, GroupVersionSupported: true},
+		consts.ExternalTypeRoute:       {ObjectType: &routev1.Route{}, GroupVersion: routev1.SchemeGroupVersion, GroupVersionSupported: false},
+		consts.ExternalTypeIngress:     {ObjectType: &ingressv1.Ingress{}, GroupVersion: schema.GroupVersion{Group: "networking.k8s.io", Version: "v1"}, GroupVersionSupported: false},
+		consts.ServiceMonitorType:      {ObjectType: &monitoringv1.ServiceMonitor{}, GroupVersion: monitoringv1.SchemeGroupVersion, GroupVersionSupported: false, TypeWatchDisable: true},
+		consts.GrafanaDashboardType:    {ObjectType: &grafanav1alpha1.GrafanaDashboard{}, GroupVersion: grafanav1alpha1.SchemeGroupVersion, GroupVersionSupported: false, TypeWatchDisable: true},
+		consts.ExternalTypeTLSRoute:    {ObjectType: newGatewayObject(TLSRouteGroupVersion, consts.ExternalTypeTLSRoute), GroupVersion: TLSRouteGroupVersion, GroupVersionSupported: false},
+		consts.PodDisruptionBudgetType: podDisruptionBudgetType(r.kube),
+		consts.NetworkPolicyType:       {ObjectType: &ingressv1.NetworkPolicy{}, GroupVersion: ingressv1.SchemeGroupVersion, GroupVersionSupported: true},
+	}
+
+	operatorNs, err := kube.GetOperatorNamespace()
+	if err != nil {
+		return err
+	}
+	isOperatorConfig := func(o client.Object) bool {
+		return o.GetName() == configMapName && o.GetNamespace() == operatorNs
 	}
 
 	builder := ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&ispnv1.Infinispan{}).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					// Relabel the cluster dashboards when the monitoring key of the operator configuration changes
+					var requests []reconcile.Request
+					ispnList := &ispnv1.InfinispanList{}
+					if err := r.List(context.TODO(), ispnList); err != nil {
+						r.log.Error(err, "failed to list Infinispan CR")
+					}
+					for _, item := range ispnList.Items {
+						if item.IsGrafanaDashboardEnabled() {
+							requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}})
+						}
+					}
+					return requests
+				}),
+		).
 		WithEventFilter(predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
 				switch e.Object.(type) {
 				case *ispnv1.Infinispan:
 					return true
+				case *corev1.ConfigMap:
+					return isOperatorConfig(e.Object)
 				}
 				return false
 			},
@@ -80,6 +119,8 @@ func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				switch e.ObjectNew.(type) {
 				case *ispnv1.Infinispan:
 					return true
+				case *corev1.ConfigMap:
+					return isOperatorConfig(e.ObjectNew)
 				}
 				return false
 			},
@@ -87,6 +128,8 @@ func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				switch e.Object.(type) {
 				case *ispnv1.Infinispan:
 					return false
+				case *corev1.ConfigMap:
+					return isOperatorConfig(e.Object)
 				}
 				return true
 			},
@@ -157,40 +200,77 @@ func (reconciler *ServiceReconciler) Reconcile(ctx context.Context, request reco
 		return reconcile.Result{}, err
 	}
 
+	if err := s.reconcileReadOnlyService(); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if err := s.reconcileResource(computeAdminService(s.infinispan)); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	if err := s.reconcileResource(computeAdminHeadlessService(s.infinispan)); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if err := s.reconcileResource(computePingService(s.infinispan)); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	var externalExposeType = ""
-	if s.infinispan.IsExposed() {
-		switch s.infinispan.GetExposeType() {
-		case ispnv1.ExposeTypeLoadBalancer, ispnv1.ExposeTypeNodePort:
-			if err := s.reconcileResource(computeServiceExternal(s.infinispan)); err != nil {
+	var exposed []client.Object
+	if s.infinispan.IsExposedPerEndpoint() {
+		for _, endpoint := range exposeEndpoints {
+			expose := s.infinispan.GetEndpointExpose(endpoint)
+			if expose == nil {
+				continue
+			}
+			resource, err := s.reconcileExternalExpose(s.infinispan.GetEndpointExternalName(endpoint), exposeEndpointPorts[endpoint], expose)
+			if err != nil {
 				return reconcile.Result{}, err
 			}
-			externalExposeType = consts.ExternalTypeService
-		case ispnv1.ExposeTypeRoute:
-			if reconciler.isTypeSupported(consts.ExternalTypeRoute) {
-				if err := s.reconcileResource(computeRoute(s.infinispan)); err != nil {
-					return reconcile.Result{}, err
-				}
-				externalExposeType = consts.ExternalTypeRoute
-			} else if reconciler.isTypeSupported(consts.ExternalTypeIngress) {
-				if err := s.reconcileResource(computeIngress(s.infinispan)); err != nil {
-					return reconcile.Result{}, err
-				}
-				externalExposeType = consts.ExternalTypeIngress
+			if resource != nil {
+				exposed = append(exposed, resource)
+			}
+		}
+	} else if s.infinispan.IsExposed() {
+		if s.infinispan.GetExposeType() == ispnv1.ExposeTypeGatewayRoute {
+			route := computeGatewayRoute(s.infinispan)
+			if !reconciler.isTypeSupported(route.GetKind()) {
+				return reconcile.Result{}, fmt.Errorf("expose type %s requires the Gateway API %s to be installed", ispnv1.ExposeTypeGatewayRoute, route.GroupVersionKind())
+			}
+			if err := s.reconcileGatewayRoute(route); err != nil {
+				return reconcile.Result{}, err
+			}
+			exposed = append(exposed, route)
+		} else {
+			resource, err := s.reconcileExternalExpose(s.infinispan.GetServiceExternalName(), consts.InfinispanUserPort, exposeSpec(s.infinispan))
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if resource != nil {
+				exposed = append(exposed, resource)
 			}
 		}
 	}
-	if err := s.cleanupExternalExpose(externalExposeType); err != nil {
+	consoleExposed, err := s.reconcileConsoleExpose()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	exposed = append(exposed, consoleExposed...)
+	if err := s.cleanupExternalExpose(exposed...); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	if err := s.reconcilePodDisruptionBudget(); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := s.reconcileNetworkPolicy(); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := s.reconcileGrafanaDashboard(); err != nil {
+		return reconcile.Result{}, err
+	}
 	return s.reconcileServiceMonitor(service)
 }
 
@@ -217,6 +297,9 @@ func (s serviceRequest) reconcileResource(resource client.Object) error {
 			_ = unstructured.SetNestedField(findResource.UnstructuredContent(), spec, "spec")
 			_ = unstructured.SetNestedField(findResource.UnstructuredContent(), metadata["annotations"], "metadata", "annotations")
 			_ = unstructured.SetNestedField(findResource.UnstructuredContent(), metadata["labels"], "metadata", "labels")
+			if resource.GetObjectKind().GroupVersionKind().Kind == consts.ExternalTypeService {
+				setServiceIPFamilies(s.infinispan, findResource, true)
+			}
 		} else {
 			findResourceMetadata := findResource.Object["metadata"].(map[string]interface{})
 			findResourceSpec := findResource.Object["spec"].(map[string]interface{})
@@ -230,6 +313,7 @@ func (s serviceRequest) reconcileResource(resource client.Object) error {
 				_ = unstructured.SetNestedField(findResource.UnstructuredContent(), spec["tls"], "spec", "tls")
 			}
 			if resource.GetObjectKind().GroupVersionKind().Kind == consts.ExternalTypeService {
+				setServiceIPFamilies(s.infinispan, findResource, false)
 				if !reflect.DeepEqual(findResourceSpec["type"], spec["type"]) {
 					_ = unstructured.SetNestedField(findResource.UnstructuredContent(), spec["type"], "spec", "type")
 				}
@@ -240,6 +324,9 @@ func (s serviceRequest) reconcileResource(resource client.Object) error {
 						_ = unstructured.SetNestedField(findResourceSpecPort, specPort["nodePort"], "nodePort")
 						_ = unstructured.SetNestedSlice(findResource.UnstructuredContent(), []interface{}{findResourceSpecPort}, "spec", "ports")
 					}
+				} else if spec["type"] == string(corev1.ServiceTypeClusterIP) && servicePortsChanged(findResourceSpec["ports"], spec["ports"]) {
+					// The ports of the endpoints enabled with the spec
+					_ = unstructured.SetNestedField(findResource.UnstructuredContent(), spec["ports"], "spec", "ports")
 				} else if spec["type"] == string(corev1.ServiceTypeLoadBalancer) {
 					specPort := spec["ports"].([]interface{})[0].(map[string]interface{})
 					findResourceSpecPort := findResourceSpec["ports"].([]interface{})[0].(map[string]interface{})
@@ -247,11 +334,25 @@ func (s serviceRequest) reconcileResource(resource client.Object) error {
 						_ = unstructured.SetNestedField(findResourceSpecPort, specPort["port"], "port")
 						_ = unstructured.SetNestedSlice(findResource.UnstructuredContent(), []interface{}{findResourceSpecPort}, "spec", "ports")
 					}
+					if !reflect.DeepEqual(findResourceSpec["loadBalancerSourceRanges"], spec["loadBalancerSourceRanges"]) {
+						if spec["loadBalancerSourceRanges"] == nil {
+							unstructured.RemoveNestedField(findResource.UnstructuredContent(), "spec", "loadBalancerSourceRanges")
+						} else {
+							_ = unstructured.SetNestedField(findResource.UnstructuredContent(), spec["loadBalancerSourceRanges"], "spec", "loadBalancerSourceRanges")
+						}
+					}
+					if findResourceSpec["loadBalancerIP"] != spec["loadBalancerIP"] {
+						if spec["loadBalancerIP"] == nil {
+							unstructured.RemoveNestedField(findResource.UnstructuredContent(), "spec", "loadBalancerIP")
+						} else {
+							_ = unstructured.SetNestedField(findResource.UnstructuredContent(), spec["loadBalancerIP"], "spec", "loadBalancerIP")
+						}
+					}
 				}
 
 			}
 		}
-
+		ApplyPropagatedMetadata(s.infinispan, findResource)
 		return nil
 	})
 	if err != nil {
@@ -264,24 +365,75 @@ func (s serviceRequest) reconcileResource(resource client.Object) error {
 	return runtime.DefaultUnstructuredConverter.FromUnstructured(findResource.UnstructuredContent(), resource)
 }
 
-func (s serviceRequest) cleanupExternalExpose(excludeKind string) error {
+// servicePortsChanged returns true if the name or the port of the current unstructured Service ports differ from the
+// desired ones, ignoring the fields defaulted by the API server
+func servicePortsChanged(current, desired interface{}) bool {
+	portsOf := func(ports interface{}) map[interface{}]interface{} {
+		names := map[interface{}]interface{}{}
+		list, _ := ports.([]interface{})
+		for _, port := range list {
+			if p, ok := port.(map[string]interface{}); ok {
+				names[p["name"]] = p["port"]
+			}
+		}
+		return names
+	}
+	currentPorts, desiredPorts := portsOf(current), portsOf(desired)
+	if len(currentPorts) != len(desiredPorts) {
+		return true
+	}
+	for name, port := range desiredPorts {
+		if fmt.Sprint(currentPorts[name]) != fmt.Sprint(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileExternalExpose creates the Service, Route or Ingress exposing the port with the given name. No resource is
+// returned when neither Route nor Ingress are supported by the platform
+func (s serviceRequest) reconcileExternalExpose(name string, port int, expose *ispnv1.EndpointExposeSpec) (client.Object, error) {
+	var resource client.Object
+	switch expose.Type {
+	case ispnv1.ExposeTypeLoadBalancer, ispnv1.ExposeTypeNodePort:
+		resource = computeServiceExternal(s.infinispan, name, port, expose)
+	case ispnv1.ExposeTypeRoute:
+		if s.isTypeSupported(consts.ExternalTypeRoute) {
+			resource = computeRoute(s.infinispan, name, port, expose)
+		} else if s.isTypeSupported(consts.ExternalTypeIngress) {
+			resource = computeIngress(s.infinispan, name, port, expose)
+		}
+	}
+	if resource == nil {
+		return nil, nil
+	}
+	return resource, s.reconcileResource(resource)
+}
+
+// cleanupExternalExpose removes the Services, Routes and Ingresses previously created to expose the cluster, except
+// the exposed resources
+func (s serviceRequest) cleanupExternalExpose(exposed ...client.Object) error {
+	keep := map[string]bool{}
+	for _, resource := range exposed {
+		keep[resource.GetObjectKind().GroupVersionKind().Kind+"/"+resource.GetName()] = true
+	}
 	for _, obj := range s.supportedTypes {
-		if obj.GroupVersionSupported && obj.Kind() != excludeKind {
-			switch obj.Kind() {
-			case consts.ExternalTypeService:
-				//TODO DeleteAllOf for Service objects not yet implemented. See https://github.com/kubernetes/kubernetes/issues/68468#issuecomment-419981870
-				serviceList := &corev1.ServiceList{}
-				if err := s.kube.ResourcesList(s.infinispan.Namespace, ExternalServiceLabels(s.infinispan.Name), serviceList, s.ctx); err != nil {
-					return nil
-				}
-				for _, service := range serviceList.Items {
-					if err := s.Client.Delete(s.ctx, &service); err != nil {
-						return nil
-					}
+		if !obj.GroupVersionSupported {
+			continue
+		}
+		switch obj.Kind() {
+		case consts.ExternalTypeService, consts.ExternalTypeIngress, consts.ExternalTypeRoute, consts.ExternalTypeTLSRoute:
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(obj.GroupVersion.WithKind(obj.Kind() + "List"))
+			listOptions := []client.ListOption{client.MatchingLabels(ExternalServiceLabels(s.infinispan.Name)), client.InNamespace(s.infinispan.Namespace)}
+			if err := s.Client.List(s.ctx, list, listOptions...); err != nil {
+				return err
+			}
+			for _, resource := range list.Items {
+				if keep[obj.Kind()+"/"+resource.GetName()] {
+					continue
 				}
-			case consts.ExternalTypeIngress, consts.ExternalTypeRoute:
-				deleteOptions := []client.DeleteAllOfOption{client.MatchingLabels(ExternalServiceLabels(s.infinispan.Name)), client.InNamespace(s.infinispan.Namespace)}
-				if err := s.Client.DeleteAllOf(s.ctx, obj.ObjectType, deleteOptions...); err != nil && !errors.IsNotFound(err) {
+				if err := s.Client.Delete(s.ctx, &resource); err != nil && !errors.IsNotFound(err) {
 					return err
 				}
 			}
@@ -292,17 +444,32 @@ func (s serviceRequest) cleanupExternalExpose(excludeKind string) error {
 
 func (s serviceRequest) reconcileServiceMonitor(service *corev1.Service) (reconcile.Result, error) {
 	if !s.isTypeSupported(consts.ServiceMonitorType) {
+		if monitoring := s.infinispan.Spec.Monitoring; monitoring != nil && monitoring.Enabled != nil && *monitoring.Enabled {
+			s.eventRec.Event(s.infinispan, corev1.EventTypeWarning, EventReasonMonitoringUnavailable, "ServiceMonitor not created, the Prometheus operator CRDs are not installed")
+		}
 		return reconcile.Result{}, nil
 	}
 
 	if s.infinispan.IsServiceMonitorEnabled() {
+		s.checkUserWorkloadMonitoring()
 		secret := &corev1.Secret{}
 		if result, err := kube.LookupResource(s.infinispan.GetAdminSecretName(), s.infinispan.Namespace, secret, s.infinispan, s.Client, s.log, s.eventRec, s.ctx); result != nil {
 			return *result, err
 		}
 
-		serviceMonitor := computeServiceMonitor(s.infinispan)
+		var tlsSecret *corev1.Secret
+		if secretName := s.infinispan.GetMetricsTLSSecretName(); secretName != "" {
+			tlsSecret = &corev1.Secret{}
+			if result, err := kube.LookupResource(secretName, s.infinispan.Namespace, tlsSecret, s.infinispan, s.Client, s.log, s.eventRec, s.ctx); result != nil {
+				return *result, err
+			}
+		}
+
+		serviceMonitor := computeServiceMonitor(s.infinispan, tlsSecret)
+		spec := serviceMonitor.Spec
 		if _, err := controllerutil.CreateOrUpdate(s.ctx, s.Client, serviceMonitor, func() error {
+			// Apply the metrics security changes to existing ServiceMonitors
+			serviceMonitor.Spec = spec
 			creationTimestamp := serviceMonitor.GetCreationTimestamp()
 			if creationTimestamp.IsZero() {
 				return controllerutil.SetControllerReference(service, serviceMonitor, s.scheme)
@@ -312,6 +479,7 @@ func (s serviceRequest) reconcileServiceMonitor(service *corev1.Service) (reconc
 			}
 			// Annotation to force ServiceMonitor update when operator admin password has been changed
 			serviceMonitor.Annotations[SecretHashAnnotation] = hash.HashByte(secret.Data[consts.AdminPasswordKey])
+			ApplyPropagatedMetadata(s.infinispan, serviceMonitor)
 			return nil
 		}); err != nil {
 			return reconcile.Result{}, err
@@ -330,6 +498,21 @@ func (s serviceRequest) reconcileServiceMonitor(service *corev1.Service) (reconc
 	return reconcile.Result{}, nil
 }
 
+// checkUserWorkloadMonitoring warns when the ServiceMonitor isn't scraped, because OpenShift user workload monitoring
+// is disabled
+func (s serviceRequest) checkUserWorkloadMonitoring() {
+	if s.kube.GetServingCertsMode(s.ctx) != "openshift.io" {
+		return
+	}
+	enabled, err := s.kube.IsUserWorkloadMonitoringEnabled(s.ctx)
+	if err != nil {
+		// The operator isn't required to read the cluster monitoring configuration
+		s.reqLogger.Info("Unable to check if user workload monitoring is enabled", "error", err.Error())
+	} else if !enabled {
+		s.eventRec.Event(s.infinispan, corev1.EventTypeWarning, EventReasonUserWorkloadMonitoringDisabled, "The ServiceMonitor is not scraped until user workload monitoring is enabled in the openshift-monitoring/cluster-monitoring-config ConfigMap")
+	}
+}
+
 func setupServiceForEncryption(ispn *ispnv1.Infinispan, service *corev1.Service) {
 	if ispn.IsEncryptionCertFromService() {
 		if strings.Contains(ispn.Spec.Security.EndpointEncryption.CertServiceName, "openshift.io") {
@@ -365,6 +548,12 @@ func computeService(ispn *ispnv1.Infinispan) *corev1.Service {
 			},
 		},
 	}
+	if ispn.IsMemcachedEnabled() {
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{Name: consts.InfinispanMemcachedPortName, Port: consts.InfinispanMemcachedPort})
+	}
+	if ispn.IsRespEnabled() {
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{Name: consts.InfinispanRespPortName, Port: consts.InfinispanRespPort})
+	}
 	// This way CR labels will override operator labels with same name
 	ispn.AddOperatorLabelsForServices(service.Labels)
 	ispn.AddLabelsForServices(service.Labels)
@@ -399,6 +588,20 @@ func computeAdminService(ispn *ispnv1.Infinispan) *corev1.Service {
 	return &service
 }
 
+// computeAdminHeadlessService computes the headless admin service, which lists the pods that are not ready too, so that
+// REST calls can target a specific pod, e.g. to shut it down or to collect its thread dump
+func computeAdminHeadlessService(ispn *ispnv1.Infinispan) *corev1.Service {
+	service := computeAdminService(ispn)
+	service.Name = ispn.GetAdminHeadlessServiceName()
+	service.Labels = LabelsResource(ispn.Name, "infinispan-service-admin-headless")
+	service.Spec.ClusterIP = corev1.ClusterIPNone
+	service.Spec.PublishNotReadyAddresses = true
+	// This way CR labels will override operator labels with same name
+	ispn.AddOperatorLabelsForServices(service.Labels)
+	ispn.AddLabelsForServices(service.Labels)
+	return service
+}
+
 func computePingService(ispn *ispnv1.Infinispan) *corev1.Service {
 	pingService := corev1.Service{
 		TypeMeta: metav1.TypeMeta{
@@ -428,35 +631,35 @@ func computePingService(ispn *ispnv1.Infinispan) *corev1.Service {
 	return &pingService
 }
 
-// computeServiceExternal compute the external service
-func computeServiceExternal(ispn *ispnv1.Infinispan) *corev1.Service {
-	externalServiceType := corev1.ServiceType(ispn.Spec.Expose.Type)
-	exposeConf := ispn.Spec.Expose
-
+// computeServiceExternal compute the external service exposing the port
+func computeServiceExternal(ispn *ispnv1.Infinispan, name string, port int, expose *ispnv1.EndpointExposeSpec) *corev1.Service {
 	metadata := metav1.ObjectMeta{
-		Name:      ispn.GetServiceExternalName(),
+		Name:      name,
 		Namespace: ispn.Namespace,
 		Labels:    ExternalServiceLabels(ispn.Name),
 	}
-	if exposeConf.Annotations != nil && len(exposeConf.Annotations) > 0 {
-		metadata.Annotations = exposeConf.Annotations
+	if expose.Annotations != nil && len(expose.Annotations) > 0 {
+		metadata.Annotations = expose.Annotations
 	}
 
 	exposeSpec := corev1.ServiceSpec{
-		Type:     externalServiceType,
+		Type:     corev1.ServiceType(expose.Type),
 		Selector: ServiceLabels(ispn.Name),
 		Ports: []corev1.ServicePort{
 			{
-				Port:       int32(consts.InfinispanUserPort),
-				TargetPort: intstr.FromInt(consts.InfinispanUserPort),
+				Port:       int32(port),
+				TargetPort: intstr.FromInt(port),
 			},
 		},
 	}
-	if exposeConf.NodePort > 0 && exposeConf.Type == ispnv1.ExposeTypeNodePort {
-		exposeSpec.Ports[0].NodePort = exposeConf.NodePort
+	if expose.NodePort > 0 && expose.Type == ispnv1.ExposeTypeNodePort {
+		exposeSpec.Ports[0].NodePort = expose.NodePort
+	}
+	if expose.Port > 0 && expose.Type == ispnv1.ExposeTypeLoadBalancer {
+		exposeSpec.Ports[0].Port = expose.Port
 	}
-	if exposeConf.Port > 0 && exposeConf.Type == ispnv1.ExposeTypeLoadBalancer {
-		exposeSpec.Ports[0].Port = exposeConf.Port
+	if expose.Type == ispnv1.ExposeTypeLoadBalancer {
+		exposeSpec.LoadBalancerSourceRanges = expose.LoadBalancerSourceRanges
 	}
 
 	externalService := corev1.Service{
@@ -532,28 +735,29 @@ func computeSiteService(ispn *ispnv1.Infinispan) *corev1.Service {
 		ObjectMeta: objectMeta,
 		Spec:       exposeSpec,
 	}
+	applyCrossSiteAddress(ispn, &siteService)
 	// This way CR labels will override operator labels with same name
 	ispn.AddOperatorLabelsForServices(siteService.Labels)
 	ispn.AddLabelsForServices(siteService.Labels)
 	return &siteService
 }
 
-// computeRoute compute the Route object
-func computeRoute(ispn *ispnv1.Infinispan) *routev1.Route {
+// computeRoute compute the Route object exposing the port
+func computeRoute(ispn *ispnv1.Infinispan, name string, port int, expose *ispnv1.EndpointExposeSpec) *routev1.Route {
 	route := routev1.Route{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "route.openshift.io/v1",
 			Kind:       "Route",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      ispn.GetServiceExternalName(),
+			Name:      name,
 			Namespace: ispn.Namespace,
 			Labels:    ExternalServiceLabels(ispn.Name),
 		},
 		Spec: routev1.RouteSpec{
-			Host: ispn.Spec.Expose.Host,
+			Host: expose.Host,
 			Port: &routev1.RoutePort{
-				TargetPort: intstr.FromInt(consts.InfinispanUserPort),
+				TargetPort: intstr.FromInt(port),
 			},
 			To: routev1.RouteTargetReference{
 				Kind: "Service",
@@ -561,8 +765,8 @@ func computeRoute(ispn *ispnv1.Infinispan) *routev1.Route {
 			},
 		},
 	}
-	if ispn.IsEncryptionEnabled() {
-		route.Spec.TLS = &routev1.TLSConfig{Termination: routev1.TLSTerminationPassthrough}
+	if termination := routeTLSTermination(ispn, expose); termination != "" {
+		route.Spec.TLS = &routev1.TLSConfig{Termination: routev1.TLSTerminationType(termination)}
 	}
 
 	// This way CR labels will override operator labels with same name
@@ -571,8 +775,8 @@ func computeRoute(ispn *ispnv1.Infinispan) *routev1.Route {
 	return &route
 }
 
-// computeIngress compute the Ingress object
-func computeIngress(ispn *ispnv1.Infinispan) *ingressv1.Ingress {
+// computeIngress compute the Ingress object exposing the port
+func computeIngress(ispn *ispnv1.Infinispan, name string, port int, expose *ispnv1.EndpointExposeSpec) *ingressv1.Ingress {
 	pathTypePrefix := ingressv1.PathTypePrefix
 	ingress := ingressv1.Ingress{
 		TypeMeta: metav1.TypeMeta{
@@ -580,7 +784,7 @@ func computeIngress(ispn *ispnv1.Infinispan) *ingressv1.Ingress {
 			Kind:       "Ingress",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      ispn.GetServiceExternalName(),
+			Name:      name,
 			Namespace: ispn.Namespace,
 			Labels:    ExternalServiceLabels(ispn.Name),
 		},
@@ -588,7 +792,7 @@ func computeIngress(ispn *ispnv1.Infinispan) *ingressv1.Ingress {
 			TLS: []ingressv1.IngressTLS{},
 			Rules: []ingressv1.IngressRule{
 				{
-					Host: ispn.Spec.Expose.Host,
+					Host: expose.Host,
 					IngressRuleValue: ingressv1.IngressRuleValue{
 						HTTP: &ingressv1.HTTPIngressRuleValue{
 							Paths: []ingressv1.HTTPIngressPath{
@@ -598,14 +802,14 @@ func computeIngress(ispn *ispnv1.Infinispan) *ingressv1.Ingress {
 									Backend: ingressv1.IngressBackend{
 										Service: &ingressv1.IngressServiceBackend{
 											Name: ispn.Name,
-											Port: ingressv1.ServiceBackendPort{Number: consts.InfinispanUserPort},
+											Port: ingressv1.ServiceBackendPort{Number: int32(port)},
 										},
 									}}},
 						}}}}}}
-	if ispn.IsEncryptionEnabled() {
+	if routeTLSTermination(ispn, expose) != "" {
 		ingress.Spec.TLS = []ingressv1.IngressTLS{
 			{
-				Hosts: []string{ispn.Spec.Expose.Host},
+				Hosts: []string{expose.Host},
 			},
 		}
 	}
@@ -615,8 +819,10 @@ func computeIngress(ispn *ispnv1.Infinispan) *ingressv1.Ingress {
 	return &ingress
 }
 
-func computeServiceMonitor(ispn *ispnv1.Infinispan) *monitoringv1.ServiceMonitor {
-	return &monitoringv1.ServiceMonitor{
+// computeServiceMonitor scrapes the metrics with the admin credentials, unless the metrics authentication is disabled,
+// over TLS when a tlsSecret is provided
+func computeServiceMonitor(ispn *ispnv1.Infinispan, tlsSecret *corev1.Secret) *monitoringv1.ServiceMonitor {
+	serviceMonitor := &monitoringv1.ServiceMonitor{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "monitoring.coreos.com/v1",
 			Kind:       "ServiceMonitor",
@@ -658,6 +864,15 @@ func computeServiceMonitor(ispn *ispnv1.Infinispan) *monitoringv1.ServiceMonitor
 			},
 		},
 	}
+	endpoint := &serviceMonitor.Spec.Endpoints[0]
+	if !ispn.IsMetricsAuthenticationEnabled() {
+		endpoint.BasicAuth = nil
+	}
+	if tlsSecret != nil {
+		endpoint.Scheme = "https"
+		endpoint.TLSConfig = metricsTLSConfig(ispn, tlsSecret)
+	}
+	return serviceMonitor
 }
 
 func (reconciler *ServiceReconciler) isTypeSupported(kind string) bool {
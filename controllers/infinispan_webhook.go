@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/version"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-infinispan-org-v1-infinispan,mutating=false,failurePolicy=fail,sideEffects=None,groups=infinispan.org,resources=infinispans,verbs=create;update,versions=v1,name=vinfinispan.kb.io,admissionReviewVersions={v1,v1beta1}
+// +kubebuilder:webhook:path=/mutate-infinispan-org-v1-infinispan,mutating=true,failurePolicy=fail,sideEffects=None,groups=infinispan.org,resources=infinispans,verbs=create;update,versions=v1,name=minfinispan.kb.io,admissionReviewVersions={v1,v1beta1}
+
+const (
+	InfinispanValidatingWebhookPath = "/validate-infinispan-org-v1-infinispan"
+	InfinispanMutatingWebhookPath   = "/mutate-infinispan-org-v1-infinispan"
+)
+
+// InfinispanValidator rejects Infinispan CRs that would fail the preliminary checks of the controller, or that render
+// a server configuration or pod the server can't start with
+type InfinispanValidator struct {
+	decoder *admission.Decoder
+}
+
+// InfinispanDefaulter applies the defaults of the controller to the Infinispan CRs at admission time, so that the
+// stored CRs are explicit and are not modified by the first reconcile of the controller
+type InfinispanDefaulter struct {
+	decoder                 *admission.Decoder
+	serviceMonitorSupported bool
+	servingCertsMode        string
+	podIPFamily             func(context.Context) corev1.IPFamily
+	log                     logr.Logger
+}
+
+// SetupInfinispanWebhook registers the Infinispan validating and mutating webhooks with the webhook server of the
+// Manager
+func SetupInfinispanWebhook(mgr ctrl.Manager) {
+	k := kube.NewKubernetesFromController(mgr)
+	log := ctrl.Log.WithName("webhooks").WithName("Infinispan")
+	serviceMonitorSupported, err := k.IsGroupVersionSupported(monitoringv1.SchemeGroupVersion.String(), monitoringv1.ServiceMonitorsKind)
+	if err != nil {
+		log.Error(err, "Failed to check if ServiceMonitors are supported")
+	}
+	mgr.GetWebhookServer().Register(InfinispanValidatingWebhookPath, &webhook.Admission{Handler: &InfinispanValidator{}})
+	mgr.GetWebhookServer().Register(InfinispanMutatingWebhookPath, &webhook.Admission{Handler: &InfinispanDefaulter{
+		serviceMonitorSupported: serviceMonitorSupported,
+		servingCertsMode:        k.GetServingCertsMode(context.TODO()),
+		podIPFamily:             k.GetPodIPFamily,
+		log:                     log,
+	}})
+}
+
+// defaultInfinispan applies the defaults of the spec, the operator labels and annotations, and the endpoint encryption
+// settings. It's applied by the mutating webhook when enabled, and by the controller otherwise
+func defaultInfinispan(ctx context.Context, infinispan *infinispanv1.Infinispan, serviceMonitorSupported bool, servingCertsMode string, podIPFamily func(context.Context) corev1.IPFamily, log logr.Logger) error {
+	infinispan.ApplyDefaults()
+	if serviceMonitorSupported {
+		infinispan.ApplyMonitoringAnnotation()
+	}
+	if isLegacyDefaultAffinity(infinispan.Spec.Affinity, PodLabels(infinispan.Name)) {
+		// The default affinity is no longer stored in the spec, it's applied to the StatefulSet instead
+		infinispan.Spec.Affinity = nil
+	}
+	err := infinispan.ApplyOperatorLabels()
+	infinispan.ApplyEndpointEncryptionSettings(servingCertsMode, log)
+	if len(infinispan.Spec.IPFamilies) == 0 {
+		infinispan.ApplyIPFamily(podIPFamily(ctx))
+	}
+	return err
+}
+
+// defaultOperandVersion pins the clusters that neither specify a version nor an image to the catalog version of the
+// default image of the operator, so that upgrading the operator doesn't upgrade them implicitly
+func defaultOperandVersion(i *infinispanv1.Infinispan) {
+	if i.Spec.Version != "" || (i.Spec.Image != nil && *i.Spec.Image != "") {
+		return
+	}
+	if operand := version.Operands.ForImage(consts.DefaultImageName); operand != nil {
+		i.Spec.Version = operand.Version
+	}
+}
+
+func (d *InfinispanDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+func (d *InfinispanDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	infinispan := &infinispanv1.Infinispan{}
+	if err := d.decoder.Decode(req, infinispan); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	status := infinispan.Status
+	if err := defaultInfinispan(ctx, infinispan, d.serviceMonitorSupported, d.servingCertsMode, d.podIPFamily, d.log); err != nil {
+		return admission.Denied(err.Error())
+	}
+	defaultOperandVersion(infinispan)
+	// The status is not part of the admitted object
+	infinispan.Status = status
+
+	defaulted, err := json.Marshal(infinispan)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+}
+
+func (v *InfinispanValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *InfinispanValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	infinispan := &infinispanv1.Infinispan{}
+	if err := v.decoder.Decode(req, infinispan); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := validateInfinispanAdmission(infinispan); err != nil {
+		return admission.Denied(err.Error())
+	}
+	if req.Operation == admissionv1.Update {
+		old := &infinispanv1.Infinispan{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := validateStorageResize(old, infinispan); err != nil {
+			return admission.Denied(err.Error())
+		}
+	}
+	return admission.Allowed("")
+}
+
+// validateInfinispanAdmission validates the Infinispan with the defaults the controller applies, and performs a dry-run
+// of the server configuration rendering. Resources that the CR depends on, such as Secrets, are not required to exist
+// yet, so the parts of the configuration depending on them are not rendered
+func validateInfinispanAdmission(i *infinispanv1.Infinispan) error {
+	infinispan := i.DeepCopy()
+	infinispan.ApplyDefaults()
+	if err := validateInfinispan(infinispan); err != nil {
+		return err
+	}
+	if _, err := PodResources(infinispan.Spec.Container); err != nil {
+		return fmt.Errorf("invalid infinispan.spec.container resources: %w", err)
+	}
+	if storage := infinispan.StorageSize(); storage != "" {
+		if _, err := resource.ParseQuantity(storage); err != nil {
+			return fmt.Errorf("invalid infinispan.spec.service.container.storage: %w", err)
+		}
+	}
+	if err := validateJvmOptions(infinispan.Spec.Container.GetExtraJvmOpts()); err != nil {
+		return err
+	}
+
+	yaml, err := computeServerConfig(infinispan, nil).Yaml()
+	if err != nil {
+		return fmt.Errorf("unable to render the server configuration: %w", err)
+	}
+	if _, err := config.FromYaml(yaml); err != nil {
+		return fmt.Errorf("the rendered server configuration is invalid: %w", err)
+	}
+	return nil
+}
+
+// validateJvmOptions verifies that the extra JVM options are options, as the JVM doesn't start otherwise
+func validateJvmOptions(opts string) error {
+	for _, opt := range strings.Fields(opts) {
+		if !strings.HasPrefix(opt, "-") {
+			return fmt.Errorf("infinispan.spec.container JVM option '%s' must start with '-'", opt)
+		}
+	}
+	return nil
+}
+
+// validateStorageResize rejects decreasing the storage of the persistent volumes, as PersistentVolumeClaims can only be
+// expanded
+func validateStorageResize(old, new *infinispanv1.Infinispan) error {
+	if old.IsEphemeralStorage() || new.IsEphemeralStorage() || old.StorageSize() == "" || new.StorageSize() == "" {
+		return nil
+	}
+	oldSize, err := resource.ParseQuantity(old.StorageSize())
+	if err != nil {
+		return nil
+	}
+	newSize, err := resource.ParseQuantity(new.StorageSize())
+	if err != nil {
+		return nil
+	}
+	if newSize.Cmp(oldSize) < 0 {
+		return fmt.Errorf("infinispan.spec.service.container.storage cannot be decreased from %s to %s, persistent volumes can only be expanded", old.StorageSize(), new.StorageSize())
+	}
+	return nil
+}
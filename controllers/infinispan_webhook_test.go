@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/version"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func webhookInfinispan() *ispnv1.Infinispan {
+	return &ispnv1.Infinispan{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "infinispan.org/v1", Kind: "Infinispan"},
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec:       ispnv1.InfinispanSpec{Replicas: 1, Service: ispnv1.InfinispanServiceSpec{Type: ispnv1.ServiceTypeDataGrid}},
+	}
+}
+
+func TestValidateInfinispanAdmission(t *testing.T) {
+	// The CR is validated with the defaults applied by the controller, without modifying it
+	i := webhookInfinispan()
+	assert.NoError(t, validateInfinispanAdmission(i))
+	assert.Empty(t, i.Spec.Container.Memory)
+
+	i = webhookInfinispan()
+	i.Spec.Container.Memory = "1Gb"
+	assert.Error(t, validateInfinispanAdmission(i))
+
+	i = webhookInfinispan()
+	i.Spec.Service.Container = &ispnv1.InfinispanServiceContainerSpec{Storage: pointer.StringPtr("lots")}
+	assert.Error(t, validateInfinispanAdmission(i))
+
+	i = webhookInfinispan()
+	i.Spec.Service.Container = &ispnv1.InfinispanServiceContainerSpec{EphemeralStorage: true, StorageType: ispnv1.StoragePersistent}
+	assert.EqualError(t, validateInfinispanAdmission(i), "infinispan.spec.service.container.ephemeralStorage cannot be combined with storageType=persistent")
+
+	i = webhookInfinispan()
+	i.Spec.Container.ExtraJvmOpts = "-Xmx512m Xss1m"
+	assert.EqualError(t, validateInfinispanAdmission(i), "infinispan.spec.container JVM option 'Xss1m' must start with '-'")
+
+	// The preliminary checks of the controller are applied
+	i = webhookInfinispan()
+	i.Spec.Security.Authorization = &ispnv1.Authorization{Enabled: true, Roles: []ispnv1.AuthorizationRole{{Name: "monitor", Permissions: []string{"PEEK"}}}}
+	assert.EqualError(t, validateInfinispanAdmission(i), "unknown permission 'PEEK' granted to role 'monitor'")
+}
+
+func TestInfinispanValidatorHandle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, ispnv1.AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	assert.NoError(t, err)
+	validator := &InfinispanValidator{}
+	assert.NoError(t, validator.InjectDecoder(decoder))
+
+	request := func(i *ispnv1.Infinispan) admission.Request {
+		raw, err := json.Marshal(i)
+		assert.NoError(t, err)
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	assert.True(t, validator.Handle(context.TODO(), request(webhookInfinispan())).Allowed)
+
+	i := webhookInfinispan()
+	i.Spec.Container.ExtraJvmOpts = "Xmx1g"
+	response := validator.Handle(context.TODO(), request(i))
+	assert.False(t, response.Allowed)
+	assert.Contains(t, string(response.Result.Reason), "Xmx1g")
+
+	// The storage of the persistent volumes cannot be decreased
+	old := webhookInfinispan()
+	old.Spec.Service.Container = &ispnv1.InfinispanServiceContainerSpec{Storage: pointer.StringPtr("2Gi")}
+	i = webhookInfinispan()
+	i.Spec.Service.Container = &ispnv1.InfinispanServiceContainerSpec{Storage: pointer.StringPtr("1Gi")}
+	update := request(i)
+	update.Operation = admissionv1.Update
+	update.OldObject = request(old).Object
+	response = validator.Handle(context.TODO(), update)
+	assert.False(t, response.Allowed)
+	assert.Equal(t, "infinispan.spec.service.container.storage cannot be decreased from 2Gi to 1Gi, persistent volumes can only be expanded", string(response.Result.Reason))
+
+	i.Spec.Service.Container.Storage = pointer.StringPtr("4Gi")
+	update.Object = request(i).Object
+	assert.True(t, validator.Handle(context.TODO(), update).Allowed)
+}
+
+func TestInfinispanDefaulterHandle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, ispnv1.AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	assert.NoError(t, err)
+	defaulter := &InfinispanDefaulter{
+		serviceMonitorSupported: true,
+		podIPFamily:             func(context.Context) corev1.IPFamily { return corev1.IPv4Protocol },
+		log:                     logf.Log,
+	}
+	assert.NoError(t, defaulter.InjectDecoder(decoder))
+
+	patches := func(i *ispnv1.Infinispan) map[string]interface{} {
+		raw, err := json.Marshal(i)
+		assert.NoError(t, err)
+		response := defaulter.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+		assert.True(t, response.Allowed)
+		m := map[string]interface{}{}
+		for _, p := range response.Patches {
+			m[p.Path] = p.Value
+		}
+		return m
+	}
+
+	p := patches(webhookInfinispan())
+	assert.Equal(t, version.Operands.ForImage(consts.DefaultImageName).Version, p["/spec/version"])
+	assert.Equal(t, string(ispnv1.MemoryPolicyManual), p["/spec/container/memoryPolicy"])
+	assert.Equal(t, consts.DefaultMemorySize.String(), p["/spec/container/memory"])
+	assert.Equal(t, map[string]interface{}{"storage": consts.DefaultPVSize.String()}, p["/spec/service/container"])
+	assert.Equal(t, map[string]interface{}{ispnv1.ServiceMonitoringAnnotation: "true"}, p["/metadata/annotations"])
+	assert.Equal(t, []interface{}{string(corev1.IPv4Protocol)}, p["/spec/ipFamilies"])
+	assert.NotContains(t, p, "/status")
+
+	// The explicit values are kept
+	i := webhookInfinispan()
+	i.Spec.Image = pointer.StringPtr("quay.io/infinispan/server:custom")
+	i.Spec.Container.MemoryPolicy = ispnv1.MemoryPolicyAuto
+	i.Annotations = map[string]string{ispnv1.ServiceMonitoringAnnotation: "false"}
+	p = patches(i)
+	assert.NotContains(t, p, "/spec/version")
+	assert.NotContains(t, p, "/spec/container/memoryPolicy")
+	assert.NotContains(t, p, "/metadata/annotations")
+	assert.Equal(t, float64(consts.DefaultMemoryHeadroomPercent), p["/spec/container/memoryHeadroomPercent"])
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"fmt"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// validateIPFamilies verifies that the IP families are distinct and match the IP family policy
+func validateIPFamilies(i *ispnv1.Infinispan) error {
+	families := i.Spec.IPFamilies
+	for idx, family := range families {
+		if family != corev1.IPv4Protocol && family != corev1.IPv6Protocol {
+			return fmt.Errorf("infinispan.spec.ipFamilies[%d] must be %s or %s", idx, corev1.IPv4Protocol, corev1.IPv6Protocol)
+		}
+	}
+	if len(families) == 2 {
+		if families[0] == families[1] {
+			return fmt.Errorf("infinispan.spec.ipFamilies must not contain duplicates")
+		}
+		if i.Spec.IPFamilyPolicy == "" || i.Spec.IPFamilyPolicy == ispnv1.IPFamilyPolicySingleStack {
+			return fmt.Errorf("infinispan.spec.ipFamilies with two families requires ipFamilyPolicy=%s or %s", ispnv1.IPFamilyPolicyPreferDualStack, ispnv1.IPFamilyPolicyRequireDualStack)
+		}
+	}
+	return nil
+}
+
+// setServiceIPFamilies sets the IP family policy and families of a Service. The fields aren't part of the Kubernetes
+// API the operator is built with, so they are set on the unstructured Service. The IP families are only set when the
+// Service is created, as Kubernetes adds the secondary family of dual-stack Services and rejects changes to the primary
+func setServiceIPFamilies(i *ispnv1.Infinispan, service *unstructured.Unstructured, create bool) {
+	if i.Spec.IPFamilyPolicy != "" {
+		_ = unstructured.SetNestedField(service.Object, string(i.Spec.IPFamilyPolicy), "spec", "ipFamilyPolicy")
+	}
+	if create && len(i.Spec.IPFamilies) > 0 {
+		families := make([]interface{}, len(i.Spec.IPFamilies))
+		for idx, family := range i.Spec.IPFamilies {
+			families[idx] = string(family)
+		}
+		_ = unstructured.SetNestedSlice(service.Object, families, "spec", "ipFamilies")
+	}
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func ipFamiliesInfinispan(policy ispnv1.IPFamilyPolicyType, families ...corev1.IPFamily) *ispnv1.Infinispan {
+	return &ispnv1.Infinispan{Spec: ispnv1.InfinispanSpec{IPFamilyPolicy: policy, IPFamilies: families}}
+}
+
+func TestValidateIPFamilies(t *testing.T) {
+	assert.NoError(t, validateIPFamilies(ipFamiliesInfinispan("")))
+	assert.NoError(t, validateIPFamilies(ipFamiliesInfinispan("", corev1.IPv6Protocol)))
+	assert.NoError(t, validateIPFamilies(ipFamiliesInfinispan(ispnv1.IPFamilyPolicyRequireDualStack, corev1.IPv6Protocol, corev1.IPv4Protocol)))
+
+	assert.Error(t, validateIPFamilies(ipFamiliesInfinispan("", "IPv5")))
+	assert.Error(t, validateIPFamilies(ipFamiliesInfinispan(ispnv1.IPFamilyPolicyPreferDualStack, corev1.IPv4Protocol, corev1.IPv4Protocol)))
+	assert.Error(t, validateIPFamilies(ipFamiliesInfinispan("", corev1.IPv4Protocol, corev1.IPv6Protocol)))
+	assert.Error(t, validateIPFamilies(ipFamiliesInfinispan(ispnv1.IPFamilyPolicySingleStack, corev1.IPv4Protocol, corev1.IPv6Protocol)))
+}
+
+func TestSetServiceIPFamilies(t *testing.T) {
+	i := ipFamiliesInfinispan(ispnv1.IPFamilyPolicyPreferDualStack, corev1.IPv6Protocol)
+
+	service := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	setServiceIPFamilies(i, service, true)
+	policy, _, _ := unstructured.NestedString(service.Object, "spec", "ipFamilyPolicy")
+	assert.Equal(t, "PreferDualStack", policy)
+	families, _, _ := unstructured.NestedStringSlice(service.Object, "spec", "ipFamilies")
+	assert.Equal(t, []string{"IPv6"}, families)
+
+	// The families of existing Services are left to Kubernetes
+	service = &unstructured.Unstructured{Object: map[string]interface{}{}}
+	setServiceIPFamilies(i, service, false)
+	_, found, _ := unstructured.NestedStringSlice(service.Object, "spec", "ipFamilies")
+	assert.False(t, found)
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+)
+
+var (
+	// jgroupsPropertyRegex matches the <PROTOCOL>.<property> keys of the JGroups property overrides
+	jgroupsPropertyRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*\.[a-z][a-z0-9_.]*$`)
+	// gossipRouterRegex matches the host[port] address of a Gossip router
+	gossipRouterRegex = regexp.MustCompile(`^[^\[\],\s]+\[[0-9]{1,5}\]$`)
+)
+
+// validateJGroups verifies that the tunnel stack has Gossip routers and that the property overrides reference a
+// protocol property
+func validateJGroups(i *ispnv1.Infinispan) error {
+	jgroups := i.Spec.JGroups
+	if jgroups == nil {
+		return nil
+	}
+	if i.GetJGroupsStack() == ispnv1.JGroupsStackTunnel {
+		if len(jgroups.GossipRouters) == 0 {
+			return fmt.Errorf("infinispan.spec.jgroups.gossipRouters must be provided for stack=%s", ispnv1.JGroupsStackTunnel)
+		}
+	} else if len(jgroups.GossipRouters) > 0 {
+		return fmt.Errorf("infinispan.spec.jgroups.gossipRouters is only supported for stack=%s", ispnv1.JGroupsStackTunnel)
+	}
+	for _, router := range jgroups.GossipRouters {
+		if !gossipRouterRegex.MatchString(router) {
+			return fmt.Errorf("infinispan.spec.jgroups.gossipRouters '%s' must have the host[port] format", router)
+		}
+	}
+	for key := range jgroups.Properties {
+		if !jgroupsPropertyRegex.MatchString(key) {
+			return fmt.Errorf("infinispan.spec.jgroups.properties key '%s' must have the <PROTOCOL>.<property> format", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestJGroupsStack(t *testing.T) {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "namespace"}}
+	assert.NoError(t, validateJGroups(ispn))
+	assert.Equal(t, "tcp", computeServerConfig(ispn, nil).JGroups.Transport)
+
+	ispn.Spec.JGroups = &ispnv1.InfinispanJGroupsSpec{
+		Stack:         ispnv1.JGroupsStackTunnel,
+		GossipRouters: []string{"gossip-router.namespace.svc[12001]"},
+		Properties:    map[string]string{"FD_ALL3.timeout": "60000", "TCP.thread_pool.max_threads": "400"},
+	}
+	assert.NoError(t, validateJGroups(ispn))
+	jgroups := computeServerConfig(ispn, nil).JGroups
+	assert.Equal(t, "tunnel", jgroups.Transport)
+	assert.Equal(t, []string{"gossip-router.namespace.svc[12001]"}, jgroups.GossipRouters)
+	assert.Equal(t, "400", jgroups.Properties["TCP.thread_pool.max_threads"])
+
+	ispn.Spec.JGroups.GossipRouters = []string{"gossip-router:12001"}
+	assert.EqualError(t, validateJGroups(ispn), "infinispan.spec.jgroups.gossipRouters 'gossip-router:12001' must have the host[port] format")
+
+	ispn.Spec.JGroups.GossipRouters = nil
+	assert.EqualError(t, validateJGroups(ispn), "infinispan.spec.jgroups.gossipRouters must be provided for stack=tunnel")
+
+	ispn.Spec.JGroups.Stack = ispnv1.JGroupsStackUDP
+	assert.NoError(t, validateJGroups(ispn))
+	ispn.Spec.JGroups.GossipRouters = []string{"gossip-router[12001]"}
+	assert.Error(t, validateJGroups(ispn))
+
+	ispn.Spec.JGroups = &ispnv1.InfinispanJGroupsSpec{Properties: map[string]string{"timeout": "60000"}}
+	assert.EqualError(t, validateJGroups(ispn), "infinispan.spec.jgroups.properties key 'timeout' must have the <PROTOCOL>.<property> format")
+}
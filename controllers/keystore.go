@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// EncryptKeystoreJKSName is the key of a JKS keystore in the keystore Secret of the endpoints
+	EncryptKeystoreJKSName     = "keystore.jks"
+	EncryptKeystorePasswordKey = "password"
+	EncryptKeystoreAliasKey    = "alias"
+
+	KeystoreTypePKCS12 = "PKCS12"
+	KeystoreTypeJKS    = "JKS"
+)
+
+var (
+	jksMagic = []byte{0xfe, 0xed, 0xfe, 0xed}
+	// pkcs12Version is the DER encoded version of the PFX structure, which follows the header of the outer sequence
+	pkcs12Version = []byte{0x02, 0x01, 0x03}
+)
+
+// keystoreType detects the format of a keystore from its content, as keystores are often stored under a key whose
+// extension doesn't match their format
+func keystoreType(keystore []byte) (string, error) {
+	if bytes.HasPrefix(keystore, jksMagic) {
+		return KeystoreTypeJKS, nil
+	}
+	// A PKCS12 keystore is a DER sequence starting with its version
+	if len(keystore) > 2 && keystore[0] == 0x30 {
+		header := 2
+		if keystore[1]&0x80 != 0 {
+			header += int(keystore[1] & 0x7f)
+		}
+		if len(keystore) >= header+len(pkcs12Version) && bytes.Equal(keystore[header:header+len(pkcs12Version)], pkcs12Version) {
+			return KeystoreTypePKCS12, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported keystore format, the keystore must be a PKCS12 or JKS keystore")
+}
+
+// userKeystore returns the key of the keystore provided in the Secret and the detected type of the keystore, or an
+// empty key if the Secret doesn't hold a keystore. JKS keystores can't be loaded without their password
+func userKeystore(secret *corev1.Secret) (string, string, error) {
+	var key string
+	for _, k := range []string{EncryptKeystoreName, EncryptKeystoreJKSName} {
+		if _, ok := secret.Data[k]; !ok {
+			continue
+		}
+		if key != "" {
+			return "", "", fmt.Errorf("the keystore Secret '%s' must contain either a '%s' or a '%s' key", secret.Name, EncryptKeystoreName, EncryptKeystoreJKSName)
+		}
+		key = k
+	}
+	if key == "" {
+		return "", "", nil
+	}
+	ksType, err := keystoreType(secret.Data[key])
+	if err != nil {
+		return "", "", fmt.Errorf("the keystore '%s' of Secret '%s': %w", key, secret.Name, err)
+	}
+	if ksType == KeystoreTypeJKS && len(secret.Data[EncryptKeystorePasswordKey]) == 0 {
+		return "", "", fmt.Errorf("the JKS keystore of Secret '%s' requires a '%s' key", secret.Name, EncryptKeystorePasswordKey)
+	}
+	return key, ksType, nil
+}
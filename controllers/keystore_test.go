@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	pkcs12Keystore = []byte{0x30, 0x82, 0x0a, 0x1f, 0x02, 0x01, 0x03, 0x30, 0x82}
+	jksKeystore    = []byte{0xfe, 0xed, 0xfe, 0xed, 0x00, 0x00, 0x00, 0x02}
+)
+
+func TestKeystoreType(t *testing.T) {
+	ksType, err := keystoreType(pkcs12Keystore)
+	assert.NoError(t, err)
+	assert.Equal(t, KeystoreTypePKCS12, ksType)
+
+	ksType, err = keystoreType(jksKeystore)
+	assert.NoError(t, err)
+	assert.Equal(t, KeystoreTypeJKS, ksType)
+
+	_, err = keystoreType([]byte("-----BEGIN CERTIFICATE-----"))
+	assert.Error(t, err)
+	_, err = keystoreType([]byte{0x30, 0x82})
+	assert.Error(t, err)
+}
+
+func TestUserKeystore(t *testing.T) {
+	secret := func(data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "keystore"}, Data: data}
+	}
+
+	key, ksType, err := userKeystore(secret(map[string][]byte{corev1.TLSCertKey: []byte("crt"), corev1.TLSPrivateKeyKey: []byte("key")}))
+	assert.NoError(t, err)
+	assert.Empty(t, key)
+
+	// The format is detected from the content, not from the key
+	key, ksType, err = userKeystore(secret(map[string][]byte{EncryptKeystoreName: jksKeystore, EncryptKeystorePasswordKey: []byte("secret")}))
+	assert.NoError(t, err)
+	assert.Equal(t, EncryptKeystoreName, key)
+	assert.Equal(t, KeystoreTypeJKS, ksType)
+
+	key, ksType, err = userKeystore(secret(map[string][]byte{EncryptKeystoreJKSName: pkcs12Keystore}))
+	assert.NoError(t, err)
+	assert.Equal(t, EncryptKeystoreJKSName, key)
+	assert.Equal(t, KeystoreTypePKCS12, ksType)
+
+	_, _, err = userKeystore(secret(map[string][]byte{EncryptKeystoreJKSName: jksKeystore}))
+	assert.EqualError(t, err, "the JKS keystore of Secret 'keystore' requires a 'password' key")
+
+	_, _, err = userKeystore(secret(map[string][]byte{EncryptKeystoreName: pkcs12Keystore, EncryptKeystoreJKSName: jksKeystore}))
+	assert.Error(t, err)
+
+	_, _, err = userKeystore(secret(map[string][]byte{EncryptKeystoreName: []byte("not a keystore")}))
+	assert.Error(t, err)
+}
+
+func TestConfigureServerEncryptionJKS(t *testing.T) {
+	i := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: infinispanv1.InfinispanSpec{Security: infinispanv1.InfinispanSecurity{
+			EndpointEncryption: &infinispanv1.EndpointEncryption{Type: infinispanv1.CertificateSourceTypeSecret, CertSecretName: "keystore"},
+		}},
+	}
+	keystoreSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystore", Namespace: namespace},
+		Data: map[string][]byte{
+			EncryptKeystoreJKSName:     jksKeystore,
+			EncryptKeystorePasswordKey: []byte("secret"),
+			EncryptKeystoreAliasKey:    []byte("server"),
+		},
+	}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewFakeClientWithScheme(scheme, keystoreSecret)
+
+	conf := &config.InfinispanConfiguration{}
+	result, err := ConfigureServerEncryption(i, conf, c, logf.Log, record.NewFakeRecorder(10), context.TODO())
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/encrypt/keystore/keystore.jks", conf.Keystore.Path)
+	assert.Equal(t, KeystoreTypeJKS, conf.Keystore.Type)
+	assert.Equal(t, "secret", conf.Keystore.Password)
+	assert.Equal(t, "server", conf.Keystore.Alias)
+}
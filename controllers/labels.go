@@ -1,5 +1,14 @@
 package controllers
 
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // LabelsResource returns the labels that must me applied to the resource
 func LabelsResource(name, resourceType string) map[string]string {
 	m := map[string]string{"infinispan_cr": name, "clusterName": name}
@@ -30,12 +39,40 @@ func BackupPodLabels(backup, cluster string) map[string]string {
 	return m
 }
 
+// BackupUploadLabels returns the labels of the Job uploading the archive of a Backup to object storage
+func BackupUploadLabels(backup, cluster string) map[string]string {
+	m := LabelsResource(cluster, "infinispan-backup-upload")
+	m["backup_cr"] = backup
+	return m
+}
+
+// BackupEncryptLabels returns the labels of the Job encrypting the archive of a Backup
+func BackupEncryptLabels(backup, cluster string) map[string]string {
+	m := LabelsResource(cluster, "infinispan-backup-encrypt")
+	m["backup_cr"] = backup
+	return m
+}
+
+// CacheLoadJobLabels returns the labels of the Job loading the data file of a CacheLoadJob
+func CacheLoadJobLabels(name, cluster string) map[string]string {
+	m := LabelsResource(cluster, "infinispan-cache-load")
+	m["cache_load_job_cr"] = name
+	return m
+}
+
 func RestorePodLabels(backup, cluster string) map[string]string {
 	m := ServiceLabels(cluster)
 	m["restore_cr"] = backup
 	return m
 }
 
+// BackupScheduleLabels returns the labels to apply to the Backups created for a scheduled Backup
+func BackupScheduleLabels(schedule string) map[string]string {
+	return map[string]string{
+		LabelBackupSchedule: schedule,
+	}
+}
+
 func BatchLabels(name string) map[string]string {
 	return map[string]string{
 		"infinispan_batch": name,
@@ -43,7 +80,98 @@ func BatchLabels(name string) map[string]string {
 	}
 }
 
+// ZeroCapacityPodLabels returns the labels of the zero-capacity pods of the cluster
+func ZeroCapacityPodLabels(name string) map[string]string {
+	m := ServiceLabels(name)
+	m["zero_capacity"] = "true"
+	return m
+}
+
+// DataMigrationPodLabels returns the labels of the pods of the staging cluster of a DataMigration upgrade, which must
+// not match the selectors of the cluster pods and Services
+func DataMigrationPodLabels(name string) map[string]string {
+	return map[string]string{
+		"clusterName": name,
+		"app":         "infinispan-data-migration-pod",
+	}
+}
+
 // GossipRouterPodLabels returns the labels to apply to GossipRouter pod
 func GossipRouterPodLabels(name string) map[string]string {
 	return LabelsResource(name, "infinispan-router-pod")
 }
+
+const (
+	// PropagatedLabelsAnnotation lists the labels of the Infinispan spec.metadata last added to a resource
+	PropagatedLabelsAnnotation = "infinispan.org/propagated-labels"
+	// PropagatedAnnotationsAnnotation lists the annotations of the Infinispan spec.metadata last added to a resource
+	PropagatedAnnotationsAnnotation = "infinispan.org/propagated-annotations"
+)
+
+// ApplyPropagatedMetadata adds the labels and annotations of the Infinispan spec.metadata to a resource created for
+// the cluster, removing the keys added previously that are no longer defined. Keys set by the operator are not
+// overridden. It returns true when the resource metadata changed
+func ApplyPropagatedMetadata(ispn *ispnv1.Infinispan, obj metav1.Object) bool {
+	var labels, annotations map[string]string
+	if ispn.Spec.Metadata != nil {
+		labels = ispn.Spec.Metadata.Labels
+		annotations = ispn.Spec.Metadata.Annotations
+	}
+	objLabels := copyStringMap(obj.GetLabels())
+	objAnnotations := copyStringMap(obj.GetAnnotations())
+
+	propagatedLabels := propagateKeys(objLabels, labels, objAnnotations[PropagatedLabelsAnnotation])
+	propagatedAnnotations := propagateKeys(objAnnotations, annotations, objAnnotations[PropagatedAnnotationsAnnotation])
+	setOrRemove(objAnnotations, PropagatedLabelsAnnotation, propagatedLabels)
+	setOrRemove(objAnnotations, PropagatedAnnotationsAnnotation, propagatedAnnotations)
+
+	if reflect.DeepEqual(emptyToNil(obj.GetLabels()), emptyToNil(objLabels)) && reflect.DeepEqual(emptyToNil(obj.GetAnnotations()), emptyToNil(objAnnotations)) {
+		return false
+	}
+	obj.SetLabels(objLabels)
+	obj.SetAnnotations(objAnnotations)
+	return true
+}
+
+// propagateKeys removes from target the keys previously propagated, then adds the values which don't collide with
+// a key already in target. It returns the sorted, comma separated, list of the propagated keys
+func propagateKeys(target, values map[string]string, previous string) string {
+	if previous != "" {
+		for _, key := range strings.Split(previous, ",") {
+			delete(target, key)
+		}
+	}
+	var keys []string
+	for key, value := range values {
+		if _, exists := target[key]; exists {
+			continue
+		}
+		target[key] = value
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func setOrRemove(m map[string]string, key, value string) {
+	if value == "" {
+		delete(m, key)
+	} else {
+		m[key] = value
+	}
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for key, value := range m {
+		c[key] = value
+	}
+	return c
+}
+
+func emptyToNil(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
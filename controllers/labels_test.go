@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyPropagatedMetadata(t *testing.T) {
+	ispn := &ispnv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan"},
+		Spec: ispnv1.InfinispanSpec{
+			Metadata: &ispnv1.InfinispanMetadataSpec{
+				Labels:      map[string]string{"cost-center": "42", "app": "custom", "team": "cache"},
+				Annotations: map[string]string{"policy": "strict"},
+			},
+		},
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Labels: LabelsResource(ispn.Name, "infinispan-secret-admin-identities")}}
+
+	// Operator labels are not overridden
+	assert.True(t, ApplyPropagatedMetadata(ispn, secret))
+	assert.Equal(t, "infinispan-secret-admin-identities", secret.Labels["app"])
+	assert.Equal(t, "42", secret.Labels["cost-center"])
+	assert.Equal(t, "cache", secret.Labels["team"])
+	assert.Equal(t, "strict", secret.Annotations["policy"])
+	assert.Equal(t, "cost-center,team", secret.Annotations[PropagatedLabelsAnnotation])
+	assert.Equal(t, "policy", secret.Annotations[PropagatedAnnotationsAnnotation])
+	assert.False(t, ApplyPropagatedMetadata(ispn, secret))
+
+	// Keys removed from the spec are removed from the resource
+	ispn.Spec.Metadata.Labels = map[string]string{"cost-center": "43"}
+	ispn.Spec.Metadata.Annotations = nil
+	secret.Annotations["kept"] = "true"
+	assert.True(t, ApplyPropagatedMetadata(ispn, secret))
+	assert.Equal(t, "43", secret.Labels["cost-center"])
+	assert.NotContains(t, secret.Labels, "team")
+	assert.Equal(t, map[string]string{"kept": "true", PropagatedLabelsAnnotation: "cost-center"}, secret.Annotations)
+
+	ispn.Spec.Metadata = nil
+	assert.True(t, ApplyPropagatedMetadata(ispn, secret))
+	assert.Equal(t, LabelsResource(ispn.Name, "infinispan-secret-admin-identities"), secret.Labels)
+	assert.Equal(t, map[string]string{"kept": "true"}, secret.Annotations)
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"github.com/infinispan/infinispan-operator/pkg/ldap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	LdapBindVolumeName = "ldap-bind-volume"
+	LdapCaVolumeName   = "ldap-ca-volume"
+	// RealmCaKey is the key of the CA certificate in the TLS Secrets of the realms
+	RealmCaKey = "ca.crt"
+
+	DefaultLdapUserFilter     = "(uid={0})"
+	DefaultLdapGroupFilter    = "(member={1})"
+	DefaultLdapGroupAttribute = "cn"
+
+	// LdapBindTimeout bounds the connection and the bind verifying the LDAP realm configuration
+	LdapBindTimeout = 10 * time.Second
+)
+
+// ldapBind binds to the LDAP server, replaced by the tests
+var ldapBind = ldap.Bind
+
+// validateLdapRealm verifies that the LDAP realm authenticates the users of the endpoints
+func validateLdapRealm(i *ispnv1.Infinispan) error {
+	realm := i.GetLdapRealm()
+	if realm == nil {
+		return nil
+	}
+	if !i.IsAuthenticationEnabled() {
+		return fmt.Errorf("infinispan.spec.security.realm.ldap requires infinispan.spec.security.endpointAuthentication=true")
+	}
+	if u, err := url.Parse(realm.URL); err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return fmt.Errorf("infinispan.spec.security.realm.ldap.url '%s' must be a ldap:// or ldaps:// URL", realm.URL)
+	}
+	return nil
+}
+
+// configureLdapRealm renders the LDAP realm of the endpoints and verifies that the server can bind to the LDAP server
+// with the bind credentials. A failed bind is reported with the LdapRealmBound condition, without blocking the
+// configuration of the cluster, as the LDAP server could be temporarily unavailable
+func (r configRequest) configureLdapRealm(c *config.InfinispanConfiguration) (*reconcile.Result, error) {
+	i := r.infinispan
+	realm := i.GetLdapRealm()
+	if realm == nil {
+		if err := r.removeInfinispanCondition(ispnv1.ConditionLdapRealmBound); err != nil {
+			return &reconcile.Result{}, err
+		}
+		return nil, nil
+	}
+
+	bindSecret := &corev1.Secret{}
+	if result, err := kube.LookupResource(realm.BindSecretName, i.Namespace, bindSecret, i, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return result, err
+	}
+	for _, key := range []string{corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey} {
+		if _, ok := bindSecret.Data[key]; !ok {
+			return &reconcile.Result{}, fmt.Errorf("the LDAP bind Secret '%s' must contain a '%s' key", bindSecret.Name, key)
+		}
+	}
+
+	ldapRealm := &config.LdapRealm{
+		URL:             realm.URL,
+		Principal:       string(bindSecret.Data[corev1.BasicAuthUsernameKey]),
+		CredentialPath:  consts.ServerLdapRoot + "/" + corev1.BasicAuthPasswordKey,
+		SearchDn:        realm.SearchDN,
+		UserFilter:      consts.GetWithDefault(realm.UserFilter, DefaultLdapUserFilter),
+		SearchRecursive: realm.SearchRecursive,
+	}
+	if realm.GroupSearchDN != "" {
+		ldapRealm.GroupSearchDn = realm.GroupSearchDN
+		ldapRealm.GroupFilter = consts.GetWithDefault(realm.GroupFilter, DefaultLdapGroupFilter)
+		ldapRealm.GroupAttribute = consts.GetWithDefault(realm.GroupAttribute, DefaultLdapGroupAttribute)
+	}
+
+	tlsConfig, result, err := r.realmTLSConfig(realm.TLSSecretName)
+	if result != nil {
+		return result, err
+	}
+	if tlsConfig != nil {
+		ldapRealm.CaPath = consts.ServerLdapCaRoot + "/" + RealmCaKey
+	}
+	c.Endpoints.LdapRealm = ldapRealm
+
+	status, reason, msg := metav1.ConditionTrue, ispnv1.ReasonLdapBound, ""
+	if err := ldapBind(realm.URL, ldapRealm.Principal, string(bindSecret.Data[corev1.BasicAuthPasswordKey]), tlsConfig, LdapBindTimeout); err != nil {
+		status, reason, msg = metav1.ConditionFalse, ispnv1.ReasonLdapBindFailed, fmt.Sprintf("Unable to bind to the LDAP server %s: %s", realm.URL, err.Error())
+		r.eventRec.Event(i, corev1.EventTypeWarning, reason, msg)
+	}
+	if err := r.setInfinispanCondition(ispnv1.ConditionLdapRealmBound, status, reason, msg); err != nil {
+		return &reconcile.Result{}, err
+	}
+	return nil, nil
+}
+
+// AddVolumesForLdapRealm mounts the LDAP bind Secret and the LDAP CA Secret in the server container
+func AddVolumesForLdapRealm(i *ispnv1.Infinispan, spec *corev1.PodSpec) {
+	realm := i.GetLdapRealm()
+	addSecretVolume(realm.BindSecretName, LdapBindVolumeName, consts.ServerLdapRoot, spec)
+	if realm.TLSSecretName != "" {
+		addSecretVolume(realm.TLSSecretName, LdapCaVolumeName, consts.ServerLdapCaRoot, spec)
+	}
+}
+
+// realmTLSConfig returns the TLS configuration verifying the server of a realm with the CA certificate of the Secret,
+// nil when no Secret is specified and the system CAs are used
+func (r configRequest) realmTLSConfig(secretName string) (*tls.Config, *reconcile.Result, error) {
+	return r.caTLSConfig(secretName, RealmCaKey)
+}
+
+// caTLSConfig returns the TLS configuration verifying a server with the CA certificate in the `caKey` of the Secret,
+// nil when no Secret is specified and the system CAs are used
+func (r configRequest) caTLSConfig(secretName, caKey string) (*tls.Config, *reconcile.Result, error) {
+	if secretName == "" {
+		return nil, nil, nil
+	}
+	i := r.infinispan
+	caSecret := &corev1.Secret{}
+	if result, err := kube.LookupResource(secretName, i.Namespace, caSecret, i, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return nil, result, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caSecret.Data[caKey]) {
+		return nil, &reconcile.Result{}, fmt.Errorf("the TLS Secret '%s' must contain a PEM certificate in the '%s' key", caSecret.Name, caKey)
+	}
+	return &tls.Config{RootCAs: pool}, nil, nil
+}
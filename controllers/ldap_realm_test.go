@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"testing"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func ldapRealmInfinispan(realm *ispnv1.LdapRealm) *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing", CreationTimestamp: metav1.Now()}}
+	ispn.Spec.Security.EndpointAuthentication = pointer.BoolPtr(true)
+	ispn.Spec.Security.Realm = &ispnv1.SecurityRealm{Ldap: realm}
+	return ispn
+}
+
+func TestValidateLdapRealm(t *testing.T) {
+	assert.NoError(t, validateLdapRealm(ldapRealmInfinispan(nil)))
+	realm := &ispnv1.LdapRealm{URL: "ldaps://ldap.example.org", BindSecretName: "ldap-bind", SearchDN: "ou=people,dc=example,dc=org"}
+	assert.NoError(t, validateLdapRealm(ldapRealmInfinispan(realm)))
+
+	ispn := ldapRealmInfinispan(realm)
+	ispn.Spec.Security.EndpointAuthentication = pointer.BoolPtr(false)
+	assert.EqualError(t, validateLdapRealm(ispn), "infinispan.spec.security.realm.ldap requires infinispan.spec.security.endpointAuthentication=true")
+
+	realm.URL = "ldap://"
+	assert.EqualError(t, validateLdapRealm(ldapRealmInfinispan(realm)), "infinispan.spec.security.realm.ldap.url 'ldap://' must be a ldap:// or ldaps:// URL")
+}
+
+func TestConfigureLdapRealm(t *testing.T) {
+	bind := ldapBind
+	defer func() { ldapBind = bind }()
+	var bindErr error
+	ldapBind = func(serverURL, dn, password string, tlsConfig *tls.Config, timeout time.Duration) error {
+		assert.Equal(t, "cn=admin,dc=example,dc=org", dn)
+		assert.Equal(t, "secret", password)
+		return bindErr
+	}
+
+	ispn := ldapRealmInfinispan(&ispnv1.LdapRealm{
+		URL:            "ldap://ldap.example.org",
+		BindSecretName: "ldap-bind",
+		SearchDN:       "ou=people,dc=example,dc=org",
+		GroupSearchDN:  "ou=groups,dc=example,dc=org",
+	})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ldap-bind", Namespace: "testing"},
+		Data:       map[string][]byte{corev1.BasicAuthUsernameKey: []byte("cn=admin,dc=example,dc=org"), corev1.BasicAuthPasswordKey: []byte("secret")},
+	}
+	c, scheme := transportEncryptionClient(t, ispn, secret)
+	r := configRequest{
+		ConfigReconciler: &ConfigReconciler{Client: c, scheme: scheme, eventRec: record.NewFakeRecorder(10)},
+		infinispan:       ispn,
+		reqLogger:        ctrl.Log,
+		ctx:              context.TODO(),
+	}
+
+	serverConf := &config.InfinispanConfiguration{}
+	result, err := r.configureLdapRealm(serverConf)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.LdapRealm{
+		URL:            "ldap://ldap.example.org",
+		Principal:      "cn=admin,dc=example,dc=org",
+		CredentialPath: "/etc/security/ldap/password",
+		SearchDn:       "ou=people,dc=example,dc=org",
+		UserFilter:     DefaultLdapUserFilter,
+		GroupSearchDn:  "ou=groups,dc=example,dc=org",
+		GroupFilter:    DefaultLdapGroupFilter,
+		GroupAttribute: DefaultLdapGroupAttribute,
+	}, serverConf.Endpoints.LdapRealm)
+	assert.True(t, ispn.IsConditionTrue(ispnv1.ConditionLdapRealmBound))
+
+	// Binding failures are reported without blocking the configuration
+	bindErr = errors.New("LDAP bind failed with result invalidCredentials (49)")
+	result, err = r.configureLdapRealm(serverConf)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	condition := ispn.GetCondition(ispnv1.ConditionLdapRealmBound)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ispnv1.ReasonLdapBindFailed, condition.Reason)
+	assert.Equal(t, "Unable to bind to the LDAP server ldap://ldap.example.org: LDAP bind failed with result invalidCredentials (49)", condition.Message)
+
+	// The CA must be a PEM certificate
+	ispn.Spec.Security.Realm.Ldap.TLSSecretName = "ldap-bind"
+	result, err = r.configureLdapRealm(serverConf)
+	assert.NotNil(t, result)
+	assert.Error(t, err)
+}
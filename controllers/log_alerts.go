@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	EventReasonServerAlert = "ServerAlert"
+
+	// logAlertMaxLength bounds the log line reported by an alert
+	logAlertMaxLength = 256
+	oomKilledReason   = "OOMKilled"
+)
+
+// logAlert is a kind of server error reported as an alert
+type logAlert struct {
+	reason  string
+	pattern *regexp.Regexp
+	// anyLevel matches lines logged at any level, such as the errors printed by the JVM
+	anyLevel bool
+}
+
+// logAlertMatch is an alert found in the logs of a pod
+type logAlertMatch struct {
+	reason   string
+	message  string
+	priority int
+}
+
+var (
+	// errorLogLine matches the lines logged at the ERROR or FATAL level
+	errorLogLine = regexp.MustCompile(`\b(ERROR|FATAL)\b`)
+	// serverLogAlerts are the alerts always reported, by decreasing priority
+	serverLogAlerts = []logAlert{
+		{reason: infinispanv1.ReasonOutOfMemory, pattern: regexp.MustCompile(`java\.lang\.OutOfMemoryError`), anyLevel: true},
+		{reason: infinispanv1.ReasonSplitBrain, pattern: regexp.MustCompile(`ISPN000314|(?i)split[ -]?brain`)},
+		{reason: infinispanv1.ReasonPersistenceFailure, pattern: regexp.MustCompile(`PersistenceException`)},
+	}
+)
+
+// logAlerts returns the server alerts followed by the alerts of the custom patterns
+func logAlerts(spec *infinispanv1.InfinispanLogAlertsSpec) ([]logAlert, error) {
+	alerts := append([]logAlert{}, serverLogAlerts...)
+	for _, p := range spec.Patterns {
+		pattern, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid infinispan.spec.logging.alerts.patterns '%s': %w", p, err)
+		}
+		alerts = append(alerts, logAlert{reason: infinispanv1.ReasonServerError, pattern: pattern})
+	}
+	return alerts, nil
+}
+
+// validateLogAlerts verifies that the custom patterns of the log alerts are valid regular expressions
+func validateLogAlerts(i *infinispanv1.Infinispan) error {
+	if spec := i.GetLogAlerts(); spec != nil {
+		_, err := logAlerts(spec)
+		return err
+	}
+	return nil
+}
+
+// matchLogAlerts returns the last line of the log matching each alert
+func matchLogAlerts(log string, alerts []logAlert) []logAlertMatch {
+	lines := make([]string, len(alerts))
+	for _, line := range strings.Split(log, "\n") {
+		errorLine := errorLogLine.MatchString(line)
+		for i, alert := range alerts {
+			if (errorLine || alert.anyLevel) && alert.pattern.MatchString(line) {
+				lines[i] = line
+				break
+			}
+		}
+	}
+	var matches []logAlertMatch
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		message := strings.TrimSpace(line)
+		if len(message) > logAlertMaxLength {
+			message = message[:logAlertMaxLength] + "..."
+		}
+		matches = append(matches, logAlertMatch{reason: alerts[i].reason, message: message, priority: i})
+	}
+	return matches
+}
+
+// oomKilled returns true if the Infinispan container of the pod has been killed as out of memory after the given time
+func oomKilled(pod corev1.Pod, since time.Time) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.LastTerminationState.Terminated
+		if status.Name == InfinispanContainer && terminated != nil && terminated.Reason == oomKilledReason && terminated.FinishedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileLogAlerts scans the recent logs of the pods for alerts. The alerts are reported with the ServerAlert
+// condition, which degrades the cluster, and with a Warning event when they first occur
+func (r *infinispanRequest) reconcileLogAlerts(podList *corev1.PodList) error {
+	infinispan := r.infinispan
+	spec := infinispan.GetLogAlerts()
+	if spec == nil {
+		return r.update(func() {
+			infinispan.RemoveCondition(infinispanv1.ConditionServerAlert)
+		})
+	}
+	alerts, err := logAlerts(spec)
+	if err != nil {
+		return err
+	}
+	window := consts.DefaultLogAlertsWindow
+	if spec.WindowSeconds != nil {
+		window = time.Duration(*spec.WindowSeconds) * time.Second
+	}
+	sinceSeconds := int64(window.Seconds())
+
+	var matches []logAlertMatch
+	for _, pod := range podList.Items {
+		if oomKilled(pod, time.Now().Add(-window)) {
+			matches = append(matches, logAlertMatch{reason: infinispanv1.ReasonOutOfMemory, message: pod.Name + ": container killed as out of memory"})
+		}
+		logs, err := r.kubernetes.LogsWithOptions(pod.Name, pod.Namespace, &corev1.PodLogOptions{Container: InfinispanContainer, SinceSeconds: &sinceSeconds}, r.ctx)
+		if err != nil {
+			r.reqLogger.Error(err, "unable to retrieve the logs of the pod", "pod", pod.Name)
+			continue
+		}
+		for _, match := range matchLogAlerts(logs, alerts) {
+			match.message = pod.Name + ": " + match.message
+			matches = append(matches, match)
+		}
+	}
+
+	if len(matches) == 0 {
+		return r.update(func() {
+			infinispan.SetCondition(infinispanv1.ConditionServerAlert, metav1.ConditionFalse, infinispanv1.ReasonNoServerAlert, "")
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].priority < matches[j].priority
+	})
+	previous := infinispan.GetCondition(infinispanv1.ConditionServerAlert).Message
+	messages := make([]string, len(matches))
+	for i, match := range matches {
+		messages[i] = match.message
+		if !strings.Contains(previous, match.message) {
+			r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonServerAlert, match.message)
+		}
+	}
+	return r.update(func() {
+		infinispan.SetCondition(infinispanv1.ConditionServerAlert, metav1.ConditionTrue, matches[0].reason, strings.Join(messages, "\n"))
+	})
+}
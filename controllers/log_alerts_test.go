@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const alertsLog = `2021-10-01 10:00:00,000 INFO  (main) [org.infinispan.SERVER] ISPN080001: Infinispan Server starting
+2021-10-01 10:01:00,000 WARN  (jgroups-5,pod-0) [org.infinispan.CLUSTER] ISPN000314: Lost at least half of the stable members, possible split brain
+2021-10-01 10:02:00,000 ERROR (blocking-thread-1) [org.infinispan.persistence] org.infinispan.persistence.spi.PersistenceException: Connection refused
+2021-10-01 10:03:00,000 ERROR (blocking-thread-2) [org.infinispan.persistence] org.infinispan.persistence.spi.PersistenceException: Timeout
+2021-10-01 10:04:00,000 ERROR (non-blocking-thread-1) [org.infinispan.CUSTOM] Custom failure
+Terminating due to java.lang.OutOfMemoryError: Java heap space`
+
+func TestMatchLogAlerts(t *testing.T) {
+	alerts, err := logAlerts(&infinispanv1.InfinispanLogAlertsSpec{Patterns: []string{"Custom failure"}})
+	assert.NoError(t, err)
+
+	matches := matchLogAlerts(alertsLog, alerts)
+	assert.Equal(t, []logAlertMatch{
+		{reason: infinispanv1.ReasonOutOfMemory, message: "Terminating due to java.lang.OutOfMemoryError: Java heap space", priority: 0},
+		// Only the ERROR and FATAL lines are matched, and the last one of each alert is reported
+		{reason: infinispanv1.ReasonPersistenceFailure, message: "2021-10-01 10:03:00,000 ERROR (blocking-thread-2) [org.infinispan.persistence] org.infinispan.persistence.spi.PersistenceException: Timeout", priority: 2},
+		{reason: infinispanv1.ReasonServerError, message: "2021-10-01 10:04:00,000 ERROR (non-blocking-thread-1) [org.infinispan.CUSTOM] Custom failure", priority: 3},
+	}, matches)
+
+	assert.Empty(t, matchLogAlerts("2021-10-01 10:00:00,000 INFO  (main) [org.infinispan.SERVER] ISPN080001: Infinispan Server starting", alerts))
+}
+
+func TestValidateLogAlerts(t *testing.T) {
+	ispn := &infinispanv1.Infinispan{}
+	assert.NoError(t, validateLogAlerts(ispn))
+	ispn.Spec.Logging = &infinispanv1.InfinispanLoggingSpec{Alerts: &infinispanv1.InfinispanLogAlertsSpec{Patterns: []string{"ISPN0000(01"}}}
+	assert.Error(t, validateLogAlerts(ispn))
+}
+
+func TestOOMKilled(t *testing.T) {
+	finishedAt := time.Now().Add(-time.Minute)
+	pod := corev1.Pod{
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: InfinispanContainer,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Reason: oomKilledReason, FinishedAt: metav1.NewTime(finishedAt)},
+				},
+			}},
+		},
+	}
+	assert.True(t, oomKilled(pod, finishedAt.Add(-time.Minute)))
+	assert.False(t, oomKilled(pod, finishedAt.Add(time.Second)))
+}
+
+func TestServerAlertDegradesCluster(t *testing.T) {
+	ispn := &infinispanv1.Infinispan{}
+	ispn.SetCondition(infinispanv1.ConditionServerAlert, metav1.ConditionTrue, infinispanv1.ReasonOutOfMemory, "pod-0: container killed as out of memory")
+	degraded := ispn.GetCondition(infinispanv1.ConditionDegraded)
+	assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+	assert.Equal(t, infinispanv1.ReasonOutOfMemory, degraded.Reason)
+	assert.Equal(t, "pod-0: container killed as out of memory", degraded.Message)
+
+	ispn.SetCondition(infinispanv1.ConditionServerAlert, metav1.ConditionFalse, infinispanv1.ReasonNoServerAlert, "")
+	assert.Equal(t, metav1.ConditionFalse, ispn.GetCondition(infinispanv1.ConditionDegraded).Status)
+}
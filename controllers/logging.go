@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// ServerConfigHashAnnotation records on the StatefulSet the serverConfigHash of the configuration the pods run with
+	ServerConfigHashAnnotation = "infinispan.org/server-config-hash"
+
+	EventReasonStartupLoggers = "StartupLoggers"
+)
+
+// validateLogging verifies that a single log output format is configured
+func validateLogging(i *infinispanv1.Infinispan) error {
+	if logging := i.Spec.Logging; logging != nil && logging.Pattern != "" && logging.JSON {
+		return fmt.Errorf("infinispan.spec.logging.pattern cannot be combined with infinispan.spec.logging.json")
+	}
+	return nil
+}
+
+// loggingConsole returns the console log output format of the server configuration, nil to keep the server default
+func loggingConsole(i *infinispanv1.Infinispan) *config.LoggingConsole {
+	logging := i.Spec.Logging
+	if logging == nil || (logging.Pattern == "" && !logging.JSON) {
+		return nil
+	}
+	return &config.LoggingConsole{Pattern: logging.Pattern, JSON: logging.JSON}
+}
+
+// serverConfigHash returns the hash of the server configuration whose changes require the pods to be restarted. The
+// log categories are excluded, as configureLoggers applies them to the running pods, whereas the configuration
+// provides them to the pods when they start
+func serverConfigHash(configMap *corev1.ConfigMap) string {
+	serverConfig := configMap.Data[consts.ServerConfigFilename]
+	content := yaml.MapSlice{}
+	if err := yaml.Unmarshal([]byte(serverConfig), &content); err != nil {
+		return hash.HashString(serverConfig)
+	}
+	for i, item := range content {
+		logging, ok := item.Value.(yaml.MapSlice)
+		if item.Key != "logging" || !ok {
+			continue
+		}
+		var withoutCategories yaml.MapSlice
+		for _, loggingItem := range logging {
+			if loggingItem.Key != "categories" {
+				withoutCategories = append(withoutCategories, loggingItem)
+			}
+		}
+		if len(withoutCategories) == len(logging) {
+			return hash.HashString(serverConfig)
+		}
+		content[i].Value = withoutCategories
+		if withoutCategories == nil {
+			content = append(content[:i], content[i+1:]...)
+		}
+		stripped, err := yaml.Marshal(content)
+		if err != nil {
+			return hash.HashString(serverConfig)
+		}
+		return hash.HashString(string(stripped))
+	}
+	return hash.HashString(serverConfig)
+}
+
+// applyServerConfigHash updates CONFIG_HASH, which restarts the pods, when the configuration changed otherwise than by
+// its log categories. A StatefulSet created by a previous operator version holds the hash of the whole configuration
+// in CONFIG_HASH, its pods keep running as long as the configuration is unchanged
+func applyServerConfigHash(statefulSet *appsv1.StatefulSet, configMap *corev1.ConfigMap) bool {
+	configHash := serverConfigHash(configMap)
+	recorded, ok := statefulSet.Annotations[ServerConfigHashAnnotation]
+	if recorded == configHash {
+		return false
+	}
+	env := &statefulSet.Spec.Template.Spec.Containers[0].Env
+	if index := kube.GetEnvVarIndex("CONFIG_HASH", env); ok || index < 0 || (*env)[index].Value != hash.HashString(configMap.Data[consts.ServerConfigFilename]) {
+		updateStatefulSetEnv(statefulSet, "CONFIG_HASH", configHash)
+	}
+	annotations := make(map[string]string, len(statefulSet.Annotations)+1)
+	for key, value := range statefulSet.Annotations {
+		annotations[key] = value
+	}
+	annotations[ServerConfigHashAnnotation] = configHash
+	statefulSet.Annotations = annotations
+	return true
+}
+
+// configureLoggers applies the log categories to the running pods through the REST logging endpoint. It returns the
+// categories that a pod doesn't report at the requested level once applied, which the server only configures when it
+// starts
+func configureLoggers(pods *corev1.PodList, cluster ispn.ClusterInterface, infinispan *infinispanv1.Infinispan) ([]string, error) {
+	if infinispan.Spec.Logging == nil || len(infinispan.Spec.Logging.Categories) == 0 {
+		return nil, nil
+	}
+	startupLoggers := map[string]bool{}
+	for _, pod := range pods.Items {
+		serverLoggers, err := cluster.GetLoggers(pod.Name)
+		if err != nil {
+			return nil, err
+		}
+		var applied []string
+		for category, level := range infinispan.Spec.Logging.Categories {
+			serverLevel, ok := serverLoggers[category]
+			if !(ok && strings.EqualFold(string(level), serverLevel)) {
+				if err := cluster.SetLogger(pod.Name, category, string(level)); err != nil {
+					return nil, err
+				}
+				applied = append(applied, category)
+			}
+		}
+		if len(applied) == 0 {
+			continue
+		}
+		if serverLoggers, err = cluster.GetLoggers(pod.Name); err != nil {
+			return nil, err
+		}
+		for _, category := range applied {
+			if !strings.EqualFold(string(infinispan.Spec.Logging.Categories[category]), serverLoggers[category]) {
+				startupLoggers[category] = true
+			}
+		}
+	}
+	categories := make([]string, 0, len(startupLoggers))
+	for category := range startupLoggers {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+// restartForStartupLoggers restarts the pods with the log categories rendered in the server configuration, when the
+// server doesn't apply some of them at runtime, by setting CONFIG_HASH to the hash of the whole configuration
+func (r *infinispanRequest) restartForStartupLoggers(statefulSet *appsv1.StatefulSet, configMap *corev1.ConfigMap, categories []string) (*ctrl.Result, error) {
+	if !updateStatefulSetEnv(statefulSet, "CONFIG_HASH", hash.HashString(configMap.Data[consts.ServerConfigFilename])) {
+		return nil, nil
+	}
+	r.eventRec.Event(r.infinispan, corev1.EventTypeNormal, EventReasonStartupLoggers, fmt.Sprintf("Restarting the pods to apply the log levels of %s, only configured when the server starts", strings.Join(categories, ", ")))
+	if err := r.Client.Update(r.ctx, statefulSet); err != nil {
+		return &ctrl.Result{}, err
+	}
+	return &ctrl.Result{}, nil
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func loggingInfinispan(logging *infinispanv1.InfinispanLoggingSpec) *infinispanv1.Infinispan {
+	return &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec:       infinispanv1.InfinispanSpec{Logging: logging},
+	}
+}
+
+// loggingConfigMap returns the ConfigMap of the server configuration rendered for the Infinispan
+func loggingConfigMap(t *testing.T, i *infinispanv1.Infinispan) *corev1.ConfigMap {
+	serverConfig, err := computeServerConfig(i, nil).Yaml()
+	assert.NoError(t, err)
+	return &corev1.ConfigMap{Data: map[string]string{consts.ServerConfigFilename: serverConfig}}
+}
+
+func TestValidateLogging(t *testing.T) {
+	assert.NoError(t, validateLogging(loggingInfinispan(nil)))
+	assert.NoError(t, validateLogging(loggingInfinispan(&infinispanv1.InfinispanLoggingSpec{JSON: true})))
+	assert.EqualError(t, validateLogging(loggingInfinispan(&infinispanv1.InfinispanLoggingSpec{Pattern: "%d %p %m%n", JSON: true})),
+		"infinispan.spec.logging.pattern cannot be combined with infinispan.spec.logging.json")
+}
+
+func TestLoggingConsole(t *testing.T) {
+	assert.Nil(t, computeServerConfig(loggingInfinispan(nil), nil).Logging.Console)
+	assert.Nil(t, computeServerConfig(loggingInfinispan(&infinispanv1.InfinispanLoggingSpec{Categories: map[string]infinispanv1.LoggingLevelType{"org.infinispan": "debug"}}), nil).Logging.Console)
+
+	console := computeServerConfig(loggingInfinispan(&infinispanv1.InfinispanLoggingSpec{Pattern: "%d %p %m%n"}), nil).Logging.Console
+	assert.Equal(t, "%d %p %m%n", console.Pattern)
+	assert.True(t, computeServerConfig(loggingInfinispan(&infinispanv1.InfinispanLoggingSpec{JSON: true}), nil).Logging.Console.JSON)
+}
+
+func TestServerConfigHash(t *testing.T) {
+	logging := &infinispanv1.InfinispanLoggingSpec{}
+	infinispan := loggingInfinispan(logging)
+	noCategories := serverConfigHash(loggingConfigMap(t, infinispan))
+
+	// The log categories are applied to the running pods
+	logging.Categories = map[string]infinispanv1.LoggingLevelType{"org.infinispan": "debug"}
+	assert.Equal(t, noCategories, serverConfigHash(loggingConfigMap(t, infinispan)))
+	logging.Categories["org.jgroups"] = "trace"
+	assert.Equal(t, noCategories, serverConfigHash(loggingConfigMap(t, infinispan)))
+
+	// The log output format requires the pods to be restarted
+	logging.JSON = true
+	jsonOutput := serverConfigHash(loggingConfigMap(t, infinispan))
+	assert.NotEqual(t, noCategories, jsonOutput)
+	logging.Categories = nil
+	assert.Equal(t, jsonOutput, serverConfigHash(loggingConfigMap(t, infinispan)))
+
+	infinispan.Spec.Replicas = 1
+	infinispan.Spec.Security.EndpointAuthentication = new(bool)
+	assert.NotEqual(t, jsonOutput, serverConfigHash(loggingConfigMap(t, infinispan)))
+}
+
+// loggersCluster records the levels set on the loggers of the pods
+type loggersCluster struct {
+	ispn.ClusterInterface
+	loggers map[string]map[string]string
+}
+
+func (c *loggersCluster) GetLoggers(podName string) (map[string]string, error) {
+	return c.loggers[podName], nil
+}
+
+func (c *loggersCluster) SetLogger(podName, loggerName, loggerLevel string) error {
+	// The level of the startup logger is ignored at runtime
+	if loggerName != "org.startup" {
+		c.loggers[podName][loggerName] = loggerLevel
+	}
+	return nil
+}
+
+func TestConfigureLoggers(t *testing.T) {
+	pods := &corev1.PodList{Items: []corev1.Pod{dataVolumePod("example-infinispan-0"), dataVolumePod("example-infinispan-1")}}
+	cluster := &loggersCluster{loggers: map[string]map[string]string{
+		"example-infinispan-0": {"org.infinispan": "info"},
+		"example-infinispan-1": {"org.infinispan": "debug"},
+	}}
+	infinispan := loggingInfinispan(&infinispanv1.InfinispanLoggingSpec{Categories: map[string]infinispanv1.LoggingLevelType{"org.infinispan": "debug", "org.jgroups": "trace"}})
+	startupLoggers, err := configureLoggers(pods, cluster, infinispan)
+	assert.NoError(t, err)
+	assert.Empty(t, startupLoggers)
+	for _, loggers := range cluster.loggers {
+		assert.Equal(t, map[string]string{"org.infinispan": "debug", "org.jgroups": "trace"}, loggers)
+	}
+
+	// The levels reported in upper case by the server are already applied
+	cluster.loggers["example-infinispan-0"]["org.infinispan"] = "DEBUG"
+	infinispan.Spec.Logging.Categories["org.startup"] = "debug"
+	startupLoggers, err = configureLoggers(pods, cluster, infinispan)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"org.startup"}, startupLoggers)
+	assert.Equal(t, "DEBUG", cluster.loggers["example-infinispan-0"]["org.infinispan"])
+}
+
+func TestApplyServerConfigHash(t *testing.T) {
+	logging := &infinispanv1.InfinispanLoggingSpec{Categories: map[string]infinispanv1.LoggingLevelType{"org.infinispan": "debug"}}
+	configMap := loggingConfigMap(t, loggingInfinispan(logging))
+	fullHash := hash.HashString(configMap.Data[consts.ServerConfigFilename])
+	statefulSet := func(configHash string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "CONFIG_HASH", Value: configHash}}}}},
+		}}}
+	}
+	configHash := func(s *appsv1.StatefulSet) string {
+		return s.Spec.Template.Spec.Containers[0].Env[0].Value
+	}
+
+	// The pods of a StatefulSet created by a previous operator version keep running with the same configuration
+	previous := statefulSet(fullHash)
+	assert.True(t, applyServerConfigHash(previous, configMap))
+	assert.Equal(t, fullHash, configHash(previous))
+	assert.Equal(t, serverConfigHash(configMap), previous.Annotations[ServerConfigHashAnnotation])
+	assert.False(t, applyServerConfigHash(previous, configMap))
+
+	// Changing the log categories doesn't restart them
+	logging.Categories["org.jgroups"] = "trace"
+	assert.False(t, applyServerConfigHash(previous, loggingConfigMap(t, loggingInfinispan(logging))))
+	assert.Equal(t, fullHash, configHash(previous))
+
+	// Changing the rest of the configuration does
+	logging.JSON = true
+	changed := loggingConfigMap(t, loggingInfinispan(logging))
+	assert.True(t, applyServerConfigHash(previous, changed))
+	assert.Equal(t, serverConfigHash(changed), configHash(previous))
+
+	// The pods of a previous StatefulSet whose configuration changed are restarted
+	outdated := statefulSet(fullHash)
+	assert.True(t, applyServerConfigHash(outdated, changed))
+	assert.Equal(t, serverConfigHash(changed), configHash(outdated))
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// ReconcileHandler is the handler label of the metrics recorded for a whole reconcile loop
+	ReconcileHandler = "reconcile"
+	// infinispanControllerName is the default name given by the controller builder to the Infinispan controller
+	infinispanControllerName = "infinispan"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "infinispan_operator_reconcile_handler_duration_seconds",
+		Help:    "Duration of the reconcile handlers in seconds",
+		Buckets: []float64{0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"controller", "handler", "cluster"})
+
+	reconcileRequeues = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "infinispan_operator_reconcile_handler_requeues_total",
+		Help: "Number of requests requeued by the reconcile handlers",
+	}, []string{"controller", "handler", "cluster"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "infinispan_operator_reconcile_handler_errors_total",
+		Help: "Number of errors returned by the reconcile handlers",
+	}, []string{"controller", "handler", "cluster"})
+)
+
+func init() {
+	// Exposed by the metrics endpoint of the manager
+	metrics.Registry.MustRegister(reconcileDuration, reconcileRequeues, reconcileErrors)
+}
+
+// observeReconcile records the duration of a reconcile handler started at `start`, and whether its result requeues the
+// request or it failed
+func observeReconcile(controller, handler string, cluster types.NamespacedName, start time.Time, result *ctrl.Result, err error) {
+	labels := prometheus.Labels{"controller": controller, "handler": handler, "cluster": cluster.String()}
+	reconcileDuration.With(labels).Observe(time.Since(start).Seconds())
+	if err != nil {
+		reconcileErrors.With(labels).Inc()
+	} else if result != nil && (result.Requeue || result.RequeueAfter > 0) {
+		reconcileRequeues.With(labels).Inc()
+	}
+}
+
+// observeHandler runs a reconcile handler of the Infinispan controller and records its metrics, and its span when the
+// reconcile loop is traced
+func (r *infinispanRequest) observeHandler(handler string, reconcileHandler func() (*ctrl.Result, error)) (*ctrl.Result, error) {
+	start := time.Now()
+	span := r.trace.StartSpan(handler)
+	result, err := reconcileHandler()
+	span.End(err)
+	observeReconcile(infinispanControllerName, handler, r.req.NamespacedName, start, result, err)
+	return result, err
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestObserveReconcile(t *testing.T) {
+	cluster := types.NamespacedName{Namespace: "metrics-namespace", Name: "example-infinispan"}
+	labels := []string{infinispanControllerName, "graceful-shutdown", cluster.String()}
+
+	observeReconcile(infinispanControllerName, "graceful-shutdown", cluster, time.Now(), nil, nil)
+	observeReconcile(infinispanControllerName, "graceful-shutdown", cluster, time.Now(), &ctrl.Result{}, nil)
+	assert.Equal(t, 0.0, testutil.ToFloat64(reconcileRequeues.WithLabelValues(labels...)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(reconcileErrors.WithLabelValues(labels...)))
+
+	observeReconcile(infinispanControllerName, "graceful-shutdown", cluster, time.Now(), &ctrl.Result{Requeue: true}, nil)
+	observeReconcile(infinispanControllerName, "graceful-shutdown", cluster, time.Now(), &ctrl.Result{RequeueAfter: time.Second}, nil)
+	assert.Equal(t, 2.0, testutil.ToFloat64(reconcileRequeues.WithLabelValues(labels...)))
+
+	// Failed handlers are counted as errors only, even though the request is requeued
+	observeReconcile(infinispanControllerName, "graceful-shutdown", cluster, time.Now(), &ctrl.Result{}, errors.New("failure"))
+	assert.Equal(t, 2.0, testutil.ToFloat64(reconcileRequeues.WithLabelValues(labels...)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(reconcileErrors.WithLabelValues(labels...)))
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	MetricsTLSVolumeName = "metrics-tls-volume"
+	MetricsTLSCAKey      = "ca.crt"
+)
+
+// configureMetricsSecurity overrides the authentication and encryption the metrics endpoint inherits from the admin
+// endpoint
+func (r configRequest) configureMetricsSecurity(c *config.InfinispanConfiguration) (*reconcile.Result, error) {
+	i := r.infinispan
+	if !i.HasMetricsSecurity() {
+		return nil, nil
+	}
+
+	c.Endpoints.Metrics = &config.Metrics{Authenticate: i.IsMetricsAuthenticationEnabled()}
+	if secretName := i.GetMetricsTLSSecretName(); secretName != "" {
+		secret := &corev1.Secret{}
+		if result, err := kube.LookupResource(secretName, i.Namespace, secret, i, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+			return result, err
+		}
+		for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			if _, ok := secret.Data[key]; !ok {
+				return &reconcile.Result{}, fmt.Errorf("the metrics TLS Secret '%s' must contain a '%s' key", secret.Name, key)
+			}
+		}
+		c.Endpoints.Metrics.CrtPath = consts.ServerEncryptMetricsRoot
+	}
+	return nil, nil
+}
+
+// AddVolumeForMetricsTLS mounts the metrics TLS Secret in the server container
+func AddVolumeForMetricsTLS(i *ispnv1.Infinispan, spec *corev1.PodSpec) {
+	addSecretVolume(i.GetMetricsTLSSecretName(), MetricsTLSVolumeName, consts.ServerEncryptMetricsRoot, spec)
+}
+
+// metricsTLSConfig returns the ServiceMonitor configuration verifying the certificate of the metrics endpoint. The CA
+// of the Secret is preferred, a self-signed certificate being its own CA
+func metricsTLSConfig(i *ispnv1.Infinispan, secret *corev1.Secret) *monitoringv1.TLSConfig {
+	caKey := corev1.TLSCertKey
+	if _, ok := secret.Data[MetricsTLSCAKey]; ok {
+		caKey = MetricsTLSCAKey
+	}
+	return &monitoringv1.TLSConfig{
+		SafeTLSConfig: monitoringv1.SafeTLSConfig{
+			CA: monitoringv1.SecretOrConfigMap{
+				Secret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+					Key:                  caKey,
+				},
+			},
+			ServerName: strings.Join([]string{i.GetAdminServiceName(), i.Namespace, "svc"}, "."),
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func metricsSecurityInfinispan(security *ispnv1.InfinispanMonitoringSecuritySpec) *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing", UID: "uid"}}
+	ispn.Spec.Monitoring = &ispnv1.InfinispanMonitoringSpec{Security: security}
+	return ispn
+}
+
+func TestComputeServiceMonitorSecurity(t *testing.T) {
+	// The metrics inherit the admin endpoint configuration by default
+	endpoint := computeServiceMonitor(metricsSecurityInfinispan(nil), nil).Spec.Endpoints[0]
+	assert.Equal(t, "http", endpoint.Scheme)
+	assert.Equal(t, "example-infinispan-generated-operator-secret", endpoint.BasicAuth.Username.Name)
+	assert.Nil(t, endpoint.TLSConfig)
+
+	disabled := false
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-tls", Namespace: "testing"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	ispn := metricsSecurityInfinispan(&ispnv1.InfinispanMonitoringSecuritySpec{Authentication: &disabled, TLSSecretName: "metrics-tls"})
+	endpoint = computeServiceMonitor(ispn, secret).Spec.Endpoints[0]
+	assert.Equal(t, "https", endpoint.Scheme)
+	assert.Nil(t, endpoint.BasicAuth)
+	assert.Equal(t, corev1.TLSCertKey, endpoint.TLSConfig.CA.Secret.Key)
+	assert.Equal(t, "example-infinispan-admin.testing.svc", endpoint.TLSConfig.ServerName)
+
+	// The CA of the Secret is preferred to the certificate
+	secret.Data[MetricsTLSCAKey] = []byte("ca")
+	endpoint = computeServiceMonitor(ispn, secret).Spec.Endpoints[0]
+	assert.Equal(t, MetricsTLSCAKey, endpoint.TLSConfig.CA.Secret.Key)
+}
+
+func TestConfigureMetricsSecurity(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-tls", Namespace: "testing"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+	c, scheme := transportEncryptionClient(t, secret)
+	r := configRequest{
+		ConfigReconciler: &ConfigReconciler{Client: c, scheme: scheme, eventRec: record.NewFakeRecorder(10)},
+		infinispan:       metricsSecurityInfinispan(nil),
+		reqLogger:        ctrl.Log,
+		ctx:              context.TODO(),
+	}
+
+	serverConf := &config.InfinispanConfiguration{}
+	result, err := r.configureMetricsSecurity(serverConf)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Nil(t, serverConf.Endpoints.Metrics)
+
+	// The Secret must contain the private key
+	r.infinispan = metricsSecurityInfinispan(&ispnv1.InfinispanMonitoringSecuritySpec{TLSSecretName: "metrics-tls"})
+	result, err = r.configureMetricsSecurity(serverConf)
+	assert.NotNil(t, result)
+	assert.Error(t, err)
+
+	secret.Data[corev1.TLSPrivateKeyKey] = []byte("key")
+	assert.NoError(t, c.Update(context.TODO(), secret))
+	result, err = r.configureMetricsSecurity(serverConf)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.Metrics{Authenticate: true, CrtPath: "/etc/encrypt/metrics"}, serverConf.Endpoints.Metrics)
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// NamespaceNameLabel is the label set by Kubernetes on every namespace with the name of the namespace
+	NamespaceNameLabel = "kubernetes.io/metadata.name"
+	// DefaultPrometheusNamespace is the namespace of the Prometheus instance scraping the ServiceMonitors by default
+	DefaultPrometheusNamespace = "monitoring"
+	// OpenShiftPrometheusNamespace is the namespace of the OpenShift user workload monitoring Prometheus instance
+	OpenShiftPrometheusNamespace = "openshift-user-workload-monitoring"
+)
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;delete;update
+
+// validateNetworkPolicy verifies that the additional sources of the NetworkPolicy select some pods
+func validateNetworkPolicy(i *ispnv1.Infinispan) error {
+	sources := i.Spec.Security.NetworkPolicySources
+	if len(sources) > 0 && !i.IsNetworkPolicyEnabled() {
+		return fmt.Errorf("infinispan.spec.security.networkPolicySources requires infinispan.spec.security.networkPolicy=true")
+	}
+	for idx, source := range sources {
+		if len(source.PodSelector) == 0 && len(source.NamespaceSelector) == 0 {
+			return fmt.Errorf("infinispan.spec.security.networkPolicySources[%d] must define a podSelector or a namespaceSelector", idx)
+		}
+	}
+	return nil
+}
+
+// reconcileNetworkPolicy keeps the NetworkPolicy of the cluster pods in line with the exposed endpoints and the
+// configured sources, removing it when disabled
+func (s serviceRequest) reconcileNetworkPolicy() error {
+	ispn := s.infinispan
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ispn.GetNetworkPolicyName(),
+			Namespace: ispn.Namespace,
+		},
+	}
+	if !ispn.IsNetworkPolicyEnabled() {
+		if err := s.Client.Delete(s.ctx, policy); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	operatorNs, err := kube.GetOperatorNamespace()
+	if err != nil {
+		return fmt.Errorf("unable to determine the operator namespace: %w", err)
+	}
+	prometheusNs := ""
+	if ispn.IsServiceMonitorEnabled() && s.isTypeSupported(consts.ServiceMonitorType) {
+		if ispn.Spec.Monitoring != nil {
+			prometheusNs = ispn.Spec.Monitoring.PrometheusNamespace
+		}
+		if prometheusNs == "" && s.isTypeSupported(consts.ExternalTypeRoute) {
+			prometheusNs = OpenShiftPrometheusNamespace
+		} else if prometheusNs == "" {
+			prometheusNs = DefaultPrometheusNamespace
+		}
+	}
+	_, err = controllerutil.CreateOrUpdate(s.ctx, s.Client, policy, func() error {
+		policy.Labels = LabelsResource(ispn.Name, "infinispan-network-policy")
+		policy.Spec = networkPolicySpec(ispn, operatorNs, prometheusNs)
+		ApplyPropagatedMetadata(ispn, policy)
+		return controllerutil.SetControllerReference(ispn, policy, s.scheme)
+	})
+	return err
+}
+
+// networkPolicySpec returns the ingress traffic allowed to the cluster pods, any other traffic being denied. The pods
+// of the cluster can connect to any port, e.g. JGroups. The operator namespace and the Batch and CacheLoadJob pods can
+// connect to the admin and user endpoints, and the Prometheus namespace, when not empty, to the admin endpoint serving
+// the metrics. The exposed endpoints and the cross-site port can be reached from any source, as the traffic of the
+// Routes, Ingresses and LoadBalancers can't be told apart, and the configured sources can reach the user endpoints
+func networkPolicySpec(ispn *ispnv1.Infinispan, operatorNs, prometheusNs string) networkingv1.NetworkPolicySpec {
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{MatchLabels: ServiceLabels(ispn.Name)},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: ServiceLabels(ispn.Name)}}},
+		}, {
+			From: []networkingv1.NetworkPolicyPeer{
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{NamespaceNameLabel: operatorNs}}},
+				{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "infinispan-batch-pod"}}},
+				{PodSelector: &metav1.LabelSelector{MatchLabels: LabelsResource(ispn.Name, "infinispan-cache-load")}},
+			},
+			Ports: networkPolicyPorts(consts.InfinispanAdminPort, consts.InfinispanUserPort),
+		}},
+	}
+	if prometheusNs != "" {
+		spec.Ingress = append(spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{NamespaceNameLabel: prometheusNs}}}},
+			Ports: networkPolicyPorts(consts.InfinispanAdminPort),
+		})
+	}
+	if ports := networkPolicyExposedPorts(ispn); len(ports) > 0 {
+		spec.Ingress = append(spec.Ingress, networkingv1.NetworkPolicyIngressRule{Ports: networkPolicyPorts(ports...)})
+	}
+	if sources := ispn.Spec.Security.NetworkPolicySources; len(sources) > 0 {
+		rule := networkingv1.NetworkPolicyIngressRule{
+			Ports: networkPolicyPorts(consts.InfinispanUserPort, consts.InfinispanMemcachedPort, consts.InfinispanRespPort),
+		}
+		for _, source := range sources {
+			peer := networkingv1.NetworkPolicyPeer{}
+			if len(source.PodSelector) > 0 {
+				peer.PodSelector = &metav1.LabelSelector{MatchLabels: source.PodSelector}
+			}
+			if len(source.NamespaceSelector) > 0 {
+				peer.NamespaceSelector = &metav1.LabelSelector{MatchLabels: source.NamespaceSelector}
+			}
+			rule.From = append(rule.From, peer)
+		}
+		spec.Ingress = append(spec.Ingress, rule)
+	}
+	return spec
+}
+
+// networkPolicyExposedPorts returns the sorted server ports of the endpoints exposed outside of the Kubernetes cluster,
+// the cross-site port included
+func networkPolicyExposedPorts(ispn *ispnv1.Infinispan) []int {
+	exposed := map[int]bool{}
+	if ispn.IsExposedPerEndpoint() {
+		for _, endpoint := range exposeEndpoints {
+			if ispn.GetEndpointExpose(endpoint) != nil {
+				exposed[exposeEndpointPorts[endpoint]] = true
+			}
+		}
+	} else if ispn.IsExposed() {
+		exposed[consts.InfinispanUserPort] = true
+	}
+	if ispn.HasSites() {
+		exposed[consts.CrossSitePort] = true
+	}
+	if ispn.GetConsoleOIDC() != nil {
+		exposed[consts.ConsoleProxyPort] = true
+	} else if ispn.IsConsoleExposed() {
+		exposed[consts.InfinispanUserPort] = true
+	}
+	ports := make([]int, 0, len(exposed))
+	for port := range exposed {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+func networkPolicyPorts(ports ...int) []networkingv1.NetworkPolicyPort {
+	policyPorts := make([]networkingv1.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		protocol := corev1.ProtocolTCP
+		policyPort := intstr.FromInt(port)
+		policyPorts = append(policyPorts, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &policyPort})
+	}
+	return policyPorts
+}
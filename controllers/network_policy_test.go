@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func networkPolicyInfinispan(sources ...ispnv1.NetworkPolicySource) *ispnv1.Infinispan {
+	return &ispnv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec: ispnv1.InfinispanSpec{
+			Security: ispnv1.InfinispanSecurity{NetworkPolicy: pointer.BoolPtr(true), NetworkPolicySources: sources},
+		},
+	}
+}
+
+func policyPorts(rule networkingv1.NetworkPolicyIngressRule) []int {
+	var ports []int
+	for _, port := range rule.Ports {
+		ports = append(ports, port.Port.IntValue())
+	}
+	return ports
+}
+
+func TestValidateNetworkPolicy(t *testing.T) {
+	assert.NoError(t, validateNetworkPolicy(networkPolicyInfinispan()))
+	assert.NoError(t, validateNetworkPolicy(networkPolicyInfinispan(ispnv1.NetworkPolicySource{NamespaceSelector: map[string]string{"team": "apps"}})))
+
+	ispn := networkPolicyInfinispan(ispnv1.NetworkPolicySource{PodSelector: map[string]string{"app": "client"}})
+	ispn.Spec.Security.NetworkPolicy = nil
+	assert.EqualError(t, validateNetworkPolicy(ispn), "infinispan.spec.security.networkPolicySources requires infinispan.spec.security.networkPolicy=true")
+
+	assert.EqualError(t, validateNetworkPolicy(networkPolicyInfinispan(ispnv1.NetworkPolicySource{})), "infinispan.spec.security.networkPolicySources[0] must define a podSelector or a namespaceSelector")
+}
+
+func TestNetworkPolicySpec(t *testing.T) {
+	spec := networkPolicySpec(networkPolicyInfinispan(), "infinispan-operator", "")
+	assert.Equal(t, ServiceLabels("example-infinispan"), spec.PodSelector.MatchLabels)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, spec.PolicyTypes)
+	assert.Len(t, spec.Ingress, 2)
+	// The pods of the cluster are allowed on any port
+	assert.Equal(t, ServiceLabels("example-infinispan"), spec.Ingress[0].From[0].PodSelector.MatchLabels)
+	assert.Empty(t, spec.Ingress[0].Ports)
+	assert.Equal(t, map[string]string{NamespaceNameLabel: "infinispan-operator"}, spec.Ingress[1].From[0].NamespaceSelector.MatchLabels)
+	assert.Equal(t, []int{consts.InfinispanAdminPort, consts.InfinispanUserPort}, policyPorts(spec.Ingress[1]))
+
+	// Exposed endpoints are allowed from any source
+	ispn := networkPolicyInfinispan(ispnv1.NetworkPolicySource{PodSelector: map[string]string{"app": "client"}, NamespaceSelector: map[string]string{"team": "apps"}})
+	ispn.Spec.Expose = &ispnv1.ExposeSpec{
+		Type:    ispnv1.ExposeTypeLoadBalancer,
+		Console: &ispnv1.ConsoleExposeSpec{OIDC: &ispnv1.ConsoleOIDCSpec{IssuerURL: "https://sso.example.com", ClientSecretName: "console-client"}},
+	}
+	spec = networkPolicySpec(ispn, "infinispan-operator", "")
+	assert.Len(t, spec.Ingress, 4)
+	assert.Empty(t, spec.Ingress[2].From)
+	assert.Equal(t, []int{consts.ConsoleProxyPort, consts.InfinispanUserPort}, policyPorts(spec.Ingress[2]))
+	assert.Equal(t, map[string]string{"app": "client"}, spec.Ingress[3].From[0].PodSelector.MatchLabels)
+	assert.Equal(t, map[string]string{"team": "apps"}, spec.Ingress[3].From[0].NamespaceSelector.MatchLabels)
+	assert.Equal(t, []int{consts.InfinispanUserPort, consts.InfinispanMemcachedPort, consts.InfinispanRespPort}, policyPorts(spec.Ingress[3]))
+}
+
+func TestNetworkPolicySpecSitesAndMonitoring(t *testing.T) {
+	// The remote sites connect to the cross-site port from any source
+	ispn := networkPolicyInfinispan()
+	ispn.Spec.Service = ispnv1.InfinispanServiceSpec{
+		Type:  ispnv1.ServiceTypeDataGrid,
+		Sites: &ispnv1.InfinispanSitesSpec{Locations: []ispnv1.InfinispanSiteLocationSpec{{Name: "NYC"}}},
+	}
+	spec := networkPolicySpec(ispn, "infinispan-operator", "")
+	assert.Len(t, spec.Ingress, 3)
+	assert.Empty(t, spec.Ingress[2].From)
+	assert.Equal(t, []int{consts.CrossSitePort}, policyPorts(spec.Ingress[2]))
+
+	// Prometheus scrapes the metrics of the admin endpoint
+	spec = networkPolicySpec(networkPolicyInfinispan(), "infinispan-operator", "monitoring")
+	assert.Len(t, spec.Ingress, 3)
+	assert.Equal(t, map[string]string{NamespaceNameLabel: "monitoring"}, spec.Ingress[2].From[0].NamespaceSelector.MatchLabels)
+	assert.Equal(t, []int{consts.InfinispanAdminPort}, policyPorts(spec.Ingress[2]))
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	OAuth2ClientVolumeName = "oauth2-client-volume"
+	OAuth2CaVolumeName     = "oauth2-ca-volume"
+	OAuth2ClientIdKey      = "clientId"
+	OAuth2ClientSecretKey  = "clientSecret"
+
+	DefaultOAuth2PrincipalClaim = "preferred_username"
+
+	// OIDCDiscoveryPath is the path of the OpenID Connect discovery document relative to the issuer URL
+	OIDCDiscoveryPath = "/.well-known/openid-configuration"
+	// OIDCDiscoveryTimeout bounds the request retrieving the discovery document of the issuer
+	OIDCDiscoveryTimeout = 10 * time.Second
+)
+
+// oidcDiscovery returns the introspection endpoint of the issuer, replaced by the tests
+var oidcDiscovery = discoverIntrospectionEndpoint
+
+// validateOAuth2Realm verifies that the OAuth2 realm authenticates the clients of the endpoints
+func validateOAuth2Realm(i *ispnv1.Infinispan) error {
+	realm := i.GetOAuth2Realm()
+	if realm == nil {
+		return nil
+	}
+	if !i.IsAuthenticationEnabled() {
+		return fmt.Errorf("infinispan.spec.security.realm.oauth2 requires infinispan.spec.security.endpointAuthentication=true")
+	}
+	if i.GetLdapRealm() != nil {
+		return fmt.Errorf("infinispan.spec.security.realm.oauth2 cannot be combined with infinispan.spec.security.realm.ldap")
+	}
+	urls := []struct{ field, value string }{{"issuerUrl", realm.IssuerURL}, {"introspectionUrl", realm.IntrospectionURL}}
+	for _, f := range urls {
+		if f.value == "" {
+			continue
+		}
+		if u, err := url.Parse(f.value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("infinispan.spec.security.realm.oauth2.%s '%s' must be a http:// or https:// URL", f.field, f.value)
+		}
+	}
+	return nil
+}
+
+// configureOAuth2Realm renders the token realm of the endpoints. The introspection endpoint is discovered from the
+// issuer when not specified, the configuration being postponed until the discovery document can be retrieved
+func (r configRequest) configureOAuth2Realm(c *config.InfinispanConfiguration) (*reconcile.Result, error) {
+	i := r.infinispan
+	realm := i.GetOAuth2Realm()
+	if realm == nil {
+		if err := r.removeInfinispanCondition(ispnv1.ConditionOAuth2RealmReady); err != nil {
+			return &reconcile.Result{}, err
+		}
+		return nil, nil
+	}
+
+	clientSecret := &corev1.Secret{}
+	if result, err := kube.LookupResource(realm.ClientSecretName, i.Namespace, clientSecret, i, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return result, err
+	}
+	for _, key := range []string{OAuth2ClientIdKey, OAuth2ClientSecretKey} {
+		if _, ok := clientSecret.Data[key]; !ok {
+			return &reconcile.Result{}, fmt.Errorf("the OAuth2 client Secret '%s' must contain a '%s' key", clientSecret.Name, key)
+		}
+	}
+
+	tlsConfig, result, err := r.realmTLSConfig(realm.TLSSecretName)
+	if result != nil {
+		return result, err
+	}
+
+	introspectionURL := realm.IntrospectionURL
+	if introspectionURL == "" {
+		if introspectionURL, err = oidcDiscovery(realm.IssuerURL, tlsConfig); err != nil {
+			msg := fmt.Sprintf("Unable to discover the introspection endpoint of the issuer %s: %s", realm.IssuerURL, err.Error())
+			r.eventRec.Event(i, corev1.EventTypeWarning, ispnv1.ReasonOAuth2DiscoveryFailed, msg)
+			if err := r.setInfinispanCondition(ispnv1.ConditionOAuth2RealmReady, metav1.ConditionFalse, ispnv1.ReasonOAuth2DiscoveryFailed, msg); err != nil {
+				return &reconcile.Result{}, err
+			}
+			return &reconcile.Result{RequeueAfter: consts.DefaultWaitOnCreateResource}, nil
+		}
+	}
+
+	c.Endpoints.TokenRealm = &config.TokenRealm{
+		AuthServerURL:    realm.IssuerURL,
+		ClientID:         string(clientSecret.Data[OAuth2ClientIdKey]),
+		ClientSecretPath: consts.ServerOAuth2Root + "/" + OAuth2ClientSecretKey,
+		IntrospectionURL: introspectionURL,
+		PrincipalClaim:   consts.GetWithDefault(realm.PrincipalClaim, DefaultOAuth2PrincipalClaim),
+		Audience:         realm.Audience,
+	}
+	if tlsConfig != nil {
+		c.Endpoints.TokenRealm.CaPath = consts.ServerOAuth2CaRoot + "/" + RealmCaKey
+	}
+	if err := r.setInfinispanCondition(ispnv1.ConditionOAuth2RealmReady, metav1.ConditionTrue, ispnv1.ReasonOAuth2RealmConfigured, ""); err != nil {
+		return &reconcile.Result{}, err
+	}
+	return nil, nil
+}
+
+// discoverIntrospectionEndpoint retrieves the introspection endpoint from the OpenID Connect discovery document of
+// the issuer
+func discoverIntrospectionEndpoint(issuerURL string, tlsConfig *tls.Config) (string, error) {
+	client := &http.Client{
+		Timeout:   OIDCDiscoveryTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	rsp, err := client.Get(strings.TrimSuffix(issuerURL, "/") + OIDCDiscoveryPath)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status %d", rsp.StatusCode)
+	}
+	discovery := struct {
+		Issuer                string `json:"issuer"`
+		IntrospectionEndpoint string `json:"introspection_endpoint"`
+	}{}
+	if err := json.NewDecoder(rsp.Body).Decode(&discovery); err != nil {
+		return "", fmt.Errorf("invalid discovery document: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuerURL, "/") {
+		return "", fmt.Errorf("the discovery document is issued by '%s'", discovery.Issuer)
+	}
+	if discovery.IntrospectionEndpoint == "" {
+		return "", fmt.Errorf("the issuer doesn't advertise an introspection endpoint, specify the introspectionUrl")
+	}
+	return discovery.IntrospectionEndpoint, nil
+}
+
+// AddVolumesForOAuth2Realm mounts the OAuth2 client Secret and the CA Secret of the identity provider in the server
+// container
+func AddVolumesForOAuth2Realm(i *ispnv1.Infinispan, spec *corev1.PodSpec) {
+	realm := i.GetOAuth2Realm()
+	addSecretVolume(realm.ClientSecretName, OAuth2ClientVolumeName, consts.ServerOAuth2Root, spec)
+	if realm.TLSSecretName != "" {
+		addSecretVolume(realm.TLSSecretName, OAuth2CaVolumeName, consts.ServerOAuth2CaRoot, spec)
+	}
+}
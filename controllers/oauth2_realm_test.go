@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func oauth2RealmInfinispan(realm *ispnv1.OAuth2Realm) *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing", CreationTimestamp: metav1.Now()}}
+	ispn.Spec.Security.EndpointAuthentication = pointer.BoolPtr(true)
+	ispn.Spec.Security.Realm = &ispnv1.SecurityRealm{OAuth2: realm}
+	return ispn
+}
+
+func TestValidateOAuth2Realm(t *testing.T) {
+	assert.NoError(t, validateOAuth2Realm(oauth2RealmInfinispan(nil)))
+	realm := &ispnv1.OAuth2Realm{IssuerURL: "https://keycloak.example.org/realms/infinispan", ClientSecretName: "oauth2-client"}
+	assert.NoError(t, validateOAuth2Realm(oauth2RealmInfinispan(realm)))
+
+	ispn := oauth2RealmInfinispan(realm)
+	ispn.Spec.Security.EndpointAuthentication = pointer.BoolPtr(false)
+	assert.EqualError(t, validateOAuth2Realm(ispn), "infinispan.spec.security.realm.oauth2 requires infinispan.spec.security.endpointAuthentication=true")
+
+	ispn = oauth2RealmInfinispan(realm)
+	ispn.Spec.Security.Realm.Ldap = &ispnv1.LdapRealm{URL: "ldap://ldap.example.org"}
+	assert.EqualError(t, validateOAuth2Realm(ispn), "infinispan.spec.security.realm.oauth2 cannot be combined with infinispan.spec.security.realm.ldap")
+
+	realm.IntrospectionURL = "keycloak/introspect"
+	assert.EqualError(t, validateOAuth2Realm(oauth2RealmInfinispan(realm)), "infinispan.spec.security.realm.oauth2.introspectionUrl 'keycloak/introspect' must be a http:// or https:// URL")
+}
+
+func TestConfigureOAuth2Realm(t *testing.T) {
+	discovery := oidcDiscovery
+	defer func() { oidcDiscovery = discovery }()
+	var discoveryErr error
+	oidcDiscovery = func(issuerURL string, tlsConfig *tls.Config) (string, error) {
+		return issuerURL + "/protocol/openid-connect/token/introspect", discoveryErr
+	}
+
+	ispn := oauth2RealmInfinispan(&ispnv1.OAuth2Realm{
+		IssuerURL:        "https://keycloak.example.org/realms/infinispan",
+		ClientSecretName: "oauth2-client",
+		Audience:         "infinispan",
+	})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "oauth2-client", Namespace: "testing"},
+		Data:       map[string][]byte{OAuth2ClientIdKey: []byte("infinispan-server"), OAuth2ClientSecretKey: []byte("secret")},
+	}
+	c, scheme := transportEncryptionClient(t, ispn, secret)
+	r := configRequest{
+		ConfigReconciler: &ConfigReconciler{Client: c, scheme: scheme, eventRec: record.NewFakeRecorder(10)},
+		infinispan:       ispn,
+		reqLogger:        ctrl.Log,
+		ctx:              context.TODO(),
+	}
+
+	serverConf := &config.InfinispanConfiguration{}
+	result, err := r.configureOAuth2Realm(serverConf)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.TokenRealm{
+		AuthServerURL:    "https://keycloak.example.org/realms/infinispan",
+		ClientID:         "infinispan-server",
+		ClientSecretPath: "/etc/security/oauth2/clientSecret",
+		IntrospectionURL: "https://keycloak.example.org/realms/infinispan/protocol/openid-connect/token/introspect",
+		PrincipalClaim:   DefaultOAuth2PrincipalClaim,
+		Audience:         "infinispan",
+	}, serverConf.Endpoints.TokenRealm)
+	assert.True(t, ispn.IsConditionTrue(ispnv1.ConditionOAuth2RealmReady))
+
+	// The configuration is postponed until the introspection endpoint is discovered
+	discoveryErr = errors.New("connection refused")
+	serverConf = &config.InfinispanConfiguration{}
+	result, err = r.configureOAuth2Realm(serverConf)
+	assert.NoError(t, err)
+	assert.Equal(t, consts.DefaultWaitOnCreateResource, result.RequeueAfter)
+	assert.Nil(t, serverConf.Endpoints.TokenRealm)
+	condition := ispn.GetCondition(ispnv1.ConditionOAuth2RealmReady)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ispnv1.ReasonOAuth2DiscoveryFailed, condition.Reason)
+
+	// The discovery is skipped when the introspection endpoint is specified
+	ispn.Spec.Security.Realm.OAuth2.IntrospectionURL = "https://keycloak.example.org/introspect"
+	result, err = r.configureOAuth2Realm(serverConf)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://keycloak.example.org/introspect", serverConf.Endpoints.TokenRealm.IntrospectionURL)
+	assert.True(t, ispn.IsConditionTrue(ispnv1.ConditionOAuth2RealmReady))
+
+	// The client Secret must contain the client credentials
+	delete(secret.Data, OAuth2ClientSecretKey)
+	assert.NoError(t, c.Update(context.TODO(), secret))
+	result, err = r.configureOAuth2Realm(serverConf)
+	assert.NotNil(t, result)
+	assert.EqualError(t, err, "the OAuth2 client Secret 'oauth2-client' must contain a 'clientSecret' key")
+}
+
+func TestDiscoverIntrospectionEndpoint(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != OIDCDiscoveryPath {
+			http.NotFound(w, req)
+			return
+		}
+		fmt.Fprintf(w, `{"issuer":"%s","introspection_endpoint":"%s/introspect"}`, server.URL, server.URL)
+	}))
+	defer server.Close()
+
+	endpoint, err := discoverIntrospectionEndpoint(server.URL+"/", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/introspect", endpoint)
+
+	_, err = discoverIntrospectionEndpoint(server.URL+"/realms/other", nil)
+	assert.EqualError(t, err, "unexpected response status 404")
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"fmt"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/version"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// validateOperandVersion verifies that spec.version is part of the operand catalog, that the cluster can be upgraded
+// to it from the version that the pods run and that the server supports the features of the spec
+func validateOperandVersion(i *infinispanv1.Infinispan) error {
+	if i.Spec.Version == "" {
+		return nil
+	}
+	if i.Spec.Image != nil && *i.Spec.Image != "" {
+		return fmt.Errorf("infinispan.spec.version cannot be combined with infinispan.spec.image")
+	}
+	operand, err := version.Operands.Get(i.Spec.Version)
+	if err != nil {
+		return fmt.Errorf("infinispan.spec.version: %w", err)
+	}
+	if i.Status.Version != "" {
+		if err := version.Operands.ValidateUpgrade(i.Status.Version, i.Spec.Version); err != nil {
+			return fmt.Errorf("infinispan.spec.version: %w", err)
+		}
+	}
+
+	required := []struct {
+		capability string
+		enabled    bool
+		feature    string
+	}{
+		{version.CapabilityMemcached, i.IsMemcachedEnabled(), "the memcached endpoint"},
+		{version.CapabilityResp, i.IsRespEnabled(), "the RESP endpoint"},
+		{version.CapabilityCanaryUpgrade, i.IsCanaryUpgrade(), "canary upgrades"},
+	}
+	for _, r := range required {
+		if r.enabled && !operand.HasCapability(r.capability) {
+			return fmt.Errorf("infinispan.spec.version '%s' does not support %s", operand.Version, r.feature)
+		}
+	}
+	return nil
+}
+
+// reconcileOperandVersion records the catalog version of the image that the ready pods run in the status
+func (r *infinispanRequest) reconcileOperandVersion(podList *corev1.PodList) error {
+	if len(podList.Items) == 0 || !kube.AreAllPodsReady(podList) {
+		return nil
+	}
+	operand := version.Operands.ForImage(kube.GetPodDefaultImage(podList.Items[0].Spec.Containers[0]))
+	if operand == nil || operand.Version == r.infinispan.Status.Version {
+		return nil
+	}
+	return r.update(func() {
+		r.infinispan.Status.Version = operand.Version
+	})
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/version"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateOperandVersion(t *testing.T) {
+	operands := version.Operands
+	defer func() { version.Operands = operands }()
+	version.Operands = version.Catalog{
+		{Version: "13.0.2", Image: "quay.io/infinispan/server:13.0.2", Capabilities: []string{}},
+		{Version: "14.0.1", Image: "quay.io/infinispan/server:14.0.1", MinUpgradeVersion: "13.0.0"},
+	}
+
+	ispn := &ispnv1.Infinispan{}
+	assert.NoError(t, validateOperandVersion(ispn))
+
+	ispn.Spec.Version = "13.0.2"
+	assert.NoError(t, validateOperandVersion(ispn))
+	assert.Equal(t, "quay.io/infinispan/server:13.0.2", ispn.ImageName())
+
+	ispn.Spec.Image = pointer.StringPtr("quay.io/infinispan/server:13.0")
+	assert.EqualError(t, validateOperandVersion(ispn), "infinispan.spec.version cannot be combined with infinispan.spec.image")
+	ispn.Spec.Image = nil
+
+	ispn.Spec.Version = "12.1.7"
+	assert.Error(t, validateOperandVersion(ispn))
+
+	// The servers can't be downgraded
+	ispn.Spec.Version = "13.0.2"
+	ispn.Status.Version = "14.0.1"
+	assert.EqualError(t, validateOperandVersion(ispn), "infinispan.spec.version: downgrading from version '14.0.1' to '13.0.2' is not supported")
+
+	ispn.Spec.Version = "14.0.1"
+	ispn.Status.Version = "13.0.2"
+	assert.NoError(t, validateOperandVersion(ispn))
+	assert.Equal(t, "quay.io/infinispan/server:14.0.1", ispn.DefaultImageName())
+
+	// The features of the spec must be supported by the version
+	ispn.Spec.Version = "13.0.2"
+	ispn.Spec.Endpoints = &ispnv1.InfinispanEndpointsSpec{Memcached: true}
+	assert.EqualError(t, validateOperandVersion(ispn), "infinispan.spec.version '13.0.2' does not support the memcached endpoint")
+}
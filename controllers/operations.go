@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+const (
+	// OperationWorkers is the number of long-running server operations executed concurrently across all the clusters
+	OperationWorkers = 4
+
+	EventReasonOperationStarted   = "OperationStarted"
+	EventReasonOperationSucceeded = "OperationSucceeded"
+	EventReasonOperationFailed    = "OperationFailed"
+)
+
+// operationQueue executes the long-running server operations of the clusters in the background, so that the reconcile
+// loop doesn't stall the other clusters while a server completes an operation. The completion of an operation is sent
+// to the completed channel, which is watched by the controller to reconcile the cluster right away
+type operationQueue struct {
+	mutex     sync.Mutex
+	workers   chan struct{}
+	tasks     map[string]*operationTask
+	completed chan event.GenericEvent
+	// The clusters whose operation waits for a free worker, reconciled again once a worker is released
+	waiting map[types.NamespacedName]bool
+	log     logr.Logger
+	// newCluster creates the cluster client of a task
+	newCluster func(i *infinispanv1.Infinispan) (ispn.ClusterInterface, error)
+}
+
+// operationTask is the state of an operation in the queue
+type operationTask struct {
+	done   bool
+	err    error
+	events []operationEvent
+}
+
+// operationEvent is an event of an operation, recorded on the cluster once the result of the operation is recorded
+type operationEvent struct {
+	eventType string
+	reason    string
+	message   string
+}
+
+// operation is given to the task of an operation. The task is executed once the reconcile that submitted it has
+// completed, so it only uses the immutable inputs it captured and the logger and cluster client created for it
+type operation struct {
+	log     logr.Logger
+	cluster ispn.ClusterInterface
+	events  []operationEvent
+}
+
+// operationFunc is the task of an operation
+type operationFunc func(op *operation) error
+
+// event records an event of the operation
+func (op *operation) event(eventType, reason, message string) {
+	op.events = append(op.events, operationEvent{eventType: eventType, reason: reason, message: message})
+}
+
+func newOperationQueue(workers int, log logr.Logger, newCluster func(i *infinispanv1.Infinispan) (ispn.ClusterInterface, error)) *operationQueue {
+	return &operationQueue{
+		workers:    make(chan struct{}, workers),
+		tasks:      map[string]*operationTask{},
+		completed:  make(chan event.GenericEvent, 100),
+		waiting:    map[types.NamespacedName]bool{},
+		log:        log,
+		newCluster: newCluster,
+	}
+}
+
+func operationKey(i *infinispanv1.Infinispan, operationType infinispanv1.OperationType, id string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", i.Namespace, i.Name, operationType, id)
+}
+
+// submit starts the task of the operation, unless it's already queued. The worker is acquired before the task is
+// started, false is returned when all the workers are busy
+func (q *operationQueue) submit(key string, i *infinispanv1.Infinispan, task operationFunc) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if _, ok := q.tasks[key]; ok {
+		return true
+	}
+	name := types.NamespacedName{Namespace: i.Namespace, Name: i.Name}
+	select {
+	case q.workers <- struct{}{}:
+	default:
+		q.waiting[name] = true
+		return false
+	}
+	q.tasks[key] = &operationTask{}
+	// The task gets a copy of the cluster, which is modified by the reconcile loop
+	snapshot := i.DeepCopy()
+	op := &operation{log: q.log.WithValues("Request.Namespace", i.Namespace, "Request.Name", i.Name, "operation", key)}
+	go func() {
+		cluster, err := q.newCluster(snapshot)
+		if err == nil {
+			op.cluster = cluster
+			err = task(op)
+		}
+		<-q.workers
+
+		q.mutex.Lock()
+		q.tasks[key] = &operationTask{done: true, err: err, events: op.events}
+		waiting := q.waiting
+		q.waiting = map[types.NamespacedName]bool{}
+		q.mutex.Unlock()
+		q.notify(name)
+		for waitingName := range waiting {
+			q.notify(waitingName)
+		}
+	}()
+	return true
+}
+
+// notify sends the completed event of the cluster
+func (q *operationQueue) notify(name types.NamespacedName) {
+	// The completed event only references the cluster
+	cluster := &infinispanv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace}}
+	select {
+	case q.completed <- event.GenericEvent{Object: cluster}:
+	default:
+		// The cluster is reconciled by its next requeue
+	}
+}
+
+// result returns the state of the operation, false if the operation isn't in the queue
+func (q *operationQueue) result(key string) (operationTask, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	task, ok := q.tasks[key]
+	if !ok {
+		return operationTask{}, false
+	}
+	return *task, true
+}
+
+// forget removes a completed operation from the queue, once its result is recorded in the status of the cluster
+func (q *operationQueue) forget(key string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.tasks, key)
+}
+
+// runOperation executes the task of the operation in the background and tracks it in the status of the cluster,
+// returning the phase of the operation. The task is only executed once for each id. An operation running when the
+// operator restarts is submitted again, as the queue is held in memory
+func (r *infinispanRequest) runOperation(operationType infinispanv1.OperationType, id string, task operationFunc) (infinispanv1.OperationPhase, error) {
+	infinispan := r.infinispan
+	status := infinispan.GetOperation(operationType)
+	if status != nil && status.ID == id && status.Phase != infinispanv1.OperationRunning {
+		return status.Phase, nil
+	}
+
+	key := operationKey(infinispan, operationType, id)
+	result, queued := r.operations.result(key)
+	if !queued {
+		if !r.operations.submit(key, infinispan, task) {
+			r.reqLogger.Info("Waiting for a free operation worker", "operation", operationType, "id", id)
+			return infinispanv1.OperationRunning, nil
+		}
+		if status != nil && status.ID == id {
+			r.reqLogger.Info("Resuming the operation", "operation", operationType, "id", id)
+			return infinispanv1.OperationRunning, nil
+		}
+		r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonOperationStarted, fmt.Sprintf("%s operation started", operationType))
+		now := metav1.Now()
+		return infinispanv1.OperationRunning, r.update(func() {
+			infinispan.SetOperation(infinispanv1.OperationStatus{Type: operationType, Phase: infinispanv1.OperationRunning, ID: id, StartTime: &now})
+		})
+	}
+	if !result.done {
+		return infinispanv1.OperationRunning, nil
+	}
+
+	completed := infinispanv1.OperationStatus{Type: operationType, Phase: infinispanv1.OperationSucceeded, ID: id}
+	if status != nil {
+		completed.StartTime = status.StartTime
+	}
+	now := metav1.Now()
+	completed.CompletionTime = &now
+	for _, e := range result.events {
+		r.eventRec.Event(infinispan, e.eventType, e.reason, e.message)
+	}
+	if result.err != nil {
+		completed.Phase = infinispanv1.OperationFailed
+		completed.Message = result.err.Error()
+		r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonOperationFailed, fmt.Sprintf("%s operation failed: %s", operationType, result.err))
+	} else {
+		r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonOperationSucceeded, fmt.Sprintf("%s operation succeeded", operationType))
+	}
+	if err := r.update(func() {
+		infinispan.SetOperation(completed)
+	}); err != nil {
+		return infinispanv1.OperationRunning, err
+	}
+	r.operations.forget(key)
+	return completed.Phase, nil
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func operationRequest(t *testing.T) *infinispanRequest {
+	infinispan := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace, CreationTimestamp: metav1.Now()},
+	}
+	r := volumeExpansionRequest(t, "1Gi", infinispan)
+	r.infinispan = infinispan.DeepCopy()
+	r.operations = newOperationQueue(1, logf.Log, operationCluster(nil))
+	return r
+}
+
+// operationCluster returns the cluster factory of an operation queue creating the given cluster client
+func operationCluster(cluster ispn.ClusterInterface) func(*infinispanv1.Infinispan) (ispn.ClusterInterface, error) {
+	return func(*infinispanv1.Infinispan) (ispn.ClusterInterface, error) {
+		return cluster, nil
+	}
+}
+
+// awaitOperation runs the operation until it's completed in the background
+func awaitOperation(t *testing.T, r *infinispanRequest, id string, task operationFunc) infinispanv1.OperationPhase {
+	var phase infinispanv1.OperationPhase
+	assert.Eventually(t, func() bool {
+		var err error
+		phase, err = r.runOperation(infinispanv1.OperationGracefulShutdown, id, task)
+		assert.NoError(t, err)
+		return phase != infinispanv1.OperationRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return phase
+}
+
+func TestRunOperation(t *testing.T) {
+	r := operationRequest(t)
+	events := r.eventRec.(*record.FakeRecorder).Events
+	release := make(chan struct{})
+	executions := 0
+	task := func(op *operation) error {
+		<-release
+		executions++
+		op.event(corev1.EventTypeNormal, "ShutdownRequested", "Shutdown requested")
+		return nil
+	}
+
+	phase, err := r.runOperation(infinispanv1.OperationGracefulShutdown, "1", task)
+	assert.NoError(t, err)
+	assert.Equal(t, infinispanv1.OperationRunning, phase)
+	status := r.infinispan.GetOperation(infinispanv1.OperationGracefulShutdown)
+	assert.Equal(t, infinispanv1.OperationRunning, status.Phase)
+	assert.NotNil(t, status.StartTime)
+	assert.Equal(t, "Normal OperationStarted GracefulShutdown operation started", nextEvent(events))
+
+	// The reconcile loop isn't blocked while the operation runs
+	phase, err = r.runOperation(infinispanv1.OperationGracefulShutdown, "1", task)
+	assert.NoError(t, err)
+	assert.Equal(t, infinispanv1.OperationRunning, phase)
+
+	close(release)
+	assert.Equal(t, infinispanv1.OperationSucceeded, awaitOperation(t, r, "1", task))
+	status = r.infinispan.GetOperation(infinispanv1.OperationGracefulShutdown)
+	assert.NotNil(t, status.CompletionTime)
+	// The events of the task are recorded once the result of the operation is recorded
+	assert.Equal(t, "Normal ShutdownRequested Shutdown requested", nextEvent(events))
+	assert.Equal(t, "Normal OperationSucceeded GracefulShutdown operation succeeded", nextEvent(events))
+	assert.Equal(t, 1, executions)
+	assert.Equal(t, "example-infinispan", (<-r.operations.completed).Object.GetName())
+
+	// A completed operation isn't executed again
+	phase, err = r.runOperation(infinispanv1.OperationGracefulShutdown, "1", task)
+	assert.NoError(t, err)
+	assert.Equal(t, infinispanv1.OperationSucceeded, phase)
+	assert.Equal(t, 1, executions)
+
+	assert.Equal(t, infinispanv1.OperationFailed, awaitOperation(t, r, "2", func(*operation) error {
+		return errors.New("server error")
+	}))
+	assert.Equal(t, "server error", r.infinispan.GetOperation(infinispanv1.OperationGracefulShutdown).Message)
+	assert.Len(t, r.infinispan.Status.Operations, 1)
+}
+
+func TestRunOperationResumed(t *testing.T) {
+	r := operationRequest(t)
+	r.infinispan.SetOperation(infinispanv1.OperationStatus{Type: infinispanv1.OperationGracefulShutdown, Phase: infinispanv1.OperationRunning, ID: "1"})
+
+	// The operation is submitted again after a restart of the operator
+	executed := false
+	assert.Equal(t, infinispanv1.OperationSucceeded, awaitOperation(t, r, "1", func(*operation) error {
+		executed = true
+		return nil
+	}))
+	assert.True(t, executed)
+}
+
+func TestRunOperationBusyWorkers(t *testing.T) {
+	r := operationRequest(t)
+	release := make(chan struct{})
+	_, err := r.runOperation(infinispanv1.OperationGracefulShutdown, "1", func(*operation) error {
+		<-release
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// The operation of another cluster waits for the worker, without being recorded as started
+	other := operationRequest(t)
+	other.operations = r.operations
+	other.infinispan.Name = "other-infinispan"
+	executed := false
+	phase, err := other.runOperation(infinispanv1.OperationGracefulShutdown, "1", func(*operation) error {
+		executed = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, infinispanv1.OperationRunning, phase)
+	assert.Nil(t, other.infinispan.GetOperation(infinispanv1.OperationGracefulShutdown))
+
+	// Both clusters are reconciled once the worker is released
+	close(release)
+	notified := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		notified[(<-r.operations.completed).Object.GetName()] = true
+	}
+	assert.Equal(t, map[string]bool{"example-infinispan": true, "other-infinispan": true}, notified)
+	assert.Equal(t, infinispanv1.OperationSucceeded, awaitOperation(t, other, "1", func(*operation) error {
+		executed = true
+		return nil
+	}))
+	assert.True(t, executed)
+}
+
+// shutdownCluster fails the graceful shutdown requests sent to the pods of failing
+type shutdownCluster struct {
+	ispn.ClusterInterface
+	failing  map[string]bool
+	shutdown []string
+}
+
+func (c *shutdownCluster) GracefulShutdown(podName string) error {
+	if c.failing[podName] {
+		return errors.New("shutdown failed")
+	}
+	c.shutdown = append(c.shutdown, podName)
+	return nil
+}
+
+func (c *shutdownCluster) GracefulShutdownTask(podName string) error {
+	return c.GracefulShutdown(podName)
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	pods := []string{"example-infinispan-0", "example-infinispan-1"}
+	cluster := &shutdownCluster{failing: map[string]bool{"example-infinispan-0": true}}
+	assert.NoError(t, gracefulShutdown(pods, ctrl.Log, cluster))
+	assert.Equal(t, []string{"example-infinispan-1"}, cluster.shutdown)
+
+	cluster = &shutdownCluster{failing: map[string]bool{"example-infinispan-0": true, "example-infinispan-1": true}}
+	assert.EqualError(t, gracefulShutdown(pods, ctrl.Log, cluster), "unable to shutdown the cluster from pod example-infinispan-1: shutdown failed")
+}
+
+func TestGracefulShutdownFailed(t *testing.T) {
+	r := operationRequest(t)
+	r.operations.newCluster = operationCluster(&shutdownCluster{failing: map[string]bool{"example-infinispan-0": true}})
+	pod := dataVolumePod("example-infinispan-0")
+	pod.Labels = PodLabels(r.infinispan.Name)
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	assert.NoError(t, r.Client.Create(r.ctx, &pod))
+
+	assert.Eventually(t, func() bool {
+		result, err := r.gracefulShutdownReq(&corev1.PodList{Items: []corev1.Pod{pod}}, logf.Log)
+		assert.NoError(t, err)
+		return result.RequeueAfter == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The pods are kept running and the cluster is degraded
+	condition := r.infinispan.GetCondition(infinispanv1.ConditionGracefulShutdown)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, infinispanv1.ReasonShutdownFailed, condition.Reason)
+	assert.Equal(t, "The graceful shutdown failed, the pods are kept running: unable to shutdown the cluster from pod example-infinispan-0: shutdown failed", condition.Message)
+	assert.Equal(t, metav1.ConditionTrue, r.infinispan.GetCondition(infinispanv1.ConditionDegraded).Status)
+	assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: pod.Name}, &corev1.Pod{}))
+}
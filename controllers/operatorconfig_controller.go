@@ -17,9 +17,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
-const configMapName = "infinispan-operator-config"
+const (
+	configMapName = "infinispan-operator-config"
+	// OperatorDashboardAnnotation records the namespace/name of the operator GrafanaDashboard on the operator
+	// ConfigMap, so that a new leader replica knows the dashboard created by the previous one
+	OperatorDashboardAnnotation = "infinispan.org/grafana-dashboard"
+)
 
+// currentConfig is the configuration applied by the operator. It's only kept in memory, so it's primed from the
+// operator ConfigMap by the first reconcile of the replica holding the leadership
 var currentConfig map[string]string = make(map[string]string)
+var currentConfigPrimed bool
 
 // ReconcileInfinispan reconciles a Infinispan object
 type ReconcileOperatorConfig struct {
@@ -76,8 +84,13 @@ func (r *ReconcileOperatorConfig) Reconcile(ctx context.Context, request reconci
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	if !currentConfigPrimed {
+		primeCurrentConfig(configMap, currentConfig)
+		currentConfigPrimed = true
+	}
+
 	config := map[string]string{
-		grafanaDashboardMonitoringKey: "middleware",
+		grafanaDashboardMonitoringKey: defaultGrafanaDashboardMonitoringKey,
 		grafanaDashboardNameKey:       "infinispan",
 	}
 	// Merge config value with defaults
@@ -85,5 +98,42 @@ func (r *ReconcileOperatorConfig) Reconcile(ctx context.Context, request reconci
 		config[k] = v
 	}
 	res, err := r.reconcileGrafana(ctx, config, currentConfig, operatorNs)
+	if err == nil && !configMap.CreationTimestamp.IsZero() {
+		err = r.recordCurrentConfig(ctx, configMap, currentConfig)
+	}
 	return *res, err
 }
+
+// primeCurrentConfig restores the location of the operator dashboard recorded on the ConfigMap, so that the dashboard
+// is deleted when its location changed while another replica was holding the leadership
+func primeCurrentConfig(configMap *corev1.ConfigMap, current map[string]string) {
+	location := strings.SplitN(configMap.Annotations[OperatorDashboardAnnotation], "/", 2)
+	if len(location) == 2 {
+		current[grafanaDashboardNamespaceKey] = location[0]
+		current[grafanaDashboardNameKey] = location[1]
+	}
+}
+
+// recordCurrentConfig records the location of the operator dashboard on the ConfigMap
+func (r *ReconcileOperatorConfig) recordCurrentConfig(ctx context.Context, configMap *corev1.ConfigMap, current map[string]string) error {
+	var location string
+	if current[grafanaDashboardNamespaceKey] != "" && current[grafanaDashboardNameKey] != "" {
+		location = current[grafanaDashboardNamespaceKey] + "/" + current[grafanaDashboardNameKey]
+	}
+	if configMap.Annotations[OperatorDashboardAnnotation] == location {
+		return nil
+	}
+	patch := client.MergeFrom(configMap.DeepCopy())
+	if location == "" {
+		delete(configMap.Annotations, OperatorDashboardAnnotation)
+	} else {
+		if configMap.Annotations == nil {
+			configMap.Annotations = map[string]string{}
+		}
+		configMap.Annotations[OperatorDashboardAnnotation] = location
+	}
+	if err := r.Client.Patch(ctx, configMap, patch); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
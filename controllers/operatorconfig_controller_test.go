@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOperatorConfigFailover(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapName, Namespace: namespace, CreationTimestamp: metav1.Now()}}
+	r := &ReconcileOperatorConfig{Client: fake.NewFakeClientWithScheme(scheme, configMap)}
+	get := func() *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{}
+		assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: configMapName}, cm))
+		return cm
+	}
+
+	// The active replica records the location of the dashboard it created
+	current := map[string]string{grafanaDashboardNamespaceKey: "grafana", grafanaDashboardNameKey: "infinispan"}
+	assert.NoError(t, r.recordCurrentConfig(context.TODO(), get(), current))
+	assert.Equal(t, "grafana/infinispan", get().Annotations[OperatorDashboardAnnotation])
+
+	// A new leader restores it
+	primed := map[string]string{}
+	primeCurrentConfig(get(), primed)
+	assert.Equal(t, current, primed)
+
+	// The annotation is removed with the dashboard
+	assert.NoError(t, r.recordCurrentConfig(context.TODO(), get(), map[string]string{grafanaDashboardNamespaceKey: "", grafanaDashboardNameKey: ""}))
+	assert.NotContains(t, get().Annotations, OperatorDashboardAnnotation)
+	primed = map[string]string{}
+	primeCurrentConfig(get(), primed)
+	assert.Empty(t, primed)
+}
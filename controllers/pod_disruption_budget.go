@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"fmt"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// PodDisruptionBudgetGroupVersion is accessed unstructured, as the client release of the operator predates it
+var PodDisruptionBudgetGroupVersion = schema.GroupVersion{Group: "policy", Version: "v1"}
+
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;delete;update
+
+// podDisruptionBudgetType returns the PodDisruptionBudget type to reconcile. The policy/v1 API is preferred, the
+// policy/v1beta1 API is only used by the Kubernetes releases that don't serve policy/v1 yet
+func podDisruptionBudgetType(kubernetes *kube.Kubernetes) *reconcileType {
+	if ok, err := kubernetes.IsGroupVersionSupported(PodDisruptionBudgetGroupVersion.String(), consts.PodDisruptionBudgetType); err == nil && ok {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(PodDisruptionBudgetGroupVersion.WithKind(consts.PodDisruptionBudgetType))
+		return &reconcileType{ObjectType: obj, GroupVersion: PodDisruptionBudgetGroupVersion, GroupVersionSupported: false}
+	}
+	return &reconcileType{ObjectType: &policyv1beta1.PodDisruptionBudget{}, GroupVersion: policyv1beta1.SchemeGroupVersion, GroupVersionSupported: false}
+}
+
+// reconcilePodDisruptionBudget keeps the PodDisruptionBudget of the cluster pods in line with the configured policy
+// and replicas, removing it when disabled
+func (s serviceRequest) reconcilePodDisruptionBudget() error {
+	if !s.isTypeSupported(consts.PodDisruptionBudgetType) {
+		return nil
+	}
+	ispn := s.infinispan
+	pdb := s.supportedTypes[consts.PodDisruptionBudgetType].ObjectType.DeepCopyObject().(client.Object)
+	pdb.SetName(ispn.GetPodDisruptionBudgetName())
+	pdb.SetNamespace(ispn.Namespace)
+	if !ispn.IsPodDisruptionBudgetEnabled() {
+		if err := s.Client.Delete(s.ctx, pdb); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	_, err := controllerutil.CreateOrUpdate(s.ctx, s.Client, pdb, func() error {
+		pdb.SetLabels(LabelsResource(ispn.Name, "infinispan-pdb"))
+		if err := setPodDisruptionBudgetSpec(pdb, podDisruptionBudgetSpec(ispn)); err != nil {
+			return err
+		}
+		ApplyPropagatedMetadata(ispn, pdb)
+		return controllerutil.SetControllerReference(ispn, pdb, s.scheme)
+	})
+	return err
+}
+
+// setPodDisruptionBudgetSpec sets the spec of the policy/v1beta1 or unstructured policy/v1 PodDisruptionBudget, the
+// fields set by the operator being the same in both versions
+func setPodDisruptionBudgetSpec(pdb client.Object, spec policyv1beta1.PodDisruptionBudgetSpec) error {
+	if typed, ok := pdb.(*policyv1beta1.PodDisruptionBudget); ok {
+		typed.Spec = spec
+		return nil
+	}
+	unstructuredSpec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&spec)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(pdb.(*unstructured.Unstructured).Object, unstructuredSpec, "spec")
+}
+
+// podDisruptionBudgetSpec returns the disruptions allowed for the cluster pods. With the Quorum policy a majority of
+// the requested replicas must remain available, otherwise a single pod at a time can be evicted
+func podDisruptionBudgetSpec(ispn *ispnv1.Infinispan) policyv1beta1.PodDisruptionBudgetSpec {
+	spec := policyv1beta1.PodDisruptionBudgetSpec{
+		Selector: &metav1.LabelSelector{MatchLabels: PodLabels(ispn.Name)},
+	}
+	if ispn.GetPodDisruptionBudgetPolicy() == ispnv1.PodDisruptionBudgetQuorum {
+		minAvailable := intstr.FromInt(int(ispn.Spec.Replicas/2 + 1))
+		spec.MinAvailable = &minAvailable
+	} else {
+		maxUnavailable := intstr.FromInt(1)
+		spec.MaxUnavailable = &maxUnavailable
+	}
+	return spec
+}
+
+// validatePodDisruptionBudget rejects the Quorum policy for clusters of fewer than 3 pods, as the majority of such a
+// cluster is every pod, so that no pod could ever be evicted and the nodes couldn't be drained
+func validatePodDisruptionBudget(ispn *ispnv1.Infinispan) error {
+	if !ispn.IsPodDisruptionBudgetEnabled() || ispn.GetPodDisruptionBudgetPolicy() != ispnv1.PodDisruptionBudgetQuorum {
+		return nil
+	}
+	replicas := ispn.Spec.Replicas
+	if autoscale := ispn.Spec.Autoscale; autoscale != nil && ispn.Spec.Service.Type == ispnv1.ServiceTypeCache && autoscale.MinReplicas < replicas {
+		replicas = autoscale.MinReplicas
+	}
+	if replicas > 0 && replicas < 3 {
+		return fmt.Errorf("infinispan.spec.scheduling.podDisruptionBudget.policy=%s requires at least 3 replicas, with %d replicas it would block the eviction of every pod", ispnv1.PodDisruptionBudgetQuorum, replicas)
+	}
+	return nil
+}
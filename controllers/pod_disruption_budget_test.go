@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func pdbInfinispan(replicas int32, pdb *ispnv1.InfinispanPodDisruptionBudgetSpec) *ispnv1.Infinispan {
+	return &ispnv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing"},
+		Spec: ispnv1.InfinispanSpec{
+			Replicas:   replicas,
+			Scheduling: &ispnv1.InfinispanSchedulingSpec{PodDisruptionBudget: pdb},
+		},
+	}
+}
+
+func TestPodDisruptionBudgetSpec(t *testing.T) {
+	// A single pod can be evicted at a time by default
+	spec := podDisruptionBudgetSpec(&ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan"}, Spec: ispnv1.InfinispanSpec{Replicas: 3}})
+	maxUnavailable := intstr.FromInt(1)
+	assert.Equal(t, &maxUnavailable, spec.MaxUnavailable)
+	assert.Nil(t, spec.MinAvailable)
+	assert.Equal(t, PodLabels("example-infinispan"), spec.Selector.MatchLabels)
+
+	for replicas, quorum := range map[int32]int{1: 1, 2: 2, 3: 2, 4: 3, 5: 3} {
+		spec := podDisruptionBudgetSpec(pdbInfinispan(replicas, &ispnv1.InfinispanPodDisruptionBudgetSpec{Policy: ispnv1.PodDisruptionBudgetQuorum}))
+		minAvailable := intstr.FromInt(quorum)
+		assert.Equal(t, &minAvailable, spec.MinAvailable, replicas)
+		assert.Nil(t, spec.MaxUnavailable)
+	}
+}
+
+func TestIsPodDisruptionBudgetEnabled(t *testing.T) {
+	assert.True(t, (&ispnv1.Infinispan{}).IsPodDisruptionBudgetEnabled())
+	assert.True(t, pdbInfinispan(1, nil).IsPodDisruptionBudgetEnabled())
+	assert.True(t, pdbInfinispan(1, &ispnv1.InfinispanPodDisruptionBudgetSpec{Enabled: pointer.BoolPtr(true)}).IsPodDisruptionBudgetEnabled())
+	assert.False(t, pdbInfinispan(1, &ispnv1.InfinispanPodDisruptionBudgetSpec{Enabled: pointer.BoolPtr(false)}).IsPodDisruptionBudgetEnabled())
+}
+
+func TestSetPodDisruptionBudgetSpec(t *testing.T) {
+	spec := podDisruptionBudgetSpec(pdbInfinispan(5, &ispnv1.InfinispanPodDisruptionBudgetSpec{Policy: ispnv1.PodDisruptionBudgetQuorum}))
+
+	typed := &policyv1beta1.PodDisruptionBudget{}
+	assert.NoError(t, setPodDisruptionBudgetSpec(typed, spec))
+	assert.Equal(t, spec, typed.Spec)
+
+	// The policy/v1 PodDisruptionBudget is unstructured
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(PodDisruptionBudgetGroupVersion.WithKind("PodDisruptionBudget"))
+	assert.NoError(t, setPodDisruptionBudgetSpec(obj, spec))
+	minAvailable, _, _ := unstructured.NestedInt64(obj.Object, "spec", "minAvailable")
+	assert.Equal(t, int64(3), minAvailable)
+	matchLabels, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, PodLabels("example-infinispan"), matchLabels)
+}
+
+func TestValidatePodDisruptionBudget(t *testing.T) {
+	quorum := &ispnv1.InfinispanPodDisruptionBudgetSpec{Policy: ispnv1.PodDisruptionBudgetQuorum}
+	assert.NoError(t, validatePodDisruptionBudget(pdbInfinispan(2, nil)))
+	assert.NoError(t, validatePodDisruptionBudget(pdbInfinispan(3, quorum)))
+	// A shutdown cluster doesn't have pods to evict
+	assert.NoError(t, validatePodDisruptionBudget(pdbInfinispan(0, quorum)))
+	assert.NoError(t, validatePodDisruptionBudget(pdbInfinispan(2, &ispnv1.InfinispanPodDisruptionBudgetSpec{Enabled: pointer.BoolPtr(false), Policy: ispnv1.PodDisruptionBudgetQuorum})))
+	assert.EqualError(t, validatePodDisruptionBudget(pdbInfinispan(2, quorum)),
+		"infinispan.spec.scheduling.podDisruptionBudget.policy=Quorum requires at least 3 replicas, with 2 replicas it would block the eviction of every pod")
+
+	// The autoscaler can scale the cluster down to its minimum replicas
+	autoscaled := pdbInfinispan(3, quorum)
+	autoscaled.Spec.Service.Type = ispnv1.ServiceTypeCache
+	autoscaled.Spec.Autoscale = &ispnv1.Autoscale{MinReplicas: 1, MaxReplicas: 5}
+	assert.Error(t, validatePodDisruptionBudget(autoscaled))
+}
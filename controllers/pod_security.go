@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"reflect"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// ServerConfMountPath is where the server configuration is generated when the container starts
+	ServerConfMountPath = ServerRoot + "/conf"
+	ServerLogMountPath  = ServerRoot + "/log"
+	TmpMountPath        = "/tmp"
+
+	// ServerConfInitContainer copies the configuration shipped with the server image into the writable conf volume,
+	// which would otherwise hide it
+	ServerConfInitContainer = "server-conf"
+	serverConfCopyPath      = "/server-conf"
+)
+
+// writableVolumes are the emptyDir volumes mounted in the Infinispan container, by mount path, for the directories
+// written by the server when the root filesystem is read-only
+var writableVolumes = map[string]string{
+	ServerConfMountPath: "server-conf",
+	ServerLogMountPath:  "server-log",
+	TmpMountPath:        "tmp",
+}
+
+func restrictedPodSecurityContext() *corev1.PodSecurityContext {
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot:   pointer.BoolPtr(true),
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+func restrictedSecurityContext() *corev1.SecurityContext {
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: pointer.BoolPtr(false),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		ReadOnlyRootFilesystem:   pointer.BoolPtr(true),
+		RunAsNonRoot:             pointer.BoolPtr(true),
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// applyPodSecurityProfile sets the security context of the pod and of its containers for the Pod Security Standard
+// profile of the Infinispan, returning true if the pod spec was changed. The containers with a security context of
+// their own, such as the user defined sidecars, are left as is. The Default profile removes the security context the
+// Restricted profile sets
+func applyPodSecurityProfile(i *infinispanv1.Infinispan, spec *corev1.PodSpec) bool {
+	original := spec.DeepCopy()
+	restricted := i.IsPodSecurityRestricted()
+	if restricted {
+		spec.SecurityContext = restrictedPodSecurityContext()
+	} else if reflect.DeepEqual(spec.SecurityContext, restrictedPodSecurityContext()) {
+		spec.SecurityContext = nil
+	}
+	applyWritableVolumes(restricted, spec)
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for c := range containers {
+			container := &containers[c]
+			if restricted && container.SecurityContext == nil {
+				container.SecurityContext = restrictedSecurityContext()
+			} else if !restricted && reflect.DeepEqual(container.SecurityContext, restrictedSecurityContext()) {
+				container.SecurityContext = nil
+			}
+		}
+	}
+	return !reflect.DeepEqual(original, spec)
+}
+
+// applyWritableVolumes adds the writable volumes to the Infinispan container, with the init container that fills the
+// conf volume from the server image, or removes them
+func applyWritableVolumes(add bool, spec *corev1.PodSpec) {
+	applyServerConfInitContainer(add, spec)
+	container := &spec.Containers[0]
+	for _, mountPath := range []string{ServerConfMountPath, ServerLogMountPath, TmpMountPath} {
+		name := writableVolumes[mountPath]
+		volumePosition := findVolume(spec.Volumes, name)
+		if add && volumePosition < 0 {
+			spec.Volumes = append(spec.Volumes, corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: name, MountPath: mountPath})
+		} else if !add && volumePosition >= 0 {
+			spec.Volumes = append(spec.Volumes[:volumePosition], spec.Volumes[volumePosition+1:]...)
+			if mountPosition := findVolumeMount(container.VolumeMounts, name); mountPosition >= 0 {
+				container.VolumeMounts = append(container.VolumeMounts[:mountPosition], container.VolumeMounts[mountPosition+1:]...)
+			}
+		}
+	}
+}
+
+// applyServerConfInitContainer adds the init container copying the server image configuration, such as log4j2.xml and
+// the default server configurations, into the conf volume, or removes it. The init container runs the image of the
+// Infinispan container so that the copied files always match the server version
+func applyServerConfInitContainer(add bool, spec *corev1.PodSpec) {
+	position := kube.ContainerIndex(spec.InitContainers, ServerConfInitContainer)
+	if !add {
+		if position >= 0 {
+			spec.InitContainers = append(spec.InitContainers[:position], spec.InitContainers[position+1:]...)
+		}
+		return
+	}
+	if position < 0 {
+		spec.InitContainers = append(spec.InitContainers, corev1.Container{
+			Name:         ServerConfInitContainer,
+			Command:      []string{"cp", "-R", ServerConfMountPath + "/.", serverConfCopyPath},
+			VolumeMounts: []corev1.VolumeMount{{Name: writableVolumes[ServerConfMountPath], MountPath: serverConfCopyPath}},
+		})
+		position = len(spec.InitContainers) - 1
+	}
+	spec.InitContainers[position].Image = spec.Containers[0].Image
+}
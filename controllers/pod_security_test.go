@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func podSecurityInfinispan(profile infinispanv1.PodSecurityProfile) *infinispanv1.Infinispan {
+	return &infinispanv1.Infinispan{Spec: infinispanv1.InfinispanSpec{Security: infinispanv1.InfinispanSecurity{PodSecurityProfile: profile}}}
+}
+
+func TestApplyPodSecurityProfile(t *testing.T) {
+	sidecarContext := &corev1.SecurityContext{RunAsUser: pointer.Int64Ptr(1000)}
+	spec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init"}},
+		Containers:     []corev1.Container{{Name: InfinispanContainer, Image: "infinispan:1"}, {Name: "sidecar", SecurityContext: sidecarContext}},
+		Volumes:        []corev1.Volume{{Name: DataMountVolume}},
+	}
+	assert.False(t, applyPodSecurityProfile(podSecurityInfinispan(""), spec))
+	assert.False(t, applyPodSecurityProfile(podSecurityInfinispan(infinispanv1.PodSecurityProfileDefault), spec))
+	assert.Nil(t, spec.SecurityContext)
+
+	assert.True(t, applyPodSecurityProfile(podSecurityInfinispan(infinispanv1.PodSecurityProfileRestricted), spec))
+	assert.True(t, *spec.SecurityContext.RunAsNonRoot)
+	assert.Equal(t, corev1.SeccompProfileTypeRuntimeDefault, spec.SecurityContext.SeccompProfile.Type)
+	// The conf volume is filled with the configuration of the server image before the server starts
+	assert.Len(t, spec.InitContainers, 2)
+	confContainer := spec.InitContainers[1]
+	assert.Equal(t, ServerConfInitContainer, confContainer.Name)
+	assert.Equal(t, "infinispan:1", confContainer.Image)
+	assert.Equal(t, []string{"cp", "-R", ServerConfMountPath + "/.", serverConfCopyPath}, confContainer.Command)
+	assert.Equal(t, []corev1.VolumeMount{{Name: "server-conf", MountPath: serverConfCopyPath}}, confContainer.VolumeMounts)
+	for _, container := range []corev1.Container{spec.InitContainers[0], confContainer, spec.Containers[0]} {
+		assert.Equal(t, restrictedSecurityContext(), container.SecurityContext, container.Name)
+	}
+	assert.True(t, *spec.Containers[0].SecurityContext.ReadOnlyRootFilesystem)
+	assert.Equal(t, []corev1.Capability{"ALL"}, spec.Containers[0].SecurityContext.Capabilities.Drop)
+	// The security context of the user defined containers is kept
+	assert.Equal(t, sidecarContext, spec.Containers[1].SecurityContext)
+	assert.Len(t, spec.Volumes, 4)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "server-conf", MountPath: ServerConfMountPath},
+		{Name: "server-log", MountPath: ServerLogMountPath},
+		{Name: "tmp", MountPath: TmpMountPath},
+	}, spec.Containers[0].VolumeMounts)
+	assert.False(t, applyPodSecurityProfile(podSecurityInfinispan(infinispanv1.PodSecurityProfileRestricted), spec))
+
+	// An upgrade of the server copies the configuration of the new image
+	spec.Containers[0].Image = "infinispan:2"
+	assert.True(t, applyPodSecurityProfile(podSecurityInfinispan(infinispanv1.PodSecurityProfileRestricted), spec))
+	assert.Equal(t, "infinispan:2", spec.InitContainers[1].Image)
+
+	assert.True(t, applyPodSecurityProfile(podSecurityInfinispan(infinispanv1.PodSecurityProfileDefault), spec))
+	assert.Nil(t, spec.SecurityContext)
+	assert.Equal(t, []corev1.Container{{Name: "init"}}, spec.InitContainers)
+	assert.Nil(t, spec.Containers[0].SecurityContext)
+	assert.Equal(t, sidecarContext, spec.Containers[1].SecurityContext)
+	assert.Equal(t, []corev1.Volume{{Name: DataMountVolume}}, spec.Volumes)
+	assert.Empty(t, spec.Containers[0].VolumeMounts)
+}
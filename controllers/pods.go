@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
 	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -26,20 +29,43 @@ func PodPortsWithXsite(i *infinispanv1.Infinispan) []corev1.ContainerPort {
 	if i.HasSites() {
 		ports = append(ports, corev1.ContainerPort{ContainerPort: consts.CrossSitePort, Name: consts.CrossSitePortName, Protocol: corev1.ProtocolTCP})
 	}
+	if i.IsMemcachedEnabled() {
+		ports = append(ports, corev1.ContainerPort{ContainerPort: consts.InfinispanMemcachedPort, Name: consts.InfinispanMemcachedPortName, Protocol: corev1.ProtocolTCP})
+	}
+	if i.IsRespEnabled() {
+		ports = append(ports, corev1.ContainerPort{ContainerPort: consts.InfinispanRespPort, Name: consts.InfinispanRespPortName, Protocol: corev1.ProtocolTCP})
+	}
 	return ports
 }
 
-func PodLivenessProbe() *corev1.Probe {
-	return probe(5, 10, 10, 1, 80)
+func PodLivenessProbe(i *infinispanv1.Infinispan) *corev1.Probe {
+	return applyProbeSpec(probe(5, 10, 10, 1, 80), i.GetProbes().Liveness)
+}
+
+func PodReadinessProbe(i *infinispanv1.Infinispan) *corev1.Probe {
+	return applyProbeSpec(probe(5, 10, 10, 1, 80), i.GetProbes().Readiness)
 }
 
-func PodReadinessProbe() *corev1.Probe {
-	return probe(5, 10, 10, 1, 80)
+func PodStartupProbe(i *infinispanv1.Infinispan) *corev1.Probe {
+	// Maximum 10 minutes (60 * 10s) to finish startup by default
+	return applyProbeSpec(probe(60, 10, 10, 1, 80), i.GetProbes().Startup)
 }
 
-func PodStartupProbe() *corev1.Probe {
-	// Maximum 10 minutes (60 * 10s) to finish startup
-	return probe(60, 10, 10, 1, 80)
+// applyProbeSpec overrides the default thresholds of the probe with the ones configured by the user
+func applyProbeSpec(probe *corev1.Probe, spec *infinispanv1.InfinispanProbeSpec) *corev1.Probe {
+	if spec == nil {
+		return probe
+	}
+	if spec.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *spec.InitialDelaySeconds
+	}
+	if spec.PeriodSeconds != nil {
+		probe.PeriodSeconds = *spec.PeriodSeconds
+	}
+	if spec.FailureThreshold != nil {
+		probe.FailureThreshold = *spec.FailureThreshold
+	}
+	return probe
 }
 
 func probe(failureThreshold, initialDelay, period, successThreshold, timeout int32) *corev1.Probe {
@@ -106,14 +132,87 @@ func PodResources(spec infinispanv1.InfinispanContainerSpec) (*corev1.ResourceRe
 	}, nil
 }
 
+const (
+	// UserEnvAnnotation lists the user defined variables of the container, so that removed variables can be dropped
+	UserEnvAnnotation = "infinispan.org/user-env"
+	// UserEnvHashAnnotation tracks the changes of the user defined variables
+	UserEnvHashAnnotation = "infinispan.org/user-env-hash"
+)
+
+// operatorEnvVars are the container variables managed by the operator, which can't be overridden by the user
+var operatorEnvVars = map[string]bool{
+	"CONFIG_PATH": true, "MANAGED_ENV": true, "JAVA_OPTIONS": true, "EXTRA_JAVA_OPTIONS": true, "DEFAULT_IMAGE": true,
+	"ADMIN_IDENTITIES_PATH": true, "IDENTITIES_PATH": true, "CONFIG_HASH": true, "ADMIN_IDENTITIES_HASH": true,
+	"IDENTITIES_HASH": true, "KEYSTORE_HASH": true, "TRUSTSTORE_HASH": true,
+}
+
+// validateContainerEnv verifies that the user defined variables are unique and not managed by the operator
+func validateContainerEnv(i *infinispanv1.Infinispan) error {
+	names := make(map[string]bool, len(i.Spec.Container.Env))
+	for _, env := range i.Spec.Container.Env {
+		if operatorEnvVars[env.Name] {
+			return fmt.Errorf("infinispan.spec.container.env variable '%s' is managed by the operator and can't be overridden", env.Name)
+		}
+		if names[env.Name] {
+			return fmt.Errorf("infinispan.spec.container.env defines variable '%s' more than once", env.Name)
+		}
+		names[env.Name] = true
+	}
+	return nil
+}
+
+// ApplyUserEnv replaces the user defined variables of the container with the ones of the Infinispan spec, returning
+// true if they have been changed
+func ApplyUserEnv(i *infinispanv1.Infinispan, meta *metav1.ObjectMeta, spec *corev1.PodSpec) bool {
+	userEnv := i.Spec.Container.Env
+	envHash := ""
+	if len(userEnv) > 0 {
+		envJSON, _ := json.Marshal(userEnv)
+		envHash = hash.HashByte(envJSON)
+	}
+	if meta.Annotations[UserEnvHashAnnotation] == envHash {
+		return false
+	}
+
+	remove := map[string]bool{}
+	for _, name := range strings.Split(meta.Annotations[UserEnvAnnotation], ",") {
+		remove[name] = true
+	}
+	names := make([]string, 0, len(userEnv))
+	for _, env := range userEnv {
+		remove[env.Name] = true
+		names = append(names, env.Name)
+	}
+	container := &spec.Containers[0]
+	var envVars []corev1.EnvVar
+	for _, env := range container.Env {
+		if !remove[env.Name] {
+			envVars = append(envVars, env)
+		}
+	}
+	container.Env = append(envVars, userEnv...)
+
+	if envHash == "" {
+		delete(meta.Annotations, UserEnvAnnotation)
+		delete(meta.Annotations, UserEnvHashAnnotation)
+	} else {
+		if meta.Annotations == nil {
+			meta.Annotations = map[string]string{}
+		}
+		meta.Annotations[UserEnvAnnotation] = strings.Join(names, ",")
+		meta.Annotations[UserEnvHashAnnotation] = envHash
+	}
+	return true
+}
+
 func PodEnv(i *infinispanv1.Infinispan, systemEnv *[]corev1.EnvVar) []corev1.EnvVar {
 	envVars := []corev1.EnvVar{
 		{Name: "CONFIG_PATH", Value: consts.ServerConfigPath},
 		// Prevent the image from generating a user if authentication disabled
 		{Name: "MANAGED_ENV", Value: "TRUE"},
 		{Name: "JAVA_OPTIONS", Value: i.GetJavaOptions()},
-		{Name: "EXTRA_JAVA_OPTIONS", Value: i.Spec.Container.ExtraJvmOpts},
-		{Name: "DEFAULT_IMAGE", Value: consts.DefaultImageName},
+		{Name: "EXTRA_JAVA_OPTIONS", Value: i.Spec.Container.GetExtraJvmOpts()},
+		{Name: "DEFAULT_IMAGE", Value: i.DefaultImageName()},
 		{Name: "ADMIN_IDENTITIES_PATH", Value: consts.ServerAdminIdentitiesPath},
 	}
 
@@ -140,31 +239,18 @@ func PodEnv(i *infinispanv1.Infinispan, systemEnv *[]corev1.EnvVar) []corev1.Env
 		envVars = append(envVars, *systemEnv...)
 	}
 
+	// User defined variables are added last, so that they take precedence over the ADDITIONAL_VARS
+	envVars = append(envVars, i.Spec.Container.Env...)
+
 	return envVars
 }
 
-// AddVolumeForUserAuthentication returns true if the volume has been added
-func AddVolumeForUserAuthentication(i *infinispanv1.Infinispan, spec *corev1.PodSpec) bool {
-	if _, index := findSecretInVolume(spec, IdentitiesVolumeName); !i.IsAuthenticationEnabled() || index >= 0 {
+// AddVolumeForUserAuthentication configures the pod to load the user identities, returning true if it has been changed
+func AddVolumeForUserAuthentication(i *infinispanv1.Infinispan, meta *metav1.ObjectMeta, spec *corev1.PodSpec) bool {
+	if !i.IsAuthenticationEnabled() {
 		return false
 	}
-
-	v := &spec.Volumes
-	*v = append(*v, corev1.Volume{
-		Name: IdentitiesVolumeName,
-		VolumeSource: corev1.VolumeSource{
-			Secret: &corev1.SecretVolumeSource{
-				SecretName: i.GetSecretName(),
-			},
-		},
-	})
-
-	vm := &spec.Containers[0].VolumeMounts
-	*vm = append(*vm, corev1.VolumeMount{
-		Name:      IdentitiesVolumeName,
-		MountPath: consts.ServerUserIdentitiesRoot,
-	})
-	return true
+	return identitiesSourceFor(i).addToPod(meta, spec)
 }
 
 // AddVolumeChmodInitContainer adds an init container that run chmod if needed
@@ -187,41 +273,42 @@ func chmodInitContainer(containerName, volumeName, mountPath string) corev1.Cont
 	}
 }
 
-func AddVolumesForEncryption(i *infinispanv1.Infinispan, spec *corev1.PodSpec) {
-	addSecretVolume := func(secretName, volumeName, mountPath string, spec *corev1.PodSpec) {
-		v := &spec.Volumes
-
-		if _, index := findSecretInVolume(spec, volumeName); index < 0 {
-			*v = append(*v, corev1.Volume{Name: volumeName,
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: secretName,
-					},
+// addSecretVolume mounts the Secret in the server container
+func addSecretVolume(secretName, volumeName, mountPath string, spec *corev1.PodSpec) {
+	v := &spec.Volumes
+
+	if _, index := findSecretInVolume(spec, volumeName); index < 0 {
+		*v = append(*v, corev1.Volume{Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
 				},
-			})
-		}
+			},
+		})
+	}
 
-		volumeMount := corev1.VolumeMount{
-			Name:      volumeName,
-			MountPath: mountPath,
-		}
+	volumeMount := corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+	}
 
-		index := -1
-		volumeMounts := &spec.Containers[0].VolumeMounts
-		for i, vm := range *volumeMounts {
-			if vm.Name == volumeName {
-				index = i
-				break
-			}
+	index := -1
+	volumeMounts := &spec.Containers[0].VolumeMounts
+	for i, vm := range *volumeMounts {
+		if vm.Name == volumeName {
+			index = i
+			break
 		}
+	}
 
-		if index < 0 {
-			*volumeMounts = append(*volumeMounts, volumeMount)
-		} else {
-			(*volumeMounts)[index] = volumeMount
-		}
+	if index < 0 {
+		*volumeMounts = append(*volumeMounts, volumeMount)
+	} else {
+		(*volumeMounts)[index] = volumeMount
 	}
+}
 
+func AddVolumesForEncryption(i *infinispanv1.Infinispan, spec *corev1.PodSpec) {
 	addSecretVolume(i.GetKeystoreSecretName(), EncryptKeystoreVolumeName, consts.ServerEncryptKeystoreRoot, spec)
 
 	if i.IsClientCertEnabled() {
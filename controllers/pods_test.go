@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateContainerEnv(t *testing.T) {
+	ispn := &ispnv1.Infinispan{}
+	assert.NoError(t, validateContainerEnv(ispn))
+
+	ispn.Spec.Container.Env = []corev1.EnvVar{{Name: "MALLOC_ARENA_MAX", Value: "2"}}
+	assert.NoError(t, validateContainerEnv(ispn))
+
+	ispn.Spec.Container.Env = append(ispn.Spec.Container.Env, corev1.EnvVar{Name: "MALLOC_ARENA_MAX", Value: "4"})
+	assert.Error(t, validateContainerEnv(ispn))
+
+	ispn.Spec.Container.Env = []corev1.EnvVar{{Name: "JAVA_OPTIONS", Value: "-Xmx1g"}}
+	assert.Error(t, validateContainerEnv(ispn))
+}
+
+func TestApplyUserEnv(t *testing.T) {
+	ispn := &ispnv1.Infinispan{}
+	meta := &metav1.ObjectMeta{}
+	spec := &corev1.PodSpec{Containers: []corev1.Container{{Env: PodEnv(ispn, nil)}}}
+	operatorEnv := append([]corev1.EnvVar{}, spec.Containers[0].Env...)
+
+	// Clusters without user defined variables are not updated
+	assert.False(t, ApplyUserEnv(ispn, meta, spec))
+	assert.Empty(t, meta.Annotations)
+
+	ispn.Spec.Container.Env = []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}
+	assert.True(t, ApplyUserEnv(ispn, meta, spec))
+	assert.Equal(t, append(operatorEnv, ispn.Spec.Container.Env...), spec.Containers[0].Env)
+	assert.Equal(t, "A,B", meta.Annotations[UserEnvAnnotation])
+	assert.False(t, ApplyUserEnv(ispn, meta, spec))
+
+	// Removed variables are dropped
+	ispn.Spec.Container.Env = []corev1.EnvVar{{Name: "B", Value: "3"}}
+	assert.True(t, ApplyUserEnv(ispn, meta, spec))
+	assert.Equal(t, append(operatorEnv, ispn.Spec.Container.Env...), spec.Containers[0].Env)
+
+	ispn.Spec.Container.Env = nil
+	assert.True(t, ApplyUserEnv(ispn, meta, spec))
+	assert.Equal(t, operatorEnv, spec.Containers[0].Env)
+	assert.Empty(t, meta.Annotations)
+
+	// Variables already added by PodEnv are not duplicated
+	ispn.Spec.Container.Env = []corev1.EnvVar{{Name: "A", Value: "1"}}
+	spec.Containers[0].Env = PodEnv(ispn, nil)
+	assert.True(t, ApplyUserEnv(ispn, meta, spec))
+	assert.Equal(t, append(operatorEnv, ispn.Spec.Container.Env...), spec.Containers[0].Env)
+}
+
+func TestPodProbes(t *testing.T) {
+	ispn := &ispnv1.Infinispan{}
+	assert.Equal(t, probe(60, 10, 10, 1, 80), PodStartupProbe(ispn))
+
+	ispn.Spec.Service.Container = &ispnv1.InfinispanServiceContainerSpec{
+		Probes: &ispnv1.InfinispanProbesSpec{
+			Startup:  &ispnv1.InfinispanProbeSpec{FailureThreshold: pointer.Int32Ptr(360)},
+			Liveness: &ispnv1.InfinispanProbeSpec{InitialDelaySeconds: pointer.Int32Ptr(0), PeriodSeconds: pointer.Int32Ptr(30)},
+		},
+	}
+	assert.Equal(t, probe(360, 10, 10, 1, 80), PodStartupProbe(ispn))
+	assert.Equal(t, probe(5, 0, 30, 1, 80), PodLivenessProbe(ispn))
+	assert.Equal(t, probe(5, 10, 10, 1, 80), PodReadinessProbe(ispn))
+}
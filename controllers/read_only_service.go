@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"fmt"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StateTransferCompleteLabel is set on the pods that have joined the cluster and completed the state transfer of the
+// caches, the pods targeted by the read-only Service
+const StateTransferCompleteLabel = "infinispan.org/state-transfer-complete"
+
+// ReadOnlyServiceLabels returns the selector of the read-only Service
+func ReadOnlyServiceLabels(name string) map[string]string {
+	m := ServiceLabels(name)
+	m[StateTransferCompleteLabel] = "true"
+	return m
+}
+
+// computeReadOnlyService computes the Service targeting the pods that completed the state transfer, which clients use
+// to read the entries without hitting pods that are still receiving them
+func computeReadOnlyService(ispn *ispnv1.Infinispan) *corev1.Service {
+	service := computeService(ispn)
+	service.Name = ispn.GetReadOnlyServiceName()
+	service.Labels = LabelsResource(ispn.Name, "infinispan-service-read-only")
+	service.Spec.Selector = ReadOnlyServiceLabels(ispn.Name)
+	// This way CR labels will override operator labels with same name
+	ispn.AddOperatorLabelsForServices(service.Labels)
+	ispn.AddLabelsForServices(service.Labels)
+	return service
+}
+
+// reconcileReadOnlyService creates the read-only Service, or deletes it when it is disabled
+func (s serviceRequest) reconcileReadOnlyService() error {
+	if s.infinispan.HasReadOnlyService() {
+		service := computeReadOnlyService(s.infinispan)
+		setupServiceForEncryption(s.infinispan, service)
+		return s.reconcileResource(service)
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.infinispan.GetReadOnlyServiceName(),
+			Namespace: s.infinispan.Namespace,
+		},
+	}
+	if err := s.Client.Delete(s.ctx, service); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// reconcileStateTransferLabels labels the pods that have joined a cluster view including all the pods, once the state
+// transfer of the caches has completed. Like the readiness gate, the label is never removed so that the read-only
+// Service keeps targeting the pods that have all their entries while new pods join the cluster
+func (r *infinispanRequest) reconcileStateTransferLabels(podList *corev1.PodList, cluster ispn.ClusterInterface) error {
+	if !r.infinispan.HasReadOnlyService() {
+		return nil
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Labels[StateTransferCompleteLabel] == "true" || !containersReady(pod) {
+			continue
+		}
+		if status, _, _, err := podJoinedCluster(pod, len(podList.Items), cluster); err != nil {
+			r.reqLogger.Error(err, "unable to check whether the pod completed the state transfer", "pod", pod.Name)
+			continue
+		} else if status != corev1.ConditionTrue {
+			continue
+		}
+
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		pod.Labels[StateTransferCompleteLabel] = "true"
+		if err := r.Client.Patch(r.ctx, pod, patch); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("unable to label pod '%s': %w", pod.Name, err)
+		}
+		r.reqLogger.Info("Pod completed the state transfer", "pod", pod.Name)
+	}
+	return nil
+}
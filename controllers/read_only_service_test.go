@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestComputeReadOnlyService(t *testing.T) {
+	i := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec:       infinispanv1.InfinispanSpec{Expose: &infinispanv1.ExposeSpec{Type: infinispanv1.ExposeTypeNodePort, ReadOnlyService: true}},
+	}
+	service := computeReadOnlyService(i)
+	assert.Equal(t, "example-infinispan-read-only", service.Name)
+	assert.Equal(t, map[string]string{"clusterName": "example-infinispan", "app": "infinispan-pod", StateTransferCompleteLabel: "true"}, service.Spec.Selector)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, service.Spec.Type)
+	assert.Equal(t, computeService(i).Spec.Ports, service.Spec.Ports)
+}
+
+func TestReconcileStateTransferLabels(t *testing.T) {
+	pod0, pod1 := gatedPod("example-infinispan-0", true), gatedPod("example-infinispan-1", true)
+	r := volumeExpansionRequest(t, "1Gi", &pod0, &pod1)
+	r.infinispan.Spec.Expose = &infinispanv1.ExposeSpec{Type: infinispanv1.ExposeTypeNodePort, ReadOnlyService: true}
+	cluster := &joiningCluster{
+		rebalancingCluster: rebalancingCluster{rebalancing: map[string]bool{"a": true}},
+		views: map[string][]string{
+			"example-infinispan-0": {"example-infinispan-0", "example-infinispan-1"},
+			"example-infinispan-1": {"example-infinispan-0", "example-infinispan-1"},
+		},
+	}
+	labelled := func(name string) bool {
+		pod := &corev1.Pod{}
+		assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, pod))
+		return pod.Labels[StateTransferCompleteLabel] == "true"
+	}
+
+	podList := &corev1.PodList{}
+	assert.NoError(t, r.Client.List(context.TODO(), podList))
+	assert.NoError(t, r.reconcileStateTransferLabels(podList, cluster))
+	assert.False(t, labelled("example-infinispan-0"))
+
+	cluster.rebalancing = nil
+	assert.NoError(t, r.Client.List(context.TODO(), podList))
+	assert.NoError(t, r.reconcileStateTransferLabels(podList, cluster))
+	assert.True(t, labelled("example-infinispan-0"))
+	assert.True(t, labelled("example-infinispan-1"))
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"fmt"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PodConditionWellFormed is the readiness gate of the pods, set by the operator once the pod has joined a cluster
+	// view with all the pods of the StatefulSet
+	PodConditionWellFormed corev1.PodConditionType = "infinispan.org/well-formed"
+
+	PodReasonJoinedCluster  = "JoinedCluster"
+	PodReasonJoiningCluster = "JoiningCluster"
+	PodReasonStateTransfer  = "StateTransferInProgress"
+)
+
+// podReadinessGates returns the readiness gates of the pods, nil if the readiness gate is not enabled
+func podReadinessGates(i *infinispanv1.Infinispan) []corev1.PodReadinessGate {
+	if !i.HasReadinessGate() {
+		return nil
+	}
+	return []corev1.PodReadinessGate{{ConditionType: PodConditionWellFormed}}
+}
+
+// reconcileReadinessGate sets the readiness gate condition of the pods that have joined a cluster view including all
+// the pods of the StatefulSet, once the state transfer of the caches has completed. The condition is never reverted,
+// so that the pods already serving clients keep receiving traffic while new pods join the cluster. The pods are
+// created one at a time by the StatefulSet, so the view of a pod only needs to include the pods that exist
+func (r *infinispanRequest) reconcileReadinessGate(podList *corev1.PodList, cluster ispn.ClusterInterface) error {
+	if !r.infinispan.HasReadinessGate() {
+		return nil
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !hasPodReadinessGate(pod) || podConditionStatus(pod, PodConditionWellFormed) == corev1.ConditionTrue || !containersReady(pod) {
+			continue
+		}
+
+		status, reason, message, err := podJoinedCluster(pod, len(podList.Items), cluster)
+		if err != nil {
+			r.reqLogger.Error(err, "unable to check whether the pod joined the cluster", "pod", pod.Name)
+			continue
+		}
+
+		if !setPodCondition(pod, PodConditionWellFormed, status, reason, message) {
+			continue
+		}
+		if err := r.Client.Status().Update(r.ctx, pod); err != nil {
+			if errors.IsConflict(err) || errors.IsNotFound(err) {
+				// The pod changed, its condition is set by the next reconciliation
+				continue
+			}
+			return fmt.Errorf("unable to update the readiness gate of pod '%s': %w", pod.Name, err)
+		}
+		if status == corev1.ConditionTrue {
+			r.reqLogger.Info("Pod joined the cluster", "pod", pod.Name)
+		}
+	}
+	return nil
+}
+
+// podJoinedCluster returns the status of the readiness gate condition of a pod, true once the cluster view of the pod
+// includes the expected number of pods and no cache is rebalancing
+func podJoinedCluster(pod *corev1.Pod, pods int, cluster ispn.ClusterInterface) (status corev1.ConditionStatus, reason, message string, err error) {
+	health, err := cluster.GetClusterHealth(pod.Name)
+	if err != nil {
+		return
+	}
+	if len(health.Nodes) < pods {
+		return corev1.ConditionFalse, PodReasonJoiningCluster, fmt.Sprintf("The cluster view has %d of the %d pods", len(health.Nodes), pods), nil
+	}
+	if rebalancing, err := clusterRebalancing(cluster, pod.Name); err != nil {
+		return "", "", "", err
+	} else if rebalancing {
+		return corev1.ConditionFalse, PodReasonStateTransfer, "Waiting for the state transfer of the caches to complete", nil
+	}
+	return corev1.ConditionTrue, PodReasonJoinedCluster, "", nil
+}
+
+func hasPodReadinessGate(pod *corev1.Pod) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == PodConditionWellFormed {
+			return true
+		}
+	}
+	return false
+}
+
+// containersReady returns true if the containers of the pod are ready, regardless of its readiness gates
+func containersReady(pod *corev1.Pod) bool {
+	return podConditionStatus(pod, corev1.ContainersReady) == corev1.ConditionTrue
+}
+
+func podConditionStatus(pod *corev1.Pod, conditionType corev1.PodConditionType) corev1.ConditionStatus {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == conditionType {
+			return cond.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
+
+// setPodCondition sets the condition of the pod, returning true if it changed
+func setPodCondition(pod *corev1.Pod, conditionType corev1.PodConditionType, status corev1.ConditionStatus, reason, message string) bool {
+	condition := corev1.PodCondition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	for i, cond := range pod.Status.Conditions {
+		if cond.Type != conditionType {
+			continue
+		}
+		if cond.Status == status && cond.Reason == reason && cond.Message == message {
+			return false
+		}
+		if cond.Status == status {
+			condition.LastTransitionTime = cond.LastTransitionTime
+		}
+		pod.Status.Conditions[i] = condition
+		return true
+	}
+	pod.Status.Conditions = append(pod.Status.Conditions, condition)
+	return true
+}
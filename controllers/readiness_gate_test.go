@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// joiningCluster reports the members of the view of each pod, and the caches in `rebalancing` as being rebalanced
+type joiningCluster struct {
+	rebalancingCluster
+	views map[string][]string
+}
+
+func (c *joiningCluster) GetClusterHealth(podName string) (*ispn.ClusterHealth, error) {
+	return &ispn.ClusterHealth{Nodes: c.views[podName], Status: ispn.ClusterHealthHealthy}, nil
+}
+
+func gatedPod(name string, containersReady bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if containersReady {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.PodSpec{ReadinessGates: []corev1.PodReadinessGate{{ConditionType: PodConditionWellFormed}}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: status}}},
+	}
+}
+
+func readinessGateStatus(t *testing.T, r *infinispanRequest, name string) corev1.ConditionStatus {
+	pod := &corev1.Pod{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, pod))
+	return podConditionStatus(pod, PodConditionWellFormed)
+}
+
+func TestPodReadinessGates(t *testing.T) {
+	i := &infinispanv1.Infinispan{}
+	assert.Nil(t, podReadinessGates(i))
+
+	i.Spec.Service.Container = &infinispanv1.InfinispanServiceContainerSpec{ReadinessGate: true}
+	assert.Equal(t, []corev1.PodReadinessGate{{ConditionType: PodConditionWellFormed}}, podReadinessGates(i))
+}
+
+func TestReconcileReadinessGate(t *testing.T) {
+	pod0, pod1, pod2 := gatedPod("example-infinispan-0", true), gatedPod("example-infinispan-1", true), gatedPod("example-infinispan-2", false)
+	r := volumeExpansionRequest(t, "1Gi", &pod0, &pod1, &pod2)
+	r.infinispan.Spec.Service.Container.ReadinessGate = true
+	cluster := &joiningCluster{views: map[string][]string{
+		"example-infinispan-0": {"example-infinispan-0", "example-infinispan-1"},
+		"example-infinispan-1": {"example-infinispan-0", "example-infinispan-1"},
+	}}
+	podList := &corev1.PodList{Items: []corev1.Pod{pod0, pod1, pod2}}
+
+	// The view doesn't include all the pods yet
+	assert.NoError(t, r.reconcileReadinessGate(podList.DeepCopy(), cluster))
+	assert.Equal(t, corev1.ConditionFalse, readinessGateStatus(t, r, "example-infinispan-0"))
+	assert.Equal(t, corev1.ConditionUnknown, readinessGateStatus(t, r, "example-infinispan-2"))
+
+	// The pods wait for the state transfer to complete
+	podList.Items = podList.Items[:2]
+	cluster.rebalancing = map[string]bool{"a": true}
+	assert.NoError(t, r.reconcileReadinessGate(podList.DeepCopy(), cluster))
+	pod := &corev1.Pod{}
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "example-infinispan-1"}, pod))
+	assert.Equal(t, PodReasonStateTransfer, pod.Status.Conditions[1].Reason)
+
+	cluster.rebalancing = nil
+	podList = &corev1.PodList{}
+	assert.NoError(t, r.Client.List(context.TODO(), podList))
+	podList.Items = podList.Items[:2]
+	assert.NoError(t, r.reconcileReadinessGate(podList, cluster))
+	assert.Equal(t, corev1.ConditionTrue, readinessGateStatus(t, r, "example-infinispan-0"))
+	assert.Equal(t, corev1.ConditionTrue, readinessGateStatus(t, r, "example-infinispan-1"))
+
+	// The condition of the ready pods is not reverted while a new pod joins the cluster
+	assert.NoError(t, r.Client.List(context.TODO(), podList))
+	assert.NoError(t, r.reconcileReadinessGate(podList, cluster))
+	assert.Equal(t, corev1.ConditionTrue, readinessGateStatus(t, r, "example-infinispan-0"))
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	EventReasonRebalanceStarted   = "RebalanceStarted"
+	EventReasonRebalanceProgress  = "RebalanceProgress"
+	EventReasonRebalanceCompleted = "RebalanceCompleted"
+)
+
+// clusterTopology returns the hash of the pods of the cluster, which changes whenever a pod joins or leaves the
+// cluster, including when a pod is recreated
+func clusterTopology(podList *corev1.PodList) string {
+	members := make([]string, 0, len(podList.Items))
+	for _, pod := range podList.Items {
+		members = append(members, pod.Name+"/"+string(pod.UID))
+	}
+	sort.Strings(members)
+	return hash.HashString(strings.Join(members, ","))
+}
+
+// rebalancingCaches returns the names of the caches whose entries are being redistributed across the cluster
+func rebalancingCaches(cluster ispn.ClusterInterface, podName string) ([]string, error) {
+	cacheNames, err := cluster.CacheNames(podName)
+	if err != nil {
+		return nil, err
+	}
+	var rebalancing []string
+	for _, cacheName := range cacheNames {
+		details, err := cluster.GetCacheDetails(cacheName, podName)
+		if err != nil {
+			return nil, err
+		}
+		if details.RehashInProgress {
+			rebalancing = append(rebalancing, cacheName)
+		}
+	}
+	sort.Strings(rebalancing)
+	return rebalancing, nil
+}
+
+// reconcileRebalance follows the rebalance of the caches after a change of the cluster topology, setting the
+// Rebalancing condition while the entries are redistributed. Events report the start of the rebalance, its progress
+// as the percentage of the caches that have been rebalanced, and its duration once completed. The caches are only
+// inspected when the topology changes or while a rebalance is in progress
+func (r *infinispanRequest) reconcileRebalance(podList *corev1.PodList, cluster ispn.ClusterInterface) error {
+	infinispan := r.infinispan
+	if len(podList.Items) == 0 || !kube.AreAllPodsReady(podList) {
+		return nil
+	}
+	topology := clusterTopology(podList)
+	rebalancing := infinispan.IsConditionTrue(infinispanv1.ConditionRebalancing)
+	status := infinispan.Status.Rebalance
+	if !rebalancing && status != nil && status.Topology == topology {
+		return nil
+	}
+
+	caches, err := rebalancingCaches(cluster, podList.Items[0].Name)
+	if err != nil {
+		r.reqLogger.Error(err, "unable to retrieve the rebalance status of the caches")
+		return nil
+	}
+
+	if len(caches) == 0 {
+		reason, message := infinispanv1.ReasonCachesBalanced, ""
+		if rebalancing && status != nil && status.StartTime != nil {
+			duration := time.Since(status.StartTime.Time).Round(time.Second)
+			reason = infinispanv1.ReasonRebalanceCompleted
+			message = fmt.Sprintf("Rebalanced %d caches in %s", len(status.Caches), duration)
+			r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonRebalanceCompleted, message)
+		}
+		return r.update(func() {
+			infinispan.SetCondition(infinispanv1.ConditionRebalancing, metav1.ConditionFalse, reason, message)
+			infinispan.Status.Rebalance = &infinispanv1.RebalanceStatus{Topology: topology, Progress: 100}
+		})
+	}
+
+	updated := &infinispanv1.RebalanceStatus{Topology: topology}
+	if rebalancing && status != nil && status.StartTime != nil {
+		updated.StartTime = status.StartTime
+		updated.Caches = mergeCacheNames(status.Caches, caches)
+	} else {
+		now := metav1.Now()
+		updated.StartTime = &now
+		updated.Caches = caches
+		r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonRebalanceStarted, fmt.Sprintf("Rebalancing %d caches after a change of the cluster topology", len(caches)))
+	}
+	updated.Progress = int32((len(updated.Caches) - len(caches)) * 100 / len(updated.Caches))
+	if status != nil && rebalancing && updated.Progress != status.Progress {
+		duration := time.Since(updated.StartTime.Time).Round(time.Second)
+		r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonRebalanceProgress, fmt.Sprintf("Rebalance %d%% complete after %s", updated.Progress, duration))
+	}
+	message := fmt.Sprintf("%d%% of the caches rebalanced, waiting for: %s", updated.Progress, strings.Join(caches, ", "))
+	return r.update(func() {
+		infinispan.SetCondition(infinispanv1.ConditionRebalancing, metav1.ConditionTrue, infinispanv1.ReasonRebalanceInProgress, message)
+		infinispan.Status.Rebalance = updated
+	})
+}
+
+// mergeCacheNames returns the sorted union of the cache names
+func mergeCacheNames(names, others []string) []string {
+	unique := make(map[string]bool, len(names)+len(others))
+	for _, name := range append(append([]string{}, names...), others...) {
+		unique[name] = true
+	}
+	merged := make([]string, 0, len(unique))
+	for name := range unique {
+		merged = append(merged, name)
+	}
+	sort.Strings(merged)
+	return merged
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// nextEvent returns the next recorded event, empty if none was recorded
+func nextEvent(events chan string) string {
+	select {
+	case event := <-events:
+		return event
+	default:
+		return ""
+	}
+}
+
+func rebalancePod(name string) corev1.Pod {
+	pod := dataVolumePod(name)
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Ready: true}}
+	return pod
+}
+
+func TestReconcileRebalance(t *testing.T) {
+	infinispan := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace, CreationTimestamp: metav1.Now()},
+	}
+	r := volumeExpansionRequest(t, "1Gi", infinispan)
+	r.infinispan = infinispan.DeepCopy()
+	events := r.eventRec.(*record.FakeRecorder).Events
+	pods := &corev1.PodList{Items: []corev1.Pod{rebalancePod("example-infinispan-0"), rebalancePod("example-infinispan-1")}}
+
+	assert.NoError(t, r.reconcileRebalance(pods, &rebalancingCluster{rebalancing: map[string]bool{"a": true, "b": true}}))
+	condition := r.infinispan.GetCondition(infinispanv1.ConditionRebalancing)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "0% of the caches rebalanced, waiting for: a, b", condition.Message)
+	assert.Equal(t, "Normal RebalanceStarted Rebalancing 2 caches after a change of the cluster topology", nextEvent(events))
+	startTime := r.infinispan.Status.Rebalance.StartTime
+	assert.NotNil(t, startTime)
+
+	assert.NoError(t, r.reconcileRebalance(pods, &rebalancingCluster{rebalancing: map[string]bool{"b": true}}))
+	assert.Equal(t, "50% of the caches rebalanced, waiting for: b", r.infinispan.GetCondition(infinispanv1.ConditionRebalancing).Message)
+	assert.Regexp(t, `^Normal RebalanceProgress Rebalance 50% complete after \ds$`, nextEvent(events))
+	assert.Equal(t, []string{"a", "b"}, r.infinispan.Status.Rebalance.Caches)
+	assert.Equal(t, startTime, r.infinispan.Status.Rebalance.StartTime)
+
+	assert.NoError(t, r.reconcileRebalance(pods, &rebalancingCluster{}))
+	condition = r.infinispan.GetCondition(infinispanv1.ConditionRebalancing)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, infinispanv1.ReasonRebalanceCompleted, condition.Reason)
+	assert.Regexp(t, `^Normal RebalanceCompleted Rebalanced 2 caches in \ds$`, nextEvent(events))
+	assert.Equal(t, int32(100), r.infinispan.Status.Rebalance.Progress)
+
+	// The caches are only inspected again when the topology changes
+	assert.NoError(t, r.reconcileRebalance(pods, &rebalancingCluster{rebalancing: map[string]bool{"a": true}}))
+	assert.False(t, r.infinispan.IsConditionTrue(infinispanv1.ConditionRebalancing))
+	assert.Empty(t, nextEvent(events))
+
+	pods.Items[1].UID = "recreated"
+	assert.NoError(t, r.reconcileRebalance(pods, &rebalancingCluster{rebalancing: map[string]bool{"a": true}}))
+	assert.True(t, r.infinispan.IsConditionTrue(infinispanv1.ConditionRebalancing))
+	assert.Equal(t, "Normal RebalanceStarted Rebalancing 1 caches after a change of the cluster topology", nextEvent(events))
+}
+
+func TestMergeCacheNames(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, mergeCacheNames([]string{"c", "a"}, []string{"b", "a"}))
+	assert.Empty(t, mergeCacheNames(nil, nil))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
 	"github.com/infinispan/infinispan-operator/pkg/infinispan/backup"
 	"github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
 	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
@@ -116,6 +117,52 @@ func (r *restore) update(mutate func()) (bool, error) {
 }
 
 func (r *restore) Init() (*zeroCapacitySpec, error) {
+	var encryptionImage string
+	if encryption := r.instance.Spec.Encryption; encryption != nil {
+		if err := validateBackupEncryption(r.ctx, r.client, r.instance.Namespace, encryption); err != nil {
+			if updateErr := r.UpdatePhase(ZeroFailed, err); updateErr != nil {
+				return nil, updateErr
+			}
+			return nil, err
+		}
+		image, err := operatorImage(r.ctx, r.client)
+		if err != nil {
+			return nil, err
+		}
+		encryptionImage = image
+	}
+
+	if storage := r.instance.Spec.Storage; storage != nil {
+		if err := validateBackupStorage(storage); err != nil {
+			if updateErr := r.UpdatePhase(ZeroFailed, err); updateErr != nil {
+				return nil, updateErr
+			}
+			return nil, err
+		}
+		// The archive is downloaded before the zero-capacity server starts, so the Backup isn't required
+		download, volumes := backupStorageContainer(storage, r.instance.Spec.Backup, restoreArchive(r.instance), false)
+		zeroSpec := &zeroCapacitySpec{
+			Container: r.instance.Spec.Container,
+			PodLabels: RestorePodLabels(r.instance.Name, r.instance.Spec.Cluster),
+			Volume: zeroCapacityVolumeSpec{
+				MountPath: BackupDataMountPath,
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			},
+			InitContainers: []corev1.Container{download},
+			Volumes:        volumes,
+		}
+		if encryptionImage != "" {
+			// The downloaded archive is decrypted in place
+			archive := restoreArchive(r.instance)
+			decrypt, keyVolume := backupEncryptionContainer(r.instance.Spec.Encryption, encryptionImage, archive, archive, true)
+			zeroSpec.InitContainers = append(zeroSpec.InitContainers, decrypt)
+			zeroSpec.Volumes = append(zeroSpec.Volumes, keyVolume)
+		}
+		return zeroSpec, nil
+	}
+
 	backup := &v2alpha1.Backup{}
 	backupKey := types.NamespacedName{
 		Namespace: r.instance.Namespace,
@@ -126,6 +173,10 @@ func (r *restore) Init() (*zeroCapacitySpec, error) {
 		return nil, fmt.Errorf("unable to load Infinispan Backup '%s': %w", backupKey.Name, err)
 	}
 
+	if encryptionImage != "" {
+		return decryptedRestoreSpec(r.instance, backup, encryptionImage), nil
+	}
+
 	return &zeroCapacitySpec{
 		Container: r.instance.Spec.Container,
 		PodLabels: RestorePodLabels(r.instance.Name, backup.Spec.Cluster),
@@ -141,6 +192,38 @@ func (r *restore) Init() (*zeroCapacitySpec, error) {
 	}, nil
 }
 
+// decryptedRestoreSpec returns the zero-capacity spec restoring the encrypted archive of the Backup PVC. The PVC is
+// read-only, so the archive is decrypted to an EmptyDir before the zero-capacity server starts
+func decryptedRestoreSpec(restore *v2alpha1.Restore, backup *v2alpha1.Backup, image string) *zeroCapacitySpec {
+	encryptedArchive := fmt.Sprintf("%[1]s/%[2]s/%[2]s.zip", BackupEncryptedDataMountPath, backup.Name)
+	decrypt, keyVolume := backupEncryptionContainer(restore.Spec.Encryption, image, encryptedArchive, restoreArchive(restore), true)
+	decrypt.VolumeMounts = append(decrypt.VolumeMounts, corev1.VolumeMount{
+		Name:      BackupEncryptedDataVolumeName,
+		MountPath: BackupEncryptedDataMountPath,
+		ReadOnly:  true,
+	})
+	return &zeroCapacitySpec{
+		Container: restore.Spec.Container,
+		PodLabels: RestorePodLabels(restore.Name, backup.Spec.Cluster),
+		Volume: zeroCapacityVolumeSpec{
+			MountPath: BackupDataMountPath,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		InitContainers: []corev1.Container{decrypt},
+		Volumes: []corev1.Volume{keyVolume, {
+			Name: BackupEncryptedDataVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: backup.Name,
+					ReadOnly:  true,
+				},
+			},
+		}},
+	}
+}
+
 func (r *restore) Exec(client http.HttpClient) error {
 	instance := r.instance
 	backupManager := backup.NewManager(instance.Name, client)
@@ -157,12 +240,20 @@ func (r *restore) Exec(client http.HttpClient) error {
 		}
 	}
 	config := &backup.RestoreConfig{
-		Location:  fmt.Sprintf("%[1]s/%[2]s/%[2]s.zip", BackupDataMountPath, instance.Spec.Backup),
+		Location:  restoreArchive(instance),
 		Resources: resources,
 	}
 	return backupManager.Restore(instance.Name, config)
 }
 
+// restoreArchive returns the path of the backup archive in the zero-capacity pod
+func restoreArchive(restore *v2alpha1.Restore) string {
+	if restore.Spec.Storage != nil {
+		return fmt.Sprintf("%s/%s.zip", BackupDataMountPath, restore.Spec.Backup)
+	}
+	return fmt.Sprintf("%[1]s/%[2]s/%[2]s.zip", BackupDataMountPath, restore.Spec.Backup)
+}
+
 func (r *restore) ExecStatus(client http.HttpClient) (zeroCapacityPhase, error) {
 	name := r.instance.Name
 	backupManager := backup.NewManager(name, client)
@@ -171,5 +262,46 @@ func (r *restore) ExecStatus(client http.HttpClient) (zeroCapacityPhase, error)
 	if err != nil {
 		return ZeroUnknown, err
 	}
+	cluster := &ispn.Cluster{Client: client}
+	if status != backup.StatusFailed {
+		if err := r.updateProgress(cluster, status); err != nil {
+			return ZeroUnknown, err
+		}
+	}
 	return zeroCapacityPhase(status), nil
 }
+
+func (r *restore) Cancel(client http.HttpClient) error {
+	name := r.instance.Name
+	return backup.NewManager(name, client).DeleteRestore(name)
+}
+
+// updateProgress publishes the progress of the restore in the status. The caches of the archive are only known to the
+// server when no caches are specified, otherwise a cache is done once it exists on the cluster
+func (r *restore) updateProgress(cluster ispn.ClusterInterface, status backup.Status) error {
+	progress := &v2alpha1.OperationProgress{}
+	if resources := r.instance.Spec.Resources; resources != nil && !containsWildcard(resources.Caches) {
+		caches := resources.Caches
+		progress.CachesTotal = int32(len(caches))
+		if status == backup.StatusSucceeded {
+			progress.CachesDone = progress.CachesTotal
+		} else if progress.CachesTotal > 0 {
+			existing, err := cluster.CacheNames(r.instance.Name)
+			if err != nil {
+				return err
+			}
+			for _, cache := range caches {
+				for _, name := range existing {
+					if cache == name {
+						progress.CachesDone++
+						break
+					}
+				}
+			}
+		}
+	}
+	_, err := r.update(func() {
+		r.instance.Status.Progress = progress
+	})
+	return err
+}
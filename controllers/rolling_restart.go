@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// RestartedAtAnnotation requests a rolling restart of the pods created before the RFC3339 timestamp it holds
+	RestartedAtAnnotation = "infinispan.org/restartedAt"
+
+	EventReasonRollingRestart          = "RollingRestart"
+	EventReasonRollingRestartCompleted = "RollingRestartCompleted"
+)
+
+// validateRestartedAt verifies that the rolling restart annotation holds a RFC3339 timestamp
+func validateRestartedAt(i *ispnv1.Infinispan) error {
+	if restartedAt, ok := i.Annotations[RestartedAtAnnotation]; ok {
+		if _, err := time.Parse(time.RFC3339, restartedAt); err != nil {
+			return fmt.Errorf("the %s annotation must be a RFC3339 timestamp: %w", RestartedAtAnnotation, err)
+		}
+	}
+	return nil
+}
+
+// reconcileRollingRestart deletes, one at a time, the pods created before the time of the RestartedAtAnnotation.
+// A pod is only deleted when all the pods are ready and no cache is rebalancing, so that the entries owned by the
+// restarted pod are always available on the other pods
+func (r *infinispanRequest) reconcileRollingRestart(podList *corev1.PodList, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	restartedAt, ok := infinispan.Annotations[RestartedAtAnnotation]
+	if !ok || restartedAt == infinispan.Status.RestartedAt {
+		return nil, nil
+	}
+	requested, err := time.Parse(time.RFC3339, restartedAt)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	pod := stalePod(podList.Items, requested)
+	if pod == nil {
+		r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonRollingRestartCompleted, fmt.Sprintf("All pods restarted after %s", restartedAt))
+		if err := r.update(func() {
+			infinispan.Status.RestartedAt = restartedAt
+		}); err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}
+
+	for _, p := range podList.Items {
+		if !kube.IsPodReady(p) {
+			r.reqLogger.Info("Waiting for the pods to be ready to continue the rolling restart", "pod", p.Name)
+			return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+		}
+	}
+	if rebalancing, err := clusterRebalancing(cluster, podList.Items[0].Name); err != nil {
+		return &ctrl.Result{}, err
+	} else if rebalancing {
+		r.reqLogger.Info("Waiting for the caches to be rebalanced to continue the rolling restart")
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+
+	r.reqLogger.Info("Restarting pod", "pod", pod.Name)
+	if err := r.Client.Delete(r.ctx, pod); err != nil && !errors.IsNotFound(err) {
+		return &ctrl.Result{}, err
+	}
+	r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonRollingRestart, fmt.Sprintf("Pod %s restarted", pod.Name))
+	return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+}
+
+// stalePod returns the pod with the highest ordinal created before `requested`, restarting the pods in the same order
+// as a StatefulSet rolling update
+func stalePod(pods []corev1.Pod, requested time.Time) *corev1.Pod {
+	var stale *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.CreationTimestamp.Time.Before(requested) && (stale == nil || podOrdinal(pod.Name) > podOrdinal(stale.Name)) {
+			stale = pod
+		}
+	}
+	return stale
+}
+
+// podOrdinal returns the ordinal of a StatefulSet pod, or -1 if the name has no ordinal suffix
+func podOrdinal(podName string) int {
+	ordinal, err := strconv.Atoi(podName[strings.LastIndex(podName, "-")+1:])
+	if err != nil {
+		return -1
+	}
+	return ordinal
+}
+
+// clusterRebalancing returns true if the data of any cache is being redistributed across the cluster
+func clusterRebalancing(cluster ispn.ClusterInterface, podName string) (bool, error) {
+	cacheNames, err := cluster.CacheNames(podName)
+	if err != nil {
+		return false, err
+	}
+	for _, cacheName := range cacheNames {
+		details, err := cluster.GetCacheDetails(cacheName, podName)
+		if err != nil {
+			return false, err
+		}
+		if details.RehashInProgress {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// rebalancingCluster reports the caches in `rebalancing` as being rebalanced
+type rebalancingCluster struct {
+	ispn.ClusterInterface
+	rebalancing map[string]bool
+}
+
+func (c *rebalancingCluster) CacheNames(podName string) ([]string, error) {
+	return []string{"a", "b"}, nil
+}
+
+func (c *rebalancingCluster) GetCacheDetails(cacheName, podName string) (*ispn.CacheDetails, error) {
+	return &ispn.CacheDetails{RehashInProgress: c.rebalancing[cacheName]}, nil
+}
+
+func TestStalePod(t *testing.T) {
+	requested := time.Date(2021, 10, 1, 10, 0, 0, 0, time.UTC)
+	pod := func(name string, created time.Time) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)}}
+	}
+	pods := []corev1.Pod{
+		pod("example-infinispan-0", requested.Add(-time.Hour)),
+		pod("example-infinispan-10", requested.Add(-time.Hour)),
+		pod("example-infinispan-2", requested.Add(-time.Hour)),
+	}
+	// Pods are restarted from the highest ordinal
+	assert.Equal(t, "example-infinispan-10", stalePod(pods, requested).Name)
+
+	pods[1] = pod("example-infinispan-10", requested.Add(time.Minute))
+	assert.Equal(t, "example-infinispan-2", stalePod(pods, requested).Name)
+
+	pods[0] = pod("example-infinispan-0", requested)
+	pods[2] = pod("example-infinispan-2", requested.Add(time.Minute))
+	assert.Nil(t, stalePod(pods, requested))
+}
+
+func TestClusterRebalancing(t *testing.T) {
+	rebalancing, err := clusterRebalancing(&rebalancingCluster{}, "pod")
+	assert.NoError(t, err)
+	assert.False(t, rebalancing)
+
+	rebalancing, err = clusterRebalancing(&rebalancingCluster{rebalancing: map[string]bool{"b": true}}, "pod")
+	assert.NoError(t, err)
+	assert.True(t, rebalancing)
+}
+
+func TestValidateRestartedAt(t *testing.T) {
+	ispn := &ispnv1.Infinispan{}
+	assert.NoError(t, validateRestartedAt(ispn))
+	ispn.Annotations = map[string]string{RestartedAtAnnotation: "2021-10-01T10:00:00Z"}
+	assert.NoError(t, validateRestartedAt(ispn))
+	ispn.Annotations[RestartedAtAnnotation] = "now"
+	assert.Error(t, validateRestartedAt(ispn))
+}
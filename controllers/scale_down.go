@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	EventReasonScaleDown = "ScaleDown"
+	// ScaleDownLeavingAnnotation names the pod that was asked to leave the cluster before the StatefulSet replicas are
+	// reduced, so that a server restarted by the kubelet is not stopped again
+	ScaleDownLeavingAnnotation = "infinispan.org/scale-down-leaving"
+)
+
+// isCoordinatedScaleDown returns true if the StatefulSet replicas are reduced by the scale-down coordinator rather
+// than set to spec.replicas. Scaling down to 0 replicas is handled by the graceful shutdown instead
+func isCoordinatedScaleDown(replicas, statefulSetReplicas int32) bool {
+	return replicas > 0 && replicas < statefulSetReplicas
+}
+
+// reconcileScaleDown removes the pods one at a time when spec.replicas is reduced. The highest ordinal pod first
+// leaves the cluster through the server, once the remaining pods are ready, they have formed a single view and no cache
+// is rebalancing. The StatefulSet replicas are only reduced once the leaving pod has left the view and the entries it
+// owned have been redistributed. The leaving pod doesn't need to be ready, so that a crash-looping pod can be removed
+func (r *infinispanRequest) reconcileScaleDown(statefulSet *appsv1.StatefulSet, podList *corev1.PodList, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	current := *statefulSet.Spec.Replicas
+	if !isCoordinatedScaleDown(infinispan.Spec.Replicas, current) {
+		if _, ok := statefulSet.Annotations[ScaleDownLeavingAnnotation]; ok {
+			// The scale down was interrupted by increasing spec.replicas again
+			delete(statefulSet.Annotations, ScaleDownLeavingAnnotation)
+			return r.updateScaleDownStatefulSet(statefulSet)
+		}
+		return nil, nil
+	}
+	leaving := fmt.Sprintf("%s-%d", statefulSet.Name, current-1)
+	remaining := &corev1.PodList{}
+	for _, pod := range podList.Items {
+		if pod.Name != leaving {
+			remaining.Items = append(remaining.Items, pod)
+		}
+	}
+	if int32(len(podList.Items)) != current || !kube.AreAllPodsReady(remaining) {
+		r.reqLogger.Info("Waiting for the pods to be ready to continue the scale down", "pods", len(podList.Items), "replicas", current)
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+	// The cluster view includes the zero-capacity pods too
+	zeroCapacityMembers, err := r.zeroCapacityMembers()
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	podName := remaining.Items[0].Name
+	members, err := cluster.GetClusterMembers(podName)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	var leavingMember string
+	for _, member := range members {
+		if isPodMember(member, leaving) {
+			leavingMember = member
+		}
+	}
+	remainingMembers := len(members)
+	if leavingMember != "" {
+		remainingMembers--
+	}
+	if remainingMembers != len(remaining.Items)+zeroCapacityMembers {
+		r.reqLogger.Info("Waiting for the cluster view to include all the pods to continue the scale down", "members", len(members))
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+	if blocker, err := scaleDownBlocker(cluster, podName); err != nil {
+		return &ctrl.Result{}, err
+	} else if blocker != "" {
+		r.reqLogger.Info("Waiting to continue the scale down", "reason", blocker)
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+
+	if leavingMember != "" && statefulSet.Annotations[ScaleDownLeavingAnnotation] != leaving {
+		if err := cluster.StopServer(leavingMember, podName); err != nil {
+			return &ctrl.Result{}, err
+		}
+		if statefulSet.Annotations == nil {
+			statefulSet.Annotations = map[string]string{}
+		}
+		statefulSet.Annotations[ScaleDownLeavingAnnotation] = leaving
+		if result, err := r.updateScaleDownStatefulSet(statefulSet); result != nil || err != nil {
+			return result, err
+		}
+		r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonScaleDown, fmt.Sprintf("Pod %s is leaving the cluster", leaving))
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+
+	delete(statefulSet.Annotations, ScaleDownLeavingAnnotation)
+	statefulSet.Spec.Replicas = pointer.Int32Ptr(current - 1)
+	if result, err := r.updateScaleDownStatefulSet(statefulSet); result != nil || err != nil {
+		return result, err
+	}
+	r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonScaleDown, fmt.Sprintf("Pod %s has left the cluster, scaling down from %d to %d pods", leaving, current, current-1))
+	return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+}
+
+// updateScaleDownStatefulSet updates the StatefulSet, requeuing the request on conflicts
+func (r *infinispanRequest) updateScaleDownStatefulSet(statefulSet *appsv1.StatefulSet) (*ctrl.Result, error) {
+	if err := r.Client.Update(r.ctx, statefulSet); err != nil {
+		if errors.IsConflict(err) {
+			return &ctrl.Result{Requeue: true}, nil
+		}
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
+
+// isPodMember returns true if the cluster member runs in the pod. The server names its node after the pod host name,
+// which JGroups can suffix with a random number
+func isPodMember(member, podName string) bool {
+	return member == podName || strings.HasPrefix(member, podName+"-")
+}
+
+// scaleDownBlocker returns why the cluster cannot lose a member yet, or an empty string. A member cannot leave while
+// the entries of a cache are being redistributed, or when rebalancing is disabled for a cache as the entries owned by
+// the leaving member would not be redistributed
+func scaleDownBlocker(cluster ispn.ClusterInterface, podName string) (string, error) {
+	cacheNames, err := cluster.CacheNames(podName)
+	if err != nil {
+		return "", err
+	}
+	for _, cacheName := range cacheNames {
+		details, err := cluster.GetCacheDetails(cacheName, podName)
+		if err != nil {
+			return "", err
+		}
+		if details.RehashInProgress {
+			return fmt.Sprintf("cache %s is rebalancing", cacheName), nil
+		}
+		if details.RebalancingEnabled != nil && !*details.RebalancingEnabled {
+			return fmt.Sprintf("rebalancing is disabled for cache %s", cacheName), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+// scaleDownCluster reports the members of the cluster and the rebalance state of a single cache, recording the stopped
+// members
+type scaleDownCluster struct {
+	ispn.ClusterInterface
+	members []string
+	details ispn.CacheDetails
+	stopped []string
+}
+
+func (c *scaleDownCluster) GetClusterMembers(podName string) ([]string, error) {
+	return c.members, nil
+}
+
+func (c *scaleDownCluster) CacheNames(podName string) ([]string, error) {
+	return []string{"books"}, nil
+}
+
+func (c *scaleDownCluster) GetCacheDetails(cacheName, podName string) (*ispn.CacheDetails, error) {
+	return &c.details, nil
+}
+
+func (c *scaleDownCluster) StopServer(memberName, podName string) error {
+	c.stopped = append(c.stopped, memberName)
+	return nil
+}
+
+func TestReconcileScaleDown(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32Ptr(3)},
+	}
+	r := dataMigrationRequest(t, nil, statefulSet.DeepCopy())
+	recorder := record.NewFakeRecorder(10)
+	r.eventRec = recorder
+	r.infinispan.Spec.Replicas = 1
+	assert.NoError(t, r.Client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "example-infinispan"}, statefulSet))
+
+	pods := &corev1.PodList{}
+	for _, name := range []string{"example-infinispan-0", "example-infinispan-1", "example-infinispan-2"} {
+		pods.Items = append(pods.Items, corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: InfinispanContainer, Ready: true}}},
+		})
+	}
+	view := []string{"example-infinispan-0-1021", "example-infinispan-1-3345", "example-infinispan-2-5270"}
+
+	// The pods leave one at a time, once the cache isn't rebalancing
+	cluster := &scaleDownCluster{members: view, details: ispn.CacheDetails{RehashInProgress: true}}
+	result, err := r.reconcileScaleDown(statefulSet, pods, cluster)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(3), *statefulSet.Spec.Replicas)
+	assert.Empty(t, cluster.stopped)
+
+	disabled := false
+	cluster = &scaleDownCluster{members: view, details: ispn.CacheDetails{RebalancingEnabled: &disabled}}
+	result, err = r.reconcileScaleDown(statefulSet, pods, cluster)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(3), *statefulSet.Spec.Replicas)
+	assert.Empty(t, cluster.stopped)
+
+	// The highest ordinal pod leaves the cluster through the server before the replicas are reduced
+	cluster = &scaleDownCluster{members: view}
+	result, err = r.reconcileScaleDown(statefulSet, pods, cluster)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(3), *statefulSet.Spec.Replicas)
+	assert.Equal(t, []string{"example-infinispan-2-5270"}, cluster.stopped)
+	assert.Equal(t, "example-infinispan-2", statefulSet.Annotations[ScaleDownLeavingAnnotation])
+	assert.Contains(t, <-recorder.Events, "Pod example-infinispan-2 is leaving the cluster")
+
+	// The stopped pod is not ready, the replicas are reduced once its entries have been redistributed
+	pods.Items[2].Status.ContainerStatuses[0].Ready = false
+	cluster = &scaleDownCluster{members: view[:2], details: ispn.CacheDetails{RehashInProgress: true}}
+	result, err = r.reconcileScaleDown(statefulSet, pods, cluster)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(3), *statefulSet.Spec.Replicas)
+
+	cluster = &scaleDownCluster{members: view[:2]}
+	result, err = r.reconcileScaleDown(statefulSet, pods, cluster)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(2), *statefulSet.Spec.Replicas)
+	assert.NotContains(t, statefulSet.Annotations, ScaleDownLeavingAnnotation)
+	assert.Empty(t, cluster.stopped)
+	assert.Contains(t, <-recorder.Events, "Pod example-infinispan-2 has left the cluster, scaling down from 3 to 2 pods")
+
+	// The next pod waits for the leaving pod to be removed
+	result, err = r.reconcileScaleDown(statefulSet, pods, &scaleDownCluster{members: view[:2]})
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(2), *statefulSet.Spec.Replicas)
+
+	// A crash-looping pod which is not part of the view is removed without waiting for it to be ready
+	pods.Items = pods.Items[:2]
+	pods.Items[1].Status.ContainerStatuses[0].Ready = false
+	result, err = r.reconcileScaleDown(statefulSet, pods, &scaleDownCluster{members: view[1:2]})
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(2), *statefulSet.Spec.Replicas)
+
+	cluster = &scaleDownCluster{members: view[:1]}
+	result, err = r.reconcileScaleDown(statefulSet, pods, cluster)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, int32(1), *statefulSet.Spec.Replicas)
+	assert.Empty(t, cluster.stopped)
+	assert.Contains(t, <-recorder.Events, "Pod example-infinispan-1 has left the cluster, scaling down from 2 to 1 pods")
+
+	pods.Items = pods.Items[:1]
+	result, err = r.reconcileScaleDown(statefulSet, pods, &scaleDownCluster{members: view[:1]})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	// Increasing spec.replicas during the scale down drops the leaving pod
+	statefulSet.Annotations = map[string]string{ScaleDownLeavingAnnotation: "example-infinispan-1"}
+	result, err = r.reconcileScaleDown(statefulSet, pods, &scaleDownCluster{members: view[:1]})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.NotContains(t, statefulSet.Annotations, ScaleDownLeavingAnnotation)
+
+	// Scaling down to 0 is a graceful shutdown
+	assert.False(t, isCoordinatedScaleDown(0, 3))
+	assert.False(t, isCoordinatedScaleDown(3, 3))
+	assert.True(t, isCoordinatedScaleDown(2, 3))
+}
+
+func TestIsPodMember(t *testing.T) {
+	assert.True(t, isPodMember("example-infinispan-1", "example-infinispan-1"))
+	assert.True(t, isPodMember("example-infinispan-1-3345", "example-infinispan-1"))
+	assert.False(t, isPodMember("example-infinispan-10", "example-infinispan-1"))
+	assert.False(t, isPodMember("example-infinispan-10-3345", "example-infinispan-1"))
+}
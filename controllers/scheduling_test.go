@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/version"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodAffinity(t *testing.T) {
+	labels := PodLabels("example-infinispan")
+	ispn := &ispnv1.Infinispan{}
+
+	// Distinct nodes and zones are preferred by default
+	affinity := podAffinity(ispn, labels)
+	assert.Nil(t, affinity.PodAffinity)
+	terms := affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	assert.Len(t, terms, 2)
+	assert.Equal(t, "kubernetes.io/hostname", terms[0].PodAffinityTerm.TopologyKey)
+	assert.Equal(t, "topology.kubernetes.io/zone", terms[1].PodAffinityTerm.TopologyKey)
+	assert.Equal(t, labels, terms[1].PodAffinityTerm.LabelSelector.MatchLabels)
+	assert.Empty(t, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+
+	ispn.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+	assert.Equal(t, ispn.Spec.Affinity, podAffinity(ispn, labels))
+}
+
+func TestArchitectureAffinity(t *testing.T) {
+	operands := version.Operands
+	defer func() { version.Operands = operands }()
+	version.Operands = version.Catalog{
+		{Version: "13.0.2", Image: "quay.io/infinispan/server:13.0.2"},
+		{Version: "14.0.1", Image: "quay.io/infinispan/server:14.0.1", Architectures: map[string]string{"arm64": "", "amd64": ""}},
+	}
+	labels := PodLabels("example-infinispan")
+	archRequirement := corev1.NodeSelectorRequirement{Key: "kubernetes.io/arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64", "arm64"}}
+
+	// No requirement when the catalog doesn't list the architectures of the image
+	ispn := &ispnv1.Infinispan{Spec: ispnv1.InfinispanSpec{Version: "13.0.2"}}
+	assert.Nil(t, podAffinity(ispn, labels).NodeAffinity)
+	assert.Nil(t, architectureAffinity(ispn, nil))
+
+	ispn.Spec.Version = "14.0.1"
+	affinity := podAffinity(ispn, labels)
+	assert.Len(t, affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 2)
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Len(t, terms, 1)
+	assert.Equal(t, []corev1.NodeSelectorRequirement{archRequirement}, terms[0].MatchExpressions)
+
+	// The requirement is added to each term of the user affinity, which is left unchanged
+	zoneRequirement := corev1.NodeSelectorRequirement{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}
+	ispn.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{zoneRequirement}},
+			{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node"}}}},
+		}},
+	}}
+	terms = podAffinity(ispn, labels).NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Equal(t, []corev1.NodeSelectorRequirement{zoneRequirement, archRequirement}, terms[0].MatchExpressions)
+	assert.Equal(t, []corev1.NodeSelectorRequirement{archRequirement}, terms[1].MatchExpressions)
+	assert.Len(t, ispn.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions, 1)
+}
+
+func TestIsLegacyDefaultAffinity(t *testing.T) {
+	labels := PodLabels("example-infinispan")
+	legacy := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+				Weight: 100,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: PodLabels("example-infinispan")},
+					TopologyKey:   "r.kubernetes.io/hostname",
+				},
+			}},
+		},
+	}
+	assert.True(t, isLegacyDefaultAffinity(legacy, labels))
+	assert.False(t, isLegacyDefaultAffinity(nil, labels))
+	assert.False(t, isLegacyDefaultAffinity(podAffinity(&ispnv1.Infinispan{}, labels), labels))
+
+	// User defined anti-affinity is kept
+	legacy.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].Weight = 50
+	assert.False(t, isLegacyDefaultAffinity(legacy, labels))
+}
+
+func TestTopologySpreadConstraints(t *testing.T) {
+	labels := PodLabels("example-infinispan")
+	assert.Nil(t, topologySpreadConstraints(&ispnv1.Infinispan{}, labels))
+
+	custom := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "custom"}}
+	ispn := &ispnv1.Infinispan{
+		Spec: ispnv1.InfinispanSpec{
+			Scheduling: &ispnv1.InfinispanSchedulingSpec{
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+					{MaxSkew: 1, TopologyKey: corev1.LabelZoneFailureDomainStable, WhenUnsatisfiable: corev1.DoNotSchedule},
+					{MaxSkew: 2, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: custom},
+				},
+			},
+		},
+	}
+	constraints := topologySpreadConstraints(ispn, labels)
+	assert.Len(t, constraints, 2)
+	assert.Equal(t, labels, constraints[0].LabelSelector.MatchLabels)
+	assert.Equal(t, custom, constraints[1].LabelSelector)
+	// The spec isn't modified
+	assert.Nil(t, ispn.Spec.Scheduling.TopologySpreadConstraints[0].LabelSelector)
+}
+
+func TestApplyPodScheduling(t *testing.T) {
+	spec := &corev1.PodSpec{}
+	ispn := &ispnv1.Infinispan{}
+	assert.False(t, applyPodScheduling(ispn, spec))
+
+	ispn.Spec.Scheduling = &ispnv1.InfinispanSchedulingSpec{
+		Tolerations:       []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "infinispan", Effect: corev1.TaintEffectNoSchedule}},
+		NodeSelector:      map[string]string{"pool": "infinispan"},
+		PriorityClassName: "high-priority",
+	}
+	assert.True(t, applyPodScheduling(ispn, spec))
+	assert.Equal(t, ispn.Spec.Scheduling.Tolerations, spec.Tolerations)
+	assert.Equal(t, map[string]string{"pool": "infinispan"}, spec.NodeSelector)
+	assert.Equal(t, "high-priority", spec.PriorityClassName)
+	assert.False(t, applyPodScheduling(ispn, spec))
+
+	// Removing the settings unpins the pods
+	ispn.Spec.Scheduling = &ispnv1.InfinispanSchedulingSpec{}
+	assert.True(t, applyPodScheduling(ispn, spec))
+	assert.Nil(t, spec.Tolerations)
+	assert.Nil(t, spec.NodeSelector)
+	assert.Empty(t, spec.PriorityClassName)
+}
@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	EventReasonServerTaskDeployed = "ServerTaskDeployed"
+
+	// ServerTaskConfigMapField is the ServerTask field indexed to lookup the ServerTasks referencing a ConfigMap
+	ServerTaskConfigMapField = "spec.scriptConfigMap.name"
+)
+
+// ServerTaskReconciler reconciles a ServerTask object
+type ServerTaskReconciler struct {
+	client.Client
+	log        logr.Logger
+	scheme     *runtime.Scheme
+	kubernetes *kube.Kubernetes
+	eventRec   record.EventRecorder
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServerTaskReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Client = mgr.GetClient()
+	r.log = ctrl.Log.WithName("controllers").WithName("ServerTask")
+	r.scheme = mgr.GetScheme()
+	r.kubernetes = kube.NewKubernetesFromController(mgr)
+	r.eventRec = mgr.GetEventRecorderFor("servertask-controller")
+
+	ctx := context.TODO()
+	// Add the ConfigMap name to the index, so that the ServerTasks referencing an updated script are reconciled
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &infinispanv2alpha1.ServerTask{}, ServerTaskConfigMapField, func(obj client.Object) []string {
+		if configMap := obj.(*infinispanv2alpha1.ServerTask).Spec.ScriptConfigMap; configMap != nil {
+			return []string{configMap.Name}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infinispanv2alpha1.ServerTask{}).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(
+				func(a client.Object) []reconcile.Request {
+					var requests []reconcile.Request
+					taskList := &infinispanv2alpha1.ServerTaskList{}
+					if err := r.kubernetes.ResourcesListByField(a.GetNamespace(), ServerTaskConfigMapField, a.GetName(), taskList, ctx); err != nil {
+						r.log.Error(err, "failed to list ServerTask CR")
+					}
+					for _, item := range taskList.Items {
+						requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}})
+					}
+					return requests
+				}),
+		).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infinispan.org,resources=servertasks;servertasks/status;servertasks/finalizers,verbs=get;list;watch;create;update;patch
+
+func (r *ServerTaskReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("+++++ Reconciling ServerTask.")
+	defer reqLogger.Info("----- End Reconciling ServerTask.")
+
+	// Fetch the ServerTask instance
+	instance := &infinispanv2alpha1.ServerTask{}
+	if err := r.Client.Get(ctx, request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.Info("ServerTask resource not found. Ignoring it since task deletion is not supported")
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	updateStatus := func(status metav1.ConditionStatus, message string) error {
+		if !instance.SetCondition(infinispanv2alpha1.ServerTaskConditionReady, status, message) {
+			return nil
+		}
+		return r.Client.Status().Update(ctx, instance)
+	}
+
+	if err := validateServerTask(instance); err != nil {
+		reqLogger.Error(err, "Invalid ServerTask")
+		return reconcile.Result{}, updateStatus(metav1.ConditionFalse, err.Error())
+	}
+
+	// Fetch the Infinispan cluster info
+	ispnInstance := &infinispanv1.Infinispan{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.ClusterName}, ispnInstance); err != nil {
+		if errors.IsNotFound(err) {
+			reqLogger.Info(fmt.Sprintf("Infinispan cluster %s not found", instance.Spec.ClusterName))
+			return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// Cluster must be well formed
+	if !ispnInstance.IsWellFormed() {
+		reqLogger.Info(fmt.Sprintf("Infinispan cluster %s not well formed", ispnInstance.Name))
+		return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+	}
+	podList, err := PodList(ispnInstance, r.kubernetes, ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to list pods")
+		return reconcile.Result{}, err
+	} else if len(podList.Items) == 0 {
+		reqLogger.Info("No Infinispan pods found")
+		return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, nil
+	}
+	podName := podList.Items[0].Name
+
+	var script string
+	if instance.GetType() == infinispanv2alpha1.ServerTaskScript {
+		if script, err = r.taskScript(ctx, instance); err != nil {
+			if errors.IsNotFound(err) {
+				reqLogger.Info(fmt.Sprintf("ConfigMap %s not found", instance.Spec.ScriptConfigMap.Name))
+				return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, updateStatus(metav1.ConditionFalse, err.Error())
+			}
+			reqLogger.Error(err, "Invalid task script")
+			if statusErr := updateStatus(metav1.ConditionFalse, err.Error()); statusErr != nil {
+				return reconcile.Result{}, statusErr
+			}
+			return reconcile.Result{}, err
+		}
+	}
+
+	cluster, err := NewCluster(ispnInstance, r.kubernetes, ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	status := instance.Status.DeepCopy()
+	registered, err := r.deployServerTask(instance, script, cluster, podName)
+	if err != nil {
+		reqLogger.Error(err, "Error deploying task")
+		return reconcile.Result{}, err
+	}
+	if registered == nil {
+		// Java tasks are registered when the server loads the deployed artifacts
+		message := fmt.Sprintf("Java task %s is not deployed on the cluster", instance.GetTaskName())
+		return reconcile.Result{RequeueAfter: constants.DefaultWaitOnCluster}, updateStatus(metav1.ConditionFalse, message)
+	}
+
+	instance.Status.ExecutionMode = registered.ExecutionMode
+	instance.Status.AllowedRole = ""
+	if registered.AllowedRole != nil {
+		instance.Status.AllowedRole = *registered.AllowedRole
+	}
+	instance.SetCondition(infinispanv2alpha1.ServerTaskConditionReady, metav1.ConditionTrue, "")
+	if !reflect.DeepEqual(status, &instance.Status) {
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			reqLogger.Error(err, fmt.Sprintf("Unable to update ServerTask %s status", instance.Name))
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// taskScript returns the JavaScript source of the task, with the metadata of the spec
+func (r *ServerTaskReconciler) taskScript(ctx context.Context, task *infinispanv2alpha1.ServerTask) (string, error) {
+	script := ""
+	if task.Spec.Script != nil {
+		script = *task.Spec.Script
+	} else {
+		selector := task.Spec.ScriptConfigMap
+		configMap := &corev1.ConfigMap{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: task.Namespace, Name: selector.Name}, configMap); err != nil {
+			return "", err
+		}
+		var ok bool
+		if script, ok = configMap.Data[selector.Key]; !ok {
+			return "", fmt.Errorf("key '%s' not found in ConfigMap %s", selector.Key, selector.Name)
+		}
+	}
+	if task.Spec.Role != "" {
+		if strings.HasPrefix(strings.TrimSpace(script), "//") {
+			// The server reads the metadata from the first comment of the script
+			return "", fmt.Errorf("role cannot be set when the script starts with a metadata comment")
+		}
+		script = fmt.Sprintf("// role=%s\n%s", task.Spec.Role, script)
+	}
+	return script, nil
+}
+
+// deployServerTask uploads the script of a Script task to the cluster when it changed, returning the registered task.
+// A nil task is returned when the task isn't registered on the cluster
+func (r *ServerTaskReconciler) deployServerTask(task *infinispanv2alpha1.ServerTask, script string, cluster ispn.ClusterInterface, podName string) (*ispn.Task, error) {
+	registered, err := registeredTask(task.GetTaskName(), cluster, podName)
+	if err != nil || task.GetType() == infinispanv2alpha1.ServerTaskJava {
+		return registered, err
+	}
+
+	scriptHash := hash.HashString(script)
+	if registered != nil && task.Status.ScriptHash == scriptHash {
+		return registered, nil
+	}
+	if err := cluster.UploadScript(task.GetTaskName(), script, podName); err != nil {
+		return nil, err
+	}
+	r.eventRec.Event(task, corev1.EventTypeNormal, EventReasonServerTaskDeployed, fmt.Sprintf("Script task %s deployed", task.GetTaskName()))
+	task.Status.ScriptHash = scriptHash
+	return registeredTask(task.GetTaskName(), cluster, podName)
+}
+
+// registeredTask returns the task registered on the cluster with the name `taskName`, or nil if it doesn't exist
+func registeredTask(taskName string, cluster ispn.ClusterInterface, podName string) (*ispn.Task, error) {
+	tasks, err := cluster.GetTasks(podName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].Name == taskName {
+			return &tasks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// validateServerTask verifies that Script tasks define their source once, and that Java tasks don't define one
+func validateServerTask(task *infinispanv2alpha1.ServerTask) error {
+	spec := task.Spec
+	if task.GetType() == infinispanv2alpha1.ServerTaskJava {
+		if spec.Script != nil || spec.ScriptConfigMap != nil || spec.Role != "" {
+			return fmt.Errorf("script, scriptConfigMap and role are only supported by Script tasks")
+		}
+		return nil
+	}
+	if (spec.Script == nil) == (spec.ScriptConfigMap == nil) {
+		return fmt.Errorf("exactly one of script and scriptConfigMap must be defined")
+	}
+	return nil
+}
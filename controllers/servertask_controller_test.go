@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// taskCluster registers the uploaded scripts, the remaining methods are not expected to be called
+type taskCluster struct {
+	ispn.ClusterInterface
+	tasks   []ispn.Task
+	uploads []string
+}
+
+func (c *taskCluster) GetTasks(podName string) ([]ispn.Task, error) {
+	return c.tasks, nil
+}
+
+func (c *taskCluster) UploadScript(taskName, script, podName string) error {
+	c.uploads = append(c.uploads, script)
+	for _, task := range c.tasks {
+		if task.Name == taskName {
+			return nil
+		}
+	}
+	c.tasks = append(c.tasks, ispn.Task{Name: taskName, Type: "Script", ExecutionMode: "ONE_NODE"})
+	return nil
+}
+
+func TestValidateServerTask(t *testing.T) {
+	task := &v2alpha1.ServerTask{}
+	assert.EqualError(t, validateServerTask(task), "exactly one of script and scriptConfigMap must be defined")
+
+	task.Spec.Script = pointer.StringPtr("cache.size()")
+	assert.NoError(t, validateServerTask(task))
+	task.Spec.ScriptConfigMap = &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "scripts"}, Key: "size.js"}
+	assert.Error(t, validateServerTask(task))
+
+	task.Spec.Type = v2alpha1.ServerTaskJava
+	assert.Error(t, validateServerTask(task))
+	task.Spec.Script, task.Spec.ScriptConfigMap = nil, nil
+	assert.NoError(t, validateServerTask(task))
+}
+
+func TestTaskScript(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "scripts", Namespace: "task-namespace"}, Data: map[string]string{"size.js": "cache.size()"}}
+	r := &ServerTaskReconciler{Client: fake.NewFakeClientWithScheme(scheme, configMap)}
+
+	task := &v2alpha1.ServerTask{ObjectMeta: metav1.ObjectMeta{Namespace: "task-namespace"}}
+	task.Spec.ScriptConfigMap = &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "scripts"}, Key: "size.js"}
+	script, err := r.taskScript(context.TODO(), task)
+	assert.NoError(t, err)
+	assert.Equal(t, "cache.size()", script)
+
+	task.Spec.Role = "admin"
+	script, err = r.taskScript(context.TODO(), task)
+	assert.NoError(t, err)
+	assert.Equal(t, "// role=admin\ncache.size()", script)
+
+	// The role can't be merged with the metadata of the script
+	configMap.Data["size.js"] = "// mode=local,language=javascript\ncache.size()"
+	assert.NoError(t, r.Client.Update(context.TODO(), configMap))
+	_, err = r.taskScript(context.TODO(), task)
+	assert.Error(t, err)
+
+	task.Spec.ScriptConfigMap.Key = "missing.js"
+	_, err = r.taskScript(context.TODO(), task)
+	assert.EqualError(t, err, "key 'missing.js' not found in ConfigMap scripts")
+}
+
+func TestDeployServerTask(t *testing.T) {
+	cluster := &taskCluster{}
+	r := &ServerTaskReconciler{eventRec: record.NewFakeRecorder(10)}
+	task := &v2alpha1.ServerTask{ObjectMeta: metav1.ObjectMeta{Name: "size"}}
+
+	registered, err := r.deployServerTask(task, "cache.size()", cluster, "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, "size", registered.Name)
+	assert.Equal(t, hash.HashString("cache.size()"), task.Status.ScriptHash)
+	assert.Equal(t, []string{"cache.size()"}, cluster.uploads)
+
+	// The script is only uploaded again when it changes
+	_, err = r.deployServerTask(task, "cache.size()", cluster, "pod")
+	assert.NoError(t, err)
+	assert.Len(t, cluster.uploads, 1)
+	_, err = r.deployServerTask(task, "cache.keySet()", cluster, "pod")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache.size()", "cache.keySet()"}, cluster.uploads)
+
+	// Java tasks are never uploaded
+	task = &v2alpha1.ServerTask{ObjectMeta: metav1.ObjectMeta{Name: "java-task"}, Spec: v2alpha1.ServerTaskSpec{Type: v2alpha1.ServerTaskJava}}
+	registered, err = r.deployServerTask(task, "", cluster, "pod")
+	assert.NoError(t, err)
+	assert.Nil(t, registered)
+	assert.Len(t, cluster.uploads, 2)
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	EventReasonSplitBrain       = "SplitBrain"
+	EventReasonSplitBrainHealed = "SplitBrainHealed"
+)
+
+// clusterPartitions returns the partitions of the cluster, given the members of the view reported by each pod. The
+// cluster is only partitioned when the views are disjoint, as overlapping views are reported while a view change is
+// being installed. Returns nil when the cluster isn't partitioned
+func clusterPartitions(views [][]string) []string {
+	partitions := make(map[string]bool)
+	members := make(map[string]bool)
+	for _, view := range views {
+		sorted := append([]string{}, view...)
+		sort.Strings(sorted)
+		partition := "[" + strings.Join(sorted, ",") + "]"
+		if partitions[partition] {
+			continue
+		}
+		partitions[partition] = true
+		for _, member := range sorted {
+			if members[member] {
+				return nil
+			}
+			members[member] = true
+		}
+	}
+	if len(partitions) < 2 {
+		return nil
+	}
+	result := make([]string, 0, len(partitions))
+	for partition := range partitions {
+		result = append(result, partition)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// reconcileSplitBrain compares the views reported by the health endpoint of the pods, setting the SplitBrain condition
+// while the cluster is partitioned. A Warning event is emitted when the split is detected, and a Normal event when the
+// partitions have merged
+func (r *infinispanRequest) reconcileSplitBrain(podList *corev1.PodList, cluster ispn.ClusterInterface) error {
+	infinispan := r.infinispan
+	// The views of pods that are not ready are not meaningful, e.g. while they join the cluster
+	if len(podList.Items) < 2 || !kube.AreAllPodsReady(podList) {
+		return nil
+	}
+	views := make([][]string, 0, len(podList.Items))
+	for _, pod := range podList.Items {
+		health, err := cluster.GetClusterHealth(pod.Name)
+		if err != nil {
+			r.reqLogger.Error(err, "unable to retrieve the cluster health of the pod", "pod", pod.Name)
+			return nil
+		}
+		views = append(views, health.Nodes)
+	}
+
+	splitBrain := infinispan.IsConditionTrue(infinispanv1.ConditionSplitBrain)
+	partitions := clusterPartitions(views)
+	if partitions == nil {
+		if splitBrain {
+			r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonSplitBrainHealed, "The cluster partitions have merged")
+		}
+		return r.update(func() {
+			infinispan.SetCondition(infinispanv1.ConditionSplitBrain, metav1.ConditionFalse, infinispanv1.ReasonSingleView, "")
+		})
+	}
+	message := fmt.Sprintf("Partitions: %s", strings.Join(partitions, " "))
+	if !splitBrain {
+		whenSplit, mergePolicy := infinispan.GetPartitionHandling()
+		r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonSplitBrain, fmt.Sprintf("The cluster is split into %d partitions, caches created by the operator behave according to %s and resolve conflicts with %s when they merge", len(partitions), whenSplit, mergePolicy))
+	}
+	return r.update(func() {
+		infinispan.SetCondition(infinispanv1.ConditionSplitBrain, metav1.ConditionTrue, infinispanv1.ReasonSplitBrain, message)
+	})
+}
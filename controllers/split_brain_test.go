@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// viewsCluster reports the view of each pod
+type viewsCluster struct {
+	ispn.ClusterInterface
+	views map[string][]string
+}
+
+func (c *viewsCluster) GetClusterHealth(podName string) (*ispn.ClusterHealth, error) {
+	return &ispn.ClusterHealth{Nodes: c.views[podName], Status: ispn.ClusterHealthHealthy}, nil
+}
+
+func TestClusterPartitions(t *testing.T) {
+	assert.Nil(t, clusterPartitions(nil))
+	assert.Nil(t, clusterPartitions([][]string{{"a", "b", "c"}, {"c", "b", "a"}, {"a", "b", "c"}}))
+	// Overlapping views are reported while a view change is installed
+	assert.Nil(t, clusterPartitions([][]string{{"a", "b", "c"}, {"a", "b"}}))
+	assert.Equal(t, []string{"[a,b]", "[c]"}, clusterPartitions([][]string{{"b", "a"}, {"a", "b"}, {"c"}}))
+}
+
+func TestReconcileSplitBrain(t *testing.T) {
+	r := dataMigrationRequest(t, nil)
+	recorder := record.NewFakeRecorder(10)
+	r.eventRec = recorder
+	infinispan := r.infinispan
+	ready := corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: InfinispanContainer, Ready: true}}}
+	pods := &corev1.PodList{}
+	for _, name := range []string{"example-infinispan-0", "example-infinispan-1", "example-infinispan-2"} {
+		pods.Items = append(pods.Items, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: ready})
+	}
+
+	split := &viewsCluster{views: map[string][]string{
+		"example-infinispan-0": {"example-infinispan-0", "example-infinispan-1"},
+		"example-infinispan-1": {"example-infinispan-0", "example-infinispan-1"},
+		"example-infinispan-2": {"example-infinispan-2"},
+	}}
+	assert.NoError(t, r.reconcileSplitBrain(pods, split))
+	condition := infinispan.GetCondition(infinispanv1.ConditionSplitBrain)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, "Partitions: [example-infinispan-0,example-infinispan-1] [example-infinispan-2]", condition.Message)
+	assert.True(t, infinispan.IsConditionTrue(infinispanv1.ConditionDegraded))
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, EventReasonSplitBrain)
+
+	// The event is only emitted when the split is detected
+	assert.NoError(t, r.reconcileSplitBrain(pods, split))
+	assert.Empty(t, recorder.Events)
+
+	merged := []string{"example-infinispan-0", "example-infinispan-1", "example-infinispan-2"}
+	assert.NoError(t, r.reconcileSplitBrain(pods, &viewsCluster{views: map[string][]string{
+		"example-infinispan-0": merged,
+		"example-infinispan-1": merged,
+		"example-infinispan-2": merged,
+	}}))
+	assert.Equal(t, metav1.ConditionFalse, infinispan.GetCondition(infinispanv1.ConditionSplitBrain).Status)
+	assert.False(t, infinispan.IsConditionTrue(infinispanv1.ConditionDegraded))
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, EventReasonSplitBrainHealed)
+}
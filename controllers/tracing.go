@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/infinispan/infinispan-operator/pkg/tracing"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	TracingCaVolumeName = "tracing-ca-volume"
+	// TracingCaKey is the key of the CA certificate in the TLS Secret of the collector
+	TracingCaKey = "ca.crt"
+	// TracingExporterProtocol is the protocol the server exports its spans with, the one of the OTLP/HTTP endpoint
+	TracingExporterProtocol = "http/protobuf"
+	// OperatorTracingServiceName is the service name of the spans of the operator
+	OperatorTracingServiceName = "infinispan-operator"
+)
+
+// clusterTracer is the operator tracer of a cluster, along with the configuration it was created with
+type clusterTracer struct {
+	config string
+	tracer *tracing.Tracer
+}
+
+// tracers caches the operator tracer of each cluster, so that the HTTP connections to the collector are reused
+var tracers = struct {
+	sync.Mutex
+	m map[types.NamespacedName]clusterTracer
+}{m: make(map[types.NamespacedName]clusterTracer)}
+
+// loadTracer returns the tracer of the cluster, replacing the cached tracer when its configuration changed
+func loadTracer(name types.NamespacedName, config string, newTracer func() *tracing.Tracer) *tracing.Tracer {
+	tracers.Lock()
+	defer tracers.Unlock()
+	if cached, ok := tracers.m[name]; ok {
+		if cached.config == config {
+			return cached.tracer
+		}
+		cached.tracer.Close()
+	}
+	tracer := newTracer()
+	tracers.m[name] = clusterTracer{config: config, tracer: tracer}
+	return tracer
+}
+
+// forgetTracer removes the tracer of a cluster that was deleted or is no longer traced
+func forgetTracer(name types.NamespacedName) {
+	tracers.Lock()
+	defer tracers.Unlock()
+	if cached, ok := tracers.m[name]; ok {
+		cached.tracer.Close()
+		delete(tracers.m, name)
+	}
+}
+
+// validateTracing verifies the collector endpoint and the sampling ratio of the tracing spec
+func validateTracing(i *ispnv1.Infinispan) error {
+	spec := i.Spec.Tracing
+	if spec == nil {
+		return nil
+	}
+	endpoint, err := url.Parse(spec.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("infinispan.spec.tracing.endpoint '%s' must be a http:// or https:// URL", spec.Endpoint)
+	}
+	if spec.TLSSecretName != "" && endpoint.Scheme != "https" {
+		return fmt.Errorf("infinispan.spec.tracing.tlsSecretName requires a https:// endpoint")
+	}
+	_, err = tracingSamplingRatio(spec)
+	return err
+}
+
+// tracingSamplingRatio returns the ratio of the traces sampled, 1 when not specified
+func tracingSamplingRatio(spec *ispnv1.InfinispanTracingSpec) (float64, error) {
+	if spec.SamplingRatio == "" {
+		return 1, nil
+	}
+	ratio, err := strconv.ParseFloat(spec.SamplingRatio, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return 0, fmt.Errorf("infinispan.spec.tracing.samplingRatio '%s' must be a number between 0 and 1", spec.SamplingRatio)
+	}
+	return ratio, nil
+}
+
+// configureTracing renders the tracing configuration of the server, once the CA Secret of the collector is available
+func (r configRequest) configureTracing(c *config.InfinispanConfiguration) (*reconcile.Result, error) {
+	i := r.infinispan
+	spec := i.Spec.Tracing
+	if spec == nil {
+		return nil, nil
+	}
+	if _, result, err := r.caTLSConfig(spec.TLSSecretName, TracingCaKey); result != nil {
+		return result, err
+	}
+	ratio, err := tracingSamplingRatio(spec)
+	if err != nil {
+		return &reconcile.Result{}, err
+	}
+	c.Infinispan.Tracing = &config.Tracing{
+		CollectorEndpoint: spec.Endpoint,
+		ExporterProtocol:  TracingExporterProtocol,
+		ServiceName:       consts.GetWithDefault(spec.ServiceName, i.Name),
+		SamplingRatio:     ratio,
+	}
+	if spec.TLSSecretName != "" {
+		c.Infinispan.Tracing.CaPath = consts.ServerTracingCaRoot + "/" + TracingCaKey
+	}
+	return nil, nil
+}
+
+// AddVolumesForTracing mounts the CA Secret of the collector in the server container
+func AddVolumesForTracing(i *ispnv1.Infinispan, spec *corev1.PodSpec) {
+	if secretName := i.Spec.Tracing.TLSSecretName; secretName != "" {
+		addSecretVolume(secretName, TracingCaVolumeName, consts.ServerTracingCaRoot, spec)
+	}
+}
+
+// startTrace starts the trace of the reconcile loop started at `start`, exported to the collector of the tracing spec.
+// It returns nil when the tracing is not configured or the CA Secret of the collector is not available
+func (r *infinispanRequest) startTrace(start time.Time) *tracing.Trace {
+	name := types.NamespacedName{Namespace: r.infinispan.Namespace, Name: r.infinispan.Name}
+	spec := r.infinispan.Spec.Tracing
+	if spec == nil {
+		forgetTracer(name)
+		return nil
+	}
+	ratio, err := tracingSamplingRatio(spec)
+	if err != nil {
+		return nil
+	}
+	var tlsConfig *tls.Config
+	var ca []byte
+	if spec.TLSSecretName != "" {
+		secret := &corev1.Secret{}
+		if err := r.Client.Get(r.ctx, types.NamespacedName{Namespace: r.infinispan.Namespace, Name: spec.TLSSecretName}, secret); err != nil {
+			r.reqLogger.Info("Unable to retrieve the CA Secret of the tracing collector, the reconcile loop is not traced", "secret", spec.TLSSecretName)
+			return nil
+		}
+		ca = secret.Data[TracingCaKey]
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil
+		}
+		tlsConfig = &tls.Config{RootCAs: pool}
+	}
+
+	tracerConfig := fmt.Sprintf("%s|%f|%s", spec.Endpoint, ratio, hash.HashByte(ca))
+	tracer := loadTracer(name, tracerConfig, func() *tracing.Tracer {
+		return tracing.NewTracer(spec.Endpoint, OperatorTracingServiceName, ratio, tlsConfig, r.log.WithName("tracing"))
+	})
+	return tracer.StartTrace(ReconcileHandler, start, map[string]string{
+		"k8s.namespace.name": r.infinispan.Namespace,
+		"infinispan.cluster": r.infinispan.Name,
+	})
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/infinispan/infinispan-operator/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func tracingInfinispan(tracing *ispnv1.InfinispanTracingSpec) *ispnv1.Infinispan {
+	return &ispnv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing", CreationTimestamp: metav1.Now()},
+		Spec:       ispnv1.InfinispanSpec{Tracing: tracing},
+	}
+}
+
+func TestValidateTracing(t *testing.T) {
+	assert.NoError(t, validateTracing(tracingInfinispan(nil)))
+	assert.NoError(t, validateTracing(tracingInfinispan(&ispnv1.InfinispanTracingSpec{Endpoint: "http://otel-collector:4318", SamplingRatio: "0.25"})))
+	assert.EqualError(t, validateTracing(tracingInfinispan(&ispnv1.InfinispanTracingSpec{Endpoint: "otel-collector:4318"})), "infinispan.spec.tracing.endpoint 'otel-collector:4318' must be a http:// or https:// URL")
+	assert.EqualError(t, validateTracing(tracingInfinispan(&ispnv1.InfinispanTracingSpec{Endpoint: "http://otel-collector:4318", TLSSecretName: "otel-ca"})), "infinispan.spec.tracing.tlsSecretName requires a https:// endpoint")
+	assert.EqualError(t, validateTracing(tracingInfinispan(&ispnv1.InfinispanTracingSpec{Endpoint: "http://otel-collector:4318", SamplingRatio: "1.5"})), "infinispan.spec.tracing.samplingRatio '1.5' must be a number between 0 and 1")
+}
+
+func TestConfigureTracing(t *testing.T) {
+	ispn := tracingInfinispan(&ispnv1.InfinispanTracingSpec{Endpoint: "https://otel-collector:4318", SamplingRatio: "0.5", TLSSecretName: "otel-ca"})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "otel-ca", Namespace: "testing"},
+		Data:       map[string][]byte{TracingCaKey: selfSignedCert(t, "otel-collector")},
+	}
+	c, scheme := transportEncryptionClient(t, ispn, secret)
+	r := configRequest{
+		ConfigReconciler: &ConfigReconciler{Client: c, scheme: scheme, eventRec: record.NewFakeRecorder(10)},
+		infinispan:       ispn,
+		reqLogger:        ctrl.Log,
+		ctx:              context.TODO(),
+	}
+
+	serverConf := &config.InfinispanConfiguration{}
+	result, err := r.configureTracing(serverConf)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.Tracing{
+		CollectorEndpoint: "https://otel-collector:4318",
+		ExporterProtocol:  "http/protobuf",
+		ServiceName:       "example-infinispan",
+		SamplingRatio:     0.5,
+		CaPath:            "/etc/security/tracing-ca/ca.crt",
+	}, serverConf.Infinispan.Tracing)
+
+	spec := &corev1.PodSpec{Containers: []corev1.Container{{}}}
+	AddVolumesForTracing(ispn, spec)
+	assert.Equal(t, "otel-ca", spec.Volumes[0].Secret.SecretName)
+}
+
+func TestLoadTracer(t *testing.T) {
+	name := types.NamespacedName{Namespace: "testing", Name: "example-infinispan"}
+	created := 0
+	newTracer := func() *tracing.Tracer {
+		created++
+		return tracing.NewTracer("http://otel-collector:4318", OperatorTracingServiceName, 1, nil, ctrl.Log)
+	}
+	defer forgetTracer(name)
+
+	tracer := loadTracer(name, "config", newTracer)
+	assert.Same(t, tracer, loadTracer(name, "config", newTracer))
+	assert.Equal(t, 1, created)
+
+	// A changed configuration replaces the tracer of the cluster
+	assert.NotSame(t, tracer, loadTracer(name, "changed", newTracer))
+	assert.Equal(t, 2, created)
+	assert.Len(t, tracers.m, 1)
+
+	forgetTracer(name)
+	assert.Empty(t, tracers.m)
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"fmt"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/security"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	TransportKeystoreVolumeName  = "transport-keystore-volume"
+	TransportKeystorePasswordKey = "password"
+	TransportKeystoreAliasKey    = "alias"
+)
+
+// validateTransportEncryption verifies that a keystore holding the secret key is provided for SYM_ENCRYPT, as the
+// operator only generates the keystore authenticating the members for ASYM_ENCRYPT
+func validateTransportEncryption(i *ispnv1.Infinispan) error {
+	if i.IsGeneratedTransportKeystore() && i.GetTransportEncryptionType() == ispnv1.TransportEncryptionSym {
+		return fmt.Errorf("infinispan.spec.security.transportEncryption.secretName must be provided for type=%s", ispnv1.TransportEncryptionSym)
+	}
+	return nil
+}
+
+// reconcileTransportKeystoreSecret generates the keystore shared by the pods for ASYM_ENCRYPT. The keystore is never
+// regenerated, as pods holding different keystores can't form a cluster
+func (s *secretRequest) reconcileTransportKeystoreSecret() error {
+	i := s.infinispan
+	if !i.IsGeneratedTransportKeystore() {
+		return nil
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.GetTransportKeystoreSecretName(),
+			Namespace: i.Namespace,
+		},
+	}
+	result, err := k8sctrlutil.CreateOrUpdate(s.ctx, s.Client, secret, func() error {
+		if _, ok := secret.Data[EncryptKeystoreName]; ok {
+			ApplyPropagatedMetadata(i, secret)
+			return nil
+		}
+		password, err := security.NewPassword()
+		if err != nil {
+			return err
+		}
+		keystore, err := security.GenerateKeystore(i.Name, password)
+		if err != nil {
+			return err
+		}
+		secret.Labels = LabelsResource(i.Name, "infinispan-secret-transport-keystore")
+		secret.Type = corev1.SecretTypeOpaque
+		secret.Data = map[string][]byte{
+			EncryptKeystoreName:          keystore,
+			TransportKeystorePasswordKey: []byte(password),
+		}
+		ApplyPropagatedMetadata(i, secret)
+		return k8sctrlutil.SetControllerReference(i, secret, s.scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create the transport keystore secret: %w", err)
+	}
+	if result == k8sctrlutil.OperationResultCreated {
+		s.reqLogger.Info(fmt.Sprintf("Created transport keystore Secret %s", secret.Name))
+	}
+	return nil
+}
+
+// configureTransportEncryption configures the JGroups protocol encrypting the cluster traffic with the keystore
+// mounted from its Secret
+func (r configRequest) configureTransportEncryption(c *config.InfinispanConfiguration) (*reconcile.Result, error) {
+	i := r.infinispan
+	if !i.IsTransportEncryptionEnabled() {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if result, err := kube.LookupResource(i.GetTransportKeystoreSecretName(), i.Namespace, secret, i, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+		return result, err
+	}
+	requiredKeys := []string{EncryptKeystoreName, TransportKeystorePasswordKey}
+	if i.GetTransportEncryptionType() == ispnv1.TransportEncryptionSym {
+		requiredKeys = append(requiredKeys, TransportKeystoreAliasKey)
+	}
+	for _, key := range requiredKeys {
+		if _, ok := secret.Data[key]; !ok {
+			return &reconcile.Result{}, fmt.Errorf("the transport keystore Secret '%s' must contain a '%s' key", secret.Name, key)
+		}
+	}
+
+	c.JGroups.Encryption = &config.JGroupsEncryption{
+		Protocol: string(i.GetTransportEncryptionType()),
+		Keystore: config.Keystore{
+			Path:     fmt.Sprintf("%s/%s", consts.ServerEncryptTransportRoot, EncryptKeystoreName),
+			Password: string(secret.Data[TransportKeystorePasswordKey]),
+			Alias:    string(secret.Data[TransportKeystoreAliasKey]),
+		},
+	}
+	return nil, nil
+}
+
+// AddVolumeForTransportEncryption mounts the transport keystore Secret in the server container
+func AddVolumeForTransportEncryption(i *ispnv1.Infinispan, spec *corev1.PodSpec) {
+	addSecretVolume(i.GetTransportKeystoreSecretName(), TransportKeystoreVolumeName, consts.ServerEncryptTransportRoot, spec)
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	p12 "software.sslmate.com/src/go-pkcs12"
+)
+
+func transportEncryptionInfinispan(encryption *ispnv1.TransportEncryption) *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing", UID: "uid"}}
+	ispn.Spec.Security.TransportEncryption = encryption
+	return ispn
+}
+
+func transportEncryptionClient(t *testing.T, objs ...runtime.Object) (client.Client, *runtime.Scheme) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, ispnv1.AddToScheme(scheme))
+	return fake.NewFakeClientWithScheme(scheme, objs...), scheme
+}
+
+func TestValidateTransportEncryption(t *testing.T) {
+	assert.NoError(t, validateTransportEncryption(transportEncryptionInfinispan(nil)))
+	assert.NoError(t, validateTransportEncryption(transportEncryptionInfinispan(&ispnv1.TransportEncryption{})))
+	assert.Error(t, validateTransportEncryption(transportEncryptionInfinispan(&ispnv1.TransportEncryption{Type: ispnv1.TransportEncryptionSym})))
+	assert.NoError(t, validateTransportEncryption(transportEncryptionInfinispan(&ispnv1.TransportEncryption{Type: ispnv1.TransportEncryptionSym, SecretName: "keystore"})))
+}
+
+func TestReconcileTransportKeystoreSecret(t *testing.T) {
+	ispn := transportEncryptionInfinispan(&ispnv1.TransportEncryption{})
+	c, scheme := transportEncryptionClient(t)
+	s := &secretRequest{
+		SecretReconciler: &SecretReconciler{Client: c, scheme: scheme},
+		infinispan:       ispn,
+		reqLogger:        ctrl.Log,
+		ctx:              context.TODO(),
+	}
+	assert.NoError(t, s.reconcileTransportKeystoreSecret())
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "testing", Name: "example-infinispan-transport-keystore"}, secret))
+	password := string(secret.Data[TransportKeystorePasswordKey])
+	key, cert, err := p12.Decode(secret.Data[EncryptKeystoreName], password)
+	assert.NoError(t, err)
+	assert.NotNil(t, key)
+	assert.Equal(t, "example-infinispan", cert.Subject.CommonName)
+
+	// The keystore is never regenerated
+	assert.NoError(t, s.reconcileTransportKeystoreSecret())
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: "testing", Name: "example-infinispan-transport-keystore"}, secret))
+	assert.Equal(t, password, string(secret.Data[TransportKeystorePasswordKey]))
+}
+
+func TestConfigureTransportEncryption(t *testing.T) {
+	ispn := transportEncryptionInfinispan(&ispnv1.TransportEncryption{Type: ispnv1.TransportEncryptionSym, SecretName: "keystore"})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystore", Namespace: "testing"},
+		Data:       map[string][]byte{EncryptKeystoreName: []byte("keystore"), TransportKeystorePasswordKey: []byte("secret")},
+	}
+	c, scheme := transportEncryptionClient(t, secret)
+	r := configRequest{
+		ConfigReconciler: &ConfigReconciler{Client: c, scheme: scheme, eventRec: record.NewFakeRecorder(10)},
+		infinispan:       ispn,
+		reqLogger:        ctrl.Log,
+		ctx:              context.TODO(),
+	}
+
+	// SYM_ENCRYPT requires the alias of the secret key
+	serverConf := &config.InfinispanConfiguration{}
+	result, err := r.configureTransportEncryption(serverConf)
+	assert.NotNil(t, result)
+	assert.Error(t, err)
+
+	secret.Data[TransportKeystoreAliasKey] = []byte("cluster-key")
+	assert.NoError(t, c.Update(context.TODO(), secret))
+	result, err = r.configureTransportEncryption(serverConf)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, &config.JGroupsEncryption{
+		Protocol: "SYM_ENCRYPT",
+		Keystore: config.Keystore{Path: "/etc/encrypt/transport/keystore.p12", Password: "secret", Alias: "cluster-key"},
+	}, serverConf.JGroups.Encryption)
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	InitContainerLibrariesMountPath  = "/opt/infinispan/server/lib/init-containers"
+	InitContainerLibrariesVolumeName = "init-container-libraries"
+
+	// UserInitContainersAnnotation lists the user defined init containers of the pod, so that removed containers can be dropped
+	UserInitContainersAnnotation = "infinispan.org/user-init-containers"
+	// UserSidecarsAnnotation lists the user defined sidecar containers of the pod, so that removed containers can be dropped
+	UserSidecarsAnnotation = "infinispan.org/user-sidecars"
+	// UserContainersHashAnnotation tracks the changes of the user defined init containers and sidecars
+	UserContainersHashAnnotation = "infinispan.org/user-containers-hash"
+)
+
+// operatorContainers are the names of the containers managed by the operator, which can't be used by the user
+var operatorContainers = map[string]bool{
+	InfinispanContainer: true, ExternalArtifactsDownloadInitContainer: true, "data-chmod-pv": true, ConsoleProxyContainer: true,
+	ServerConfInitContainer: true,
+}
+
+// validateUserContainers verifies that the user defined init containers and sidecars have unique names, which are not
+// used by the operator
+func validateUserContainers(i *infinispanv1.Infinispan) error {
+	names := map[string]bool{}
+	validate := func(field string, containers []corev1.Container) error {
+		for _, container := range containers {
+			if operatorContainers[container.Name] {
+				return fmt.Errorf("infinispan.spec.%s container name '%s' is reserved by the operator", field, container.Name)
+			}
+			if names[container.Name] {
+				return fmt.Errorf("infinispan.spec.%s defines container '%s' more than once", field, container.Name)
+			}
+			names[container.Name] = true
+		}
+		return nil
+	}
+	if err := validate("dependencies.initContainers", userInitContainers(i)); err != nil {
+		return err
+	}
+	return validate("sidecars", i.Spec.Sidecars)
+}
+
+func userInitContainers(i *infinispanv1.Infinispan) []corev1.Container {
+	if i.Spec.Dependencies == nil {
+		return nil
+	}
+	return i.Spec.Dependencies.InitContainers
+}
+
+// ApplyUserContainers replaces the user defined init containers and sidecars of the pod with the ones of the Infinispan
+// spec, returning true if they have been changed. The server container is kept first, as the operator expects it
+func ApplyUserContainers(i *infinispanv1.Infinispan, meta *metav1.ObjectMeta, spec *corev1.PodSpec) bool {
+	initContainers := userInitContainers(i)
+	sidecars := i.Spec.Sidecars
+	containersHash := ""
+	if len(initContainers) > 0 || len(sidecars) > 0 {
+		containersJSON, _ := json.Marshal([][]corev1.Container{initContainers, sidecars})
+		containersHash = hash.HashByte(containersJSON)
+	}
+	if meta.Annotations[UserContainersHashAnnotation] == containersHash {
+		return false
+	}
+
+	var initNames []string
+	spec.InitContainers = removeContainers(spec.InitContainers, meta.Annotations[UserInitContainersAnnotation])
+	for _, container := range initContainers {
+		container = *container.DeepCopy()
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: InitContainerLibrariesVolumeName, MountPath: InitContainerLibrariesMountPath})
+		spec.InitContainers = append(spec.InitContainers, container)
+		initNames = append(initNames, container.Name)
+	}
+	var sidecarNames []string
+	spec.Containers = removeContainers(spec.Containers, meta.Annotations[UserSidecarsAnnotation])
+	for _, container := range sidecars {
+		spec.Containers = append(spec.Containers, *container.DeepCopy())
+		sidecarNames = append(sidecarNames, container.Name)
+	}
+
+	volumes := &spec.Volumes
+	volumeMounts := &spec.Containers[0].VolumeMounts
+	volumePosition := findVolume(*volumes, InitContainerLibrariesVolumeName)
+	if len(initContainers) > 0 && volumePosition < 0 {
+		*volumeMounts = append(*volumeMounts, corev1.VolumeMount{Name: InitContainerLibrariesVolumeName, MountPath: InitContainerLibrariesMountPath, ReadOnly: true})
+		*volumes = append(*volumes, corev1.Volume{Name: InitContainerLibrariesVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+	} else if len(initContainers) == 0 && volumePosition >= 0 {
+		volumeMountPosition := findVolumeMount(*volumeMounts, InitContainerLibrariesVolumeName)
+		*volumes = append(spec.Volumes[:volumePosition], spec.Volumes[volumePosition+1:]...)
+		*volumeMounts = append(spec.Containers[0].VolumeMounts[:volumeMountPosition], spec.Containers[0].VolumeMounts[volumeMountPosition+1:]...)
+	}
+
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	for annotation, value := range map[string]string{
+		UserInitContainersAnnotation: strings.Join(initNames, ","),
+		UserSidecarsAnnotation:       strings.Join(sidecarNames, ","),
+		UserContainersHashAnnotation: containersHash,
+	} {
+		if value == "" {
+			delete(meta.Annotations, annotation)
+		} else {
+			meta.Annotations[annotation] = value
+		}
+	}
+	return true
+}
+
+// removeContainers returns the containers whose name isn't in the comma separated `names`
+func removeContainers(containers []corev1.Container, names string) []corev1.Container {
+	remove := map[string]bool{}
+	for _, name := range strings.Split(names, ",") {
+		remove[name] = true
+	}
+	var kept []corev1.Container
+	for _, container := range containers {
+		if !remove[container.Name] {
+			kept = append(kept, container)
+		}
+	}
+	return kept
+}
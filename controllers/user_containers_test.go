@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateUserContainers(t *testing.T) {
+	ispn := &ispnv1.Infinispan{}
+	assert.NoError(t, validateUserContainers(ispn))
+
+	ispn.Spec.Dependencies = &ispnv1.InfinispanExternalDependencies{InitContainers: []corev1.Container{{Name: "download"}}}
+	ispn.Spec.Sidecars = []corev1.Container{{Name: "exporter"}}
+	assert.NoError(t, validateUserContainers(ispn))
+
+	ispn.Spec.Sidecars = []corev1.Container{{Name: "download"}}
+	assert.EqualError(t, validateUserContainers(ispn), "infinispan.spec.sidecars defines container 'download' more than once")
+
+	ispn.Spec.Sidecars = []corev1.Container{{Name: InfinispanContainer}}
+	assert.EqualError(t, validateUserContainers(ispn), "infinispan.spec.sidecars container name 'infinispan' is reserved by the operator")
+}
+
+func TestApplyUserContainers(t *testing.T) {
+	ispn := &ispnv1.Infinispan{
+		Spec: ispnv1.InfinispanSpec{
+			Dependencies: &ispnv1.InfinispanExternalDependencies{InitContainers: []corev1.Container{{Name: "download", Image: "busybox"}}},
+			Sidecars:     []corev1.Container{{Name: "exporter", Image: "exporter:1"}},
+		},
+	}
+	meta := &metav1.ObjectMeta{}
+	spec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: ExternalArtifactsDownloadInitContainer}},
+		Containers:     []corev1.Container{{Name: InfinispanContainer}},
+	}
+
+	assert.True(t, ApplyUserContainers(ispn, meta, spec))
+	assert.Equal(t, []string{ExternalArtifactsDownloadInitContainer, "download"}, containerNames(spec.InitContainers))
+	assert.Equal(t, []corev1.VolumeMount{{Name: InitContainerLibrariesVolumeName, MountPath: InitContainerLibrariesMountPath}}, spec.InitContainers[1].VolumeMounts)
+	assert.Empty(t, ispn.Spec.Dependencies.InitContainers[0].VolumeMounts)
+	assert.Equal(t, []string{InfinispanContainer, "exporter"}, containerNames(spec.Containers))
+	assert.Equal(t, []corev1.VolumeMount{{Name: InitContainerLibrariesVolumeName, MountPath: InitContainerLibrariesMountPath, ReadOnly: true}}, spec.Containers[0].VolumeMounts)
+	assert.Equal(t, 0, findVolume(spec.Volumes, InitContainerLibrariesVolumeName))
+	assert.False(t, ApplyUserContainers(ispn, meta, spec))
+
+	// Changed containers are replaced
+	ispn.Spec.Sidecars[0].Image = "exporter:2"
+	assert.True(t, ApplyUserContainers(ispn, meta, spec))
+	assert.Equal(t, []string{InfinispanContainer, "exporter"}, containerNames(spec.Containers))
+	assert.Equal(t, "exporter:2", spec.Containers[1].Image)
+	assert.Len(t, spec.InitContainers[1].VolumeMounts, 1)
+
+	// Removed containers are dropped, along with the libraries volume
+	ispn.Spec.Dependencies = nil
+	ispn.Spec.Sidecars = nil
+	assert.True(t, ApplyUserContainers(ispn, meta, spec))
+	assert.Equal(t, []string{ExternalArtifactsDownloadInitContainer}, containerNames(spec.InitContainers))
+	assert.Equal(t, []string{InfinispanContainer}, containerNames(spec.Containers))
+	assert.Empty(t, spec.Volumes)
+	assert.Empty(t, spec.Containers[0].VolumeMounts)
+	assert.Empty(t, meta.Annotations)
+}
+
+func containerNames(containers []corev1.Container) []string {
+	var names []string
+	for _, container := range containers {
+		names = append(names, container.Name)
+	}
+	return names
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"fmt"
+
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	EventReasonVolumeExpanded           = "VolumeExpanded"
+	EventReasonVolumeExpansionFailed    = "VolumeExpansionFailed"
+	EventReasonVolumeExpansionRestarted = "VolumeExpansionRestarted"
+)
+
+// reconcileVolumeExpansion expands the data PersistentVolumeClaims of the pods to the storage of the spec. The
+// StatefulSet claim template can't be changed, so the claims are patched directly when their storage class allows
+// volume expansion. Pods whose file system can only be resized offline are restarted one at a time, once all the pods
+// are ready and no cache is rebalancing
+func (r *infinispanRequest) reconcileVolumeExpansion(podList *corev1.PodList, cluster ispn.ClusterInterface) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	if infinispan.IsEphemeralStorage() || !infinispan.IsDataGrid() || infinispan.StorageSize() == "" {
+		return nil, nil
+	}
+	desired, err := resource.ParseQuantity(infinispan.StorageSize())
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	resizing := false
+	var pendingPod *corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		pvc, err := r.podDataVolumeClaim(pod)
+		if err != nil {
+			return &ctrl.Result{}, err
+		} else if pvc == nil {
+			continue
+		}
+
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if requested.Cmp(desired) < 0 {
+			if expanded, err := r.expandVolumeClaim(pvc, desired); err != nil {
+				return &ctrl.Result{}, err
+			} else if !expanded {
+				continue
+			}
+		}
+		if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok && capacity.Cmp(pvc.Spec.Resources.Requests[corev1.ResourceStorage]) < 0 {
+			resizing = true
+		}
+		if volumeClaimResizePending(pvc) && (pendingPod == nil || podOrdinal(pod.Name) > podOrdinal(pendingPod.Name)) {
+			pendingPod = pod
+		}
+	}
+
+	if pendingPod == nil {
+		if resizing {
+			// Wait for the volumes to be resized, as some require the pod to be restarted
+			return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+		}
+		return nil, nil
+	}
+
+	for _, p := range podList.Items {
+		if !kube.IsPodReady(p) {
+			r.reqLogger.Info("Waiting for the pods to be ready to restart the pods with a pending volume resize", "pod", p.Name)
+			return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+		}
+	}
+	if rebalancing, err := clusterRebalancing(cluster, podList.Items[0].Name); err != nil {
+		return &ctrl.Result{}, err
+	} else if rebalancing {
+		r.reqLogger.Info("Waiting for the caches to be rebalanced to restart the pods with a pending volume resize")
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+
+	r.reqLogger.Info("Restarting pod to complete the volume resize", "pod", pendingPod.Name)
+	if err := r.Client.Delete(r.ctx, pendingPod); err != nil && !errors.IsNotFound(err) {
+		return &ctrl.Result{}, err
+	}
+	r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonVolumeExpansionRestarted, fmt.Sprintf("Pod %s restarted to resize its data volume", pendingPod.Name))
+	return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+}
+
+// podDataVolumeClaim returns the PersistentVolumeClaim of the data volume of the pod, nil if the pod has none
+func (r *infinispanRequest) podDataVolumeClaim(pod *corev1.Pod) (*corev1.PersistentVolumeClaim, error) {
+	for _, volume := range pod.Spec.Volumes {
+		// The data volume is named after the cluster by releases that predate the data-volume name
+		if volume.PersistentVolumeClaim == nil || (volume.Name != DataMountVolume && volume.Name != r.infinispan.Name) {
+			continue
+		}
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Client.Get(r.ctx, types.NamespacedName{Namespace: pod.Namespace, Name: volume.PersistentVolumeClaim.ClaimName}, pvc); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return pvc, nil
+	}
+	return nil, nil
+}
+
+// expandVolumeClaim requests the desired storage for the claim, returning false when its storage class doesn't
+// allow volume expansion
+func (r *infinispanRequest) expandVolumeClaim(pvc *corev1.PersistentVolumeClaim, desired resource.Quantity) (bool, error) {
+	infinispan := r.infinispan
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		msg := fmt.Sprintf("PersistentVolumeClaim %s has no storage class and cannot be expanded to %s", pvc.Name, desired.String())
+		r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonVolumeExpansionFailed, msg)
+		return false, nil
+	}
+	storageClass, err := kube.FindStorageClass(*pvc.Spec.StorageClassName, r.Client, r.ctx)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		msg := fmt.Sprintf("StorageClass %s doesn't allow volume expansion, PersistentVolumeClaim %s cannot be expanded to %s", storageClass.Name, pvc.Name, desired.String())
+		r.eventRec.Event(infinispan, corev1.EventTypeWarning, EventReasonVolumeExpansionFailed, msg)
+		return false, nil
+	}
+
+	patch := client.MergeFrom(pvc.DeepCopy())
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desired
+	if err := r.Client.Patch(r.ctx, pvc, patch); err != nil {
+		return false, fmt.Errorf("unable to expand PersistentVolumeClaim %s: %w", pvc.Name, err)
+	}
+	r.eventRec.Event(infinispan, corev1.EventTypeNormal, EventReasonVolumeExpanded, fmt.Sprintf("PersistentVolumeClaim %s expanded to %s", pvc.Name, desired.String()))
+	return true, nil
+}
+
+// volumeClaimResizePending returns true if the volume of the claim was expanded, but its file system is only resized
+// when the pod is restarted
+func volumeClaimResizePending(pvc *corev1.PersistentVolumeClaim) bool {
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
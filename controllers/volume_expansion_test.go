@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func volumeExpansionRequest(t *testing.T, storage string, objs ...runtime.Object) *infinispanRequest {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, infinispanv1.AddToScheme(scheme))
+	infinispan := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: infinispanv1.InfinispanSpec{Service: infinispanv1.InfinispanServiceSpec{
+			Type:      infinispanv1.ServiceTypeDataGrid,
+			Container: &infinispanv1.InfinispanServiceContainerSpec{Storage: pointer.StringPtr(storage)},
+		}},
+	}
+	return &infinispanRequest{
+		InfinispanReconciler: &InfinispanReconciler{
+			Client:   fake.NewFakeClientWithScheme(scheme, objs...),
+			scheme:   scheme,
+			eventRec: record.NewFakeRecorder(10),
+		},
+		ctx:        context.TODO(),
+		infinispan: infinispan,
+		reqLogger:  logf.Log,
+	}
+}
+
+func dataVolumePod(name string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         DataMountVolume,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: DataMountVolume + "-" + name}},
+		}}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+}
+
+func dataVolumeClaim(podName, storageClass, size string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: DataMountVolume + "-" + podName, Namespace: namespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: pointer.StringPtr(storageClass),
+			Resources:        corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}},
+	}
+}
+
+func TestReconcileVolumeExpansion(t *testing.T) {
+	expandable := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: pointer.BoolPtr(true)}
+	fixed := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}}
+	pods := &corev1.PodList{Items: []corev1.Pod{dataVolumePod("example-infinispan-0"), dataVolumePod("example-infinispan-1")}}
+
+	// The claims are only expanded when their storage class allows it
+	r := volumeExpansionRequest(t, "2Gi", expandable, fixed, dataVolumeClaim("example-infinispan-0", "expandable", "1Gi"), dataVolumeClaim("example-infinispan-1", "fixed", "1Gi"))
+	result, err := r.reconcileVolumeExpansion(pods, &rebalancingCluster{})
+	assert.NoError(t, err)
+	assert.Equal(t, consts.DefaultWaitOnCluster, result.RequeueAfter)
+	pvc := &corev1.PersistentVolumeClaim{}
+	assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "data-volume-example-infinispan-0"}, pvc))
+	assert.Equal(t, "2Gi", pvc.Spec.Resources.Requests.Storage().String())
+	assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "data-volume-example-infinispan-1"}, pvc))
+	assert.Equal(t, "1Gi", pvc.Spec.Resources.Requests.Storage().String())
+
+	// The pods whose file system resize is pending are restarted, once the caches are balanced
+	pending := dataVolumeClaim("example-infinispan-1", "expandable", "1Gi")
+	pending.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("2Gi")
+	pending.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue}}
+	r = volumeExpansionRequest(t, "2Gi", expandable, dataVolumeClaim("example-infinispan-0", "expandable", "2Gi"), pending, &pods.Items[1])
+	result, err = r.reconcileVolumeExpansion(pods, &rebalancingCluster{rebalancing: map[string]bool{"a": true}})
+	assert.NoError(t, err)
+	assert.Equal(t, consts.DefaultWaitOnCluster, result.RequeueAfter)
+	assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "example-infinispan-1"}, &corev1.Pod{}))
+
+	result, err = r.reconcileVolumeExpansion(pods, &rebalancingCluster{})
+	assert.NoError(t, err)
+	assert.Equal(t, consts.DefaultWaitOnCluster, result.RequeueAfter)
+	assert.Error(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: namespace, Name: "example-infinispan-1"}, &corev1.Pod{}))
+
+	// Nothing to do once the volumes have the requested storage
+	r = volumeExpansionRequest(t, "2Gi", expandable, dataVolumeClaim("example-infinispan-0", "expandable", "2Gi"), dataVolumeClaim("example-infinispan-1", "expandable", "4Gi"))
+	result, err = r.reconcileVolumeExpansion(pods, &rebalancingCluster{})
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,18 +16,19 @@ import (
 	"k8s.io/utils/pointer"
 )
 
-func (r *infinispanRequest) GetCrossSiteViewCondition(podList *corev1.PodList, siteLocations []string, cluster ispn.ClusterInterface) (*ispnv1.InfinispanCondition, error) {
+func (r *infinispanRequest) GetCrossSiteViewCondition(podList *corev1.PodList, siteLocations []string, cluster ispn.ClusterInterface) (*metav1.Condition, error) {
 	for _, item := range podList.Items {
 		cacheManagerInfo, err := cluster.GetCacheManagerInfo(consts.DefaultCacheManagerName, item.Name)
 		if err == nil {
 			if cacheManagerInfo.Coordinator {
 				// Perform cross-site view validation
-				crossSiteViewFormed := &ispnv1.InfinispanCondition{Type: ispnv1.ConditionCrossSiteViewFormed, Status: metav1.ConditionTrue}
+				crossSiteViewFormed := &metav1.Condition{Type: string(ispnv1.ConditionCrossSiteViewFormed), Status: metav1.ConditionTrue, Reason: ispnv1.ReasonCrossSiteViewFormed}
 				sitesView, err := cacheManagerInfo.GetSitesView()
 				if err == nil {
 					for _, location := range siteLocations {
 						if !sitesView[location] {
 							crossSiteViewFormed.Status = metav1.ConditionFalse
+							crossSiteViewFormed.Reason = ispnv1.ReasonCrossSiteViewNotFormed
 							crossSiteViewFormed.Message = fmt.Sprintf("Site '%s' not ready", location)
 							break
 						}
@@ -36,24 +38,67 @@ func (r *infinispanRequest) GetCrossSiteViewCondition(podList *corev1.PodList, s
 					}
 				} else {
 					crossSiteViewFormed.Status = metav1.ConditionUnknown
+					crossSiteViewFormed.Reason = ispnv1.ReasonCrossSiteViewError
 					crossSiteViewFormed.Message = fmt.Sprintf("Error: %s", err.Error())
 				}
 				return crossSiteViewFormed, nil
 			}
 		}
 	}
-	return &ispnv1.InfinispanCondition{Type: ispnv1.ConditionCrossSiteViewFormed, Status: metav1.ConditionFalse, Message: "Coordinator not ready"}, nil
+	return &metav1.Condition{Type: string(ispnv1.ConditionCrossSiteViewFormed), Status: metav1.ConditionFalse, Reason: ispnv1.ReasonCrossSiteViewNotFormed, Message: "Coordinator not ready"}, nil
 }
 
-// GetGossipRouterDeployment returns the deployment for the Gossip Router pod
-func (r *infinispanRequest) GetGossipRouterDeployment(m *infinispanv1.Infinispan) *appsv1.Deployment {
-	lsTunnel := GossipRouterPodLabels(m.Name)
-	replicas := int32(1)
+// GetCrossSiteStatus returns the status of each backup site along with the state transfer status of the caches pushing
+// their state to it
+func GetCrossSiteStatus(podName string, cluster ispn.ClusterInterface) ([]ispnv1.CrossSiteStatus, error) {
+	statuses, err := cluster.GetXSiteStatus(podName)
+	if err != nil || len(statuses) == 0 {
+		return nil, err
+	}
+	cacheNames, err := cluster.CacheNames(podName)
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make(map[string]*ispnv1.CrossSiteStatus, len(statuses))
+	for name, status := range statuses {
+		sites[name] = &ispnv1.CrossSiteStatus{Name: name, Status: status.Status}
+	}
+	for _, cache := range cacheNames {
+		if strings.HasPrefix(cache, "___") {
+			continue
+		}
+		pushStatus, err := cluster.GetXSitePushStateStatus(cache, podName)
+		if err != nil {
+			// Caches without backups have no state transfer status
+			continue
+		}
+		for name, status := range pushStatus {
+			if site, ok := sites[name]; ok {
+				if site.StateTransfer == nil {
+					site.StateTransfer = make(map[string]string)
+				}
+				site.StateTransfer[cache] = status
+			}
+		}
+	}
 
-	// if the user configures 0 replicas, shutdown the gossip router pod too.
-	if m.Spec.Replicas <= 0 {
-		replicas = 0
+	xsite := make([]ispnv1.CrossSiteStatus, 0, len(sites))
+	for _, site := range sites {
+		xsite = append(xsite, *site)
 	}
+	sort.Slice(xsite, func(i, j int) bool {
+		return xsite[i].Name < xsite[j].Name
+	})
+	return xsite, nil
+}
+
+// GetGossipRouterDeployment returns the deployment for the Gossip Router pods. The keystore Secret is only provided
+// when the connections to the Gossip Router are secured with TLS
+func (r *infinispanRequest) GetGossipRouterDeployment(m *infinispanv1.Infinispan, keystoreSecret *corev1.Secret) (*appsv1.Deployment, error) {
+	lsTunnel := GossipRouterPodLabels(m.Name)
+	// if the user configures 0 replicas, shutdown the gossip router pods too.
+	replicas := m.GetGossipRouterReplicas()
 
 	deployment := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -76,6 +121,7 @@ func (r *infinispanRequest) GetGossipRouterDeployment(m *infinispanv1.Infinispan
 					Labels:    lsTunnel,
 				},
 				Spec: corev1.PodSpec{
+					Affinity: architectureAffinity(m, nil),
 					Containers: []corev1.Container{{
 						Name:    "gossiprouter",
 						Image:   m.ImageName(),
@@ -91,11 +137,17 @@ func (r *infinispanRequest) GetGossipRouterDeployment(m *infinispanv1.Infinispan
 						LivenessProbe:  GossipRouterLivenessProbe(),
 						ReadinessProbe: GossipRouterLivenessProbe(),
 						StartupProbe:   GossipRouterStartupProbe(),
+						Resources:      gossipRouterResources(m),
 					}},
 				},
 			},
 			Replicas: pointer.Int32Ptr(replicas),
 		},
 	}
-	return deployment
+	if keystoreSecret != nil {
+		if err := applyGossipRouterTLS(m, keystoreSecret, &deployment.Spec.Template.Spec); err != nil {
+			return nil, err
+		}
+	}
+	return deployment, nil
 }
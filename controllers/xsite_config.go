@@ -19,16 +19,20 @@ import (
 	"k8s.io/cloud-provider/service/helpers"
 )
 
+// lookupHostAddresses resolves the x-site host names, replaced by the tests
+var lookupHostAddresses = net.LookupHost
+
 const (
 	SchemeTypeKubernetes = "kubernetes"
 	SchemeTypeMinikube   = "minikube"
 	SchemeTypeOpenShift  = "openshift"
 )
 
-// ComputeXSite compute the xsite struct for cross site function
-func ComputeXSite(infinispan *ispnv1.Infinispan, kubernetes *kube.Kubernetes, service *corev1.Service, logger logr.Logger, eventRec record.EventRecorder, ctx context.Context) (*config.XSite, error) {
+// ComputeXSite compute the xsite struct for cross site function. The remote x-site Services looked up via the
+// Kubernetes API must have the type in exposeTypes of their location, if any
+func ComputeXSite(infinispan *ispnv1.Infinispan, exposeTypes map[string]ispnv1.CrossSiteExposeType, kubernetes *kube.Kubernetes, service *corev1.Service, logger logr.Logger, eventRec record.EventRecorder, ctx context.Context) (*config.XSite, error) {
 	siteServiceName := infinispan.GetSiteServiceName()
-	localSiteHost, localSitePort, err := getCrossSiteServiceHostPort(service, kubernetes, logger, eventRec, "XSiteLocalServiceUnsupported", ctx)
+	localSiteHost, localSitePort, err := getCrossSiteServiceHostPort(localCrossSiteService(infinispan, service), kubernetes, logger, eventRec, "XSiteLocalServiceUnsupported", ctx)
 	if err != nil {
 		logger.Error(err, "error retrieving local x-site service information")
 		return nil, err
@@ -54,6 +58,7 @@ func ComputeXSite(infinispan *ispnv1.Infinispan, kubernetes *kube.Kubernetes, se
 		Transport:      "tunnel",
 		MaxSiteMasters: maxRelayNodes,
 	}
+	xsite.StateTransfer = xsiteStateTransfer(infinispan.Spec.Service.Sites.Local.StateTransfer)
 
 	for _, remoteLocation := range infinispan.GetRemoteSiteLocations() {
 		backupSiteURL, err := url.Parse(remoteLocation.URL)
@@ -73,7 +78,7 @@ func ComputeXSite(infinispan *ispnv1.Infinispan, kubernetes *kube.Kubernetes, se
 			appendBackupSite(remoteLocation.Name, backupSiteURL.Hostname(), int32(port), xsite)
 		} else {
 			// lookup remote service via kubernetes API
-			if err = appendRemoteLocation(ctx, infinispan, &remoteLocation, kubernetes, logger, eventRec, xsite); err != nil {
+			if err = appendRemoteLocation(ctx, infinispan, &remoteLocation, exposeTypes[remoteLocation.Name], kubernetes, logger, eventRec, xsite); err != nil {
 				return nil, err
 			}
 		}
@@ -83,8 +88,26 @@ func ComputeXSite(infinispan *ispnv1.Infinispan, kubernetes *kube.Kubernetes, se
 	return xsite, nil
 }
 
-func appendRemoteLocation(ctx context.Context, infinispan *ispnv1.Infinispan, remoteLocation *ispnv1.InfinispanSiteLocationSpec, kubernetes *kube.Kubernetes,
-	logger logr.Logger, eventRec record.EventRecorder, xsite *config.XSite) error {
+// xsiteStateTransfer returns the state transfer tuning of the server configuration, the timeout being in milliseconds
+func xsiteStateTransfer(spec *ispnv1.CrossSiteStateTransferSpec) *config.XSiteStateTransfer {
+	if spec == nil {
+		return nil
+	}
+	stateTransfer := &config.XSiteStateTransfer{
+		MaxRetries: spec.MaxRetries,
+		Mode:       string(spec.Mode),
+	}
+	if spec.ChunkSize != nil {
+		stateTransfer.ChunkSize = *spec.ChunkSize
+	}
+	if spec.TimeoutSeconds != nil {
+		stateTransfer.Timeout = int64(*spec.TimeoutSeconds) * 1000
+	}
+	return stateTransfer
+}
+
+func appendRemoteLocation(ctx context.Context, infinispan *ispnv1.Infinispan, remoteLocation *ispnv1.InfinispanSiteLocationSpec, exposeType ispnv1.CrossSiteExposeType,
+	kubernetes *kube.Kubernetes, logger logr.Logger, eventRec record.EventRecorder, xsite *config.XSite) error {
 	restConfig, err := getRemoteSiteRESTConfig(infinispan.Namespace, remoteLocation, kubernetes, logger, ctx)
 	if err != nil {
 		return err
@@ -96,13 +119,14 @@ func appendRemoteLocation(ctx context.Context, infinispan *ispnv1.Infinispan, re
 		return err
 	}
 
-	if err = appendKubernetesRemoteLocation(ctx, infinispan, remoteLocation.Name, remoteKubernetes, logger, eventRec, xsite); err != nil {
+	if err = appendKubernetesRemoteLocation(ctx, infinispan, remoteLocation.Name, exposeType, remoteKubernetes, logger, eventRec, xsite); err != nil {
 		return err
 	}
 	return nil
 }
 
-func appendKubernetesRemoteLocation(ctx context.Context, infinispan *ispnv1.Infinispan, remoteLocationName string, remoteKubernetes *kube.Kubernetes, logger logr.Logger, eventRec record.EventRecorder, xsite *config.XSite) error {
+func appendKubernetesRemoteLocation(ctx context.Context, infinispan *ispnv1.Infinispan, remoteLocationName string, exposeType ispnv1.CrossSiteExposeType,
+	remoteKubernetes *kube.Kubernetes, logger logr.Logger, eventRec record.EventRecorder, xsite *config.XSite) error {
 	remoteNamespace := infinispan.GetRemoteSiteNamespace(remoteLocationName)
 	remoteServiceName := infinispan.GetRemoteSiteServiceName(remoteLocationName)
 
@@ -112,6 +136,9 @@ func appendKubernetesRemoteLocation(ctx context.Context, infinispan *ispnv1.Infi
 		logger.Error(err, "could not get x-site service in remote cluster", "site service name", remoteServiceName, "site namespace", remoteNamespace)
 		return err
 	}
+	if exposeType != "" && siteService.Spec.Type != corev1.ServiceType(exposeType) {
+		return fmt.Errorf("x-site service '%s' of remote location '%s' has type '%s', expected '%s'", remoteServiceName, remoteLocationName, siteService.Spec.Type, exposeType)
+	}
 
 	host, port, err := getCrossSiteServiceHostPort(siteService, remoteKubernetes, logger, eventRec, "XSiteRemoteServiceUnsupported", ctx)
 	if err != nil {
@@ -144,7 +171,20 @@ func appendBackupSite(name, host string, port int32, xsite *config.XSite) {
 	xsite.Backups = append(xsite.Backups, backupSite)
 }
 
+// getCrossSiteServiceHostPort returns the address of the x-site service. The host name advertised by the site, if any,
+// replaces the address resolved from the service type
 func getCrossSiteServiceHostPort(service *corev1.Service, kubernetes *kube.Kubernetes, logger logr.Logger, eventRec record.EventRecorder, reason string, ctx context.Context) (string, int32, error) {
+	if hostName := service.Annotations[CrossSiteHostNameAnnotation]; hostName != "" {
+		port := service.Spec.Ports[0].Port
+		if service.Spec.Type == corev1.ServiceTypeNodePort {
+			port = service.Spec.Ports[0].NodePort
+		}
+		host, err := lookupHost(hostName, logger)
+		if err != nil {
+			return "", port, fmt.Errorf("the host name '%s' advertised by x-site service '%s' does not resolve: %w", hostName, service.Name, err)
+		}
+		return host, port, nil
+	}
 	switch serviceType := service.Spec.Type; serviceType {
 	case corev1.ServiceTypeNodePort:
 		// If configuring NodePort, expect external IPs to be configured
@@ -166,6 +206,9 @@ func getLoadBalancerServiceHostPort(service *corev1.Service, logger logr.Logger,
 	// If configuring load balancer, look for external ingress
 	if len(service.Status.LoadBalancer.Ingress) > 0 {
 		ingress := service.Status.LoadBalancer.Ingress[0]
+		if staticIP := service.Spec.LoadBalancerIP; staticIP != "" && ingress.IP != staticIP {
+			return "", port, fmt.Errorf("the load balancer of x-site service '%s' has the address '%s' instead of the requested loadBalancerIP '%s'", service.Name, ingress.IP+ingress.Hostname, staticIP)
+		}
 		if ingress.IP != "" {
 			return ingress.IP, port, nil
 		}
@@ -215,7 +258,7 @@ func getRemoteSiteRESTConfig(namespace string, location *ispnv1.InfinispanSiteLo
 }
 
 func lookupHost(host string, logger logr.Logger) (string, error) {
-	addresses, err := net.LookupHost(host)
+	addresses, err := lookupHostAddresses(host)
 	if err != nil {
 		logger.Error(err, "host does not resolve")
 		return "", err
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"fmt"
+	"net"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CrossSiteHostNameAnnotation holds the host name advertised by a site on its x-site service, so that the sites
+// looking the service up via the Kubernetes API connect to the same address as the local site advertises
+const CrossSiteHostNameAnnotation = "infinispan.org/xsite-host-name"
+
+// validateCrossSiteExpose verifies the address pinned for the x-site service
+func validateCrossSiteExpose(i *ispnv1.Infinispan) error {
+	if !i.HasSites() {
+		return nil
+	}
+	expose := i.Spec.Service.Sites.Local.Expose
+	if expose.RouteHostName != "" {
+		if errs := validation.IsDNS1123Subdomain(expose.RouteHostName); len(errs) > 0 {
+			return fmt.Errorf("infinispan.spec.service.sites.local.expose.routeHostName '%s' is not a valid host name: %s", expose.RouteHostName, errs[0])
+		}
+	}
+	if expose.LoadBalancerIP != "" {
+		if expose.Type != ispnv1.CrossSiteExposeTypeLoadBalancer {
+			return fmt.Errorf("infinispan.spec.service.sites.local.expose.loadBalancerIP is only supported for type=%s", ispnv1.CrossSiteExposeTypeLoadBalancer)
+		}
+		if net.ParseIP(expose.LoadBalancerIP) == nil {
+			return fmt.Errorf("infinispan.spec.service.sites.local.expose.loadBalancerIP '%s' is not a valid IP address", expose.LoadBalancerIP)
+		}
+	}
+	return nil
+}
+
+// applyCrossSiteAddress requests the static load balancer IP of the x-site service and records the advertised host
+// name on the service
+func applyCrossSiteAddress(i *ispnv1.Infinispan, service *corev1.Service) {
+	expose := i.Spec.Service.Sites.Local.Expose
+	if expose.Type == ispnv1.CrossSiteExposeTypeLoadBalancer {
+		service.Spec.LoadBalancerIP = expose.LoadBalancerIP
+	}
+	if expose.RouteHostName != "" {
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		service.Annotations[CrossSiteHostNameAnnotation] = expose.RouteHostName
+	}
+}
+
+// localCrossSiteService returns the local x-site service with the address configured in the Infinispan CR, as the
+// service may not have been updated yet by the service controller
+func localCrossSiteService(i *ispnv1.Infinispan, service *corev1.Service) *corev1.Service {
+	service = service.DeepCopy()
+	delete(service.Annotations, CrossSiteHostNameAnnotation)
+	applyCrossSiteAddress(i, service)
+	return service
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func exposeXSiteInfinispan(expose ispnv1.CrossSiteExposeSpec) *ispnv1.Infinispan {
+	ispn := staticXSiteInfinispan.DeepCopy()
+	ispn.Spec.Service.Type = ispnv1.ServiceTypeDataGrid
+	ispn.Spec.Service.Sites.Local.Expose = expose
+	return ispn
+}
+
+func TestValidateCrossSiteExpose(t *testing.T) {
+	assert.NoError(t, validateCrossSiteExpose(exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeClusterIP, RouteHostName: "site-a.example.com"})))
+	assert.NoError(t, validateCrossSiteExpose(exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeLoadBalancer, LoadBalancerIP: "203.0.113.10"})))
+	err := validateCrossSiteExpose(exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeClusterIP, RouteHostName: "Site_A.example.com"}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "infinispan.spec.service.sites.local.expose.routeHostName 'Site_A.example.com' is not a valid host name")
+	assert.EqualError(t, validateCrossSiteExpose(exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeNodePort, LoadBalancerIP: "203.0.113.10"})),
+		"infinispan.spec.service.sites.local.expose.loadBalancerIP is only supported for type=LoadBalancer")
+	assert.EqualError(t, validateCrossSiteExpose(exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeLoadBalancer, LoadBalancerIP: "site-a"})),
+		"infinispan.spec.service.sites.local.expose.loadBalancerIP 'site-a' is not a valid IP address")
+}
+
+func TestComputeSiteServiceAddress(t *testing.T) {
+	service := computeSiteService(exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeLoadBalancer, LoadBalancerIP: "203.0.113.10", RouteHostName: "site-a.example.com"}))
+	assert.Equal(t, "203.0.113.10", service.Spec.LoadBalancerIP)
+	assert.Equal(t, "site-a.example.com", service.Annotations[CrossSiteHostNameAnnotation])
+
+	service = computeSiteService(exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeClusterIP}))
+	assert.Empty(t, service.Spec.LoadBalancerIP)
+	assert.NotContains(t, service.Annotations, CrossSiteHostNameAnnotation)
+}
+
+func TestComputeXSiteRouteHostName(t *testing.T) {
+	defer func(lookup func(string) ([]string, error)) { lookupHostAddresses = lookup }(lookupHostAddresses)
+	lookupHostAddresses = func(host string) ([]string, error) {
+		if host == "site-a.example.com" {
+			return []string{"203.0.113.10"}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	// The host name of the Infinispan CR is advertised before the service is updated
+	ispn := exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeClusterIP, RouteHostName: "site-a.example.com"})
+	xsite, err := ComputeXSite(ispn, nil, nil, staticSiteService, logger, nil, context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "site-a.example.com", xsite.Address)
+	assert.Equal(t, int32(consts.CrossSitePort), xsite.Port)
+
+	ispn.Spec.Service.Sites.Local.Expose.RouteHostName = "site-b.example.com"
+	_, err = ComputeXSite(ispn, nil, nil, staticSiteService, logger, nil, context.TODO())
+	assert.EqualError(t, err, "the host name 'site-b.example.com' advertised by x-site service 'example-clustera-site' does not resolve: no such host")
+}
+
+func TestGetLoadBalancerServiceHostPortStaticIP(t *testing.T) {
+	service := computeSiteService(exposeXSiteInfinispan(ispnv1.CrossSiteExposeSpec{Type: ispnv1.CrossSiteExposeTypeLoadBalancer, LoadBalancerIP: "203.0.113.10"}))
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+	host, port, err := getLoadBalancerServiceHostPort(service, logger, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", host)
+	assert.Equal(t, int32(consts.CrossSitePort), port)
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "198.51.100.7"}}
+	_, _, err = getLoadBalancerServiceHostPort(service, logger, nil, "")
+	assert.EqualError(t, err, "the load balancer of x-site service 'example-clustera-site' has the address '198.51.100.7' instead of the requested loadBalancerIP '203.0.113.10'")
+}
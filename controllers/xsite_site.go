@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// InfinispanSiteRefField is the Infinispan field indexed to lookup the Infinispans referencing an InfinispanSite
+const InfinispanSiteRefField = "spec.service.sites.locations.site"
+
+// +kubebuilder:rbac:groups=infinispan.org,resources=infinispansites,verbs=get;list;watch
+
+// siteRefs returns the names of the InfinispanSites referenced by the site locations of the Infinispan
+func siteRefs(obj client.Object) []string {
+	ispn := obj.(*ispnv1.Infinispan)
+	if ispn.Spec.Service.Sites == nil {
+		return nil
+	}
+	var sites []string
+	for _, location := range ispn.Spec.Service.Sites.Locations {
+		if location.Site != "" {
+			sites = append(sites, location.Site)
+		}
+	}
+	return sites
+}
+
+// resolveSiteLocations returns a copy of the Infinispan with the site locations referencing an InfinispanSite
+// completed with the connection details of the site, along with the expected type of the remote x-site Service of
+// each location
+func (r configRequest) resolveSiteLocations() (*ispnv1.Infinispan, map[string]ispnv1.CrossSiteExposeType, *reconcile.Result, error) {
+	resolved := r.infinispan.DeepCopy()
+	exposeTypes := map[string]ispnv1.CrossSiteExposeType{}
+	locations := resolved.Spec.Service.Sites.Locations
+	for i := range locations {
+		if locations[i].Site == "" {
+			continue
+		}
+		site := &infinispanv2alpha1.InfinispanSite{}
+		if result, err := kube.LookupResource(locations[i].Site, "", site, r.infinispan, r.Client, r.reqLogger, r.eventRec, r.ctx); result != nil {
+			return nil, nil, result, err
+		}
+		applySite(&locations[i], site)
+		if site.Spec.ExposeType != "" {
+			exposeTypes[locations[i].Name] = site.Spec.ExposeType
+		}
+	}
+	return resolved, exposeTypes, nil, nil
+}
+
+// applySite sets the connection details of the InfinispanSite which are not defined by the location
+func applySite(location *ispnv1.InfinispanSiteLocationSpec, site *infinispanv2alpha1.InfinispanSite) {
+	if location.URL == "" && location.Host == nil {
+		location.URL = site.Spec.URL
+	}
+	if location.SecretName == "" {
+		location.SecretName = site.Spec.SecretName
+	}
+	if location.Namespace == "" {
+		location.Namespace = site.Spec.Namespace
+	}
+	if location.ClusterName == "" {
+		location.ClusterName = site.Spec.ClusterName
+	}
+}
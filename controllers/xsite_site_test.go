@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplySite(t *testing.T) {
+	site := &infinispanv2alpha1.InfinispanSite{
+		ObjectMeta: metav1.ObjectMeta{Name: "site-b"},
+		Spec: infinispanv2alpha1.InfinispanSiteSpec{
+			URL:         "openshift://api.site-b.example.com:6443",
+			SecretName:  "site-b-token",
+			Namespace:   "xsite",
+			ClusterName: "example-clusterb",
+		},
+	}
+
+	location := ispnv1.InfinispanSiteLocationSpec{Name: "SiteB", Site: "site-b"}
+	applySite(&location, site)
+	assert.Equal(t, ispnv1.InfinispanSiteLocationSpec{Name: "SiteB", Site: "site-b", URL: site.Spec.URL, SecretName: "site-b-token",
+		Namespace: "xsite", ClusterName: "example-clusterb"}, location)
+
+	// The fields of the location take precedence
+	location = ispnv1.InfinispanSiteLocationSpec{Name: "SiteB", Site: "site-b", URL: "infinispan+xsite://site-b.example.com", ClusterName: "other"}
+	applySite(&location, site)
+	assert.Equal(t, "infinispan+xsite://site-b.example.com", location.URL)
+	assert.Equal(t, "other", location.ClusterName)
+	assert.Equal(t, "xsite", location.Namespace)
+}
+
+func TestSiteRefs(t *testing.T) {
+	assert.Nil(t, siteRefs(&ispnv1.Infinispan{}))
+	ispn := &ispnv1.Infinispan{
+		Spec: ispnv1.InfinispanSpec{
+			Service: ispnv1.InfinispanServiceSpec{
+				Sites: &ispnv1.InfinispanSitesSpec{
+					Locations: []ispnv1.InfinispanSiteLocationSpec{
+						{Name: "SiteA"},
+						{Name: "SiteB", Site: "site-b"},
+						{Name: "SiteC", Site: "site-c"},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{"site-b", "site-c"}, siteRefs(ispn))
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// XSiteStateTransferAtAnnotation requests the state of the caches to be pushed to the remote sites, with a RFC3339
+	// timestamp so that the transfer can be requested again
+	XSiteStateTransferAtAnnotation = "infinispan.org/xsiteStateTransferAt"
+	// XSiteStateTransferSitesAnnotation restricts the requested state transfer to a comma separated list of remote sites
+	XSiteStateTransferSitesAnnotation = "infinispan.org/xsiteStateTransferSites"
+
+	EventReasonXSiteStateTransferStarted = "XSiteStateTransferStarted"
+	EventReasonXSiteStateTransferSkipped = "XSiteStateTransferSkipped"
+)
+
+// validateXSiteStateTransfer verifies that the state transfer annotations request a transfer to the remote sites of
+// the cluster
+func validateXSiteStateTransfer(i *ispnv1.Infinispan) error {
+	requestedAt, ok := i.Annotations[XSiteStateTransferAtAnnotation]
+	if !ok {
+		return nil
+	}
+	if !i.HasSites() {
+		return fmt.Errorf("the %s annotation requires infinispan.spec.service.sites", XSiteStateTransferAtAnnotation)
+	}
+	if _, err := time.Parse(time.RFC3339, requestedAt); err != nil {
+		return fmt.Errorf("the %s annotation must be a RFC3339 timestamp: %w", XSiteStateTransferAtAnnotation, err)
+	}
+	remoteSites := i.GetRemoteSiteLocations()
+	for _, site := range xsiteStateTransferSites(i) {
+		if _, ok := remoteSites[site]; !ok {
+			return fmt.Errorf("the %s annotation references '%s', which is not a remote site location", XSiteStateTransferSitesAnnotation, site)
+		}
+	}
+	return nil
+}
+
+// xsiteStateTransferSites returns the remote sites the state transfer is requested for
+func xsiteStateTransferSites(i *ispnv1.Infinispan) []string {
+	var sites []string
+	if value := i.Annotations[XSiteStateTransferSitesAnnotation]; value != "" {
+		for _, site := range strings.Split(value, ",") {
+			if site = strings.TrimSpace(site); site != "" {
+				sites = append(sites, site)
+			}
+		}
+		return sites
+	}
+	for site := range i.GetRemoteSiteLocations() {
+		sites = append(sites, site)
+	}
+	sort.Strings(sites)
+	return sites
+}
+
+// reconcileXSiteStateTransfer pushes the state of the caches to the remote sites when requested with the
+// XSiteStateTransferAtAnnotation. Offline sites are brought online first, as the state can only be pushed to online
+// sites. The transfer is started in the background, as the remote sites may be slow to respond, and the progress of the
+// transfer is reported by the stateTransfer of the xsite status
+func (r *infinispanRequest) reconcileXSiteStateTransfer(podName string) (*ctrl.Result, error) {
+	infinispan := r.infinispan
+	requestedAt, ok := infinispan.Annotations[XSiteStateTransferAtAnnotation]
+	if !ok || requestedAt == infinispan.Status.XSiteStateTransferAt {
+		return nil, nil
+	}
+
+	sites := xsiteStateTransferSites(infinispan)
+	phase, err := r.runOperation(ispnv1.OperationXSiteStateTransfer, requestedAt, func(op *operation) error {
+		return pushXSiteState(op, sites, podName)
+	})
+	if err != nil || phase == ispnv1.OperationRunning {
+		// The cluster is reconciled again once the transfer is started
+		return nil, err
+	}
+	if err := r.update(func() {
+		infinispan.Status.XSiteStateTransferAt = requestedAt
+	}); err != nil {
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
+
+// pushXSiteState starts the state transfer to each of the sites
+func pushXSiteState(op *operation, sites []string, podName string) error {
+	cluster := op.cluster
+	statuses, err := cluster.GetXSiteStatus(podName)
+	if err != nil {
+		return err
+	}
+	for _, site := range sites {
+		status, ok := statuses[site]
+		if !ok {
+			op.event(corev1.EventTypeWarning, EventReasonXSiteStateTransferSkipped, fmt.Sprintf("No cache backs up to site %s", site))
+			continue
+		}
+		if status.Status != "online" {
+			op.log.Info("Bringing site online before the state transfer", "site", site, "status", status.Status)
+			if err := cluster.XSiteBringOnline(site, podName); err != nil {
+				return err
+			}
+		}
+		if err := cluster.XSitePushState(site, podName); err != nil {
+			return err
+		}
+		op.event(corev1.EventTypeNormal, EventReasonXSiteStateTransferStarted, fmt.Sprintf("State transfer to site %s started", site))
+	}
+	return nil
+}
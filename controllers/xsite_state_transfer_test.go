@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// stateTransferCluster records the sites brought online and the sites the state is pushed to
+type stateTransferCluster struct {
+	ispn.ClusterInterface
+	sites    map[string]ispn.XSiteStatus
+	online   []string
+	pushedTo []string
+}
+
+func (c *stateTransferCluster) GetXSiteStatus(podName string) (map[string]ispn.XSiteStatus, error) {
+	return c.sites, nil
+}
+
+func (c *stateTransferCluster) XSiteBringOnline(siteName, podName string) error {
+	c.online = append(c.online, siteName)
+	return nil
+}
+
+func (c *stateTransferCluster) XSitePushState(siteName, podName string) error {
+	c.pushedTo = append(c.pushedTo, siteName)
+	return nil
+}
+
+func stateTransferInfinispan(annotations map[string]string) *ispnv1.Infinispan {
+	i := staticXSiteInfinispan.DeepCopy()
+	i.CreationTimestamp = metav1.Now()
+	i.Spec.Service.Type = ispnv1.ServiceTypeDataGrid
+	i.Annotations = annotations
+	return i
+}
+
+func stateTransferRequest(t *testing.T, infinispan *ispnv1.Infinispan, cluster ispn.ClusterInterface) *infinispanRequest {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, ispnv1.AddToScheme(scheme))
+	return &infinispanRequest{
+		InfinispanReconciler: &InfinispanReconciler{
+			Client:     fake.NewFakeClientWithScheme(scheme, infinispan),
+			scheme:     scheme,
+			eventRec:   record.NewFakeRecorder(10),
+			operations: newOperationQueue(1, logf.Log, operationCluster(cluster)),
+		},
+		ctx:        context.TODO(),
+		infinispan: infinispan,
+		reqLogger:  logf.Log,
+	}
+}
+
+// reconcileXSiteStateTransfer reconciles the requested state transfer until it's completed in the background
+func reconcileXSiteStateTransfer(t *testing.T, r *infinispanRequest) {
+	requestedAt := r.infinispan.Annotations[XSiteStateTransferAtAnnotation]
+	assert.Eventually(t, func() bool {
+		result, err := r.reconcileXSiteStateTransfer("pod-0")
+		assert.Nil(t, result)
+		assert.NoError(t, err)
+		return r.infinispan.Status.XSiteStateTransferAt == requestedAt
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestValidateXSiteStateTransfer(t *testing.T) {
+	assert.NoError(t, validateXSiteStateTransfer(stateTransferInfinispan(nil)))
+	assert.NoError(t, validateXSiteStateTransfer(stateTransferInfinispan(map[string]string{
+		XSiteStateTransferAtAnnotation:    "2021-06-01T10:00:00Z",
+		XSiteStateTransferSitesAnnotation: "SiteB, SiteC",
+	})))
+
+	i := stateTransferInfinispan(map[string]string{XSiteStateTransferAtAnnotation: "now"})
+	assert.EqualError(t, validateXSiteStateTransfer(i), `the infinispan.org/xsiteStateTransferAt annotation must be a RFC3339 timestamp: parsing time "now" as "2006-01-02T15:04:05Z07:00": cannot parse "now" as "2006"`)
+
+	i = stateTransferInfinispan(map[string]string{
+		XSiteStateTransferAtAnnotation:    "2021-06-01T10:00:00Z",
+		XSiteStateTransferSitesAnnotation: "SiteA",
+	})
+	assert.EqualError(t, validateXSiteStateTransfer(i), "the infinispan.org/xsiteStateTransferSites annotation references 'SiteA', which is not a remote site location")
+
+	i.Spec.Service.Sites = nil
+	assert.EqualError(t, validateXSiteStateTransfer(i), "the infinispan.org/xsiteStateTransferAt annotation requires infinispan.spec.service.sites")
+}
+
+func TestReconcileXSiteStateTransfer(t *testing.T) {
+	infinispan := stateTransferInfinispan(map[string]string{XSiteStateTransferAtAnnotation: "2021-06-01T10:00:00Z"})
+	cluster := &stateTransferCluster{sites: map[string]ispn.XSiteStatus{
+		"SiteB": {Status: "offline"},
+		"SiteC": {Status: "online"},
+	}}
+	r := stateTransferRequest(t, infinispan, cluster)
+
+	reconcileXSiteStateTransfer(t, r)
+	assert.Equal(t, []string{"SiteB"}, cluster.online)
+	assert.Equal(t, []string{"SiteB", "SiteC"}, cluster.pushedTo)
+
+	updated := &ispnv1.Infinispan{}
+	assert.NoError(t, r.Client.Get(r.ctx, types.NamespacedName{Namespace: infinispan.Namespace, Name: infinispan.Name}, updated))
+	assert.Equal(t, "2021-06-01T10:00:00Z", updated.Status.XSiteStateTransferAt)
+	operation := updated.GetOperation(ispnv1.OperationXSiteStateTransfer)
+	assert.Equal(t, ispnv1.OperationSucceeded, operation.Phase)
+	assert.Equal(t, "2021-06-01T10:00:00Z", operation.ID)
+
+	// The transfer is only started once for each request
+	result, err := r.reconcileXSiteStateTransfer("pod-0")
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SiteB", "SiteC"}, cluster.pushedTo)
+}
+
+func TestReconcileXSiteStateTransferSites(t *testing.T) {
+	infinispan := stateTransferInfinispan(map[string]string{
+		XSiteStateTransferAtAnnotation:    "2021-06-01T10:00:00Z",
+		XSiteStateTransferSitesAnnotation: "SiteC",
+	})
+	cluster := &stateTransferCluster{sites: map[string]ispn.XSiteStatus{
+		"SiteB": {Status: "online"},
+		"SiteC": {Status: "online"},
+	}}
+	r := stateTransferRequest(t, infinispan, cluster)
+
+	reconcileXSiteStateTransfer(t, r)
+	assert.Empty(t, cluster.online)
+	assert.Equal(t, []string{"SiteC"}, cluster.pushedTo)
+}
+
+func TestXSiteStateTransferConfig(t *testing.T) {
+	assert.Nil(t, xsiteStateTransfer(nil))
+	stateTransfer := xsiteStateTransfer(&ispnv1.CrossSiteStateTransferSpec{
+		ChunkSize:      pointer.Int32Ptr(256),
+		TimeoutSeconds: pointer.Int32Ptr(30),
+		MaxRetries:     pointer.Int32Ptr(0),
+		Mode:           ispnv1.CrossSiteStateTransferAuto,
+	})
+	assert.Equal(t, int32(256), stateTransfer.ChunkSize)
+	assert.Equal(t, int64(30000), stateTransfer.Timeout)
+	assert.Equal(t, int32(0), *stateTransfer.MaxRetries)
+	assert.Equal(t, "AUTO", stateTransfer.Mode)
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+)
+
+// xsiteCluster reports the configured sites and push state, the remaining methods are not expected to be called
+type xsiteCluster struct {
+	ispn.ClusterInterface
+	sites     map[string]ispn.XSiteStatus
+	pushState map[string]map[string]string
+}
+
+func (c *xsiteCluster) GetXSiteStatus(podName string) (map[string]ispn.XSiteStatus, error) {
+	return c.sites, nil
+}
+
+func (c *xsiteCluster) CacheNames(podName string) ([]string, error) {
+	return []string{"___protobuf_metadata", "books", "authors", "local"}, nil
+}
+
+func (c *xsiteCluster) GetXSitePushStateStatus(cacheName, podName string) (map[string]string, error) {
+	if status, ok := c.pushState[cacheName]; ok {
+		return status, nil
+	}
+	return nil, fmt.Errorf("cache '%s' has no backups", cacheName)
+}
+
+func TestGetCrossSiteStatus(t *testing.T) {
+	cluster := &xsiteCluster{
+		sites: map[string]ispn.XSiteStatus{
+			"SiteC": {Status: "offline"},
+			"SiteB": {Status: "mixed", Online: []string{"books"}, Offline: []string{"authors"}},
+		},
+		pushState: map[string]map[string]string{
+			"books":   {"SiteB": "SENDING", "SiteC": "OK"},
+			"authors": {"SiteB": "ERROR"},
+		},
+	}
+	xsite, err := GetCrossSiteStatus("pod", cluster)
+	assert.NoError(t, err)
+	assert.Equal(t, []ispnv1.CrossSiteStatus{
+		{Name: "SiteB", Status: "mixed", StateTransfer: map[string]string{"books": "SENDING", "authors": "ERROR"}},
+		{Name: "SiteC", Status: "offline", StateTransfer: map[string]string{"books": "OK"}},
+	}, xsite)
+}
+
+func TestGetCrossSiteStatusNoBackups(t *testing.T) {
+	xsite, err := GetCrossSiteStatus("pod", &xsiteCluster{sites: map[string]ispn.XSiteStatus{}})
+	assert.NoError(t, err)
+	assert.Empty(t, xsite)
+}
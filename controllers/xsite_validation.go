@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// CrossSiteDialTimeout bounds the connection verifying that the Gossip router of a remote site is reachable
+const CrossSiteDialTimeout = 5 * time.Second
+
+var (
+	// dialCrossSite connects to the Gossip router of a remote site, replaced by the tests
+	dialCrossSite = func(address string, timeout time.Duration) error {
+		conn, err := net.DialTimeout("tcp", address, timeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	// remoteSiteClient connects to the Kubernetes API of a remote site, replaced by the tests
+	remoteSiteClient = func(config *restclient.Config, scheme *runtime.Scheme) (client.Client, error) {
+		return client.New(config, client.Options{Scheme: scheme})
+	}
+	// remoteSiteSecretKeys are the keys of the Secret holding the credentials of the remote sites, by URL scheme
+	remoteSiteSecretKeys = map[string][]string{
+		ispnv1.CrossSiteSchemeTypeKubernetes: {"certificate-authority", "client-certificate", "client-key"},
+		ispnv1.CrossSiteSchemeTypeMinikube:   {"certificate-authority", "client-certificate", "client-key"},
+		ispnv1.CrossSiteSchemeTypeOpenShift:  {"token"},
+	}
+)
+
+// validateCrossSite verifies that the site locations can be told apart and that the locations connecting to the
+// Kubernetes API of a remote site provide the Secret with its credentials
+func validateCrossSite(i *ispnv1.Infinispan) error {
+	if !i.HasSites() {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, location := range i.Spec.Service.Sites.Locations {
+		if names[location.Name] {
+			return fmt.Errorf("infinispan.spec.service.sites.locations contains the location '%s' more than once", location.Name)
+		}
+		names[location.Name] = true
+		if location.URL == "" || location.Site != "" {
+			continue
+		}
+		siteURL, err := url.Parse(location.URL)
+		if err != nil {
+			return fmt.Errorf("the URL of site location '%s' is invalid: %w", location.Name, err)
+		}
+		if _, ok := remoteSiteSecretKeys[siteURL.Scheme]; ok && location.SecretName == "" {
+			return fmt.Errorf("site location '%s' requires a secretName with the credentials of the %s cluster", location.Name, siteURL.Scheme)
+		}
+	}
+	return nil
+}
+
+// verifyCrossSite checks the connectivity to the remote sites before the server configuration is computed, so that
+// misconfigurations are reported with the CrossSiteConfigurationValid condition rather than by the JGroups stack of
+// the running pods. Invalid credentials block the configuration of the cluster, whereas unreachable sites are only
+// reported, as the remote sites are commonly deployed after the local one
+func (r configRequest) verifyCrossSite(resolved *ispnv1.Infinispan) (*reconcile.Result, error) {
+	var invalid, unreachable []string
+	remoteLocations := resolved.GetRemoteSiteLocations()
+	names := make([]string, 0, len(remoteLocations))
+	for name := range remoteLocations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		location := remoteLocations[name]
+		isInvalid, msg := r.verifyRemoteLocation(resolved, &location)
+		if msg == "" {
+			continue
+		}
+		if isInvalid {
+			invalid = append(invalid, msg)
+		} else {
+			unreachable = append(unreachable, msg)
+		}
+	}
+
+	i := r.infinispan
+	status, reason, msg := metav1.ConditionTrue, ispnv1.ReasonCrossSiteConfigurationValid, ""
+	if len(invalid) > 0 {
+		status, reason, msg = metav1.ConditionFalse, ispnv1.ReasonCrossSiteConfigurationInvalid, strings.Join(append(invalid, unreachable...), "; ")
+	} else if len(unreachable) > 0 {
+		status, reason, msg = metav1.ConditionFalse, ispnv1.ReasonCrossSiteUnreachable, strings.Join(unreachable, "; ")
+	}
+	if status == metav1.ConditionFalse {
+		r.eventRec.Event(i, corev1.EventTypeWarning, reason, msg)
+	}
+	if err := r.setInfinispanCondition(ispnv1.ConditionCrossSiteConfigurationValid, status, reason, msg); err != nil {
+		return &reconcile.Result{}, err
+	}
+	if len(invalid) > 0 {
+		r.reqLogger.Info("Postponing reconciliation. Invalid cross-site configuration", "reason", msg)
+		return &reconcile.Result{RequeueAfter: consts.DefaultWaitOnCreateResource}, nil
+	}
+	return nil, nil
+}
+
+// verifyRemoteLocation returns a message describing why the remote site can't be connected to, empty when the site is
+// reachable, and whether the location is misconfigured
+func (r configRequest) verifyRemoteLocation(resolved *ispnv1.Infinispan, location *ispnv1.InfinispanSiteLocationSpec) (bool, string) {
+	siteURL, err := url.Parse(location.URL)
+	if err != nil {
+		return true, fmt.Sprintf("the URL of site '%s' is invalid: %s", location.Name, err.Error())
+	}
+	switch siteURL.Scheme {
+	case "":
+		// The remote site is resolved by the x-site Service of a cluster of the same Kubernetes cluster
+		return false, ""
+	case consts.StaticCrossSiteUriSchema:
+		if siteURL.Hostname() == "" {
+			return false, ""
+		}
+		port := siteURL.Port()
+		if port == "" {
+			port = strconv.Itoa(consts.CrossSitePort)
+		}
+		address := net.JoinHostPort(siteURL.Hostname(), port)
+		if err := dialCrossSite(address, CrossSiteDialTimeout); err != nil {
+			return false, fmt.Sprintf("the Gossip router %s of site '%s' is unreachable: %s", address, location.Name, err.Error())
+		}
+		return false, ""
+	}
+
+	secretKeys, ok := remoteSiteSecretKeys[siteURL.Scheme]
+	if !ok {
+		return true, fmt.Sprintf("the URL scheme '%s' of site '%s' is not supported", siteURL.Scheme, location.Name)
+	}
+	if location.SecretName == "" {
+		return true, fmt.Sprintf("site '%s' requires a secretName with the credentials of the %s cluster", location.Name, siteURL.Scheme)
+	}
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(r.ctx, types.NamespacedName{Namespace: resolved.Namespace, Name: location.SecretName}, secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return true, fmt.Sprintf("the Secret '%s' of site '%s' does not exist", location.SecretName, location.Name)
+		}
+		return false, fmt.Sprintf("unable to retrieve the Secret '%s' of site '%s': %s", location.SecretName, location.Name, err.Error())
+	}
+	for _, key := range secretKeys {
+		if len(secret.Data[key]) == 0 {
+			return true, fmt.Sprintf("the Secret '%s' of site '%s' must contain a '%s' key", location.SecretName, location.Name, key)
+		}
+	}
+
+	restConfig, err := getRemoteSiteRESTConfig(resolved.Namespace, location, r.kubernetes, r.reqLogger, r.ctx)
+	if err != nil {
+		return true, fmt.Sprintf("unable to configure the connection to site '%s': %s", location.Name, err.Error())
+	}
+	remoteClient, err := remoteSiteClient(restConfig, r.scheme)
+	if err == nil {
+		service := &corev1.Service{}
+		remoteNamespace := resolved.GetRemoteSiteNamespace(location.Name)
+		remoteService := types.NamespacedName{Namespace: remoteNamespace, Name: resolved.GetRemoteSiteServiceName(location.Name)}
+		if err = remoteClient.Get(r.ctx, remoteService, service); k8serrors.IsNotFound(err) {
+			return false, fmt.Sprintf("the x-site Service '%s' does not exist in namespace '%s' of site '%s'", remoteService.Name, remoteNamespace, location.Name)
+		} else if k8serrors.IsForbidden(err) {
+			return true, fmt.Sprintf("the credentials of Secret '%s' are not allowed to get the Services of namespace '%s' of site '%s'", location.SecretName, remoteNamespace, location.Name)
+		}
+	}
+	switch {
+	case err == nil:
+		return false, ""
+	case k8serrors.IsUnauthorized(err):
+		return true, fmt.Sprintf("the credentials of Secret '%s' are rejected by site '%s', they may have expired", location.SecretName, location.Name)
+	default:
+		return false, fmt.Sprintf("unable to connect to the Kubernetes API of site '%s' at %s: %s", location.Name, restConfig.Host, err.Error())
+	}
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ispnv1 "github.com/infinispan/infinispan-operator/api/v1"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func crossSiteInfinispan(locations ...ispnv1.InfinispanSiteLocationSpec) *ispnv1.Infinispan {
+	ispn := &ispnv1.Infinispan{ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: "testing", CreationTimestamp: metav1.Now()}}
+	ispn.Spec.Service.Type = ispnv1.ServiceTypeDataGrid
+	ispn.Spec.Service.Sites = &ispnv1.InfinispanSitesSpec{
+		Local:     ispnv1.InfinispanSitesLocalSpec{Name: "site-a"},
+		Locations: locations,
+	}
+	return ispn
+}
+
+// serviceClient returns the result of the lookup of the remote x-site Service
+type serviceClient struct {
+	client.Client
+	err error
+}
+
+func (c *serviceClient) Get(ctx context.Context, key types.NamespacedName, obj client.Object) error {
+	return c.err
+}
+
+func TestValidateCrossSite(t *testing.T) {
+	assert.NoError(t, validateCrossSite(crossSiteInfinispan(
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-b", URL: "openshift://api.site-b.example.com:6443", SecretName: "site-b-token"},
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-c", URL: "infinispan+xsite://site-c.example.com:7900"},
+		// The Secret is provided by the InfinispanSite
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-d", URL: "kubernetes://api.site-d.example.com:6443", Site: "site-d"},
+	)))
+
+	assert.EqualError(t, validateCrossSite(crossSiteInfinispan(
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-b", URL: "openshift://api.site-b.example.com:6443"},
+	)), "site location 'site-b' requires a secretName with the credentials of the openshift cluster")
+
+	assert.EqualError(t, validateCrossSite(crossSiteInfinispan(
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-b", URL: "infinispan+xsite://site-b.example.com"},
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-b", URL: "infinispan+xsite://site-c.example.com"},
+	)), "infinispan.spec.service.sites.locations contains the location 'site-b' more than once")
+}
+
+func TestVerifyCrossSite(t *testing.T) {
+	dial := dialCrossSite
+	remote := remoteSiteClient
+	defer func() {
+		dialCrossSite = dial
+		remoteSiteClient = remote
+	}()
+	var dialed []string
+	dialCrossSite = func(address string, timeout time.Duration) error {
+		dialed = append(dialed, address)
+		return nil
+	}
+	remoteClient := &serviceClient{}
+	remoteSiteClient = func(config *restclient.Config, scheme *runtime.Scheme) (client.Client, error) {
+		assert.Equal(t, "token", config.BearerToken)
+		return remoteClient, nil
+	}
+
+	ispn := crossSiteInfinispan(
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-a"},
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-b", URL: "openshift://api.site-b.example.com:6443", SecretName: "site-b-token"},
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-c", URL: "infinispan+xsite://site-c.example.com"},
+		ispnv1.InfinispanSiteLocationSpec{Name: "site-d", URL: "infinispan+xsite://", Namespace: "site-d"},
+	)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "site-b-token", Namespace: "testing"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	c, scheme := transportEncryptionClient(t, ispn, secret)
+	r := configRequest{
+		ConfigReconciler: &ConfigReconciler{Client: c, scheme: scheme, kubernetes: &kube.Kubernetes{Client: c}, eventRec: record.NewFakeRecorder(10)},
+		infinispan:       ispn,
+		reqLogger:        ctrl.Log,
+		ctx:              context.TODO(),
+	}
+
+	result, err := r.verifyCrossSite(ispn)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.True(t, ispn.IsConditionTrue(ispnv1.ConditionCrossSiteConfigurationValid))
+	assert.Equal(t, []string{"site-c.example.com:7900"}, dialed)
+
+	// Unreachable sites are reported without blocking the configuration
+	dialCrossSite = func(address string, timeout time.Duration) error {
+		return errors.New("connection refused")
+	}
+	result, err = r.verifyCrossSite(ispn)
+	assert.Nil(t, result)
+	assert.NoError(t, err)
+	condition := ispn.GetCondition(ispnv1.ConditionCrossSiteConfigurationValid)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ispnv1.ReasonCrossSiteUnreachable, condition.Reason)
+	assert.Equal(t, "the Gossip router site-c.example.com:7900 of site 'site-c' is unreachable: connection refused", condition.Message)
+
+	// Rejected credentials block the configuration
+	remoteClient.err = k8serrors.NewUnauthorized("Unauthorized")
+	result, err = r.verifyCrossSite(ispn)
+	assert.NotNil(t, result)
+	assert.NoError(t, err)
+	condition = ispn.GetCondition(ispnv1.ConditionCrossSiteConfigurationValid)
+	assert.Equal(t, ispnv1.ReasonCrossSiteConfigurationInvalid, condition.Reason)
+	assert.Equal(t, "the credentials of Secret 'site-b-token' are rejected by site 'site-b', they may have expired; "+
+		"the Gossip router site-c.example.com:7900 of site 'site-c' is unreachable: connection refused", condition.Message)
+
+	remoteClient.err = k8serrors.NewForbidden(schema.GroupResource{Resource: "services"}, "example-infinispan-site", errors.New("forbidden"))
+	_, msg := r.verifyRemoteLocation(ispn, &ispn.Spec.Service.Sites.Locations[1])
+	assert.Equal(t, "the credentials of Secret 'site-b-token' are not allowed to get the Services of namespace 'testing' of site 'site-b'", msg)
+
+	remoteClient.err = k8serrors.NewNotFound(schema.GroupResource{Resource: "services"}, "example-infinispan-site")
+	invalid, msg := r.verifyRemoteLocation(ispn, &ispn.Spec.Service.Sites.Locations[1])
+	assert.False(t, invalid)
+	assert.Equal(t, "the x-site Service 'example-infinispan-site' does not exist in namespace 'testing' of site 'site-b'", msg)
+
+	// The Secret must hold the credentials required by the URL scheme
+	secret.Data = map[string][]byte{"certificate-authority": []byte("ca")}
+	assert.NoError(t, c.Update(context.TODO(), secret))
+	invalid, msg = r.verifyRemoteLocation(ispn, &ispn.Spec.Service.Sites.Locations[1])
+	assert.True(t, invalid)
+	assert.Equal(t, "the Secret 'site-b-token' of site 'site-b' must contain a 'token' key", msg)
+}
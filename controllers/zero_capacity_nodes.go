@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"fmt"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	"github.com/infinispan/infinispan-operator/pkg/hash"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// reconcileZeroCapacityNodes creates the StatefulSet of the zero-capacity pods from the pod template of the cluster
+// StatefulSet, so that they follow the changes of the cluster pods, or deletes it when no zero-capacity pod is requested
+func (r *infinispanRequest) reconcileZeroCapacityNodes(statefulSet *appsv1.StatefulSet, configMap *corev1.ConfigMap) (*ctrl.Result, error) {
+	i := r.infinispan
+	if i.GetZeroCapacityNodes() == 0 {
+		return nil, r.deleteZeroCapacityNodes()
+	}
+	zeroConfig, ok := configMap.Data[consts.ServerZeroCapacityConfigFilename]
+	if !ok {
+		r.reqLogger.Info("Waiting for the configuration of the zero-capacity pods")
+		return &ctrl.Result{RequeueAfter: consts.DefaultWaitOnCluster}, nil
+	}
+
+	zeroStatefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.GetZeroCapacityStatefulSetName(),
+			Namespace: i.Namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(r.ctx, r.Client, zeroStatefulSet, func() error {
+		desired := zeroCapacityStatefulSet(i, statefulSet, zeroConfig)
+		zeroStatefulSet.Labels = desired.Labels
+		zeroStatefulSet.Spec.Replicas = desired.Spec.Replicas
+		zeroStatefulSet.Spec.Template = desired.Spec.Template
+		ApplyPropagatedMetadata(i, zeroStatefulSet)
+		if zeroStatefulSet.CreationTimestamp.IsZero() {
+			zeroStatefulSet.Annotations = desired.Annotations
+			zeroStatefulSet.Spec.Selector = desired.Spec.Selector
+			zeroStatefulSet.Spec.UpdateStrategy = desired.Spec.UpdateStrategy
+			zeroStatefulSet.Spec.PodManagementPolicy = desired.Spec.PodManagementPolicy
+			return controllerutil.SetControllerReference(i, zeroStatefulSet, r.scheme)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.IsConflict(err) {
+			return &ctrl.Result{Requeue: true}, nil
+		}
+		return &ctrl.Result{}, fmt.Errorf("unable to reconcile the StatefulSet of the zero-capacity pods: %w", err)
+	}
+	if result != controllerutil.OperationResultNone {
+		r.reqLogger.Info(fmt.Sprintf("Zero-capacity StatefulSet %s", string(result)), "replicas", i.GetZeroCapacityNodes())
+	}
+	return nil, nil
+}
+
+// deleteZeroCapacityNodes deletes the StatefulSet of the zero-capacity pods, if any
+func (r *infinispanRequest) deleteZeroCapacityNodes() error {
+	zeroStatefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.infinispan.GetZeroCapacityStatefulSetName(),
+			Namespace: r.infinispan.Namespace,
+		},
+	}
+	if err := r.Client.Delete(r.ctx, zeroStatefulSet); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// zeroCapacityMembers returns the number of ready zero-capacity pods, the members of the cluster view in addition to
+// the cluster pods
+func (r *infinispanRequest) zeroCapacityMembers() (int, error) {
+	if r.infinispan.GetZeroCapacityNodes() == 0 {
+		return 0, nil
+	}
+	zeroStatefulSet := &appsv1.StatefulSet{}
+	name := types.NamespacedName{Namespace: r.infinispan.Namespace, Name: r.infinispan.GetZeroCapacityStatefulSetName()}
+	if err := r.Client.Get(r.ctx, name, zeroStatefulSet); err != nil {
+		if errors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return int(zeroStatefulSet.Status.ReadyReplicas), nil
+}
+
+// zeroCapacityStatefulSet returns the StatefulSet of the zero-capacity pods. The pods run the cluster pod template,
+// loading the zero-capacity configuration and storing the server data in an emptyDir volume as they don't own any
+// cache entry. They match the selector of the cluster Services, so that they join the cluster, but not the PodLabels
+// of the cluster pods
+func zeroCapacityStatefulSet(i *infinispanv1.Infinispan, statefulSet *appsv1.StatefulSet, zeroConfig string) *appsv1.StatefulSet {
+	labels := ZeroCapacityPodLabels(i.Name)
+	podLabels := ZeroCapacityPodLabels(i.Name)
+	i.AddOperatorLabelsForPods(podLabels)
+	i.AddLabelsForPods(podLabels)
+
+	template := statefulSet.Spec.Template.DeepCopy()
+	template.Labels = podLabels
+	spec := &template.Spec
+	// The readiness gate is only managed for the cluster pods
+	spec.ReadinessGates = nil
+	spec.Affinity = podAffinity(i, labels)
+	spec.TopologySpreadConstraints = topologySpreadConstraints(i, labels)
+
+	container := &spec.Containers[0]
+	setContainerEnv(container, "CONFIG_PATH", consts.ServerZeroCapacityConfigPath)
+	setContainerEnv(container, "CONFIG_HASH", hash.HashString(zeroConfig))
+	for _, mount := range container.VolumeMounts {
+		if mount.MountPath == DataMountPath && !hasVolume(spec, mount.Name) {
+			// The data volume of the cluster pods is a PersistentVolumeClaim template
+			spec.Volumes = append(spec.Volumes, corev1.Volume{
+				Name:         mount.Name,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			})
+		}
+	}
+
+	replicas := i.GetZeroCapacityNodes()
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        i.GetZeroCapacityStatefulSetName(),
+			Namespace:   i.Namespace,
+			Annotations: consts.DeploymentAnnotations,
+			Labels:      LabelsResource(i.Name, "infinispan-zero-capacity"),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			// The zero-capacity pods don't own data, so they can start and stop together
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Replicas: &replicas,
+			Template: *template,
+		},
+	}
+}
+
+// setContainerEnv sets the value of the container variable, adding it if absent
+func setContainerEnv(container *corev1.Container, name, value string) {
+	if index := kube.GetEnvVarIndex(name, &container.Env); index >= 0 {
+		container.Env[index].Value = value
+		return
+	}
+	container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: value})
+}
+
+func hasVolume(spec *corev1.PodSpec, name string) bool {
+	for _, volume := range spec.Volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
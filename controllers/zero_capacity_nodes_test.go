@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	config "github.com/infinispan/infinispan-operator/pkg/infinispan/configuration"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func clusterStatefulSet() *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: PodLabels("example-infinispan")},
+				Spec: corev1.PodSpec{
+					ReadinessGates: []corev1.PodReadinessGate{{ConditionType: PodConditionWellFormed}},
+					Containers: []corev1.Container{{
+						Name: InfinispanContainer,
+						Env: []corev1.EnvVar{
+							{Name: "CONFIG_PATH", Value: consts.ServerConfigPath},
+							{Name: "CONFIG_HASH", Value: "cluster"},
+						},
+						VolumeMounts: []corev1.VolumeMount{{Name: DataMountVolume, MountPath: DataMountPath}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestZeroCapacityStatefulSet(t *testing.T) {
+	i := &infinispanv1.Infinispan{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan", Namespace: namespace},
+		Spec: infinispanv1.InfinispanSpec{Service: infinispanv1.InfinispanServiceSpec{
+			Type:      infinispanv1.ServiceTypeDataGrid,
+			Container: &infinispanv1.InfinispanServiceContainerSpec{ZeroCapacityNodes: 2},
+		}},
+	}
+	statefulSet := clusterStatefulSet()
+	zero := zeroCapacityStatefulSet(i, statefulSet, "zero")
+
+	assert.Equal(t, "example-infinispan-zero", zero.Name)
+	assert.Equal(t, int32(2), *zero.Spec.Replicas)
+	assert.Equal(t, ZeroCapacityPodLabels(i.Name), zero.Spec.Selector.MatchLabels)
+	// The zero-capacity pods join the cluster Services, but are not listed with the cluster pods
+	podLabels := zero.Spec.Template.Labels
+	for key, value := range ServiceLabels(i.Name) {
+		assert.Equal(t, value, podLabels[key])
+	}
+	assert.NotContains(t, podLabels, "infinispan_cr")
+	assert.Empty(t, zero.Spec.Template.Spec.ReadinessGates)
+
+	env := zero.Spec.Template.Spec.Containers[0].Env
+	assert.Contains(t, env, corev1.EnvVar{Name: "CONFIG_PATH", Value: consts.ServerZeroCapacityConfigPath})
+	assert.NotContains(t, env, corev1.EnvVar{Name: "CONFIG_HASH", Value: "cluster"})
+	assert.Equal(t, []corev1.Volume{{
+		Name:         DataMountVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}}, zero.Spec.Template.Spec.Volumes)
+
+	// The cluster StatefulSet is not modified
+	assert.Equal(t, clusterStatefulSet().Spec, statefulSet.Spec)
+}
+
+func TestReconcileZeroCapacityNodes(t *testing.T) {
+	r := volumeExpansionRequest(t, "1Gi")
+	r.infinispan.Spec.Service.Container.ZeroCapacityNodes = 1
+	configMap := &corev1.ConfigMap{Data: map[string]string{consts.ServerConfigFilename: "cluster"}}
+	zeroName := types.NamespacedName{Namespace: namespace, Name: r.infinispan.GetZeroCapacityStatefulSetName()}
+
+	// Wait for the config controller to add the zero-capacity configuration
+	result, err := r.reconcileZeroCapacityNodes(clusterStatefulSet(), configMap)
+	assert.NoError(t, err)
+	assert.Equal(t, consts.DefaultWaitOnCluster, result.RequeueAfter)
+
+	configMap.Data[consts.ServerZeroCapacityConfigFilename] = "zero"
+	result, err = r.reconcileZeroCapacityNodes(clusterStatefulSet(), configMap)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	zero := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Client.Get(r.ctx, zeroName, zero))
+	assert.Equal(t, int32(1), *zero.Spec.Replicas)
+
+	r.infinispan.Spec.Service.Container.ZeroCapacityNodes = 3
+	_, err = r.reconcileZeroCapacityNodes(clusterStatefulSet(), configMap)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Client.Get(r.ctx, zeroName, zero))
+	assert.Equal(t, int32(3), *zero.Spec.Replicas)
+
+	r.infinispan.Spec.Service.Container.ZeroCapacityNodes = 0
+	result, err = r.reconcileZeroCapacityNodes(clusterStatefulSet(), configMap)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	assert.True(t, errors.IsNotFound(r.Client.Get(r.ctx, zeroName, zero)))
+}
+
+func TestZeroCapacityConfig(t *testing.T) {
+	serverConf := &config.InfinispanConfiguration{Infinispan: config.Infinispan{ClusterName: "example-infinispan"}}
+	zeroYaml, err := zeroCapacityConfig(serverConf, "")
+	assert.NoError(t, err)
+	zeroConf, err := config.FromYaml(zeroYaml)
+	assert.NoError(t, err)
+	assert.True(t, zeroConf.Infinispan.ZeroCapacityNode)
+	assert.Equal(t, "example-infinispan", zeroConf.Infinispan.ClusterName)
+	assert.False(t, serverConf.Infinispan.ZeroCapacityNode)
+}
@@ -23,9 +23,11 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -45,6 +47,8 @@ type zeroCapacityResource interface {
 	Exec(client http.HttpClient) error
 	// Return true when the operation(s) have completed, otherwise false
 	ExecStatus(client http.HttpClient) (zeroCapacityPhase, error)
+	// Cancel the operation(s) still in progress on the zero-capacity pod
+	Cancel(client http.HttpClient) error
 	// Utility method to return a metav1.Object in order to set the controller reference
 	AsMeta() metav1.Object
 }
@@ -63,6 +67,10 @@ type zeroCapacitySpec struct {
 	Container v1.InfinispanContainerSpec
 	// The labels to apply to the zero-capacity pod
 	PodLabels map[string]string
+	// Containers to run before the zero-capacity server starts, the Volume is mounted at its MountPath
+	InitContainers []corev1.Container
+	// Additional volumes required by the InitContainers
+	Volumes []corev1.Volume
 }
 
 type zeroCapacityVolumeSpec struct {
@@ -103,7 +111,7 @@ const (
 	ZeroUnknown zeroCapacityPhase = "Unknown"
 )
 
-func newZeroCapacityController(name string, reconciler zeroCapacityReconciler, mgr ctrl.Manager) error {
+func newZeroCapacityController(name string, reconciler zeroCapacityReconciler, mgr ctrl.Manager, predicates ...predicate.Predicate) error {
 	r := &zeroCapacityController{
 		Name:       name,
 		Client:     mgr.GetClient(),
@@ -116,7 +124,7 @@ func newZeroCapacityController(name string, reconciler zeroCapacityReconciler, m
 
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{Reconciler: r}).
-		For(reconciler.Type()).
+		For(reconciler.Type(), builder.WithPredicates(predicates...)).
 		Owns(&corev1.Pod{}).
 		Complete(r)
 }
@@ -143,6 +151,21 @@ func (z *zeroCapacityController) Reconcile(ctx context.Context, request reconcil
 	}
 
 	phase := instance.Phase()
+	obj := instance.AsMeta().(client.Object)
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return z.cancel(request, instance, ctx)
+	}
+	// The finalizer is only required whilst the operation can be in progress on the server
+	inProgress := phase != ZeroSucceeded && phase != ZeroFailed
+	if inProgress != controllerutil.ContainsFinalizer(obj, consts.ZeroCapacityFinalizer) {
+		if inProgress {
+			controllerutil.AddFinalizer(obj, consts.ZeroCapacityFinalizer)
+		} else {
+			controllerutil.RemoveFinalizer(obj, consts.ZeroCapacityFinalizer)
+		}
+		return reconcile.Result{}, z.Update(ctx, obj)
+	}
+
 	switch phase {
 	case "":
 		// Perform any transformations required on the CR for backwards-compatibility. Returning if a tranformation or error occurs
@@ -303,6 +326,41 @@ func (z *zeroCapacityController) cleanupResources(httpClient http.HttpClient, re
 	return reconcile.Result{}, logErr
 }
 
+// cancel stops the operation still in progress on the server when the resource is deleted, so that no server task is
+// left running, and then removes the finalizer of the resource
+func (z *zeroCapacityController) cancel(request reconcile.Request, instance zeroCapacityResource, ctx context.Context) (reconcile.Result, error) {
+	obj := instance.AsMeta().(client.Object)
+	if !controllerutil.ContainsFinalizer(obj, consts.ZeroCapacityFinalizer) {
+		return reconcile.Result{}, nil
+	}
+
+	infinispan := &v1.Infinispan{}
+	err := z.Get(ctx, types.NamespacedName{Namespace: request.Namespace, Name: instance.Cluster()}, infinispan)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, fmt.Errorf("unable to fetch CR '%s': %w", instance.Cluster(), err)
+	}
+
+	// The operation can only be in progress if the cluster and the zero-capacity pod exist
+	if err == nil && z.isZeroPodReady(request, ctx) {
+		httpClient, err := newHttpClient(infinispan, z.Kube, ctx)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if instance.Phase() == ZeroRunning {
+			if err := instance.Cancel(httpClient); err != nil {
+				return reconcile.Result{}, fmt.Errorf("unable to cancel operation on zero-capacity pod: %w", err)
+			}
+			z.Log.Info("Cancelled operation on zero-capacity pod", "request.Name", request.Name)
+		}
+		if result, err := z.cleanupResources(httpClient, request, ctx); err != nil {
+			return result, err
+		}
+	}
+
+	controllerutil.RemoveFinalizer(obj, consts.ZeroCapacityFinalizer)
+	return reconcile.Result{}, z.Update(ctx, obj)
+}
+
 func (z *zeroCapacityController) isZeroPodReady(request reconcile.Request, ctx context.Context) bool {
 	pod := &corev1.Pod{}
 	if err := z.Get(ctx, request.NamespacedName, pod); err != nil {
@@ -316,6 +374,9 @@ func (z *zeroCapacityController) zeroPodSpec(name, namespace, configMap string,
 	if err != nil {
 		return nil, err
 	}
+	// The JVM memory auto-tuned by the memory policy is sized for the zero-capacity container
+	envIspn := ispn.DeepCopy()
+	envIspn.Spec.Container.Memory = zeroSpec.Container.Memory
 	dataVolName := name + "-data"
 	labels := zeroSpec.PodLabels
 	ispn.AddLabelsForPods(labels)
@@ -327,13 +388,14 @@ func (z *zeroCapacityController) zeroPodSpec(name, namespace, configMap string,
 		},
 		Spec: corev1.PodSpec{
 			SecurityContext: podSecurityCtx,
+			Affinity:        architectureAffinity(ispn, nil),
 			Containers: []corev1.Container{{
 				Image:          ispn.ImageName(),
 				Name:           name,
-				Env:            PodEnv(ispn, nil),
-				LivenessProbe:  PodLivenessProbe(),
+				Env:            PodEnv(envIspn, nil),
+				LivenessProbe:  PodLivenessProbe(ispn),
 				Ports:          PodPorts(),
-				ReadinessProbe: PodReadinessProbe(),
+				ReadinessProbe: PodReadinessProbe(ispn),
 				Resources:      *podResources,
 				VolumeMounts: []corev1.VolumeMount{
 					{
@@ -395,11 +457,18 @@ func (z *zeroCapacityController) zeroPodSpec(name, namespace, configMap string,
 		AddVolumeChmodInitContainer("backup-chmod-pv", name, zeroSpec.Volume.MountPath, &pod.Spec)
 	}
 
-	AddVolumeForUserAuthentication(ispn, &pod.Spec)
+	for _, container := range zeroSpec.InitContainers {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: name, MountPath: zeroSpec.Volume.MountPath})
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, container)
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, zeroSpec.Volumes...)
+
+	AddVolumeForUserAuthentication(ispn, &pod.ObjectMeta, &pod.Spec)
 
 	if ispn.IsEncryptionEnabled() {
 		AddVolumesForEncryption(ispn, &pod.Spec)
 	}
+	applyPodSecurityProfile(ispn, &pod.Spec)
 	return pod, nil
 }
 
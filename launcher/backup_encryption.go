@@ -0,0 +1,104 @@
+package lancher
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/infinispan/infinispan-operator/controllers"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/backup"
+)
+
+// BackupEncryption runs the backup-encryption subcommand, encrypting or decrypting a backup archive with the key of the
+// Backup encryption Secret. The output replaces the input when they are the same file
+func BackupEncryption(args []string) {
+	var keyFile, input, output string
+	var decrypt bool
+	flags := flag.NewFlagSet(controllers.BackupEncryptionCommand, flag.ExitOnError)
+	flags.StringVar(&keyFile, "key-file", "", "The file containing the AES key.")
+	flags.StringVar(&input, "input", "", "The backup archive to encrypt or decrypt.")
+	flags.StringVar(&output, "output", "", "The file the result is written to, defaults to the input.")
+	flags.BoolVar(&decrypt, "decrypt", false, "Decrypt the archive instead of encrypting it.")
+	_ = flags.Parse(args)
+
+	if keyFile == "" || input == "" {
+		fmt.Fprintln(os.Stderr, "--key-file and --input must be provided")
+		flags.Usage()
+		os.Exit(2)
+	}
+	if output == "" {
+		output = input
+	}
+
+	if err := transformArchive(keyFile, input, output, decrypt); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to process the backup archive: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func transformArchive(keyFile, input, output string, decrypt bool) error {
+	key, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// A retried Job finds the archive it already encrypted, which is kept as is
+	encrypted := false
+	if !decrypt {
+		if encrypted, err = backup.IsEncrypted(in); err != nil {
+			return err
+		}
+		if encrypted && input == output {
+			return nil
+		}
+		if _, err := in.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	// The archive is never left half processed
+	return writeFile(output, func(out *os.File) (err error) {
+		switch {
+		case decrypt:
+			return backup.Decrypt(key, in, out)
+		case encrypted:
+			_, err = io.Copy(out, in)
+			return
+		default:
+			return backup.Encrypt(key, in, out)
+		}
+	})
+}
+
+// writeFile writes to a temporary file first, so that the file is never left half written
+func writeFile(path string, write func(out *os.File) error) (err error) {
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	err = write(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,95 @@
+package lancher
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/infinispan/infinispan-operator/controllers"
+	client "github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/client/http/direct"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/load"
+)
+
+// cacheLoadProgressInterval is the number of entries between two progress reports
+const cacheLoadProgressInterval = 1000
+
+// CacheLoad runs the cache-load subcommand, writing the entries of a data file to a cache. The progress is written to
+// stdout with the markers parsed by the CacheLoadJob controller
+func CacheLoad(args []string) {
+	var service, cache, format, file, mediaType string
+	var concurrency, maxErrors int
+	flags := flag.NewFlagSet(controllers.CacheLoadCommand, flag.ExitOnError)
+	flags.StringVar(&service, "service", "", "The admin service of the Infinispan cluster.")
+	flags.StringVar(&cache, "cache", "", "The cache the entries are written to.")
+	flags.StringVar(&format, "format", load.FormatJSON, "The format of the data file, CSV or JSON.")
+	flags.StringVar(&file, "file", "", "The data file.")
+	flags.StringVar(&mediaType, "value-media-type", "", "The media type of the values.")
+	flags.IntVar(&concurrency, "concurrency", 10, "The number of entries written concurrently.")
+	flags.IntVar(&maxErrors, "max-errors", 0, "The number of entries that can fail to be written.")
+	_ = flags.Parse(args)
+
+	if service == "" || cache == "" || file == "" {
+		fmt.Fprintln(os.Stderr, "--service, --cache and --file must be provided")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err := loadCache(service, cache, format, file, mediaType, concurrency, maxErrors); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load cache '%s': %v\n", cache, err)
+		os.Exit(1)
+	}
+}
+
+func loadCache(service, cache, format, file, mediaType string, concurrency, maxErrors int) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	reader, err := load.NewReader(format, in)
+	if err != nil {
+		return err
+	}
+
+	// The digest nonces are only valid on the server that issued them, so all the requests are sent to the same pod
+	addresses, err := net.LookupHost(service)
+	if err != nil {
+		return err
+	}
+	if len(addresses) == 0 {
+		return fmt.Errorf("service '%s' has no ready pods", service)
+	}
+	httpClient := direct.New(client.HttpConfig{
+		Credentials: &client.Credentials{
+			Username: os.Getenv(controllers.CacheLoadUsernameEnv),
+			Password: os.Getenv(controllers.CacheLoadPasswordEnv),
+		},
+		Protocol: "http",
+		Host:     addresses[0],
+	}, nil)
+
+	if mediaType == "" {
+		mediaType = controllers.CacheLoadDefaultMediaType(format)
+	}
+	loader := &load.Loader{
+		Client:         httpClient,
+		Cache:          cache,
+		ValueMediaType: mediaType,
+		Concurrency:    concurrency,
+		MaxErrors:      int64(maxErrors),
+	}
+	var last load.Progress
+	err = loader.Load(reader, func(progress load.Progress) {
+		if progress.Err != nil {
+			fmt.Printf("%s%v\n", controllers.CacheLoadErrorMarker, progress.Err)
+		}
+		if progress.Err != nil || (progress.Loaded+progress.Failed)%cacheLoadProgressInterval == 0 {
+			fmt.Printf("%s%d %d\n", controllers.CacheLoadProgressMarker, progress.Loaded, progress.Failed)
+		}
+		last = progress
+	})
+	fmt.Printf("%s%d %d\n", controllers.CacheLoadProgressMarker, last.Loaded, last.Failed)
+	return err
+}
@@ -0,0 +1,84 @@
+package lancher
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	"github.com/infinispan/infinispan-operator/controllers"
+	"github.com/infinispan/infinispan-operator/pkg/kubernetes"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// DebugBundle runs the debug-bundle subcommand, writing a tar.gz archive with the state of a cluster to a file or to
+// stdout so that it can be collected with kubectl exec
+func DebugBundle(args []string) {
+	var namespace, cluster, output string
+	var wait time.Duration
+	flags := flag.NewFlagSet(controllers.DebugBundleCommand, flag.ExitOnError)
+	flags.StringVar(&namespace, "namespace", "", "The namespace of the Infinispan cluster.")
+	flags.StringVar(&cluster, "cluster", "", "The name of the Infinispan cluster.")
+	flags.StringVar(&output, "output", "-", "The file the tar.gz bundle is written to, '-' writes it to stdout.")
+	flags.DurationVar(&wait, "wait", 0, "How long to wait once the bundle is written, so that it can be copied from the pod.")
+	_ = flags.Parse(args)
+
+	if namespace == "" || cluster == "" {
+		fmt.Fprintln(os.Stderr, "--namespace and --cluster must be provided")
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err := writeDebugBundle(namespace, cluster, output); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to collect the debug bundle: %v\n", err)
+		os.Exit(1)
+	}
+	if output != "-" {
+		fmt.Fprintf(os.Stderr, "Debug bundle written to %s\n", output)
+	}
+	time.Sleep(wait)
+}
+
+func writeDebugBundle(namespace, name, output string) (err error) {
+	ctx := context.Background()
+	kube, err := kubernetes.NewKubernetesFromConfig(ctrl.GetConfigOrDie(), scheme)
+	if err != nil {
+		return err
+	}
+
+	infinispan := &infinispanv1.Infinispan{}
+	if err = kube.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, infinispan); err != nil {
+		return err
+	}
+	cluster, err := controllers.NewPodCluster(infinispan, kube, ctx)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if output != "-" {
+		file, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			cerr := file.Close()
+			if err == nil {
+				err = cerr
+			}
+		}()
+		out = file
+	}
+
+	bundle := &controllers.DebugBundle{
+		Client:  kube.Client,
+		Cluster: cluster,
+		Logs:    kube.Logs,
+		Ctx:     ctx,
+	}
+	return bundle.Write(infinispan, out)
+}
@@ -4,7 +4,6 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -22,6 +21,7 @@ import (
 	infinispanv2alpha1 "github.com/infinispan/infinispan-operator/api/v2alpha1"
 	"github.com/infinispan/infinispan-operator/controllers"
 	grafanav1alpha1 "github.com/infinispan/infinispan-operator/pkg/apis/integreatly/v1alpha1"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/version"
 	"github.com/infinispan/infinispan-operator/pkg/kubernetes"
 	routev1 "github.com/openshift/api/route/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
@@ -61,6 +61,8 @@ func Launch(p Parameters) {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	var leaderElection leaderElectionTimings
+	leaderElection.bindFlags(flag.CommandLine)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -69,12 +71,24 @@ func Launch(p Parameters) {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	namespace, err := kubernetes.GetWatchNamespace()
+	if enableLeaderElection {
+		if err := leaderElection.validate(); err != nil {
+			setupLog.Error(err, "invalid leader election configuration")
+			os.Exit(1)
+		}
+	}
+
+	namespaces, err := kubernetes.GetWatchNamespaces()
 	if err != nil {
 		setupLog.Error(err, "failed to get watch namespace")
 		os.Exit(1)
 	}
 
+	if err := version.LoadOperands(); err != nil {
+		setupLog.Error(err, "failed to load the operand catalog")
+		os.Exit(1)
+	}
+
 	options := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -82,12 +96,26 @@ func Launch(p Parameters) {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "632512e4.infinispan.org",
-	}
-
-	if strings.Contains(namespace, ",") {
-		options.NewCache = cache.MultiNamespacedCacheBuilder(strings.Split(namespace, ","))
+		// Several replicas of the operator can run with leader election, the standby replicas taking over once the Lease
+		// of the active replica expires. The active replica releases the Lease when it's stopped, so that rolling updates
+		// of the operator Deployment don't wait for the Lease to expire
+		LeaderElectionReleaseOnCancel: enableLeaderElection,
+		LeaseDuration:                 &leaderElection.LeaseDuration,
+		RenewDeadline:                 &leaderElection.RenewDeadline,
+		RetryPeriod:                   &leaderElection.RetryPeriod,
+	}
+
+	// Each namespace of the allow-list is cached separately, so that the operator only lists and watches the resources
+	// of the namespaces it manages
+	if len(namespaces) > 1 {
+		options.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+	} else if len(namespaces) == 1 {
+		options.Namespace = namespaces[0]
+	}
+	if len(namespaces) == 0 {
+		setupLog.Info("Watching all namespaces")
 	} else {
-		options.Namespace = namespace
+		setupLog.Info("Watching namespaces", "namespaces", namespaces)
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
@@ -104,6 +132,10 @@ func Launch(p Parameters) {
 		setupLog.Error(err, "unable to create controller", "controller", "Backup")
 		os.Exit(1)
 	}
+	if err = (&controllers.BackupScheduleReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupSchedule")
+		os.Exit(1)
+	}
 	if err = (&controllers.RestoreReconciler{}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Restore")
 		os.Exit(1)
@@ -116,6 +148,22 @@ func Launch(p Parameters) {
 		setupLog.Error(err, "unable to create controller", "controller", "Cache")
 		os.Exit(1)
 	}
+	if err = (&controllers.CounterReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Counter")
+		os.Exit(1)
+	}
+	if err = (&controllers.ServerTaskReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ServerTask")
+		os.Exit(1)
+	}
+	if err = (&controllers.CachePolicyReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CachePolicy")
+		os.Exit(1)
+	}
+	if err = (&controllers.CacheLoadJobReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CacheLoadJob")
+		os.Exit(1)
+	}
 
 	if err = (&controllers.SecretReconciler{}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Secret")
@@ -136,6 +184,11 @@ func Launch(p Parameters) {
 		setupLog.Error(err, "unable to create controller", "controller", "OperatorConfig")
 		os.Exit(1)
 	}
+	// The webhook server requires a serving certificate, so webhooks are only enabled on request
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		controllers.SetupInfinispanWebhook(mgr)
+		controllers.SetupCacheWebhook(mgr)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
@@ -148,6 +201,13 @@ func Launch(p Parameters) {
 	}
 
 	setupLog.Info(fmt.Sprintf("Starting Infinispan Operator Version: %s", Version))
+	if enableLeaderElection {
+		setupLog.Info("Waiting for the leadership", "leaseDuration", leaderElection.LeaseDuration,
+			"renewDeadline", leaderElection.RenewDeadline, "retryPeriod", leaderElection.RetryPeriod)
+	}
+	// The manager returns an error when the leadership is lost, exiting so that the replica restarts as a standby one
+	// with empty caches, while the new leader resumes the reconciliation of all the resources from their state in the
+	// cluster
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
@@ -0,0 +1,69 @@
+package lancher
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// LeaseDurationEnv, RenewDeadlineEnv and RetryPeriodEnv override the default leader election timings, so that they
+	// can be tuned from the Subscription config of OLM installs without editing the Deployment arguments
+	LeaseDurationEnv = "LEADER_ELECTION_LEASE_DURATION"
+	RenewDeadlineEnv = "LEADER_ELECTION_RENEW_DEADLINE"
+	RetryPeriodEnv   = "LEADER_ELECTION_RETRY_PERIOD"
+
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// leaderElectionTimings are the timings of the Lease held by the active operator replica. The standby replicas take over
+// at most LeaseDuration after the active replica stops renewing the Lease
+type leaderElectionTimings struct {
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// bindFlags registers the leader election flags, defaulting to the environment variables
+func (t *leaderElectionTimings) bindFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&t.LeaseDuration, "leader-elect-lease-duration", durationEnv(LeaseDurationEnv, DefaultLeaseDuration),
+		"The duration that standby replicas wait before forcing the acquisition of the leadership.")
+	fs.DurationVar(&t.RenewDeadline, "leader-elect-renew-deadline", durationEnv(RenewDeadlineEnv, DefaultRenewDeadline),
+		"The duration that the active replica retries refreshing the leadership before giving it up.")
+	fs.DurationVar(&t.RetryPeriod, "leader-elect-retry-period", durationEnv(RetryPeriodEnv, DefaultRetryPeriod),
+		"The duration that the replicas wait between tries of acquiring or renewing the leadership.")
+}
+
+// validate checks the constraints of the client-go leader elector, which would otherwise only fail once the manager
+// starts
+func (t *leaderElectionTimings) validate() error {
+	if t.RetryPeriod <= 0 {
+		return fmt.Errorf("leader election retry period must be positive, got %s", t.RetryPeriod)
+	}
+	if t.LeaseDuration <= t.RenewDeadline {
+		return fmt.Errorf("leader election lease duration %s must be greater than the renew deadline %s", t.LeaseDuration, t.RenewDeadline)
+	}
+	// The leader elector requires the renew deadline to be greater than the jittered retry period
+	if float64(t.RenewDeadline) <= 1.2*float64(t.RetryPeriod) {
+		return fmt.Errorf("leader election renew deadline %s must be greater than 1.2 times the retry period %s", t.RenewDeadline, t.RetryPeriod)
+	}
+	return nil
+}
+
+// durationEnv returns the duration of the environment variable, or defValue when it is not set or invalid
+func durationEnv(name string, defValue time.Duration) time.Duration {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return defValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		// The flags are bound before the logger is configured
+		fmt.Fprintf(os.Stderr, "ignoring invalid %s '%s': %v\n", name, value, err)
+		return defValue
+	}
+	return d
+}
@@ -1,10 +1,24 @@
 package main
 
 import (
+	"os"
+
 	launcher "github.com/infinispan/infinispan-operator/launcher"
 	// +kubebuilder:scaffold:imports
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "debug-bundle" {
+		launcher.DebugBundle(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backup-encryption" {
+		launcher.BackupEncryption(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "cache-load" {
+		launcher.CacheLoad(os.Args[2:])
+		return
+	}
 	launcher.Launch(launcher.Parameters{})
 }
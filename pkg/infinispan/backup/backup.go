@@ -51,8 +51,11 @@ const (
 type ManagerInterface interface {
 	Backup(name string, config *BackupConfig) error
 	BackupStatus(name string) (Status, error)
+	BackupSize(name string) (int64, error)
+	DeleteBackup(name string) error
 	Restore(name string, config *RestoreConfig) error
 	RestoreStatus(name string) (Status, error)
+	DeleteRestore(name string) error
 }
 
 type Manager struct {
@@ -92,6 +95,31 @@ func (manager *Manager) BackupStatus(name string) (Status, error) {
 	return manager.status(url, name, "Backup")
 }
 
+// BackupSize returns the size in bytes of the archive of a completed backup, 0 if the server doesn't report it
+func (manager *Manager) BackupSize(name string) (int64, error) {
+	if err := manager.validator.Var(name, "required"); err != nil {
+		return 0, err
+	}
+	url := fmt.Sprintf("%s/%s", BackupUrl, name)
+	rsp, err, _ := manager.http.Head(manager.podName, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("Unable to retrieve the size of Backup '%s'. Unexpected response %d", name, rsp.StatusCode)
+	}
+	if rsp.ContentLength < 0 {
+		return 0, nil
+	}
+	return rsp.ContentLength, nil
+}
+
+// DeleteBackup removes the backup from the server. A backup still in progress is removed once it completes
+func (manager *Manager) DeleteBackup(name string) error {
+	url := fmt.Sprintf("%s/%s", BackupUrl, name)
+	return manager.delete(url, name, "Backup")
+}
+
 func (manager *Manager) Restore(name string, config *RestoreConfig) error {
 	if err := manager.validator.Var(name, "required"); err != nil {
 		return err
@@ -108,6 +136,12 @@ func (manager *Manager) RestoreStatus(name string) (Status, error) {
 	return manager.status(url, name, "Restore")
 }
 
+// DeleteRestore removes the restore from the server. A restore still in progress is removed once it completes
+func (manager *Manager) DeleteRestore(name string) error {
+	url := fmt.Sprintf("%s/%s", RestoreUrl, name)
+	return manager.delete(url, name, "Restore")
+}
+
 func (manager *Manager) post(url, op string, config interface{}) (err error) {
 	headers := map[string]string{"Content-Type": "application/json"}
 	json, err := json.Marshal(config)
@@ -134,6 +168,31 @@ func (manager *Manager) post(url, op string, config interface{}) (err error) {
 	return fmt.Errorf("%s failed. Unexpected response %d: '%s'", op, rsp.StatusCode, rsp.Body)
 }
 
+func (manager *Manager) delete(url, name, op string) (err error) {
+	if err = manager.validator.Var(name, "required"); err != nil {
+		return
+	}
+
+	rsp, err, _ := manager.http.Delete(manager.podName, url, nil)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	switch rsp.StatusCode {
+	case http.StatusAccepted, http.StatusNoContent, http.StatusNotFound:
+		return
+	default:
+		return fmt.Errorf("Unable to delete %s with name '%s'. Unexpected response %d: '%s'", op, name, rsp.StatusCode, bodyOrStatus(rsp))
+	}
+}
+
 func (manager *Manager) status(url, name, op string) (Status, error) {
 	if err := manager.validator.Var(name, "required"); err != nil {
 		return StatusUnknown, err
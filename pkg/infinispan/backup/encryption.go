@@ -0,0 +1,153 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The encrypted archive starts with the header magic and a random nonce prefix, followed by the chunks of the archive.
+// Each chunk is sealed with AES-GCM, using the prefix and the chunk index as nonce, and is preceded by a flag marking
+// the last chunk and the length of the sealed chunk. The flag is authenticated, so that a truncated archive is detected
+const (
+	encryptionMagic     = "ISPNBAK1"
+	encryptionChunkSize = 64 * 1024
+	noncePrefixSize     = 8
+	lastChunkFlag       = byte(1)
+)
+
+// ValidateEncryptionKey verifies that the key is an AES-128, AES-192 or AES-256 key
+func ValidateEncryptionKey(key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return fmt.Errorf("the encryption key must be 16, 24 or 32 bytes long, got %d bytes", len(key))
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if err := ValidateEncryptionKey(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, index uint32) []byte {
+	nonce := make([]byte, noncePrefixSize+4)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], index)
+	return nonce
+}
+
+// IsEncrypted returns true when the archive read from in starts with the header magic written by Encrypt. A zip
+// archive starts with its own signature, so it's never mistaken for an encrypted archive
+func IsEncrypted(in io.Reader) (bool, error) {
+	magic := make([]byte, len(encryptionMagic))
+	if _, err := io.ReadFull(in, magic); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(magic, []byte(encryptionMagic)), nil
+}
+
+// Encrypt writes the archive read from in to out, encrypted with AES-GCM
+func Encrypt(key []byte, in io.Reader, out io.Writer) error {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return err
+	}
+	if _, err := out.Write(append([]byte(encryptionMagic), prefix...)); err != nil {
+		return err
+	}
+
+	current := make([]byte, encryptionChunkSize)
+	next := make([]byte, encryptionChunkSize)
+	n, err := io.ReadFull(in, current)
+	for index := uint32(0); ; index++ {
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+		// The chunk is the last one when no data follows it
+		var nextN int
+		var nextErr error
+		if err == nil {
+			nextN, nextErr = io.ReadFull(in, next)
+		}
+		last := err != nil || nextErr == io.EOF
+
+		header := make([]byte, 5)
+		if last {
+			header[0] = lastChunkFlag
+		}
+		sealed := gcm.Seal(nil, chunkNonce(prefix, index), current[:n], header[:1])
+		binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+		if _, err := out.Write(append(header, sealed...)); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		if index == ^uint32(0) {
+			return errors.New("the archive is too large to be encrypted")
+		}
+		current, next = next, current
+		n, err = nextN, nextErr
+	}
+}
+
+// Decrypt writes the archive encrypted by Encrypt read from in to out, failing if the archive was modified or truncated
+func Decrypt(key []byte, in io.Reader, out io.Writer) error {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, len(encryptionMagic)+noncePrefixSize)
+	if _, err := io.ReadFull(in, header); err != nil {
+		return fmt.Errorf("unable to read the encrypted archive header: %w", err)
+	}
+	if !bytes.Equal(header[:len(encryptionMagic)], []byte(encryptionMagic)) {
+		return errors.New("the archive is not encrypted")
+	}
+	prefix := header[len(encryptionMagic):]
+
+	chunkHeader := make([]byte, 5)
+	for index := uint32(0); ; index++ {
+		if _, err := io.ReadFull(in, chunkHeader); err != nil {
+			return fmt.Errorf("the encrypted archive is truncated: %w", err)
+		}
+		length := binary.BigEndian.Uint32(chunkHeader[1:])
+		if length > encryptionChunkSize+uint32(gcm.Overhead()) {
+			return fmt.Errorf("invalid encrypted chunk length %d", length)
+		}
+		sealed := make([]byte, length)
+		if _, err := io.ReadFull(in, sealed); err != nil {
+			return fmt.Errorf("the encrypted archive is truncated: %w", err)
+		}
+		chunk, err := gcm.Open(nil, chunkNonce(prefix, index), sealed, chunkHeader[:1])
+		if err != nil {
+			return errors.New("unable to decrypt the archive, the key is invalid or the archive was modified")
+		}
+		if _, err := out.Write(chunk); err != nil {
+			return err
+		}
+		if chunkHeader[0] == lastChunkFlag {
+			return nil
+		}
+	}
+}
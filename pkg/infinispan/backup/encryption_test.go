@@ -0,0 +1,64 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryption(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	for _, size := range []int{0, 10, encryptionChunkSize, 2*encryptionChunkSize + 10} {
+		archive := make([]byte, size)
+		_, err := rand.Read(archive)
+		assert.NoError(t, err)
+
+		encrypted := &bytes.Buffer{}
+		assert.NoError(t, Encrypt(key, bytes.NewReader(archive), encrypted))
+		assert.False(t, size > 0 && bytes.Contains(encrypted.Bytes(), archive))
+
+		decrypted := &bytes.Buffer{}
+		assert.NoError(t, Decrypt(key, bytes.NewReader(encrypted.Bytes()), decrypted))
+		assert.True(t, bytes.Equal(archive, decrypted.Bytes()))
+
+		// Truncated archives are rejected, even when the cut is on a chunk boundary
+		truncated := encrypted.Bytes()[:encrypted.Len()-1]
+		assert.Error(t, Decrypt(key, bytes.NewReader(truncated), &bytes.Buffer{}))
+	}
+
+	archive := []byte("archive")
+	encrypted := &bytes.Buffer{}
+	assert.NoError(t, Encrypt(key, bytes.NewReader(archive), encrypted))
+
+	otherKey := make([]byte, 32)
+	assert.Error(t, Decrypt(otherKey, bytes.NewReader(encrypted.Bytes()), &bytes.Buffer{}))
+
+	modified := encrypted.Bytes()
+	modified[len(modified)-1] ^= 1
+	assert.Error(t, Decrypt(key, bytes.NewReader(modified), &bytes.Buffer{}))
+
+	assert.Error(t, Decrypt(key, bytes.NewReader(archive), &bytes.Buffer{}))
+	assert.Error(t, Encrypt([]byte("short"), bytes.NewReader(archive), &bytes.Buffer{}))
+}
+
+func TestIsEncrypted(t *testing.T) {
+	key := make([]byte, 16)
+	encrypted := &bytes.Buffer{}
+	assert.NoError(t, Encrypt(key, bytes.NewReader([]byte("archive")), encrypted))
+
+	for archive, expected := range map[string]bool{
+		encrypted.String():  true,
+		"PK\x03\x04archive": false,
+		encryptionMagic[:4]: false,
+		"":                  false,
+	} {
+		actual, err := IsEncrypted(bytes.NewReader([]byte(archive)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, "archive %q", archive)
+	}
+}
@@ -1,6 +1,8 @@
 package caches
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
 
 	"github.com/go-logr/logr"
@@ -11,16 +13,46 @@ import (
 
 // DefaultCacheTemplateXML return default template for cache
 func DefaultCacheTemplateXML(podName string, infinispan *infinispanv1.Infinispan, cluster ispn.ClusterInterface, logger logr.Logger) (string, error) {
+	evictTotalMemoryBytes, err := offHeapSizeBytes(podName, cluster, logger)
+	if err != nil {
+		return "", err
+	}
+	replicationFactor := infinispan.Spec.Service.ReplicationFactor
+	whenSplit, mergePolicy := infinispan.GetPartitionHandling()
+	return fmt.Sprintf(consts.DefaultCacheTemplate, consts.DefaultCacheName, replicationFactor, evictTotalMemoryBytes, whenSplit, mergePolicy), nil
+}
+
+// IndexedCacheTemplateXML return default template for cache with indexing enabled for the given entities
+func IndexedCacheTemplateXML(podName string, infinispan *infinispanv1.Infinispan, indexedEntities []string, cluster ispn.ClusterInterface, logger logr.Logger) (string, error) {
+	evictTotalMemoryBytes, err := offHeapSizeBytes(podName, cluster, logger)
+	if err != nil {
+		return "", err
+	}
+
+	entities := new(bytes.Buffer)
+	for _, entity := range indexedEntities {
+		entities.WriteString("\n<indexed-entity>")
+		if err := xml.EscapeText(entities, []byte(entity)); err != nil {
+			return "", err
+		}
+		entities.WriteString("</indexed-entity>")
+	}
+	replicationFactor := infinispan.Spec.Service.ReplicationFactor
+	whenSplit, mergePolicy := infinispan.GetPartitionHandling()
+	return fmt.Sprintf(consts.IndexedCacheTemplate, consts.DefaultCacheName, replicationFactor, evictTotalMemoryBytes, entities.String(), whenSplit, mergePolicy), nil
+}
+
+func offHeapSizeBytes(podName string, cluster ispn.ClusterInterface, logger logr.Logger) (uint64, error) {
 	memoryLimitBytes, err := cluster.GetMemoryLimitBytes(podName)
 	if err != nil {
 		logger.Error(err, "unable to extract memory limit (bytes) from pod")
-		return "", err
+		return 0, err
 	}
 
 	maxUnboundedMemory, err := cluster.GetMaxMemoryUnboundedBytes(podName)
 	if err != nil {
 		logger.Error(err, "unable to extract max memory unbounded from pod")
-		return "", err
+		return 0, err
 	}
 
 	containerMaxMemory := maxUnboundedMemory
@@ -30,11 +62,9 @@ func DefaultCacheTemplateXML(podName string, infinispan *infinispanv1.Infinispan
 
 	nativeMemoryOverhead := containerMaxMemory * (consts.CacheServiceJvmNativePercentageOverhead / 100)
 	evictTotalMemoryBytes := containerMaxMemory - (consts.CacheServiceJvmNativeMb * 1024 * 1024) - (consts.CacheServiceFixedMemoryXmxMb * 1024 * 1024) - nativeMemoryOverhead
-	replicationFactor := infinispan.Spec.Service.ReplicationFactor
 
 	logger.Info("calculated maximum off-heap size", "size", evictTotalMemoryBytes, "container max memory", containerMaxMemory, "memory limit (bytes)", memoryLimitBytes, "max memory bound", maxUnboundedMemory)
-
-	return fmt.Sprintf(consts.DefaultCacheTemplate, consts.DefaultCacheName, replicationFactor, evictTotalMemoryBytes), nil
+	return evictTotalMemoryBytes, nil
 }
 
 func CreateCacheFromDefault(podName string, infinispan *infinispanv1.Infinispan, cluster ispn.ClusterInterface, logger logr.Logger) error {
@@ -0,0 +1,58 @@
+package caches
+
+import (
+	"fmt"
+	"testing"
+
+	infinispanv1 "github.com/infinispan/infinispan-operator/api/v1"
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	ispn "github.com/infinispan/infinispan-operator/pkg/infinispan"
+	"github.com/stretchr/testify/assert"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// memoryCluster reports a fixed amount of memory, the remaining methods are not expected to be called
+type memoryCluster struct {
+	ispn.ClusterInterface
+	memoryBytes uint64
+}
+
+func (c memoryCluster) GetMemoryLimitBytes(podName string) (uint64, error) {
+	return c.memoryBytes, nil
+}
+
+func (c memoryCluster) GetMaxMemoryUnboundedBytes(podName string) (uint64, error) {
+	return c.memoryBytes, nil
+}
+
+func TestIndexedCacheTemplateXML(t *testing.T) {
+	infinispan := &infinispanv1.Infinispan{
+		Spec: infinispanv1.InfinispanSpec{
+			Service: infinispanv1.InfinispanServiceSpec{
+				ReplicationFactor: 2,
+			},
+		},
+	}
+	cluster := memoryCluster{memoryBytes: 1024 * 1024 * 1024}
+	offHeap, err := offHeapSizeBytes("pod-0", cluster, logf.Log)
+	assert.NoError(t, err)
+
+	xml, err := IndexedCacheTemplateXML("pod-0", infinispan, []string{"book_sample.Book", "sample.<Author>&'Co'"}, cluster, logf.Log)
+	assert.NoError(t, err)
+
+	// Entity names are XML escaped, which also keeps single quotes out of the curl payload
+	entities := "\n<indexed-entity>book_sample.Book</indexed-entity>" +
+		"\n<indexed-entity>sample.&lt;Author&gt;&amp;&#39;Co&#39;</indexed-entity>"
+	assert.Equal(t, fmt.Sprintf(consts.IndexedCacheTemplate, consts.DefaultCacheName, 2, offHeap, entities, infinispanv1.WhenSplitAllowReadWrites, infinispanv1.MergePolicyRemoveAll), xml)
+	assert.NotContains(t, xml, "'")
+
+	// The partition handling is only configurable for a DataGrid service
+	infinispan.Spec.Service.PartitionHandling = &infinispanv1.InfinispanPartitionHandlingSpec{WhenSplit: infinispanv1.WhenSplitDenyReadWrites, MergePolicy: infinispanv1.MergePolicyPreferredAlways}
+	xml, err = DefaultCacheTemplateXML("pod-0", infinispan, cluster, logf.Log)
+	assert.NoError(t, err)
+	assert.Contains(t, xml, `<partition-handling when-split="ALLOW_READ_WRITES" merge-policy="REMOVE_ALL" />`)
+	infinispan.Spec.Service.Type = infinispanv1.ServiceTypeDataGrid
+	xml, err = DefaultCacheTemplateXML("pod-0", infinispan, cluster, logf.Log)
+	assert.NoError(t, err)
+	assert.Contains(t, xml, `<partition-handling when-split="DENY_READ_WRITES" merge-policy="PREFERRED_ALWAYS" />`)
+}
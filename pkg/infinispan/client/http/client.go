@@ -13,6 +13,11 @@ type HttpConfig struct {
 	Credentials *Credentials
 	Namespace   string
 	Protocol    string
+	// ServiceName is the headless service that the pods are resolved with when the REST calls are not executed in the pods
+	ServiceName string
+	// Host is the address that the REST calls are sent to instead of the pods, e.g. by the Jobs that have no access to
+	// the Kubernetes API
+	Host string
 }
 
 type HttpClient interface {
@@ -20,4 +25,5 @@ type HttpClient interface {
 	Get(podName, path string, headers map[string]string) (*http.Response, error, string)
 	Post(podName, path, payload string, headers map[string]string) (*http.Response, error, string)
 	Put(podName, path, payload string, headers map[string]string) (*http.Response, error, string)
+	Delete(podName, path string, headers map[string]string) (*http.Response, error, string)
 }
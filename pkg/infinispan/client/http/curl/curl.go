@@ -39,7 +39,7 @@ func (c *CurlClient) Head(podName, path string, headers map[string]string) (*htt
 func (c *CurlClient) Post(podName, path, payload string, headers map[string]string) (*http.Response, error, string) {
 	data := ""
 	if payload != "" {
-		data = fmt.Sprintf("-d $'%s'", payload)
+		data = fmt.Sprintf("-d $'%s'", escapePayload(payload))
 	}
 	return c.executeCurlCommand(podName, path, headers, data, "-X POST")
 }
@@ -47,11 +47,15 @@ func (c *CurlClient) Post(podName, path, payload string, headers map[string]stri
 func (c *CurlClient) Put(podName, path, payload string, headers map[string]string) (*http.Response, error, string) {
 	data := ""
 	if payload != "" {
-		data = fmt.Sprintf("-d $'%s'", payload)
+		data = fmt.Sprintf("-d $'%s'", escapePayload(payload))
 	}
 	return c.executeCurlCommand(podName, path, headers, data, "-X PUT")
 }
 
+func (c *CurlClient) Delete(podName, path string, headers map[string]string) (*http.Response, error, string) {
+	return c.executeCurlCommand(podName, path, headers, "-X DELETE")
+}
+
 func (c *CurlClient) executeCurlCommand(podName string, path string, headers map[string]string, args ...string) (*http.Response, error, string) {
 	httpURL := fmt.Sprintf("%s://%s:%d/%s", c.config.Protocol, podName, consts.InfinispanAdminPort, path)
 
@@ -124,6 +128,11 @@ func handleContent(reader *bufio.Reader) (*http.Response, error, string) {
 	return rsp, nil, ""
 }
 
+// escapePayload escapes backslashes and single quotes, as the payload is passed to curl as an ANSI-C quoted string
+func escapePayload(payload string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(payload)
+}
+
 func headerString(headers map[string]string) string {
 	if headers == nil {
 		return ""
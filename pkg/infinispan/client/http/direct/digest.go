@@ -0,0 +1,125 @@
+package direct
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+
+	client "github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
+)
+
+// newCnonce generates the client nonce of the Authorization header
+var newCnonce = func() (string, error) {
+	cnonce := make([]byte, 16)
+	if _, err := rand.Read(cnonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(cnonce), nil
+}
+
+// challenge is a HTTP Digest authentication challenge, as defined by RFC 7616
+type challenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+}
+
+// digestChallenge parses the first Digest challenge of the WWW-Authenticate headers, nil if there is none
+func digestChallenge(headers []string) *challenge {
+	for _, header := range headers {
+		if !strings.HasPrefix(strings.ToLower(header), "digest ") {
+			continue
+		}
+		params := parseParams(header[len("digest "):])
+		c := &challenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: params["algorithm"],
+		}
+		// Only the auth quality of protection is supported, as the body of the request isn't hashed
+		for _, qop := range strings.Split(params["qop"], ",") {
+			if strings.TrimSpace(qop) == "auth" {
+				c.qop = "auth"
+			}
+		}
+		return c
+	}
+	return nil
+}
+
+// parseParams parses the comma separated key=value parameters of a challenge, the values are optionally quoted
+func parseParams(s string) map[string]string {
+	params := map[string]string{}
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else if comma := strings.IndexByte(s, ','); comma >= 0 {
+			value, s = s[:comma], s[comma:]
+		} else {
+			value, s = s, ""
+		}
+		params[key] = strings.TrimSpace(value)
+	}
+	return params
+}
+
+// authorize returns the Authorization header answering the challenge
+func (c *challenge) authorize(credentials *client.Credentials, method, uri string) (string, error) {
+	var h func() hash.Hash
+	switch strings.ToUpper(c.algorithm) {
+	case "", "MD5":
+		h = md5.New
+	case "SHA-256":
+		h = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm '%s'", c.algorithm)
+	}
+	digest := func(s string) string {
+		d := h()
+		d.Write([]byte(s))
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	ha1 := digest(fmt.Sprintf("%s:%s:%s", credentials.Username, c.realm, credentials.Password))
+	ha2 := digest(fmt.Sprintf("%s:%s", method, uri))
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username="%s", realm="%s", nonce="%s", uri="%s"`, credentials.Username, c.realm, c.nonce, uri)
+	if c.qop == "" {
+		fmt.Fprintf(&b, `, response="%s"`, digest(fmt.Sprintf("%s:%s:%s", ha1, c.nonce, ha2)))
+	} else {
+		cn, err := newCnonce()
+		if err != nil {
+			return "", err
+		}
+		const nc = "00000001"
+		response := digest(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, c.nonce, nc, cn, c.qop, ha2))
+		fmt.Fprintf(&b, `, qop=%s, nc=%s, cnonce="%s", response="%s"`, c.qop, nc, cn, response)
+	}
+	if c.algorithm != "" {
+		fmt.Fprintf(&b, ", algorithm=%s", c.algorithm)
+	}
+	if c.opaque != "" {
+		fmt.Fprintf(&b, `, opaque="%s"`, c.opaque)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,35 @@
+package direct
+
+import (
+	"testing"
+
+	client "github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestAuthorization(t *testing.T) {
+	cnonce := newCnonce
+	defer func() { newCnonce = cnonce }()
+	newCnonce = func() (string, error) { return "0a4f113b", nil }
+
+	// The example of RFC 2617
+	c := digestChallenge([]string{
+		`Basic realm="ignored"`,
+		`Digest realm="testrealm@host.com", qop="auth,auth-int", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`,
+	})
+	assert.Equal(t, &challenge{
+		realm:  "testrealm@host.com",
+		nonce:  "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+		opaque: "5ccc069c403ebaf9f0171e9517f40e41",
+		qop:    "auth",
+	}, c)
+
+	authorization, err := c.authorize(&client.Credentials{Username: "Mufasa", Password: "Circle Of Life"}, "GET", "/dir/index.html")
+	assert.NoError(t, err)
+	assert.Equal(t, `Digest username="Mufasa", realm="testrealm@host.com", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", uri="/dir/index.html", `+
+		`qop=auth, nc=00000001, cnonce="0a4f113b", response="6629fae49393a05397450978507c4ef1", opaque="5ccc069c403ebaf9f0171e9517f40e41"`, authorization)
+
+	assert.Nil(t, digestChallenge([]string{`Basic realm="default"`}))
+	_, err = (&challenge{algorithm: "SHA-512-256"}).authorize(&client.Credentials{}, "GET", "/")
+	assert.Error(t, err)
+}
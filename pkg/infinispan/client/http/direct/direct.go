@@ -0,0 +1,119 @@
+package direct
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	client "github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
+	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
+)
+
+// DirectClient sends the REST calls from the operator to the admin endpoint of a specific pod, resolving the pod with
+// the Endpoints of the headless admin service. Unlike the curl client it doesn't require exec permissions on the pods
+// nor curl in the server image, but the operator must be able to reach the pods
+type DirectClient struct {
+	credentials *client.Credentials
+	config      client.HttpConfig
+	http        *http.Client
+	*kube.Kubernetes
+}
+
+func New(c client.HttpConfig, kubernetes *kube.Kubernetes) *DirectClient {
+	return &DirectClient{
+		config:      c,
+		credentials: c.Credentials,
+		http: &http.Client{
+			Timeout: time.Minute,
+			Transport: &http.Transport{
+				// Same as the curl --insecure flag, the pods are addressed by IP so the hostname can't be verified
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+		Kubernetes: kubernetes,
+	}
+}
+
+func (c *DirectClient) Get(podName, path string, headers map[string]string) (*http.Response, error, string) {
+	return c.execute(podName, http.MethodGet, path, "", headers)
+}
+
+func (c *DirectClient) Head(podName, path string, headers map[string]string) (*http.Response, error, string) {
+	return c.execute(podName, http.MethodHead, path, "", headers)
+}
+
+func (c *DirectClient) Post(podName, path, payload string, headers map[string]string) (*http.Response, error, string) {
+	return c.execute(podName, http.MethodPost, path, payload, headers)
+}
+
+func (c *DirectClient) Put(podName, path, payload string, headers map[string]string) (*http.Response, error, string) {
+	return c.execute(podName, http.MethodPut, path, payload, headers)
+}
+
+func (c *DirectClient) Delete(podName, path string, headers map[string]string) (*http.Response, error, string) {
+	return c.execute(podName, http.MethodDelete, path, "", headers)
+}
+
+func (c *DirectClient) execute(podName, method, path, payload string, headers map[string]string) (*http.Response, error, string) {
+	address := c.config.Host
+	if address == "" {
+		var err error
+		if address, err = c.Kubernetes.PodAddress(c.config.ServiceName, podName, c.config.Namespace, context.TODO()); err != nil {
+			return nil, err, ""
+		}
+	}
+	httpURL := fmt.Sprintf("%s://%s/%s", c.config.Protocol, net.JoinHostPort(address, strconv.Itoa(consts.InfinispanAdminPort)), path)
+
+	rsp, err := c.send(method, httpURL, payload, headers, "")
+	if err != nil || rsp.StatusCode != http.StatusUnauthorized || c.credentials == nil {
+		return rsp, err, ""
+	}
+	challenge := digestChallenge(rsp.Header.Values("WWW-Authenticate"))
+	if challenge == nil {
+		return rsp, nil, "Expected 401 DIGEST response before content"
+	}
+	authorization, err := challenge.authorize(c.credentials, method, "/"+path)
+	if err != nil {
+		return nil, err, ""
+	}
+	rsp, err = c.send(method, httpURL, payload, headers, authorization)
+	return rsp, err, ""
+}
+
+func (c *DirectClient) send(method, httpURL, payload string, headers map[string]string, authorization string) (*http.Response, error) {
+	var body io.Reader
+	if payload != "" {
+		body = strings.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, httpURL, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rsp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	// Read the body, so that the connection is released and the response can be consumed like the curl responses
+	defer rsp.Body.Close()
+	b := new(bytes.Buffer)
+	if _, err = io.Copy(b, rsp.Body); err != nil {
+		return nil, err
+	}
+	rsp.Body = ioutil.NopCloser(b)
+	return rsp, nil
+}
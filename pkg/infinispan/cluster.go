@@ -5,13 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
 	consts "github.com/infinispan/infinispan-operator/controllers/constants"
 	ispnclient "github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
 	"github.com/infinispan/infinispan-operator/pkg/infinispan/client/http/curl"
+	"github.com/infinispan/infinispan-operator/pkg/infinispan/client/http/direct"
 	kube "github.com/infinispan/infinispan-operator/pkg/kubernetes"
 )
 
@@ -24,12 +29,27 @@ type Cluster struct {
 
 // ClusterHealth represents the health of the cluster
 type ClusterHealth struct {
-	Nodes []string `json:"node_names"`
+	Nodes  []string `json:"node_names"`
+	Status string   `json:"health_status"`
+}
+
+// ClusterHealthDegraded is the status of a cluster with caches that are not available, e.g. while the members that
+// were gracefully shutdown have not all rejoined the cluster
+const ClusterHealthDegraded = "DEGRADED"
+
+// ClusterHealthHealthy is the status of a cluster with all the caches available and no rebalance in progress
+const ClusterHealthHealthy = "HEALTHY"
+
+// CacheHealth is the health of a cache, as reported by the health of the server
+type CacheHealth struct {
+	Name   string `json:"cache_name"`
+	Status string `json:"status"`
 }
 
 // Health represents the health of an Infinispan server
 type Health struct {
 	ClusterHealth ClusterHealth `json:"cluster_health"`
+	CacheHealth   []CacheHealth `json:"cache_health,omitempty"`
 }
 
 type CacheManagerInfo struct {
@@ -37,6 +57,60 @@ type CacheManagerInfo struct {
 	SitesView   *[]interface{} `json:"sites_view,omitempty"`
 }
 
+// CacheStats represents the statistics of a cache
+type CacheStats struct {
+	DataMemoryUsed    int64 `json:"data_memory_used"`
+	OffHeapMemoryUsed int64 `json:"off_heap_memory_used"`
+}
+
+// CacheDetails represents the details of a cache
+type CacheDetails struct {
+	Stats              CacheStats `json:"stats"`
+	Size               int64      `json:"size"`
+	RehashInProgress   bool       `json:"rehash_in_progress"`
+	RebalancingEnabled *bool      `json:"rebalancing_enabled,omitempty"`
+}
+
+// CounterConfiguration represents the configuration of a counter, only one of Strong and Weak is set
+type CounterConfiguration struct {
+	Strong *CounterAttributes `json:"strong-counter,omitempty"`
+	Weak   *CounterAttributes `json:"weak-counter,omitempty"`
+}
+
+// CounterAttributes represents the attributes of a counter, the bounds only apply to strong counters
+type CounterAttributes struct {
+	InitialValue int64  `json:"initial-value"`
+	Storage      string `json:"storage"`
+	LowerBound   *int64 `json:"lower-bound,omitempty"`
+	UpperBound   *int64 `json:"upper-bound,omitempty"`
+}
+
+// Task represents a task registered on the server
+type Task struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	ExecutionMode string   `json:"execution_mode"`
+	Parameters    []string `json:"parameters,omitempty"`
+	AllowedRole   *string  `json:"allowed_role,omitempty"`
+}
+
+// SourceConnection describes the cluster that a cache is connected to by a rolling upgrade, the entries of the cache
+// of the same name on the source cluster being read through a remote store
+type SourceConnection struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// XSiteStatus represents the status of a backup site
+type XSiteStatus struct {
+	Status string `json:"status"`
+	// Online and Offline list the caches backing up to the site when its status is mixed
+	Online  []string `json:"online,omitempty"`
+	Offline []string `json:"offline,omitempty"`
+}
+
 type Logger struct {
 	Name  string `json:"name"`
 	Level string `json:"level"`
@@ -58,18 +132,49 @@ type ClusterInterface interface {
 	GetClusterSize(podName string) (int, error)
 	GracefulShutdown(podName string) error
 	GracefulShutdownTask(podName string) error
+	StopServer(memberName, podName string) error
 	GetClusterMembers(podName string) ([]string, error)
+	GetClusterHealth(podName string) (*ClusterHealth, error)
+	GetHealth(podName string) (*Health, error)
 	ExistsCache(cacheName, podName string) (bool, error)
 	CreateCacheWithTemplate(cacheName, cacheXML, podName string) error
 	CreateCacheWithTemplateName(cacheName, templateName, podName string) error
+	CreateCacheWithConfiguration(cacheName, configuration, podName string) error
+	UpdateCacheConfiguration(cacheName, configuration, podName string) error
+	CompareCacheConfigurations(configuration, other string, ignoreMutable bool, podName string) (bool, error)
+	ConvertCacheConfiguration(configuration, mediaType, podName string) (string, error)
+	DeleteCache(cacheName, podName string) error
+	GetCacheConfiguration(cacheName, podName string) (string, error)
+	UpdateUserPassword(usersFile, username, password, podName string) error
 	GetMemoryLimitBytes(podName string) (uint64, error)
 	GetMaxMemoryUnboundedBytes(podName string) (uint64, error)
 	CacheNames(podName string) ([]string, error)
 	GetMetrics(podName, postfix string) (*bytes.Buffer, error)
+	GetThreadDump(podName string) (*bytes.Buffer, error)
+	GetServerConfig(podName string) (string, error)
+	GetCacheManagerStats(cacheManagerName, podName string) (*bytes.Buffer, error)
+	GetCacheStats(cacheName, podName string) (*bytes.Buffer, error)
 	GetCacheManagerInfo(cacheManagerName, podName string) (*CacheManagerInfo, error)
 	GetLoggers(podName string) (map[string]string, error)
 	SetLogger(podName, loggerName, loggerLevel string) error
 	XsitePushAllState(podName string) error
+	RegisterProtobufSchema(schemaName, schema, podName string) error
+	GetCacheSize(cacheName, podName string) (int, error)
+	GetCacheDetails(cacheName, podName string) (*CacheDetails, error)
+	GetXSiteStatus(podName string) (map[string]XSiteStatus, error)
+	GetXSitePushStateStatus(cacheName, podName string) (map[string]string, error)
+	XSiteBringOnline(siteName, podName string) error
+	XSitePushState(siteName, podName string) error
+	GetCounterConfiguration(counterName, podName string) (*CounterConfiguration, error)
+	CreateCounter(counterName string, configuration CounterConfiguration, podName string) error
+	GetCounterValue(counterName, podName string) (int64, error)
+	ReloadKeystores(podName string) error
+	GetTasks(podName string) ([]Task, error)
+	UploadScript(taskName, script, podName string) error
+	GetProtobufSchemas(podName string) (map[string]string, error)
+	ConnectSource(cacheName string, source SourceConnection, podName string) error
+	SyncData(cacheName, podName string) (int, error)
+	DisconnectSource(cacheName, podName string) error
 }
 
 // NewClusterNoAuth creates a new instance of Cluster without authentication
@@ -86,6 +191,26 @@ func NewCluster(username, password, namespace string, protocol string, kubernete
 	return cluster(namespace, protocol, credentials, kubernetes)
 }
 
+// NewDirectCluster creates a new instance of Cluster sending the REST calls from the operator to the pods, which are
+// resolved with the headless service `serviceName`
+func NewDirectCluster(username, password, namespace, protocol, serviceName string, kubernetes *kube.Kubernetes) *Cluster {
+	client := direct.New(ispnclient.HttpConfig{
+		Credentials: &ispnclient.Credentials{
+			Username: username,
+			Password: password,
+		},
+		Namespace:   namespace,
+		Protocol:    protocol,
+		ServiceName: serviceName,
+	}, kubernetes)
+
+	return &Cluster{
+		Kubernetes: kubernetes,
+		Client:     client,
+		Namespace:  namespace,
+	}
+}
+
 func cluster(namespace, protocol string, credentials *ispnclient.Credentials, kubernetes *kube.Kubernetes) *Cluster {
 	client := curl.New(ispnclient.HttpConfig{
 		Credentials: credentials,
@@ -116,6 +241,14 @@ func (c Cluster) GracefulShutdown(podName string) error {
 	return validateResponse(rsp, reason, err, "during graceful shutdown", http.StatusNoContent)
 }
 
+// StopServer stops a single member of the cluster, which leaves the cluster view so that the entries it owns are
+// redistributed across the remaining members
+func (c Cluster) StopServer(memberName, podName string) error {
+	path := fmt.Sprintf("%s&server=%s", consts.ServerHTTPClusterStop, url.QueryEscape(memberName))
+	rsp, err, reason := c.Client.Post(podName, path, "", nil)
+	return validateResponse(rsp, reason, err, "stopping server "+memberName, http.StatusNoContent)
+}
+
 // ISPN-13141 Upload custom task to perform graceful shutdown that does not fail on cache errors
 // This task calls Cache#shutdown which disables rebalancing on the cache before stopping it
 func (c Cluster) GracefulShutdownTask(podName string) error {
@@ -166,9 +299,27 @@ func (c Cluster) GracefulShutdownTask(podName string) error {
 }
 
 // GetClusterMembers get the cluster members as seen by a given pod
-func (c Cluster) GetClusterMembers(podName string) (members []string, err error) {
+func (c Cluster) GetClusterMembers(podName string) ([]string, error) {
+	health, err := c.GetClusterHealth(podName)
+	if err != nil {
+		return nil, err
+	}
+	return health.Nodes, nil
+}
+
+// GetClusterHealth get the health of the cluster as seen by a given pod
+func (c Cluster) GetClusterHealth(podName string) (*ClusterHealth, error) {
+	health, err := c.GetHealth(podName)
+	if err != nil {
+		return nil, err
+	}
+	return &health.ClusterHealth, nil
+}
+
+// GetHealth get the health of the cluster and of its caches as seen by a given pod
+func (c Cluster) GetHealth(podName string) (health *Health, err error) {
 	rsp, err, reason := c.Client.Get(podName, consts.ServerHTTPHealthPath, nil)
-	if err = validateResponse(rsp, reason, err, "getting cluster members", http.StatusOK); err != nil {
+	if err = validateResponse(rsp, reason, err, "getting cluster health", http.StatusOK); err != nil {
 		return
 	}
 
@@ -179,11 +330,11 @@ func (c Cluster) GetClusterMembers(podName string) (members []string, err error)
 		}
 	}()
 
-	var health Health
-	if err := json.NewDecoder(rsp.Body).Decode(&health); err != nil {
+	health = &Health{}
+	if err := json.NewDecoder(rsp.Body).Decode(health); err != nil {
 		return nil, fmt.Errorf("unable to decode: %w", err)
 	}
-	return health.ClusterHealth.Nodes, nil
+	return health, nil
 }
 
 // ExistsCache returns true if cacheName cache exists on the podName pod
@@ -203,6 +354,48 @@ func (c Cluster) ExistsCache(cacheName, podName string) (bool, error) {
 	return false, nil
 }
 
+// GetCacheSize returns the number of entries of the cacheName cache as seen by the pod `podName`
+func (c Cluster) GetCacheSize(cacheName, podName string) (size int, err error) {
+	path := fmt.Sprintf("%s/caches/%s?action=size", consts.ServerHTTPBasePath, cacheName)
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, "getting cache size", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if err := json.NewDecoder(rsp.Body).Decode(&size); err != nil {
+		return 0, fmt.Errorf("unable to decode: %w", err)
+	}
+	return
+}
+
+// GetCacheDetails returns the details of the cacheName cache, including its statistics, as seen by the pod `podName`
+func (c Cluster) GetCacheDetails(cacheName, podName string) (details *CacheDetails, err error) {
+	path := fmt.Sprintf("%s/caches/%s", consts.ServerHTTPBasePath, cacheName)
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, "getting cache details", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if err = json.NewDecoder(rsp.Body).Decode(&details); err != nil {
+		return nil, fmt.Errorf("unable to decode: %w", err)
+	}
+	return
+}
+
 // CacheNames return the names of the cluster caches available on the pod `podName`
 func (c Cluster) CacheNames(podName string) (caches []string, err error) {
 	path := fmt.Sprintf("%s/caches", consts.ServerHTTPBasePath)
@@ -241,6 +434,382 @@ func (c Cluster) CreateCacheWithTemplateName(cacheName, templateName, podName st
 	return validateResponse(rsp, reason, err, "creating cache with template", http.StatusOK)
 }
 
+// CreateCacheWithConfiguration create cluster cache on the pod `podName` from an XML, JSON or YAML configuration
+func (c Cluster) CreateCacheWithConfiguration(cacheName, configuration, podName string) error {
+	headers := map[string]string{"Content-Type": CacheConfigurationContentType(configuration)}
+	path := fmt.Sprintf("%s/caches/%s", consts.ServerHTTPBasePath, url.PathEscape(cacheName))
+	rsp, err, reason := c.Client.Post(podName, path, configuration, headers)
+	return validateResponse(rsp, reason, err, "creating cache", http.StatusOK)
+}
+
+// UpdateCacheConfiguration updates the configuration of an existing cache on the pod `podName`. The server rejects
+// the update if an attribute that cannot be changed at runtime differs
+func (c Cluster) UpdateCacheConfiguration(cacheName, configuration, podName string) error {
+	headers := map[string]string{"Content-Type": CacheConfigurationContentType(configuration)}
+	path := fmt.Sprintf("%s/caches/%s", consts.ServerHTTPBasePath, url.PathEscape(cacheName))
+	rsp, err, reason := c.Client.Put(podName, path, configuration, headers)
+	return validateResponse(rsp, reason, err, "updating cache", http.StatusOK, http.StatusNoContent)
+}
+
+// CompareCacheConfigurations returns true if both configurations, in XML, JSON or YAML format, are equal. Attributes
+// that can be changed at runtime are ignored when `ignoreMutable` is true
+func (c Cluster) CompareCacheConfigurations(configuration, other string, ignoreMutable bool, podName string) (bool, error) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for i, config := range []string{configuration, other} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="configuration%d"`, i))
+		header.Set("Content-Type", CacheConfigurationContentType(config))
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return false, err
+		}
+		if _, err = part.Write([]byte(config)); err != nil {
+			return false, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return false, err
+	}
+
+	headers := map[string]string{"Content-Type": writer.FormDataContentType()}
+	path := fmt.Sprintf("%s/caches?action=compare&ignoreMutable=%t", consts.ServerHTTPBasePath, ignoreMutable)
+	rsp, err, reason := c.Client.Post(podName, path, body.String(), headers)
+	if err = validateResponse(rsp, reason, err, "comparing cache configurations", http.StatusNoContent, http.StatusConflict); err != nil {
+		return false, err
+	}
+	return rsp.StatusCode == http.StatusNoContent, nil
+}
+
+// ConvertCacheConfiguration converts the configuration to its canonical JSON form on the pod `podName`. The format of
+// the configuration is detected from its content when `mediaType` is empty
+func (c Cluster) ConvertCacheConfiguration(configuration, mediaType, podName string) (config string, err error) {
+	if mediaType == "" {
+		mediaType = CacheConfigurationContentType(configuration)
+	}
+	headers := map[string]string{"Content-Type": mediaType, "Accept": "application/json"}
+	path := fmt.Sprintf("%s/caches?action=convert", consts.ServerHTTPBasePath)
+	rsp, err, reason := c.Client.Post(podName, path, configuration, headers)
+	if err = validateResponse(rsp, reason, err, "converting cache configuration", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read converted cache configuration: %w", err)
+	}
+	return string(body), nil
+}
+
+// DeleteCache removes the cache from the cluster on the pod `podName`
+func (c Cluster) DeleteCache(cacheName, podName string) error {
+	path := fmt.Sprintf("%s/caches/%s", consts.ServerHTTPBasePath, url.PathEscape(cacheName))
+	rsp, err, reason := c.Client.Delete(podName, path, nil)
+	return validateResponse(rsp, reason, err, "deleting cache", http.StatusOK, http.StatusNoContent, http.StatusNotFound)
+}
+
+// GetCacheConfiguration returns the JSON configuration of the cache as seen by the pod `podName`
+func (c Cluster) GetCacheConfiguration(cacheName, podName string) (config string, err error) {
+	path := fmt.Sprintf("%s/caches/%s?action=config", consts.ServerHTTPBasePath, url.PathEscape(cacheName))
+	rsp, err, reason := c.Client.Get(podName, path, map[string]string{"Accept": "application/json"})
+	if err = validateResponse(rsp, reason, err, "getting cache configuration", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read cache configuration: %w", err)
+	}
+	return string(body), nil
+}
+
+// CacheConfigurationContentType returns the media type of a cache configuration based on its first character
+func CacheConfigurationContentType(configuration string) string {
+	switch trimmed := strings.TrimSpace(configuration); {
+	case strings.HasPrefix(trimmed, "<"):
+		return "application/xml"
+	case strings.HasPrefix(trimmed, "{"):
+		return "application/json"
+	default:
+		return "application/yaml"
+	}
+}
+
+// protobufSchemaName matches the schema names that are safe to use in the request path
+var protobufSchemaName = regexp.MustCompile(`^[A-Za-z0-9_./-]+\.proto$`)
+
+// RegisterProtobufSchema creates or updates the schema in the ___protobuf_metadata cache on the pod `podName`
+func (c Cluster) RegisterProtobufSchema(schemaName, schema, podName string) error {
+	if !protobufSchemaName.MatchString(schemaName) {
+		return fmt.Errorf("invalid protobuf schema name '%s'", schemaName)
+	}
+	headers := make(map[string]string)
+	headers["Content-Type"] = "text/plain"
+
+	path := fmt.Sprintf("%s/%s", consts.ServerHTTPProtobufPath, url.PathEscape(schemaName))
+	rsp, err, reason := c.Client.Put(podName, path, schema, headers)
+	return validateResponse(rsp, reason, err, "registering protobuf schema", http.StatusOK, http.StatusNoContent)
+}
+
+// GetProtobufSchemas returns the content of the schemas registered in the ___protobuf_metadata cache on the pod
+// `podName`, by schema name
+func (c Cluster) GetProtobufSchemas(podName string) (schemas map[string]string, err error) {
+	path := fmt.Sprintf("%s?action=keys", consts.ServerHTTPProtobufPath)
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, "getting protobuf schemas", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	var names []string
+	if err = json.NewDecoder(rsp.Body).Decode(&names); err != nil {
+		return nil, fmt.Errorf("unable to decode: %w", err)
+	}
+	schemas = make(map[string]string, len(names))
+	for _, name := range names {
+		// The errors of each schema are held by the cache along with the schemas
+		if !protobufSchemaName.MatchString(name) {
+			continue
+		}
+		if schemas[name], err = c.getProtobufSchema(name, podName); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+func (c Cluster) getProtobufSchema(schemaName, podName string) (schema string, err error) {
+	path := fmt.Sprintf("%s/%s", consts.ServerHTTPProtobufPath, url.PathEscape(schemaName))
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, "getting protobuf schema", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read protobuf schema: %w", err)
+	}
+	return string(body), nil
+}
+
+// ConnectSource adds a remote store to the cacheName cache on the pod `podName`, reading through the entries of the
+// cache of the same name on the source cluster
+func (c Cluster) ConnectSource(cacheName string, source SourceConnection, podName string) error {
+	type digest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Realm    string `json:"realm"`
+	}
+	type authentication struct {
+		ServerName string  `json:"server-name"`
+		Digest     *digest `json:"digest"`
+	}
+	type security struct {
+		Authentication authentication `json:"authentication"`
+	}
+	type remoteServer struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type remoteStore struct {
+		Cache        string         `json:"cache"`
+		Shared       bool           `json:"shared"`
+		RawValues    bool           `json:"raw-values"`
+		Segmented    bool           `json:"segmented"`
+		RemoteServer []remoteServer `json:"remote-server"`
+		Security     *security      `json:"security,omitempty"`
+	}
+	store := remoteStore{
+		Cache:        cacheName,
+		Shared:       true,
+		RawValues:    true,
+		RemoteServer: []remoteServer{{Host: source.Host, Port: source.Port}},
+	}
+	if source.Username != "" {
+		store.Security = &security{
+			Authentication: authentication{
+				ServerName: "infinispan",
+				Digest:     &digest{Username: source.Username, Password: source.Password, Realm: "default"},
+			},
+		}
+	}
+	payload, err := json.Marshal(map[string]remoteStore{"remote-store": store})
+	if err != nil {
+		return fmt.Errorf("unable to encode source connection: %w", err)
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	path := fmt.Sprintf("%s/caches/%s/rolling-upgrade/source-connection", consts.ServerHTTPBasePath, url.PathEscape(cacheName))
+	rsp, err, reason := c.Client.Post(podName, path, string(payload), headers)
+	return validateResponse(rsp, reason, err, "connecting source cluster", http.StatusOK, http.StatusNoContent)
+}
+
+// SyncData copies the entries of the source cluster that the cacheName cache is connected to on the pod `podName`,
+// returning the number of entries read from the source cluster
+func (c Cluster) SyncData(cacheName, podName string) (migrated int, err error) {
+	path := fmt.Sprintf("%s/caches/%s?action=sync-data", consts.ServerHTTPBasePath, url.PathEscape(cacheName))
+	rsp, err, reason := c.Client.Post(podName, path, "", nil)
+	if err = validateResponse(rsp, reason, err, "synchronizing data", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read the synchronized data: %w", err)
+	}
+	// The server responds with the number of migrated entries, as in "5 entries migrated"
+	if _, err = fmt.Sscanf(string(body), "%d", &migrated); err != nil {
+		return 0, fmt.Errorf("unexpected synchronized data response '%s': %w", string(body), err)
+	}
+	return
+}
+
+// DisconnectSource removes the remote store connecting the cacheName cache to the source cluster on the pod `podName`,
+// if any
+func (c Cluster) DisconnectSource(cacheName, podName string) error {
+	path := fmt.Sprintf("%s/caches/%s/rolling-upgrade/source-connection", consts.ServerHTTPBasePath, url.PathEscape(cacheName))
+	rsp, err, reason := c.Client.Delete(podName, path, nil)
+	return validateResponse(rsp, reason, err, "disconnecting source cluster", http.StatusOK, http.StatusNoContent, http.StatusNotModified, http.StatusNotFound)
+}
+
+// GetCounterConfiguration returns the configuration of the counterName counter, or nil if it doesn't exist on the pod `podName`
+func (c Cluster) GetCounterConfiguration(counterName, podName string) (configuration *CounterConfiguration, err error) {
+	path := fmt.Sprintf("%s/counters/%s/config", consts.ServerHTTPBasePath, url.PathEscape(counterName))
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, "getting counter configuration", http.StatusOK, http.StatusNotFound); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err = json.NewDecoder(rsp.Body).Decode(&configuration); err != nil {
+		return nil, fmt.Errorf("unable to decode: %w", err)
+	}
+	return
+}
+
+// CreateCounter creates the counterName counter on the pod `podName`
+func (c Cluster) CreateCounter(counterName string, configuration CounterConfiguration, podName string) error {
+	payload, err := json.Marshal(configuration)
+	if err != nil {
+		return fmt.Errorf("unable to encode counter configuration: %w", err)
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	path := fmt.Sprintf("%s/counters/%s", consts.ServerHTTPBasePath, url.PathEscape(counterName))
+	rsp, err, reason := c.Client.Post(podName, path, string(payload), headers)
+	return validateResponse(rsp, reason, err, "creating counter", http.StatusOK)
+}
+
+// GetCounterValue returns the current value of the counterName counter as seen by the pod `podName`
+func (c Cluster) GetCounterValue(counterName, podName string) (value int64, err error) {
+	path := fmt.Sprintf("%s/counters/%s", consts.ServerHTTPBasePath, url.PathEscape(counterName))
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, "getting counter value", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if err = json.NewDecoder(rsp.Body).Decode(&value); err != nil {
+		return 0, fmt.Errorf("unable to decode: %w", err)
+	}
+	return
+}
+
+// GetTasks returns the user tasks registered on the pod `podName`
+func (c Cluster) GetTasks(podName string) (tasks []Task, err error) {
+	path := fmt.Sprintf("%s/tasks?type=user", consts.ServerHTTPBasePath)
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, "getting tasks", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if err = json.NewDecoder(rsp.Body).Decode(&tasks); err != nil {
+		return nil, fmt.Errorf("unable to decode: %w", err)
+	}
+	return
+}
+
+// UploadScript creates or replaces the taskName script task with the JavaScript `script` on the pod `podName`
+func (c Cluster) UploadScript(taskName, script, podName string) error {
+	headers := map[string]string{"Content-Type": "application/javascript"}
+	path := fmt.Sprintf("%s/tasks/%s", consts.ServerHTTPBasePath, url.PathEscape(taskName))
+	rsp, err, reason := c.Client.Put(podName, path, script, headers)
+	return validateResponse(rsp, reason, err, "uploading script", http.StatusOK, http.StatusNoContent)
+}
+
+// ReloadKeystores reloads the keystores and truststores of the endpoints of the server on the pod `podName` from their
+// files, so that renewed certificates are used by new connections without restarting the server
+func (c Cluster) ReloadKeystores(podName string) error {
+	path := fmt.Sprintf("%s/security/keystores?action=reload", consts.ServerHTTPBasePath)
+	rsp, err, reason := c.Client.Post(podName, path, "", nil)
+	return validateResponse(rsp, reason, err, "reloading keystores", http.StatusOK, http.StatusNoContent)
+}
+
+// UpdateUserPassword replaces the password of a user in the properties realm file `usersFile` of the server on the pod
+// `podName`. The realm reloads the file when it changes, so the password is updated without restarting the server
+func (c Cluster) UpdateUserPassword(usersFile, username, password, podName string) error {
+	command := []string{consts.ServerCliPath, "user", "password", "--users-file", usersFile, "--password", password, username}
+	execOptions := kube.ExecOptions{Command: command, PodName: podName, Namespace: c.Namespace}
+	if _, execErr, err := c.Kubernetes.ExecWithOptions(execOptions); err != nil {
+		return fmt.Errorf("unexpected error updating the password of user '%s', stderr: %v, err: %w", username, execErr, err)
+	}
+	return nil
+}
+
 func (c Cluster) GetMemoryLimitBytes(podName string) (uint64, error) {
 	command := []string{"cat", "/sys/fs/cgroup/memory/memory.limit_in_bytes"}
 	execOptions := kube.ExecOptions{Command: command, PodName: podName, Namespace: c.Namespace}
@@ -309,6 +878,65 @@ func (c Cluster) GetMetrics(podName, postfix string) (buf *bytes.Buffer, err err
 	return
 }
 
+// GetThreadDump returns the thread dump of the server running on the pod `podName`
+func (c Cluster) GetThreadDump(podName string) (*bytes.Buffer, error) {
+	return c.getRaw(podName, consts.ServerHTTPThreadsPath, "getting thread dump")
+}
+
+// GetServerConfig returns the live configuration of the server running on the pod `podName` in XML format
+func (c Cluster) GetServerConfig(podName string) (config string, err error) {
+	headers := map[string]string{"Accept": "application/xml"}
+	rsp, err, reason := c.Client.Get(podName, consts.ServerHTTPConfigPath, headers)
+	if err = validateResponse(rsp, reason, err, "getting server configuration", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	buf := new(bytes.Buffer)
+	if _, err = buf.ReadFrom(rsp.Body); err != nil {
+		return
+	}
+	return buf.String(), nil
+}
+
+// GetCacheManagerStats returns the statistics of the cache manager, as returned by the server in JSON format
+func (c Cluster) GetCacheManagerStats(cacheManagerName, podName string) (*bytes.Buffer, error) {
+	path := fmt.Sprintf("%s/cache-managers/%s/stats", consts.ServerHTTPBasePath, cacheManagerName)
+	return c.getRaw(podName, path, "getting cache manager stats")
+}
+
+// GetCacheStats returns the statistics of the cache, as returned by the server in JSON format
+func (c Cluster) GetCacheStats(cacheName, podName string) (*bytes.Buffer, error) {
+	path := fmt.Sprintf("%s/caches/%s?action=stats", consts.ServerHTTPBasePath, cacheName)
+	return c.getRaw(podName, path, "getting cache stats")
+}
+
+func (c Cluster) getRaw(podName, path, entity string) (buf *bytes.Buffer, err error) {
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, entity, http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	buf = new(bytes.Buffer)
+	if _, err = buf.ReadFrom(rsp.Body); err != nil {
+		return
+	}
+	return
+}
+
 // GetCacheManagerInfo via REST v2 interface
 func (c Cluster) GetCacheManagerInfo(cacheManagerName, podName string) (info *CacheManagerInfo, err error) {
 	path := fmt.Sprintf("%s/cache-managers/%s", consts.ServerHTTPBasePath, cacheManagerName)
@@ -365,6 +993,47 @@ func (c Cluster) SetLogger(podName, loggerName, loggerLevel string) error {
 	return nil
 }
 
+// GetXSiteStatus returns the status of each backup site of the cache manager
+func (c Cluster) GetXSiteStatus(podName string) (statuses map[string]XSiteStatus, err error) {
+	rsp, err, reason := c.Client.Get(podName, consts.ServerHTTPXSitePath, nil)
+	if err = validateResponse(rsp, reason, err, "Retrieving xsite status", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if err = json.NewDecoder(rsp.Body).Decode(&statuses); err != nil {
+		return nil, fmt.Errorf("unable to decode: %w", err)
+	}
+	return
+}
+
+// GetXSitePushStateStatus returns the state transfer status of the cache for each site it is pushing its state to
+func (c Cluster) GetXSitePushStateStatus(cacheName, podName string) (statuses map[string]string, err error) {
+	path := fmt.Sprintf(consts.ServerHTTPXSitePushPath, cacheName)
+	rsp, err, reason := c.Client.Get(podName, path, nil)
+	if err = validateResponse(rsp, reason, err, "Retrieving xsite push state status", http.StatusOK); err != nil {
+		return
+	}
+
+	defer func() {
+		cerr := rsp.Body.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	if err = json.NewDecoder(rsp.Body).Decode(&statuses); err != nil {
+		return nil, fmt.Errorf("unable to decode: %w", err)
+	}
+	return
+}
+
 func (c Cluster) XsitePushAllState(podName string) (err error) {
 	rsp, err, reason := c.Client.Get(podName, consts.ServerHTTPXSitePath, nil)
 	if err = validateResponse(rsp, reason, err, "Retrieving xsite status", http.StatusOK); err != nil {
@@ -389,9 +1058,7 @@ func (c Cluster) XsitePushAllState(podName string) (err error) {
 	// Statuses will be empty if no xsite caches are configured
 	for k, v := range statuses {
 		if v.Status == "online" {
-			url := fmt.Sprintf("%s/%s?action=start-push-state", consts.ServerHTTPXSitePath, k)
-			rsp, err, reason = c.Client.Post(podName, url, "", nil)
-			if err = validateResponse(rsp, reason, err, "Pushing xsite state", http.StatusOK); err != nil {
+			if err = c.XSitePushState(k, podName); err != nil {
 				return
 			}
 		}
@@ -399,6 +1066,20 @@ func (c Cluster) XsitePushAllState(podName string) (err error) {
 	return
 }
 
+// XSiteBringOnline brings the remote site online for all the caches backing up to it
+func (c Cluster) XSiteBringOnline(siteName, podName string) error {
+	path := fmt.Sprintf("%s/%s?action=bring-online", consts.ServerHTTPXSitePath, siteName)
+	rsp, err, reason := c.Client.Post(podName, path, "", nil)
+	return validateResponse(rsp, reason, err, "Bringing xsite online", http.StatusOK, http.StatusNoContent)
+}
+
+// XSitePushState starts the state transfer of all the caches backing up to the remote site
+func (c Cluster) XSitePushState(siteName, podName string) error {
+	path := fmt.Sprintf("%s/%s?action=start-push-state", consts.ServerHTTPXSitePath, siteName)
+	rsp, err, reason := c.Client.Post(podName, path, "", nil)
+	return validateResponse(rsp, reason, err, "Pushing xsite state", http.StatusOK, http.StatusNoContent)
+}
+
 func validateResponse(rsp *http.Response, reason string, inperr error, entity string, validCodes ...int) (err error) {
 	if inperr != nil {
 		return fmt.Errorf("unexpected error %s, stderr: %s, err: %w", entity, reason, inperr)
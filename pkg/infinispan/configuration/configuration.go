@@ -27,6 +27,17 @@ type Infinispan struct {
 	ClusterName      string        `yaml:"clusterName"`
 	ZeroCapacityNode bool          `yaml:"zeroCapacityNode"`
 	Locks            Locks         `yaml:"locks"`
+	// Tracing exports the server spans to an OpenTelemetry collector
+	Tracing *Tracing `yaml:"tracing,omitempty"`
+}
+
+// Tracing configures the OpenTelemetry tracing of the server
+type Tracing struct {
+	CollectorEndpoint string  `yaml:"collectorEndpoint"`
+	ExporterProtocol  string  `yaml:"exporterProtocol"`
+	ServiceName       string  `yaml:"serviceName"`
+	SamplingRatio     float64 `yaml:"samplingRatio"`
+	CaPath            string  `yaml:"caPath,omitempty"`
 }
 
 type Authorization struct {
@@ -44,6 +55,45 @@ type Endpoints struct {
 	Authenticate   bool   `yaml:"auth"`
 	DedicatedAdmin bool   `yaml:"dedicatedAdmin"`
 	ClientCert     string `yaml:"clientCert,omitempty"`
+	Memcached      bool   `yaml:"memcached,omitempty"`
+	Resp           bool   `yaml:"resp,omitempty"`
+	// Metrics overrides the configuration the metrics endpoint inherits from the admin endpoint
+	Metrics *Metrics `yaml:"metrics,omitempty"`
+	// LdapRealm authenticates the users of the endpoints against an LDAP server instead of the identities file
+	LdapRealm *LdapRealm `yaml:"ldapRealm,omitempty"`
+	// TokenRealm authenticates the bearer tokens of the clients with the introspection endpoint of an identity provider
+	TokenRealm *TokenRealm `yaml:"tokenRealm,omitempty"`
+}
+
+// TokenRealm configures the OAuth2 token realm of the endpoints
+type TokenRealm struct {
+	AuthServerURL    string `yaml:"authServerUrl"`
+	ClientID         string `yaml:"clientId"`
+	ClientSecretPath string `yaml:"clientSecretPath"`
+	IntrospectionURL string `yaml:"introspectionUrl"`
+	PrincipalClaim   string `yaml:"principalClaim"`
+	Audience         string `yaml:"audience,omitempty"`
+	CaPath           string `yaml:"caPath,omitempty"`
+}
+
+// LdapRealm configures the LDAP security realm of the endpoints
+type LdapRealm struct {
+	URL             string `yaml:"url"`
+	Principal       string `yaml:"principal"`
+	CredentialPath  string `yaml:"credentialPath"`
+	SearchDn        string `yaml:"searchDn"`
+	UserFilter      string `yaml:"userFilter"`
+	SearchRecursive bool   `yaml:"searchRecursive"`
+	GroupSearchDn   string `yaml:"groupSearchDn,omitempty"`
+	GroupFilter     string `yaml:"groupFilter,omitempty"`
+	GroupAttribute  string `yaml:"groupAttribute,omitempty"`
+	CaPath          string `yaml:"caPath,omitempty"`
+}
+
+// Metrics configures the access to the metrics endpoint
+type Metrics struct {
+	Authenticate bool   `yaml:"auth"`
+	CrtPath      string `yaml:"crtPath,omitempty"`
 }
 
 type Locks struct {
@@ -57,6 +107,7 @@ type Keystore struct {
 	Password string
 	Alias    string
 	CrtPath  string `yaml:"crtPath,omitempty"`
+	Type     string `yaml:"type,omitempty"`
 }
 
 // Truststore configuration info for endpoint encryption
@@ -70,22 +121,51 @@ type Truststore struct {
 // JGroups configures clustering layer
 type JGroups struct {
 	Transport   string
-	DNSPing     DNSPing `yaml:"dnsPing"`
-	Diagnostics bool    `yaml:"diagnostics"`
+	DNSPing     DNSPing            `yaml:"dnsPing"`
+	Diagnostics bool               `yaml:"diagnostics"`
+	Encryption  *JGroupsEncryption `yaml:"encryption,omitempty"`
+	// GossipRouters the host[port] addresses of the Gossip routers used by the tunnel transport
+	GossipRouters []string `yaml:"gossipRouters,omitempty"`
+	// Properties overrides the properties of the stack protocols, keyed by <PROTOCOL>.<property>
+	Properties map[string]string `yaml:"properties,omitempty"`
+}
+
+// JGroupsEncryption configures the encryption of the cluster traffic
+type JGroupsEncryption struct {
+	Protocol string   `yaml:"protocol"`
+	Keystore Keystore `yaml:"keystore"`
 }
 
 // DNSPing configures DNS cluster lookup settings
 type DNSPing struct {
-	Query string
+	Query      string
+	RecordType string `yaml:"recordType,omitempty"`
 }
 
 type XSite struct {
-	Address        string       `yaml:"address"`
-	Name           string       `yaml:"name"`
-	Port           int32        `yaml:"port"`
-	Transport      string       `yaml:"transport"`
-	MaxSiteMasters int32        `yaml:"maxSiteMasters"`
-	Backups        []BackupSite `yaml:"backups"`
+	Address        string              `yaml:"address"`
+	Name           string              `yaml:"name"`
+	Port           int32               `yaml:"port"`
+	Transport      string              `yaml:"transport"`
+	MaxSiteMasters int32               `yaml:"maxSiteMasters"`
+	Backups        []BackupSite        `yaml:"backups"`
+	StateTransfer  *XSiteStateTransfer `yaml:"stateTransfer,omitempty"`
+	Encryption     *XSiteEncryption    `yaml:"encryption,omitempty"`
+}
+
+// XSiteEncryption secures the connections of the relay nodes to the Gossip Routers with TLS, the keystore also being
+// used as truststore
+type XSiteEncryption struct {
+	Protocol string
+	Keystore Keystore
+}
+
+// XSiteStateTransfer tunes the state transfer of the caches backing up to the remote sites
+type XSiteStateTransfer struct {
+	ChunkSize  int32  `yaml:"chunkSize,omitempty"`
+	Timeout    int64  `yaml:"timeout,omitempty"`
+	MaxRetries *int32 `yaml:"maxRetries,omitempty"`
+	Mode       string `yaml:"mode,omitempty"`
 }
 
 type BackupSite struct {
@@ -95,9 +175,15 @@ type BackupSite struct {
 }
 
 type Logging struct {
+	Console    *LoggingConsole   `yaml:"console,omitempty"`
 	Categories map[string]string `yaml:"categories,omitempty"`
 }
 
+type LoggingConsole struct {
+	Pattern string `yaml:"pattern,omitempty"`
+	JSON    bool   `yaml:"json,omitempty"`
+}
+
 func (c *InfinispanConfiguration) Yaml() (string, error) {
 	y, err := yaml.Marshal(c)
 	if err != nil {
@@ -0,0 +1,53 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Merge merges the YAML `overlay` fragment into the `generated` server configuration element by element. Elements
+// missing from the generated configuration are added, whereas the generated values take precedence over the overlay
+// values that differ from them. The paths of the conflicting overlay values are returned sorted
+func Merge(generated, overlay string) (string, []string, error) {
+	base := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(generated), &base); err != nil {
+		return "", nil, err
+	}
+	fragment := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(overlay), &fragment); err != nil {
+		return "", nil, fmt.Errorf("unable to parse the configuration overlay: %w", err)
+	}
+
+	conflicts := mergeElements(base, fragment, "")
+	sort.Strings(conflicts)
+	merged, err := yaml.Marshal(base)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(merged), conflicts, nil
+}
+
+func mergeElements(base, overlay map[interface{}]interface{}, path string) (conflicts []string) {
+	for key, value := range overlay {
+		elementPath := fmt.Sprint(key)
+		if path != "" {
+			elementPath = path + "." + elementPath
+		}
+		current, exists := base[key]
+		if !exists || current == nil {
+			base[key] = value
+			continue
+		}
+		currentElements, currentIsMap := current.(map[interface{}]interface{})
+		elements, isMap := value.(map[interface{}]interface{})
+		if currentIsMap && isMap {
+			conflicts = append(conflicts, mergeElements(currentElements, elements, elementPath)...)
+		} else if !reflect.DeepEqual(current, value) {
+			conflicts = append(conflicts, elementPath)
+		}
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	generated := &InfinispanConfiguration{
+		Infinispan: Infinispan{ClusterName: "example-infinispan"},
+		JGroups:    JGroups{Transport: "tcp", DNSPing: DNSPing{Query: "example-infinispan-ping"}},
+		Endpoints:  Endpoints{Authenticate: true, DedicatedAdmin: true},
+	}
+	generatedYaml, err := generated.Yaml()
+	assert.NoError(t, err)
+
+	overlay := `
+infinispan:
+  clusterName: example-infinispan
+  locks:
+    owners: 2
+jgroups:
+  transport: udp
+endpoints:
+  auth: false
+logging:
+  categories:
+    org.infinispan: debug
+`
+	merged, conflicts, err := Merge(generatedYaml, overlay)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"endpoints.auth", "jgroups.transport"}, conflicts)
+
+	config, err := FromYaml(merged)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), config.Infinispan.Locks.Owners)
+	assert.Equal(t, map[string]string{"org.infinispan": "debug"}, config.Logging.Categories)
+	// The generated values take precedence
+	assert.Equal(t, "tcp", config.JGroups.Transport)
+	assert.True(t, config.Endpoints.Authenticate)
+	assert.Equal(t, "example-infinispan-ping", config.JGroups.DNSPing.Query)
+
+	merged, conflicts, err = Merge(generatedYaml, "")
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+	config, err = FromYaml(merged)
+	assert.NoError(t, err)
+	assert.Equal(t, generated, config)
+
+	_, _, err = Merge(generatedYaml, "- not a configuration")
+	assert.Error(t, err)
+}
@@ -0,0 +1,197 @@
+package load
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+	client "github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
+)
+
+const (
+	FormatCSV  = "CSV"
+	FormatJSON = "JSON"
+)
+
+// Entry is a cache entry read from the data file
+type Entry struct {
+	Key   string
+	Value string
+}
+
+// Reader returns the entries of a data file, io.EOF once all the entries have been read
+type Reader interface {
+	Next() (*Entry, error)
+}
+
+// NewReader returns the Reader of the entries of a data file in the CSV or JSON format
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.Comment = '#'
+		reader.FieldsPerRecord = -1
+		return &csvReader{reader: reader}, nil
+	case FormatJSON:
+		return &jsonReader{decoder: json.NewDecoder(r)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format '%s', expected %s or %s", format, FormatCSV, FormatJSON)
+	}
+}
+
+// csvReader reads the key from the first column and the value from the second column of each record
+type csvReader struct {
+	reader  *csv.Reader
+	records int
+}
+
+func (r *csvReader) Next() (*Entry, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	r.records++
+	if len(record) < 2 {
+		return nil, fmt.Errorf("record %d must have a key and a value column", r.records)
+	}
+	return &Entry{Key: record[0], Value: record[1]}, nil
+}
+
+// jsonReader reads the members of a JSON object, the values being written as JSON text
+type jsonReader struct {
+	decoder *json.Decoder
+	started bool
+}
+
+func (r *jsonReader) Next() (*Entry, error) {
+	if !r.started {
+		if token, err := r.decoder.Token(); err != nil {
+			return nil, err
+		} else if delim, ok := token.(json.Delim); !ok || delim != '{' {
+			return nil, errors.New("the data file must be a JSON object")
+		}
+		r.started = true
+	}
+	if !r.decoder.More() {
+		return nil, io.EOF
+	}
+	token, err := r.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	value := json.RawMessage{}
+	if err := r.decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid value of key '%s': %w", token, err)
+	}
+	return &Entry{Key: token.(string), Value: string(value)}, nil
+}
+
+// Progress of the load, reported after each written entry
+type Progress struct {
+	Loaded int64
+	Failed int64
+	// Error of the last entry that couldn't be written, nil if it was written
+	Err error
+}
+
+// Loader writes the entries to a cache with the REST API
+type Loader struct {
+	Client         client.HttpClient
+	Cache          string
+	ValueMediaType string
+	Concurrency    int
+	// MaxErrors is the number of entries that can fail to be written before the load is stopped
+	MaxErrors int64
+}
+
+// Load writes all the entries of the reader to the cache, calling progress after each entry. An error is returned if
+// the data file can't be read or more than MaxErrors entries can't be written
+func (l *Loader) Load(reader Reader, progress func(Progress)) error {
+	concurrency := l.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	entries := make(chan *Entry)
+	stop := make(chan struct{})
+	var mu sync.Mutex
+	var stopOnce sync.Once
+	status := Progress{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range entries {
+				err := l.put(entry)
+				mu.Lock()
+				if err != nil {
+					status.Failed++
+					if status.Failed > l.MaxErrors {
+						stopOnce.Do(func() { close(stop) })
+					}
+				} else {
+					status.Loaded++
+				}
+				status.Err = err
+				progress(status)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var readErr error
+read:
+	for {
+		entry, err := reader.Next()
+		if err != nil {
+			if err != io.EOF {
+				readErr = fmt.Errorf("unable to read the data file: %w", err)
+			}
+			break
+		}
+		select {
+		case entries <- entry:
+		case <-stop:
+			break read
+		}
+	}
+	close(entries)
+	wg.Wait()
+
+	if readErr != nil {
+		return readErr
+	}
+	if status.Failed > l.MaxErrors {
+		return fmt.Errorf("%d entries couldn't be written to cache '%s'", status.Failed, l.Cache)
+	}
+	return nil
+}
+
+func (l *Loader) put(entry *Entry) error {
+	path := fmt.Sprintf("%s/caches/%s/%s", consts.ServerHTTPBasePath, url.PathEscape(l.Cache), url.PathEscape(entry.Key))
+	headers := map[string]string{"Content-Type": l.ValueMediaType}
+	rsp, err, reason := l.Client.Put("", path, entry.Value, headers)
+	if err != nil {
+		return fmt.Errorf("unable to write key '%s': %w", entry.Key, err)
+	}
+	if reason != "" {
+		return fmt.Errorf("unable to write key '%s': %s", entry.Key, reason)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusNoContent && rsp.StatusCode != http.StatusOK {
+		msg := fmt.Sprintf("status %d", rsp.StatusCode)
+		if body, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 256)); len(body) > 0 {
+			msg = fmt.Sprintf("%s: %s", msg, body)
+		}
+		return fmt.Errorf("unable to write key '%s': %s", entry.Key, msg)
+	}
+	return nil
+}
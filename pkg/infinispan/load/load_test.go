@@ -0,0 +1,104 @@
+package load
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	client "github.com/infinispan/infinispan-operator/pkg/infinispan/client/http"
+	"github.com/stretchr/testify/assert"
+)
+
+// cacheClient stores the entries written with PUT, failing the keys starting with "fail"
+type cacheClient struct {
+	client.HttpClient
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func (c *cacheClient) Put(podName, path, payload string, headers map[string]string) (*http.Response, error, string) {
+	key := path[strings.LastIndex(path, "/")+1:]
+	status := http.StatusNoContent
+	if strings.HasPrefix(key, "fail") {
+		status = http.StatusBadRequest
+	} else {
+		c.mu.Lock()
+		c.entries[key] = payload
+		c.mu.Unlock()
+	}
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(""))}, nil, ""
+}
+
+func readAll(t *testing.T, reader Reader) []Entry {
+	var entries []Entry
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return entries
+		}
+		assert.NoError(t, err)
+		entries = append(entries, *entry)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	reader, err := NewReader(FormatCSV, strings.NewReader("# code,name\nit,Italy\nfr,\"France, Republic\"\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{Key: "it", Value: "Italy"}, {Key: "fr", Value: "France, Republic"}}, readAll(t, reader))
+
+	reader, _ = NewReader(FormatCSV, strings.NewReader("it,Italy\nfr\n"))
+	_, _ = reader.Next()
+	_, err = reader.Next()
+	assert.EqualError(t, err, "record 2 must have a key and a value column")
+}
+
+func TestJSONReader(t *testing.T) {
+	reader, err := NewReader(FormatJSON, strings.NewReader(`{"it": {"name": "Italy"}, "fr": "France", "de": 83}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{Key: "it", Value: `{"name": "Italy"}`}, {Key: "fr", Value: `"France"`}, {Key: "de", Value: "83"}}, readAll(t, reader))
+
+	reader, _ = NewReader(FormatJSON, strings.NewReader(`["it"]`))
+	_, err = reader.Next()
+	assert.EqualError(t, err, "the data file must be a JSON object")
+
+	_, err = NewReader("XML", strings.NewReader(""))
+	assert.EqualError(t, err, "unsupported format 'XML', expected CSV or JSON")
+}
+
+func TestLoad(t *testing.T) {
+	data := &strings.Builder{}
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(data, "key%d,value%d\n", i, i)
+	}
+	data.WriteString("fail1,value\n")
+	cache := &cacheClient{entries: map[string]string{}}
+	loader := &Loader{Client: cache, Cache: "countries", ValueMediaType: "text/plain", Concurrency: 4, MaxErrors: 1}
+	reader, _ := NewReader(FormatCSV, strings.NewReader(data.String()))
+
+	var last Progress
+	var errs []error
+	assert.NoError(t, loader.Load(reader, func(p Progress) {
+		last = p
+		if p.Err != nil {
+			errs = append(errs, p.Err)
+		}
+	}))
+	assert.Len(t, cache.entries, 50)
+	assert.Equal(t, "value7", cache.entries["key7"])
+	assert.Equal(t, Progress{Loaded: 50, Failed: 1}, Progress{Loaded: last.Loaded, Failed: last.Failed})
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "unable to write key 'fail1': status 400")
+}
+
+func TestLoadMaxErrors(t *testing.T) {
+	cache := &cacheClient{entries: map[string]string{}}
+	loader := &Loader{Client: cache, Cache: "countries", ValueMediaType: "text/plain"}
+	reader, _ := NewReader(FormatCSV, strings.NewReader("fail1,a\n"))
+	err := loader.Load(reader, func(Progress) {})
+	assert.EqualError(t, err, "1 entries couldn't be written to cache 'countries'")
+	assert.Empty(t, cache.entries)
+}
@@ -2,9 +2,13 @@ package security
 
 import (
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
+	"math/big"
+	"time"
 
 	certUtil "k8s.io/client-go/util/cert"
 
@@ -39,3 +43,40 @@ func GenerateTruststore(pemFiles [][]byte, password string) ([]byte, error) {
 	}
 	return truststore, nil
 }
+
+// GenerateKeystore creates a PKCS12 keystore holding a private key and its self-signed certificate. The certificate
+// authenticates the holders of the keystore with each other, so it is valid for 10 years
+func GenerateKeystore(commonName, password string) ([]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to generate private key: %w", err)
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to generate certificate serial number: %w", err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse certificate: %w", err)
+	}
+	keystore, err := p12.Encode(rand.Reader, key, cert, nil, password)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to create keystore: %w", err)
+	}
+	return keystore, nil
+}
@@ -90,6 +90,34 @@ func GetUserCredentials() ([]byte, error) {
 	return CreateIdentitiesFor(consts.DefaultDeveloperUser, pass)
 }
 
+// NewPassword generates a random password
+func NewPassword() (string, error) {
+	return getRandomStringForAuth(16)
+}
+
+// ParseIdentities parses identities in yaml format
+func ParseIdentities(descriptor []byte) (*Identities, error) {
+	identities := &Identities{}
+	if err := yaml.Unmarshal(descriptor, identities); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// RotatePasswords generates a new password for each of the identities, keeping their usernames and roles
+func RotatePasswords(descriptor []byte) ([]byte, error) {
+	identities, err := ParseIdentities(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	for i := range identities.Credentials {
+		if identities.Credentials[i].Password, err = NewPassword(); err != nil {
+			return nil, err
+		}
+	}
+	return yaml.Marshal(identities)
+}
+
 // FindPassword finds a user's password
 func FindPassword(usr string, descriptor []byte) (string, error) {
 	var identities Identities
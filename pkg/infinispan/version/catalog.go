@@ -0,0 +1,255 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	consts "github.com/infinispan/infinispan-operator/controllers/constants"
+)
+
+// Capabilities of the server that the spec of a cluster can depend on
+const (
+	CapabilityMemcached     = "memcached"
+	CapabilityResp          = "resp"
+	CapabilityCanaryUpgrade = "canaryUpgrade"
+)
+
+// Architectures of the nodes, as labelled by kubernetes.io/arch, that the operand images can be built for
+const (
+	ArchitectureAmd64   = "amd64"
+	ArchitectureArm64   = "arm64"
+	ArchitectureS390x   = "s390x"
+	ArchitecturePpc64le = "ppc64le"
+)
+
+var supportedArchitectures = map[string]bool{
+	ArchitectureAmd64: true, ArchitectureArm64: true, ArchitectureS390x: true, ArchitecturePpc64le: true,
+}
+
+var digestRegex = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// Operand is an Infinispan server version that the operator can provision
+type Operand struct {
+	// Version of the server, e.g. 13.0.2
+	Version string `json:"version"`
+	// Image of the server
+	Image string `json:"image"`
+	// The oldest version that can be upgraded to this version, any older version when empty
+	MinUpgradeVersion string `json:"minUpgradeVersion,omitempty"`
+	// Capabilities of the server, the operand supports all capabilities when nil
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Architectures that the image is available for, mapped to the digest of the image of the architecture when known.
+	// The pods are only scheduled on the nodes of these architectures, any node when empty
+	Architectures map[string]string `json:"architectures,omitempty"`
+}
+
+// ArchitectureNames returns the sorted architectures that the image is available for
+func (o *Operand) ArchitectureNames() []string {
+	names := make([]string, 0, len(o.Architectures))
+	for arch := range o.Architectures {
+		names = append(names, arch)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ImageForArchitecture returns the image of the architecture, pinned to its digest when the catalog lists it
+func (o *Operand) ImageForArchitecture(arch string) (string, error) {
+	if len(o.Architectures) == 0 {
+		return o.Image, nil
+	}
+	digest, ok := o.Architectures[arch]
+	if !ok {
+		return "", fmt.Errorf("the image of version '%s' is not available for architecture '%s'", o.Version, arch)
+	}
+	if digest == "" {
+		return o.Image, nil
+	}
+	return ImageRepository(o.Image) + "@" + digest, nil
+}
+
+// ResolvedImage returns the image that the pods run. The image of a single architecture operand is pinned to its
+// digest, while the image of a multi-architecture operand is resolved by the container runtime of each node
+func (o *Operand) ResolvedImage() string {
+	if len(o.Architectures) != 1 {
+		return o.Image
+	}
+	image, _ := o.ImageForArchitecture(o.ArchitectureNames()[0])
+	return image
+}
+
+// HasCapability returns true if the operand supports the capability
+func (o *Operand) HasCapability(capability string) bool {
+	if o.Capabilities == nil {
+		return true
+	}
+	for _, c := range o.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Catalog lists the operands that the operator can provision
+type Catalog []Operand
+
+// OperandCatalogEnv is the operator env containing the JSON operand catalog, usually populated from a ConfigMap
+const OperandCatalogEnv = "OPERAND_CATALOG"
+
+// Operands is the catalog of the operator. Only the default image of the operator is available until the catalog is
+// loaded from the OPERAND_CATALOG env
+var Operands = DefaultCatalog(consts.DefaultImageName)
+
+// LoadOperands replaces the default catalog with the catalog of the OPERAND_CATALOG env when defined
+func LoadOperands() error {
+	catalog := consts.GetEnvWithDefault(OperandCatalogEnv, "")
+	if catalog == "" {
+		return nil
+	}
+	operands, err := ParseCatalog(catalog)
+	if err != nil {
+		return err
+	}
+	Operands = operands
+	return nil
+}
+
+// DefaultCatalog returns a catalog containing only the default image, versioned by its tag
+func DefaultCatalog(defaultImage string) Catalog {
+	return Catalog{{Version: ImageVersion(defaultImage), Image: defaultImage}}
+}
+
+// ParseCatalog parses and validates a JSON array of operands
+func ParseCatalog(catalog string) (Catalog, error) {
+	operands := Catalog{}
+	if err := json.Unmarshal([]byte(catalog), &operands); err != nil {
+		return nil, fmt.Errorf("unable to parse the operand catalog: %w", err)
+	}
+	versions := map[string]bool{}
+	for _, o := range operands {
+		if o.Version == "" || o.Image == "" {
+			return nil, fmt.Errorf("the operands of the catalog must define a version and an image")
+		}
+		if versions[o.Version] {
+			return nil, fmt.Errorf("the operand version '%s' is defined more than once", o.Version)
+		}
+		versions[o.Version] = true
+		for arch, digest := range o.Architectures {
+			if !supportedArchitectures[arch] {
+				return nil, fmt.Errorf("the operand version '%s' defines unsupported architecture '%s'", o.Version, arch)
+			}
+			if digest != "" && !digestRegex.MatchString(digest) {
+				return nil, fmt.Errorf("the digest '%s' of the operand version '%s' for architecture '%s' must be a sha256 digest", digest, o.Version, arch)
+			}
+		}
+	}
+	return operands, nil
+}
+
+// ImageVersion returns the tag of the image, which is used as the version of the default operand
+func ImageVersion(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
+
+// ImageRepository returns the image without its tag or digest
+func ImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
+
+// Get returns the operand of the version
+func (c Catalog) Get(version string) (*Operand, error) {
+	for i := range c {
+		if c[i].Version == version {
+			return &c[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported version '%s', the supported versions are %s", version, strings.Join(c.Versions(), ", "))
+}
+
+// ForImage returns the operand of the image, or of its resolved image, nil if the image is not part of the catalog
+func (c Catalog) ForImage(image string) *Operand {
+	for i := range c {
+		if c[i].Image == image || c[i].ResolvedImage() == image {
+			return &c[i]
+		}
+	}
+	return nil
+}
+
+// Versions returns the versions of the catalog
+func (c Catalog) Versions() []string {
+	versions := make([]string, len(c))
+	for i, o := range c {
+		versions[i] = o.Version
+	}
+	return versions
+}
+
+// ValidateUpgrade verifies that a cluster running the from version can be upgraded to the to version. Downgrades are
+// not supported, as the servers can't read the state persisted by a newer version
+func (c Catalog) ValidateUpgrade(from, to string) error {
+	if from == to {
+		return nil
+	}
+	target, err := c.Get(to)
+	if err != nil {
+		return err
+	}
+	if Compare(from, to) > 0 {
+		return fmt.Errorf("downgrading from version '%s' to '%s' is not supported", from, to)
+	}
+	if target.MinUpgradeVersion != "" && Compare(from, target.MinUpgradeVersion) < 0 {
+		return fmt.Errorf("upgrading from version '%s' to '%s' is not supported, upgrade to version '%s' first", from, to, target.MinUpgradeVersion)
+	}
+	return nil
+}
+
+// Compare compares two dotted versions numerically, returning -1, 0 or 1. Non numeric segments, e.g. Final, are
+// compared lexicographically
+func Compare(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if x == y {
+			continue
+		}
+		xi, xErr := strconv.Atoi(x)
+		yi, yErr := strconv.Atoi(y)
+		switch {
+		case x == "":
+			return -1
+		case y == "":
+			return 1
+		case xErr == nil && yErr == nil:
+			if xi < yi {
+				return -1
+			}
+			return 1
+		case x < y:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package version
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	assert.Equal(t, 0, Compare("13.0.2", "13.0.2"))
+	assert.Equal(t, -1, Compare("13.0.2", "13.0.10"))
+	assert.Equal(t, 1, Compare("14.0", "13.0.10"))
+	assert.Equal(t, -1, Compare("13.0", "13.0.1"))
+	assert.Equal(t, -1, Compare("13.0.2.CR1", "13.0.2.Final"))
+}
+
+func TestParseCatalog(t *testing.T) {
+	catalog, err := ParseCatalog(`[{"version":"13.0.2","image":"quay.io/infinispan/server:13.0.2","capabilities":["memcached"]},
+		{"version":"14.0.1","image":"quay.io/infinispan/server:14.0.1","minUpgradeVersion":"13.0.0"}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"13.0.2", "14.0.1"}, catalog.Versions())
+
+	operand, err := catalog.Get("13.0.2")
+	assert.NoError(t, err)
+	assert.True(t, operand.HasCapability(CapabilityMemcached))
+	assert.False(t, operand.HasCapability(CapabilityResp))
+	assert.Equal(t, "14.0.1", catalog.ForImage("quay.io/infinispan/server:14.0.1").Version)
+	assert.Nil(t, catalog.ForImage("quay.io/infinispan/server:12.1"))
+
+	_, err = catalog.Get("15.0.0")
+	assert.EqualError(t, err, "unsupported version '15.0.0', the supported versions are 13.0.2, 14.0.1")
+
+	_, err = ParseCatalog(`[{"version":"13.0.2","image":"a"},{"version":"13.0.2","image":"b"}]`)
+	assert.Error(t, err)
+	_, err = ParseCatalog(`[{"version":"13.0.2"}]`)
+	assert.Error(t, err)
+}
+
+func TestValidateUpgrade(t *testing.T) {
+	catalog := Catalog{
+		{Version: "12.1.7", Image: "quay.io/infinispan/server:12.1.7"},
+		{Version: "13.0.2", Image: "quay.io/infinispan/server:13.0.2"},
+		{Version: "14.0.1", Image: "quay.io/infinispan/server:14.0.1", MinUpgradeVersion: "13.0.0"},
+	}
+	assert.NoError(t, catalog.ValidateUpgrade("12.1.7", "13.0.2"))
+	assert.NoError(t, catalog.ValidateUpgrade("13.0.2", "13.0.2"))
+	assert.NoError(t, catalog.ValidateUpgrade("13.0.2", "14.0.1"))
+	assert.EqualError(t, catalog.ValidateUpgrade("14.0.1", "13.0.2"), "downgrading from version '14.0.1' to '13.0.2' is not supported")
+	assert.EqualError(t, catalog.ValidateUpgrade("12.1.7", "14.0.1"), "upgrading from version '12.1.7' to '14.0.1' is not supported, upgrade to version '13.0.0' first")
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	assert.Equal(t, "13.0", DefaultCatalog("quay.io/infinispan/server:13.0")[0].Version)
+	assert.Equal(t, "latest", DefaultCatalog("localhost:5000/infinispan/server")[0].Version)
+}
+
+func TestOperandArchitectures(t *testing.T) {
+	amd64 := "sha256:" + strings.Repeat("a", 64)
+	catalog, err := ParseCatalog(`[{"version":"14.0.1","image":"quay.io/infinispan/server:14.0.1","architectures":{"arm64":"","amd64":"` + amd64 + `"}},
+		{"version":"14.0.2","image":"quay.io/infinispan/server:14.0.2","architectures":{"amd64":"` + amd64 + `"}}]`)
+	assert.NoError(t, err)
+
+	operand, _ := catalog.Get("14.0.1")
+	assert.Equal(t, []string{"amd64", "arm64"}, operand.ArchitectureNames())
+	image, err := operand.ImageForArchitecture(ArchitectureAmd64)
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io/infinispan/server@"+amd64, image)
+	image, err = operand.ImageForArchitecture(ArchitectureArm64)
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io/infinispan/server:14.0.1", image)
+	_, err = operand.ImageForArchitecture(ArchitectureS390x)
+	assert.Error(t, err)
+	// The multi-architecture image is resolved by the container runtime
+	assert.Equal(t, "quay.io/infinispan/server:14.0.1", operand.ResolvedImage())
+
+	// The single architecture image is pinned to its digest
+	operand, _ = catalog.Get("14.0.2")
+	assert.Equal(t, "quay.io/infinispan/server@"+amd64, operand.ResolvedImage())
+	assert.Equal(t, "14.0.2", catalog.ForImage("quay.io/infinispan/server@"+amd64).Version)
+
+	_, err = ParseCatalog(`[{"version":"14.0.1","image":"a","architectures":{"riscv64":""}}]`)
+	assert.Error(t, err)
+	_, err = ParseCatalog(`[{"version":"14.0.1","image":"a","architectures":{"amd64":"latest"}}]`)
+	assert.Error(t, err)
+}
+
+func TestImageRepository(t *testing.T) {
+	assert.Equal(t, "quay.io/infinispan/server", ImageRepository("quay.io/infinispan/server:14.0.1"))
+	assert.Equal(t, "localhost:5000/infinispan/server", ImageRepository("localhost:5000/infinispan/server"))
+	assert.Equal(t, "quay.io/infinispan/server", ImageRepository("quay.io/infinispan/server@sha256:abc"))
+}
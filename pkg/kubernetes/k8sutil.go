@@ -49,6 +49,28 @@ func GetWatchNamespace() (string, error) {
 	return ns, nil
 }
 
+// GetWatchNamespaces returns the namespaces of the comma-separated WATCH_NAMESPACE list, without duplicates. No
+// namespace is returned when the operator watches all namespaces
+func GetWatchNamespaces() ([]string, error) {
+	watchNamespace, err := GetWatchNamespace()
+	if err != nil {
+		return nil, err
+	}
+	return parseNamespaces(watchNamespace), nil
+}
+
+func parseNamespaces(list string) []string {
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, ns := range strings.Split(list, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" && !seen[ns] {
+			seen[ns] = true
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
 // ErrNoNamespace indicates that a namespace could not be found for the current
 // environment
 var ErrNoNamespace = fmt.Errorf("namespace not found for current environment")
@@ -59,7 +81,7 @@ var ErrRunLocal = fmt.Errorf("operator run mode forced to local")
 
 // GetOperatorNamespace returns the namespace the operator should be running in.
 func getOperatorNamespace() (string, error) {
-	if isRunModeLocal() {
+	if IsRunModeLocal() {
 		return "", ErrRunLocal
 	}
 	nsBytes, err := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
@@ -78,10 +100,10 @@ func GetOperatorNamespace() (string, error) {
 	operatorNs, err := getOperatorNamespace()
 	// This makes everything work even running outside the cluster
 	if errors.Is(err, ErrRunLocal) {
-		var operatorWatchNs string
-		operatorWatchNs, err = GetWatchNamespace()
-		if operatorWatchNs != "" {
-			operatorNs = strings.Split(operatorWatchNs, ",")[0]
+		var operatorWatchNs []string
+		operatorWatchNs, err = GetWatchNamespaces()
+		if len(operatorWatchNs) > 0 {
+			operatorNs = operatorWatchNs[0]
 		}
 	}
 	return operatorNs, err
@@ -111,7 +133,7 @@ func ResourceExists(dc discovery.DiscoveryInterface, apiGroupVersion, kind strin
 // is currently running.
 // It expects the environment variable POD_NAME to be set by the downwards API.
 func GetPod(ctx context.Context, client crclient.Client, ns string) (*corev1.Pod, error) {
-	if isRunModeLocal() {
+	if IsRunModeLocal() {
 		return nil, ErrRunLocal
 	}
 	podName := os.Getenv(PodNameEnvVar)
@@ -139,7 +161,8 @@ func GetPod(ctx context.Context, client crclient.Client, ns string) (*corev1.Pod
 	return pod, nil
 }
 
-func isRunModeLocal() bool {
+// IsRunModeLocal returns true if the operator runs outside of the Kubernetes cluster
+func IsRunModeLocal() bool {
 	return os.Getenv(ForceRunModeEnv) == string(LocalRunMode)
 }
 
@@ -0,0 +1,13 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNamespaces(t *testing.T) {
+	assert.Nil(t, parseNamespaces(""))
+	assert.Equal(t, []string{"namespace"}, parseNamespaces("namespace"))
+	assert.Equal(t, []string{"ns-1", "ns-2"}, parseNamespaces(" ns-1, ns-2,,ns-1 "))
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"sort"
+	"strconv"
 
 	"github.com/go-logr/logr"
 	consts "github.com/infinispan/infinispan-operator/controllers/constants"
@@ -26,6 +28,7 @@ import (
 	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
 )
 
 // Kubernetes abstracts interaction with a Kubernetes cluster
@@ -53,7 +56,7 @@ func NewKubernetesFromController(mgr manager.Manager) *Kubernetes {
 
 // NewKubernetesFromConfig creates a new Kubernetes from the Kubernetes master URL to connect to
 func NewKubernetesFromConfig(config *rest.Config, scheme *runtime.Scheme) (*Kubernetes, error) {
-	kubeClient, err := client.New(config, client.Options{})
+	kubeClient, err := client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +105,25 @@ func (k Kubernetes) GetSecret(secretName, namespace string, ctx context.Context)
 	return secret, err
 }
 
+// PodAddress returns the address of the pod listed in the Endpoints of the headless service, which includes the pods
+// that are not ready, so that REST calls can target a specific pod instead of being load balanced
+func (k Kubernetes) PodAddress(serviceName, podName, namespace string, ctx context.Context) (string, error) {
+	endpoints := &corev1.Endpoints{}
+	if err := k.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: serviceName}, endpoints); err != nil {
+		return "", err
+	}
+	for _, subset := range endpoints.Subsets {
+		for _, addresses := range [][]corev1.EndpointAddress{subset.Addresses, subset.NotReadyAddresses} {
+			for _, address := range addresses {
+				if address.TargetRef != nil && address.TargetRef.Kind == "Pod" && address.TargetRef.Name == podName {
+					return address.IP, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("pod '%s' is not an endpoint of service '%s'", podName, serviceName)
+}
+
 // ExecOptions specify execution options
 type ExecOptions struct {
 	Command   []string
@@ -183,6 +205,53 @@ func (k Kubernetes) GetServingCertsMode(ctx context.Context) string {
 	return ""
 }
 
+// IsUserWorkloadMonitoringEnabled returns true if the OpenShift monitoring stack scrapes the ServiceMonitors of user
+// namespaces, as configured by the cluster-monitoring-config ConfigMap
+func (k Kubernetes) IsUserWorkloadMonitoringEnabled(ctx context.Context) (bool, error) {
+	configMap := &corev1.ConfigMap{}
+	err := k.RestClient.Get().AbsPath("api/v1/namespaces/openshift-monitoring/configmaps/cluster-monitoring-config").Do(ctx).Into(configMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// User workload monitoring is disabled by default
+			return false, nil
+		}
+		return false, err
+	}
+	config := struct {
+		EnableUserWorkload bool `json:"enableUserWorkload"`
+	}{}
+	if err := yaml.Unmarshal([]byte(configMap.Data["config.yaml"]), &config); err != nil {
+		return false, fmt.Errorf("unable to parse the cluster monitoring configuration: %w", err)
+	}
+	return config.EnableUserWorkload, nil
+}
+
+// GetPodIPFamily returns the IP family of the pod network, as seen by the operator pod, or an empty string if it can't
+// be determined
+func (k Kubernetes) GetPodIPFamily(ctx context.Context) corev1.IPFamily {
+	namespace, err := GetOperatorNamespace()
+	if err != nil {
+		return ""
+	}
+	pod, err := GetPod(ctx, k.Client, namespace)
+	if err != nil {
+		return ""
+	}
+	return IPFamily(pod.Status.PodIP)
+}
+
+// IPFamily returns the IP family of the address, or an empty string if it isn't an IP address
+func IPFamily(address string) corev1.IPFamily {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return ""
+	}
+	if ip.To4() != nil {
+		return corev1.IPv4Protocol
+	}
+	return corev1.IPv6Protocol
+}
+
 func (k Kubernetes) GetKubernetesRESTConfig(masterURL, secretName, namespace string, logger logr.Logger, ctx context.Context) (*restclient.Config, error) {
 	logger.Info("connect to backup Kubernetes cluster", "url", masterURL)
 
@@ -296,7 +365,7 @@ func (k Kubernetes) GetExternalAddress(route *corev1.Service) string {
 	// If the cluster exposes external IP then return it
 	if len(route.Status.LoadBalancer.Ingress) > 0 {
 		if route.Status.LoadBalancer.Ingress[0].IP != "" {
-			return fmt.Sprintf("%s:%d", route.Status.LoadBalancer.Ingress[0].IP, route.Spec.Ports[0].Port)
+			return net.JoinHostPort(route.Status.LoadBalancer.Ingress[0].IP, strconv.Itoa(int(route.Spec.Ports[0].Port)))
 		}
 		if route.Status.LoadBalancer.Ingress[0].Hostname != "" {
 			return fmt.Sprintf("%s:%d", route.Status.LoadBalancer.Ingress[0].Hostname, route.Spec.Ports[0].Port)
@@ -330,7 +399,13 @@ func (k Kubernetes) ResourcesListByField(namespace, fieldName, fieldValue string
 }
 
 func (k Kubernetes) Logs(pod, namespace string, ctx context.Context) (logs string, err error) {
-	readCloser, err := k.RestClient.Get().Namespace(namespace).Resource("pods").Name(pod).SubResource("log").Stream(ctx)
+	return k.LogsWithOptions(pod, namespace, &corev1.PodLogOptions{}, ctx)
+}
+
+// LogsWithOptions returns the logs of the pod selected by the given options, e.g. the lines of the last seconds
+func (k Kubernetes) LogsWithOptions(pod, namespace string, options *corev1.PodLogOptions, ctx context.Context) (logs string, err error) {
+	readCloser, err := k.RestClient.Get().Namespace(namespace).Resource("pods").Name(pod).SubResource("log").
+		VersionedParams(options, scheme.ParameterCodec).Stream(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodAddress(t *testing.T) {
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-infinispan-admin-headless", Namespace: "testing"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "example-infinispan-0"}}},
+			NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.2", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "example-infinispan-1"}}},
+		}},
+	}
+	k := Kubernetes{Client: fake.NewClientBuilder().WithObjects(endpoints).Build()}
+
+	address, err := k.PodAddress(endpoints.Name, "example-infinispan-0", "testing", context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", address)
+
+	// The pods that are not ready can be targeted too
+	address, err = k.PodAddress(endpoints.Name, "example-infinispan-1", "testing", context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", address)
+
+	_, err = k.PodAddress(endpoints.Name, "example-infinispan-2", "testing", context.TODO())
+	assert.EqualError(t, err, "pod 'example-infinispan-2' is not an endpoint of service 'example-infinispan-admin-headless'")
+}
@@ -0,0 +1,223 @@
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// BER tags of the LDAP messages exchanged by a simple bind, as defined by RFC 4511
+const (
+	tagInteger      = 0x02
+	tagOctetString  = 0x04
+	tagEnumerated   = 0x0a
+	tagSequence     = 0x30
+	tagBindRequest  = 0x60
+	tagBindResponse = 0x61
+	tagUnbind       = 0x42
+	tagSimpleAuth   = 0x80
+
+	resultSuccess = 0
+	ldapVersion   = 3
+)
+
+// resultCodes describes the result codes of the bind failures that are usually caused by a misconfiguration
+var resultCodes = map[int]string{
+	32: "noSuchObject",
+	34: "invalidDNSyntax",
+	48: "inappropriateAuthentication",
+	49: "invalidCredentials",
+	50: "insufficientAccessRights",
+	51: "busy",
+	52: "unavailable",
+	53: "unwillingToPerform",
+}
+
+// Bind connects to the LDAP server of the ldap:// or ldaps:// URL and performs a simple bind with the DN and password,
+// returning an error if the server can't be reached or rejects the credentials
+func Bind(serverURL, dn, password string, tlsConfig *tls.Config, timeout time.Duration) error {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return fmt.Errorf("invalid LDAP URL '%s': %w", serverURL, err)
+	}
+	host := u.Host
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: timeout}
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+		conn, err = dialer.Dial("tcp", host)
+	case "ldaps":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, tlsConfig)
+	default:
+		return fmt.Errorf("unsupported LDAP URL scheme '%s', expected ldap or ldaps", u.Scheme)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to connect to the LDAP server %s: %w", host, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	return bind(conn, dn, password)
+}
+
+func bind(conn io.ReadWriter, dn, password string) error {
+	request := encode(tagSequence,
+		encodeInt(tagInteger, 1),
+		encode(tagBindRequest,
+			encodeInt(tagInteger, ldapVersion),
+			encodeString(tagOctetString, dn),
+			encodeString(tagSimpleAuth, password),
+		),
+	)
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("unable to send the LDAP bind request: %w", err)
+	}
+
+	tag, message, err := readElement(bufio.NewReader(conn))
+	if err != nil {
+		return fmt.Errorf("unable to read the LDAP bind response: %w", err)
+	}
+	if tag != tagSequence {
+		return errors.New("invalid LDAP bind response")
+	}
+	// Skip the message ID
+	if _, _, message, err = nextElement(message); err != nil {
+		return err
+	}
+	tag, response, _, err := nextElement(message)
+	if err != nil {
+		return err
+	}
+	if tag != tagBindResponse {
+		return fmt.Errorf("unexpected LDAP response with tag 0x%x", tag)
+	}
+	tag, code, response, err := nextElement(response)
+	if err != nil || tag != tagEnumerated {
+		return errors.New("invalid LDAP bind response result code")
+	}
+	resultCode := decodeInt(code)
+
+	// Best effort unbind, the connection is closed anyway
+	_, _ = conn.Write(encode(tagSequence, encodeInt(tagInteger, 2), []byte{tagUnbind, 0}))
+
+	if resultCode == resultSuccess {
+		return nil
+	}
+	// The matched DN is followed by the diagnostic message
+	var diagnostic []byte
+	if _, _, response, err = nextElement(response); err == nil {
+		_, diagnostic, _, _ = nextElement(response)
+	}
+	name := resultCodes[resultCode]
+	if name == "" {
+		name = "error"
+	}
+	msg := fmt.Sprintf("LDAP bind failed with result %s (%d)", name, resultCode)
+	if len(diagnostic) > 0 {
+		msg = fmt.Sprintf("%s: %s", msg, diagnostic)
+	}
+	return errors.New(msg)
+}
+
+func encode(tag byte, contents ...[]byte) []byte {
+	var content []byte
+	for _, c := range contents {
+		content = append(content, c...)
+	}
+	return append(append([]byte{tag}, encodeLength(len(content))...), content...)
+}
+
+func encodeString(tag byte, s string) []byte {
+	return encode(tag, []byte(s))
+}
+
+func encodeInt(tag byte, i int) []byte {
+	// The values are small positive integers, encoded on a single byte
+	return encode(tag, []byte{byte(i)})
+}
+
+func encodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+	var b []byte
+	for l := length; l > 0; l >>= 8 {
+		b = append([]byte{byte(l)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+func decodeInt(b []byte) int {
+	i := 0
+	for _, v := range b {
+		i = i<<8 | int(v)
+	}
+	return i
+}
+
+// readElement reads a single BER element from the connection
+func readElement(r *bufio.Reader) (byte, []byte, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length := int(first)
+	if first&0x80 != 0 {
+		n := int(first & 0x7f)
+		if n == 0 || n > 4 {
+			return 0, nil, errors.New("unsupported BER length")
+		}
+		length = 0
+		for i := 0; i < n; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return 0, nil, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return 0, nil, err
+	}
+	return tag, content, nil
+}
+
+// nextElement splits the first BER element from the buffer, returning its tag, content and the remaining bytes
+func nextElement(b []byte) (byte, []byte, []byte, error) {
+	if len(b) < 2 {
+		return 0, nil, nil, errors.New("truncated BER element")
+	}
+	tag, length, offset := b[0], int(b[1]), 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 || len(b) < 2+n {
+			return 0, nil, nil, errors.New("unsupported BER length")
+		}
+		length = decodeInt(b[2 : 2+n])
+		offset += n
+	}
+	if len(b) < offset+length {
+		return 0, nil, nil, errors.New("truncated BER element")
+	}
+	return tag, b[offset : offset+length], b[offset+length:], nil
+}
@@ -0,0 +1,63 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type conn struct {
+	io.Reader
+	io.Writer
+}
+
+func bindResponse(code byte, diagnostic string) *bytes.Buffer {
+	return bytes.NewBuffer(encode(tagSequence,
+		encodeInt(tagInteger, 1),
+		encode(tagBindResponse,
+			encode(tagEnumerated, []byte{code}),
+			encodeString(tagOctetString, ""),
+			encodeString(tagOctetString, diagnostic),
+		),
+	))
+}
+
+func TestBind(t *testing.T) {
+	sent := &bytes.Buffer{}
+	assert.NoError(t, bind(conn{bindResponse(resultSuccess, ""), sent}, "cn=admin,dc=example,dc=org", "secret"))
+
+	// The request is followed by the unbind
+	tag, request, err := readElement(bufio.NewReader(sent))
+	assert.NoError(t, err)
+	assert.Equal(t, byte(tagSequence), tag)
+	_, _, rest, _ := nextElement(request)
+	tag, bindRequest, _, _ := nextElement(rest)
+	assert.Equal(t, byte(tagBindRequest), tag)
+	assert.True(t, bytes.Contains(bindRequest, []byte("cn=admin,dc=example,dc=org")))
+	assert.True(t, bytes.Contains(bindRequest, []byte("secret")))
+
+	err = bind(conn{bindResponse(49, "80090308: LdapErr"), &bytes.Buffer{}}, "cn=admin,dc=example,dc=org", "wrong")
+	assert.EqualError(t, err, "LDAP bind failed with result invalidCredentials (49): 80090308: LdapErr")
+
+	assert.Error(t, bind(conn{&bytes.Buffer{}, &bytes.Buffer{}}, "cn=admin", "secret"))
+}
+
+func TestEncodeLength(t *testing.T) {
+	assert.Equal(t, []byte{0x7f}, encodeLength(127))
+	assert.Equal(t, []byte{0x81, 0x80}, encodeLength(128))
+	assert.Equal(t, []byte{0x82, 0x01, 0x00}, encodeLength(256))
+
+	long := encodeString(tagOctetString, string(make([]byte, 300)))
+	tag, content, rest, err := nextElement(long)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(tagOctetString), tag)
+	assert.Len(t, content, 300)
+	assert.Empty(t, rest)
+}
+
+func TestBindURL(t *testing.T) {
+	assert.EqualError(t, Bind("http://ldap.example.org", "", "", nil, 0), "unsupported LDAP URL scheme 'http', expected ldap or ldaps")
+}
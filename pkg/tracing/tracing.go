@@ -0,0 +1,228 @@
+// Package tracing records the spans of the operator and exports them to an OpenTelemetry collector with the OTLP/HTTP
+// JSON encoding
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// TracesPath is the path of the OTLP/HTTP traces endpoint of the collector
+	TracesPath = "/v1/traces"
+	// ExportTimeout bounds the request exporting the spans of a trace
+	ExportTimeout = 10 * time.Second
+
+	spanKindInternal = 1
+	statusCodeOk     = 1
+	statusCodeError  = 2
+)
+
+// Tracer starts the traces exported to a collector
+type Tracer struct {
+	endpoint    string
+	serviceName string
+	ratio       float64
+	client      *http.Client
+	log         logr.Logger
+}
+
+// NewTracer returns a Tracer exporting the spans of the sampled traces, `ratio` being the ratio of the traces sampled
+func NewTracer(endpoint, serviceName string, ratio float64, tlsConfig *tls.Config, log logr.Logger) *Tracer {
+	return &Tracer{
+		endpoint:    strings.TrimSuffix(endpoint, "/") + TracesPath,
+		serviceName: serviceName,
+		ratio:       ratio,
+		client: &http.Client{
+			Timeout:   ExportTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+		log: log,
+	}
+}
+
+// Close closes the idle connections to the collector, the tracer is not expected to be used afterwards
+func (t *Tracer) Close() {
+	t.client.CloseIdleConnections()
+}
+
+// Trace is a root span and its children, exported once the root span ends
+type Trace struct {
+	tracer  *Tracer
+	id      [16]byte
+	sampled bool
+	root    *Span
+	mu      sync.Mutex
+	spans   []*Span
+}
+
+// Span is a timed operation of a trace
+type Span struct {
+	trace      *Trace
+	id         [8]byte
+	parent     *Span
+	name       string
+	start      time.Time
+	end        time.Time
+	attributes map[string]string
+	err        error
+}
+
+// StartTrace starts a trace with a root span started at `start`
+func (t *Tracer) StartTrace(name string, start time.Time, attributes map[string]string) *Trace {
+	trace := &Trace{tracer: t}
+	_, _ = rand.Read(trace.id[:])
+	// The trace ID is random, so its lower bits sample the traces with the configured ratio
+	trace.sampled = t.ratio >= 1 || float64(binary.BigEndian.Uint64(trace.id[8:])>>11)/(1<<53) < t.ratio
+	trace.root = trace.newSpan(name, nil, start, attributes)
+	return trace
+}
+
+// StartSpan starts a child span of the root span. It's safe to call on a nil Trace, returning a nil Span
+func (t *Trace) StartSpan(name string) *Span {
+	if t == nil {
+		return nil
+	}
+	return t.newSpan(name, t.root, time.Now(), nil)
+}
+
+// End ends the root span and exports the spans of the trace in the background when it is sampled. It's safe to call on
+// a nil Trace
+func (t *Trace) End(err error) {
+	if t == nil {
+		return
+	}
+	t.root.End(err)
+	if !t.sampled {
+		return
+	}
+	t.mu.Lock()
+	spans := t.spans
+	t.mu.Unlock()
+	go func() {
+		if err := t.tracer.export(spans); err != nil {
+			t.tracer.log.Error(err, "unable to export the operator spans", "endpoint", t.tracer.endpoint)
+		}
+	}()
+}
+
+func (t *Trace) newSpan(name string, parent *Span, start time.Time, attributes map[string]string) *Span {
+	span := &Span{trace: t, parent: parent, name: name, start: start, attributes: attributes}
+	_, _ = rand.Read(span.id[:])
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return span
+}
+
+// End ends the span, with an error status if err is not nil. It's safe to call on a nil Span
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	s.err = err
+}
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type spanStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            spanStatus `json:"status"`
+}
+
+func attributes(m map[string]string) []keyValue {
+	var kvs []keyValue
+	for k, v := range m {
+		kv := keyValue{Key: k}
+		kv.Value.StringValue = v
+		kvs = append(kvs, kv)
+	}
+	return kvs
+}
+
+// exportRequest returns the OTLP ExportTraceServiceRequest of the spans in the JSON encoding
+func (t *Tracer) exportRequest(spans []*Span) ([]byte, error) {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		end := s.end
+		if end.IsZero() {
+			// The span of a handler interrupted by a panic
+			end = s.trace.root.end
+		}
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.trace.id[:]),
+			SpanID:            hex.EncodeToString(s.id[:]),
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+			Attributes:        attributes(s.attributes),
+			Status:            spanStatus{Code: statusCodeOk},
+		}
+		if s.parent != nil {
+			span.ParentSpanID = hex.EncodeToString(s.parent.id[:])
+		}
+		if s.err != nil {
+			span.Status = spanStatus{Code: statusCodeError, Message: s.err.Error()}
+		}
+		otlpSpans = append(otlpSpans, span)
+	}
+	request := map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": attributes(map[string]string{"service.name": t.serviceName}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": t.serviceName},
+				"spans": otlpSpans,
+			}},
+		}},
+	}
+	return json.Marshal(request)
+}
+
+func (t *Tracer) export(spans []*Span) error {
+	body, err := t.exportRequest(spans)
+	if err != nil {
+		return err
+	}
+	rsp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %d", rsp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestExportTrace(t *testing.T) {
+	requests := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, TracesPath, r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(r.Body)
+		request := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(body, &request))
+		requests <- request
+	}))
+	defer server.Close()
+
+	tracer := NewTracer(server.URL+"/", "infinispan-operator", 1, nil, logf.Log)
+	trace := tracer.StartTrace("reconcile", time.Now(), map[string]string{"infinispan.cluster": "example-infinispan"})
+	trace.StartSpan("upgrade").End(nil)
+	trace.StartSpan("scale-down").End(errors.New("unable to scale down"))
+	trace.End(nil)
+
+	var request map[string]interface{}
+	select {
+	case request = <-requests:
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "the spans were not exported")
+	}
+	resourceSpans := request["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	service := resourceSpans["resource"].(map[string]interface{})["attributes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "service.name", service["key"])
+	assert.Equal(t, "infinispan-operator", service["value"].(map[string]interface{})["stringValue"])
+
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	assert.Len(t, spans, 3)
+	root, upgrade, scaleDown := spans[0].(map[string]interface{}), spans[1].(map[string]interface{}), spans[2].(map[string]interface{})
+	assert.Equal(t, "reconcile", root["name"])
+	assert.Nil(t, root["parentSpanId"])
+	assert.Len(t, root["traceId"], 32)
+	assert.Equal(t, root["traceId"], upgrade["traceId"])
+	assert.Equal(t, root["spanId"], upgrade["parentSpanId"])
+	assert.Equal(t, float64(statusCodeOk), upgrade["status"].(map[string]interface{})["code"])
+	assert.Equal(t, map[string]interface{}{"code": float64(statusCodeError), "message": "unable to scale down"}, scaleDown["status"])
+}
+
+func TestSampling(t *testing.T) {
+	tracer := NewTracer("http://localhost:4318", "infinispan-operator", 0, nil, logf.Log)
+	assert.False(t, tracer.StartTrace("reconcile", time.Now(), nil).sampled)
+
+	tracer = NewTracer("http://localhost:4318", "infinispan-operator", 1, nil, logf.Log)
+	assert.True(t, tracer.StartTrace("reconcile", time.Now(), nil).sampled)
+}
+
+func TestNilTrace(t *testing.T) {
+	var trace *Trace
+	span := trace.StartSpan("upgrade")
+	assert.Nil(t, span)
+	span.End(nil)
+	trace.End(nil)
+}